@@ -29,6 +29,10 @@ fi
 go build -o "$WORKPUBLISH_DIR/metaServer/metaServer" ./cmd/metaServer
 echo "MetaServer 编译完成"
 
+# 编译 minfs 命令行工具
+go build -o "$WORKPUBLISH_DIR/bin/minfs" ./cmd/minfs
+echo "minfs 命令行工具编译完成"
+
 # 复制 metaServer 配置文件
 if [ -f "config.yaml" ]; then
     cp config.yaml "$WORKPUBLISH_DIR/metaServer/"
@@ -22,10 +22,11 @@ import (
 )
 
 var (
-	configPath = flag.String("config", "config.yaml", "Path to configuration file")
-	mockMode   = flag.Bool("mock", false, "Run in mock mode without etcd dependency")
-	port       = flag.String("port", "8001", "Port number for this DataServer instance")
-	instanceID = flag.String("id", "01", "Instance ID (01, 02, 03, 04)")
+	configPath  = flag.String("config", "config.yaml", "Path to configuration file")
+	mockMode    = flag.Bool("mock", false, "Run in mock mode without etcd dependency")
+	port        = flag.String("port", "8001", "Port number for this DataServer instance")
+	instanceID  = flag.String("id", "01", "Instance ID (01, 02, 03, 04)")
+	metricsPort = flag.Int("metrics-port", 0, "HTTP port for Prometheus /metrics (overrides server.metrics_port)")
 )
 
 func main() {
@@ -41,29 +42,57 @@ func main() {
 	config.Server.ListenAddress = fmt.Sprintf("0.0.0.0:%s", *port)
 	config.Server.DataserverId = fmt.Sprintf("dataServer-%s", *instanceID)
 	config.Storage.DataRootPath = fmt.Sprintf("./data%s", *instanceID)
+	if *metricsPort > 0 {
+		config.Server.MetricsPort = *metricsPort
+	}
 
 	log.Printf("Starting DataServer %s (Mock Mode: %v)", config.Server.DataserverId, *mockMode)
 	log.Printf("Listening on %s", config.Server.ListenAddress)
 
 	// 初始化存储服务
-	storageService, err := service.NewStorageService(config.Storage.DataRootPath)
+	storageService, err := service.NewStorageService(config.Storage.DataRootPath, config.Storage.ChunkChecksums)
 	if err != nil {
 		log.Fatalf("Failed to create storage service: %v", err)
 	}
 	log.Printf("Storage service initialized with root path: %s", config.Storage.DataRootPath)
 
+	// 初始化后台块巡检
+	if config.Scrubber.QuarantineDir != "" {
+		storageService.SetQuarantineDir(config.Scrubber.QuarantineDir)
+	}
+	if config.Scrubber.Enabled {
+		scrubber := service.NewBlockScrubber(storageService, config)
+		scrubber.Start()
+		defer scrubber.Stop()
+	}
+
+	// 传输层安全和块访问令牌，密钥与 MetaServer 的 security.block_token_key 一致
+	dialOption, err := service.DialOption(config)
+	if err != nil {
+		log.Fatalf("Failed to load TLS credentials: %v", err)
+	}
+	serverOptions, err := service.ServerOptions(config)
+	if err != nil {
+		log.Fatalf("Failed to load TLS credentials: %v", err)
+	}
+	blockTokens := service.NewBlockTokenManager(config.Security.BlockTokenKey,
+		time.Duration(config.Security.BlockTokenLifetime)*time.Second)
+
 	// 初始化复制服务
-	replicationService := service.NewReplicationService()
+	replicationService := service.NewReplicationService(dialOption, blockTokens)
 	defer replicationService.Close()
 	log.Println("Replication service initialized")
 
+	// 初始化纠删码服务，条带位于本节点时直接读写本地存储
+	erasureService := service.NewErasureService(storageService, replicationService, config.Server.ListenAddress)
+
 	// 初始化集群服务 - 根据模式选择实现
 	var clusterService model.ClusterService
 	if *mockMode {
 		clusterService = service.NewMockClusterService(config, storageService)
 		log.Println("Mock cluster service initialized")
 	} else {
-		clusterService, err = service.NewClusterService(config, storageService)
+		clusterService, err = service.NewClusterService(config, storageService, erasureService, blockTokens)
 		if err != nil {
 			log.Fatalf("Failed to create cluster service: %v", err)
 		}
@@ -82,14 +111,25 @@ func main() {
 	}
 
 	// 创建gRPC处理器
-	grpcHandler := handler.NewDataServerHandler(storageService, replicationService)
+	grpcHandler := handler.NewDataServerHandler(storageService, replicationService, erasureService, blockTokens)
 	log.Println("gRPC handler created")
 
+	// Prometheus 指标，磁盘使用情况在抓取时读取
+	metrics := service.NewMetrics(storageService)
+	grpcHandler.SetMetrics(metrics)
+	grpcHandler.SetMaxBlockGroupSize(config.Storage.BlockSize)
+	metricsServer := metrics.Serve(config.Server.MetricsPort)
+	if metricsServer != nil {
+		defer metricsServer.Close()
+	}
+
 	// 创建gRPC服务器
-	grpcServer := grpc.NewServer(
+	grpcServer := grpc.NewServer(append(serverOptions,
 		grpc.MaxRecvMsgSize(1024*1024*1024), // 1GB
 		grpc.MaxSendMsgSize(1024*1024*1024), // 1GB
-	)
+		grpc.ChainUnaryInterceptor(metrics.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(metrics.StreamServerInterceptor()),
+	)...)
 
 	// 注册服务
 	pb.RegisterDataServerServiceServer(grpcServer, grpcHandler)
@@ -2,13 +2,16 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
-	"sync"
 
 	"dataServer/internal/model"
 	"dataServer/pb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // DataServerHandler 实现DataServerService的gRPC处理器
@@ -17,20 +20,51 @@ type DataServerHandler struct {
 
 	storageService     model.StorageService
 	replicationService model.ReplicationService
+	erasureService     model.ErasureService
+	blockTokens        model.BlockTokenService
+	metrics            model.MetricsRecorder
+	maxBlockGroupSize  uint64 // WriteBlockGroup 接收的数据上限，0 表示不限制
 }
 
 // NewDataServerHandler 创建新的DataServer处理器
 func NewDataServerHandler(
 	storageSvc model.StorageService,
 	replicationSvc model.ReplicationService,
+	erasureSvc model.ErasureService,
+	blockTokens model.BlockTokenService,
 ) *DataServerHandler {
 	return &DataServerHandler{
 		storageService:     storageSvc,
 		replicationService: replicationSvc,
+		erasureService:     erasureSvc,
+		blockTokens:        blockTokens,
+	}
+}
+
+// SetMetrics 设置读写字节数和操作结果的指标
+func (h *DataServerHandler) SetMetrics(metrics model.MetricsRecorder) {
+	h.metrics = metrics
+}
+
+// SetMaxBlockGroupSize 设置 WriteBlockGroup 在内存中缓存的数据上限，通常为块大小
+func (h *DataServerHandler) SetMaxBlockGroupSize(size uint64) {
+	h.maxBlockGroupSize = size
+}
+
+// checkToken 校验请求携带的块访问令牌，op 为 r(读)、w(写)、d(删除)、c(复制)
+func (h *DataServerHandler) checkToken(token string, blockID uint64, op string) error {
+	if err := h.blockTokens.Verify(token, blockID, op); err != nil {
+		log.Printf("Rejected %s on block %d: %v", op, blockID, err)
+		return status.Errorf(codes.PermissionDenied, "block %d: %v", blockID, err)
 	}
+	return nil
 }
 
 // WriteBlock 实现流式写入数据块
+// 写入流水线：每收到一个分片即写入本地临时文件并转发给下一个副本，
+// 下一个副本再转发给它之后的副本，整个块无需载入内存。
+// 客户端直连的首个节点要求下一个副本确认成功，否则放弃本地写入；
+// 流水线中更靠后的副本失败只记录日志，由FSCK补齐副本
 func (h *DataServerHandler) WriteBlock(stream pb.DataServerService_WriteBlockServer) error {
 	// 接收第一个消息（应该包含元数据）
 	req, err := stream.Recv()
@@ -46,32 +80,104 @@ func (h *DataServerHandler) WriteBlock(stream pb.DataServerService_WriteBlockSer
 	metadata := req.GetMetadata()
 	blockID := metadata.BlockId
 	replicaLocations := metadata.ReplicaLocations
+	if err := h.checkToken(metadata.Token, blockID, "w"); err != nil {
+		return err
+	}
 
 	log.Printf("Starting write block %d with %d replicas", blockID, len(replicaLocations))
 
-	// 收集所有数据块
-	var allData []byte
+	writer, err := h.storageService.OpenBlockWriter(blockID)
+	if err != nil {
+		return fmt.Errorf("failed to open block %d for writing: %w", blockID, err)
+	}
+
+	// 打开到下一个副本的转发流，剩余副本由下游继续转发
+	var downstream model.BlockForwardStream
+	var downstreamErr error
+	if len(replicaLocations) > 0 {
+		downstream, downstreamErr = h.replicationService.OpenForwardStream(replicaLocations[0], &model.WriteBlockMetadata{
+			BlockId:          blockID,
+			ReplicaLocations: replicaLocations[1:],
+			Forwarded:        true,
+			Token:            metadata.Token,
+		})
+		if downstreamErr != nil {
+			log.Printf("Failed to open pipeline to %s for block %d: %v", replicaLocations[0], blockID, downstreamErr)
+		}
+	}
+
+	abort := func() {
+		writer.Abort()
+		if downstream != nil && downstreamErr == nil {
+			downstream.Abort()
+		}
+	}
 
 	// 继续接收数据块
+	var received int64
 	for {
 		req, err := stream.Recv()
 		if err != nil {
 			if err == io.EOF {
 				break
 			}
+			abort()
 			return fmt.Errorf("failed to receive data chunk: %w", err)
 		}
 
-		// 检查是否为数据块
-		if chunkData := req.GetChunkData(); chunkData != nil {
-			allData = append(allData, chunkData...)
+		chunkData := req.GetChunkData()
+		if len(chunkData) == 0 {
+			continue
+		}
+
+		if _, err := writer.Write(chunkData); err != nil {
+			abort()
+			return fmt.Errorf("failed to write block %d: %w", blockID, err)
 		}
+
+		if downstream != nil && downstreamErr == nil {
+			if err := downstream.Send(chunkData); err != nil {
+				// 下游中断后继续完成本地写入，是否成功由下面的确认规则决定
+				log.Printf("Pipeline to %s broken for block %d: %v", replicaLocations[0], blockID, err)
+				downstreamErr = err
+				downstream.Abort()
+			}
+		}
+		received += int64(len(chunkData))
 	}
 
-	log.Printf("Received %d bytes for block %d", len(allData), blockID)
+	log.Printf("Received %d bytes for block %d", received, blockID)
 
-	// 主从同步复制：1个主库 + 1个同步从库 + 1个异步从库
-	success := h.performMasterSlaveReplication(blockID, allData, replicaLocations)
+	if downstream != nil && downstreamErr == nil {
+		downstreamErr = downstream.CloseAndRecv()
+	}
+
+	if len(replicaLocations) > 0 && h.metrics != nil {
+		h.metrics.RecordOperation("FORWARD", downstreamErr == nil)
+	}
+
+	success := true
+	if downstreamErr != nil {
+		log.Printf("Downstream replica %s failed for block %d: %v", replicaLocations[0], blockID, downstreamErr)
+		if !metadata.Forwarded {
+			success = false
+		}
+	}
+
+	if success {
+		if err := writer.Commit(); err != nil {
+			log.Printf("Local write failed for block %d: %v", blockID, err)
+			success = false
+		}
+	} else {
+		log.Printf("Discarding local write for block %d", blockID)
+		writer.Abort()
+	}
+
+	if success {
+		h.addBytesWritten(received)
+	}
+	h.logOperationStats("WRITE", blockID, int(received), success)
 
 	// 发送响应
 	response := &pb.WriteBlockResponse{
@@ -84,44 +190,68 @@ func (h *DataServerHandler) WriteBlock(stream pb.DataServerService_WriteBlockSer
 // ReadBlock 实现流式读取数据块
 func (h *DataServerHandler) ReadBlock(req *pb.ReadBlockRequest, stream pb.DataServerService_ReadBlockServer) error {
 	blockID := req.BlockId
-	log.Printf("Reading block %d", blockID)
+	if err := h.checkToken(req.Token, blockID, "r"); err != nil {
+		return err
+	}
+	log.Printf("Reading block %d (offset=%d, length=%d)", blockID, req.Offset, req.Length)
 
-	// 从本地存储读取数据
-	data, err := h.storageService.ReadBlock(blockID)
+	reader, err := h.storageService.OpenBlockRangeReader(blockID, int64(req.Offset), int64(req.Length))
 	if err != nil {
 		log.Printf("Failed to read block %d: %v", blockID, err)
 		return fmt.Errorf("failed to read block %d: %w", blockID, err)
 	}
-
-	log.Printf("Successfully read %d bytes for block %d", len(data), blockID)
+	defer reader.Close()
 
 	// 分块发送数据
 	const chunkSize = 64 * 1024 // 64KB per chunk
-
-	for i := 0; i < len(data); i += chunkSize {
-		end := i + chunkSize
-		if end > len(data) {
-			end = len(data)
+	buffer := make([]byte, chunkSize)
+
+	// 第一个消息携带保存的整块校验和，供接收方端到端校验（范围读取时不携带）
+	var sent int64
+	for first := true; ; first = false {
+		n, err := io.ReadFull(reader, buffer)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			log.Printf("Failed to read block %d: %v", blockID, err)
+			if errors.Is(err, model.ErrChecksumMismatch) {
+				// 校验失败使用独立的错误码，客户端可据此换副本重试
+				return status.Errorf(codes.DataLoss, "block %d is corrupt: %v", blockID, err)
+			}
+			return fmt.Errorf("failed to read block %d: %w", blockID, err)
 		}
 
-		chunk := data[i:end]
-		response := &pb.ReadBlockResponse{
-			ChunkData: chunk,
+		if n > 0 || first {
+			response := &pb.ReadBlockResponse{
+				ChunkData: buffer[:n],
+			}
+			if first {
+				response.Crc32C, response.HasCrc32C = reader.Checksum()
+			}
+
+			if err := stream.Send(response); err != nil {
+				log.Printf("Failed to send chunk for block %d: %v", blockID, err)
+				h.addBytesRead(sent)
+				return fmt.Errorf("failed to send data chunk: %w", err)
+			}
+			sent += int64(n)
 		}
 
-		if err := stream.Send(response); err != nil {
-			log.Printf("Failed to send chunk for block %d: %v", blockID, err)
-			return fmt.Errorf("failed to send data chunk: %w", err)
+		if n < chunkSize {
+			break
 		}
 	}
 
-	log.Printf("Successfully sent all chunks for block %d", blockID)
+	log.Printf("Successfully sent %d bytes for block %d", sent, blockID)
+	h.addBytesRead(sent)
+	h.logOperationStats("READ", blockID, int(sent), true)
 	return nil
 }
 
 // DeleteBlock 实现删除数据块
 func (h *DataServerHandler) DeleteBlock(ctx context.Context, req *pb.DeleteBlockRequest) (*pb.DeleteBlockResponse, error) {
 	blockID := req.BlockId
+	if err := h.checkToken(req.Token, blockID, "d"); err != nil {
+		return nil, err
+	}
 	log.Printf("Deleting block %d", blockID)
 
 	err := h.storageService.DeleteBlock(blockID)
@@ -132,6 +262,7 @@ func (h *DataServerHandler) DeleteBlock(ctx context.Context, req *pb.DeleteBlock
 	} else {
 		log.Printf("Successfully deleted block %d", blockID)
 	}
+	h.logOperationStats("DELETE", blockID, 0, success)
 
 	return &pb.DeleteBlockResponse{
 		Success: success,
@@ -142,33 +273,128 @@ func (h *DataServerHandler) DeleteBlock(ctx context.Context, req *pb.DeleteBlock
 func (h *DataServerHandler) CopyBlock(ctx context.Context, req *pb.CopyBlockRequest) (*pb.CopyBlockResponse, error) {
 	blockID := req.BlockId
 	sourceAddr := req.SourceAddress
+	if err := h.checkToken(req.Token, blockID, "c"); err != nil {
+		return nil, err
+	}
 
 	log.Printf("Copying block %d from %s", blockID, sourceAddr)
 
-	// 从源地址拉取数据
-	data, err := h.replicationService.PullBlock(sourceAddr, blockID)
+	// 边拉取边写入本地临时文件，校验通过后再提交
+	writer, err := h.storageService.OpenBlockWriter(blockID)
+	if err != nil {
+		log.Printf("Failed to open block %d for writing: %v", blockID, err)
+		return &pb.CopyBlockResponse{
+			Success: false,
+		}, nil
+	}
+
+	received, err := h.replicationService.PullBlockTo(sourceAddr, blockID, writer)
 	if err != nil {
 		log.Printf("Failed to pull block %d from %s: %v", blockID, sourceAddr, err)
+		writer.Abort()
 		return &pb.CopyBlockResponse{
 			Success: false,
 		}, nil
 	}
 
 	// 存储到本地
-	err = h.storageService.WriteBlock(blockID, data)
+	err = writer.Commit()
 	success := err == nil
 
 	if err != nil {
 		log.Printf("Failed to store copied block %d: %v", blockID, err)
 	} else {
-		log.Printf("Successfully copied block %d from %s (%d bytes)", blockID, sourceAddr, len(data))
+		log.Printf("Successfully copied block %d from %s (%d bytes)", blockID, sourceAddr, received)
+		h.addBytesWritten(received)
 	}
+	h.logOperationStats("COPY", blockID, int(received), success)
 
 	return &pb.CopyBlockResponse{
 		Success: success,
 	}, nil
 }
 
+// WriteBlockGroup 接收一个逻辑块的完整数据，编码为纠删码条带后写入块组中的各个位置
+func (h *DataServerHandler) WriteBlockGroup(stream pb.DataServerService_WriteBlockGroupServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return fmt.Errorf("failed to receive first message: %w", err)
+	}
+	group := req.GetGroup()
+	if group == nil {
+		return fmt.Errorf("first message must contain block group")
+	}
+	if err := h.checkToken(group.Token, group.GroupId, "w"); err != nil {
+		return err
+	}
+
+	log.Printf("Starting write block group %d (%s)", group.GroupId, group.EcPolicy)
+
+	// 编码需要完整的逻辑块，数据先在内存中拼接，超过上限时拒绝以免占满内存
+	var data []byte
+	for {
+		req, err := stream.Recv()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return fmt.Errorf("failed to receive data chunk: %w", err)
+		}
+		chunk := req.GetChunkData()
+		if h.maxBlockGroupSize > 0 && uint64(len(data)+len(chunk)) > h.maxBlockGroupSize {
+			h.logOperationStats("WRITE_GROUP", group.GroupId, len(data), false)
+			return status.Errorf(codes.ResourceExhausted, "block group %d exceeds %d bytes", group.GroupId, h.maxBlockGroupSize)
+		}
+		data = append(data, chunk...)
+	}
+
+	success := true
+	if err := h.erasureService.WriteGroup(group, data); err != nil {
+		log.Printf("Failed to write block group %d: %v", group.GroupId, err)
+		success = false
+	} else {
+		h.addBytesWritten(int64(len(data)))
+	}
+	h.logOperationStats("WRITE_GROUP", group.GroupId, len(data), success)
+
+	return stream.SendAndClose(&pb.WriteBlockResponse{
+		Success: success,
+	})
+}
+
+// ReadBlockGroup 读取纠删码块组中的数据范围，数据条带缺失时由校验条带解码
+func (h *DataServerHandler) ReadBlockGroup(req *pb.ReadBlockGroupRequest, stream pb.DataServerService_ReadBlockGroupServer) error {
+	group := req.GetGroup()
+	if group == nil {
+		return status.Error(codes.InvalidArgument, "block group is required")
+	}
+	if err := h.checkToken(group.Token, group.GroupId, "r"); err != nil {
+		return err
+	}
+	log.Printf("Reading block group %d (offset=%d, length=%d)", group.GroupId, req.Offset, req.Length)
+
+	data, err := h.erasureService.ReadGroup(group, req.Offset, req.Length)
+	if err != nil {
+		log.Printf("Failed to read block group %d: %v", group.GroupId, err)
+		return fmt.Errorf("failed to read block group %d: %w", group.GroupId, err)
+	}
+
+	const chunkSize = 64 * 1024 // 64KB per chunk
+	for i := 0; i < len(data); i += chunkSize {
+		end := i + chunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		if err := stream.Send(&pb.ReadBlockResponse{ChunkData: data[i:end]}); err != nil {
+			h.addBytesRead(int64(i))
+			return fmt.Errorf("failed to send data chunk: %w", err)
+		}
+	}
+	h.addBytesRead(int64(len(data)))
+	h.logOperationStats("READ_GROUP", group.GroupId, len(data), true)
+	return nil
+}
+
 // 实现简化的流接口
 
 // writeBlockServer 实现WriteBlockStream接口
@@ -201,75 +427,6 @@ func (h *DataServerHandler) validateBlockID(blockID uint64) error {
 	return nil
 }
 
-// performMasterSlaveReplication 执行主从同步复制
-// 架构：1个主库 + 1个同步从库 + 1个异步从库
-func (h *DataServerHandler) performMasterSlaveReplication(blockID uint64, data []byte, replicaLocations []string) bool {
-	if len(replicaLocations) == 0 {
-		log.Printf("No replica locations provided, only writing to local storage")
-		return h.storageService.WriteBlock(blockID, data) == nil
-	}
-
-	// 步骤1：写入主库（当前dataServer）
-	log.Printf("Step 1: Writing to master (local storage)")
-	err := h.storageService.WriteBlock(blockID, data)
-	if err != nil {
-		log.Printf("Master write failed for block %d: %v", blockID, err)
-		return false
-	}
-	log.Printf("Master write successful for block %d", blockID)
-
-	// 步骤2：同步写入第一个从库
-	if len(replicaLocations) >= 1 {
-		syncSlaveAddr := replicaLocations[0]
-		log.Printf("Step 2: Synchronous write to slave %s", syncSlaveAddr)
-
-		err := h.replicationService.PushBlock(syncSlaveAddr, blockID, data)
-		if err != nil {
-			log.Printf("Synchronous slave write failed for block %d to %s: %v", blockID, syncSlaveAddr, err)
-
-			// 回滚：删除主库中的数据
-			log.Printf("Rolling back master write for block %d", blockID)
-			if rollbackErr := h.storageService.DeleteBlock(blockID); rollbackErr != nil {
-				log.Printf("Rollback failed for block %d: %v", blockID, rollbackErr)
-			} else {
-				log.Printf("Rollback successful for block %d", blockID)
-			}
-			return false
-		}
-		log.Printf("Synchronous slave write successful for block %d to %s", blockID, syncSlaveAddr)
-	}
-
-	// 步骤3：异步写入其他从库
-	if len(replicaLocations) >= 2 {
-		asyncSlaves := replicaLocations[1:]
-		log.Printf("Step 3: Asynchronous write to %d slaves", len(asyncSlaves))
-
-		// 使用goroutine异步写入
-		go func() {
-			var wg sync.WaitGroup
-			for _, slaveAddr := range asyncSlaves {
-				wg.Add(1)
-				go func(addr string) {
-					defer wg.Done()
-					log.Printf("Async write to slave %s", addr)
-					err := h.replicationService.PushBlock(addr, blockID, data)
-					if err != nil {
-						log.Printf("Async slave write failed for block %d to %s: %v", blockID, addr, err)
-					} else {
-						log.Printf("Async slave write successful for block %d to %s", blockID, addr)
-					}
-				}(slaveAddr)
-			}
-			wg.Wait()
-			log.Printf("All async writes completed for block %d", blockID)
-		}()
-	}
-
-	// 主库和同步从库都成功，返回true
-	log.Printf("Master-slave replication successful for block %d", blockID)
-	return true
-}
-
 // 辅助函数：记录操作统计
 func (h *DataServerHandler) logOperationStats(operation string, blockID uint64, dataSize int, success bool) {
 	status := "SUCCESS"
@@ -282,4 +439,22 @@ func (h *DataServerHandler) logOperationStats(operation string, blockID uint64,
 	} else {
 		log.Printf("[STATS] %s block %d: %s", operation, blockID, status)
 	}
+
+	if h.metrics != nil {
+		h.metrics.RecordOperation(operation, success)
+	}
+}
+
+// addBytesRead 记录发送给客户端的字节数
+func (h *DataServerHandler) addBytesRead(n int64) {
+	if h.metrics != nil && n > 0 {
+		h.metrics.AddBytesRead(n)
+	}
+}
+
+// addBytesWritten 记录提交到本地存储的字节数
+func (h *DataServerHandler) addBytesWritten(n int64) {
+	if h.metrics != nil && n > 0 {
+		h.metrics.AddBytesWritten(n)
+	}
 }
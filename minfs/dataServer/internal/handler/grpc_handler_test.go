@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"dataServer/internal/model"
+	"dataServer/internal/service"
+	"dataServer/pb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeReadStream 收集 ReadBlock 发送的数据
+type fakeReadStream struct {
+	grpc.ServerStream
+	data      []byte
+	responses []*pb.ReadBlockResponse
+}
+
+func (s *fakeReadStream) Send(resp *pb.ReadBlockResponse) error {
+	s.data = append(s.data, resp.ChunkData...)
+	s.responses = append(s.responses, resp)
+	return nil
+}
+
+// fakeWriteStream 依次返回预置的 WriteBlock 请求，最后返回 io.EOF
+type fakeWriteStream struct {
+	grpc.ServerStream
+	reqs []*pb.WriteBlockRequest
+	resp *pb.WriteBlockResponse
+}
+
+func (s *fakeWriteStream) Recv() (*pb.WriteBlockRequest, error) {
+	if len(s.reqs) == 0 {
+		return nil, io.EOF
+	}
+	req := s.reqs[0]
+	s.reqs = s.reqs[1:]
+	return req, nil
+}
+
+func (s *fakeWriteStream) SendAndClose(resp *pb.WriteBlockResponse) error {
+	s.resp = resp
+	return nil
+}
+
+// fakeWriteGroupStream 依次返回预置的 WriteBlockGroup 请求，最后返回 io.EOF
+type fakeWriteGroupStream struct {
+	grpc.ServerStream
+	reqs []*pb.WriteBlockGroupRequest
+}
+
+func (s *fakeWriteGroupStream) Recv() (*pb.WriteBlockGroupRequest, error) {
+	if len(s.reqs) == 0 {
+		return nil, io.EOF
+	}
+	req := s.reqs[0]
+	s.reqs = s.reqs[1:]
+	return req, nil
+}
+
+func (s *fakeWriteGroupStream) SendAndClose(resp *pb.WriteBlockResponse) error {
+	return nil
+}
+
+// unreachableReplication 模拟下游副本不可达
+type unreachableReplication struct {
+	model.ReplicationService
+}
+
+func (unreachableReplication) OpenForwardStream(targetAddr string, metadata *model.WriteBlockMetadata) (model.BlockForwardStream, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestReadBlockReturnsDataLossOnChecksumMismatch(t *testing.T) {
+	dir := t.TempDir()
+	storage, err := service.NewStorageService(dir, true)
+	if err != nil {
+		t.Fatalf("new storage: %v", err)
+	}
+	h := NewDataServerHandler(storage, nil, nil, service.NewBlockTokenManager("", 0))
+
+	data := make([]byte, 2*service.ChecksumChunkSize+100)
+	rand.New(rand.NewSource(1)).Read(data)
+	if err := storage.WriteBlock(1, data); err != nil {
+		t.Fatalf("write block: %v", err)
+	}
+
+	// 第一个消息携带整块校验和
+	stream := &fakeReadStream{}
+	if err := h.ReadBlock(&pb.ReadBlockRequest{BlockId: 1}, stream); err != nil || !bytes.Equal(stream.data, data) {
+		t.Fatalf("read intact block: %d bytes, err=%v", len(stream.data), err)
+	}
+	if first := stream.responses[0]; !first.HasCrc32C || first.Crc32C != service.ComputeCRC32C(data) {
+		t.Errorf("first response checksum: %08x (present=%v)", first.Crc32C, first.HasCrc32C)
+	}
+
+	// 损坏最后一个分片
+	corruptAt := int64(len(data) - 1)
+	file, err := os.OpenFile(filepath.Join(dir, "1.dat"), os.O_RDWR, 0)
+	if err != nil {
+		t.Fatalf("open block file: %v", err)
+	}
+	if _, err := file.WriteAt([]byte{data[corruptAt] ^ 0xff}, corruptAt); err != nil {
+		t.Fatalf("corrupt block file: %v", err)
+	}
+	file.Close()
+
+	// 整块读取和范围读取都以 DataLoss 失败，且只返回损坏分片之前已校验的数据
+	for _, c := range []struct {
+		req     *pb.ReadBlockRequest
+		maxSent int
+	}{
+		{&pb.ReadBlockRequest{BlockId: 1}, 2 * service.ChecksumChunkSize},
+		{&pb.ReadBlockRequest{BlockId: 1, Offset: uint64(corruptAt) - 10, Length: 20}, 0},
+	} {
+		stream := &fakeReadStream{}
+		err := h.ReadBlock(c.req, stream)
+		if status.Code(err) != codes.DataLoss {
+			t.Errorf("read corrupt block (offset=%d, length=%d): err=%v, want DataLoss", c.req.Offset, c.req.Length, err)
+		}
+		if len(stream.data) > c.maxSent {
+			t.Errorf("read corrupt block (offset=%d, length=%d): sent %d bytes, want at most %d", c.req.Offset, c.req.Length, len(stream.data), c.maxSent)
+		}
+	}
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	dir := t.TempDir()
+	storage, err := service.NewStorageService(dir, true)
+	if err != nil {
+		t.Fatalf("new storage: %v", err)
+	}
+	metrics := service.NewMetrics(storage)
+	h := NewDataServerHandler(storage, unreachableReplication{}, nil, service.NewBlockTokenManager("", 0))
+	h.SetMetrics(metrics)
+
+	// 巡检一轮：块 1 完好，块 2 损坏，扫描数只计校验通过的块
+	for blockID := uint64(1); blockID <= 2; blockID++ {
+		if err := storage.WriteBlock(blockID, make([]byte, 100)); err != nil {
+			t.Fatalf("write block %d: %v", blockID, err)
+		}
+	}
+	file, err := os.OpenFile(filepath.Join(dir, "2.dat"), os.O_RDWR, 0)
+	if err != nil {
+		t.Fatalf("open block file: %v", err)
+	}
+	if _, err := file.WriteAt([]byte{0xff}, 10); err != nil {
+		t.Fatalf("corrupt block file: %v", err)
+	}
+	file.Close()
+
+	config := &model.Config{}
+	config.Scrubber.RateMBps = 1000
+	scrubber := service.NewBlockScrubber(storage, config)
+	storage.SetScrubber(scrubber)
+	scrubber.Start()
+	deadline := time.Now().Add(5 * time.Second)
+	for scrubber.Stats().LastPassTime.IsZero() {
+		if time.Now().After(deadline) {
+			t.Fatal("scrub pass did not finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	scrubber.Stop()
+
+	// 作为流水线首个节点写入块 3，下游不可达；Forwarded 为真时本地写入仍然成功
+	data := make([]byte, 300)
+	write := &fakeWriteStream{reqs: []*pb.WriteBlockRequest{
+		{Content: &pb.WriteBlockRequest_Metadata{Metadata: &pb.WriteBlockMetadata{BlockId: 3, ReplicaLocations: []string{"ds2:8001"}, Forwarded: true}}},
+		{Content: &pb.WriteBlockRequest_ChunkData{ChunkData: data}},
+	}}
+	if err := h.WriteBlock(write); err != nil || !write.resp.Success {
+		t.Fatalf("write block: resp=%v, err=%v", write.resp, err)
+	}
+	read := &fakeReadStream{}
+	if err := h.ReadBlock(&pb.ReadBlockRequest{BlockId: 3, Offset: 100}, read); err != nil {
+		t.Fatalf("read block: %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	samples := make(map[string]string)
+	for _, line := range strings.Split(recorder.Body.String(), "\n") {
+		if i := strings.LastIndex(line, " "); i > 0 && !strings.HasPrefix(line, "#") {
+			samples[line[:i]] = line[i+1:]
+		}
+	}
+
+	for series, want := range map[string]string{
+		`minfs_dataserver_operations_total{operation="WRITE",status="success"}`:  "1",
+		`minfs_dataserver_operations_total{operation="FORWARD",status="failed"}`: "1",
+		`minfs_dataserver_operations_total{operation="READ",status="success"}`:   "1",
+		"minfs_dataserver_bytes_written_total":                                   "300",
+		"minfs_dataserver_bytes_read_total":                                      "200",
+		"minfs_dataserver_blocks":                                                "2",
+		"minfs_dataserver_corrupt_blocks":                                        "1",
+		"minfs_dataserver_scrub_blocks_scanned_total":                            "1",
+		"minfs_dataserver_scrub_bytes_scanned_total":                             "100",
+		"minfs_dataserver_scrub_corrupt_blocks_total":                            "1",
+	} {
+		if got, ok := samples[series]; !ok || got != want {
+			t.Errorf("%s = %q, want %q", series, got, want)
+		}
+	}
+	if _, ok := samples["minfs_dataserver_scrub_last_pass_timestamp_seconds"]; !ok {
+		t.Error("scrub last pass time not exported")
+	}
+}
+
+func TestWriteBlockGroupRejectsOversizedGroup(t *testing.T) {
+	// 超限时在编码之前拒绝，不会调用纠删码服务
+	h := NewDataServerHandler(nil, nil, nil, service.NewBlockTokenManager("", 0))
+	h.SetMaxBlockGroupSize(100)
+
+	stream := &fakeWriteGroupStream{reqs: []*pb.WriteBlockGroupRequest{
+		{Content: &pb.WriteBlockGroupRequest_Group{Group: &pb.BlockGroup{GroupId: 1, EcPolicy: "RS-2-1"}}},
+		{Content: &pb.WriteBlockGroupRequest_ChunkData{ChunkData: make([]byte, 60)}},
+		{Content: &pb.WriteBlockGroupRequest_ChunkData{ChunkData: make([]byte, 60)}},
+	}}
+	if err := h.WriteBlockGroup(stream); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("write oversized block group: err=%v, want ResourceExhausted", err)
+	}
+}
@@ -1,6 +1,12 @@
 package model
 
 import (
+	"errors"
+	"io"
+	"time"
+
+	"dataServer/pb"
+
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"google.golang.org/grpc"
 )
@@ -9,17 +15,23 @@ const (
 	DefaultBlockSize = 4 * 1024 * 1024 // 4MB per block
 )
 
+// ErrChecksumMismatch 数据块内容与保存的校验和不一致
+var ErrChecksumMismatch = errors.New("block checksum mismatch")
+
 // Config 配置结构体，映射 config.yaml
 type Config struct {
 	Server struct {
 		ListenAddress string `yaml:"listen_address"`
 		DataserverId  string `yaml:"dataServer_id"`
+		Topology      string `yaml:"topology"`     // 拓扑标签 /zone/rack，用于副本放置
+		MetricsPort   int    `yaml:"metrics_port"` // Prometheus /metrics 的 HTTP 端口，不大于 0 时不启动
 	} `yaml:"server"`
 
 	Storage struct {
 		DataRootPath   string `yaml:"data_root_path"`
 		MaxStorageSize uint64 `yaml:"max_storage_size"`
 		BlockSize      uint64 `yaml:"block_size"`
+		ChunkChecksums bool   `yaml:"chunk_checksums"` // 是否额外保存每 64KB 分片的校验和
 	} `yaml:"storage"`
 
 	Etcd struct {
@@ -28,6 +40,13 @@ type Config struct {
 		LeaseTTL    int64    `yaml:"lease_ttl"`
 	} `yaml:"etcd"`
 
+	Scrubber struct {
+		Enabled       bool    `yaml:"enabled"`
+		RateMBps      float64 `yaml:"rate_mb_per_sec"` // 巡检读取速率上限 (MB/s)
+		Interval      int     `yaml:"interval"`        // 两轮巡检之间的间隔(秒)
+		QuarantineDir string  `yaml:"quarantine_dir"`  // 损坏块隔离目录，默认为 <data_root_path>/quarantine
+	} `yaml:"scrubber"`
+
 	MetaServer struct {
 		// Address removed - now uses etcd leader discovery
 		HeartbeatInterval int `yaml:"heartbeat_interval"`
@@ -38,6 +57,21 @@ type Config struct {
 		Level  string `yaml:"level"`
 		Output string `yaml:"output"`
 	} `yaml:"logging"`
+
+	Security struct {
+		TLS                TLSConfig `yaml:"tls"`
+		BlockTokenKey      string    `yaml:"block_token_key"`      // 与 MetaServer 共享的块令牌密钥，为空时不校验令牌
+		BlockTokenLifetime int       `yaml:"block_token_lifetime"` // 节点间内部调用自签令牌的有效期(秒)
+	} `yaml:"security"`
+}
+
+// TLSConfig gRPC 传输层安全配置，client_auth 开启时要求对端出示由 CA 签发的证书 (mTLS)
+type TLSConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	CertFile   string `yaml:"cert_file"`
+	KeyFile    string `yaml:"key_file"`
+	CAFile     string `yaml:"ca_file"`
+	ClientAuth bool   `yaml:"client_auth"`
 }
 
 // DataServer 核心状态结构体
@@ -65,6 +99,26 @@ type StorageService interface {
 	GetStat() (*StorageStat, error)
 	BlockExists(blockID uint64) bool
 	ListBlocks() ([]uint64, error)
+
+	// 流式接口，避免将整个块载入内存
+	OpenBlockWriter(blockID uint64) (BlockWriter, error)
+	OpenBlockReader(blockID uint64) (BlockReader, error)
+	OpenBlockRangeReader(blockID uint64, offset, length int64) (BlockReader, error)
+}
+
+// BlockWriter 数据块写入流，Commit 之前写入的数据对读取不可见
+type BlockWriter interface {
+	io.Writer
+	Checksum() uint32 // 目前已写入数据的 CRC32C
+	Commit() error    // 落盘并原子替换旧块，同时写入校验文件
+	Abort() error     // 放弃写入，清理临时文件
+}
+
+// BlockReader 数据块读取流，读取过程中校验数据，损坏时返回 ErrChecksumMismatch
+type BlockReader interface {
+	io.ReadCloser
+	Size() int64              // 读取的数据长度
+	Checksum() (uint32, bool) // 保存的整块 CRC32C，旧版本写入的块或范围读取时第二个返回值为 false
 }
 
 // ReplicationService 复制服务接口
@@ -72,6 +126,38 @@ type ReplicationService interface {
 	ForwardBlock(targetAddr string, metadata *WriteBlockMetadata, data []byte) error
 	PushBlock(targetAddr string, blockID uint64, data []byte) error
 	PullBlock(sourceAddr string, blockID uint64) ([]byte, error)
+
+	// 流式接口，数据分片到达即转发或写出
+	OpenForwardStream(targetAddr string, metadata *WriteBlockMetadata) (BlockForwardStream, error)
+	PullBlockTo(sourceAddr string, blockID uint64, w io.Writer) (int64, error)
+}
+
+// BlockForwardStream 向下游DataServer转发数据块的写入流
+type BlockForwardStream interface {
+	Send(chunk []byte) error
+	CloseAndRecv() error // 结束发送并等待下游确认
+	Abort()              // 中止转发
+}
+
+// ErasureService 纠删码服务接口，块组按 RS-<k>-<m> 策略编码为 k 个数据条带和 m 个校验条带
+type ErasureService interface {
+	WriteGroup(group *pb.BlockGroup, data []byte) error
+	ReadGroup(group *pb.BlockGroup, offset, length uint64) ([]byte, error)
+	ReconstructStripe(group *pb.BlockGroup, index int) error
+	EncodeBlock(blockID uint64, group *pb.BlockGroup) error
+}
+
+// BlockTokenService 块访问令牌服务接口，未配置密钥时签发空令牌且不做校验
+type BlockTokenService interface {
+	Sign(blockID uint64, ops string) string
+	Verify(token string, blockID uint64, op string) error
+}
+
+// MetricsRecorder 记录 gRPC 处理器的读写字节数和操作结果
+type MetricsRecorder interface {
+	RecordOperation(operation string, success bool)
+	AddBytesRead(n int64)
+	AddBytesWritten(n int64)
 }
 
 // ClusterService 集群服务接口
@@ -83,15 +169,27 @@ type ClusterService interface {
 
 // StorageStat 存储统计信息
 type StorageStat struct {
-	BlockCount    uint64
-	FreeSpace     uint64
-	UsedSpace     uint64
-	TotalCapacity uint64
-	BlockIds      []uint64
+	BlockCount      uint64
+	FreeSpace       uint64
+	UsedSpace       uint64
+	TotalCapacity   uint64
+	BlockIds        []uint64 // 不包含已发现损坏的块
+	CorruptBlockIds []uint64 // 校验失败、等待从其他副本修复的块
+	Scrub           ScrubStats
+}
+
+// ScrubStats 后台巡检统计信息
+type ScrubStats struct {
+	BlocksScanned uint64    // 累计扫描的块数
+	BytesScanned  uint64    // 累计扫描的字节数
+	CorruptBlocks uint64    // 累计发现的损坏块数
+	LastPassTime  time.Time // 最近一轮完成时间
 }
 
 // WriteBlockMetadata 写入块元数据
 type WriteBlockMetadata struct {
 	BlockId          uint64
 	ReplicaLocations []string
+	Forwarded        bool   // 由上游DataServer在流水线中转发
+	Token            string // 块访问令牌，为空时由本节点签发
 }
@@ -0,0 +1,526 @@
+package service
+
+import (
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+
+	"dataServer/internal/model"
+)
+
+// localBlockWriter 流式写入本地数据块
+// 数据先写入临时文件并同时计算校验和，Commit 时落盘、写入校验文件后原子重命名
+type localBlockWriter struct {
+	storage  *LocalStorageService
+	blockID  uint64
+	file     *os.File
+	tempPath string
+
+	length     int64
+	crc        uint32
+	withChunks bool
+	chunkCRCs  []uint32
+	chunkCRC   uint32 // 当前未满分片的校验和
+	chunkFill  int    // 当前分片已写入的字节数
+
+	done bool
+}
+
+// OpenBlockWriter 打开一个数据块写入流
+func (s *LocalStorageService) OpenBlockWriter(blockID uint64) (model.BlockWriter, error) {
+	// 每次写入使用独立的临时文件，避免同一块的并发写入相互覆盖
+	file, err := os.CreateTemp(s.rootDir, fmt.Sprintf("%d.dat.*.tmp", blockID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file for block %d: %w", blockID, err)
+	}
+
+	return &localBlockWriter{
+		storage:    s,
+		blockID:    blockID,
+		file:       file,
+		tempPath:   file.Name(),
+		withChunks: s.chunkChecksums,
+	}, nil
+}
+
+// Write 写入数据并累计校验和
+func (w *localBlockWriter) Write(p []byte) (int, error) {
+	if w.done {
+		return 0, fmt.Errorf("block %d writer already closed", w.blockID)
+	}
+
+	n, err := w.file.Write(p)
+	w.update(p[:n])
+	if err != nil {
+		return n, fmt.Errorf("failed to write data to file: %w", err)
+	}
+	return n, nil
+}
+
+// update 更新整块及分片校验和
+func (w *localBlockWriter) update(p []byte) {
+	w.length += int64(len(p))
+	w.crc = crc32.Update(w.crc, crc32cTable, p)
+
+	if !w.withChunks {
+		return
+	}
+	for len(p) > 0 {
+		n := ChecksumChunkSize - w.chunkFill
+		if n > len(p) {
+			n = len(p)
+		}
+		w.chunkCRC = crc32.Update(w.chunkCRC, crc32cTable, p[:n])
+		w.chunkFill += n
+		p = p[n:]
+
+		if w.chunkFill == ChecksumChunkSize {
+			w.chunkCRCs = append(w.chunkCRCs, w.chunkCRC)
+			w.chunkCRC = 0
+			w.chunkFill = 0
+		}
+	}
+}
+
+// Checksum 返回目前已写入数据的 CRC32C
+func (w *localBlockWriter) Checksum() uint32 {
+	return w.crc
+}
+
+// Commit 完成写入
+func (w *localBlockWriter) Commit() error {
+	if w.done {
+		return fmt.Errorf("block %d writer already closed", w.blockID)
+	}
+	w.done = true
+
+	// 确保数据写入磁盘
+	if err := w.file.Sync(); err != nil {
+		w.file.Close()
+		os.Remove(w.tempPath)
+		return fmt.Errorf("failed to sync file: %w", err)
+	}
+	if err := w.file.Close(); err != nil {
+		os.Remove(w.tempPath)
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
+
+	checksum := &BlockChecksum{
+		CRC32C: w.crc,
+		Length: w.length,
+	}
+	if w.withChunks {
+		checksum.ChunkSize = ChecksumChunkSize
+		checksum.ChunkCRCs = w.chunkCRCs
+		if w.chunkFill > 0 {
+			checksum.ChunkCRCs = append(checksum.ChunkCRCs, w.chunkCRC)
+		}
+	}
+
+	s := w.storage
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 先写入校验文件再重命名数据文件，崩溃后不会留下没有校验文件的块；
+	// 只有校验文件而没有数据文件时块不存在，下次写入会覆盖校验文件
+	if err := writeChecksumFile(s.getChecksumFilePath(w.blockID), checksum); err != nil {
+		os.Remove(w.tempPath)
+		return fmt.Errorf("failed to write checksum for block %d: %w", w.blockID, err)
+	}
+
+	// 原子性重命名
+	filePath := s.getBlockFilePath(w.blockID)
+	if err := os.Rename(w.tempPath, filePath); err != nil {
+		os.Remove(w.tempPath)
+		return fmt.Errorf("failed to rename temp file: %w", err)
+	}
+
+	s.clearCorrupt(w.blockID)
+	return nil
+}
+
+// Abort 放弃写入并清理临时文件
+func (w *localBlockWriter) Abort() error {
+	if w.done {
+		return nil
+	}
+	w.done = true
+
+	w.file.Close()
+	if err := os.Remove(w.tempPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove temp file %s: %w", w.tempPath, err)
+	}
+	return nil
+}
+
+// verifyingBlockReader 流式读取本地数据块，边读边校验
+// 有分片校验和时按分片读取，分片（最后一个分片还包括整块校验和）校验通过后才返回其中的数据；
+// 只有整块校验和时数据边读边返回，整块校验和在读到末尾时检查，失败只能中止读取，调用方需丢弃已读到的数据。
+// 不一致时返回 model.ErrChecksumMismatch 并标记块为损坏
+type verifyingBlockReader struct {
+	storage  *LocalStorageService
+	blockID  uint64
+	file     *os.File
+	size     int64
+	checksum *BlockChecksum // 为 nil 表示不做校验（旧版本写入的块或无法校验的范围读取）
+
+	offset    int64 // 当前文件偏移
+	end       int64 // 读取结束的文件偏移
+	partial   bool  // 只读取部分分片，不检查整块校验和
+	crc       uint32
+	chunkIdx  int
+	chunkCRC  uint32
+	chunkFill int
+
+	chunkBuf []byte // 分片读取缓冲
+	pending  []byte // 已校验、尚未返回的分片数据
+
+	err error // 校验失败后后续读取都返回该错误
+}
+
+// OpenBlockReader 打开一个数据块读取流
+func (s *LocalStorageService) OpenBlockReader(blockID uint64) (model.BlockReader, error) {
+	return s.openVerifyingReader(blockID)
+}
+
+// openVerifyingReader 打开整块读取流
+func (s *LocalStorageService) openVerifyingReader(blockID uint64) (*verifyingBlockReader, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	filePath := s.getBlockFilePath(blockID)
+	file, err := os.Open(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("block %d not found", blockID)
+		}
+		return nil, fmt.Errorf("failed to read block %d: %w", blockID, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to stat block %d: %w", blockID, err)
+	}
+
+	checksum, err := readChecksumFile(s.getChecksumFilePath(blockID))
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read checksum for block %d: %w", blockID, err)
+	}
+
+	return &verifyingBlockReader{
+		storage:  s,
+		blockID:  blockID,
+		file:     file,
+		size:     info.Size(),
+		end:      info.Size(),
+		checksum: checksum,
+	}, nil
+}
+
+// OpenBlockRangeReader 打开数据块中 [offset, offset+length) 范围的读取流，length 为 0 表示读到块末尾
+// 开启分片校验时从分片边界开始读取，并校验范围覆盖到的每个分片；
+// 只有整块校验和的块无法校验部分数据，范围读取时不做校验
+func (s *LocalStorageService) OpenBlockRangeReader(blockID uint64, offset, length int64) (model.BlockReader, error) {
+	if offset < 0 || length < 0 {
+		return nil, fmt.Errorf("invalid range offset=%d length=%d for block %d", offset, length, blockID)
+	}
+
+	r, err := s.openVerifyingReader(blockID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 读取整块时直接使用整块校验
+	if offset == 0 && (length == 0 || length >= r.size) {
+		return r, nil
+	}
+
+	if offset > r.size {
+		r.Close()
+		return nil, fmt.Errorf("offset %d beyond block %d size %d", offset, blockID, r.size)
+	}
+
+	end := r.size
+	if length > 0 && offset+length < end {
+		end = offset + length
+	}
+
+	// 按分片边界扩展实际读取范围
+	start := offset
+	c := r.checksum
+	if c != nil && c.ChunkSize > 0 && len(c.ChunkCRCs) > 0 {
+		chunkSize := int64(c.ChunkSize)
+		start = offset - offset%chunkSize
+		if alignedEnd := (end + chunkSize - 1) / chunkSize * chunkSize; alignedEnd < r.end {
+			r.end = alignedEnd
+		}
+		r.chunkIdx = int(start / chunkSize)
+		r.partial = true
+	} else {
+		r.checksum = nil
+		r.end = end
+	}
+
+	if _, err := r.file.Seek(start, io.SeekStart); err != nil {
+		r.Close()
+		return nil, fmt.Errorf("failed to seek block %d to %d: %w", blockID, start, err)
+	}
+	r.offset = start
+
+	return &rangeBlockReader{
+		verifyingBlockReader: r,
+		skip:                 offset - start,
+		length:               end - offset,
+		remaining:            end - offset,
+	}, nil
+}
+
+// Read 读取数据并校验
+// 校验失败时不返回本次读取的数据，有分片校验和时损坏的分片不会返回给调用方
+func (r *verifyingBlockReader) Read(p []byte) (int, error) {
+	if len(r.pending) > 0 {
+		n := copy(p, r.pending)
+		r.pending = r.pending[n:]
+		return n, nil
+	}
+	if r.err != nil {
+		return 0, r.err
+	}
+
+	if r.offset >= r.end {
+		r.err = io.EOF
+		if r.checksum != nil {
+			if verr := r.finish(); verr != nil {
+				r.err = verr
+			}
+		}
+		return 0, r.err
+	}
+
+	if r.chunked() {
+		return r.readChunk(p)
+	}
+
+	if remaining := r.end - r.offset; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+
+	n, err := r.file.Read(p)
+	if r.checksum == nil {
+		r.offset += int64(n)
+		return n, err
+	}
+
+	if verr := r.update(p[:n]); verr != nil {
+		r.err = verr
+		return 0, verr
+	}
+
+	if err == io.EOF {
+		// 文件比打开时短
+		if verr := r.finish(); verr != nil {
+			r.err = verr
+			return 0, verr
+		}
+	}
+	return n, err
+}
+
+// chunked 是否按分片校验
+func (r *verifyingBlockReader) chunked() bool {
+	return r.checksum != nil && r.checksum.ChunkSize > 0 && len(r.checksum.ChunkCRCs) > 0
+}
+
+// readChunk 读取并校验下一个分片，校验通过后从分片缓冲返回数据
+// 读取从分片边界开始，每次读取一个完整分片（块末尾的分片可能不满）
+func (r *verifyingBlockReader) readChunk(p []byte) (int, error) {
+	if r.chunkBuf == nil {
+		r.chunkBuf = make([]byte, r.checksum.ChunkSize)
+	}
+	buf := r.chunkBuf[:min(int64(r.checksum.ChunkSize-r.chunkFill), r.end-r.offset)]
+
+	n, err := io.ReadFull(r.file, buf)
+	if verr := r.update(buf[:n]); verr != nil {
+		r.err = verr
+		return 0, verr
+	}
+	if err != nil {
+		if err != io.EOF && err != io.ErrUnexpectedEOF {
+			r.err = err
+			return 0, err
+		}
+		// 文件比打开时短
+		r.err = r.finish()
+		if r.err == nil {
+			r.err = io.ErrUnexpectedEOF
+		}
+		return 0, r.err
+	}
+
+	if r.offset >= r.end {
+		// 最后一个分片不满时尚未比对，整块校验和也在返回数据前检查
+		if verr := r.finish(); verr != nil {
+			r.err = verr
+			return 0, verr
+		}
+		r.err = io.EOF
+	}
+
+	r.pending = buf
+	n = copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// update 累计校验和，分片读满时立即比对
+func (r *verifyingBlockReader) update(p []byte) error {
+	if !r.partial {
+		r.crc = crc32.Update(r.crc, crc32cTable, p)
+	}
+
+	c := r.checksum
+	if c.ChunkSize <= 0 || len(c.ChunkCRCs) == 0 {
+		r.offset += int64(len(p))
+		return nil
+	}
+
+	for len(p) > 0 {
+		n := c.ChunkSize - r.chunkFill
+		if n > len(p) {
+			n = len(p)
+		}
+		r.chunkCRC = crc32.Update(r.chunkCRC, crc32cTable, p[:n])
+		r.chunkFill += n
+		r.offset += int64(n)
+		p = p[n:]
+
+		if r.chunkFill == c.ChunkSize {
+			if err := r.checkChunk(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkChunk 比对当前分片的校验和
+func (r *verifyingBlockReader) checkChunk() error {
+	c := r.checksum
+	chunkStart := r.offset - int64(r.chunkFill)
+	if r.chunkIdx >= len(c.ChunkCRCs) || r.chunkCRC != c.ChunkCRCs[r.chunkIdx] {
+		return r.corrupt(fmt.Errorf("block %d chunk %d (offset %d): %w", r.blockID, r.chunkIdx, chunkStart, model.ErrChecksumMismatch))
+	}
+	r.chunkIdx++
+	r.chunkCRC = 0
+	r.chunkFill = 0
+	return nil
+}
+
+// finish 读到末尾时检查长度、最后一个分片和整块校验和
+func (r *verifyingBlockReader) finish() error {
+	c := r.checksum
+	if r.partial {
+		if r.offset != r.end {
+			return r.corrupt(fmt.Errorf("block %d truncated at %d, expected %d: %w", r.blockID, r.offset, r.end, model.ErrChecksumMismatch))
+		}
+		if r.chunkFill > 0 {
+			return r.checkChunk()
+		}
+		return nil
+	}
+
+	if r.offset != c.Length {
+		return r.corrupt(fmt.Errorf("block %d length %d, expected %d: %w", r.blockID, r.offset, c.Length, model.ErrChecksumMismatch))
+	}
+	if r.chunkFill > 0 {
+		if err := r.checkChunk(); err != nil {
+			return err
+		}
+	}
+	if r.crc != c.CRC32C {
+		return r.corrupt(fmt.Errorf("block %d: %w", r.blockID, model.ErrChecksumMismatch))
+	}
+	return nil
+}
+
+// corrupt 标记块为损坏并返回错误
+func (r *verifyingBlockReader) corrupt(err error) error {
+	r.storage.markCorrupt(r.blockID)
+	return err
+}
+
+// Size 返回块大小
+func (r *verifyingBlockReader) Size() int64 {
+	return r.size
+}
+
+// Checksum 返回保存的整块 CRC32C，旧版本写入的块没有校验和
+func (r *verifyingBlockReader) Checksum() (uint32, bool) {
+	if r.checksum == nil {
+		return 0, false
+	}
+	return r.checksum.CRC32C, true
+}
+
+// Close 关闭文件
+func (r *verifyingBlockReader) Close() error {
+	return r.file.Close()
+}
+
+// rangeBlockReader 范围读取流，丢弃分片对齐带来的多余数据
+type rangeBlockReader struct {
+	*verifyingBlockReader
+	skip      int64 // 范围起点之前需要丢弃的字节数
+	length    int64 // 范围长度
+	remaining int64 // 范围内剩余未返回的字节数
+}
+
+// Read 读取范围内的数据
+// 范围的最后一部分返回前会先读完所在分片并完成校验
+func (r *rangeBlockReader) Read(p []byte) (int, error) {
+	if r.skip > 0 {
+		if _, err := io.CopyN(io.Discard, r.verifyingBlockReader, r.skip); err != nil {
+			return 0, err
+		}
+		r.skip = 0
+	}
+
+	if r.remaining <= 0 {
+		return 0, io.EOF
+	}
+
+	if int64(len(p)) > r.remaining {
+		p = p[:r.remaining]
+	}
+
+	n, err := r.verifyingBlockReader.Read(p)
+	r.remaining -= int64(n)
+	if err != nil && err != io.EOF {
+		return 0, err
+	}
+
+	if r.remaining <= 0 {
+		// 读完尾部分片的剩余部分以完成校验
+		if _, derr := io.Copy(io.Discard, r.verifyingBlockReader); derr != nil {
+			return 0, derr
+		}
+		return n, nil
+	}
+	if n == 0 && err == io.EOF {
+		return 0, io.ErrUnexpectedEOF
+	}
+	return n, nil
+}
+
+// Size 返回范围长度
+func (r *rangeBlockReader) Size() int64 {
+	return r.length
+}
+
+// Checksum 范围读取不携带整块校验和
+func (r *rangeBlockReader) Checksum() (uint32, bool) {
+	return 0, false
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"os"
+	"testing"
+
+	"dataServer/internal/model"
+)
+
+// readUntilError 按小缓冲区读取，返回出错前读到的所有数据
+func readUntilError(r io.Reader) ([]byte, error) {
+	var out []byte
+	buf := make([]byte, 1000)
+	for {
+		n, err := r.Read(buf)
+		out = append(out, buf[:n]...)
+		if err != nil {
+			return out, err
+		}
+	}
+}
+
+func TestVerifyingReaderWithholdsCorruptChunk(t *testing.T) {
+	storage, err := NewStorageService(t.TempDir(), true)
+	if err != nil {
+		t.Fatalf("new storage: %v", err)
+	}
+	data := make([]byte, 3*ChecksumChunkSize+100)
+	rand.New(rand.NewSource(1)).Read(data)
+	if err := storage.WriteBlock(1, data); err != nil {
+		t.Fatalf("write block: %v", err)
+	}
+
+	got, err := storage.ReadBlock(1)
+	if err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("read intact block: %d bytes, err=%v", len(got), err)
+	}
+
+	// 损坏第二个分片中间的一个字节
+	corruptAt := int64(ChecksumChunkSize + 10)
+	file, err := os.OpenFile(storage.getBlockFilePath(1), os.O_RDWR, 0)
+	if err != nil {
+		t.Fatalf("open block file: %v", err)
+	}
+	if _, err := file.WriteAt([]byte{data[corruptAt] ^ 0xff}, corruptAt); err != nil {
+		t.Fatalf("corrupt block file: %v", err)
+	}
+	file.Close()
+
+	reader, err := storage.OpenBlockReader(1)
+	if err != nil {
+		t.Fatalf("open reader: %v", err)
+	}
+	out, err := readUntilError(reader)
+	reader.Close()
+	if !errors.Is(err, model.ErrChecksumMismatch) {
+		t.Fatalf("read corrupt block: err=%v, want ErrChecksumMismatch", err)
+	}
+	// 只返回损坏分片之前已校验的分片
+	if len(out) != ChecksumChunkSize || !bytes.Equal(out, data[:ChecksumChunkSize]) {
+		t.Fatalf("returned %d bytes before the error, want the first %d verified bytes", len(out), ChecksumChunkSize)
+	}
+	if !storage.isCorrupt(1) {
+		t.Error("block not marked corrupt")
+	}
+
+	// 范围读取只校验覆盖到的分片：第三个分片可以读出，跨越损坏分片的范围只返回前一个分片中的数据
+	reader, err = storage.OpenBlockRangeReader(1, 2*ChecksumChunkSize+5, 200)
+	if err != nil {
+		t.Fatalf("open range reader: %v", err)
+	}
+	out, err = io.ReadAll(reader)
+	reader.Close()
+	if err != nil || !bytes.Equal(out, data[2*ChecksumChunkSize+5:2*ChecksumChunkSize+205]) {
+		t.Fatalf("range read of intact chunk: %d bytes, err=%v", len(out), err)
+	}
+
+	reader, err = storage.OpenBlockRangeReader(1, ChecksumChunkSize-50, 100)
+	if err != nil {
+		t.Fatalf("open range reader: %v", err)
+	}
+	out, err = readUntilError(reader)
+	reader.Close()
+	if !errors.Is(err, model.ErrChecksumMismatch) || len(out) != 50 {
+		t.Fatalf("range read across corrupt chunk: %d bytes, err=%v", len(out), err)
+	}
+}
+
+func TestRangeReaderAlignsToChunks(t *testing.T) {
+	data := make([]byte, 3*ChecksumChunkSize+100)
+	rand.New(rand.NewSource(2)).Read(data)
+	size := int64(len(data))
+
+	// 有分片校验时按分片边界扩展读取范围，没有时直接定位，两种情况都只返回请求的字节
+	for _, chunkChecksums := range []bool{true, false} {
+		storage, err := NewStorageService(t.TempDir(), chunkChecksums)
+		if err != nil {
+			t.Fatalf("new storage: %v", err)
+		}
+		if err := storage.WriteBlock(1, data); err != nil {
+			t.Fatalf("write block: %v", err)
+		}
+
+		for _, c := range []struct{ offset, length int64 }{
+			{0, 10},
+			{ChecksumChunkSize - 1, 2},
+			{ChecksumChunkSize, ChecksumChunkSize},
+			{ChecksumChunkSize + 7, 2*ChecksumChunkSize + 50},
+			{3*ChecksumChunkSize + 50, 0},
+			{size - 1, 100},
+			{size, 10},
+		} {
+			reader, err := storage.OpenBlockRangeReader(1, c.offset, c.length)
+			if err != nil {
+				t.Fatalf("open range reader (offset=%d, length=%d): %v", c.offset, c.length, err)
+			}
+			out, err := io.ReadAll(reader)
+			reader.Close()
+
+			end := size
+			if c.length > 0 && c.offset+c.length < end {
+				end = c.offset + c.length
+			}
+			if err != nil || !bytes.Equal(out, data[c.offset:end]) {
+				t.Errorf("chunk checksums=%v, range read (offset=%d, length=%d): %d bytes, err=%v, want %d bytes",
+					chunkChecksums, c.offset, c.length, len(out), err, end-c.offset)
+			}
+		}
+
+		if _, err := storage.OpenBlockRangeReader(1, size+1, 10); err == nil {
+			t.Errorf("chunk checksums=%v: range read beyond the block accepted", chunkChecksums)
+		}
+	}
+}
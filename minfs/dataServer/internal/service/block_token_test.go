@@ -0,0 +1,46 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBlockToken(t *testing.T) {
+	tokens := NewBlockTokenManager("secret", time.Minute)
+
+	token := tokens.Sign(42, "rw")
+	if err := tokens.Verify(token, 42, "r"); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if err := tokens.Verify(token, 42, "w"); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := tokens.Verify(token, 42, "d"); err == nil {
+		t.Errorf("delete allowed by rw token")
+	}
+	if err := tokens.Verify(token, 43, "r"); err == nil {
+		t.Errorf("token accepted for another block")
+	}
+	if err := tokens.Verify("", 42, "r"); err == nil {
+		t.Errorf("missing token accepted")
+	}
+
+	// 篡改操作或使用其他密钥签发都会导致签名不匹配
+	if err := tokens.Verify("42:rwd"+token[len("42:rw"):], 42, "d"); err == nil {
+		t.Errorf("tampered token accepted")
+	}
+	if err := tokens.Verify(NewBlockTokenManager("other", time.Minute).Sign(42, "r"), 42, "r"); err == nil {
+		t.Errorf("token signed with another key accepted")
+	}
+
+	expired := &BlockTokenManager{key: []byte("secret"), lifetime: -time.Second}
+	if err := tokens.Verify(expired.Sign(42, "r"), 42, "r"); err == nil {
+		t.Errorf("expired token accepted")
+	}
+
+	// 未配置密钥时不校验
+	var disabled *BlockTokenManager
+	if err := disabled.Verify("", 42, "d"); err != nil {
+		t.Errorf("disabled manager rejected request: %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+
+	"dataServer/internal/model"
+	"dataServer/pb"
+)
+
+// ChecksumChunkSize 分片校验和的粒度，与 ReadBlock 的发送分片大小一致
+const ChecksumChunkSize = 64 * 1024
+
+// crc32cTable Castagnoli 多项式表
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// BlockChecksum 数据块的校验信息，以 JSON 形式保存在 <blockID>.meta 文件中
+type BlockChecksum struct {
+	CRC32C    uint32   `json:"crc32c"`               // 整块 CRC32C
+	Length    int64    `json:"length"`               // 数据长度
+	ChunkSize int      `json:"chunk_size,omitempty"` // 分片大小，0 表示未开启分片校验
+	ChunkCRCs []uint32 `json:"chunk_crcs,omitempty"` // 每个分片的 CRC32C
+}
+
+// ComputeCRC32C 计算数据的 CRC32C
+func ComputeCRC32C(data []byte) uint32 {
+	return crc32.Checksum(data, crc32cTable)
+}
+
+// readChecksumFile 读取校验文件，文件不存在时返回 nil（兼容旧版本写入的块）
+func readChecksumFile(path string) (*BlockChecksum, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var checksum BlockChecksum
+	if err := json.Unmarshal(data, &checksum); err != nil {
+		return nil, fmt.Errorf("failed to parse checksum file %s: %w", path, err)
+	}
+	return &checksum, nil
+}
+
+// writeChecksumFile 原子性写入校验文件，返回前落盘
+func writeChecksumFile(path string, checksum *BlockChecksum) error {
+	data, err := json.Marshal(checksum)
+	if err != nil {
+		return err
+	}
+
+	tempFile := path + ".tmp"
+	file, err := os.OpenFile(tempFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		os.Remove(tempFile)
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		os.Remove(tempFile)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tempFile)
+		return err
+	}
+	if err := os.Rename(tempFile, path); err != nil {
+		os.Remove(tempFile)
+		return err
+	}
+	return nil
+}
+
+// receiveBlockStream 接收其他节点 ReadBlock 流中的数据并写入 w，边接收边计算校验和
+// 第一个消息携带发送方保存的整块 CRC32C，has_crc32c 为 false 表示对端没有校验和（旧版本写入的块），此时跳过校验
+func receiveBlockStream(blockID uint64, stream pb.DataServerService_ReadBlockClient, w io.Writer) (int64, error) {
+	var received int64
+	var expected, actual uint32
+	var verify bool
+	first := true
+
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return received, fmt.Errorf("failed to receive data chunk: %w", err)
+		}
+
+		if first {
+			expected, verify = resp.Crc32C, resp.HasCrc32C
+			first = false
+		}
+
+		chunk := resp.ChunkData
+		if _, err := w.Write(chunk); err != nil {
+			return received, fmt.Errorf("failed to write data chunk: %w", err)
+		}
+		actual = crc32.Update(actual, crc32cTable, chunk)
+		received += int64(len(chunk))
+	}
+
+	// 端到端校验，避免把损坏的数据复制到本地
+	if verify && actual != expected {
+		return received, fmt.Errorf("block %d transfer crc32c %08x, expected %08x: %w", blockID, actual, expected, model.ErrChecksumMismatch)
+	}
+	return received, nil
+}
@@ -15,7 +15,6 @@ import (
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.etcd.io/etcd/client/v3/concurrency"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 // EtcdClusterService etcd集群服务实现
@@ -24,6 +23,9 @@ type EtcdClusterService struct {
 	etcdClient     *clientv3.Client
 	metaClient     *grpc.ClientConn
 	storageService model.StorageService
+	erasureService model.ErasureService
+	dialOption     grpc.DialOption         // 连接 MetaServer 和其他 DataServer 的传输凭证
+	blockTokens    model.BlockTokenService // 从其他 DataServer 复制块时自签读取令牌
 
 	// 租约管理
 	lease   clientv3.Lease
@@ -40,7 +42,12 @@ type EtcdClusterService struct {
 }
 
 // NewClusterService 创建集群服务实例
-func NewClusterService(config *model.Config, storageService model.StorageService) (*EtcdClusterService, error) {
+func NewClusterService(config *model.Config, storageService model.StorageService, erasureService model.ErasureService, blockTokens model.BlockTokenService) (*EtcdClusterService, error) {
+	dialOption, err := DialOption(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tls credentials: %w", err)
+	}
+
 	// 创建etcd客户端
 	etcdClient, err := clientv3.New(clientv3.Config{
 		Endpoints:   config.Etcd.Endpoints,
@@ -62,9 +69,7 @@ func NewClusterService(config *model.Config, storageService model.StorageService
 		time.Duration(config.MetaServer.ConnectionTimeout)*time.Second)
 	defer cancel()
 
-	metaConn, err := grpc.DialContext(ctx, leader,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+	metaConn, err := grpc.DialContext(ctx, leader, dialOption)
 	if err != nil {
 		etcdClient.Close()
 		return nil, fmt.Errorf("failed to connect to leader metaServer %s: %w", leader, err)
@@ -75,6 +80,9 @@ func NewClusterService(config *model.Config, storageService model.StorageService
 		etcdClient:     etcdClient,
 		metaClient:     metaConn,
 		storageService: storageService,
+		erasureService: erasureService,
+		dialOption:     dialOption,
+		blockTokens:    blockTokens,
 		lease:          clientv3.NewLease(etcdClient),
 		stopChan:       make(chan struct{}),
 		leaderStopChan: make(chan struct{}),
@@ -87,6 +95,14 @@ func NewClusterService(config *model.Config, storageService model.StorageService
 	return service, nil
 }
 
+// dataServerRegistration 注册到etcd的信息，与metaServer的 DataServerRegistration 对应
+type dataServerRegistration struct {
+	ID         string    `json:"id"`
+	Addr       string    `json:"addr"`
+	RegisterAt time.Time `json:"register_at"`
+	Topology   string    `json:"topology"`
+}
+
 // RegisterToETCD 在etcd中注册本服务
 func (s *EtcdClusterService) RegisterToETCD() error {
 	ctx := context.Background()
@@ -102,9 +118,17 @@ func (s *EtcdClusterService) RegisterToETCD() error {
 
 	// 注册服务key - 使用与metaServer配置匹配的前缀
 	key := fmt.Sprintf("/dfs/dataServers/%s", s.config.Server.DataserverId)
-	value := fmt.Sprintf("%s", s.config.Server.ListenAddress)
+	value, err := json.Marshal(dataServerRegistration{
+		ID:         s.config.Server.DataserverId,
+		Addr:       s.config.Server.ListenAddress,
+		RegisterAt: time.Now(),
+		Topology:   s.config.Server.Topology,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal registration: %w", err)
+	}
 
-	_, err = s.etcdClient.Put(ctx, key, value, clientv3.WithLease(s.leaseID))
+	_, err = s.etcdClient.Put(ctx, key, string(value), clientv3.WithLease(s.leaseID))
 	if err != nil {
 		return fmt.Errorf("failed to register service: %w", err)
 	}
@@ -203,9 +227,7 @@ func (s *EtcdClusterService) handleLeaderChange() error {
 		time.Duration(s.config.MetaServer.ConnectionTimeout)*time.Second)
 	defer cancel()
 
-	newConn, err := grpc.DialContext(ctx, newLeader,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+	newConn, err := grpc.DialContext(ctx, newLeader, s.dialOption)
 	if err != nil {
 		return fmt.Errorf("failed to connect to new leader %s: %w", newLeader, err)
 	}
@@ -281,14 +303,7 @@ func (s *EtcdClusterService) sendHeartbeat() error {
 	client := NewMetaServerServiceClient(s.metaClient)
 
 	// 构建心跳请求
-	req := &pb.HeartbeatRequest{
-		DataserverId:   s.config.Server.DataserverId,
-		DataserverAddr: s.config.Server.ListenAddress,
-		BlockCount:     stat.BlockCount,
-		FreeSpace:      stat.FreeSpace,
-		BlockIdsReport: stat.BlockIds,
-		TotalCapacity:  stat.TotalCapacity,
-	}
+	req := newHeartbeatRequest(s.config, stat)
 
 	// 打印心跳请求数据到控制台
 	log.Printf("📡 [HEARTBEAT REQUEST] DataServer: %s", req.DataserverId)
@@ -304,6 +319,9 @@ func (s *EtcdClusterService) sendHeartbeat() error {
 	} else {
 		log.Printf("    └── Block IDs: [] (no blocks stored)")
 	}
+	if len(req.CorruptBlockIds) > 0 {
+		log.Printf("    └── Corrupt Block IDs: %v", req.CorruptBlockIds)
+	}
 
 	// 发送心跳
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -338,6 +356,10 @@ func (s *EtcdClusterService) sendHeartbeat() error {
 				actionName = "DELETE_BLOCK"
 			case pb.Command_COPY_BLOCK:
 				actionName = "COPY_BLOCK"
+			case pb.Command_RECONSTRUCT_STRIPE:
+				actionName = "RECONSTRUCT_STRIPE"
+			case pb.Command_ENCODE_BLOCK:
+				actionName = "ENCODE_BLOCK"
 			}
 			log.Printf("    └── Command %d: %s (Block ID: %d)", i+1, actionName, cmd.BlockId)
 			if len(cmd.Targets) > 0 {
@@ -352,6 +374,35 @@ func (s *EtcdClusterService) sendHeartbeat() error {
 	return nil
 }
 
+// newHeartbeatRequest 由存储统计构建心跳请求，损坏的块只出现在 CorruptBlockIds 中
+func newHeartbeatRequest(config *model.Config, stat *model.StorageStat) *pb.HeartbeatRequest {
+	return &pb.HeartbeatRequest{
+		DataserverId:    config.Server.DataserverId,
+		DataserverAddr:  config.Server.ListenAddress,
+		BlockCount:      stat.BlockCount,
+		FreeSpace:       stat.FreeSpace,
+		BlockIdsReport:  stat.BlockIds,
+		TotalCapacity:   stat.TotalCapacity,
+		CorruptBlockIds: stat.CorruptBlockIds,
+		ScrubStats:      scrubStatsToPB(stat.Scrub),
+		Topology:        config.Server.Topology,
+	}
+}
+
+// scrubStatsToPB 将巡检统计转换为心跳中的格式
+func scrubStatsToPB(stats model.ScrubStats) *pb.ScrubStats {
+	var lastPassTime int64
+	if !stats.LastPassTime.IsZero() {
+		lastPassTime = stats.LastPassTime.UnixMilli()
+	}
+	return &pb.ScrubStats{
+		BlocksScanned: stats.BlocksScanned,
+		CorruptBlocks: stats.CorruptBlocks,
+		LastPassTime:  lastPassTime,
+		BytesScanned:  stats.BytesScanned,
+	}
+}
+
 // processCommands 处理来自metaServer的命令
 func (s *EtcdClusterService) processCommands(commands []*pb.Command) {
 	for _, cmd := range commands {
@@ -368,13 +419,34 @@ func (s *EtcdClusterService) processCommand(cmd *pb.Command) error {
 		return s.processDeleteCommand(cmd.BlockId)
 
 	case pb.Command_COPY_BLOCK:
-		return s.processReplicateCommand(cmd.BlockId, cmd.Targets)
+		return s.processReplicateCommand(cmd.BlockId, cmd.Targets, cmd.Bandwidth)
+
+	case pb.Command_RECONSTRUCT_STRIPE:
+		log.Printf("Processing reconstruct command for stripe %d of group %d", cmd.StripeIndex, cmd.GetGroup().GetBlockId())
+		return s.erasureService.ReconstructStripe(blockGroupFromPB(cmd.Group), int(cmd.StripeIndex))
+
+	case pb.Command_ENCODE_BLOCK:
+		log.Printf("Processing encode command for block %d into group %d", cmd.BlockId, cmd.GetGroup().GetBlockId())
+		return s.erasureService.EncodeBlock(cmd.BlockId, blockGroupFromPB(cmd.Group))
 
 	default:
 		return fmt.Errorf("unknown command action: %d", cmd.Action)
 	}
 }
 
+// blockGroupFromPB 将metaServer下发的块位置转换为DataServer的块组
+func blockGroupFromPB(block *pb.BlockLocations) *pb.BlockGroup {
+	if block == nil {
+		return nil
+	}
+	return &pb.BlockGroup{
+		GroupId:   block.BlockId,
+		EcPolicy:  block.EcPolicy,
+		StripeIds: block.StripeIds,
+		Locations: block.Locations,
+	}
+}
+
 // processDeleteCommand 处理删除块命令
 func (s *EtcdClusterService) processDeleteCommand(blockID uint64) error {
 	log.Printf("Processing delete command for block %d", blockID)
@@ -388,7 +460,7 @@ func (s *EtcdClusterService) processDeleteCommand(blockID uint64) error {
 }
 
 // processReplicateCommand 处理复制块命令 - 从源地址复制数据到本地
-func (s *EtcdClusterService) processReplicateCommand(blockID uint64, targets []string) error {
+func (s *EtcdClusterService) processReplicateCommand(blockID uint64, targets []string, bandwidth uint64) error {
 	if len(targets) == 0 {
 		return fmt.Errorf("no source address provided for block %d replication", blockID)
 	}
@@ -397,7 +469,7 @@ func (s *EtcdClusterService) processReplicateCommand(blockID uint64, targets []s
 	log.Printf("Processing replicate command for block %d from source: %s", blockID, sourceAddr)
 
 	// 连接到源DataServer
-	conn, err := grpc.Dial(sourceAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.Dial(sourceAddr, s.dialOption)
 	if err != nil {
 		return fmt.Errorf("failed to connect to source %s: %w", sourceAddr, err)
 	}
@@ -408,6 +480,7 @@ func (s *EtcdClusterService) processReplicateCommand(blockID uint64, targets []s
 	// 从源地址读取块数据
 	req := &pb.ReadBlockRequest{
 		BlockId: blockID,
+		Token:   s.blockTokens.Sign(blockID, "r"),
 	}
 
 	stream, err := client.ReadBlock(context.Background(), req)
@@ -415,24 +488,29 @@ func (s *EtcdClusterService) processReplicateCommand(blockID uint64, targets []s
 		return fmt.Errorf("failed to read block %d from source %s: %w", blockID, sourceAddr, err)
 	}
 
-	var blockData []byte
-	for {
-		resp, err := stream.Recv()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return fmt.Errorf("failed to receive block data: %w", err)
-		}
-		blockData = append(blockData, resp.ChunkData...)
+	// 边接收边写入本地临时文件，校验通过后再提交
+	writer, err := s.storageService.OpenBlockWriter(blockID)
+	if err != nil {
+		return fmt.Errorf("failed to open block %d for writing: %w", blockID, err)
+	}
+
+	// 均衡器迁移块时限制复制带宽，避免影响正常读写
+	var dst io.Writer = writer
+	if bandwidth > 0 {
+		dst = &throttledWriter{w: writer, rate: bandwidth, start: time.Now()}
 	}
 
-	// 将数据写入本地存储
-	if err := s.storageService.WriteBlock(blockID, blockData); err != nil {
+	received, err := receiveBlockStream(blockID, stream, dst)
+	if err != nil {
+		writer.Abort()
+		return fmt.Errorf("failed to replicate block %d from source %s: %w", blockID, sourceAddr, err)
+	}
+
+	if err := writer.Commit(); err != nil {
 		return fmt.Errorf("failed to write block %d locally: %w", blockID, err)
 	}
 
-	log.Printf("Successfully replicated block %d from %s (%d bytes)", blockID, sourceAddr, len(blockData))
+	log.Printf("Successfully replicated block %d from %s (%d bytes)", blockID, sourceAddr, received)
 	return nil
 }
 
@@ -527,9 +605,7 @@ func (s *EtcdClusterService) reconnectToLeader() error {
 		time.Duration(s.config.MetaServer.ConnectionTimeout)*time.Second)
 	defer cancel()
 
-	newConn, err := grpc.DialContext(ctx, newLeader,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+	newConn, err := grpc.DialContext(ctx, newLeader, s.dialOption)
 	if err != nil {
 		return fmt.Errorf("failed to connect to new leader %s: %w", newLeader, err)
 	}
@@ -604,3 +680,23 @@ func (s *EtcdClusterService) DeregisterFromETCD() error {
 	log.Printf("DataServer %s successfully deregistered from etcd", s.config.Server.DataserverId)
 	return nil
 }
+
+// throttledWriter 按 rate 字节/秒限速写入，超前时休眠
+type throttledWriter struct {
+	w       io.Writer
+	rate    uint64
+	start   time.Time
+	written uint64
+}
+
+func (t *throttledWriter) Write(p []byte) (int, error) {
+	n, err := t.w.Write(p)
+	t.written += uint64(n)
+
+	// 按已写字节数计算应耗费的最短时间
+	expected := time.Duration(float64(t.written) / float64(t.rate) * float64(time.Second))
+	if wait := expected - time.Since(t.start); wait > 0 {
+		time.Sleep(wait)
+	}
+	return n, err
+}
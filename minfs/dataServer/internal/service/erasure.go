@@ -0,0 +1,311 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrTooFewShards 可用分片少于数据分片数，无法解码
+var ErrTooFewShards = errors.New("too few shards to reconstruct")
+
+// GF(2^8) 运算表，本原多项式 x^8+x^4+x^3+x^2+1
+var (
+	gfExp [510]byte
+	gfLog [256]int
+)
+
+func init() {
+	x := 1
+	for i := 0; i < 255; i++ {
+		gfExp[i] = byte(x)
+		gfLog[x] = i
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= 0x11d
+		}
+	}
+	for i := 255; i < len(gfExp); i++ {
+		gfExp[i] = gfExp[i-255]
+	}
+}
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[gfLog[a]+gfLog[b]]
+}
+
+func gfInv(a byte) byte {
+	return gfExp[255-gfLog[a]]
+}
+
+// gfPow 计算 a^n，约定 0^0 = 1
+func gfPow(a byte, n int) byte {
+	if n == 0 {
+		return 1
+	}
+	if a == 0 {
+		return 0
+	}
+	return gfExp[(gfLog[a]*n)%255]
+}
+
+// ParseECPolicy 解析 RS-<k>-<m> 形式的纠删码策略名
+func ParseECPolicy(name string) (int, int, error) {
+	parts := strings.Split(name, "-")
+	if len(parts) != 3 || parts[0] != "RS" {
+		return 0, 0, fmt.Errorf("invalid erasure coding policy %q, want RS-<data>-<parity>", name)
+	}
+	k, err1 := strconv.Atoi(parts[1])
+	m, err2 := strconv.Atoi(parts[2])
+	if err1 != nil || err2 != nil || k < 1 || m < 1 || k+m > 255 {
+		return 0, 0, fmt.Errorf("invalid erasure coding policy %q", name)
+	}
+	return k, m, nil
+}
+
+// ReedSolomon 系统 Reed-Solomon 编码器，k 个数据分片和 m 个校验分片
+type ReedSolomon struct {
+	dataShards   int
+	parityShards int
+	matrix       [][]byte // (k+m) x k 编码矩阵，前 k 行为单位阵
+}
+
+// NewReedSolomon 创建编码器，任意 k 个分片都可以恢复全部数据
+func NewReedSolomon(dataShards, parityShards int) (*ReedSolomon, error) {
+	if dataShards < 1 || parityShards < 1 || dataShards+parityShards > 255 {
+		return nil, fmt.Errorf("invalid shard counts %d+%d", dataShards, parityShards)
+	}
+	total := dataShards + parityShards
+
+	// 范德蒙矩阵乘以其上方 k x k 子矩阵的逆，得到系统形式且保持任意 k 行可逆
+	vandermonde := make([][]byte, total)
+	for r := range vandermonde {
+		vandermonde[r] = make([]byte, dataShards)
+		for c := range vandermonde[r] {
+			vandermonde[r][c] = gfPow(byte(r), c)
+		}
+	}
+	top, err := invertMatrix(vandermonde[:dataShards])
+	if err != nil {
+		return nil, err
+	}
+	return &ReedSolomon{
+		dataShards:   dataShards,
+		parityShards: parityShards,
+		matrix:       multiplyMatrix(vandermonde, top),
+	}, nil
+}
+
+// NewReedSolomonForPolicy 按策略名创建编码器
+func NewReedSolomonForPolicy(policy string) (*ReedSolomon, error) {
+	k, m, err := ParseECPolicy(policy)
+	if err != nil {
+		return nil, err
+	}
+	return NewReedSolomon(k, m)
+}
+
+// DataShards 数据分片数
+func (r *ReedSolomon) DataShards() int {
+	return r.dataShards
+}
+
+// TotalShards 数据分片与校验分片总数
+func (r *ReedSolomon) TotalShards() int {
+	return r.dataShards + r.parityShards
+}
+
+// Split 将数据切分为 k 个等长数据分片（末尾补零），并为校验分片分配空间
+func (r *ReedSolomon) Split(data []byte) [][]byte {
+	shardSize := (len(data) + r.dataShards - 1) / r.dataShards
+	if shardSize == 0 {
+		shardSize = 1
+	}
+	padded := make([]byte, shardSize*r.TotalShards())
+	copy(padded, data)
+
+	shards := make([][]byte, r.TotalShards())
+	for i := range shards {
+		shards[i] = padded[i*shardSize : (i+1)*shardSize : (i+1)*shardSize]
+	}
+	return shards
+}
+
+// Join 拼接数据分片并截取前 size 字节
+func (r *ReedSolomon) Join(shards [][]byte, size int) ([]byte, error) {
+	data := make([]byte, 0, size)
+	for i := 0; i < r.dataShards && len(data) < size; i++ {
+		if shards[i] == nil {
+			return nil, fmt.Errorf("data shard %d missing", i)
+		}
+		data = append(data, shards[i]...)
+	}
+	if len(data) < size {
+		return nil, fmt.Errorf("shards hold %d bytes, want %d", len(data), size)
+	}
+	return data[:size], nil
+}
+
+// Encode 根据数据分片计算校验分片，所有分片必须等长
+func (r *ReedSolomon) Encode(shards [][]byte) error {
+	if _, err := r.checkShards(shards, false); err != nil {
+		return err
+	}
+	for i := r.dataShards; i < r.TotalShards(); i++ {
+		r.codeShard(r.matrix[i], shards[:r.dataShards], shards[i])
+	}
+	return nil
+}
+
+// Reconstruct 恢复 shards 中为 nil 的分片，至少需要 k 个可用分片
+func (r *ReedSolomon) Reconstruct(shards [][]byte) error {
+	shardSize, err := r.checkShards(shards, true)
+	if err != nil {
+		return err
+	}
+
+	var present []int
+	for i, shard := range shards {
+		if shard != nil {
+			present = append(present, i)
+		}
+	}
+	if len(present) < r.dataShards {
+		return ErrTooFewShards
+	}
+	if len(present) == r.TotalShards() {
+		return nil
+	}
+
+	// 用前 k 个可用分片对应的编码矩阵行求逆，解出缺失的数据分片
+	rows := make([][]byte, r.dataShards)
+	inputs := make([][]byte, r.dataShards)
+	for j, i := range present[:r.dataShards] {
+		rows[j] = r.matrix[i]
+		inputs[j] = shards[i]
+	}
+	decode, err := invertMatrix(rows)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < r.dataShards; i++ {
+		if shards[i] == nil {
+			shards[i] = make([]byte, shardSize)
+			r.codeShard(decode[i], inputs, shards[i])
+		}
+	}
+
+	// 数据分片齐全后重新计算缺失的校验分片
+	for i := r.dataShards; i < r.TotalShards(); i++ {
+		if shards[i] == nil {
+			shards[i] = make([]byte, shardSize)
+			r.codeShard(r.matrix[i], shards[:r.dataShards], shards[i])
+		}
+	}
+	return nil
+}
+
+// checkShards 检查分片数量和长度，返回分片长度
+func (r *ReedSolomon) checkShards(shards [][]byte, allowNil bool) (int, error) {
+	if len(shards) != r.TotalShards() {
+		return 0, fmt.Errorf("got %d shards, want %d", len(shards), r.TotalShards())
+	}
+	size := -1
+	for i, shard := range shards {
+		if shard == nil {
+			if !allowNil {
+				return 0, fmt.Errorf("shard %d missing", i)
+			}
+			continue
+		}
+		if size >= 0 && len(shard) != size {
+			return 0, fmt.Errorf("shard %d has %d bytes, want %d", i, len(shard), size)
+		}
+		size = len(shard)
+	}
+	if size <= 0 {
+		return 0, ErrTooFewShards
+	}
+	return size, nil
+}
+
+// codeShard out = sum(coeffs[c] * inputs[c])
+func (r *ReedSolomon) codeShard(coeffs []byte, inputs [][]byte, out []byte) {
+	for j := range out {
+		out[j] = 0
+	}
+	for c, coeff := range coeffs {
+		if coeff == 0 {
+			continue
+		}
+		logCoeff := gfLog[coeff]
+		for j, b := range inputs[c] {
+			if b != 0 {
+				out[j] ^= gfExp[logCoeff+gfLog[b]]
+			}
+		}
+	}
+}
+
+// multiplyMatrix 矩阵乘法
+func multiplyMatrix(a, b [][]byte) [][]byte {
+	result := make([][]byte, len(a))
+	for r := range a {
+		result[r] = make([]byte, len(b[0]))
+		for c := range result[r] {
+			var v byte
+			for i := range b {
+				v ^= gfMul(a[r][i], b[i][c])
+			}
+			result[r][c] = v
+		}
+	}
+	return result
+}
+
+// invertMatrix 高斯消元求方阵的逆
+func invertMatrix(m [][]byte) ([][]byte, error) {
+	n := len(m)
+	work := make([][]byte, n)
+	for r := range m {
+		work[r] = make([]byte, 2*n)
+		copy(work[r], m[r])
+		work[r][n+r] = 1
+	}
+
+	for c := 0; c < n; c++ {
+		pivot := c
+		for pivot < n && work[pivot][c] == 0 {
+			pivot++
+		}
+		if pivot == n {
+			return nil, errors.New("matrix is singular")
+		}
+		work[c], work[pivot] = work[pivot], work[c]
+
+		if inv := gfInv(work[c][c]); inv != 1 {
+			for j := range work[c] {
+				work[c][j] = gfMul(work[c][j], inv)
+			}
+		}
+		for r := 0; r < n; r++ {
+			if r == c || work[r][c] == 0 {
+				continue
+			}
+			factor := work[r][c]
+			for j := range work[r] {
+				work[r][j] ^= gfMul(factor, work[c][j])
+			}
+		}
+	}
+
+	inverse := make([][]byte, n)
+	for r := range work {
+		inverse[r] = work[r][n:]
+	}
+	return inverse, nil
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"dataServer/internal/model"
+	"dataServer/pb"
+)
+
+// RSErasureService Reed-Solomon 纠删码块组的编码写入、解码读取和条带重建
+type RSErasureService struct {
+	storageService     model.StorageService
+	replicationService model.ReplicationService
+	selfAddr           string // 本节点地址，条带位于本节点时直接读写本地存储
+}
+
+// NewErasureService 创建纠删码服务
+func NewErasureService(storageService model.StorageService, replicationService model.ReplicationService, selfAddr string) *RSErasureService {
+	return &RSErasureService{
+		storageService:     storageService,
+		replicationService: replicationService,
+		selfAddr:           selfAddr,
+	}
+}
+
+// codecFor 检查块组并创建对应的编码器
+func codecFor(group *pb.BlockGroup) (*ReedSolomon, error) {
+	if group == nil {
+		return nil, fmt.Errorf("block group is required")
+	}
+	codec, err := NewReedSolomonForPolicy(group.EcPolicy)
+	if err != nil {
+		return nil, err
+	}
+	if len(group.StripeIds) != codec.TotalShards() || len(group.Locations) != codec.TotalShards() {
+		return nil, fmt.Errorf("block group %d has %d stripes and %d locations, policy %s needs %d",
+			group.GroupId, len(group.StripeIds), len(group.Locations), group.EcPolicy, codec.TotalShards())
+	}
+	return codec, nil
+}
+
+// WriteGroup 将数据编码为 k+m 个条带并写到各自的位置，失败的条带不超过 m 个即视为成功，缺失条带由 FSCK 重建
+func (s *RSErasureService) WriteGroup(group *pb.BlockGroup, data []byte) error {
+	codec, err := codecFor(group)
+	if err != nil {
+		return err
+	}
+	shards := codec.Split(data)
+	if err := codec.Encode(shards); err != nil {
+		return err
+	}
+
+	errs := make([]error, len(shards))
+	var wg sync.WaitGroup
+	for i := range shards {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = s.storeStripe(group.Locations[i], group.StripeIds[i], shards[i])
+		}(i)
+	}
+	wg.Wait()
+
+	var failed int
+	var lastErr error
+	for i, err := range errs {
+		if err != nil {
+			log.Printf("Failed to write stripe %d (block %d) of group %d to %s: %v",
+				i, group.StripeIds[i], group.GroupId, group.Locations[i], err)
+			failed++
+			lastErr = err
+		}
+	}
+	if failed > codec.TotalShards()-codec.DataShards() {
+		return fmt.Errorf("failed to write %d stripes of group %d: %w", failed, group.GroupId, lastErr)
+	}
+	return nil
+}
+
+// ReadGroup 读取块组中 [offset, offset+length) 的数据，优先读取数据条带，缺失时读取校验条带解码
+func (s *RSErasureService) ReadGroup(group *pb.BlockGroup, offset, length uint64) ([]byte, error) {
+	codec, err := codecFor(group)
+	if err != nil {
+		return nil, err
+	}
+
+	shards := make([][]byte, codec.TotalShards())
+	s.fetchStripes(group, shards, 0, codec.DataShards())
+	missing := 0
+	for i := 0; i < codec.DataShards(); i++ {
+		if shards[i] == nil {
+			missing++
+		}
+	}
+	if missing > 0 {
+		log.Printf("Group %d missing %d data stripes, decoding from parity", group.GroupId, missing)
+		s.fetchStripes(group, shards, codec.DataShards(), codec.TotalShards())
+		if err := codec.Reconstruct(shards); err != nil {
+			return nil, fmt.Errorf("failed to decode group %d: %w", group.GroupId, err)
+		}
+	}
+
+	size := uint64(len(shards[0])) * uint64(codec.DataShards())
+	if length == 0 || offset+length > size {
+		return nil, fmt.Errorf("range [%d, %d) out of group %d size %d", offset, offset+length, group.GroupId, size)
+	}
+	data, err := codec.Join(shards, int(offset+length))
+	if err != nil {
+		return nil, err
+	}
+	return data[offset:], nil
+}
+
+// ReconstructStripe 读取其余条带，重建第 index 个条带并写入本地存储
+func (s *RSErasureService) ReconstructStripe(group *pb.BlockGroup, index int) error {
+	codec, err := codecFor(group)
+	if err != nil {
+		return err
+	}
+	if index < 0 || index >= codec.TotalShards() {
+		return fmt.Errorf("stripe index %d out of range for group %d", index, group.GroupId)
+	}
+
+	shards := make([][]byte, codec.TotalShards())
+	s.fetchStripes(group, shards, 0, codec.TotalShards(), index)
+	if err := codec.Reconstruct(shards); err != nil {
+		return fmt.Errorf("failed to reconstruct stripe %d of group %d: %w", index, group.GroupId, err)
+	}
+	if err := s.storageService.WriteBlock(group.StripeIds[index], shards[index]); err != nil {
+		return fmt.Errorf("failed to store stripe %d of group %d: %w", index, group.GroupId, err)
+	}
+	log.Printf("Reconstructed stripe %d (block %d) of group %d", index, group.StripeIds[index], group.GroupId)
+	return nil
+}
+
+// EncodeBlock 将本地的多副本块编码为块组，用于转换为纠删码存储
+func (s *RSErasureService) EncodeBlock(blockID uint64, group *pb.BlockGroup) error {
+	data, err := s.storageService.ReadBlock(blockID)
+	if err != nil {
+		return fmt.Errorf("failed to read block %d for encoding: %w", blockID, err)
+	}
+	if err := s.WriteGroup(group, data); err != nil {
+		return err
+	}
+	log.Printf("Encoded block %d into group %d (%s)", blockID, group.GroupId, group.EcPolicy)
+	return nil
+}
+
+// fetchStripes 并发读取 [from, to) 范围内的条带，跳过 skip 中的下标，读取失败的条带保持为 nil
+func (s *RSErasureService) fetchStripes(group *pb.BlockGroup, shards [][]byte, from, to int, skip ...int) {
+	skipped := make(map[int]bool, len(skip))
+	for _, i := range skip {
+		skipped[i] = true
+	}
+
+	var wg sync.WaitGroup
+	for i := from; i < to; i++ {
+		if skipped[i] {
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			data, err := s.loadStripe(group.Locations[i], group.StripeIds[i])
+			if err != nil {
+				log.Printf("Failed to read stripe %d (block %d) of group %d from %s: %v",
+					i, group.StripeIds[i], group.GroupId, group.Locations[i], err)
+				return
+			}
+			shards[i] = data
+		}(i)
+	}
+	wg.Wait()
+}
+
+// storeStripe 写入一个条带，目标为本节点时直接写本地存储
+func (s *RSErasureService) storeStripe(addr string, blockID uint64, data []byte) error {
+	if addr == s.selfAddr {
+		return s.storageService.WriteBlock(blockID, data)
+	}
+	return s.replicationService.PushBlock(addr, blockID, data)
+}
+
+// loadStripe 读取一个条带，位于本节点时直接读本地存储
+func (s *RSErasureService) loadStripe(addr string, blockID uint64) ([]byte, error) {
+	if addr == s.selfAddr {
+		return s.storageService.ReadBlock(blockID)
+	}
+	return s.replicationService.PullBlock(addr, blockID)
+}
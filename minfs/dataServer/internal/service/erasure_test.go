@@ -0,0 +1,64 @@
+package service
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+func TestReedSolomonReconstruct(t *testing.T) {
+	codec, err := NewReedSolomon(6, 3)
+	if err != nil {
+		t.Fatalf("new codec: %v", err)
+	}
+	data := make([]byte, 100003)
+	rand.New(rand.NewSource(1)).Read(data)
+
+	shards := codec.Split(data)
+	if err := codec.Encode(shards); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	original := make([][]byte, len(shards))
+	for i := range shards {
+		original[i] = append([]byte(nil), shards[i]...)
+	}
+
+	// 丢失任意 m 个分片都能恢复，包括数据分片和校验分片混合丢失
+	for _, lost := range [][]int{{0, 1, 2}, {3, 7, 8}, {6, 7, 8}, {0, 4, 8}} {
+		damaged := make([][]byte, len(original))
+		copy(damaged, original)
+		for _, i := range lost {
+			damaged[i] = nil
+		}
+		if err := codec.Reconstruct(damaged); err != nil {
+			t.Fatalf("reconstruct %v: %v", lost, err)
+		}
+		for i := range damaged {
+			if !bytes.Equal(damaged[i], original[i]) {
+				t.Fatalf("shard %d differs after losing %v", i, lost)
+			}
+		}
+		joined, err := codec.Join(damaged, len(data))
+		if err != nil || !bytes.Equal(joined, data) {
+			t.Fatalf("join after losing %v: %v", lost, err)
+		}
+	}
+
+	damaged := make([][]byte, len(original))
+	copy(damaged, original)
+	damaged[0], damaged[1], damaged[2], damaged[3] = nil, nil, nil, nil
+	if err := codec.Reconstruct(damaged); err != ErrTooFewShards {
+		t.Errorf("reconstruct with %d shards: %v", codec.DataShards()-1, err)
+	}
+}
+
+func TestParseECPolicy(t *testing.T) {
+	if k, m, err := ParseECPolicy("RS-6-3"); err != nil || k != 6 || m != 3 {
+		t.Errorf("RS-6-3 parsed as %d %d %v", k, m, err)
+	}
+	for _, name := range []string{"", "RS-6", "XOR-2-1", "RS-0-2", "RS-3-0", "RS-200-100"} {
+		if _, _, err := ParseECPolicy(name); err == nil {
+			t.Errorf("policy %q accepted", name)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"dataServer/internal/model"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// Metrics DataServer 的 Prometheus 指标，通过 HTTP /metrics 暴露
+type Metrics struct {
+	registry *prometheus.Registry
+
+	rpcDuration  *prometheus.HistogramVec
+	operations   *prometheus.CounterVec
+	bytesRead    prometheus.Counter
+	bytesWritten prometheus.Counter
+}
+
+// NewMetrics 创建指标并注册 Go 运行时和进程指标，磁盘使用情况在抓取时从 storage 读取
+func NewMetrics(storage model.StorageService) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		rpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "minfs",
+			Subsystem: "dataserver",
+			Name:      "rpc_duration_seconds",
+			Help:      "gRPC request latency by method and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "code"}),
+		operations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "minfs",
+			Subsystem: "dataserver",
+			Name:      "operations_total",
+			Help:      "Block operations by type and result.",
+		}, []string{"operation", "status"}),
+		bytesRead: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "minfs",
+			Subsystem: "dataserver",
+			Name:      "bytes_read_total",
+			Help:      "Bytes sent to clients by ReadBlock and ReadBlockGroup.",
+		}),
+		bytesWritten: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "minfs",
+			Subsystem: "dataserver",
+			Name:      "bytes_written_total",
+			Help:      "Bytes committed by WriteBlock, WriteBlockGroup and CopyBlock.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.rpcDuration, m.operations, m.bytesRead, m.bytesWritten,
+	)
+	if storage != nil {
+		m.registry.MustRegister(&storageCollector{storage: storage})
+	}
+	return m
+}
+
+var (
+	diskUsedDesc     = prometheus.NewDesc("minfs_dataserver_disk_used_bytes", "Bytes used by stored blocks.", nil, nil)
+	diskFreeDesc     = prometheus.NewDesc("minfs_dataserver_disk_free_bytes", "Free bytes on the data disk.", nil, nil)
+	diskCapacityDesc = prometheus.NewDesc("minfs_dataserver_disk_capacity_bytes", "Total capacity of the data disk.", nil, nil)
+	blockCountDesc   = prometheus.NewDesc("minfs_dataserver_blocks", "Healthy blocks stored on this DataServer.", nil, nil)
+	corruptDesc      = prometheus.NewDesc("minfs_dataserver_corrupt_blocks", "Blocks that failed checksum verification and await repair.", nil, nil)
+
+	scrubBlocksDesc   = prometheus.NewDesc("minfs_dataserver_scrub_blocks_scanned_total", "Blocks verified by the background scrubber.", nil, nil)
+	scrubBytesDesc    = prometheus.NewDesc("minfs_dataserver_scrub_bytes_scanned_total", "Bytes verified by the background scrubber.", nil, nil)
+	scrubCorruptDesc  = prometheus.NewDesc("minfs_dataserver_scrub_corrupt_blocks_total", "Corrupt blocks found and quarantined by the background scrubber.", nil, nil)
+	scrubLastPassDesc = prometheus.NewDesc("minfs_dataserver_scrub_last_pass_timestamp_seconds", "Unix time the last scrub pass finished.", nil, nil)
+)
+
+// storageCollector 抓取时读取存储统计
+type storageCollector struct {
+	storage model.StorageService
+}
+
+// Describe 实现 prometheus.Collector
+func (c *storageCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- diskUsedDesc
+	ch <- diskFreeDesc
+	ch <- diskCapacityDesc
+	ch <- blockCountDesc
+	ch <- corruptDesc
+	ch <- scrubBlocksDesc
+	ch <- scrubBytesDesc
+	ch <- scrubCorruptDesc
+	ch <- scrubLastPassDesc
+}
+
+// Collect 实现 prometheus.Collector
+func (c *storageCollector) Collect(ch chan<- prometheus.Metric) {
+	stat, err := c.storage.GetStat()
+	if err != nil {
+		log.Printf("Failed to collect storage metrics: %v", err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(diskUsedDesc, prometheus.GaugeValue, float64(stat.UsedSpace))
+	ch <- prometheus.MustNewConstMetric(diskFreeDesc, prometheus.GaugeValue, float64(stat.FreeSpace))
+	ch <- prometheus.MustNewConstMetric(diskCapacityDesc, prometheus.GaugeValue, float64(stat.TotalCapacity))
+	ch <- prometheus.MustNewConstMetric(blockCountDesc, prometheus.GaugeValue, float64(stat.BlockCount))
+	ch <- prometheus.MustNewConstMetric(corruptDesc, prometheus.GaugeValue, float64(len(stat.CorruptBlockIds)))
+
+	// 巡检统计是启动以来的累计值，按计数器暴露；尚未完成一轮时不暴露完成时间
+	ch <- prometheus.MustNewConstMetric(scrubBlocksDesc, prometheus.CounterValue, float64(stat.Scrub.BlocksScanned))
+	ch <- prometheus.MustNewConstMetric(scrubBytesDesc, prometheus.CounterValue, float64(stat.Scrub.BytesScanned))
+	ch <- prometheus.MustNewConstMetric(scrubCorruptDesc, prometheus.CounterValue, float64(stat.Scrub.CorruptBlocks))
+	if !stat.Scrub.LastPassTime.IsZero() {
+		ch <- prometheus.MustNewConstMetric(scrubLastPassDesc, prometheus.GaugeValue, float64(stat.Scrub.LastPassTime.Unix()))
+	}
+}
+
+// RecordOperation 实现 model.MetricsRecorder
+func (m *Metrics) RecordOperation(operation string, success bool) {
+	result := "success"
+	if !success {
+		result = "failed"
+	}
+	m.operations.WithLabelValues(operation, result).Inc()
+}
+
+// AddBytesRead 实现 model.MetricsRecorder
+func (m *Metrics) AddBytesRead(n int64) {
+	m.bytesRead.Add(float64(n))
+}
+
+// AddBytesWritten 实现 model.MetricsRecorder
+func (m *Metrics) AddBytesWritten(n int64) {
+	m.bytesWritten.Add(float64(n))
+}
+
+// UnaryServerInterceptor 记录一元 RPC 的耗时
+func (m *Metrics) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		m.observeRPC(info.FullMethod, err, time.Since(start))
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor 记录流式 RPC 从开始到结束的耗时
+func (m *Metrics) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, stream)
+		m.observeRPC(info.FullMethod, err, time.Since(start))
+		return err
+	}
+}
+
+// observeRPC 记录 RPC 耗时，方法名去掉服务前缀
+func (m *Metrics) observeRPC(fullMethod string, err error, duration time.Duration) {
+	method := fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+	m.rpcDuration.WithLabelValues(method, status.Code(err).String()).Observe(duration.Seconds())
+}
+
+// Handler 返回 /metrics 的 HTTP 处理器
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Serve 在 port 上启动 HTTP 服务暴露 /metrics，port 不大于 0 时不启动
+func (m *Metrics) Serve(port int) *http.Server {
+	if port <= 0 {
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m.Handler())
+	server := &http.Server{Addr: fmt.Sprintf(":%d", port), Handler: mux}
+
+	go func() {
+		log.Printf("Metrics listening on :%d/metrics", port)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("Metrics server error: %v", err)
+		}
+	}()
+	return server
+}
@@ -105,6 +105,11 @@ func (s *MockClusterService) sendMockHeartbeat() {
 	} else {
 		log.Printf("    └── Block IDs: [] (no blocks stored)")
 	}
+	if len(stat.CorruptBlockIds) > 0 {
+		log.Printf("    └── Corrupt Block IDs: %v", stat.CorruptBlockIds)
+	}
+	log.Printf("    └── Scrub: %d blocks scanned, %d corrupt, last pass %v",
+		stat.Scrub.BlocksScanned, stat.Scrub.CorruptBlocks, stat.Scrub.LastPassTime)
 
 	log.Printf("💓 [MOCK HEARTBEAT RESPONSE] No commands from MetaServer (simulated)")
 
@@ -5,90 +5,125 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"sync"
 	"time"
 
 	"dataServer/internal/model"
 	"dataServer/pb"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 // GrpcReplicationService gRPC复制服务实现
 type GrpcReplicationService struct {
 	connectionTimeout time.Duration
 	connections       map[string]*grpc.ClientConn // 连接缓存
+	mu                sync.Mutex                  // 保护连接缓存，写入流水线会并发访问
+	dialOption        grpc.DialOption             // 传输凭证，开启 TLS 时使用证书
+	blockTokens       model.BlockTokenService     // 节点间读写自签块令牌
 }
 
 // NewReplicationService 创建新的复制服务实例
-func NewReplicationService() *GrpcReplicationService {
+func NewReplicationService(dialOption grpc.DialOption, blockTokens model.BlockTokenService) *GrpcReplicationService {
 	return &GrpcReplicationService{
 		connectionTimeout: 5 * time.Second,
 		connections:       make(map[string]*grpc.ClientConn),
+		dialOption:        dialOption,
+		blockTokens:       blockTokens,
 	}
 }
 
 // ForwardBlock 作为gRPC客户端，将数据块转发到目标地址
 func (s *GrpcReplicationService) ForwardBlock(targetAddr string, metadata *model.WriteBlockMetadata, data []byte) error {
+	stream, err := s.OpenForwardStream(targetAddr, metadata)
+	if err != nil {
+		return err
+	}
+
+	// 分块发送数据
+	const chunkSize = 64 * 1024 // 64KB per chunk
+	for i := 0; i < len(data); i += chunkSize {
+		end := i + chunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		if err := stream.Send(data[i:end]); err != nil {
+			stream.Abort()
+			return err
+		}
+	}
+
+	return stream.CloseAndRecv()
+}
+
+// OpenForwardStream 打开到目标地址的写入流并发送元数据，之后的数据分片可边收边转发
+func (s *GrpcReplicationService) OpenForwardStream(targetAddr string, metadata *model.WriteBlockMetadata) (model.BlockForwardStream, error) {
 	// 获取或创建连接
 	conn, err := s.getConnection(targetAddr)
 	if err != nil {
-		return fmt.Errorf("failed to connect to %s: %w", targetAddr, err)
+		return nil, fmt.Errorf("failed to connect to %s: %w", targetAddr, err)
 	}
 
-	// 创建客户端
+	// 创建客户端，流的生命周期跟随上游写入，由 CloseAndRecv/Abort 结束
 	client := pb.NewDataServerServiceClient(conn)
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	ctx, cancel := context.WithCancel(context.Background())
 
 	// 开始流式传输
 	stream, err := client.WriteBlock(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to create write stream: %w", err)
+		cancel()
+		return nil, fmt.Errorf("failed to create write stream: %w", err)
 	}
 
-	// 发送元数据
+	// 发送元数据，流水线转发时沿用客户端的令牌
+	token := metadata.Token
+	if token == "" {
+		token = s.blockTokens.Sign(metadata.BlockId, "w")
+	}
 	metadataReq := &pb.WriteBlockRequest{
 		Content: &pb.WriteBlockRequest_Metadata{
 			Metadata: &pb.WriteBlockMetadata{
 				BlockId:          metadata.BlockId,
 				ReplicaLocations: metadata.ReplicaLocations,
+				Forwarded:        metadata.Forwarded,
+				Token:            token,
 			},
 		},
 	}
 
 	if err := stream.Send(metadataReq); err != nil {
-		return fmt.Errorf("failed to send metadata: %w", err)
+		cancel()
+		return nil, fmt.Errorf("failed to send metadata: %w", err)
 	}
 
-	// 分块发送数据
-	const chunkSize = 64 * 1024 // 64KB per chunk
-	reader := bytes.NewReader(data)
-	buffer := make([]byte, chunkSize)
-
-	for {
-		n, err := reader.Read(buffer)
-		if err != nil {
-			if err == io.EOF {
-				break
-			}
-			return fmt.Errorf("failed to read data chunk: %w", err)
-		}
+	return &grpcForwardStream{stream: stream, cancel: cancel}, nil
+}
 
-		// 发送数据块
-		chunkReq := &pb.WriteBlockRequest{
-			Content: &pb.WriteBlockRequest_ChunkData{
-				ChunkData: buffer[:n],
-			},
-		}
+// grpcForwardStream 基于 WriteBlock 客户端流的转发实现
+type grpcForwardStream struct {
+	stream pb.DataServerService_WriteBlockClient
+	cancel context.CancelFunc
+}
 
-		if err := stream.Send(chunkReq); err != nil {
-			return fmt.Errorf("failed to send data chunk: %w", err)
-		}
+// Send 发送一个数据分片
+func (f *grpcForwardStream) Send(chunk []byte) error {
+	chunkReq := &pb.WriteBlockRequest{
+		Content: &pb.WriteBlockRequest_ChunkData{
+			ChunkData: chunk,
+		},
 	}
 
-	// 关闭发送并接收响应
-	resp, err := stream.CloseAndRecv()
+	if err := f.stream.Send(chunkReq); err != nil {
+		return fmt.Errorf("failed to send data chunk: %w", err)
+	}
+	return nil
+}
+
+// CloseAndRecv 关闭发送并等待下游响应
+func (f *grpcForwardStream) CloseAndRecv() error {
+	defer f.cancel()
+
+	resp, err := f.stream.CloseAndRecv()
 	if err != nil {
 		return fmt.Errorf("failed to close stream and receive response: %w", err)
 	}
@@ -100,6 +135,11 @@ func (s *GrpcReplicationService) ForwardBlock(targetAddr string, metadata *model
 	return nil
 }
 
+// Abort 中止转发，下游会丢弃未完成的块
+func (f *grpcForwardStream) Abort() {
+	f.cancel()
+}
+
 // PushBlock 推送数据块到目标地址
 func (s *GrpcReplicationService) PushBlock(targetAddr string, blockID uint64, data []byte) error {
 	// 创建简化的元数据（不需要副本位置，因为这是点对点传输）
@@ -114,10 +154,20 @@ func (s *GrpcReplicationService) PushBlock(targetAddr string, blockID uint64, da
 
 // PullBlock 从源地址拉取数据块并返回数据
 func (s *GrpcReplicationService) PullBlock(sourceAddr string, blockID uint64) ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := s.PullBlockTo(sourceAddr, blockID, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// PullBlockTo 从源地址拉取数据块，边接收边写入 w，返回接收的字节数
+// 调用方在返回错误时应丢弃已写入的数据
+func (s *GrpcReplicationService) PullBlockTo(sourceAddr string, blockID uint64, w io.Writer) (int64, error) {
 	// 获取或创建连接
 	conn, err := s.getConnection(sourceAddr)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to %s: %w", sourceAddr, err)
+		return 0, fmt.Errorf("failed to connect to %s: %w", sourceAddr, err)
 	}
 
 	// 创建客户端
@@ -128,32 +178,22 @@ func (s *GrpcReplicationService) PullBlock(sourceAddr string, blockID uint64) ([
 	// 发起读取请求
 	req := &pb.ReadBlockRequest{
 		BlockId: blockID,
+		Token:   s.blockTokens.Sign(blockID, "r"),
 	}
 
 	stream, err := client.ReadBlock(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create read stream: %w", err)
-	}
-
-	// 接收数据块
-	var data []byte
-	for {
-		resp, err := stream.Recv()
-		if err != nil {
-			if err == io.EOF {
-				break
-			}
-			return nil, fmt.Errorf("failed to receive data chunk: %w", err)
-		}
-
-		data = append(data, resp.ChunkData...)
+		return 0, fmt.Errorf("failed to create read stream: %w", err)
 	}
 
-	return data, nil
+	return receiveBlockStream(blockID, stream, w)
 }
 
 // getConnection 获取或创建到目标地址的gRPC连接
 func (s *GrpcReplicationService) getConnection(addr string) (*grpc.ClientConn, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	// 检查是否已有连接
 	if conn, exists := s.connections[addr]; exists {
 		// 检查连接状态
@@ -169,7 +209,7 @@ func (s *GrpcReplicationService) getConnection(addr string) (*grpc.ClientConn, e
 	defer cancel()
 
 	conn, err := grpc.DialContext(ctx, addr,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		s.dialOption,
 		grpc.WithBlock(), // 等待连接建立
 	)
 	if err != nil {
@@ -184,6 +224,9 @@ func (s *GrpcReplicationService) getConnection(addr string) (*grpc.ClientConn, e
 
 // Close 关闭所有连接
 func (s *GrpcReplicationService) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	for addr, conn := range s.connections {
 		if err := conn.Close(); err != nil {
 			// 记录错误但继续关闭其他连接
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"errors"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"dataServer/internal/model"
+)
+
+// BlockScrubber 后台块巡检服务
+// 按限定速率逐个重新校验本地块，发现损坏的块后移入隔离目录，
+// 损坏块会继续在心跳中上报，由metaServer从健康副本重新复制
+type BlockScrubber struct {
+	storage  *LocalStorageService
+	rateMBps float64
+	interval time.Duration
+
+	stats   model.ScrubStats
+	statsMu sync.RWMutex
+
+	mu        sync.Mutex
+	stopChan  chan struct{}
+	isRunning bool
+}
+
+// NewBlockScrubber 创建块巡检服务
+func NewBlockScrubber(storage *LocalStorageService, config *model.Config) *BlockScrubber {
+	rate := config.Scrubber.RateMBps
+	if rate <= 0 {
+		rate = 10
+	}
+	interval := time.Duration(config.Scrubber.Interval) * time.Second
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	scrubber := &BlockScrubber{
+		storage:  storage,
+		rateMBps: rate,
+		interval: interval,
+	}
+	storage.SetScrubber(scrubber)
+	return scrubber
+}
+
+// Start 启动巡检循环，停止后可以再次启动
+func (b *BlockScrubber) Start() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.isRunning {
+		return
+	}
+	b.isRunning = true
+	b.stopChan = make(chan struct{})
+
+	go b.scrubLoop(b.stopChan)
+	log.Printf("Block scrubber started (rate: %.1f MB/s, interval: %v)", b.rateMBps, b.interval)
+}
+
+// Stop 停止巡检循环
+func (b *BlockScrubber) Stop() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.isRunning {
+		return
+	}
+	close(b.stopChan)
+	b.isRunning = false
+	log.Println("Block scrubber stopped")
+}
+
+// Stats 获取巡检统计信息，扫描数和损坏块数均为启动以来所有已完成轮次的累计值
+func (b *BlockScrubber) Stats() model.ScrubStats {
+	b.statsMu.RLock()
+	defer b.statsMu.RUnlock()
+	return b.stats
+}
+
+// scrubLoop 巡检循环，每轮结束后等待 interval 再开始下一轮，stop 关闭时退出
+func (b *BlockScrubber) scrubLoop(stop <-chan struct{}) {
+	for {
+		if !b.runPass(stop) {
+			return
+		}
+
+		select {
+		case <-time.After(b.interval):
+		case <-stop:
+			return
+		}
+	}
+}
+
+// runPass 执行一轮巡检，stop 关闭时中止并返回 false，中止的一轮不计入统计
+func (b *BlockScrubber) runPass(stop <-chan struct{}) bool {
+	blockIds, err := b.storage.ListBlocks()
+	if err != nil {
+		log.Printf("Scrubber: failed to list blocks: %v", err)
+		return true
+	}
+
+	start := time.Now()
+	var scanned, bytesScanned, corrupt uint64
+
+	for _, blockID := range blockIds {
+		select {
+		case <-stop:
+			return false
+		default:
+		}
+
+		n, err := b.scrubBlock(blockID)
+		if err != nil {
+			if errors.Is(err, model.ErrChecksumMismatch) {
+				corrupt++
+				log.Printf("Scrubber: %v", err)
+				if qErr := b.storage.QuarantineBlock(blockID); qErr != nil {
+					log.Printf("Scrubber: failed to quarantine block %d: %v", blockID, qErr)
+				}
+			}
+			// 其他错误（如块在巡检期间被删除）直接跳过
+			continue
+		}
+
+		scanned++
+		bytesScanned += uint64(n)
+
+		// 限速：按已读字节数计算本轮应耗费的最短时间
+		expected := time.Duration(float64(bytesScanned) / (b.rateMBps * 1024 * 1024) * float64(time.Second))
+		if wait := expected - time.Since(start); wait > 0 {
+			select {
+			case <-time.After(wait):
+			case <-stop:
+				return false
+			}
+		}
+	}
+
+	b.statsMu.Lock()
+	b.stats.BlocksScanned += scanned
+	b.stats.BytesScanned += bytesScanned
+	b.stats.CorruptBlocks += corrupt
+	b.stats.LastPassTime = time.Now()
+	b.statsMu.Unlock()
+
+	log.Printf("Scrubber: pass completed in %v, scanned %d blocks (%d bytes), found %d corrupt",
+		time.Since(start), scanned, bytesScanned, corrupt)
+	return true
+}
+
+// scrubBlock 流式读取并校验一个块，返回读取的字节数
+func (b *BlockScrubber) scrubBlock(blockID uint64) (int64, error) {
+	reader, err := b.storage.OpenBlockReader(blockID)
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Close()
+
+	return io.Copy(io.Discard, reader)
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"dataServer/internal/model"
+)
+
+func TestScrubberQuarantinesCorruptBlock(t *testing.T) {
+	dir := t.TempDir()
+	storage, err := NewStorageService(dir, true)
+	if err != nil {
+		t.Fatalf("new storage: %v", err)
+	}
+	for blockID := uint64(1); blockID <= 2; blockID++ {
+		if err := storage.WriteBlock(blockID, make([]byte, ChecksumChunkSize+100)); err != nil {
+			t.Fatalf("write block %d: %v", blockID, err)
+		}
+	}
+
+	// 损坏块 2 的数据文件
+	file, err := os.OpenFile(storage.getBlockFilePath(2), os.O_RDWR, 0)
+	if err != nil {
+		t.Fatalf("open block file: %v", err)
+	}
+	if _, err := file.WriteAt([]byte{0xff}, 10); err != nil {
+		t.Fatalf("corrupt block file: %v", err)
+	}
+	file.Close()
+
+	config := &model.Config{}
+	config.Scrubber.RateMBps = 1000
+	scrubber := NewBlockScrubber(storage, config)
+
+	for pass := 1; pass <= 2; pass++ {
+		if !scrubber.runPass(make(chan struct{})) {
+			t.Fatalf("pass %d stopped", pass)
+		}
+	}
+
+	// 损坏的块移入隔离目录，不再参与巡检
+	if storage.BlockExists(2) {
+		t.Error("corrupt block still in the data directory")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "quarantine", "2.dat")); err != nil {
+		t.Errorf("corrupt block not quarantined: %v", err)
+	}
+
+	// 统计为两轮的累计值
+	stats := scrubber.Stats()
+	if stats.BlocksScanned != 2 || stats.CorruptBlocks != 1 || stats.LastPassTime.IsZero() {
+		t.Errorf("scrub stats: %+v", stats)
+	}
+
+	stat, err := storage.GetStat()
+	if err != nil {
+		t.Fatalf("get stat: %v", err)
+	}
+	req := newHeartbeatRequest(config, stat)
+	if len(req.CorruptBlockIds) != 1 || req.CorruptBlockIds[0] != 2 {
+		t.Errorf("heartbeat corrupt blocks: %v", req.CorruptBlockIds)
+	}
+	if len(req.BlockIdsReport) != 1 || req.BlockIdsReport[0] != 1 {
+		t.Errorf("heartbeat block report: %v", req.BlockIdsReport)
+	}
+	if req.ScrubStats.GetCorruptBlocks() != 1 {
+		t.Errorf("heartbeat scrub stats: %v", req.ScrubStats)
+	}
+
+	// 停止后可以再次启动
+	scrubber.Start()
+	scrubber.Stop()
+	scrubber.Start()
+	scrubber.Stop()
+}
@@ -1,7 +1,10 @@
 package service
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"log"
 	"os"
 	"path/filepath"
 	"strings"
@@ -13,83 +16,113 @@ import (
 
 // LocalStorageService 本地存储服务实现
 type LocalStorageService struct {
-	rootDir string
-	mu      sync.RWMutex
+	rootDir        string
+	chunkChecksums bool // 是否保存分片校验和
+	mu             sync.RWMutex
+
+	// 校验失败的块，在心跳中上报，重新写入或删除后清除
+	corruptBlocks map[uint64]bool
+	corruptMu     sync.Mutex
+
+	quarantineDir string         // 损坏块隔离目录
+	scrubber      *BlockScrubber // 后台巡检，用于在统计信息中附带巡检结果
 }
 
 // NewStorageService 创建新的存储服务实例
-func NewStorageService(rootDir string) (*LocalStorageService, error) {
+func NewStorageService(rootDir string, chunkChecksums bool) (*LocalStorageService, error) {
 	// 确保根目录存在
 	if err := os.MkdirAll(rootDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create root directory %s: %w", rootDir, err)
 	}
 
 	return &LocalStorageService{
-		rootDir: rootDir,
+		rootDir:        rootDir,
+		chunkChecksums: chunkChecksums,
+		corruptBlocks:  make(map[uint64]bool),
+		quarantineDir:  filepath.Join(rootDir, "quarantine"),
 	}, nil
 }
 
-// WriteBlock 将数据块写入本地文件系统
-// 使用哈希目录结构，例如: /data/f1/8b/f18be298c765.dat
-func (s *LocalStorageService) WriteBlock(blockID uint64, data []byte) error {
+// SetQuarantineDir 设置损坏块隔离目录
+func (s *LocalStorageService) SetQuarantineDir(dir string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	s.quarantineDir = dir
+}
 
-	filePath := s.getBlockFilePath(blockID)
+// SetScrubber 设置后台巡检服务
+func (s *LocalStorageService) SetScrubber(scrubber *BlockScrubber) {
+	s.scrubber = scrubber
+}
 
-	// 确保目录存在
-	dir := filepath.Dir(filePath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+// WriteBlock 将数据块写入本地文件系统
+// 使用哈希目录结构，例如: /data/f1/8b/f18be298c765.dat
+func (s *LocalStorageService) WriteBlock(blockID uint64, data []byte) error {
+	writer, err := s.OpenBlockWriter(blockID)
+	if err != nil {
+		return err
 	}
 
-	// 创建临时文件，原子性写入
-	tempFile := filePath + ".tmp"
-	file, err := os.Create(tempFile)
-	if err != nil {
-		return fmt.Errorf("failed to create temp file %s: %w", tempFile, err)
+	if _, err := writer.Write(data); err != nil {
+		writer.Abort()
+		return err
 	}
-	defer file.Close()
 
-	// 写入数据
-	if _, err := file.Write(data); err != nil {
-		os.Remove(tempFile) // 清理临时文件
-		return fmt.Errorf("failed to write data to file: %w", err)
+	return writer.Commit()
+}
+
+// ReadBlock 从本地文件系统读取整个数据块
+func (s *LocalStorageService) ReadBlock(blockID uint64) ([]byte, error) {
+	reader, err := s.OpenBlockReader(blockID)
+	if err != nil {
+		return nil, err
 	}
+	defer reader.Close()
 
-	// 确保数据写入磁盘
-	if err := file.Sync(); err != nil {
-		os.Remove(tempFile)
-		return fmt.Errorf("failed to sync file: %w", err)
+	data := make([]byte, 0, reader.Size())
+	buf := bytes.NewBuffer(data)
+	if _, err := io.Copy(buf, reader); err != nil {
+		return nil, err
 	}
 
-	file.Close()
+	return buf.Bytes(), nil
+}
+
+// markCorrupt 标记块为损坏
+func (s *LocalStorageService) markCorrupt(blockID uint64) {
+	s.corruptMu.Lock()
+	defer s.corruptMu.Unlock()
 
-	// 原子性重命名
-	if err := os.Rename(tempFile, filePath); err != nil {
-		os.Remove(tempFile)
-		return fmt.Errorf("failed to rename temp file: %w", err)
+	if !s.corruptBlocks[blockID] {
+		log.Printf("Block %d marked as corrupt", blockID)
 	}
+	s.corruptBlocks[blockID] = true
+}
 
-	return nil
+// clearCorrupt 清除块的损坏标记
+func (s *LocalStorageService) clearCorrupt(blockID uint64) {
+	s.corruptMu.Lock()
+	defer s.corruptMu.Unlock()
+	delete(s.corruptBlocks, blockID)
 }
 
-// ReadBlock 从本地文件系统读取数据块
-func (s *LocalStorageService) ReadBlock(blockID uint64) ([]byte, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+// isCorrupt 检查块是否被标记为损坏
+func (s *LocalStorageService) isCorrupt(blockID uint64) bool {
+	s.corruptMu.Lock()
+	defer s.corruptMu.Unlock()
+	return s.corruptBlocks[blockID]
+}
 
-	filePath := s.getBlockFilePath(blockID)
+// GetCorruptBlocks 获取所有被标记为损坏的块ID
+func (s *LocalStorageService) GetCorruptBlocks() []uint64 {
+	s.corruptMu.Lock()
+	defer s.corruptMu.Unlock()
 
-	data, err := os.ReadFile(filePath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("block %d not found", blockID)
-		}
-		return nil, fmt.Errorf("failed to read block %d: %w", blockID, err)
+	blockIds := make([]uint64, 0, len(s.corruptBlocks))
+	for blockID := range s.corruptBlocks {
+		blockIds = append(blockIds, blockID)
 	}
-
-	return data, nil
+	return blockIds
 }
 
 // DeleteBlock 删除本地数据块文件
@@ -99,13 +132,43 @@ func (s *LocalStorageService) DeleteBlock(blockID uint64) error {
 
 	filePath := s.getBlockFilePath(blockID)
 
-	if err := os.Remove(filePath); err != nil {
-		if os.IsNotExist(err) {
-			return nil // 文件不存在，认为删除成功
-		}
+	s.clearCorrupt(blockID)
+
+	// 先删除数据文件，中途失败时不会留下没有校验文件的块；文件不存在时认为删除成功
+	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to delete block %d: %w", blockID, err)
 	}
 
+	if err := os.Remove(s.getChecksumFilePath(blockID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete checksum of block %d: %w", blockID, err)
+	}
+
+	return nil
+}
+
+// QuarantineBlock 将损坏的块及其校验文件移入隔离目录
+// 块仍保持损坏标记，直到从健康副本重新写入或被删除
+func (s *LocalStorageService) QuarantineBlock(blockID uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.quarantineDir, 0755); err != nil {
+		return fmt.Errorf("failed to create quarantine directory %s: %w", s.quarantineDir, err)
+	}
+
+	s.markCorrupt(blockID)
+
+	dataPath := s.getBlockFilePath(blockID)
+	if err := os.Rename(dataPath, filepath.Join(s.quarantineDir, filepath.Base(dataPath))); err != nil {
+		return fmt.Errorf("failed to quarantine block %d: %w", blockID, err)
+	}
+
+	checksumPath := s.getChecksumFilePath(blockID)
+	if err := os.Rename(checksumPath, filepath.Join(s.quarantineDir, filepath.Base(checksumPath))); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to quarantine checksum of block %d: %w", blockID, err)
+	}
+
+	log.Printf("Block %d moved to quarantine %s", blockID, s.quarantineDir)
 	return nil
 }
 
@@ -132,8 +195,11 @@ func (s *LocalStorageService) ListBlocks() ([]uint64, error) {
 			return err
 		}
 
-		// 跳过目录
+		// 跳过目录，隔离目录中的块不计入
 		if info.IsDir() {
+			if path == s.quarantineDir {
+				return filepath.SkipDir
+			}
 			return nil
 		}
 
@@ -165,13 +231,17 @@ func (s *LocalStorageService) GetStat() (*model.StorageStat, error) {
 		return nil, err
 	}
 
-	// 计算已使用空间
+	// 计算已使用空间，损坏的块不上报，等待metaServer调度修复
 	var usedSpace uint64
+	healthyBlockIds := make([]uint64, 0, len(blockIds))
 	for _, blockID := range blockIds {
 		filePath := s.getBlockFilePath(blockID)
 		if info, err := os.Stat(filePath); err == nil {
 			usedSpace += uint64(info.Size())
 		}
+		if !s.isCorrupt(blockID) {
+			healthyBlockIds = append(healthyBlockIds, blockID)
+		}
 	}
 
 	// 获取可用空间和总容量
@@ -180,13 +250,20 @@ func (s *LocalStorageService) GetStat() (*model.StorageStat, error) {
 		return nil, fmt.Errorf("failed to get disk space info: %w", err)
 	}
 
-	return &model.StorageStat{
-		BlockCount:    uint64(len(blockIds)),
-		FreeSpace:     freeSpace,
-		UsedSpace:     usedSpace,
-		TotalCapacity: totalCapacity,
-		BlockIds:      blockIds,
-	}, nil
+	stat := &model.StorageStat{
+		BlockCount:      uint64(len(healthyBlockIds)),
+		FreeSpace:       freeSpace,
+		UsedSpace:       usedSpace,
+		TotalCapacity:   totalCapacity,
+		BlockIds:        healthyBlockIds,
+		CorruptBlockIds: s.GetCorruptBlocks(),
+	}
+
+	if s.scrubber != nil {
+		stat.Scrub = s.scrubber.Stats()
+	}
+
+	return stat, nil
 }
 
 // getBlockFilePath 根据块ID生成文件路径
@@ -196,6 +273,12 @@ func (s *LocalStorageService) getBlockFilePath(blockID uint64) string {
 	return filepath.Join(s.rootDir, fileName)
 }
 
+// getChecksumFilePath 根据块ID生成校验文件路径
+func (s *LocalStorageService) getChecksumFilePath(blockID uint64) string {
+	fileName := fmt.Sprintf("%d.meta", blockID)
+	return filepath.Join(s.rootDir, fileName)
+}
+
 // extractBlockIDFromPath 从文件路径提取块ID
 func (s *LocalStorageService) extractBlockIDFromPath(path string) uint64 {
 	fileName := filepath.Base(path)
@@ -108,6 +108,8 @@ type WriteBlockMetadata struct {
 	state            protoimpl.MessageState `protogen:"open.v1"`
 	BlockId          uint64                 `protobuf:"varint,1,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
 	ReplicaLocations []string               `protobuf:"bytes,2,rep,name=replica_locations,json=replicaLocations,proto3" json:"replica_locations,omitempty"`
+	Forwarded        bool                   `protobuf:"varint,3,opt,name=forwarded,proto3" json:"forwarded,omitempty"` // 由上游DataServer在写入流水线中转发
+	Token            string                 `protobuf:"bytes,4,opt,name=token,proto3" json:"token,omitempty"`          // 块访问令牌，需要 w 权限
 	unknownFields    protoimpl.UnknownFields
 	sizeCache        protoimpl.SizeCache
 }
@@ -156,6 +158,20 @@ func (x *WriteBlockMetadata) GetReplicaLocations() []string {
 	return nil
 }
 
+func (x *WriteBlockMetadata) GetForwarded() bool {
+	if x != nil {
+		return x.Forwarded
+	}
+	return false
+}
+
+func (x *WriteBlockMetadata) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
 type WriteBlockResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
@@ -203,6 +219,9 @@ func (x *WriteBlockResponse) GetSuccess() bool {
 type ReadBlockRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	BlockId       uint64                 `protobuf:"varint,1,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
+	Offset        uint64                 `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"` // 块内起始偏移
+	Length        uint64                 `protobuf:"varint,3,opt,name=length,proto3" json:"length,omitempty"` // 读取长度，0 表示读到块末尾
+	Token         string                 `protobuf:"bytes,4,opt,name=token,proto3" json:"token,omitempty"`    // 块访问令牌，需要 r 权限
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -244,9 +263,32 @@ func (x *ReadBlockRequest) GetBlockId() uint64 {
 	return 0
 }
 
+func (x *ReadBlockRequest) GetOffset() uint64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ReadBlockRequest) GetLength() uint64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *ReadBlockRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
 type ReadBlockResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	ChunkData     []byte                 `protobuf:"bytes,1,opt,name=chunk_data,json=chunkData,proto3" json:"chunk_data,omitempty"`
+	Crc32C        uint32                 `protobuf:"varint,2,opt,name=crc32c,proto3" json:"crc32c,omitempty"`                        // 整块数据的 CRC32C，只在第一个消息中携带
+	HasCrc32C     bool                   `protobuf:"varint,3,opt,name=has_crc32c,json=hasCrc32c,proto3" json:"has_crc32c,omitempty"` // crc32c 是否有效；范围读取和旧版本写入的块没有整块校验和
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -288,9 +330,24 @@ func (x *ReadBlockResponse) GetChunkData() []byte {
 	return nil
 }
 
+func (x *ReadBlockResponse) GetCrc32C() uint32 {
+	if x != nil {
+		return x.Crc32C
+	}
+	return 0
+}
+
+func (x *ReadBlockResponse) GetHasCrc32C() bool {
+	if x != nil {
+		return x.HasCrc32C
+	}
+	return false
+}
+
 type DeleteBlockRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	BlockId       uint64                 `protobuf:"varint,1,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
+	Token         string                 `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"` // 块访问令牌，需要 d 权限
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -332,6 +389,13 @@ func (x *DeleteBlockRequest) GetBlockId() uint64 {
 	return 0
 }
 
+func (x *DeleteBlockRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
 type DeleteBlockResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
@@ -380,6 +444,7 @@ type CopyBlockRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	BlockId       uint64                 `protobuf:"varint,1,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
 	SourceAddress string                 `protobuf:"bytes,2,opt,name=source_address,json=sourceAddress,proto3" json:"source_address,omitempty"`
+	Token         string                 `protobuf:"bytes,3,opt,name=token,proto3" json:"token,omitempty"` // 块访问令牌，需要 c 权限
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -428,6 +493,13 @@ func (x *CopyBlockRequest) GetSourceAddress() string {
 	return ""
 }
 
+func (x *CopyBlockRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
 type CopyBlockResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
@@ -472,6 +544,225 @@ func (x *CopyBlockResponse) GetSuccess() bool {
 	return false
 }
 
+// 纠删码块组，与 metaServer 的 BlockLocations 对应
+type BlockGroup struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GroupId       uint64                 `protobuf:"varint,1,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	EcPolicy      string                 `protobuf:"bytes,2,opt,name=ec_policy,json=ecPolicy,proto3" json:"ec_policy,omitempty"`            // RS-<k>-<m>
+	StripeIds     []uint64               `protobuf:"varint,3,rep,packed,name=stripe_ids,json=stripeIds,proto3" json:"stripe_ids,omitempty"` // 前 k 个为数据条带，其余为校验条带
+	Locations     []string               `protobuf:"bytes,4,rep,name=locations,proto3" json:"locations,omitempty"`                          // locations[i] 存放第 i 个条带
+	Token         string                 `protobuf:"bytes,5,opt,name=token,proto3" json:"token,omitempty"`                                  // 块组的访问令牌（按 group_id 签发），写入需要 w 权限，读取需要 r 权限
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BlockGroup) Reset() {
+	*x = BlockGroup{}
+	mi := &file_dataServer_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BlockGroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlockGroup) ProtoMessage() {}
+
+func (x *BlockGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_dataServer_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlockGroup.ProtoReflect.Descriptor instead.
+func (*BlockGroup) Descriptor() ([]byte, []int) {
+	return file_dataServer_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *BlockGroup) GetGroupId() uint64 {
+	if x != nil {
+		return x.GroupId
+	}
+	return 0
+}
+
+func (x *BlockGroup) GetEcPolicy() string {
+	if x != nil {
+		return x.EcPolicy
+	}
+	return ""
+}
+
+func (x *BlockGroup) GetStripeIds() []uint64 {
+	if x != nil {
+		return x.StripeIds
+	}
+	return nil
+}
+
+func (x *BlockGroup) GetLocations() []string {
+	if x != nil {
+		return x.Locations
+	}
+	return nil
+}
+
+func (x *BlockGroup) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+type WriteBlockGroupRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Content:
+	//
+	//	*WriteBlockGroupRequest_Group
+	//	*WriteBlockGroupRequest_ChunkData
+	Content       isWriteBlockGroupRequest_Content `protobuf_oneof:"content"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WriteBlockGroupRequest) Reset() {
+	*x = WriteBlockGroupRequest{}
+	mi := &file_dataServer_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WriteBlockGroupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriteBlockGroupRequest) ProtoMessage() {}
+
+func (x *WriteBlockGroupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dataServer_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WriteBlockGroupRequest.ProtoReflect.Descriptor instead.
+func (*WriteBlockGroupRequest) Descriptor() ([]byte, []int) {
+	return file_dataServer_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *WriteBlockGroupRequest) GetContent() isWriteBlockGroupRequest_Content {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+func (x *WriteBlockGroupRequest) GetGroup() *BlockGroup {
+	if x != nil {
+		if x, ok := x.Content.(*WriteBlockGroupRequest_Group); ok {
+			return x.Group
+		}
+	}
+	return nil
+}
+
+func (x *WriteBlockGroupRequest) GetChunkData() []byte {
+	if x != nil {
+		if x, ok := x.Content.(*WriteBlockGroupRequest_ChunkData); ok {
+			return x.ChunkData
+		}
+	}
+	return nil
+}
+
+type isWriteBlockGroupRequest_Content interface {
+	isWriteBlockGroupRequest_Content()
+}
+
+type WriteBlockGroupRequest_Group struct {
+	Group *BlockGroup `protobuf:"bytes,1,opt,name=group,proto3,oneof"`
+}
+
+type WriteBlockGroupRequest_ChunkData struct {
+	ChunkData []byte `protobuf:"bytes,2,opt,name=chunk_data,json=chunkData,proto3,oneof"`
+}
+
+func (*WriteBlockGroupRequest_Group) isWriteBlockGroupRequest_Content() {}
+
+func (*WriteBlockGroupRequest_ChunkData) isWriteBlockGroupRequest_Content() {}
+
+type ReadBlockGroupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Group         *BlockGroup            `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	Offset        uint64                 `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"` // 块组内起始偏移
+	Length        uint64                 `protobuf:"varint,3,opt,name=length,proto3" json:"length,omitempty"` // 读取长度，必须大于 0，不能超过块的实际长度
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadBlockGroupRequest) Reset() {
+	*x = ReadBlockGroupRequest{}
+	mi := &file_dataServer_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadBlockGroupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadBlockGroupRequest) ProtoMessage() {}
+
+func (x *ReadBlockGroupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dataServer_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadBlockGroupRequest.ProtoReflect.Descriptor instead.
+func (*ReadBlockGroupRequest) Descriptor() ([]byte, []int) {
+	return file_dataServer_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ReadBlockGroupRequest) GetGroup() *BlockGroup {
+	if x != nil {
+		return x.Group
+	}
+	return nil
+}
+
+func (x *ReadBlockGroupRequest) GetOffset() uint64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ReadBlockGroupRequest) GetLength() uint64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
 var File_dataServer_proto protoreflect.FileDescriptor
 
 const file_dataServer_proto_rawDesc = "" +
@@ -481,32 +772,61 @@ const file_dataServer_proto_rawDesc = "" +
 	"\bmetadata\x18\x01 \x01(\v2\x1f.dfs_project.WriteBlockMetadataH\x00R\bmetadata\x12\x1f\n" +
 	"\n" +
 	"chunk_data\x18\x02 \x01(\fH\x00R\tchunkDataB\t\n" +
-	"\acontent\"\\\n" +
+	"\acontent\"\x90\x01\n" +
 	"\x12WriteBlockMetadata\x12\x19\n" +
 	"\bblock_id\x18\x01 \x01(\x04R\ablockId\x12+\n" +
-	"\x11replica_locations\x18\x02 \x03(\tR\x10replicaLocations\".\n" +
+	"\x11replica_locations\x18\x02 \x03(\tR\x10replicaLocations\x12\x1c\n" +
+	"\tforwarded\x18\x03 \x01(\bR\tforwarded\x12\x14\n" +
+	"\x05token\x18\x04 \x01(\tR\x05token\".\n" +
 	"\x12WriteBlockResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\"-\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"s\n" +
 	"\x10ReadBlockRequest\x12\x19\n" +
-	"\bblock_id\x18\x01 \x01(\x04R\ablockId\"2\n" +
+	"\bblock_id\x18\x01 \x01(\x04R\ablockId\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\x04R\x06offset\x12\x16\n" +
+	"\x06length\x18\x03 \x01(\x04R\x06length\x12\x14\n" +
+	"\x05token\x18\x04 \x01(\tR\x05token\"i\n" +
 	"\x11ReadBlockResponse\x12\x1d\n" +
 	"\n" +
-	"chunk_data\x18\x01 \x01(\fR\tchunkData\"/\n" +
+	"chunk_data\x18\x01 \x01(\fR\tchunkData\x12\x16\n" +
+	"\x06crc32c\x18\x02 \x01(\rR\x06crc32c\x12\x1d\n" +
+	"\n" +
+	"has_crc32c\x18\x03 \x01(\bR\thasCrc32c\"E\n" +
 	"\x12DeleteBlockRequest\x12\x19\n" +
-	"\bblock_id\x18\x01 \x01(\x04R\ablockId\"/\n" +
+	"\bblock_id\x18\x01 \x01(\x04R\ablockId\x12\x14\n" +
+	"\x05token\x18\x02 \x01(\tR\x05token\"/\n" +
 	"\x13DeleteBlockResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\"T\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"j\n" +
 	"\x10CopyBlockRequest\x12\x19\n" +
 	"\bblock_id\x18\x01 \x01(\x04R\ablockId\x12%\n" +
-	"\x0esource_address\x18\x02 \x01(\tR\rsourceAddress\"-\n" +
+	"\x0esource_address\x18\x02 \x01(\tR\rsourceAddress\x12\x14\n" +
+	"\x05token\x18\x03 \x01(\tR\x05token\"-\n" +
 	"\x11CopyBlockResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess2\xd0\x02\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"\x97\x01\n" +
+	"\n" +
+	"BlockGroup\x12\x19\n" +
+	"\bgroup_id\x18\x01 \x01(\x04R\agroupId\x12\x1b\n" +
+	"\tec_policy\x18\x02 \x01(\tR\becPolicy\x12\x1d\n" +
+	"\n" +
+	"stripe_ids\x18\x03 \x03(\x04R\tstripeIds\x12\x1c\n" +
+	"\tlocations\x18\x04 \x03(\tR\tlocations\x12\x14\n" +
+	"\x05token\x18\x05 \x01(\tR\x05token\"u\n" +
+	"\x16WriteBlockGroupRequest\x12/\n" +
+	"\x05group\x18\x01 \x01(\v2\x17.dfs_project.BlockGroupH\x00R\x05group\x12\x1f\n" +
+	"\n" +
+	"chunk_data\x18\x02 \x01(\fH\x00R\tchunkDataB\t\n" +
+	"\acontent\"v\n" +
+	"\x15ReadBlockGroupRequest\x12-\n" +
+	"\x05group\x18\x01 \x01(\v2\x17.dfs_project.BlockGroupR\x05group\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\x04R\x06offset\x12\x16\n" +
+	"\x06length\x18\x03 \x01(\x04R\x06length2\x83\x04\n" +
 	"\x11DataServerService\x12O\n" +
 	"\n" +
 	"WriteBlock\x12\x1e.dfs_project.WriteBlockRequest\x1a\x1f.dfs_project.WriteBlockResponse(\x01\x12L\n" +
 	"\tReadBlock\x12\x1d.dfs_project.ReadBlockRequest\x1a\x1e.dfs_project.ReadBlockResponse0\x01\x12P\n" +
 	"\vDeleteBlock\x12\x1f.dfs_project.DeleteBlockRequest\x1a .dfs_project.DeleteBlockResponse\x12J\n" +
-	"\tCopyBlock\x12\x1d.dfs_project.CopyBlockRequest\x1a\x1e.dfs_project.CopyBlockResponseB\x06Z\x04./pbb\x06proto3"
+	"\tCopyBlock\x12\x1d.dfs_project.CopyBlockRequest\x1a\x1e.dfs_project.CopyBlockResponse\x12Y\n" +
+	"\x0fWriteBlockGroup\x12#.dfs_project.WriteBlockGroupRequest\x1a\x1f.dfs_project.WriteBlockResponse(\x01\x12V\n" +
+	"\x0eReadBlockGroup\x12\".dfs_project.ReadBlockGroupRequest\x1a\x1e.dfs_project.ReadBlockResponse0\x01B\x06Z\x04./pbb\x06proto3"
 
 var (
 	file_dataServer_proto_rawDescOnce sync.Once
@@ -520,33 +840,42 @@ func file_dataServer_proto_rawDescGZIP() []byte {
 	return file_dataServer_proto_rawDescData
 }
 
-var file_dataServer_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_dataServer_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
 var file_dataServer_proto_goTypes = []any{
-	(*WriteBlockRequest)(nil),   // 0: dfs_project.WriteBlockRequest
-	(*WriteBlockMetadata)(nil),  // 1: dfs_project.WriteBlockMetadata
-	(*WriteBlockResponse)(nil),  // 2: dfs_project.WriteBlockResponse
-	(*ReadBlockRequest)(nil),    // 3: dfs_project.ReadBlockRequest
-	(*ReadBlockResponse)(nil),   // 4: dfs_project.ReadBlockResponse
-	(*DeleteBlockRequest)(nil),  // 5: dfs_project.DeleteBlockRequest
-	(*DeleteBlockResponse)(nil), // 6: dfs_project.DeleteBlockResponse
-	(*CopyBlockRequest)(nil),    // 7: dfs_project.CopyBlockRequest
-	(*CopyBlockResponse)(nil),   // 8: dfs_project.CopyBlockResponse
+	(*WriteBlockRequest)(nil),      // 0: dfs_project.WriteBlockRequest
+	(*WriteBlockMetadata)(nil),     // 1: dfs_project.WriteBlockMetadata
+	(*WriteBlockResponse)(nil),     // 2: dfs_project.WriteBlockResponse
+	(*ReadBlockRequest)(nil),       // 3: dfs_project.ReadBlockRequest
+	(*ReadBlockResponse)(nil),      // 4: dfs_project.ReadBlockResponse
+	(*DeleteBlockRequest)(nil),     // 5: dfs_project.DeleteBlockRequest
+	(*DeleteBlockResponse)(nil),    // 6: dfs_project.DeleteBlockResponse
+	(*CopyBlockRequest)(nil),       // 7: dfs_project.CopyBlockRequest
+	(*CopyBlockResponse)(nil),      // 8: dfs_project.CopyBlockResponse
+	(*BlockGroup)(nil),             // 9: dfs_project.BlockGroup
+	(*WriteBlockGroupRequest)(nil), // 10: dfs_project.WriteBlockGroupRequest
+	(*ReadBlockGroupRequest)(nil),  // 11: dfs_project.ReadBlockGroupRequest
 }
 var file_dataServer_proto_depIdxs = []int32{
-	1, // 0: dfs_project.WriteBlockRequest.metadata:type_name -> dfs_project.WriteBlockMetadata
-	0, // 1: dfs_project.DataServerService.WriteBlock:input_type -> dfs_project.WriteBlockRequest
-	3, // 2: dfs_project.DataServerService.ReadBlock:input_type -> dfs_project.ReadBlockRequest
-	5, // 3: dfs_project.DataServerService.DeleteBlock:input_type -> dfs_project.DeleteBlockRequest
-	7, // 4: dfs_project.DataServerService.CopyBlock:input_type -> dfs_project.CopyBlockRequest
-	2, // 5: dfs_project.DataServerService.WriteBlock:output_type -> dfs_project.WriteBlockResponse
-	4, // 6: dfs_project.DataServerService.ReadBlock:output_type -> dfs_project.ReadBlockResponse
-	6, // 7: dfs_project.DataServerService.DeleteBlock:output_type -> dfs_project.DeleteBlockResponse
-	8, // 8: dfs_project.DataServerService.CopyBlock:output_type -> dfs_project.CopyBlockResponse
-	5, // [5:9] is the sub-list for method output_type
-	1, // [1:5] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	1,  // 0: dfs_project.WriteBlockRequest.metadata:type_name -> dfs_project.WriteBlockMetadata
+	9,  // 1: dfs_project.WriteBlockGroupRequest.group:type_name -> dfs_project.BlockGroup
+	9,  // 2: dfs_project.ReadBlockGroupRequest.group:type_name -> dfs_project.BlockGroup
+	0,  // 3: dfs_project.DataServerService.WriteBlock:input_type -> dfs_project.WriteBlockRequest
+	3,  // 4: dfs_project.DataServerService.ReadBlock:input_type -> dfs_project.ReadBlockRequest
+	5,  // 5: dfs_project.DataServerService.DeleteBlock:input_type -> dfs_project.DeleteBlockRequest
+	7,  // 6: dfs_project.DataServerService.CopyBlock:input_type -> dfs_project.CopyBlockRequest
+	10, // 7: dfs_project.DataServerService.WriteBlockGroup:input_type -> dfs_project.WriteBlockGroupRequest
+	11, // 8: dfs_project.DataServerService.ReadBlockGroup:input_type -> dfs_project.ReadBlockGroupRequest
+	2,  // 9: dfs_project.DataServerService.WriteBlock:output_type -> dfs_project.WriteBlockResponse
+	4,  // 10: dfs_project.DataServerService.ReadBlock:output_type -> dfs_project.ReadBlockResponse
+	6,  // 11: dfs_project.DataServerService.DeleteBlock:output_type -> dfs_project.DeleteBlockResponse
+	8,  // 12: dfs_project.DataServerService.CopyBlock:output_type -> dfs_project.CopyBlockResponse
+	2,  // 13: dfs_project.DataServerService.WriteBlockGroup:output_type -> dfs_project.WriteBlockResponse
+	4,  // 14: dfs_project.DataServerService.ReadBlockGroup:output_type -> dfs_project.ReadBlockResponse
+	9,  // [9:15] is the sub-list for method output_type
+	3,  // [3:9] is the sub-list for method input_type
+	3,  // [3:3] is the sub-list for extension type_name
+	3,  // [3:3] is the sub-list for extension extendee
+	0,  // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_dataServer_proto_init() }
@@ -558,13 +887,17 @@ func file_dataServer_proto_init() {
 		(*WriteBlockRequest_Metadata)(nil),
 		(*WriteBlockRequest_ChunkData)(nil),
 	}
+	file_dataServer_proto_msgTypes[10].OneofWrappers = []any{
+		(*WriteBlockGroupRequest_Group)(nil),
+		(*WriteBlockGroupRequest_ChunkData)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_dataServer_proto_rawDesc), len(file_dataServer_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   9,
+			NumMessages:   12,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -20,10 +20,12 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	DataServerService_WriteBlock_FullMethodName  = "/dfs_project.DataServerService/WriteBlock"
-	DataServerService_ReadBlock_FullMethodName   = "/dfs_project.DataServerService/ReadBlock"
-	DataServerService_DeleteBlock_FullMethodName = "/dfs_project.DataServerService/DeleteBlock"
-	DataServerService_CopyBlock_FullMethodName   = "/dfs_project.DataServerService/CopyBlock"
+	DataServerService_WriteBlock_FullMethodName      = "/dfs_project.DataServerService/WriteBlock"
+	DataServerService_ReadBlock_FullMethodName       = "/dfs_project.DataServerService/ReadBlock"
+	DataServerService_DeleteBlock_FullMethodName     = "/dfs_project.DataServerService/DeleteBlock"
+	DataServerService_CopyBlock_FullMethodName       = "/dfs_project.DataServerService/CopyBlock"
+	DataServerService_WriteBlockGroup_FullMethodName = "/dfs_project.DataServerService/WriteBlockGroup"
+	DataServerService_ReadBlockGroup_FullMethodName  = "/dfs_project.DataServerService/ReadBlockGroup"
 )
 
 // DataServerServiceClient is the client API for DataServerService service.
@@ -34,6 +36,10 @@ type DataServerServiceClient interface {
 	ReadBlock(ctx context.Context, in *ReadBlockRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ReadBlockResponse], error)
 	DeleteBlock(ctx context.Context, in *DeleteBlockRequest, opts ...grpc.CallOption) (*DeleteBlockResponse, error)
 	CopyBlock(ctx context.Context, in *CopyBlockRequest, opts ...grpc.CallOption) (*CopyBlockResponse, error)
+	// 写入一个纠删码块组：接收原始数据，编码后将各条带写入 group.locations
+	WriteBlockGroup(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[WriteBlockGroupRequest, WriteBlockResponse], error)
+	// 读取纠删码块组中的数据范围，条带缺失时用任意 k 个条带重建
+	ReadBlockGroup(ctx context.Context, in *ReadBlockGroupRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ReadBlockResponse], error)
 }
 
 type dataServerServiceClient struct {
@@ -96,6 +102,38 @@ func (c *dataServerServiceClient) CopyBlock(ctx context.Context, in *CopyBlockRe
 	return out, nil
 }
 
+func (c *dataServerServiceClient) WriteBlockGroup(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[WriteBlockGroupRequest, WriteBlockResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &DataServerService_ServiceDesc.Streams[2], DataServerService_WriteBlockGroup_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WriteBlockGroupRequest, WriteBlockResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DataServerService_WriteBlockGroupClient = grpc.ClientStreamingClient[WriteBlockGroupRequest, WriteBlockResponse]
+
+func (c *dataServerServiceClient) ReadBlockGroup(ctx context.Context, in *ReadBlockGroupRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ReadBlockResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &DataServerService_ServiceDesc.Streams[3], DataServerService_ReadBlockGroup_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ReadBlockGroupRequest, ReadBlockResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DataServerService_ReadBlockGroupClient = grpc.ServerStreamingClient[ReadBlockResponse]
+
 // DataServerServiceServer is the server API for DataServerService service.
 // All implementations must embed UnimplementedDataServerServiceServer
 // for forward compatibility.
@@ -104,6 +142,10 @@ type DataServerServiceServer interface {
 	ReadBlock(*ReadBlockRequest, grpc.ServerStreamingServer[ReadBlockResponse]) error
 	DeleteBlock(context.Context, *DeleteBlockRequest) (*DeleteBlockResponse, error)
 	CopyBlock(context.Context, *CopyBlockRequest) (*CopyBlockResponse, error)
+	// 写入一个纠删码块组：接收原始数据，编码后将各条带写入 group.locations
+	WriteBlockGroup(grpc.ClientStreamingServer[WriteBlockGroupRequest, WriteBlockResponse]) error
+	// 读取纠删码块组中的数据范围，条带缺失时用任意 k 个条带重建
+	ReadBlockGroup(*ReadBlockGroupRequest, grpc.ServerStreamingServer[ReadBlockResponse]) error
 	mustEmbedUnimplementedDataServerServiceServer()
 }
 
@@ -126,6 +168,12 @@ func (UnimplementedDataServerServiceServer) DeleteBlock(context.Context, *Delete
 func (UnimplementedDataServerServiceServer) CopyBlock(context.Context, *CopyBlockRequest) (*CopyBlockResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CopyBlock not implemented")
 }
+func (UnimplementedDataServerServiceServer) WriteBlockGroup(grpc.ClientStreamingServer[WriteBlockGroupRequest, WriteBlockResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method WriteBlockGroup not implemented")
+}
+func (UnimplementedDataServerServiceServer) ReadBlockGroup(*ReadBlockGroupRequest, grpc.ServerStreamingServer[ReadBlockResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ReadBlockGroup not implemented")
+}
 func (UnimplementedDataServerServiceServer) mustEmbedUnimplementedDataServerServiceServer() {}
 func (UnimplementedDataServerServiceServer) testEmbeddedByValue()                           {}
 
@@ -201,6 +249,24 @@ func _DataServerService_CopyBlock_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DataServerService_WriteBlockGroup_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(DataServerServiceServer).WriteBlockGroup(&grpc.GenericServerStream[WriteBlockGroupRequest, WriteBlockResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DataServerService_WriteBlockGroupServer = grpc.ClientStreamingServer[WriteBlockGroupRequest, WriteBlockResponse]
+
+func _DataServerService_ReadBlockGroup_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ReadBlockGroupRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DataServerServiceServer).ReadBlockGroup(m, &grpc.GenericServerStream[ReadBlockGroupRequest, ReadBlockResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DataServerService_ReadBlockGroupServer = grpc.ServerStreamingServer[ReadBlockResponse]
+
 // DataServerService_ServiceDesc is the grpc.ServiceDesc for DataServerService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -228,6 +294,16 @@ var DataServerService_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _DataServerService_ReadBlock_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "WriteBlockGroup",
+			Handler:       _DataServerService_WriteBlockGroup_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ReadBlockGroup",
+			Handler:       _DataServerService_ReadBlockGroup_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "dataServer.proto",
 }
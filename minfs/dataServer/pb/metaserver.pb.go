@@ -1,7 +1,7 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.36.7
-// 	protoc        v3.20.3
+// 	protoc-gen-go v1.36.6
+// 	protoc        v3.12.4
 // source: metaServer.proto
 
 // 与 dataServer.proto 使用同一个包名，便于管理
@@ -81,25 +81,70 @@ func (FileType) EnumDescriptor() ([]byte, []int) {
 type WALOperationType int32
 
 const (
-	WALOperationType_CREATE_NODE    WALOperationType = 0 // 创建文件或目录
-	WALOperationType_DELETE_NODE    WALOperationType = 1 // 删除文件或目录
-	WALOperationType_UPDATE_NODE    WALOperationType = 2 // 更新节点信息
-	WALOperationType_FINALIZE_WRITE WALOperationType = 3 // 完成写入操作
+	WALOperationType_CREATE_NODE             WALOperationType = 0  // 创建文件或目录
+	WALOperationType_DELETE_NODE             WALOperationType = 1  // 删除文件或目录
+	WALOperationType_UPDATE_NODE             WALOperationType = 2  // 更新节点信息
+	WALOperationType_FINALIZE_WRITE          WALOperationType = 3  // 完成写入操作
+	WALOperationType_UPDATE_BLOCK_LOCATION   WALOperationType = 4  // 更新块位置信息
+	WALOperationType_SET_BLOCK_MAPPING       WALOperationType = 5  // 设置文件块映射关系
+	WALOperationType_RENAME_NODE             WALOperationType = 6  // 重命名/移动节点
+	WALOperationType_TRUNCATE_BLOCK_MAPPINGS WALOperationType = 7  // 删除文件中索引不小于 block_count 的块映射
+	WALOperationType_GRANT_LEASE             WALOperationType = 8  // 授予文件写租约
+	WALOperationType_RELEASE_LEASE           WALOperationType = 9  // 释放文件写租约
+	WALOperationType_NO_OP                   WALOperationType = 10 // 新leader当选后提交的空条目
+	WALOperationType_SET_QUOTA               WALOperationType = 11 // 设置目录配额
+	WALOperationType_CREATE_SNAPSHOT         WALOperationType = 12 // 创建目录快照
+	WALOperationType_DELETE_SNAPSHOT         WALOperationType = 13 // 删除目录快照
+	WALOperationType_SET_REPLICATION         WALOperationType = 14 // 修改文件副本数
+	WALOperationType_SET_EC_POLICY           WALOperationType = 15 // 设置目录纠删码策略
+	WALOperationType_CONVERT_BLOCK           WALOperationType = 16 // 将多副本块替换为纠删码块组
+	WALOperationType_SET_DATASERVER_STATE    WALOperationType = 17 // 设置 DataServer 管理状态
+	WALOperationType_SET_PERMISSION          WALOperationType = 18 // 修改节点的属主、属组、权限位或 ACL
 )
 
 // Enum value maps for WALOperationType.
 var (
 	WALOperationType_name = map[int32]string{
-		0: "CREATE_NODE",
-		1: "DELETE_NODE",
-		2: "UPDATE_NODE",
-		3: "FINALIZE_WRITE",
+		0:  "CREATE_NODE",
+		1:  "DELETE_NODE",
+		2:  "UPDATE_NODE",
+		3:  "FINALIZE_WRITE",
+		4:  "UPDATE_BLOCK_LOCATION",
+		5:  "SET_BLOCK_MAPPING",
+		6:  "RENAME_NODE",
+		7:  "TRUNCATE_BLOCK_MAPPINGS",
+		8:  "GRANT_LEASE",
+		9:  "RELEASE_LEASE",
+		10: "NO_OP",
+		11: "SET_QUOTA",
+		12: "CREATE_SNAPSHOT",
+		13: "DELETE_SNAPSHOT",
+		14: "SET_REPLICATION",
+		15: "SET_EC_POLICY",
+		16: "CONVERT_BLOCK",
+		17: "SET_DATASERVER_STATE",
+		18: "SET_PERMISSION",
 	}
 	WALOperationType_value = map[string]int32{
-		"CREATE_NODE":    0,
-		"DELETE_NODE":    1,
-		"UPDATE_NODE":    2,
-		"FINALIZE_WRITE": 3,
+		"CREATE_NODE":             0,
+		"DELETE_NODE":             1,
+		"UPDATE_NODE":             2,
+		"FINALIZE_WRITE":          3,
+		"UPDATE_BLOCK_LOCATION":   4,
+		"SET_BLOCK_MAPPING":       5,
+		"RENAME_NODE":             6,
+		"TRUNCATE_BLOCK_MAPPINGS": 7,
+		"GRANT_LEASE":             8,
+		"RELEASE_LEASE":           9,
+		"NO_OP":                   10,
+		"SET_QUOTA":               11,
+		"CREATE_SNAPSHOT":         12,
+		"DELETE_SNAPSHOT":         13,
+		"SET_REPLICATION":         14,
+		"SET_EC_POLICY":           15,
+		"CONVERT_BLOCK":           16,
+		"SET_DATASERVER_STATE":    17,
+		"SET_PERMISSION":          18,
 	}
 )
 
@@ -133,8 +178,10 @@ func (WALOperationType) EnumDescriptor() ([]byte, []int) {
 type Command_Action int32
 
 const (
-	Command_DELETE_BLOCK Command_Action = 0
-	Command_COPY_BLOCK   Command_Action = 1
+	Command_DELETE_BLOCK       Command_Action = 0
+	Command_COPY_BLOCK         Command_Action = 1
+	Command_RECONSTRUCT_STRIPE Command_Action = 2 // 从块组的其他条带重建 stripe_index 条带并保存到本地
+	Command_ENCODE_BLOCK       Command_Action = 3 // 将本地的多副本块 block_id 编码为 group 并写入各条带位置
 )
 
 // Enum value maps for Command_Action.
@@ -142,10 +189,14 @@ var (
 	Command_Action_name = map[int32]string{
 		0: "DELETE_BLOCK",
 		1: "COPY_BLOCK",
+		2: "RECONSTRUCT_STRIPE",
+		3: "ENCODE_BLOCK",
 	}
 	Command_Action_value = map[string]int32{
-		"DELETE_BLOCK": 0,
-		"COPY_BLOCK":   1,
+		"DELETE_BLOCK":       0,
+		"COPY_BLOCK":         1,
+		"RECONSTRUCT_STRIPE": 2,
+		"ENCODE_BLOCK":       3,
 	}
 )
 
@@ -173,7 +224,7 @@ func (x Command_Action) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use Command_Action.Descriptor instead.
 func (Command_Action) EnumDescriptor() ([]byte, []int) {
-	return file_metaServer_proto_rawDescGZIP(), []int{20, 0}
+	return file_metaServer_proto_rawDescGZIP(), []int{29, 0}
 }
 
 // 副本数据结构 (匹配 easyClient ReplicaData)
@@ -245,7 +296,12 @@ type StatInfo struct {
 	Mtime         int64                  `protobuf:"varint,3,opt,name=mtime,proto3" json:"mtime,omitempty"`                         // 修改时间 Unix时间戳(毫秒)
 	Type          FileType               `protobuf:"varint,4,opt,name=type,proto3,enum=dfs_project.FileType" json:"type,omitempty"` // 文件类型
 	ReplicaData   []*ReplicaData         `protobuf:"bytes,5,rep,name=replicaData,proto3" json:"replicaData,omitempty"`              // 副本数据列表
-	Md5           string                 `protobuf:"bytes,6,opt,name=md5,proto3" json:"md5,omitempty"`                              // 文件MD5哈希值
+	Md5           string                 `protobuf:"bytes,6,opt,name=md5,proto3" json:"md5,omitempty"`
+	Owner         string                 `protobuf:"bytes,7,opt,name=owner,proto3" json:"owner,omitempty"`                               // 属主，为空时为未记录属主的旧节点
+	Group         string                 `protobuf:"bytes,8,opt,name=group,proto3" json:"group,omitempty"`                               // 属组
+	Mode          uint32                 `protobuf:"varint,9,opt,name=mode,proto3" json:"mode,omitempty"`                                // 权限位 (如 0755)
+	Acl           []*AclEntry            `protobuf:"bytes,10,rep,name=acl,proto3" json:"acl,omitempty"`                                  // ACL 条目
+	ChildCount    int64                  `protobuf:"varint,11,opt,name=child_count,json=childCount,proto3" json:"child_count,omitempty"` // 目录的直接子节点数
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -322,6 +378,41 @@ func (x *StatInfo) GetMd5() string {
 	return ""
 }
 
+func (x *StatInfo) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *StatInfo) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *StatInfo) GetMode() uint32 {
+	if x != nil {
+		return x.Mode
+	}
+	return 0
+}
+
+func (x *StatInfo) GetAcl() []*AclEntry {
+	if x != nil {
+		return x.Acl
+	}
+	return nil
+}
+
+func (x *StatInfo) GetChildCount() int64 {
+	if x != nil {
+		return x.ChildCount
+	}
+	return 0
+}
+
 // MetaServer 信息 (匹配 easyClient MetaServerMsg)
 type MetaServerMsg struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -383,6 +474,7 @@ type DataServerMsg struct {
 	FileTotal     int32                  `protobuf:"varint,3,opt,name=fileTotal,proto3" json:"fileTotal,omitempty"`     // 文件总数
 	Capacity      int32                  `protobuf:"varint,4,opt,name=capacity,proto3" json:"capacity,omitempty"`       // 总容量 (MB)
 	UseCapacity   int32                  `protobuf:"varint,5,opt,name=useCapacity,proto3" json:"useCapacity,omitempty"` // 已使用容量 (MB)
+	Topology      string                 `protobuf:"bytes,6,opt,name=topology,proto3" json:"topology,omitempty"`        // 拓扑标签 (/zone/rack)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -452,6 +544,13 @@ func (x *DataServerMsg) GetUseCapacity() int32 {
 	return 0
 }
 
+func (x *DataServerMsg) GetTopology() string {
+	if x != nil {
+		return x.Topology
+	}
+	return ""
+}
+
 // 集群信息 (完全匹配 easyClient ClusterInfo)
 type ClusterInfo struct {
 	state            protoimpl.MessageState `protogen:"open.v1"`
@@ -516,14 +615,20 @@ func (x *ClusterInfo) GetDataServer() []*DataServerMsg {
 // 内部节点信息 (服务端内部使用，保留必要字段)
 type NodeInfo struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Inode         uint64                 `protobuf:"varint,1,opt,name=inode,proto3" json:"inode,omitempty"`                         // 内部inode编号
-	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`                            // 完整路径
-	Type          FileType               `protobuf:"varint,3,opt,name=type,proto3,enum=dfs_project.FileType" json:"type,omitempty"` // 类型 (使用统一的FileType)
-	Size          int64                  `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`                           // 文件大小
-	Mtime         int64                  `protobuf:"varint,5,opt,name=mtime,proto3" json:"mtime,omitempty"`                         // 修改时间 Unix时间戳(毫秒)
-	Replication   uint32                 `protobuf:"varint,6,opt,name=replication,proto3" json:"replication,omitempty"`             // 副本数
-	Md5           string                 `protobuf:"bytes,7,opt,name=md5,proto3" json:"md5,omitempty"`                              // 文件MD5哈希值
-	ReplicaData   []*ReplicaData         `protobuf:"bytes,8,rep,name=replicaData,proto3" json:"replicaData,omitempty"`              // 副本数据
+	Inode         uint64                 `protobuf:"varint,1,opt,name=inode,proto3" json:"inode,omitempty"`                              // 内部inode编号
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`                                 // 完整路径
+	Type          FileType               `protobuf:"varint,3,opt,name=type,proto3,enum=dfs_project.FileType" json:"type,omitempty"`      // 类型 (使用统一的FileType)
+	Size          int64                  `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`                                // 文件大小
+	Mtime         int64                  `protobuf:"varint,5,opt,name=mtime,proto3" json:"mtime,omitempty"`                              // 修改时间 Unix时间戳(毫秒)
+	Replication   uint32                 `protobuf:"varint,6,opt,name=replication,proto3" json:"replication,omitempty"`                  // 副本数
+	Md5           string                 `protobuf:"bytes,7,opt,name=md5,proto3" json:"md5,omitempty"`                                   // 文件MD5哈希值
+	ReplicaData   []*ReplicaData         `protobuf:"bytes,8,rep,name=replicaData,proto3" json:"replicaData,omitempty"`                   // 副本数据
+	EcPolicy      string                 `protobuf:"bytes,9,opt,name=ec_policy,json=ecPolicy,proto3" json:"ec_policy,omitempty"`         // 纠删码策略，为空时为多副本文件
+	Owner         string                 `protobuf:"bytes,10,opt,name=owner,proto3" json:"owner,omitempty"`                              // 属主，为空时不做权限检查（旧版本创建的节点）
+	Group         string                 `protobuf:"bytes,11,opt,name=group,proto3" json:"group,omitempty"`                              // 属组
+	Mode          uint32                 `protobuf:"varint,12,opt,name=mode,proto3" json:"mode,omitempty"`                               // 权限位 (如 0755)
+	Acl           []*AclEntry            `protobuf:"bytes,13,rep,name=acl,proto3" json:"acl,omitempty"`                                  // ACL 条目，在属主之后、属组和其他用户之前匹配
+	ChildCount    int64                  `protobuf:"varint,14,opt,name=child_count,json=childCount,proto3" json:"child_count,omitempty"` // 目录的直接子节点数，读取时由使用量记录填充，不持久化
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -614,18 +719,124 @@ func (x *NodeInfo) GetReplicaData() []*ReplicaData {
 	return nil
 }
 
+func (x *NodeInfo) GetEcPolicy() string {
+	if x != nil {
+		return x.EcPolicy
+	}
+	return ""
+}
+
+func (x *NodeInfo) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *NodeInfo) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *NodeInfo) GetMode() uint32 {
+	if x != nil {
+		return x.Mode
+	}
+	return 0
+}
+
+func (x *NodeInfo) GetAcl() []*AclEntry {
+	if x != nil {
+		return x.Acl
+	}
+	return nil
+}
+
+func (x *NodeInfo) GetChildCount() int64 {
+	if x != nil {
+		return x.ChildCount
+	}
+	return 0
+}
+
+// ACL 条目，为指定用户或组授予权限
+type AclEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`  // user / group
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`  // 用户名或组名
+	Perm          uint32                 `protobuf:"varint,3,opt,name=perm,proto3" json:"perm,omitempty"` // 权限位 0-7 (r=4, w=2, x=1)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AclEntry) Reset() {
+	*x = AclEntry{}
+	mi := &file_metaServer_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AclEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AclEntry) ProtoMessage() {}
+
+func (x *AclEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AclEntry.ProtoReflect.Descriptor instead.
+func (*AclEntry) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *AclEntry) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *AclEntry) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AclEntry) GetPerm() uint32 {
+	if x != nil {
+		return x.Perm
+	}
+	return 0
+}
+
 // 一个数据块的所有副本位置
 type BlockLocations struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	BlockId       uint64                 `protobuf:"varint,1,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
-	Locations     []string               `protobuf:"bytes,2,rep,name=locations,proto3" json:"locations,omitempty"` // DataServer 地址列表 (IP:Port)
+	Locations     []string               `protobuf:"bytes,2,rep,name=locations,proto3" json:"locations,omitempty"`                          // DataServer 地址列表 (IP:Port)；纠删码块组中 locations[i] 存放第 i 个条带
+	EcPolicy      string                 `protobuf:"bytes,3,opt,name=ec_policy,json=ecPolicy,proto3" json:"ec_policy,omitempty"`            // 纠删码策略，如 RS-6-3；为空时为多副本块
+	StripeIds     []uint64               `protobuf:"varint,4,rep,packed,name=stripe_ids,json=stripeIds,proto3" json:"stripe_ids,omitempty"` // 纠删码块组中各条带的块ID，前 k 个为数据条带，其余为校验条带
+	Token         string                 `protobuf:"bytes,5,opt,name=token,proto3" json:"token,omitempty"`                                  // 块访问令牌，只在返回给客户端时携带，访问 DataServer 时原样传递
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *BlockLocations) Reset() {
 	*x = BlockLocations{}
-	mi := &file_metaServer_proto_msgTypes[6]
+	mi := &file_metaServer_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -637,7 +848,7 @@ func (x *BlockLocations) String() string {
 func (*BlockLocations) ProtoMessage() {}
 
 func (x *BlockLocations) ProtoReflect() protoreflect.Message {
-	mi := &file_metaServer_proto_msgTypes[6]
+	mi := &file_metaServer_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -650,7 +861,7 @@ func (x *BlockLocations) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BlockLocations.ProtoReflect.Descriptor instead.
 func (*BlockLocations) Descriptor() ([]byte, []int) {
-	return file_metaServer_proto_rawDescGZIP(), []int{6}
+	return file_metaServer_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *BlockLocations) GetBlockId() uint64 {
@@ -667,6 +878,27 @@ func (x *BlockLocations) GetLocations() []string {
 	return nil
 }
 
+func (x *BlockLocations) GetEcPolicy() string {
+	if x != nil {
+		return x.EcPolicy
+	}
+	return ""
+}
+
+func (x *BlockLocations) GetStripeIds() []uint64 {
+	if x != nil {
+		return x.StripeIds
+	}
+	return nil
+}
+
+func (x *BlockLocations) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
 // 通用的简单响应，用于表示操作成功与否
 type SimpleResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -678,7 +910,7 @@ type SimpleResponse struct {
 
 func (x *SimpleResponse) Reset() {
 	*x = SimpleResponse{}
-	mi := &file_metaServer_proto_msgTypes[7]
+	mi := &file_metaServer_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -690,7 +922,7 @@ func (x *SimpleResponse) String() string {
 func (*SimpleResponse) ProtoMessage() {}
 
 func (x *SimpleResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_metaServer_proto_msgTypes[7]
+	mi := &file_metaServer_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -703,7 +935,7 @@ func (x *SimpleResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SimpleResponse.ProtoReflect.Descriptor instead.
 func (*SimpleResponse) Descriptor() ([]byte, []int) {
-	return file_metaServer_proto_rawDescGZIP(), []int{7}
+	return file_metaServer_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *SimpleResponse) GetSuccess() bool {
@@ -725,13 +957,15 @@ type CreateNodeRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
 	Type          FileType               `protobuf:"varint,2,opt,name=type,proto3,enum=dfs_project.FileType" json:"type,omitempty"` // 使用统一的FileType
+	Replication   uint32                 `protobuf:"varint,3,opt,name=replication,proto3" json:"replication,omitempty"`             // 文件副本数，0 表示使用默认副本数
+	Mode          uint32                 `protobuf:"varint,4,opt,name=mode,proto3" json:"mode,omitempty"`                           // 权限位，0 表示目录 0755、文件 0644
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CreateNodeRequest) Reset() {
 	*x = CreateNodeRequest{}
-	mi := &file_metaServer_proto_msgTypes[8]
+	mi := &file_metaServer_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -743,7 +977,7 @@ func (x *CreateNodeRequest) String() string {
 func (*CreateNodeRequest) ProtoMessage() {}
 
 func (x *CreateNodeRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_metaServer_proto_msgTypes[8]
+	mi := &file_metaServer_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -756,7 +990,7 @@ func (x *CreateNodeRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateNodeRequest.ProtoReflect.Descriptor instead.
 func (*CreateNodeRequest) Descriptor() ([]byte, []int) {
-	return file_metaServer_proto_rawDescGZIP(), []int{8}
+	return file_metaServer_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *CreateNodeRequest) GetPath() string {
@@ -773,6 +1007,20 @@ func (x *CreateNodeRequest) GetType() FileType {
 	return FileType_Unknown
 }
 
+func (x *CreateNodeRequest) GetReplication() uint32 {
+	if x != nil {
+		return x.Replication
+	}
+	return 0
+}
+
+func (x *CreateNodeRequest) GetMode() uint32 {
+	if x != nil {
+		return x.Mode
+	}
+	return 0
+}
+
 // GetNodeInfo - 返回 StatInfo 供 easyClient 使用
 type GetNodeInfoRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -783,7 +1031,7 @@ type GetNodeInfoRequest struct {
 
 func (x *GetNodeInfoRequest) Reset() {
 	*x = GetNodeInfoRequest{}
-	mi := &file_metaServer_proto_msgTypes[9]
+	mi := &file_metaServer_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -795,7 +1043,7 @@ func (x *GetNodeInfoRequest) String() string {
 func (*GetNodeInfoRequest) ProtoMessage() {}
 
 func (x *GetNodeInfoRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_metaServer_proto_msgTypes[9]
+	mi := &file_metaServer_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -808,7 +1056,7 @@ func (x *GetNodeInfoRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetNodeInfoRequest.ProtoReflect.Descriptor instead.
 func (*GetNodeInfoRequest) Descriptor() ([]byte, []int) {
-	return file_metaServer_proto_rawDescGZIP(), []int{9}
+	return file_metaServer_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *GetNodeInfoRequest) GetPath() string {
@@ -827,7 +1075,7 @@ type GetNodeInfoResponse struct {
 
 func (x *GetNodeInfoResponse) Reset() {
 	*x = GetNodeInfoResponse{}
-	mi := &file_metaServer_proto_msgTypes[10]
+	mi := &file_metaServer_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -839,7 +1087,7 @@ func (x *GetNodeInfoResponse) String() string {
 func (*GetNodeInfoResponse) ProtoMessage() {}
 
 func (x *GetNodeInfoResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_metaServer_proto_msgTypes[10]
+	mi := &file_metaServer_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -852,7 +1100,7 @@ func (x *GetNodeInfoResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetNodeInfoResponse.ProtoReflect.Descriptor instead.
 func (*GetNodeInfoResponse) Descriptor() ([]byte, []int) {
-	return file_metaServer_proto_rawDescGZIP(), []int{10}
+	return file_metaServer_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *GetNodeInfoResponse) GetStatInfo() *StatInfo {
@@ -864,15 +1112,17 @@ func (x *GetNodeInfoResponse) GetStatInfo() *StatInfo {
 
 // ListDirectory - 返回 StatInfo 列表供 easyClient 使用
 type ListDirectoryRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Path              string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Limit             uint32                 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`                                                 // 每页（流式为每批）最多返回的子节点数，0 表示不分页（流式时使用默认批大小）
+	ContinuationToken string                 `protobuf:"bytes,3,opt,name=continuation_token,json=continuationToken,proto3" json:"continuation_token,omitempty"` // 上一页返回的 next_continuation_token，为空时从头开始
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
 func (x *ListDirectoryRequest) Reset() {
 	*x = ListDirectoryRequest{}
-	mi := &file_metaServer_proto_msgTypes[11]
+	mi := &file_metaServer_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -884,7 +1134,7 @@ func (x *ListDirectoryRequest) String() string {
 func (*ListDirectoryRequest) ProtoMessage() {}
 
 func (x *ListDirectoryRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_metaServer_proto_msgTypes[11]
+	mi := &file_metaServer_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -897,7 +1147,7 @@ func (x *ListDirectoryRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListDirectoryRequest.ProtoReflect.Descriptor instead.
 func (*ListDirectoryRequest) Descriptor() ([]byte, []int) {
-	return file_metaServer_proto_rawDescGZIP(), []int{11}
+	return file_metaServer_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *ListDirectoryRequest) GetPath() string {
@@ -907,16 +1157,31 @@ func (x *ListDirectoryRequest) GetPath() string {
 	return ""
 }
 
+func (x *ListDirectoryRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListDirectoryRequest) GetContinuationToken() string {
+	if x != nil {
+		return x.ContinuationToken
+	}
+	return ""
+}
+
 type ListDirectoryResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Nodes         []*StatInfo            `protobuf:"bytes,1,rep,name=nodes,proto3" json:"nodes,omitempty"` // 直接返回easyClient需要的格式
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	Nodes                 []*StatInfo            `protobuf:"bytes,1,rep,name=nodes,proto3" json:"nodes,omitempty"`                                                                // 直接返回easyClient需要的格式
+	NextContinuationToken string                 `protobuf:"bytes,2,opt,name=next_continuation_token,json=nextContinuationToken,proto3" json:"next_continuation_token,omitempty"` // 还有剩余子节点时非空
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
 }
 
 func (x *ListDirectoryResponse) Reset() {
 	*x = ListDirectoryResponse{}
-	mi := &file_metaServer_proto_msgTypes[12]
+	mi := &file_metaServer_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -928,7 +1193,7 @@ func (x *ListDirectoryResponse) String() string {
 func (*ListDirectoryResponse) ProtoMessage() {}
 
 func (x *ListDirectoryResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_metaServer_proto_msgTypes[12]
+	mi := &file_metaServer_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -941,7 +1206,7 @@ func (x *ListDirectoryResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListDirectoryResponse.ProtoReflect.Descriptor instead.
 func (*ListDirectoryResponse) Descriptor() ([]byte, []int) {
-	return file_metaServer_proto_rawDescGZIP(), []int{12}
+	return file_metaServer_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *ListDirectoryResponse) GetNodes() []*StatInfo {
@@ -951,18 +1216,26 @@ func (x *ListDirectoryResponse) GetNodes() []*StatInfo {
 	return nil
 }
 
+func (x *ListDirectoryResponse) GetNextContinuationToken() string {
+	if x != nil {
+		return x.NextContinuationToken
+	}
+	return ""
+}
+
 // DeleteNode
 type DeleteNodeRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
 	Recursive     bool                   `protobuf:"varint,2,opt,name=recursive,proto3" json:"recursive,omitempty"`
+	SkipTrash     bool                   `protobuf:"varint,3,opt,name=skip_trash,json=skipTrash,proto3" json:"skip_trash,omitempty"` // 跳过回收站直接删除
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *DeleteNodeRequest) Reset() {
 	*x = DeleteNodeRequest{}
-	mi := &file_metaServer_proto_msgTypes[13]
+	mi := &file_metaServer_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -974,7 +1247,7 @@ func (x *DeleteNodeRequest) String() string {
 func (*DeleteNodeRequest) ProtoMessage() {}
 
 func (x *DeleteNodeRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_metaServer_proto_msgTypes[13]
+	mi := &file_metaServer_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -987,7 +1260,7 @@ func (x *DeleteNodeRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteNodeRequest.ProtoReflect.Descriptor instead.
 func (*DeleteNodeRequest) Descriptor() ([]byte, []int) {
-	return file_metaServer_proto_rawDescGZIP(), []int{13}
+	return file_metaServer_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *DeleteNodeRequest) GetPath() string {
@@ -1004,30 +1277,36 @@ func (x *DeleteNodeRequest) GetRecursive() bool {
 	return false
 }
 
-// GetBlockLocations
-type GetBlockLocationsRequest struct {
+func (x *DeleteNodeRequest) GetSkipTrash() bool {
+	if x != nil {
+		return x.SkipTrash
+	}
+	return false
+}
+
+// RestoreNode
+type RestoreNodeRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
-	Size          int64                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"` // 对于写操作，Client 告诉 metaServer 文件总大小
+	TrashPath     string                 `protobuf:"bytes,1,opt,name=trash_path,json=trashPath,proto3" json:"trash_path,omitempty"` // 回收站中的路径，如 /.Trash/<时间>/a/b
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetBlockLocationsRequest) Reset() {
-	*x = GetBlockLocationsRequest{}
-	mi := &file_metaServer_proto_msgTypes[14]
+func (x *RestoreNodeRequest) Reset() {
+	*x = RestoreNodeRequest{}
+	mi := &file_metaServer_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetBlockLocationsRequest) String() string {
+func (x *RestoreNodeRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetBlockLocationsRequest) ProtoMessage() {}
+func (*RestoreNodeRequest) ProtoMessage() {}
 
-func (x *GetBlockLocationsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_metaServer_proto_msgTypes[14]
+func (x *RestoreNodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1038,48 +1317,41 @@ func (x *GetBlockLocationsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetBlockLocationsRequest.ProtoReflect.Descriptor instead.
-func (*GetBlockLocationsRequest) Descriptor() ([]byte, []int) {
-	return file_metaServer_proto_rawDescGZIP(), []int{14}
+// Deprecated: Use RestoreNodeRequest.ProtoReflect.Descriptor instead.
+func (*RestoreNodeRequest) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{15}
 }
 
-func (x *GetBlockLocationsRequest) GetPath() string {
+func (x *RestoreNodeRequest) GetTrashPath() string {
 	if x != nil {
-		return x.Path
+		return x.TrashPath
 	}
 	return ""
 }
 
-func (x *GetBlockLocationsRequest) GetSize() int64 {
-	if x != nil {
-		return x.Size
-	}
-	return 0
-}
-
-type GetBlockLocationsResponse struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	Inode          uint64                 `protobuf:"varint,1,opt,name=inode,proto3" json:"inode,omitempty"`
-	BlockLocations []*BlockLocations      `protobuf:"bytes,2,rep,name=block_locations,json=blockLocations,proto3" json:"block_locations,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+type RestoreNodeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"` // 恢复后的路径
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetBlockLocationsResponse) Reset() {
-	*x = GetBlockLocationsResponse{}
-	mi := &file_metaServer_proto_msgTypes[15]
+func (x *RestoreNodeResponse) Reset() {
+	*x = RestoreNodeResponse{}
+	mi := &file_metaServer_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetBlockLocationsResponse) String() string {
+func (x *RestoreNodeResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetBlockLocationsResponse) ProtoMessage() {}
+func (*RestoreNodeResponse) ProtoMessage() {}
 
-func (x *GetBlockLocationsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_metaServer_proto_msgTypes[15]
+func (x *RestoreNodeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1090,51 +1362,3878 @@ func (x *GetBlockLocationsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetBlockLocationsResponse.ProtoReflect.Descriptor instead.
-func (*GetBlockLocationsResponse) Descriptor() ([]byte, []int) {
-	return file_metaServer_proto_rawDescGZIP(), []int{15}
+// Deprecated: Use RestoreNodeResponse.ProtoReflect.Descriptor instead.
+func (*RestoreNodeResponse) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{16}
 }
 
-func (x *GetBlockLocationsResponse) GetInode() uint64 {
+func (x *RestoreNodeResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *RestoreNodeResponse) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+// Rename
+type RenameRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Src           string                 `protobuf:"bytes,1,opt,name=src,proto3" json:"src,omitempty"`
+	Dst           string                 `protobuf:"bytes,2,opt,name=dst,proto3" json:"dst,omitempty"`
+	Overwrite     bool                   `protobuf:"varint,3,opt,name=overwrite,proto3" json:"overwrite,omitempty"` // 目标已存在时是否覆盖
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenameRequest) Reset() {
+	*x = RenameRequest{}
+	mi := &file_metaServer_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenameRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenameRequest) ProtoMessage() {}
+
+func (x *RenameRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenameRequest.ProtoReflect.Descriptor instead.
+func (*RenameRequest) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *RenameRequest) GetSrc() string {
+	if x != nil {
+		return x.Src
+	}
+	return ""
+}
+
+func (x *RenameRequest) GetDst() string {
+	if x != nil {
+		return x.Dst
+	}
+	return ""
+}
+
+func (x *RenameRequest) GetOverwrite() bool {
+	if x != nil {
+		return x.Overwrite
+	}
+	return false
+}
+
+// GetBlockLocations
+type GetBlockLocationsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Size          int64                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`                              // 对于写操作，Client 告诉 metaServer 文件总大小；追加模式下为追加的字节数
+	Append        bool                   `protobuf:"varint,3,opt,name=append,proto3" json:"append,omitempty"`                          // 追加模式，只分配文件末尾之后需要的块
+	ClientName    string                 `protobuf:"bytes,4,opt,name=client_name,json=clientName,proto3" json:"client_name,omitempty"` // 写入方标识，用于写租约；为空时使用连接地址
+	Replication   uint32                 `protobuf:"varint,5,opt,name=replication,proto3" json:"replication,omitempty"`                // 新建或覆盖写时的副本数，0 表示使用默认值或保留文件原有的副本数
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBlockLocationsRequest) Reset() {
+	*x = GetBlockLocationsRequest{}
+	mi := &file_metaServer_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBlockLocationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBlockLocationsRequest) ProtoMessage() {}
+
+func (x *GetBlockLocationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBlockLocationsRequest.ProtoReflect.Descriptor instead.
+func (*GetBlockLocationsRequest) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *GetBlockLocationsRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *GetBlockLocationsRequest) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *GetBlockLocationsRequest) GetAppend() bool {
+	if x != nil {
+		return x.Append
+	}
+	return false
+}
+
+func (x *GetBlockLocationsRequest) GetClientName() string {
+	if x != nil {
+		return x.ClientName
+	}
+	return ""
+}
+
+func (x *GetBlockLocationsRequest) GetReplication() uint32 {
+	if x != nil {
+		return x.Replication
+	}
+	return 0
+}
+
+type GetBlockLocationsResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Inode           uint64                 `protobuf:"varint,1,opt,name=inode,proto3" json:"inode,omitempty"`
+	BlockLocations  []*BlockLocations      `protobuf:"bytes,2,rep,name=block_locations,json=blockLocations,proto3" json:"block_locations,omitempty"`
+	FirstBlockIndex uint64                 `protobuf:"varint,3,opt,name=first_block_index,json=firstBlockIndex,proto3" json:"first_block_index,omitempty"` // block_locations[0] 在文件中的块索引，追加模式下可能不为 0
+	TailOffset      uint64                 `protobuf:"varint,4,opt,name=tail_offset,json=tailOffset,proto3" json:"tail_offset,omitempty"`                  // 追加模式下第一个块已有的数据长度，Client 需重写该块：先写入已有的前 tail_offset 字节，再写追加的数据
+	PrevTail        *BlockLocations        `protobuf:"bytes,5,opt,name=prev_tail,json=prevTail,proto3" json:"prev_tail,omitempty"`                         // 追加模式下尾块原来的位置，Client 从中读取已有的前 tail_offset 字节；block_locations[0] 为替换它的新块
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *GetBlockLocationsResponse) Reset() {
+	*x = GetBlockLocationsResponse{}
+	mi := &file_metaServer_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBlockLocationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBlockLocationsResponse) ProtoMessage() {}
+
+func (x *GetBlockLocationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBlockLocationsResponse.ProtoReflect.Descriptor instead.
+func (*GetBlockLocationsResponse) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *GetBlockLocationsResponse) GetInode() uint64 {
 	if x != nil {
 		return x.Inode
 	}
-	return 0
+	return 0
+}
+
+func (x *GetBlockLocationsResponse) GetBlockLocations() []*BlockLocations {
+	if x != nil {
+		return x.BlockLocations
+	}
+	return nil
+}
+
+func (x *GetBlockLocationsResponse) GetFirstBlockIndex() uint64 {
+	if x != nil {
+		return x.FirstBlockIndex
+	}
+	return 0
+}
+
+func (x *GetBlockLocationsResponse) GetTailOffset() uint64 {
+	if x != nil {
+		return x.TailOffset
+	}
+	return 0
+}
+
+func (x *GetBlockLocationsResponse) GetPrevTail() *BlockLocations {
+	if x != nil {
+		return x.PrevTail
+	}
+	return nil
+}
+
+// GetBlockRange
+type GetBlockRangeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Offset        int64                  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"` // 文件内起始偏移
+	Length        int64                  `protobuf:"varint,3,opt,name=length,proto3" json:"length,omitempty"` // 读取长度，0 表示读到文件末尾
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBlockRangeRequest) Reset() {
+	*x = GetBlockRangeRequest{}
+	mi := &file_metaServer_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBlockRangeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBlockRangeRequest) ProtoMessage() {}
+
+func (x *GetBlockRangeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBlockRangeRequest.ProtoReflect.Descriptor instead.
+func (*GetBlockRangeRequest) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *GetBlockRangeRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *GetBlockRangeRequest) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *GetBlockRangeRequest) GetLength() int64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+// 一个数据块内需要读取的范围，可直接用于 ReadBlock 的 offset/length
+type BlockRange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BlockIndex    uint64                 `protobuf:"varint,1,opt,name=block_index,json=blockIndex,proto3" json:"block_index,omitempty"`    // 块在文件中的索引
+	BlockOffset   uint64                 `protobuf:"varint,2,opt,name=block_offset,json=blockOffset,proto3" json:"block_offset,omitempty"` // 块内起始偏移
+	Length        uint64                 `protobuf:"varint,3,opt,name=length,proto3" json:"length,omitempty"`                              // 块内读取长度
+	Block         *BlockLocations        `protobuf:"bytes,4,opt,name=block,proto3" json:"block,omitempty"`                                 // 块ID及副本位置
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BlockRange) Reset() {
+	*x = BlockRange{}
+	mi := &file_metaServer_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BlockRange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlockRange) ProtoMessage() {}
+
+func (x *BlockRange) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlockRange.ProtoReflect.Descriptor instead.
+func (*BlockRange) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *BlockRange) GetBlockIndex() uint64 {
+	if x != nil {
+		return x.BlockIndex
+	}
+	return 0
+}
+
+func (x *BlockRange) GetBlockOffset() uint64 {
+	if x != nil {
+		return x.BlockOffset
+	}
+	return 0
+}
+
+func (x *BlockRange) GetLength() uint64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *BlockRange) GetBlock() *BlockLocations {
+	if x != nil {
+		return x.Block
+	}
+	return nil
+}
+
+type GetBlockRangeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Inode         uint64                 `protobuf:"varint,1,opt,name=inode,proto3" json:"inode,omitempty"`
+	Size          int64                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`    // 文件大小
+	Ranges        []*BlockRange          `protobuf:"bytes,3,rep,name=ranges,proto3" json:"ranges,omitempty"` // 按文件偏移排序，超出文件末尾的部分会被截断
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBlockRangeResponse) Reset() {
+	*x = GetBlockRangeResponse{}
+	mi := &file_metaServer_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBlockRangeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBlockRangeResponse) ProtoMessage() {}
+
+func (x *GetBlockRangeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBlockRangeResponse.ProtoReflect.Descriptor instead.
+func (*GetBlockRangeResponse) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *GetBlockRangeResponse) GetInode() uint64 {
+	if x != nil {
+		return x.Inode
+	}
+	return 0
+}
+
+func (x *GetBlockRangeResponse) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *GetBlockRangeResponse) GetRanges() []*BlockRange {
+	if x != nil {
+		return x.Ranges
+	}
+	return nil
+}
+
+// FinalizeWrite
+type FinalizeWriteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Inode         uint64                 `protobuf:"varint,2,opt,name=inode,proto3" json:"inode,omitempty"`
+	Size          int64                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	Md5           string                 `protobuf:"bytes,4,opt,name=md5,proto3" json:"md5,omitempty"`
+	ClientName    string                 `protobuf:"bytes,5,opt,name=client_name,json=clientName,proto3" json:"client_name,omitempty"` // 与 GetBlockLocations 一致的写入方标识
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FinalizeWriteRequest) Reset() {
+	*x = FinalizeWriteRequest{}
+	mi := &file_metaServer_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FinalizeWriteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FinalizeWriteRequest) ProtoMessage() {}
+
+func (x *FinalizeWriteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FinalizeWriteRequest.ProtoReflect.Descriptor instead.
+func (*FinalizeWriteRequest) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *FinalizeWriteRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *FinalizeWriteRequest) GetInode() uint64 {
+	if x != nil {
+		return x.Inode
+	}
+	return 0
+}
+
+func (x *FinalizeWriteRequest) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *FinalizeWriteRequest) GetMd5() string {
+	if x != nil {
+		return x.Md5
+	}
+	return ""
+}
+
+func (x *FinalizeWriteRequest) GetClientName() string {
+	if x != nil {
+		return x.ClientName
+	}
+	return ""
+}
+
+// RenewLease
+type RenewLeaseRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ClientName    string                 `protobuf:"bytes,1,opt,name=client_name,json=clientName,proto3" json:"client_name,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"` // 为空时续约该写入方持有的所有租约
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenewLeaseRequest) Reset() {
+	*x = RenewLeaseRequest{}
+	mi := &file_metaServer_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenewLeaseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenewLeaseRequest) ProtoMessage() {}
+
+func (x *RenewLeaseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenewLeaseRequest.ProtoReflect.Descriptor instead.
+func (*RenewLeaseRequest) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *RenewLeaseRequest) GetClientName() string {
+	if x != nil {
+		return x.ClientName
+	}
+	return ""
+}
+
+func (x *RenewLeaseRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+// GetClusterInfo - 直接返回 easyClient 需要的 ClusterInfo
+type GetClusterInfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetClusterInfoRequest) Reset() {
+	*x = GetClusterInfoRequest{}
+	mi := &file_metaServer_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetClusterInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetClusterInfoRequest) ProtoMessage() {}
+
+func (x *GetClusterInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetClusterInfoRequest.ProtoReflect.Descriptor instead.
+func (*GetClusterInfoRequest) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{25}
+}
+
+type GetClusterInfoResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ClusterInfo   *ClusterInfo           `protobuf:"bytes,1,opt,name=clusterInfo,proto3" json:"clusterInfo,omitempty"` // 直接返回easyClient需要的格式
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetClusterInfoResponse) Reset() {
+	*x = GetClusterInfoResponse{}
+	mi := &file_metaServer_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetClusterInfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetClusterInfoResponse) ProtoMessage() {}
+
+func (x *GetClusterInfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetClusterInfoResponse.ProtoReflect.Descriptor instead.
+func (*GetClusterInfoResponse) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *GetClusterInfoResponse) GetClusterInfo() *ClusterInfo {
+	if x != nil {
+		return x.ClusterInfo
+	}
+	return nil
+}
+
+// Heartbeat (内部接口，保持不变以兼容DataServer)
+type HeartbeatRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	DataserverId    string                 `protobuf:"bytes,1,opt,name=dataServer_id,json=dataServerId,proto3" json:"dataServer_id,omitempty"`
+	DataserverAddr  string                 `protobuf:"bytes,2,opt,name=dataServer_addr,json=dataServerAddr,proto3" json:"dataServer_addr,omitempty"`
+	BlockCount      uint64                 `protobuf:"varint,3,opt,name=block_count,json=blockCount,proto3" json:"block_count,omitempty"`
+	FreeSpace       uint64                 `protobuf:"varint,4,opt,name=free_space,json=freeSpace,proto3" json:"free_space,omitempty"`
+	BlockIdsReport  []uint64               `protobuf:"varint,5,rep,packed,name=block_ids_report,json=blockIdsReport,proto3" json:"block_ids_report,omitempty"`
+	TotalCapacity   uint64                 `protobuf:"varint,6,opt,name=total_capacity,json=totalCapacity,proto3" json:"total_capacity,omitempty"`                // 总容量（字节）
+	CorruptBlockIds []uint64               `protobuf:"varint,7,rep,packed,name=corrupt_block_ids,json=corruptBlockIds,proto3" json:"corrupt_block_ids,omitempty"` // 校验和不匹配的块ID，需要从健康副本重新复制
+	ScrubStats      *ScrubStats            `protobuf:"bytes,8,opt,name=scrub_stats,json=scrubStats,proto3" json:"scrub_stats,omitempty"`                          // 后台巡检统计
+	Topology        string                 `protobuf:"bytes,9,opt,name=topology,proto3" json:"topology,omitempty"`                                                // 拓扑标签，如 /zone-a/rack-1，用于机架感知的副本放置
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *HeartbeatRequest) Reset() {
+	*x = HeartbeatRequest{}
+	mi := &file_metaServer_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeartbeatRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeartbeatRequest) ProtoMessage() {}
+
+func (x *HeartbeatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeartbeatRequest.ProtoReflect.Descriptor instead.
+func (*HeartbeatRequest) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *HeartbeatRequest) GetDataserverId() string {
+	if x != nil {
+		return x.DataserverId
+	}
+	return ""
+}
+
+func (x *HeartbeatRequest) GetDataserverAddr() string {
+	if x != nil {
+		return x.DataserverAddr
+	}
+	return ""
+}
+
+func (x *HeartbeatRequest) GetBlockCount() uint64 {
+	if x != nil {
+		return x.BlockCount
+	}
+	return 0
+}
+
+func (x *HeartbeatRequest) GetFreeSpace() uint64 {
+	if x != nil {
+		return x.FreeSpace
+	}
+	return 0
+}
+
+func (x *HeartbeatRequest) GetBlockIdsReport() []uint64 {
+	if x != nil {
+		return x.BlockIdsReport
+	}
+	return nil
+}
+
+func (x *HeartbeatRequest) GetTotalCapacity() uint64 {
+	if x != nil {
+		return x.TotalCapacity
+	}
+	return 0
+}
+
+func (x *HeartbeatRequest) GetCorruptBlockIds() []uint64 {
+	if x != nil {
+		return x.CorruptBlockIds
+	}
+	return nil
+}
+
+func (x *HeartbeatRequest) GetScrubStats() *ScrubStats {
+	if x != nil {
+		return x.ScrubStats
+	}
+	return nil
+}
+
+func (x *HeartbeatRequest) GetTopology() string {
+	if x != nil {
+		return x.Topology
+	}
+	return ""
+}
+
+// DataServer 后台块巡检统计
+type ScrubStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BlocksScanned uint64                 `protobuf:"varint,1,opt,name=blocks_scanned,json=blocksScanned,proto3" json:"blocks_scanned,omitempty"` // 累计扫描的块数
+	CorruptBlocks uint64                 `protobuf:"varint,2,opt,name=corrupt_blocks,json=corruptBlocks,proto3" json:"corrupt_blocks,omitempty"` // 累计发现的损坏块数
+	LastPassTime  int64                  `protobuf:"varint,3,opt,name=last_pass_time,json=lastPassTime,proto3" json:"last_pass_time,omitempty"`  // 最近一轮完成时间 Unix时间戳(毫秒)
+	BytesScanned  uint64                 `protobuf:"varint,4,opt,name=bytes_scanned,json=bytesScanned,proto3" json:"bytes_scanned,omitempty"`    // 累计扫描的字节数
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScrubStats) Reset() {
+	*x = ScrubStats{}
+	mi := &file_metaServer_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScrubStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScrubStats) ProtoMessage() {}
+
+func (x *ScrubStats) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScrubStats.ProtoReflect.Descriptor instead.
+func (*ScrubStats) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ScrubStats) GetBlocksScanned() uint64 {
+	if x != nil {
+		return x.BlocksScanned
+	}
+	return 0
+}
+
+func (x *ScrubStats) GetCorruptBlocks() uint64 {
+	if x != nil {
+		return x.CorruptBlocks
+	}
+	return 0
+}
+
+func (x *ScrubStats) GetLastPassTime() int64 {
+	if x != nil {
+		return x.LastPassTime
+	}
+	return 0
+}
+
+func (x *ScrubStats) GetBytesScanned() uint64 {
+	if x != nil {
+		return x.BytesScanned
+	}
+	return 0
+}
+
+type Command struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Action        Command_Action         `protobuf:"varint,1,opt,name=action,proto3,enum=dfs_project.Command_Action" json:"action,omitempty"`
+	BlockId       uint64                 `protobuf:"varint,2,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
+	Targets       []string               `protobuf:"bytes,3,rep,name=targets,proto3" json:"targets,omitempty"`
+	Group         *BlockLocations        `protobuf:"bytes,4,opt,name=group,proto3" json:"group,omitempty"` // RECONSTRUCT_STRIPE 和 ENCODE_BLOCK 使用的纠删码块组
+	StripeIndex   uint32                 `protobuf:"varint,5,opt,name=stripe_index,json=stripeIndex,proto3" json:"stripe_index,omitempty"`
+	Bandwidth     uint64                 `protobuf:"varint,6,opt,name=bandwidth,proto3" json:"bandwidth,omitempty"` // COPY_BLOCK 的限速（字节/秒），0 表示不限速
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Command) Reset() {
+	*x = Command{}
+	mi := &file_metaServer_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Command) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Command) ProtoMessage() {}
+
+func (x *Command) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Command.ProtoReflect.Descriptor instead.
+func (*Command) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *Command) GetAction() Command_Action {
+	if x != nil {
+		return x.Action
+	}
+	return Command_DELETE_BLOCK
+}
+
+func (x *Command) GetBlockId() uint64 {
+	if x != nil {
+		return x.BlockId
+	}
+	return 0
+}
+
+func (x *Command) GetTargets() []string {
+	if x != nil {
+		return x.Targets
+	}
+	return nil
+}
+
+func (x *Command) GetGroup() *BlockLocations {
+	if x != nil {
+		return x.Group
+	}
+	return nil
+}
+
+func (x *Command) GetStripeIndex() uint32 {
+	if x != nil {
+		return x.StripeIndex
+	}
+	return 0
+}
+
+func (x *Command) GetBandwidth() uint64 {
+	if x != nil {
+		return x.Bandwidth
+	}
+	return 0
+}
+
+type HeartbeatResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Commands      []*Command             `protobuf:"bytes,1,rep,name=commands,proto3" json:"commands,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeartbeatResponse) Reset() {
+	*x = HeartbeatResponse{}
+	mi := &file_metaServer_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeartbeatResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeartbeatResponse) ProtoMessage() {}
+
+func (x *HeartbeatResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeartbeatResponse.ProtoReflect.Descriptor instead.
+func (*HeartbeatResponse) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *HeartbeatResponse) GetCommands() []*Command {
+	if x != nil {
+		return x.Commands
+	}
+	return nil
+}
+
+// GetReplicationInfo
+type GetReplicationInfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetReplicationInfoRequest) Reset() {
+	*x = GetReplicationInfoRequest{}
+	mi := &file_metaServer_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetReplicationInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetReplicationInfoRequest) ProtoMessage() {}
+
+func (x *GetReplicationInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetReplicationInfoRequest.ProtoReflect.Descriptor instead.
+func (*GetReplicationInfoRequest) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *GetReplicationInfoRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type BlockReplicationInfo struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	BlockId           uint64                 `protobuf:"varint,1,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
+	Locations         []string               `protobuf:"bytes,2,rep,name=locations,proto3" json:"locations,omitempty"`
+	ExpectedLocations []string               `protobuf:"bytes,3,rep,name=expected_locations,json=expectedLocations,proto3" json:"expected_locations,omitempty"`
+	ReplicaCount      uint32                 `protobuf:"varint,4,opt,name=replica_count,json=replicaCount,proto3" json:"replica_count,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *BlockReplicationInfo) Reset() {
+	*x = BlockReplicationInfo{}
+	mi := &file_metaServer_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BlockReplicationInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlockReplicationInfo) ProtoMessage() {}
+
+func (x *BlockReplicationInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlockReplicationInfo.ProtoReflect.Descriptor instead.
+func (*BlockReplicationInfo) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *BlockReplicationInfo) GetBlockId() uint64 {
+	if x != nil {
+		return x.BlockId
+	}
+	return 0
+}
+
+func (x *BlockReplicationInfo) GetLocations() []string {
+	if x != nil {
+		return x.Locations
+	}
+	return nil
+}
+
+func (x *BlockReplicationInfo) GetExpectedLocations() []string {
+	if x != nil {
+		return x.ExpectedLocations
+	}
+	return nil
+}
+
+func (x *BlockReplicationInfo) GetReplicaCount() uint32 {
+	if x != nil {
+		return x.ReplicaCount
+	}
+	return 0
+}
+
+type ReplicationStatus struct {
+	state            protoimpl.MessageState  `protogen:"open.v1"`
+	Path             string                  `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Inode            uint64                  `protobuf:"varint,2,opt,name=inode,proto3" json:"inode,omitempty"`
+	Size             int64                   `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"` // 改为 int64
+	ExpectedReplicas uint32                  `protobuf:"varint,4,opt,name=expected_replicas,json=expectedReplicas,proto3" json:"expected_replicas,omitempty"`
+	ActualReplicas   uint32                  `protobuf:"varint,5,opt,name=actual_replicas,json=actualReplicas,proto3" json:"actual_replicas,omitempty"`
+	Blocks           []*BlockReplicationInfo `protobuf:"bytes,6,rep,name=blocks,proto3" json:"blocks,omitempty"`
+	Status           string                  `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ReplicationStatus) Reset() {
+	*x = ReplicationStatus{}
+	mi := &file_metaServer_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplicationStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplicationStatus) ProtoMessage() {}
+
+func (x *ReplicationStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplicationStatus.ProtoReflect.Descriptor instead.
+func (*ReplicationStatus) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *ReplicationStatus) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ReplicationStatus) GetInode() uint64 {
+	if x != nil {
+		return x.Inode
+	}
+	return 0
+}
+
+func (x *ReplicationStatus) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *ReplicationStatus) GetExpectedReplicas() uint32 {
+	if x != nil {
+		return x.ExpectedReplicas
+	}
+	return 0
+}
+
+func (x *ReplicationStatus) GetActualReplicas() uint32 {
+	if x != nil {
+		return x.ActualReplicas
+	}
+	return 0
+}
+
+func (x *ReplicationStatus) GetBlocks() []*BlockReplicationInfo {
+	if x != nil {
+		return x.Blocks
+	}
+	return nil
+}
+
+func (x *ReplicationStatus) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+// Fsck
+type FsckRequest struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Path                string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`                                                             // 只检查该路径下的文件，为空或 / 时检查整个命名空间
+	OnlyUnderReplicated bool                   `protobuf:"varint,2,opt,name=only_under_replicated,json=onlyUnderReplicated,proto3" json:"only_under_replicated,omitempty"` // 以下过滤条件任一满足即返回，全部为 false 时返回所有块
+	OnlyMissing         bool                   `protobuf:"varint,3,opt,name=only_missing,json=onlyMissing,proto3" json:"only_missing,omitempty"`
+	OnlyCorrupt         bool                   `protobuf:"varint,4,opt,name=only_corrupt,json=onlyCorrupt,proto3" json:"only_corrupt,omitempty"`
+	TriggerFsck         bool                   `protobuf:"varint,5,opt,name=trigger_fsck,json=triggerFsck,proto3" json:"trigger_fsck,omitempty"` // 立即在 leader 上对该子树执行一次 FSCK 并调度修复
+	BatchSize           uint32                 `protobuf:"varint,6,opt,name=batch_size,json=batchSize,proto3" json:"batch_size,omitempty"`       // 每条消息最多携带的块数，0 使用默认值
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *FsckRequest) Reset() {
+	*x = FsckRequest{}
+	mi := &file_metaServer_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FsckRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FsckRequest) ProtoMessage() {}
+
+func (x *FsckRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FsckRequest.ProtoReflect.Descriptor instead.
+func (*FsckRequest) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *FsckRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *FsckRequest) GetOnlyUnderReplicated() bool {
+	if x != nil {
+		return x.OnlyUnderReplicated
+	}
+	return false
+}
+
+func (x *FsckRequest) GetOnlyMissing() bool {
+	if x != nil {
+		return x.OnlyMissing
+	}
+	return false
+}
+
+func (x *FsckRequest) GetOnlyCorrupt() bool {
+	if x != nil {
+		return x.OnlyCorrupt
+	}
+	return false
+}
+
+func (x *FsckRequest) GetTriggerFsck() bool {
+	if x != nil {
+		return x.TriggerFsck
+	}
+	return false
+}
+
+func (x *FsckRequest) GetBatchSize() uint32 {
+	if x != nil {
+		return x.BatchSize
+	}
+	return 0
+}
+
+type FsckBlock struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Path              string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"` // 所属文件，只被快照引用的块为空
+	Inode             uint64                 `protobuf:"varint,2,opt,name=inode,proto3" json:"inode,omitempty"`
+	BlockId           uint64                 `protobuf:"varint,3,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"` // 纠删码块组展开为各条带的块ID
+	GroupId           uint64                 `protobuf:"varint,4,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"` // 纠删码条带所属的块组ID，普通块为 0
+	Replication       uint32                 `protobuf:"varint,5,opt,name=replication,proto3" json:"replication,omitempty"`        // 目标副本数，纠删码条带和只被快照引用的块为 0
+	ExpectedLocations []string               `protobuf:"bytes,6,rep,name=expected_locations,json=expectedLocations,proto3" json:"expected_locations,omitempty"`
+	LiveLocations     []string               `protobuf:"bytes,7,rep,name=live_locations,json=liveLocations,proto3" json:"live_locations,omitempty"`          // 期望位置中实际上报该块的健康节点
+	CorruptLocations  []string               `protobuf:"bytes,8,rep,name=corrupt_locations,json=corruptLocations,proto3" json:"corrupt_locations,omitempty"` // 上报该块校验失败的节点
+	UnderReplicated   bool                   `protobuf:"varint,9,opt,name=under_replicated,json=underReplicated,proto3" json:"under_replicated,omitempty"`
+	Missing           bool                   `protobuf:"varint,10,opt,name=missing,proto3" json:"missing,omitempty"` // 没有任何可用副本
+	Corrupt           bool                   `protobuf:"varint,11,opt,name=corrupt,proto3" json:"corrupt,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *FsckBlock) Reset() {
+	*x = FsckBlock{}
+	mi := &file_metaServer_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FsckBlock) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FsckBlock) ProtoMessage() {}
+
+func (x *FsckBlock) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FsckBlock.ProtoReflect.Descriptor instead.
+func (*FsckBlock) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *FsckBlock) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *FsckBlock) GetInode() uint64 {
+	if x != nil {
+		return x.Inode
+	}
+	return 0
+}
+
+func (x *FsckBlock) GetBlockId() uint64 {
+	if x != nil {
+		return x.BlockId
+	}
+	return 0
+}
+
+func (x *FsckBlock) GetGroupId() uint64 {
+	if x != nil {
+		return x.GroupId
+	}
+	return 0
+}
+
+func (x *FsckBlock) GetReplication() uint32 {
+	if x != nil {
+		return x.Replication
+	}
+	return 0
+}
+
+func (x *FsckBlock) GetExpectedLocations() []string {
+	if x != nil {
+		return x.ExpectedLocations
+	}
+	return nil
+}
+
+func (x *FsckBlock) GetLiveLocations() []string {
+	if x != nil {
+		return x.LiveLocations
+	}
+	return nil
+}
+
+func (x *FsckBlock) GetCorruptLocations() []string {
+	if x != nil {
+		return x.CorruptLocations
+	}
+	return nil
+}
+
+func (x *FsckBlock) GetUnderReplicated() bool {
+	if x != nil {
+		return x.UnderReplicated
+	}
+	return false
+}
+
+func (x *FsckBlock) GetMissing() bool {
+	if x != nil {
+		return x.Missing
+	}
+	return false
+}
+
+func (x *FsckBlock) GetCorrupt() bool {
+	if x != nil {
+		return x.Corrupt
+	}
+	return false
+}
+
+type FsckSummary struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	TotalFiles            uint32                 `protobuf:"varint,1,opt,name=total_files,json=totalFiles,proto3" json:"total_files,omitempty"`
+	TotalBlocks           uint64                 `protobuf:"varint,2,opt,name=total_blocks,json=totalBlocks,proto3" json:"total_blocks,omitempty"`
+	HealthyBlocks         uint64                 `protobuf:"varint,3,opt,name=healthy_blocks,json=healthyBlocks,proto3" json:"healthy_blocks,omitempty"`
+	UnderReplicatedBlocks uint64                 `protobuf:"varint,4,opt,name=under_replicated_blocks,json=underReplicatedBlocks,proto3" json:"under_replicated_blocks,omitempty"` // 与 FSCK 的统计一致，包含 missing_blocks
+	MissingBlocks         uint64                 `protobuf:"varint,5,opt,name=missing_blocks,json=missingBlocks,proto3" json:"missing_blocks,omitempty"`
+	CorruptBlocks         uint64                 `protobuf:"varint,6,opt,name=corrupt_blocks,json=corruptBlocks,proto3" json:"corrupt_blocks,omitempty"`
+	OrphanBlocks          int64                  `protobuf:"varint,7,opt,name=orphan_blocks,json=orphanBlocks,proto3" json:"orphan_blocks,omitempty"` // 最近一次 FSCK 发现的孤儿块数，只在检查整个命名空间时给出，否则为 -1
+	FsckTriggered         bool                   `protobuf:"varint,8,opt,name=fsck_triggered,json=fsckTriggered,proto3" json:"fsck_triggered,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *FsckSummary) Reset() {
+	*x = FsckSummary{}
+	mi := &file_metaServer_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FsckSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FsckSummary) ProtoMessage() {}
+
+func (x *FsckSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FsckSummary.ProtoReflect.Descriptor instead.
+func (*FsckSummary) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *FsckSummary) GetTotalFiles() uint32 {
+	if x != nil {
+		return x.TotalFiles
+	}
+	return 0
+}
+
+func (x *FsckSummary) GetTotalBlocks() uint64 {
+	if x != nil {
+		return x.TotalBlocks
+	}
+	return 0
+}
+
+func (x *FsckSummary) GetHealthyBlocks() uint64 {
+	if x != nil {
+		return x.HealthyBlocks
+	}
+	return 0
+}
+
+func (x *FsckSummary) GetUnderReplicatedBlocks() uint64 {
+	if x != nil {
+		return x.UnderReplicatedBlocks
+	}
+	return 0
+}
+
+func (x *FsckSummary) GetMissingBlocks() uint64 {
+	if x != nil {
+		return x.MissingBlocks
+	}
+	return 0
+}
+
+func (x *FsckSummary) GetCorruptBlocks() uint64 {
+	if x != nil {
+		return x.CorruptBlocks
+	}
+	return 0
+}
+
+func (x *FsckSummary) GetOrphanBlocks() int64 {
+	if x != nil {
+		return x.OrphanBlocks
+	}
+	return 0
+}
+
+func (x *FsckSummary) GetFsckTriggered() bool {
+	if x != nil {
+		return x.FsckTriggered
+	}
+	return false
+}
+
+type FsckResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Blocks        []*FsckBlock           `protobuf:"bytes,1,rep,name=blocks,proto3" json:"blocks,omitempty"`
+	Summary       *FsckSummary           `protobuf:"bytes,2,opt,name=summary,proto3" json:"summary,omitempty"` // 只在最后一条消息中设置
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FsckResponse) Reset() {
+	*x = FsckResponse{}
+	mi := &file_metaServer_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FsckResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FsckResponse) ProtoMessage() {}
+
+func (x *FsckResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FsckResponse.ProtoReflect.Descriptor instead.
+func (*FsckResponse) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *FsckResponse) GetBlocks() []*FsckBlock {
+	if x != nil {
+		return x.Blocks
+	}
+	return nil
+}
+
+func (x *FsckResponse) GetSummary() *FsckSummary {
+	if x != nil {
+		return x.Summary
+	}
+	return nil
+}
+
+// GetOrphanReport
+type GetOrphanReportRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrphanReportRequest) Reset() {
+	*x = GetOrphanReportRequest{}
+	mi := &file_metaServer_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrphanReportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrphanReportRequest) ProtoMessage() {}
+
+func (x *GetOrphanReportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrphanReportRequest.ProtoReflect.Descriptor instead.
+func (*GetOrphanReportRequest) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{38}
+}
+
+type OrphanBlock struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BlockId       uint64                 `protobuf:"varint,1,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
+	Locations     []string               `protobuf:"bytes,2,rep,name=locations,proto3" json:"locations,omitempty"`
+	Passes        uint32                 `protobuf:"varint,3,opt,name=passes,proto3" json:"passes,omitempty"`            // 连续出现的 FSCK 次数
+	AgeMs         int64                  `protobuf:"varint,4,opt,name=age_ms,json=ageMs,proto3" json:"age_ms,omitempty"` // 按块ID中的时间戳计算的年龄，无法解析时为 -1
+	Eligible      bool                   `protobuf:"varint,5,opt,name=eligible,proto3" json:"eligible,omitempty"`        // 已达到删除条件
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrphanBlock) Reset() {
+	*x = OrphanBlock{}
+	mi := &file_metaServer_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrphanBlock) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrphanBlock) ProtoMessage() {}
+
+func (x *OrphanBlock) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrphanBlock.ProtoReflect.Descriptor instead.
+func (*OrphanBlock) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *OrphanBlock) GetBlockId() uint64 {
+	if x != nil {
+		return x.BlockId
+	}
+	return 0
+}
+
+func (x *OrphanBlock) GetLocations() []string {
+	if x != nil {
+		return x.Locations
+	}
+	return nil
+}
+
+func (x *OrphanBlock) GetPasses() uint32 {
+	if x != nil {
+		return x.Passes
+	}
+	return 0
+}
+
+func (x *OrphanBlock) GetAgeMs() int64 {
+	if x != nil {
+		return x.AgeMs
+	}
+	return 0
+}
+
+func (x *OrphanBlock) GetEligible() bool {
+	if x != nil {
+		return x.Eligible
+	}
+	return false
+}
+
+type GetOrphanReportResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DryRun        bool                   `protobuf:"varint,1,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`                        // 只报告不删除
+	LastPassTime  int64                  `protobuf:"varint,2,opt,name=last_pass_time,json=lastPassTime,proto3" json:"last_pass_time,omitempty"`    // 最近一次 FSCK 的时间 (Unix 毫秒)
+	LastPassFound uint32                 `protobuf:"varint,3,opt,name=last_pass_found,json=lastPassFound,proto3" json:"last_pass_found,omitempty"` // 最近一次 FSCK 发现的孤儿块数
+	Pending       uint32                 `protobuf:"varint,4,opt,name=pending,proto3" json:"pending,omitempty"`                                    // 仍在宽限期内的孤儿块数
+	DeletedTotal  uint64                 `protobuf:"varint,5,opt,name=deleted_total,json=deletedTotal,proto3" json:"deleted_total,omitempty"`      // 累计调度删除的孤儿块数
+	ReportedTotal uint64                 `protobuf:"varint,6,opt,name=reported_total,json=reportedTotal,proto3" json:"reported_total,omitempty"`   // dry-run 下累计达到删除条件但只报告的次数
+	Orphans       []*OrphanBlock         `protobuf:"bytes,7,rep,name=orphans,proto3" json:"orphans,omitempty"`                                     // 最近一次 FSCK 发现的孤儿块
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrphanReportResponse) Reset() {
+	*x = GetOrphanReportResponse{}
+	mi := &file_metaServer_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrphanReportResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrphanReportResponse) ProtoMessage() {}
+
+func (x *GetOrphanReportResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrphanReportResponse.ProtoReflect.Descriptor instead.
+func (*GetOrphanReportResponse) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *GetOrphanReportResponse) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *GetOrphanReportResponse) GetLastPassTime() int64 {
+	if x != nil {
+		return x.LastPassTime
+	}
+	return 0
+}
+
+func (x *GetOrphanReportResponse) GetLastPassFound() uint32 {
+	if x != nil {
+		return x.LastPassFound
+	}
+	return 0
+}
+
+func (x *GetOrphanReportResponse) GetPending() uint32 {
+	if x != nil {
+		return x.Pending
+	}
+	return 0
+}
+
+func (x *GetOrphanReportResponse) GetDeletedTotal() uint64 {
+	if x != nil {
+		return x.DeletedTotal
+	}
+	return 0
+}
+
+func (x *GetOrphanReportResponse) GetReportedTotal() uint64 {
+	if x != nil {
+		return x.ReportedTotal
+	}
+	return 0
+}
+
+func (x *GetOrphanReportResponse) GetOrphans() []*OrphanBlock {
+	if x != nil {
+		return x.Orphans
+	}
+	return nil
+}
+
+type GetReplicationInfoResponse struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Files                []*ReplicationStatus   `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
+	TotalFiles           uint32                 `protobuf:"varint,2,opt,name=total_files,json=totalFiles,proto3" json:"total_files,omitempty"`
+	HealthyFiles         uint32                 `protobuf:"varint,3,opt,name=healthy_files,json=healthyFiles,proto3" json:"healthy_files,omitempty"`
+	UnderReplicatedFiles uint32                 `protobuf:"varint,4,opt,name=under_replicated_files,json=underReplicatedFiles,proto3" json:"under_replicated_files,omitempty"`
+	OverReplicatedFiles  uint32                 `protobuf:"varint,5,opt,name=over_replicated_files,json=overReplicatedFiles,proto3" json:"over_replicated_files,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *GetReplicationInfoResponse) Reset() {
+	*x = GetReplicationInfoResponse{}
+	mi := &file_metaServer_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetReplicationInfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetReplicationInfoResponse) ProtoMessage() {}
+
+func (x *GetReplicationInfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetReplicationInfoResponse.ProtoReflect.Descriptor instead.
+func (*GetReplicationInfoResponse) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *GetReplicationInfoResponse) GetFiles() []*ReplicationStatus {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+func (x *GetReplicationInfoResponse) GetTotalFiles() uint32 {
+	if x != nil {
+		return x.TotalFiles
+	}
+	return 0
+}
+
+func (x *GetReplicationInfoResponse) GetHealthyFiles() uint32 {
+	if x != nil {
+		return x.HealthyFiles
+	}
+	return 0
+}
+
+func (x *GetReplicationInfoResponse) GetUnderReplicatedFiles() uint32 {
+	if x != nil {
+		return x.UnderReplicatedFiles
+	}
+	return 0
+}
+
+func (x *GetReplicationInfoResponse) GetOverReplicatedFiles() uint32 {
+	if x != nil {
+		return x.OverReplicatedFiles
+	}
+	return 0
+}
+
+// SetReplication
+type SetReplicationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Replication   uint32                 `protobuf:"varint,2,opt,name=replication,proto3" json:"replication,omitempty"` // 1 到 cluster.max_replication
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetReplicationRequest) Reset() {
+	*x = SetReplicationRequest{}
+	mi := &file_metaServer_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetReplicationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetReplicationRequest) ProtoMessage() {}
+
+func (x *SetReplicationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetReplicationRequest.ProtoReflect.Descriptor instead.
+func (*SetReplicationRequest) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *SetReplicationRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *SetReplicationRequest) GetReplication() uint32 {
+	if x != nil {
+		return x.Replication
+	}
+	return 0
+}
+
+type ChmodRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Mode          uint32                 `protobuf:"varint,2,opt,name=mode,proto3" json:"mode,omitempty"` // 权限位 (如 0750)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChmodRequest) Reset() {
+	*x = ChmodRequest{}
+	mi := &file_metaServer_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChmodRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChmodRequest) ProtoMessage() {}
+
+func (x *ChmodRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChmodRequest.ProtoReflect.Descriptor instead.
+func (*ChmodRequest) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *ChmodRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ChmodRequest) GetMode() uint32 {
+	if x != nil {
+		return x.Mode
+	}
+	return 0
+}
+
+type ChownRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Owner         string                 `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"` // 为空时不修改
+	Group         string                 `protobuf:"bytes,3,opt,name=group,proto3" json:"group,omitempty"` // 为空时不修改
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChownRequest) Reset() {
+	*x = ChownRequest{}
+	mi := &file_metaServer_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChownRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChownRequest) ProtoMessage() {}
+
+func (x *ChownRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChownRequest.ProtoReflect.Descriptor instead.
+func (*ChownRequest) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *ChownRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ChownRequest) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *ChownRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+type SetAclRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Acl           []*AclEntry            `protobuf:"bytes,2,rep,name=acl,proto3" json:"acl,omitempty"` // 为空时删除所有 ACL 条目
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetAclRequest) Reset() {
+	*x = SetAclRequest{}
+	mi := &file_metaServer_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetAclRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetAclRequest) ProtoMessage() {}
+
+func (x *SetAclRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetAclRequest.ProtoReflect.Descriptor instead.
+func (*SetAclRequest) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *SetAclRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *SetAclRequest) GetAcl() []*AclEntry {
+	if x != nil {
+		return x.Acl
+	}
+	return nil
+}
+
+// 目录的使用量和配额
+type DirectoryUsage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Inode         uint64                 `protobuf:"varint,2,opt,name=inode,proto3" json:"inode,omitempty"`
+	Bytes         int64                  `protobuf:"varint,3,opt,name=bytes,proto3" json:"bytes,omitempty"`                                      // 文件逻辑大小之和
+	SpaceConsumed int64                  `protobuf:"varint,4,opt,name=space_consumed,json=spaceConsumed,proto3" json:"space_consumed,omitempty"` // 按副本数计算的占用空间
+	InodeCount    int64                  `protobuf:"varint,5,opt,name=inode_count,json=inodeCount,proto3" json:"inode_count,omitempty"`          // 子树中的节点数（包括目录本身）
+	MaxBytes      uint64                 `protobuf:"varint,6,opt,name=max_bytes,json=maxBytes,proto3" json:"max_bytes,omitempty"`                // 空间配额（按副本数计算），0 表示不限制
+	MaxInodes     uint64                 `protobuf:"varint,7,opt,name=max_inodes,json=maxInodes,proto3" json:"max_inodes,omitempty"`             // 节点数配额，0 表示不限制
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DirectoryUsage) Reset() {
+	*x = DirectoryUsage{}
+	mi := &file_metaServer_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DirectoryUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DirectoryUsage) ProtoMessage() {}
+
+func (x *DirectoryUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DirectoryUsage.ProtoReflect.Descriptor instead.
+func (*DirectoryUsage) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *DirectoryUsage) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *DirectoryUsage) GetInode() uint64 {
+	if x != nil {
+		return x.Inode
+	}
+	return 0
+}
+
+func (x *DirectoryUsage) GetBytes() int64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+func (x *DirectoryUsage) GetSpaceConsumed() int64 {
+	if x != nil {
+		return x.SpaceConsumed
+	}
+	return 0
+}
+
+func (x *DirectoryUsage) GetInodeCount() int64 {
+	if x != nil {
+		return x.InodeCount
+	}
+	return 0
+}
+
+func (x *DirectoryUsage) GetMaxBytes() uint64 {
+	if x != nil {
+		return x.MaxBytes
+	}
+	return 0
+}
+
+func (x *DirectoryUsage) GetMaxInodes() uint64 {
+	if x != nil {
+		return x.MaxInodes
+	}
+	return 0
+}
+
+type SetQuotaRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	MaxBytes      uint64                 `protobuf:"varint,2,opt,name=max_bytes,json=maxBytes,proto3" json:"max_bytes,omitempty"`
+	MaxInodes     uint64                 `protobuf:"varint,3,opt,name=max_inodes,json=maxInodes,proto3" json:"max_inodes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetQuotaRequest) Reset() {
+	*x = SetQuotaRequest{}
+	mi := &file_metaServer_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetQuotaRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetQuotaRequest) ProtoMessage() {}
+
+func (x *SetQuotaRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetQuotaRequest.ProtoReflect.Descriptor instead.
+func (*SetQuotaRequest) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *SetQuotaRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *SetQuotaRequest) GetMaxBytes() uint64 {
+	if x != nil {
+		return x.MaxBytes
+	}
+	return 0
+}
+
+func (x *SetQuotaRequest) GetMaxInodes() uint64 {
+	if x != nil {
+		return x.MaxInodes
+	}
+	return 0
+}
+
+type GetQuotaRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetQuotaRequest) Reset() {
+	*x = GetQuotaRequest{}
+	mi := &file_metaServer_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetQuotaRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetQuotaRequest) ProtoMessage() {}
+
+func (x *GetQuotaRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetQuotaRequest.ProtoReflect.Descriptor instead.
+func (*GetQuotaRequest) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *GetQuotaRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type GetQuotaResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Usage         *DirectoryUsage        `protobuf:"bytes,1,opt,name=usage,proto3" json:"usage,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetQuotaResponse) Reset() {
+	*x = GetQuotaResponse{}
+	mi := &file_metaServer_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetQuotaResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetQuotaResponse) ProtoMessage() {}
+
+func (x *GetQuotaResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetQuotaResponse.ProtoReflect.Descriptor instead.
+func (*GetQuotaResponse) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *GetQuotaResponse) GetUsage() *DirectoryUsage {
+	if x != nil {
+		return x.Usage
+	}
+	return nil
+}
+
+type GetUsageReportRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Recursive     bool                   `protobuf:"varint,2,opt,name=recursive,proto3" json:"recursive,omitempty"` // 包括所有子孙目录，否则只包括直接子目录
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUsageReportRequest) Reset() {
+	*x = GetUsageReportRequest{}
+	mi := &file_metaServer_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUsageReportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUsageReportRequest) ProtoMessage() {}
+
+func (x *GetUsageReportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUsageReportRequest.ProtoReflect.Descriptor instead.
+func (*GetUsageReportRequest) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *GetUsageReportRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *GetUsageReportRequest) GetRecursive() bool {
+	if x != nil {
+		return x.Recursive
+	}
+	return false
+}
+
+type GetUsageReportResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Directories   []*DirectoryUsage      `protobuf:"bytes,1,rep,name=directories,proto3" json:"directories,omitempty"` // 第一项为 path 本身
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUsageReportResponse) Reset() {
+	*x = GetUsageReportResponse{}
+	mi := &file_metaServer_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUsageReportResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUsageReportResponse) ProtoMessage() {}
+
+func (x *GetUsageReportResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUsageReportResponse.ProtoReflect.Descriptor instead.
+func (*GetUsageReportResponse) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *GetUsageReportResponse) GetDirectories() []*DirectoryUsage {
+	if x != nil {
+		return x.Directories
+	}
+	return nil
+}
+
+type SnapshotInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`                                // 被快照的目录
+	CreatedAt     int64                  `protobuf:"varint,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`    // 创建时间 (Unix 毫秒)
+	Bytes         int64                  `protobuf:"varint,4,opt,name=bytes,proto3" json:"bytes,omitempty"`                             // 快照中文件逻辑大小之和
+	InodeCount    int64                  `protobuf:"varint,5,opt,name=inode_count,json=inodeCount,proto3" json:"inode_count,omitempty"` // 快照中的节点数（包括目录本身）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SnapshotInfo) Reset() {
+	*x = SnapshotInfo{}
+	mi := &file_metaServer_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SnapshotInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotInfo) ProtoMessage() {}
+
+func (x *SnapshotInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotInfo.ProtoReflect.Descriptor instead.
+func (*SnapshotInfo) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *SnapshotInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SnapshotInfo) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *SnapshotInfo) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *SnapshotInfo) GetBytes() int64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+func (x *SnapshotInfo) GetInodeCount() int64 {
+	if x != nil {
+		return x.InodeCount
+	}
+	return 0
+}
+
+type CreateSnapshotRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"` // 全局唯一，不能包含 '/'
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateSnapshotRequest) Reset() {
+	*x = CreateSnapshotRequest{}
+	mi := &file_metaServer_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateSnapshotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateSnapshotRequest) ProtoMessage() {}
+
+func (x *CreateSnapshotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateSnapshotRequest.ProtoReflect.Descriptor instead.
+func (*CreateSnapshotRequest) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *CreateSnapshotRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *CreateSnapshotRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type DeleteSnapshotRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteSnapshotRequest) Reset() {
+	*x = DeleteSnapshotRequest{}
+	mi := &file_metaServer_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteSnapshotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteSnapshotRequest) ProtoMessage() {}
+
+func (x *DeleteSnapshotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteSnapshotRequest.ProtoReflect.Descriptor instead.
+func (*DeleteSnapshotRequest) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *DeleteSnapshotRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type ListSnapshotsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSnapshotsRequest) Reset() {
+	*x = ListSnapshotsRequest{}
+	mi := &file_metaServer_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSnapshotsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSnapshotsRequest) ProtoMessage() {}
+
+func (x *ListSnapshotsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSnapshotsRequest.ProtoReflect.Descriptor instead.
+func (*ListSnapshotsRequest) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{55}
+}
+
+type ListSnapshotsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Snapshots     []*SnapshotInfo        `protobuf:"bytes,1,rep,name=snapshots,proto3" json:"snapshots,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSnapshotsResponse) Reset() {
+	*x = ListSnapshotsResponse{}
+	mi := &file_metaServer_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSnapshotsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSnapshotsResponse) ProtoMessage() {}
+
+func (x *ListSnapshotsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSnapshotsResponse.ProtoReflect.Descriptor instead.
+func (*ListSnapshotsResponse) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{56}
+}
+
+func (x *ListSnapshotsResponse) GetSnapshots() []*SnapshotInfo {
+	if x != nil {
+		return x.Snapshots
+	}
+	return nil
+}
+
+type SetErasureCodingPolicyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Policy        string                 `protobuf:"bytes,2,opt,name=policy,proto3" json:"policy,omitempty"` // RS-<k>-<m>，为空时删除目录上的策略
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetErasureCodingPolicyRequest) Reset() {
+	*x = SetErasureCodingPolicyRequest{}
+	mi := &file_metaServer_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetErasureCodingPolicyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetErasureCodingPolicyRequest) ProtoMessage() {}
+
+func (x *SetErasureCodingPolicyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[57]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetErasureCodingPolicyRequest.ProtoReflect.Descriptor instead.
+func (*SetErasureCodingPolicyRequest) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{57}
+}
+
+func (x *SetErasureCodingPolicyRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *SetErasureCodingPolicyRequest) GetPolicy() string {
+	if x != nil {
+		return x.Policy
+	}
+	return ""
+}
+
+type GetErasureCodingPolicyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetErasureCodingPolicyRequest) Reset() {
+	*x = GetErasureCodingPolicyRequest{}
+	mi := &file_metaServer_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetErasureCodingPolicyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetErasureCodingPolicyRequest) ProtoMessage() {}
+
+func (x *GetErasureCodingPolicyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetErasureCodingPolicyRequest.ProtoReflect.Descriptor instead.
+func (*GetErasureCodingPolicyRequest) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{58}
+}
+
+func (x *GetErasureCodingPolicyRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type GetErasureCodingPolicyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Policy        string                 `protobuf:"bytes,1,opt,name=policy,proto3" json:"policy,omitempty"`       // 为空时为多副本
+	Directory     string                 `protobuf:"bytes,2,opt,name=directory,proto3" json:"directory,omitempty"` // 设置该策略的目录
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetErasureCodingPolicyResponse) Reset() {
+	*x = GetErasureCodingPolicyResponse{}
+	mi := &file_metaServer_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetErasureCodingPolicyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetErasureCodingPolicyResponse) ProtoMessage() {}
+
+func (x *GetErasureCodingPolicyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetErasureCodingPolicyResponse.ProtoReflect.Descriptor instead.
+func (*GetErasureCodingPolicyResponse) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{59}
+}
+
+func (x *GetErasureCodingPolicyResponse) GetPolicy() string {
+	if x != nil {
+		return x.Policy
+	}
+	return ""
+}
+
+func (x *GetErasureCodingPolicyResponse) GetDirectory() string {
+	if x != nil {
+		return x.Directory
+	}
+	return ""
+}
+
+type SetDataServerStateRequest struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Address             string                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`                                                     // DataServer 地址 (host:port)
+	State               string                 `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`                                                         // in_service / decommissioning / maintenance
+	MaintenanceDuration int64                  `protobuf:"varint,3,opt,name=maintenance_duration,json=maintenanceDuration,proto3" json:"maintenance_duration,omitempty"` // 维护模式持续时间（秒），0 表示使用 cluster.maintenance_duration
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *SetDataServerStateRequest) Reset() {
+	*x = SetDataServerStateRequest{}
+	mi := &file_metaServer_proto_msgTypes[60]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetDataServerStateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetDataServerStateRequest) ProtoMessage() {}
+
+func (x *SetDataServerStateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[60]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetDataServerStateRequest.ProtoReflect.Descriptor instead.
+func (*SetDataServerStateRequest) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{60}
+}
+
+func (x *SetDataServerStateRequest) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *SetDataServerStateRequest) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *SetDataServerStateRequest) GetMaintenanceDuration() int64 {
+	if x != nil {
+		return x.MaintenanceDuration
+	}
+	return 0
+}
+
+type ListDataServerStatesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDataServerStatesRequest) Reset() {
+	*x = ListDataServerStatesRequest{}
+	mi := &file_metaServer_proto_msgTypes[61]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDataServerStatesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDataServerStatesRequest) ProtoMessage() {}
+
+func (x *ListDataServerStatesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[61]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDataServerStatesRequest.ProtoReflect.Descriptor instead.
+func (*ListDataServerStatesRequest) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{61}
+}
+
+type DataServerState struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	Address               string                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Id                    string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`       // 未在集群中时为空
+	State                 string                 `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"` // in_service / decommissioning / decommissioned / maintenance
+	Healthy               bool                   `protobuf:"varint,4,opt,name=healthy,proto3" json:"healthy,omitempty"`
+	StateTime             int64                  `protobuf:"varint,5,opt,name=state_time,json=stateTime,proto3" json:"state_time,omitempty"`                                       // 进入该状态的时间 Unix时间戳(毫秒)
+	MaintenanceExpireTime int64                  `protobuf:"varint,6,opt,name=maintenance_expire_time,json=maintenanceExpireTime,proto3" json:"maintenance_expire_time,omitempty"` // 维护模式到期时间 Unix时间戳(毫秒)
+	RemainingBlocks       uint64                 `protobuf:"varint,7,opt,name=remaining_blocks,json=remainingBlocks,proto3" json:"remaining_blocks,omitempty"`                     // 退役中仍引用该节点的块数（最近一次 FSCK）
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *DataServerState) Reset() {
+	*x = DataServerState{}
+	mi := &file_metaServer_proto_msgTypes[62]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DataServerState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DataServerState) ProtoMessage() {}
+
+func (x *DataServerState) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[62]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DataServerState.ProtoReflect.Descriptor instead.
+func (*DataServerState) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{62}
+}
+
+func (x *DataServerState) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *DataServerState) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DataServerState) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *DataServerState) GetHealthy() bool {
+	if x != nil {
+		return x.Healthy
+	}
+	return false
+}
+
+func (x *DataServerState) GetStateTime() int64 {
+	if x != nil {
+		return x.StateTime
+	}
+	return 0
+}
+
+func (x *DataServerState) GetMaintenanceExpireTime() int64 {
+	if x != nil {
+		return x.MaintenanceExpireTime
+	}
+	return 0
+}
+
+func (x *DataServerState) GetRemainingBlocks() uint64 {
+	if x != nil {
+		return x.RemainingBlocks
+	}
+	return 0
+}
+
+type ListDataServerStatesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Servers       []*DataServerState     `protobuf:"bytes,1,rep,name=servers,proto3" json:"servers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDataServerStatesResponse) Reset() {
+	*x = ListDataServerStatesResponse{}
+	mi := &file_metaServer_proto_msgTypes[63]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDataServerStatesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDataServerStatesResponse) ProtoMessage() {}
+
+func (x *ListDataServerStatesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[63]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDataServerStatesResponse.ProtoReflect.Descriptor instead.
+func (*ListDataServerStatesResponse) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{63}
+}
+
+func (x *ListDataServerStatesResponse) GetServers() []*DataServerState {
+	if x != nil {
+		return x.Servers
+	}
+	return nil
+}
+
+type StartBalancerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Threshold     float64                `protobuf:"fixed64,1,opt,name=threshold,proto3" json:"threshold,omitempty"` // 使用率与平均值的最大偏差（0~1），0 表示使用 balancer.threshold
+	Bandwidth     uint64                 `protobuf:"varint,2,opt,name=bandwidth,proto3" json:"bandwidth,omitempty"`  // 每个迁移的带宽限制（字节/秒），0 表示使用 balancer.bandwidth
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartBalancerRequest) Reset() {
+	*x = StartBalancerRequest{}
+	mi := &file_metaServer_proto_msgTypes[64]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartBalancerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartBalancerRequest) ProtoMessage() {}
+
+func (x *StartBalancerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[64]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartBalancerRequest.ProtoReflect.Descriptor instead.
+func (*StartBalancerRequest) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{64}
+}
+
+func (x *StartBalancerRequest) GetThreshold() float64 {
+	if x != nil {
+		return x.Threshold
+	}
+	return 0
+}
+
+func (x *StartBalancerRequest) GetBandwidth() uint64 {
+	if x != nil {
+		return x.Bandwidth
+	}
+	return 0
+}
+
+type StopBalancerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StopBalancerRequest) Reset() {
+	*x = StopBalancerRequest{}
+	mi := &file_metaServer_proto_msgTypes[65]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopBalancerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopBalancerRequest) ProtoMessage() {}
+
+func (x *StopBalancerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[65]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopBalancerRequest.ProtoReflect.Descriptor instead.
+func (*StopBalancerRequest) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{65}
+}
+
+type GetBalancerStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBalancerStatusRequest) Reset() {
+	*x = GetBalancerStatusRequest{}
+	mi := &file_metaServer_proto_msgTypes[66]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBalancerStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBalancerStatusRequest) ProtoMessage() {}
+
+func (x *GetBalancerStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[66]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBalancerStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetBalancerStatusRequest) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{66}
+}
+
+type ServerUtilization struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Address       string                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Used          uint64                 `protobuf:"varint,2,opt,name=used,proto3" json:"used,omitempty"`                // 已用空间（字节），包含正在迁移的块
+	Capacity      uint64                 `protobuf:"varint,3,opt,name=capacity,proto3" json:"capacity,omitempty"`        // 总容量（字节）
+	Utilization   float64                `protobuf:"fixed64,4,opt,name=utilization,proto3" json:"utilization,omitempty"` // used / capacity
+	Class         string                 `protobuf:"bytes,5,opt,name=class,proto3" json:"class,omitempty"`               // over / above / below / under
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServerUtilization) Reset() {
+	*x = ServerUtilization{}
+	mi := &file_metaServer_proto_msgTypes[67]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerUtilization) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerUtilization) ProtoMessage() {}
+
+func (x *ServerUtilization) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[67]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerUtilization.ProtoReflect.Descriptor instead.
+func (*ServerUtilization) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{67}
+}
+
+func (x *ServerUtilization) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *ServerUtilization) GetUsed() uint64 {
+	if x != nil {
+		return x.Used
+	}
+	return 0
+}
+
+func (x *ServerUtilization) GetCapacity() uint64 {
+	if x != nil {
+		return x.Capacity
+	}
+	return 0
+}
+
+func (x *ServerUtilization) GetUtilization() float64 {
+	if x != nil {
+		return x.Utilization
+	}
+	return 0
+}
+
+func (x *ServerUtilization) GetClass() string {
+	if x != nil {
+		return x.Class
+	}
+	return ""
+}
+
+type GetBalancerStatusResponse struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Running            bool                   `protobuf:"varint,1,opt,name=running,proto3" json:"running,omitempty"`
+	Threshold          float64                `protobuf:"fixed64,2,opt,name=threshold,proto3" json:"threshold,omitempty"`
+	Bandwidth          uint64                 `protobuf:"varint,3,opt,name=bandwidth,proto3" json:"bandwidth,omitempty"`
+	AverageUtilization float64                `protobuf:"fixed64,4,opt,name=average_utilization,json=averageUtilization,proto3" json:"average_utilization,omitempty"`
+	Servers            []*ServerUtilization   `protobuf:"bytes,5,rep,name=servers,proto3" json:"servers,omitempty"`
+	PendingMoves       uint64                 `protobuf:"varint,6,opt,name=pending_moves,json=pendingMoves,proto3" json:"pending_moves,omitempty"`
+	MovedBlocks        uint64                 `protobuf:"varint,7,opt,name=moved_blocks,json=movedBlocks,proto3" json:"moved_blocks,omitempty"`
+	MovedBytes         uint64                 `protobuf:"varint,8,opt,name=moved_bytes,json=movedBytes,proto3" json:"moved_bytes,omitempty"`
+	FailedMoves        uint64                 `protobuf:"varint,9,opt,name=failed_moves,json=failedMoves,proto3" json:"failed_moves,omitempty"`
+	StartTime          int64                  `protobuf:"varint,10,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`                           // Unix时间戳(毫秒)
+	LastIterationTime  int64                  `protobuf:"varint,11,opt,name=last_iteration_time,json=lastIterationTime,proto3" json:"last_iteration_time,omitempty"` // Unix时间戳(毫秒)
+	Message            string                 `protobuf:"bytes,12,opt,name=message,proto3" json:"message,omitempty"`                                                 // 最近的状态说明，如 cluster is balanced
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *GetBalancerStatusResponse) Reset() {
+	*x = GetBalancerStatusResponse{}
+	mi := &file_metaServer_proto_msgTypes[68]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBalancerStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBalancerStatusResponse) ProtoMessage() {}
+
+func (x *GetBalancerStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[68]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBalancerStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetBalancerStatusResponse) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{68}
+}
+
+func (x *GetBalancerStatusResponse) GetRunning() bool {
+	if x != nil {
+		return x.Running
+	}
+	return false
+}
+
+func (x *GetBalancerStatusResponse) GetThreshold() float64 {
+	if x != nil {
+		return x.Threshold
+	}
+	return 0
+}
+
+func (x *GetBalancerStatusResponse) GetBandwidth() uint64 {
+	if x != nil {
+		return x.Bandwidth
+	}
+	return 0
+}
+
+func (x *GetBalancerStatusResponse) GetAverageUtilization() float64 {
+	if x != nil {
+		return x.AverageUtilization
+	}
+	return 0
+}
+
+func (x *GetBalancerStatusResponse) GetServers() []*ServerUtilization {
+	if x != nil {
+		return x.Servers
+	}
+	return nil
+}
+
+func (x *GetBalancerStatusResponse) GetPendingMoves() uint64 {
+	if x != nil {
+		return x.PendingMoves
+	}
+	return 0
+}
+
+func (x *GetBalancerStatusResponse) GetMovedBlocks() uint64 {
+	if x != nil {
+		return x.MovedBlocks
+	}
+	return 0
+}
+
+func (x *GetBalancerStatusResponse) GetMovedBytes() uint64 {
+	if x != nil {
+		return x.MovedBytes
+	}
+	return 0
+}
+
+func (x *GetBalancerStatusResponse) GetFailedMoves() uint64 {
+	if x != nil {
+		return x.FailedMoves
+	}
+	return 0
+}
+
+func (x *GetBalancerStatusResponse) GetStartTime() int64 {
+	if x != nil {
+		return x.StartTime
+	}
+	return 0
+}
+
+func (x *GetBalancerStatusResponse) GetLastIterationTime() int64 {
+	if x != nil {
+		return x.LastIterationTime
+	}
+	return 0
+}
+
+func (x *GetBalancerStatusResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type GetLeaderRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLeaderRequest) Reset() {
+	*x = GetLeaderRequest{}
+	mi := &file_metaServer_proto_msgTypes[69]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLeaderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLeaderRequest) ProtoMessage() {}
+
+func (x *GetLeaderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[69]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLeaderRequest.ProtoReflect.Descriptor instead.
+func (*GetLeaderRequest) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{69}
+}
+
+type GetLeaderResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Leader        *MetaServerMsg         `protobuf:"bytes,1,opt,name=leader,proto3" json:"leader,omitempty"`
+	Followers     []*MetaServerMsg       `protobuf:"bytes,2,rep,name=followers,proto3" json:"followers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLeaderResponse) Reset() {
+	*x = GetLeaderResponse{}
+	mi := &file_metaServer_proto_msgTypes[70]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLeaderResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLeaderResponse) ProtoMessage() {}
+
+func (x *GetLeaderResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[70]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLeaderResponse.ProtoReflect.Descriptor instead.
+func (*GetLeaderResponse) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{70}
+}
+
+func (x *GetLeaderResponse) GetLeader() *MetaServerMsg {
+	if x != nil {
+		return x.Leader
+	}
+	return nil
+}
+
+func (x *GetLeaderResponse) GetFollowers() []*MetaServerMsg {
+	if x != nil {
+		return x.Followers
+	}
+	return nil
+}
+
+// WAL日志条目 (用于主从同步)
+type LogEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	LogIndex      uint64                 `protobuf:"varint,1,opt,name=log_index,json=logIndex,proto3" json:"log_index,omitempty"`                     // 日志序号
+	Timestamp     int64                  `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`                                   // 时间戳
+	Operation     WALOperationType       `protobuf:"varint,3,opt,name=operation,proto3,enum=dfs_project.WALOperationType" json:"operation,omitempty"` // 操作类型
+	Data          []byte                 `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`                                              // 操作数据（JSON格式）
+	Checksum      string                 `protobuf:"bytes,5,opt,name=checksum,proto3" json:"checksum,omitempty"`                                      // 数据校验和
+	Term          uint64                 `protobuf:"varint,6,opt,name=term,proto3" json:"term,omitempty"`                                             // 写入该条目的leader任期
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogEntry) Reset() {
+	*x = LogEntry{}
+	mi := &file_metaServer_proto_msgTypes[71]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogEntry) ProtoMessage() {}
+
+func (x *LogEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[71]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogEntry.ProtoReflect.Descriptor instead.
+func (*LogEntry) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{71}
+}
+
+func (x *LogEntry) GetLogIndex() uint64 {
+	if x != nil {
+		return x.LogIndex
+	}
+	return 0
+}
+
+func (x *LogEntry) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *LogEntry) GetOperation() WALOperationType {
+	if x != nil {
+		return x.Operation
+	}
+	return WALOperationType_CREATE_NODE
+}
+
+func (x *LogEntry) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *LogEntry) GetChecksum() string {
+	if x != nil {
+		return x.Checksum
+	}
+	return ""
+}
+
+func (x *LogEntry) GetTerm() uint64 {
+	if x != nil {
+		return x.Term
+	}
+	return 0
+}
+
+// 创建节点操作的数据
+type CreateNodeOperation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Type          FileType               `protobuf:"varint,2,opt,name=type,proto3,enum=dfs_project.FileType" json:"type,omitempty"`
+	InodeId       uint64                 `protobuf:"varint,3,opt,name=inode_id,json=inodeId,proto3" json:"inode_id,omitempty"` // 实际分配的inode ID
+	Mtime         int64                  `protobuf:"varint,4,opt,name=mtime,proto3" json:"mtime,omitempty"`                    // Unix时间戳(毫秒)，由 leader 决定；为 0 时（旧版本日志）使用日志条目的时间戳
+	Replication   uint32                 `protobuf:"varint,5,opt,name=replication,proto3" json:"replication,omitempty"`        // 文件副本数，0 表示使用默认副本数
+	Owner         string                 `protobuf:"bytes,6,opt,name=owner,proto3" json:"owner,omitempty"`                     // 为空时不记录属主
+	Group         string                 `protobuf:"bytes,7,opt,name=group,proto3" json:"group,omitempty"`
+	Mode          uint32                 `protobuf:"varint,8,opt,name=mode,proto3" json:"mode,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateNodeOperation) Reset() {
+	*x = CreateNodeOperation{}
+	mi := &file_metaServer_proto_msgTypes[72]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateNodeOperation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateNodeOperation) ProtoMessage() {}
+
+func (x *CreateNodeOperation) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[72]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateNodeOperation.ProtoReflect.Descriptor instead.
+func (*CreateNodeOperation) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{72}
+}
+
+func (x *CreateNodeOperation) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *CreateNodeOperation) GetType() FileType {
+	if x != nil {
+		return x.Type
+	}
+	return FileType_Unknown
+}
+
+func (x *CreateNodeOperation) GetInodeId() uint64 {
+	if x != nil {
+		return x.InodeId
+	}
+	return 0
+}
+
+func (x *CreateNodeOperation) GetMtime() int64 {
+	if x != nil {
+		return x.Mtime
+	}
+	return 0
+}
+
+func (x *CreateNodeOperation) GetReplication() uint32 {
+	if x != nil {
+		return x.Replication
+	}
+	return 0
+}
+
+func (x *CreateNodeOperation) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *CreateNodeOperation) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *CreateNodeOperation) GetMode() uint32 {
+	if x != nil {
+		return x.Mode
+	}
+	return 0
+}
+
+// 删除节点操作的数据
+type DeleteNodeOperation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Recursive     bool                   `protobuf:"varint,2,opt,name=recursive,proto3" json:"recursive,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteNodeOperation) Reset() {
+	*x = DeleteNodeOperation{}
+	mi := &file_metaServer_proto_msgTypes[73]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteNodeOperation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteNodeOperation) ProtoMessage() {}
+
+func (x *DeleteNodeOperation) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[73]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteNodeOperation.ProtoReflect.Descriptor instead.
+func (*DeleteNodeOperation) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{73}
+}
+
+func (x *DeleteNodeOperation) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *DeleteNodeOperation) GetRecursive() bool {
+	if x != nil {
+		return x.Recursive
+	}
+	return false
+}
+
+// 重命名节点操作的数据
+type RenameNodeOperation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SrcPath       string                 `protobuf:"bytes,1,opt,name=src_path,json=srcPath,proto3" json:"src_path,omitempty"`
+	DstPath       string                 `protobuf:"bytes,2,opt,name=dst_path,json=dstPath,proto3" json:"dst_path,omitempty"`
+	Overwrite     bool                   `protobuf:"varint,3,opt,name=overwrite,proto3" json:"overwrite,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenameNodeOperation) Reset() {
+	*x = RenameNodeOperation{}
+	mi := &file_metaServer_proto_msgTypes[74]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenameNodeOperation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenameNodeOperation) ProtoMessage() {}
+
+func (x *RenameNodeOperation) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[74]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenameNodeOperation.ProtoReflect.Descriptor instead.
+func (*RenameNodeOperation) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{74}
+}
+
+func (x *RenameNodeOperation) GetSrcPath() string {
+	if x != nil {
+		return x.SrcPath
+	}
+	return ""
+}
+
+func (x *RenameNodeOperation) GetDstPath() string {
+	if x != nil {
+		return x.DstPath
+	}
+	return ""
+}
+
+func (x *RenameNodeOperation) GetOverwrite() bool {
+	if x != nil {
+		return x.Overwrite
+	}
+	return false
+}
+
+// 更新节点操作的数据
+type UpdateNodeOperation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Size          int64                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	Mtime         int64                  `protobuf:"varint,3,opt,name=mtime,proto3" json:"mtime,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateNodeOperation) Reset() {
+	*x = UpdateNodeOperation{}
+	mi := &file_metaServer_proto_msgTypes[75]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateNodeOperation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateNodeOperation) ProtoMessage() {}
+
+func (x *UpdateNodeOperation) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[75]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateNodeOperation.ProtoReflect.Descriptor instead.
+func (*UpdateNodeOperation) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{75}
+}
+
+func (x *UpdateNodeOperation) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *UpdateNodeOperation) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *UpdateNodeOperation) GetMtime() int64 {
+	if x != nil {
+		return x.Mtime
+	}
+	return 0
+}
+
+// 完成写入操作的数据
+type FinalizeWriteOperation struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Path           string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	BlockLocations []*BlockLocations      `protobuf:"bytes,2,rep,name=block_locations,json=blockLocations,proto3" json:"block_locations,omitempty"`
+	Inode          uint64                 `protobuf:"varint,3,opt,name=inode,proto3" json:"inode,omitempty"`
+	Size           int64                  `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
+	Md5            string                 `protobuf:"bytes,5,opt,name=md5,proto3" json:"md5,omitempty"`
+	LeaseHolder    string                 `protobuf:"bytes,6,opt,name=lease_holder,json=leaseHolder,proto3" json:"lease_holder,omitempty"` // 非空时文件必须持有该写入方的写租约，租约与文件状态在同一事务中释放
+	Mtime          int64                  `protobuf:"varint,7,opt,name=mtime,proto3" json:"mtime,omitempty"`                               // Unix时间戳(毫秒)，由 leader 决定；为 0 时（旧版本日志）使用日志条目的时间戳
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *FinalizeWriteOperation) Reset() {
+	*x = FinalizeWriteOperation{}
+	mi := &file_metaServer_proto_msgTypes[76]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FinalizeWriteOperation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FinalizeWriteOperation) ProtoMessage() {}
+
+func (x *FinalizeWriteOperation) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[76]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
 
-func (x *GetBlockLocationsResponse) GetBlockLocations() []*BlockLocations {
+// Deprecated: Use FinalizeWriteOperation.ProtoReflect.Descriptor instead.
+func (*FinalizeWriteOperation) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{76}
+}
+
+func (x *FinalizeWriteOperation) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *FinalizeWriteOperation) GetBlockLocations() []*BlockLocations {
 	if x != nil {
 		return x.BlockLocations
 	}
 	return nil
 }
 
-// FinalizeWrite
-type FinalizeWriteRequest struct {
+func (x *FinalizeWriteOperation) GetInode() uint64 {
+	if x != nil {
+		return x.Inode
+	}
+	return 0
+}
+
+func (x *FinalizeWriteOperation) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *FinalizeWriteOperation) GetMd5() string {
+	if x != nil {
+		return x.Md5
+	}
+	return ""
+}
+
+func (x *FinalizeWriteOperation) GetLeaseHolder() string {
+	if x != nil {
+		return x.LeaseHolder
+	}
+	return ""
+}
+
+func (x *FinalizeWriteOperation) GetMtime() int64 {
+	if x != nil {
+		return x.Mtime
+	}
+	return 0
+}
+
+// 更新块位置信息的数据
+type UpdateBlockLocationOperation struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
-	Inode         uint64                 `protobuf:"varint,2,opt,name=inode,proto3" json:"inode,omitempty"`
-	Size          int64                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
-	Md5           string                 `protobuf:"bytes,4,opt,name=md5,proto3" json:"md5,omitempty"`
+	BlockId       uint64                 `protobuf:"varint,1,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"` // 块ID
+	OldAddr       string                 `protobuf:"bytes,2,opt,name=old_addr,json=oldAddr,proto3" json:"old_addr,omitempty"`  // 原地址，为空时添加 new_addr
+	NewAddr       string                 `protobuf:"bytes,3,opt,name=new_addr,json=newAddr,proto3" json:"new_addr,omitempty"`  // 新地址，为空时删除 old_addr
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *FinalizeWriteRequest) Reset() {
-	*x = FinalizeWriteRequest{}
-	mi := &file_metaServer_proto_msgTypes[16]
+func (x *UpdateBlockLocationOperation) Reset() {
+	*x = UpdateBlockLocationOperation{}
+	mi := &file_metaServer_proto_msgTypes[77]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *FinalizeWriteRequest) String() string {
+func (x *UpdateBlockLocationOperation) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*FinalizeWriteRequest) ProtoMessage() {}
+func (*UpdateBlockLocationOperation) ProtoMessage() {}
 
-func (x *FinalizeWriteRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_metaServer_proto_msgTypes[16]
+func (x *UpdateBlockLocationOperation) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[77]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1145,61 +5244,117 @@ func (x *FinalizeWriteRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use FinalizeWriteRequest.ProtoReflect.Descriptor instead.
-func (*FinalizeWriteRequest) Descriptor() ([]byte, []int) {
-	return file_metaServer_proto_rawDescGZIP(), []int{16}
+// Deprecated: Use UpdateBlockLocationOperation.ProtoReflect.Descriptor instead.
+func (*UpdateBlockLocationOperation) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{77}
 }
 
-func (x *FinalizeWriteRequest) GetPath() string {
+func (x *UpdateBlockLocationOperation) GetBlockId() uint64 {
 	if x != nil {
-		return x.Path
+		return x.BlockId
+	}
+	return 0
+}
+
+func (x *UpdateBlockLocationOperation) GetOldAddr() string {
+	if x != nil {
+		return x.OldAddr
 	}
 	return ""
 }
 
-func (x *FinalizeWriteRequest) GetInode() uint64 {
+func (x *UpdateBlockLocationOperation) GetNewAddr() string {
 	if x != nil {
-		return x.Inode
+		return x.NewAddr
+	}
+	return ""
+}
+
+// 设置块映射关系的数据
+type SetBlockMappingOperation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	InodeId       uint64                 `protobuf:"varint,1,opt,name=inode_id,json=inodeId,proto3" json:"inode_id,omitempty"`          // 文件inode ID
+	BlockIndex    uint64                 `protobuf:"varint,2,opt,name=block_index,json=blockIndex,proto3" json:"block_index,omitempty"` // 块索引
+	BlockLocs     *BlockLocations        `protobuf:"bytes,3,opt,name=block_locs,json=blockLocs,proto3" json:"block_locs,omitempty"`     // 块位置信息
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetBlockMappingOperation) Reset() {
+	*x = SetBlockMappingOperation{}
+	mi := &file_metaServer_proto_msgTypes[78]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetBlockMappingOperation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetBlockMappingOperation) ProtoMessage() {}
+
+func (x *SetBlockMappingOperation) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[78]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetBlockMappingOperation.ProtoReflect.Descriptor instead.
+func (*SetBlockMappingOperation) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{78}
+}
+
+func (x *SetBlockMappingOperation) GetInodeId() uint64 {
+	if x != nil {
+		return x.InodeId
 	}
 	return 0
 }
 
-func (x *FinalizeWriteRequest) GetSize() int64 {
+func (x *SetBlockMappingOperation) GetBlockIndex() uint64 {
 	if x != nil {
-		return x.Size
+		return x.BlockIndex
 	}
 	return 0
 }
 
-func (x *FinalizeWriteRequest) GetMd5() string {
+func (x *SetBlockMappingOperation) GetBlockLocs() *BlockLocations {
 	if x != nil {
-		return x.Md5
+		return x.BlockLocs
 	}
-	return ""
+	return nil
 }
 
-// GetClusterInfo - 直接返回 easyClient 需要的 ClusterInfo
-type GetClusterInfoRequest struct {
+// 截断块映射的数据
+type TruncateBlockMappingsOperation struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	InodeId       uint64                 `protobuf:"varint,1,opt,name=inode_id,json=inodeId,proto3" json:"inode_id,omitempty"`          // 文件inode ID
+	BlockCount    uint64                 `protobuf:"varint,2,opt,name=block_count,json=blockCount,proto3" json:"block_count,omitempty"` // 保留的块数量
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetClusterInfoRequest) Reset() {
-	*x = GetClusterInfoRequest{}
-	mi := &file_metaServer_proto_msgTypes[17]
+func (x *TruncateBlockMappingsOperation) Reset() {
+	*x = TruncateBlockMappingsOperation{}
+	mi := &file_metaServer_proto_msgTypes[79]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetClusterInfoRequest) String() string {
+func (x *TruncateBlockMappingsOperation) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetClusterInfoRequest) ProtoMessage() {}
+func (*TruncateBlockMappingsOperation) ProtoMessage() {}
 
-func (x *GetClusterInfoRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_metaServer_proto_msgTypes[17]
+func (x *TruncateBlockMappingsOperation) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[79]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1210,33 +5365,55 @@ func (x *GetClusterInfoRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetClusterInfoRequest.ProtoReflect.Descriptor instead.
-func (*GetClusterInfoRequest) Descriptor() ([]byte, []int) {
-	return file_metaServer_proto_rawDescGZIP(), []int{17}
+// Deprecated: Use TruncateBlockMappingsOperation.ProtoReflect.Descriptor instead.
+func (*TruncateBlockMappingsOperation) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{79}
 }
 
-type GetClusterInfoResponse struct {
+func (x *TruncateBlockMappingsOperation) GetInodeId() uint64 {
+	if x != nil {
+		return x.InodeId
+	}
+	return 0
+}
+
+func (x *TruncateBlockMappingsOperation) GetBlockCount() uint64 {
+	if x != nil {
+		return x.BlockCount
+	}
+	return 0
+}
+
+// 授予写租约操作的数据，同时作为租约记录保存在 lease/<path>
+type GrantLeaseOperation struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ClusterInfo   *ClusterInfo           `protobuf:"bytes,1,opt,name=clusterInfo,proto3" json:"clusterInfo,omitempty"` // 直接返回easyClient需要的格式
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Holder        string                 `protobuf:"bytes,2,opt,name=holder,proto3" json:"holder,omitempty"` // 写入方标识
+	Inode         uint64                 `protobuf:"varint,3,opt,name=inode,proto3" json:"inode,omitempty"`
+	AcquireTime   int64                  `protobuf:"varint,4,opt,name=acquire_time,json=acquireTime,proto3" json:"acquire_time,omitempty"` // Unix时间戳(毫秒)，由 leader 决定
+	TargetSize    int64                  `protobuf:"varint,5,opt,name=target_size,json=targetSize,proto3" json:"target_size,omitempty"`    // 写入完成后预期的文件大小
+	PrevSize      int64                  `protobuf:"varint,6,opt,name=prev_size,json=prevSize,proto3" json:"prev_size,omitempty"`          // 写入前的文件大小
+	PrevMd5       string                 `protobuf:"bytes,7,opt,name=prev_md5,json=prevMd5,proto3" json:"prev_md5,omitempty"`              // 写入前的文件MD5
+	PrevBlocks    []*BlockLocations      `protobuf:"bytes,8,rep,name=prev_blocks,json=prevBlocks,proto3" json:"prev_blocks,omitempty"`     // 写入前的块映射
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetClusterInfoResponse) Reset() {
-	*x = GetClusterInfoResponse{}
-	mi := &file_metaServer_proto_msgTypes[18]
+func (x *GrantLeaseOperation) Reset() {
+	*x = GrantLeaseOperation{}
+	mi := &file_metaServer_proto_msgTypes[80]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetClusterInfoResponse) String() string {
+func (x *GrantLeaseOperation) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetClusterInfoResponse) ProtoMessage() {}
+func (*GrantLeaseOperation) ProtoMessage() {}
 
-func (x *GetClusterInfoResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_metaServer_proto_msgTypes[18]
+func (x *GrantLeaseOperation) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[80]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1247,46 +5424,91 @@ func (x *GetClusterInfoResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetClusterInfoResponse.ProtoReflect.Descriptor instead.
-func (*GetClusterInfoResponse) Descriptor() ([]byte, []int) {
-	return file_metaServer_proto_rawDescGZIP(), []int{18}
+// Deprecated: Use GrantLeaseOperation.ProtoReflect.Descriptor instead.
+func (*GrantLeaseOperation) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{80}
 }
 
-func (x *GetClusterInfoResponse) GetClusterInfo() *ClusterInfo {
+func (x *GrantLeaseOperation) GetPath() string {
 	if x != nil {
-		return x.ClusterInfo
+		return x.Path
+	}
+	return ""
+}
+
+func (x *GrantLeaseOperation) GetHolder() string {
+	if x != nil {
+		return x.Holder
+	}
+	return ""
+}
+
+func (x *GrantLeaseOperation) GetInode() uint64 {
+	if x != nil {
+		return x.Inode
+	}
+	return 0
+}
+
+func (x *GrantLeaseOperation) GetAcquireTime() int64 {
+	if x != nil {
+		return x.AcquireTime
+	}
+	return 0
+}
+
+func (x *GrantLeaseOperation) GetTargetSize() int64 {
+	if x != nil {
+		return x.TargetSize
+	}
+	return 0
+}
+
+func (x *GrantLeaseOperation) GetPrevSize() int64 {
+	if x != nil {
+		return x.PrevSize
+	}
+	return 0
+}
+
+func (x *GrantLeaseOperation) GetPrevMd5() string {
+	if x != nil {
+		return x.PrevMd5
+	}
+	return ""
+}
+
+func (x *GrantLeaseOperation) GetPrevBlocks() []*BlockLocations {
+	if x != nil {
+		return x.PrevBlocks
 	}
 	return nil
 }
 
-// Heartbeat (内部接口，保持不变以兼容DataServer)
-type HeartbeatRequest struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	DataserverId   string                 `protobuf:"bytes,1,opt,name=dataServer_id,json=dataServerId,proto3" json:"dataServer_id,omitempty"`
-	DataserverAddr string                 `protobuf:"bytes,2,opt,name=dataServer_addr,json=dataServerAddr,proto3" json:"dataServer_addr,omitempty"`
-	BlockCount     uint64                 `protobuf:"varint,3,opt,name=block_count,json=blockCount,proto3" json:"block_count,omitempty"`
-	FreeSpace      uint64                 `protobuf:"varint,4,opt,name=free_space,json=freeSpace,proto3" json:"free_space,omitempty"`
-	BlockIdsReport []uint64               `protobuf:"varint,5,rep,packed,name=block_ids_report,json=blockIdsReport,proto3" json:"block_ids_report,omitempty"`
-	TotalCapacity  uint64                 `protobuf:"varint,6,opt,name=total_capacity,json=totalCapacity,proto3" json:"total_capacity,omitempty"` // 总容量（字节）
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+// 释放写租约操作的数据，租约已属于其他写入方时不做修改
+type ReleaseLeaseOperation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Holder        string                 `protobuf:"bytes,2,opt,name=holder,proto3" json:"holder,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HeartbeatRequest) Reset() {
-	*x = HeartbeatRequest{}
-	mi := &file_metaServer_proto_msgTypes[19]
+func (x *ReleaseLeaseOperation) Reset() {
+	*x = ReleaseLeaseOperation{}
+	mi := &file_metaServer_proto_msgTypes[81]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HeartbeatRequest) String() string {
+func (x *ReleaseLeaseOperation) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HeartbeatRequest) ProtoMessage() {}
+func (*ReleaseLeaseOperation) ProtoMessage() {}
 
-func (x *HeartbeatRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_metaServer_proto_msgTypes[19]
+func (x *ReleaseLeaseOperation) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[81]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1297,77 +5519,110 @@ func (x *HeartbeatRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HeartbeatRequest.ProtoReflect.Descriptor instead.
-func (*HeartbeatRequest) Descriptor() ([]byte, []int) {
-	return file_metaServer_proto_rawDescGZIP(), []int{19}
+// Deprecated: Use ReleaseLeaseOperation.ProtoReflect.Descriptor instead.
+func (*ReleaseLeaseOperation) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{81}
 }
 
-func (x *HeartbeatRequest) GetDataserverId() string {
+func (x *ReleaseLeaseOperation) GetPath() string {
 	if x != nil {
-		return x.DataserverId
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ReleaseLeaseOperation) GetHolder() string {
+	if x != nil {
+		return x.Holder
 	}
 	return ""
 }
 
-func (x *HeartbeatRequest) GetDataserverAddr() string {
+// 设置目录配额的数据
+type SetQuotaOperation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	MaxBytes      uint64                 `protobuf:"varint,2,opt,name=max_bytes,json=maxBytes,proto3" json:"max_bytes,omitempty"`
+	MaxInodes     uint64                 `protobuf:"varint,3,opt,name=max_inodes,json=maxInodes,proto3" json:"max_inodes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetQuotaOperation) Reset() {
+	*x = SetQuotaOperation{}
+	mi := &file_metaServer_proto_msgTypes[82]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetQuotaOperation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetQuotaOperation) ProtoMessage() {}
+
+func (x *SetQuotaOperation) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[82]
 	if x != nil {
-		return x.DataserverAddr
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *HeartbeatRequest) GetBlockCount() uint64 {
-	if x != nil {
-		return x.BlockCount
-	}
-	return 0
+// Deprecated: Use SetQuotaOperation.ProtoReflect.Descriptor instead.
+func (*SetQuotaOperation) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{82}
 }
 
-func (x *HeartbeatRequest) GetFreeSpace() uint64 {
+func (x *SetQuotaOperation) GetPath() string {
 	if x != nil {
-		return x.FreeSpace
+		return x.Path
 	}
-	return 0
+	return ""
 }
 
-func (x *HeartbeatRequest) GetBlockIdsReport() []uint64 {
+func (x *SetQuotaOperation) GetMaxBytes() uint64 {
 	if x != nil {
-		return x.BlockIdsReport
+		return x.MaxBytes
 	}
-	return nil
+	return 0
 }
 
-func (x *HeartbeatRequest) GetTotalCapacity() uint64 {
+func (x *SetQuotaOperation) GetMaxInodes() uint64 {
 	if x != nil {
-		return x.TotalCapacity
+		return x.MaxInodes
 	}
 	return 0
 }
 
-type Command struct {
+// 修改文件副本数操作的数据
+type SetReplicationOperation struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Action        Command_Action         `protobuf:"varint,1,opt,name=action,proto3,enum=dfs_project.Command_Action" json:"action,omitempty"`
-	BlockId       uint64                 `protobuf:"varint,2,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
-	Targets       []string               `protobuf:"bytes,3,rep,name=targets,proto3" json:"targets,omitempty"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Replication   uint32                 `protobuf:"varint,2,opt,name=replication,proto3" json:"replication,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Command) Reset() {
-	*x = Command{}
-	mi := &file_metaServer_proto_msgTypes[20]
+func (x *SetReplicationOperation) Reset() {
+	*x = SetReplicationOperation{}
+	mi := &file_metaServer_proto_msgTypes[83]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Command) String() string {
+func (x *SetReplicationOperation) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Command) ProtoMessage() {}
+func (*SetReplicationOperation) ProtoMessage() {}
 
-func (x *Command) ProtoReflect() protoreflect.Message {
-	mi := &file_metaServer_proto_msgTypes[20]
+func (x *SetReplicationOperation) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[83]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1378,54 +5633,49 @@ func (x *Command) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Command.ProtoReflect.Descriptor instead.
-func (*Command) Descriptor() ([]byte, []int) {
-	return file_metaServer_proto_rawDescGZIP(), []int{20}
+// Deprecated: Use SetReplicationOperation.ProtoReflect.Descriptor instead.
+func (*SetReplicationOperation) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{83}
 }
 
-func (x *Command) GetAction() Command_Action {
+func (x *SetReplicationOperation) GetPath() string {
 	if x != nil {
-		return x.Action
+		return x.Path
 	}
-	return Command_DELETE_BLOCK
+	return ""
 }
 
-func (x *Command) GetBlockId() uint64 {
+func (x *SetReplicationOperation) GetReplication() uint32 {
 	if x != nil {
-		return x.BlockId
+		return x.Replication
 	}
 	return 0
 }
 
-func (x *Command) GetTargets() []string {
-	if x != nil {
-		return x.Targets
-	}
-	return nil
-}
-
-type HeartbeatResponse struct {
+// 设置目录纠删码策略操作的数据
+type SetErasureCodingPolicyOperation struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Commands      []*Command             `protobuf:"bytes,1,rep,name=commands,proto3" json:"commands,omitempty"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Policy        string                 `protobuf:"bytes,2,opt,name=policy,proto3" json:"policy,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HeartbeatResponse) Reset() {
-	*x = HeartbeatResponse{}
-	mi := &file_metaServer_proto_msgTypes[21]
+func (x *SetErasureCodingPolicyOperation) Reset() {
+	*x = SetErasureCodingPolicyOperation{}
+	mi := &file_metaServer_proto_msgTypes[84]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HeartbeatResponse) String() string {
+func (x *SetErasureCodingPolicyOperation) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HeartbeatResponse) ProtoMessage() {}
+func (*SetErasureCodingPolicyOperation) ProtoMessage() {}
 
-func (x *HeartbeatResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_metaServer_proto_msgTypes[21]
+func (x *SetErasureCodingPolicyOperation) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[84]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1436,41 +5686,52 @@ func (x *HeartbeatResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HeartbeatResponse.ProtoReflect.Descriptor instead.
-func (*HeartbeatResponse) Descriptor() ([]byte, []int) {
-	return file_metaServer_proto_rawDescGZIP(), []int{21}
+// Deprecated: Use SetErasureCodingPolicyOperation.ProtoReflect.Descriptor instead.
+func (*SetErasureCodingPolicyOperation) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{84}
 }
 
-func (x *HeartbeatResponse) GetCommands() []*Command {
+func (x *SetErasureCodingPolicyOperation) GetPath() string {
 	if x != nil {
-		return x.Commands
+		return x.Path
 	}
-	return nil
+	return ""
 }
 
-// GetReplicationInfo
-type GetReplicationInfoRequest struct {
+func (x *SetErasureCodingPolicyOperation) GetPolicy() string {
+	if x != nil {
+		return x.Policy
+	}
+	return ""
+}
+
+// 将文件的一个多副本块替换为纠删码块组的数据，块映射已变化时不生效
+type ConvertBlockOperation struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	InodeId       uint64                 `protobuf:"varint,1,opt,name=inode_id,json=inodeId,proto3" json:"inode_id,omitempty"`
+	BlockIndex    uint64                 `protobuf:"varint,2,opt,name=block_index,json=blockIndex,proto3" json:"block_index,omitempty"`
+	OldBlockId    uint64                 `protobuf:"varint,3,opt,name=old_block_id,json=oldBlockId,proto3" json:"old_block_id,omitempty"`
+	Group         *BlockLocations        `protobuf:"bytes,4,opt,name=group,proto3" json:"group,omitempty"`
+	FileSize      int64                  `protobuf:"varint,5,opt,name=file_size,json=fileSize,proto3" json:"file_size,omitempty"` // 开始转换时的文件大小，文件已被追加或截断时不生效
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetReplicationInfoRequest) Reset() {
-	*x = GetReplicationInfoRequest{}
-	mi := &file_metaServer_proto_msgTypes[22]
+func (x *ConvertBlockOperation) Reset() {
+	*x = ConvertBlockOperation{}
+	mi := &file_metaServer_proto_msgTypes[85]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetReplicationInfoRequest) String() string {
+func (x *ConvertBlockOperation) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetReplicationInfoRequest) ProtoMessage() {}
+func (*ConvertBlockOperation) ProtoMessage() {}
 
-func (x *GetReplicationInfoRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_metaServer_proto_msgTypes[22]
+func (x *ConvertBlockOperation) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[85]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1481,43 +5742,72 @@ func (x *GetReplicationInfoRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetReplicationInfoRequest.ProtoReflect.Descriptor instead.
-func (*GetReplicationInfoRequest) Descriptor() ([]byte, []int) {
-	return file_metaServer_proto_rawDescGZIP(), []int{22}
+// Deprecated: Use ConvertBlockOperation.ProtoReflect.Descriptor instead.
+func (*ConvertBlockOperation) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{85}
 }
 
-func (x *GetReplicationInfoRequest) GetPath() string {
+func (x *ConvertBlockOperation) GetInodeId() uint64 {
 	if x != nil {
-		return x.Path
+		return x.InodeId
 	}
-	return ""
+	return 0
 }
 
-type BlockReplicationInfo struct {
-	state             protoimpl.MessageState `protogen:"open.v1"`
-	BlockId           uint64                 `protobuf:"varint,1,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
-	Locations         []string               `protobuf:"bytes,2,rep,name=locations,proto3" json:"locations,omitempty"`
-	ExpectedLocations []string               `protobuf:"bytes,3,rep,name=expected_locations,json=expectedLocations,proto3" json:"expected_locations,omitempty"`
-	ReplicaCount      uint32                 `protobuf:"varint,4,opt,name=replica_count,json=replicaCount,proto3" json:"replica_count,omitempty"`
-	unknownFields     protoimpl.UnknownFields
-	sizeCache         protoimpl.SizeCache
+func (x *ConvertBlockOperation) GetBlockIndex() uint64 {
+	if x != nil {
+		return x.BlockIndex
+	}
+	return 0
 }
 
-func (x *BlockReplicationInfo) Reset() {
-	*x = BlockReplicationInfo{}
-	mi := &file_metaServer_proto_msgTypes[23]
+func (x *ConvertBlockOperation) GetOldBlockId() uint64 {
+	if x != nil {
+		return x.OldBlockId
+	}
+	return 0
+}
+
+func (x *ConvertBlockOperation) GetGroup() *BlockLocations {
+	if x != nil {
+		return x.Group
+	}
+	return nil
+}
+
+func (x *ConvertBlockOperation) GetFileSize() int64 {
+	if x != nil {
+		return x.FileSize
+	}
+	return 0
+}
+
+// 设置 DataServer 管理状态操作的数据，时间由 leader 决定
+type SetDataServerStateOperation struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	Address               string                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	State                 string                 `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
+	StateTime             int64                  `protobuf:"varint,3,opt,name=state_time,json=stateTime,proto3" json:"state_time,omitempty"`                                       // Unix时间戳(毫秒)
+	MaintenanceExpireTime int64                  `protobuf:"varint,4,opt,name=maintenance_expire_time,json=maintenanceExpireTime,proto3" json:"maintenance_expire_time,omitempty"` // Unix时间戳(毫秒)
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *SetDataServerStateOperation) Reset() {
+	*x = SetDataServerStateOperation{}
+	mi := &file_metaServer_proto_msgTypes[86]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *BlockReplicationInfo) String() string {
+func (x *SetDataServerStateOperation) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*BlockReplicationInfo) ProtoMessage() {}
+func (*SetDataServerStateOperation) ProtoMessage() {}
 
-func (x *BlockReplicationInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_metaServer_proto_msgTypes[23]
+func (x *SetDataServerStateOperation) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[86]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1528,67 +5818,68 @@ func (x *BlockReplicationInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use BlockReplicationInfo.ProtoReflect.Descriptor instead.
-func (*BlockReplicationInfo) Descriptor() ([]byte, []int) {
-	return file_metaServer_proto_rawDescGZIP(), []int{23}
+// Deprecated: Use SetDataServerStateOperation.ProtoReflect.Descriptor instead.
+func (*SetDataServerStateOperation) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{86}
 }
 
-func (x *BlockReplicationInfo) GetBlockId() uint64 {
+func (x *SetDataServerStateOperation) GetAddress() string {
 	if x != nil {
-		return x.BlockId
+		return x.Address
 	}
-	return 0
+	return ""
 }
 
-func (x *BlockReplicationInfo) GetLocations() []string {
+func (x *SetDataServerStateOperation) GetState() string {
 	if x != nil {
-		return x.Locations
+		return x.State
 	}
-	return nil
+	return ""
 }
 
-func (x *BlockReplicationInfo) GetExpectedLocations() []string {
+func (x *SetDataServerStateOperation) GetStateTime() int64 {
 	if x != nil {
-		return x.ExpectedLocations
+		return x.StateTime
 	}
-	return nil
+	return 0
 }
 
-func (x *BlockReplicationInfo) GetReplicaCount() uint32 {
+func (x *SetDataServerStateOperation) GetMaintenanceExpireTime() int64 {
 	if x != nil {
-		return x.ReplicaCount
+		return x.MaintenanceExpireTime
 	}
 	return 0
 }
 
-type ReplicationStatus struct {
-	state            protoimpl.MessageState  `protogen:"open.v1"`
-	Path             string                  `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
-	Inode            uint64                  `protobuf:"varint,2,opt,name=inode,proto3" json:"inode,omitempty"`
-	Size             int64                   `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"` // 改为 int64
-	ExpectedReplicas uint32                  `protobuf:"varint,4,opt,name=expected_replicas,json=expectedReplicas,proto3" json:"expected_replicas,omitempty"`
-	ActualReplicas   uint32                  `protobuf:"varint,5,opt,name=actual_replicas,json=actualReplicas,proto3" json:"actual_replicas,omitempty"`
-	Blocks           []*BlockReplicationInfo `protobuf:"bytes,6,rep,name=blocks,proto3" json:"blocks,omitempty"`
-	Status           string                  `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"`
-	unknownFields    protoimpl.UnknownFields
-	sizeCache        protoimpl.SizeCache
+// 修改节点权限操作的数据，只修改非空或设置了 set_ 标志的字段
+type SetPermissionOperation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Owner         string                 `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`
+	Group         string                 `protobuf:"bytes,3,opt,name=group,proto3" json:"group,omitempty"`
+	Mode          uint32                 `protobuf:"varint,4,opt,name=mode,proto3" json:"mode,omitempty"`
+	SetMode       bool                   `protobuf:"varint,5,opt,name=set_mode,json=setMode,proto3" json:"set_mode,omitempty"`
+	Acl           []*AclEntry            `protobuf:"bytes,6,rep,name=acl,proto3" json:"acl,omitempty"`
+	SetAcl        bool                   `protobuf:"varint,7,opt,name=set_acl,json=setAcl,proto3" json:"set_acl,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ReplicationStatus) Reset() {
-	*x = ReplicationStatus{}
-	mi := &file_metaServer_proto_msgTypes[24]
+func (x *SetPermissionOperation) Reset() {
+	*x = SetPermissionOperation{}
+	mi := &file_metaServer_proto_msgTypes[87]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ReplicationStatus) String() string {
+func (x *SetPermissionOperation) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ReplicationStatus) ProtoMessage() {}
+func (*SetPermissionOperation) ProtoMessage() {}
 
-func (x *ReplicationStatus) ProtoReflect() protoreflect.Message {
-	mi := &file_metaServer_proto_msgTypes[24]
+func (x *SetPermissionOperation) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[87]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1599,86 +5890,85 @@ func (x *ReplicationStatus) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ReplicationStatus.ProtoReflect.Descriptor instead.
-func (*ReplicationStatus) Descriptor() ([]byte, []int) {
-	return file_metaServer_proto_rawDescGZIP(), []int{24}
+// Deprecated: Use SetPermissionOperation.ProtoReflect.Descriptor instead.
+func (*SetPermissionOperation) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{87}
 }
 
-func (x *ReplicationStatus) GetPath() string {
+func (x *SetPermissionOperation) GetPath() string {
 	if x != nil {
 		return x.Path
 	}
 	return ""
 }
 
-func (x *ReplicationStatus) GetInode() uint64 {
+func (x *SetPermissionOperation) GetOwner() string {
 	if x != nil {
-		return x.Inode
+		return x.Owner
 	}
-	return 0
+	return ""
 }
 
-func (x *ReplicationStatus) GetSize() int64 {
+func (x *SetPermissionOperation) GetGroup() string {
 	if x != nil {
-		return x.Size
+		return x.Group
 	}
-	return 0
+	return ""
 }
 
-func (x *ReplicationStatus) GetExpectedReplicas() uint32 {
+func (x *SetPermissionOperation) GetMode() uint32 {
 	if x != nil {
-		return x.ExpectedReplicas
+		return x.Mode
 	}
 	return 0
 }
 
-func (x *ReplicationStatus) GetActualReplicas() uint32 {
+func (x *SetPermissionOperation) GetSetMode() bool {
 	if x != nil {
-		return x.ActualReplicas
+		return x.SetMode
 	}
-	return 0
+	return false
 }
 
-func (x *ReplicationStatus) GetBlocks() []*BlockReplicationInfo {
+func (x *SetPermissionOperation) GetAcl() []*AclEntry {
 	if x != nil {
-		return x.Blocks
+		return x.Acl
 	}
 	return nil
 }
 
-func (x *ReplicationStatus) GetStatus() string {
+func (x *SetPermissionOperation) GetSetAcl() bool {
 	if x != nil {
-		return x.Status
+		return x.SetAcl
 	}
-	return ""
+	return false
 }
 
-type GetReplicationInfoResponse struct {
-	state                protoimpl.MessageState `protogen:"open.v1"`
-	Files                []*ReplicationStatus   `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
-	TotalFiles           uint32                 `protobuf:"varint,2,opt,name=total_files,json=totalFiles,proto3" json:"total_files,omitempty"`
-	HealthyFiles         uint32                 `protobuf:"varint,3,opt,name=healthy_files,json=healthyFiles,proto3" json:"healthy_files,omitempty"`
-	UnderReplicatedFiles uint32                 `protobuf:"varint,4,opt,name=under_replicated_files,json=underReplicatedFiles,proto3" json:"under_replicated_files,omitempty"`
-	OverReplicatedFiles  uint32                 `protobuf:"varint,5,opt,name=over_replicated_files,json=overReplicatedFiles,proto3" json:"over_replicated_files,omitempty"`
-	unknownFields        protoimpl.UnknownFields
-	sizeCache            protoimpl.SizeCache
+// 创建目录快照操作的数据
+type CreateSnapshotOperation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // 由 leader 决定，保证各节点一致
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetReplicationInfoResponse) Reset() {
-	*x = GetReplicationInfoResponse{}
-	mi := &file_metaServer_proto_msgTypes[25]
+func (x *CreateSnapshotOperation) Reset() {
+	*x = CreateSnapshotOperation{}
+	mi := &file_metaServer_proto_msgTypes[88]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetReplicationInfoResponse) String() string {
+func (x *CreateSnapshotOperation) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetReplicationInfoResponse) ProtoMessage() {}
+func (*CreateSnapshotOperation) ProtoMessage() {}
 
-func (x *GetReplicationInfoResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_metaServer_proto_msgTypes[25]
+func (x *CreateSnapshotOperation) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[88]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1689,67 +5979,55 @@ func (x *GetReplicationInfoResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetReplicationInfoResponse.ProtoReflect.Descriptor instead.
-func (*GetReplicationInfoResponse) Descriptor() ([]byte, []int) {
-	return file_metaServer_proto_rawDescGZIP(), []int{25}
-}
-
-func (x *GetReplicationInfoResponse) GetFiles() []*ReplicationStatus {
-	if x != nil {
-		return x.Files
-	}
-	return nil
-}
-
-func (x *GetReplicationInfoResponse) GetTotalFiles() uint32 {
-	if x != nil {
-		return x.TotalFiles
-	}
-	return 0
+// Deprecated: Use CreateSnapshotOperation.ProtoReflect.Descriptor instead.
+func (*CreateSnapshotOperation) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{88}
 }
 
-func (x *GetReplicationInfoResponse) GetHealthyFiles() uint32 {
+func (x *CreateSnapshotOperation) GetName() string {
 	if x != nil {
-		return x.HealthyFiles
+		return x.Name
 	}
-	return 0
+	return ""
 }
 
-func (x *GetReplicationInfoResponse) GetUnderReplicatedFiles() uint32 {
+func (x *CreateSnapshotOperation) GetPath() string {
 	if x != nil {
-		return x.UnderReplicatedFiles
+		return x.Path
 	}
-	return 0
+	return ""
 }
 
-func (x *GetReplicationInfoResponse) GetOverReplicatedFiles() uint32 {
+func (x *CreateSnapshotOperation) GetCreatedAt() int64 {
 	if x != nil {
-		return x.OverReplicatedFiles
+		return x.CreatedAt
 	}
 	return 0
 }
 
-type GetLeaderRequest struct {
+// 删除目录快照操作的数据
+type DeleteSnapshotOperation struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetLeaderRequest) Reset() {
-	*x = GetLeaderRequest{}
-	mi := &file_metaServer_proto_msgTypes[26]
+func (x *DeleteSnapshotOperation) Reset() {
+	*x = DeleteSnapshotOperation{}
+	mi := &file_metaServer_proto_msgTypes[89]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetLeaderRequest) String() string {
+func (x *DeleteSnapshotOperation) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetLeaderRequest) ProtoMessage() {}
+func (*DeleteSnapshotOperation) ProtoMessage() {}
 
-func (x *GetLeaderRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_metaServer_proto_msgTypes[26]
+func (x *DeleteSnapshotOperation) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[89]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1760,34 +6038,43 @@ func (x *GetLeaderRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetLeaderRequest.ProtoReflect.Descriptor instead.
-func (*GetLeaderRequest) Descriptor() ([]byte, []int) {
-	return file_metaServer_proto_rawDescGZIP(), []int{26}
+// Deprecated: Use DeleteSnapshotOperation.ProtoReflect.Descriptor instead.
+func (*DeleteSnapshotOperation) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{89}
+}
+
+func (x *DeleteSnapshotOperation) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
 }
 
-type GetLeaderResponse struct {
+// 请求WAL同步的消息
+type RequestWALSyncRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Leader        *MetaServerMsg         `protobuf:"bytes,1,opt,name=leader,proto3" json:"leader,omitempty"`
-	Followers     []*MetaServerMsg       `protobuf:"bytes,2,rep,name=followers,proto3" json:"followers,omitempty"`
+	NodeId        string                 `protobuf:"bytes,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`                      // 请求同步的节点ID
+	LastLogIndex  uint64                 `protobuf:"varint,2,opt,name=last_log_index,json=lastLogIndex,proto3" json:"last_log_index,omitempty"` // 最后同步的日志索引，0表示从头开始
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`                                    // 同步原因，如"rejoin_cluster"
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetLeaderResponse) Reset() {
-	*x = GetLeaderResponse{}
-	mi := &file_metaServer_proto_msgTypes[27]
+func (x *RequestWALSyncRequest) Reset() {
+	*x = RequestWALSyncRequest{}
+	mi := &file_metaServer_proto_msgTypes[90]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetLeaderResponse) String() string {
+func (x *RequestWALSyncRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetLeaderResponse) ProtoMessage() {}
+func (*RequestWALSyncRequest) ProtoMessage() {}
 
-func (x *GetLeaderResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_metaServer_proto_msgTypes[27]
+func (x *RequestWALSyncRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[90]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1798,52 +6085,57 @@ func (x *GetLeaderResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetLeaderResponse.ProtoReflect.Descriptor instead.
-func (*GetLeaderResponse) Descriptor() ([]byte, []int) {
-	return file_metaServer_proto_rawDescGZIP(), []int{27}
+// Deprecated: Use RequestWALSyncRequest.ProtoReflect.Descriptor instead.
+func (*RequestWALSyncRequest) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{90}
 }
 
-func (x *GetLeaderResponse) GetLeader() *MetaServerMsg {
+func (x *RequestWALSyncRequest) GetNodeId() string {
 	if x != nil {
-		return x.Leader
+		return x.NodeId
 	}
-	return nil
+	return ""
 }
 
-func (x *GetLeaderResponse) GetFollowers() []*MetaServerMsg {
+func (x *RequestWALSyncRequest) GetLastLogIndex() uint64 {
 	if x != nil {
-		return x.Followers
+		return x.LastLogIndex
 	}
-	return nil
+	return 0
 }
 
-// WAL日志条目 (用于主从同步)
-type LogEntry struct {
+func (x *RequestWALSyncRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type RequestVoteRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	LogIndex      uint64                 `protobuf:"varint,1,opt,name=log_index,json=logIndex,proto3" json:"log_index,omitempty"`                     // 日志序号
-	Timestamp     int64                  `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`                                   // 时间戳
-	Operation     WALOperationType       `protobuf:"varint,3,opt,name=operation,proto3,enum=dfs_project.WALOperationType" json:"operation,omitempty"` // 操作类型
-	Data          []byte                 `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`                                              // 操作数据（JSON格式）
-	Checksum      string                 `protobuf:"bytes,5,opt,name=checksum,proto3" json:"checksum,omitempty"`                                      // 数据校验和
+	Term          uint64                 `protobuf:"varint,1,opt,name=term,proto3" json:"term,omitempty"`                                       // 候选人任期
+	CandidateId   string                 `protobuf:"bytes,2,opt,name=candidate_id,json=candidateId,proto3" json:"candidate_id,omitempty"`       // 候选人节点ID
+	LastLogIndex  uint64                 `protobuf:"varint,3,opt,name=last_log_index,json=lastLogIndex,proto3" json:"last_log_index,omitempty"` // 候选人最后一条日志的索引
+	LastLogTerm   uint64                 `protobuf:"varint,4,opt,name=last_log_term,json=lastLogTerm,proto3" json:"last_log_term,omitempty"`    // 候选人最后一条日志的任期
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *LogEntry) Reset() {
-	*x = LogEntry{}
-	mi := &file_metaServer_proto_msgTypes[28]
+func (x *RequestVoteRequest) Reset() {
+	*x = RequestVoteRequest{}
+	mi := &file_metaServer_proto_msgTypes[91]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *LogEntry) String() string {
+func (x *RequestVoteRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LogEntry) ProtoMessage() {}
+func (*RequestVoteRequest) ProtoMessage() {}
 
-func (x *LogEntry) ProtoReflect() protoreflect.Message {
-	mi := &file_metaServer_proto_msgTypes[28]
+func (x *RequestVoteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[91]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1854,70 +6146,62 @@ func (x *LogEntry) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LogEntry.ProtoReflect.Descriptor instead.
-func (*LogEntry) Descriptor() ([]byte, []int) {
-	return file_metaServer_proto_rawDescGZIP(), []int{28}
-}
-
-func (x *LogEntry) GetLogIndex() uint64 {
-	if x != nil {
-		return x.LogIndex
-	}
-	return 0
+// Deprecated: Use RequestVoteRequest.ProtoReflect.Descriptor instead.
+func (*RequestVoteRequest) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{91}
 }
 
-func (x *LogEntry) GetTimestamp() int64 {
+func (x *RequestVoteRequest) GetTerm() uint64 {
 	if x != nil {
-		return x.Timestamp
+		return x.Term
 	}
 	return 0
 }
 
-func (x *LogEntry) GetOperation() WALOperationType {
+func (x *RequestVoteRequest) GetCandidateId() string {
 	if x != nil {
-		return x.Operation
+		return x.CandidateId
 	}
-	return WALOperationType_CREATE_NODE
+	return ""
 }
 
-func (x *LogEntry) GetData() []byte {
+func (x *RequestVoteRequest) GetLastLogIndex() uint64 {
 	if x != nil {
-		return x.Data
+		return x.LastLogIndex
 	}
-	return nil
+	return 0
 }
 
-func (x *LogEntry) GetChecksum() string {
+func (x *RequestVoteRequest) GetLastLogTerm() uint64 {
 	if x != nil {
-		return x.Checksum
+		return x.LastLogTerm
 	}
-	return ""
+	return 0
 }
 
-// 创建节点操作的数据
-type CreateNodeOperation struct {
+type RequestVoteResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
-	Type          FileType               `protobuf:"varint,2,opt,name=type,proto3,enum=dfs_project.FileType" json:"type,omitempty"`
+	Term          uint64                 `protobuf:"varint,1,opt,name=term,proto3" json:"term,omitempty"`                                  // 投票方当前任期
+	VoteGranted   bool                   `protobuf:"varint,2,opt,name=vote_granted,json=voteGranted,proto3" json:"vote_granted,omitempty"` // 是否投票
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CreateNodeOperation) Reset() {
-	*x = CreateNodeOperation{}
-	mi := &file_metaServer_proto_msgTypes[29]
+func (x *RequestVoteResponse) Reset() {
+	*x = RequestVoteResponse{}
+	mi := &file_metaServer_proto_msgTypes[92]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CreateNodeOperation) String() string {
+func (x *RequestVoteResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateNodeOperation) ProtoMessage() {}
+func (*RequestVoteResponse) ProtoMessage() {}
 
-func (x *CreateNodeOperation) ProtoReflect() protoreflect.Message {
-	mi := &file_metaServer_proto_msgTypes[29]
+func (x *RequestVoteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[92]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1928,49 +6212,53 @@ func (x *CreateNodeOperation) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreateNodeOperation.ProtoReflect.Descriptor instead.
-func (*CreateNodeOperation) Descriptor() ([]byte, []int) {
-	return file_metaServer_proto_rawDescGZIP(), []int{29}
+// Deprecated: Use RequestVoteResponse.ProtoReflect.Descriptor instead.
+func (*RequestVoteResponse) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{92}
 }
 
-func (x *CreateNodeOperation) GetPath() string {
+func (x *RequestVoteResponse) GetTerm() uint64 {
 	if x != nil {
-		return x.Path
+		return x.Term
 	}
-	return ""
+	return 0
 }
 
-func (x *CreateNodeOperation) GetType() FileType {
+func (x *RequestVoteResponse) GetVoteGranted() bool {
 	if x != nil {
-		return x.Type
+		return x.VoteGranted
 	}
-	return FileType_Unknown
+	return false
 }
 
-// 删除节点操作的数据
-type DeleteNodeOperation struct {
+type AppendEntriesRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
-	Recursive     bool                   `protobuf:"varint,2,opt,name=recursive,proto3" json:"recursive,omitempty"`
+	Term          uint64                 `protobuf:"varint,1,opt,name=term,proto3" json:"term,omitempty"`                                       // leader任期
+	LeaderId      string                 `protobuf:"bytes,2,opt,name=leader_id,json=leaderId,proto3" json:"leader_id,omitempty"`                // leader节点ID
+	LeaderAddr    string                 `protobuf:"bytes,3,opt,name=leader_addr,json=leaderAddr,proto3" json:"leader_addr,omitempty"`          // leader地址，用于客户端重定向
+	PrevLogIndex  uint64                 `protobuf:"varint,4,opt,name=prev_log_index,json=prevLogIndex,proto3" json:"prev_log_index,omitempty"` // 新条目之前一条日志的索引
+	PrevLogTerm   uint64                 `protobuf:"varint,5,opt,name=prev_log_term,json=prevLogTerm,proto3" json:"prev_log_term,omitempty"`    // 新条目之前一条日志的任期
+	Entries       []*LogEntry            `protobuf:"bytes,6,rep,name=entries,proto3" json:"entries,omitempty"`                                  // 待追加的日志条目，为空时为心跳
+	LeaderCommit  uint64                 `protobuf:"varint,7,opt,name=leader_commit,json=leaderCommit,proto3" json:"leader_commit,omitempty"`   // leader已提交的日志索引
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteNodeOperation) Reset() {
-	*x = DeleteNodeOperation{}
-	mi := &file_metaServer_proto_msgTypes[30]
+func (x *AppendEntriesRequest) Reset() {
+	*x = AppendEntriesRequest{}
+	mi := &file_metaServer_proto_msgTypes[93]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteNodeOperation) String() string {
+func (x *AppendEntriesRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteNodeOperation) ProtoMessage() {}
+func (*AppendEntriesRequest) ProtoMessage() {}
 
-func (x *DeleteNodeOperation) ProtoReflect() protoreflect.Message {
-	mi := &file_metaServer_proto_msgTypes[30]
+func (x *AppendEntriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[93]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1981,50 +6269,84 @@ func (x *DeleteNodeOperation) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteNodeOperation.ProtoReflect.Descriptor instead.
-func (*DeleteNodeOperation) Descriptor() ([]byte, []int) {
-	return file_metaServer_proto_rawDescGZIP(), []int{30}
+// Deprecated: Use AppendEntriesRequest.ProtoReflect.Descriptor instead.
+func (*AppendEntriesRequest) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{93}
 }
 
-func (x *DeleteNodeOperation) GetPath() string {
+func (x *AppendEntriesRequest) GetTerm() uint64 {
 	if x != nil {
-		return x.Path
+		return x.Term
+	}
+	return 0
+}
+
+func (x *AppendEntriesRequest) GetLeaderId() string {
+	if x != nil {
+		return x.LeaderId
 	}
 	return ""
 }
 
-func (x *DeleteNodeOperation) GetRecursive() bool {
+func (x *AppendEntriesRequest) GetLeaderAddr() string {
 	if x != nil {
-		return x.Recursive
+		return x.LeaderAddr
 	}
-	return false
+	return ""
 }
 
-// 更新节点操作的数据
-type UpdateNodeOperation struct {
+func (x *AppendEntriesRequest) GetPrevLogIndex() uint64 {
+	if x != nil {
+		return x.PrevLogIndex
+	}
+	return 0
+}
+
+func (x *AppendEntriesRequest) GetPrevLogTerm() uint64 {
+	if x != nil {
+		return x.PrevLogTerm
+	}
+	return 0
+}
+
+func (x *AppendEntriesRequest) GetEntries() []*LogEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *AppendEntriesRequest) GetLeaderCommit() uint64 {
+	if x != nil {
+		return x.LeaderCommit
+	}
+	return 0
+}
+
+type AppendEntriesResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
-	Size          int64                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
-	Mtime         int64                  `protobuf:"varint,3,opt,name=mtime,proto3" json:"mtime,omitempty"`
+	Term          uint64                 `protobuf:"varint,1,opt,name=term,proto3" json:"term,omitempty"`                                       // follower当前任期
+	Success       bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`                                 // prev_log_index/prev_log_term 是否匹配
+	LastLogIndex  uint64                 `protobuf:"varint,3,opt,name=last_log_index,json=lastLogIndex,proto3" json:"last_log_index,omitempty"` // follower最后一条日志的索引，用于leader快速回退
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateNodeOperation) Reset() {
-	*x = UpdateNodeOperation{}
-	mi := &file_metaServer_proto_msgTypes[31]
+func (x *AppendEntriesResponse) Reset() {
+	*x = AppendEntriesResponse{}
+	mi := &file_metaServer_proto_msgTypes[94]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateNodeOperation) String() string {
+func (x *AppendEntriesResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateNodeOperation) ProtoMessage() {}
+func (*AppendEntriesResponse) ProtoMessage() {}
 
-func (x *UpdateNodeOperation) ProtoReflect() protoreflect.Message {
-	mi := &file_metaServer_proto_msgTypes[31]
+func (x *AppendEntriesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[94]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2035,59 +6357,59 @@ func (x *UpdateNodeOperation) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateNodeOperation.ProtoReflect.Descriptor instead.
-func (*UpdateNodeOperation) Descriptor() ([]byte, []int) {
-	return file_metaServer_proto_rawDescGZIP(), []int{31}
+// Deprecated: Use AppendEntriesResponse.ProtoReflect.Descriptor instead.
+func (*AppendEntriesResponse) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{94}
 }
 
-func (x *UpdateNodeOperation) GetPath() string {
+func (x *AppendEntriesResponse) GetTerm() uint64 {
 	if x != nil {
-		return x.Path
+		return x.Term
 	}
-	return ""
+	return 0
 }
 
-func (x *UpdateNodeOperation) GetSize() int64 {
+func (x *AppendEntriesResponse) GetSuccess() bool {
 	if x != nil {
-		return x.Size
+		return x.Success
 	}
-	return 0
+	return false
 }
 
-func (x *UpdateNodeOperation) GetMtime() int64 {
+func (x *AppendEntriesResponse) GetLastLogIndex() uint64 {
 	if x != nil {
-		return x.Mtime
+		return x.LastLogIndex
 	}
 	return 0
 }
 
-// 完成写入操作的数据
-type FinalizeWriteOperation struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	Path           string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
-	BlockLocations []*BlockLocations      `protobuf:"bytes,2,rep,name=block_locations,json=blockLocations,proto3" json:"block_locations,omitempty"`
-	Inode          uint64                 `protobuf:"varint,3,opt,name=inode,proto3" json:"inode,omitempty"`
-	Size           int64                  `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
-	Md5            string                 `protobuf:"bytes,5,opt,name=md5,proto3" json:"md5,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+type InstallSnapshotRequest struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Term              uint64                 `protobuf:"varint,1,opt,name=term,proto3" json:"term,omitempty"`                                                      // leader任期，只在第一个分块中设置
+	LeaderId          string                 `protobuf:"bytes,2,opt,name=leader_id,json=leaderId,proto3" json:"leader_id,omitempty"`                               // leader节点ID
+	LeaderAddr        string                 `protobuf:"bytes,3,opt,name=leader_addr,json=leaderAddr,proto3" json:"leader_addr,omitempty"`                         // leader地址
+	LastIncludedIndex uint64                 `protobuf:"varint,4,opt,name=last_included_index,json=lastIncludedIndex,proto3" json:"last_included_index,omitempty"` // 快照包含的最后一条日志的索引
+	LastIncludedTerm  uint64                 `protobuf:"varint,5,opt,name=last_included_term,json=lastIncludedTerm,proto3" json:"last_included_term,omitempty"`    // 快照包含的最后一条日志的任期
+	Data              []byte                 `protobuf:"bytes,6,opt,name=data,proto3" json:"data,omitempty"`                                                       // 快照数据分块（Badger Backup 格式）
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
-func (x *FinalizeWriteOperation) Reset() {
-	*x = FinalizeWriteOperation{}
-	mi := &file_metaServer_proto_msgTypes[32]
+func (x *InstallSnapshotRequest) Reset() {
+	*x = InstallSnapshotRequest{}
+	mi := &file_metaServer_proto_msgTypes[95]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *FinalizeWriteOperation) String() string {
+func (x *InstallSnapshotRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*FinalizeWriteOperation) ProtoMessage() {}
+func (*InstallSnapshotRequest) ProtoMessage() {}
 
-func (x *FinalizeWriteOperation) ProtoReflect() protoreflect.Message {
-	mi := &file_metaServer_proto_msgTypes[32]
+func (x *InstallSnapshotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[95]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2098,44 +6420,95 @@ func (x *FinalizeWriteOperation) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use FinalizeWriteOperation.ProtoReflect.Descriptor instead.
-func (*FinalizeWriteOperation) Descriptor() ([]byte, []int) {
-	return file_metaServer_proto_rawDescGZIP(), []int{32}
+// Deprecated: Use InstallSnapshotRequest.ProtoReflect.Descriptor instead.
+func (*InstallSnapshotRequest) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{95}
 }
 
-func (x *FinalizeWriteOperation) GetPath() string {
+func (x *InstallSnapshotRequest) GetTerm() uint64 {
 	if x != nil {
-		return x.Path
+		return x.Term
+	}
+	return 0
+}
+
+func (x *InstallSnapshotRequest) GetLeaderId() string {
+	if x != nil {
+		return x.LeaderId
 	}
 	return ""
 }
 
-func (x *FinalizeWriteOperation) GetBlockLocations() []*BlockLocations {
+func (x *InstallSnapshotRequest) GetLeaderAddr() string {
 	if x != nil {
-		return x.BlockLocations
+		return x.LeaderAddr
 	}
-	return nil
+	return ""
 }
 
-func (x *FinalizeWriteOperation) GetInode() uint64 {
+func (x *InstallSnapshotRequest) GetLastIncludedIndex() uint64 {
 	if x != nil {
-		return x.Inode
+		return x.LastIncludedIndex
 	}
 	return 0
 }
 
-func (x *FinalizeWriteOperation) GetSize() int64 {
+func (x *InstallSnapshotRequest) GetLastIncludedTerm() uint64 {
 	if x != nil {
-		return x.Size
+		return x.LastIncludedTerm
 	}
 	return 0
 }
 
-func (x *FinalizeWriteOperation) GetMd5() string {
+func (x *InstallSnapshotRequest) GetData() []byte {
 	if x != nil {
-		return x.Md5
+		return x.Data
 	}
-	return ""
+	return nil
+}
+
+type InstallSnapshotResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Term          uint64                 `protobuf:"varint,1,opt,name=term,proto3" json:"term,omitempty"` // follower当前任期
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InstallSnapshotResponse) Reset() {
+	*x = InstallSnapshotResponse{}
+	mi := &file_metaServer_proto_msgTypes[96]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InstallSnapshotResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InstallSnapshotResponse) ProtoMessage() {}
+
+func (x *InstallSnapshotResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metaServer_proto_msgTypes[96]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InstallSnapshotResponse.ProtoReflect.Descriptor instead.
+func (*InstallSnapshotResponse) Descriptor() ([]byte, []int) {
+	return file_metaServer_proto_rawDescGZIP(), []int{96}
+}
+
+func (x *InstallSnapshotResponse) GetTerm() uint64 {
+	if x != nil {
+		return x.Term
+	}
+	return 0
 }
 
 var File_metaServer_proto protoreflect.FileDescriptor
@@ -2146,29 +6519,37 @@ const file_metaServer_proto_rawDesc = "" +
 	"\vReplicaData\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
 	"\x06dsNode\x18\x02 \x01(\tR\x06dsNode\x12\x12\n" +
-	"\x04path\x18\x03 \x01(\tR\x04path\"\xc1\x01\n" +
+	"\x04path\x18\x03 \x01(\tR\x04path\"\xcb\x02\n" +
 	"\bStatInfo\x12\x12\n" +
 	"\x04path\x18\x01 \x01(\tR\x04path\x12\x12\n" +
 	"\x04size\x18\x02 \x01(\x03R\x04size\x12\x14\n" +
 	"\x05mtime\x18\x03 \x01(\x03R\x05mtime\x12)\n" +
 	"\x04type\x18\x04 \x01(\x0e2\x15.dfs_project.FileTypeR\x04type\x12:\n" +
 	"\vreplicaData\x18\x05 \x03(\v2\x18.dfs_project.ReplicaDataR\vreplicaData\x12\x10\n" +
-	"\x03md5\x18\x06 \x01(\tR\x03md5\"7\n" +
+	"\x03md5\x18\x06 \x01(\tR\x03md5\x12\x14\n" +
+	"\x05owner\x18\a \x01(\tR\x05owner\x12\x14\n" +
+	"\x05group\x18\b \x01(\tR\x05group\x12\x12\n" +
+	"\x04mode\x18\t \x01(\rR\x04mode\x12'\n" +
+	"\x03acl\x18\n" +
+	" \x03(\v2\x15.dfs_project.AclEntryR\x03acl\x12\x1f\n" +
+	"\vchild_count\x18\v \x01(\x03R\n" +
+	"childCount\"7\n" +
 	"\rMetaServerMsg\x12\x12\n" +
 	"\x04host\x18\x01 \x01(\tR\x04host\x12\x12\n" +
-	"\x04port\x18\x02 \x01(\x05R\x04port\"\x93\x01\n" +
+	"\x04port\x18\x02 \x01(\x05R\x04port\"\xaf\x01\n" +
 	"\rDataServerMsg\x12\x12\n" +
 	"\x04host\x18\x01 \x01(\tR\x04host\x12\x12\n" +
 	"\x04port\x18\x02 \x01(\x05R\x04port\x12\x1c\n" +
 	"\tfileTotal\x18\x03 \x01(\x05R\tfileTotal\x12\x1a\n" +
 	"\bcapacity\x18\x04 \x01(\x05R\bcapacity\x12 \n" +
-	"\vuseCapacity\x18\x05 \x01(\x05R\vuseCapacity\"\xd7\x01\n" +
+	"\vuseCapacity\x18\x05 \x01(\x05R\vuseCapacity\x12\x1a\n" +
+	"\btopology\x18\x06 \x01(\tR\btopology\"\xd7\x01\n" +
 	"\vClusterInfo\x12F\n" +
 	"\x10masterMetaServer\x18\x01 \x01(\v2\x1a.dfs_project.MetaServerMsgR\x10masterMetaServer\x12D\n" +
 	"\x0fslaveMetaServer\x18\x02 \x03(\v2\x1a.dfs_project.MetaServerMsgR\x0fslaveMetaServer\x12:\n" +
 	"\n" +
 	"dataServer\x18\x03 \x03(\v2\x1a.dfs_project.DataServerMsgR\n" +
-	"dataServer\"\xf9\x01\n" +
+	"dataServer\"\xa0\x03\n" +
 	"\bNodeInfo\x12\x14\n" +
 	"\x05inode\x18\x01 \x01(\x04R\x05inode\x12\x12\n" +
 	"\x04path\x18\x02 \x01(\tR\x04path\x12)\n" +
@@ -2177,41 +6558,103 @@ const file_metaServer_proto_rawDesc = "" +
 	"\x05mtime\x18\x05 \x01(\x03R\x05mtime\x12 \n" +
 	"\vreplication\x18\x06 \x01(\rR\vreplication\x12\x10\n" +
 	"\x03md5\x18\a \x01(\tR\x03md5\x12:\n" +
-	"\vreplicaData\x18\b \x03(\v2\x18.dfs_project.ReplicaDataR\vreplicaData\"I\n" +
+	"\vreplicaData\x18\b \x03(\v2\x18.dfs_project.ReplicaDataR\vreplicaData\x12\x1b\n" +
+	"\tec_policy\x18\t \x01(\tR\becPolicy\x12\x14\n" +
+	"\x05owner\x18\n" +
+	" \x01(\tR\x05owner\x12\x14\n" +
+	"\x05group\x18\v \x01(\tR\x05group\x12\x12\n" +
+	"\x04mode\x18\f \x01(\rR\x04mode\x12'\n" +
+	"\x03acl\x18\r \x03(\v2\x15.dfs_project.AclEntryR\x03acl\x12\x1f\n" +
+	"\vchild_count\x18\x0e \x01(\x03R\n" +
+	"childCount\"F\n" +
+	"\bAclEntry\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04perm\x18\x03 \x01(\rR\x04perm\"\x9b\x01\n" +
 	"\x0eBlockLocations\x12\x19\n" +
 	"\bblock_id\x18\x01 \x01(\x04R\ablockId\x12\x1c\n" +
-	"\tlocations\x18\x02 \x03(\tR\tlocations\"D\n" +
+	"\tlocations\x18\x02 \x03(\tR\tlocations\x12\x1b\n" +
+	"\tec_policy\x18\x03 \x01(\tR\becPolicy\x12\x1d\n" +
+	"\n" +
+	"stripe_ids\x18\x04 \x03(\x04R\tstripeIds\x12\x14\n" +
+	"\x05token\x18\x05 \x01(\tR\x05token\"D\n" +
 	"\x0eSimpleResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\"R\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\x88\x01\n" +
 	"\x11CreateNodeRequest\x12\x12\n" +
 	"\x04path\x18\x01 \x01(\tR\x04path\x12)\n" +
-	"\x04type\x18\x02 \x01(\x0e2\x15.dfs_project.FileTypeR\x04type\"(\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x15.dfs_project.FileTypeR\x04type\x12 \n" +
+	"\vreplication\x18\x03 \x01(\rR\vreplication\x12\x12\n" +
+	"\x04mode\x18\x04 \x01(\rR\x04mode\"(\n" +
 	"\x12GetNodeInfoRequest\x12\x12\n" +
 	"\x04path\x18\x01 \x01(\tR\x04path\"H\n" +
 	"\x13GetNodeInfoResponse\x121\n" +
-	"\bstatInfo\x18\x01 \x01(\v2\x15.dfs_project.StatInfoR\bstatInfo\"*\n" +
+	"\bstatInfo\x18\x01 \x01(\v2\x15.dfs_project.StatInfoR\bstatInfo\"o\n" +
 	"\x14ListDirectoryRequest\x12\x12\n" +
-	"\x04path\x18\x01 \x01(\tR\x04path\"D\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\rR\x05limit\x12-\n" +
+	"\x12continuation_token\x18\x03 \x01(\tR\x11continuationToken\"|\n" +
 	"\x15ListDirectoryResponse\x12+\n" +
-	"\x05nodes\x18\x01 \x03(\v2\x15.dfs_project.StatInfoR\x05nodes\"E\n" +
+	"\x05nodes\x18\x01 \x03(\v2\x15.dfs_project.StatInfoR\x05nodes\x126\n" +
+	"\x17next_continuation_token\x18\x02 \x01(\tR\x15nextContinuationToken\"d\n" +
 	"\x11DeleteNodeRequest\x12\x12\n" +
 	"\x04path\x18\x01 \x01(\tR\x04path\x12\x1c\n" +
-	"\trecursive\x18\x02 \x01(\bR\trecursive\"B\n" +
+	"\trecursive\x18\x02 \x01(\bR\trecursive\x12\x1d\n" +
+	"\n" +
+	"skip_trash\x18\x03 \x01(\bR\tskipTrash\"3\n" +
+	"\x12RestoreNodeRequest\x12\x1d\n" +
+	"\n" +
+	"trash_path\x18\x01 \x01(\tR\ttrashPath\"C\n" +
+	"\x13RestoreNodeResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\"Q\n" +
+	"\rRenameRequest\x12\x10\n" +
+	"\x03src\x18\x01 \x01(\tR\x03src\x12\x10\n" +
+	"\x03dst\x18\x02 \x01(\tR\x03dst\x12\x1c\n" +
+	"\toverwrite\x18\x03 \x01(\bR\toverwrite\"\x9d\x01\n" +
 	"\x18GetBlockLocationsRequest\x12\x12\n" +
 	"\x04path\x18\x01 \x01(\tR\x04path\x12\x12\n" +
-	"\x04size\x18\x02 \x01(\x03R\x04size\"w\n" +
+	"\x04size\x18\x02 \x01(\x03R\x04size\x12\x16\n" +
+	"\x06append\x18\x03 \x01(\bR\x06append\x12\x1f\n" +
+	"\vclient_name\x18\x04 \x01(\tR\n" +
+	"clientName\x12 \n" +
+	"\vreplication\x18\x05 \x01(\rR\vreplication\"\xfe\x01\n" +
 	"\x19GetBlockLocationsResponse\x12\x14\n" +
 	"\x05inode\x18\x01 \x01(\x04R\x05inode\x12D\n" +
-	"\x0fblock_locations\x18\x02 \x03(\v2\x1b.dfs_project.BlockLocationsR\x0eblockLocations\"f\n" +
+	"\x0fblock_locations\x18\x02 \x03(\v2\x1b.dfs_project.BlockLocationsR\x0eblockLocations\x12*\n" +
+	"\x11first_block_index\x18\x03 \x01(\x04R\x0ffirstBlockIndex\x12\x1f\n" +
+	"\vtail_offset\x18\x04 \x01(\x04R\n" +
+	"tailOffset\x128\n" +
+	"\tprev_tail\x18\x05 \x01(\v2\x1b.dfs_project.BlockLocationsR\bprevTail\"Z\n" +
+	"\x14GetBlockRangeRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\x03R\x06offset\x12\x16\n" +
+	"\x06length\x18\x03 \x01(\x03R\x06length\"\x9b\x01\n" +
+	"\n" +
+	"BlockRange\x12\x1f\n" +
+	"\vblock_index\x18\x01 \x01(\x04R\n" +
+	"blockIndex\x12!\n" +
+	"\fblock_offset\x18\x02 \x01(\x04R\vblockOffset\x12\x16\n" +
+	"\x06length\x18\x03 \x01(\x04R\x06length\x121\n" +
+	"\x05block\x18\x04 \x01(\v2\x1b.dfs_project.BlockLocationsR\x05block\"r\n" +
+	"\x15GetBlockRangeResponse\x12\x14\n" +
+	"\x05inode\x18\x01 \x01(\x04R\x05inode\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x03R\x04size\x12/\n" +
+	"\x06ranges\x18\x03 \x03(\v2\x17.dfs_project.BlockRangeR\x06ranges\"\x87\x01\n" +
 	"\x14FinalizeWriteRequest\x12\x12\n" +
 	"\x04path\x18\x01 \x01(\tR\x04path\x12\x14\n" +
 	"\x05inode\x18\x02 \x01(\x04R\x05inode\x12\x12\n" +
 	"\x04size\x18\x03 \x01(\x03R\x04size\x12\x10\n" +
-	"\x03md5\x18\x04 \x01(\tR\x03md5\"\x17\n" +
+	"\x03md5\x18\x04 \x01(\tR\x03md5\x12\x1f\n" +
+	"\vclient_name\x18\x05 \x01(\tR\n" +
+	"clientName\"H\n" +
+	"\x11RenewLeaseRequest\x12\x1f\n" +
+	"\vclient_name\x18\x01 \x01(\tR\n" +
+	"clientName\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\"\x17\n" +
 	"\x15GetClusterInfoRequest\"T\n" +
 	"\x16GetClusterInfoResponse\x12:\n" +
-	"\vclusterInfo\x18\x01 \x01(\v2\x18.dfs_project.ClusterInfoR\vclusterInfo\"\xf1\x01\n" +
+	"\vclusterInfo\x18\x01 \x01(\v2\x18.dfs_project.ClusterInfoR\vclusterInfo\"\xf3\x02\n" +
 	"\x10HeartbeatRequest\x12#\n" +
 	"\rdataServer_id\x18\x01 \x01(\tR\fdataServerId\x12'\n" +
 	"\x0fdataServer_addr\x18\x02 \x01(\tR\x0edataServerAddr\x12\x1f\n" +
@@ -2220,15 +6663,30 @@ const file_metaServer_proto_rawDesc = "" +
 	"\n" +
 	"free_space\x18\x04 \x01(\x04R\tfreeSpace\x12(\n" +
 	"\x10block_ids_report\x18\x05 \x03(\x04R\x0eblockIdsReport\x12%\n" +
-	"\x0etotal_capacity\x18\x06 \x01(\x04R\rtotalCapacity\"\x9f\x01\n" +
+	"\x0etotal_capacity\x18\x06 \x01(\x04R\rtotalCapacity\x12*\n" +
+	"\x11corrupt_block_ids\x18\a \x03(\x04R\x0fcorruptBlockIds\x128\n" +
+	"\vscrub_stats\x18\b \x01(\v2\x17.dfs_project.ScrubStatsR\n" +
+	"scrubStats\x12\x1a\n" +
+	"\btopology\x18\t \x01(\tR\btopology\"\xa5\x01\n" +
+	"\n" +
+	"ScrubStats\x12%\n" +
+	"\x0eblocks_scanned\x18\x01 \x01(\x04R\rblocksScanned\x12%\n" +
+	"\x0ecorrupt_blocks\x18\x02 \x01(\x04R\rcorruptBlocks\x12$\n" +
+	"\x0elast_pass_time\x18\x03 \x01(\x03R\flastPassTime\x12#\n" +
+	"\rbytes_scanned\x18\x04 \x01(\x04R\fbytesScanned\"\xbd\x02\n" +
 	"\aCommand\x123\n" +
 	"\x06action\x18\x01 \x01(\x0e2\x1b.dfs_project.Command.ActionR\x06action\x12\x19\n" +
 	"\bblock_id\x18\x02 \x01(\x04R\ablockId\x12\x18\n" +
-	"\atargets\x18\x03 \x03(\tR\atargets\"*\n" +
+	"\atargets\x18\x03 \x03(\tR\atargets\x121\n" +
+	"\x05group\x18\x04 \x01(\v2\x1b.dfs_project.BlockLocationsR\x05group\x12!\n" +
+	"\fstripe_index\x18\x05 \x01(\rR\vstripeIndex\x12\x1c\n" +
+	"\tbandwidth\x18\x06 \x01(\x04R\tbandwidth\"T\n" +
 	"\x06Action\x12\x10\n" +
 	"\fDELETE_BLOCK\x10\x00\x12\x0e\n" +
 	"\n" +
-	"COPY_BLOCK\x10\x01\"E\n" +
+	"COPY_BLOCK\x10\x01\x12\x16\n" +
+	"\x12RECONSTRUCT_STRIPE\x10\x02\x12\x10\n" +
+	"\fENCODE_BLOCK\x10\x03\"E\n" +
 	"\x11HeartbeatResponse\x120\n" +
 	"\bcommands\x18\x01 \x03(\v2\x14.dfs_project.CommandR\bcommands\"/\n" +
 	"\x19GetReplicationInfoRequest\x12\x12\n" +
@@ -2245,64 +6703,378 @@ const file_metaServer_proto_rawDesc = "" +
 	"\x11expected_replicas\x18\x04 \x01(\rR\x10expectedReplicas\x12'\n" +
 	"\x0factual_replicas\x18\x05 \x01(\rR\x0eactualReplicas\x129\n" +
 	"\x06blocks\x18\x06 \x03(\v2!.dfs_project.BlockReplicationInfoR\x06blocks\x12\x16\n" +
-	"\x06status\x18\a \x01(\tR\x06status\"\x82\x02\n" +
+	"\x06status\x18\a \x01(\tR\x06status\"\xdd\x01\n" +
+	"\vFsckRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x122\n" +
+	"\x15only_under_replicated\x18\x02 \x01(\bR\x13onlyUnderReplicated\x12!\n" +
+	"\fonly_missing\x18\x03 \x01(\bR\vonlyMissing\x12!\n" +
+	"\fonly_corrupt\x18\x04 \x01(\bR\vonlyCorrupt\x12!\n" +
+	"\ftrigger_fsck\x18\x05 \x01(\bR\vtriggerFsck\x12\x1d\n" +
+	"\n" +
+	"batch_size\x18\x06 \x01(\rR\tbatchSize\"\xef\x02\n" +
+	"\tFsckBlock\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x14\n" +
+	"\x05inode\x18\x02 \x01(\x04R\x05inode\x12\x19\n" +
+	"\bblock_id\x18\x03 \x01(\x04R\ablockId\x12\x19\n" +
+	"\bgroup_id\x18\x04 \x01(\x04R\agroupId\x12 \n" +
+	"\vreplication\x18\x05 \x01(\rR\vreplication\x12-\n" +
+	"\x12expected_locations\x18\x06 \x03(\tR\x11expectedLocations\x12%\n" +
+	"\x0elive_locations\x18\a \x03(\tR\rliveLocations\x12+\n" +
+	"\x11corrupt_locations\x18\b \x03(\tR\x10corruptLocations\x12)\n" +
+	"\x10under_replicated\x18\t \x01(\bR\x0funderReplicated\x12\x18\n" +
+	"\amissing\x18\n" +
+	" \x01(\bR\amissing\x12\x18\n" +
+	"\acorrupt\x18\v \x01(\bR\acorrupt\"\xca\x02\n" +
+	"\vFsckSummary\x12\x1f\n" +
+	"\vtotal_files\x18\x01 \x01(\rR\n" +
+	"totalFiles\x12!\n" +
+	"\ftotal_blocks\x18\x02 \x01(\x04R\vtotalBlocks\x12%\n" +
+	"\x0ehealthy_blocks\x18\x03 \x01(\x04R\rhealthyBlocks\x126\n" +
+	"\x17under_replicated_blocks\x18\x04 \x01(\x04R\x15underReplicatedBlocks\x12%\n" +
+	"\x0emissing_blocks\x18\x05 \x01(\x04R\rmissingBlocks\x12%\n" +
+	"\x0ecorrupt_blocks\x18\x06 \x01(\x04R\rcorruptBlocks\x12#\n" +
+	"\rorphan_blocks\x18\a \x01(\x03R\forphanBlocks\x12%\n" +
+	"\x0efsck_triggered\x18\b \x01(\bR\rfsckTriggered\"r\n" +
+	"\fFsckResponse\x12.\n" +
+	"\x06blocks\x18\x01 \x03(\v2\x16.dfs_project.FsckBlockR\x06blocks\x122\n" +
+	"\asummary\x18\x02 \x01(\v2\x18.dfs_project.FsckSummaryR\asummary\"\x18\n" +
+	"\x16GetOrphanReportRequest\"\x91\x01\n" +
+	"\vOrphanBlock\x12\x19\n" +
+	"\bblock_id\x18\x01 \x01(\x04R\ablockId\x12\x1c\n" +
+	"\tlocations\x18\x02 \x03(\tR\tlocations\x12\x16\n" +
+	"\x06passes\x18\x03 \x01(\rR\x06passes\x12\x15\n" +
+	"\x06age_ms\x18\x04 \x01(\x03R\x05ageMs\x12\x1a\n" +
+	"\beligible\x18\x05 \x01(\bR\beligible\"\x9a\x02\n" +
+	"\x17GetOrphanReportResponse\x12\x17\n" +
+	"\adry_run\x18\x01 \x01(\bR\x06dryRun\x12$\n" +
+	"\x0elast_pass_time\x18\x02 \x01(\x03R\flastPassTime\x12&\n" +
+	"\x0flast_pass_found\x18\x03 \x01(\rR\rlastPassFound\x12\x18\n" +
+	"\apending\x18\x04 \x01(\rR\apending\x12#\n" +
+	"\rdeleted_total\x18\x05 \x01(\x04R\fdeletedTotal\x12%\n" +
+	"\x0ereported_total\x18\x06 \x01(\x04R\rreportedTotal\x122\n" +
+	"\aorphans\x18\a \x03(\v2\x18.dfs_project.OrphanBlockR\aorphans\"\x82\x02\n" +
 	"\x1aGetReplicationInfoResponse\x124\n" +
 	"\x05files\x18\x01 \x03(\v2\x1e.dfs_project.ReplicationStatusR\x05files\x12\x1f\n" +
 	"\vtotal_files\x18\x02 \x01(\rR\n" +
 	"totalFiles\x12#\n" +
 	"\rhealthy_files\x18\x03 \x01(\rR\fhealthyFiles\x124\n" +
 	"\x16under_replicated_files\x18\x04 \x01(\rR\x14underReplicatedFiles\x122\n" +
-	"\x15over_replicated_files\x18\x05 \x01(\rR\x13overReplicatedFiles\"\x12\n" +
+	"\x15over_replicated_files\x18\x05 \x01(\rR\x13overReplicatedFiles\"M\n" +
+	"\x15SetReplicationRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12 \n" +
+	"\vreplication\x18\x02 \x01(\rR\vreplication\"6\n" +
+	"\fChmodRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x12\n" +
+	"\x04mode\x18\x02 \x01(\rR\x04mode\"N\n" +
+	"\fChownRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x14\n" +
+	"\x05owner\x18\x02 \x01(\tR\x05owner\x12\x14\n" +
+	"\x05group\x18\x03 \x01(\tR\x05group\"L\n" +
+	"\rSetAclRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12'\n" +
+	"\x03acl\x18\x02 \x03(\v2\x15.dfs_project.AclEntryR\x03acl\"\xd4\x01\n" +
+	"\x0eDirectoryUsage\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x14\n" +
+	"\x05inode\x18\x02 \x01(\x04R\x05inode\x12\x14\n" +
+	"\x05bytes\x18\x03 \x01(\x03R\x05bytes\x12%\n" +
+	"\x0espace_consumed\x18\x04 \x01(\x03R\rspaceConsumed\x12\x1f\n" +
+	"\vinode_count\x18\x05 \x01(\x03R\n" +
+	"inodeCount\x12\x1b\n" +
+	"\tmax_bytes\x18\x06 \x01(\x04R\bmaxBytes\x12\x1d\n" +
+	"\n" +
+	"max_inodes\x18\a \x01(\x04R\tmaxInodes\"a\n" +
+	"\x0fSetQuotaRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x1b\n" +
+	"\tmax_bytes\x18\x02 \x01(\x04R\bmaxBytes\x12\x1d\n" +
+	"\n" +
+	"max_inodes\x18\x03 \x01(\x04R\tmaxInodes\"%\n" +
+	"\x0fGetQuotaRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\"E\n" +
+	"\x10GetQuotaResponse\x121\n" +
+	"\x05usage\x18\x01 \x01(\v2\x1b.dfs_project.DirectoryUsageR\x05usage\"I\n" +
+	"\x15GetUsageReportRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x1c\n" +
+	"\trecursive\x18\x02 \x01(\bR\trecursive\"W\n" +
+	"\x16GetUsageReportResponse\x12=\n" +
+	"\vdirectories\x18\x01 \x03(\v2\x1b.dfs_project.DirectoryUsageR\vdirectories\"\x8c\x01\n" +
+	"\fSnapshotInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\x03R\tcreatedAt\x12\x14\n" +
+	"\x05bytes\x18\x04 \x01(\x03R\x05bytes\x12\x1f\n" +
+	"\vinode_count\x18\x05 \x01(\x03R\n" +
+	"inodeCount\"?\n" +
+	"\x15CreateSnapshotRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"+\n" +
+	"\x15DeleteSnapshotRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"\x16\n" +
+	"\x14ListSnapshotsRequest\"P\n" +
+	"\x15ListSnapshotsResponse\x127\n" +
+	"\tsnapshots\x18\x01 \x03(\v2\x19.dfs_project.SnapshotInfoR\tsnapshots\"K\n" +
+	"\x1dSetErasureCodingPolicyRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x16\n" +
+	"\x06policy\x18\x02 \x01(\tR\x06policy\"3\n" +
+	"\x1dGetErasureCodingPolicyRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\"V\n" +
+	"\x1eGetErasureCodingPolicyResponse\x12\x16\n" +
+	"\x06policy\x18\x01 \x01(\tR\x06policy\x12\x1c\n" +
+	"\tdirectory\x18\x02 \x01(\tR\tdirectory\"~\n" +
+	"\x19SetDataServerStateRequest\x12\x18\n" +
+	"\aaddress\x18\x01 \x01(\tR\aaddress\x12\x14\n" +
+	"\x05state\x18\x02 \x01(\tR\x05state\x121\n" +
+	"\x14maintenance_duration\x18\x03 \x01(\x03R\x13maintenanceDuration\"\x1d\n" +
+	"\x1bListDataServerStatesRequest\"\xed\x01\n" +
+	"\x0fDataServerState\x12\x18\n" +
+	"\aaddress\x18\x01 \x01(\tR\aaddress\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x14\n" +
+	"\x05state\x18\x03 \x01(\tR\x05state\x12\x18\n" +
+	"\ahealthy\x18\x04 \x01(\bR\ahealthy\x12\x1d\n" +
+	"\n" +
+	"state_time\x18\x05 \x01(\x03R\tstateTime\x126\n" +
+	"\x17maintenance_expire_time\x18\x06 \x01(\x03R\x15maintenanceExpireTime\x12)\n" +
+	"\x10remaining_blocks\x18\a \x01(\x04R\x0fremainingBlocks\"V\n" +
+	"\x1cListDataServerStatesResponse\x126\n" +
+	"\aservers\x18\x01 \x03(\v2\x1c.dfs_project.DataServerStateR\aservers\"R\n" +
+	"\x14StartBalancerRequest\x12\x1c\n" +
+	"\tthreshold\x18\x01 \x01(\x01R\tthreshold\x12\x1c\n" +
+	"\tbandwidth\x18\x02 \x01(\x04R\tbandwidth\"\x15\n" +
+	"\x13StopBalancerRequest\"\x1a\n" +
+	"\x18GetBalancerStatusRequest\"\x95\x01\n" +
+	"\x11ServerUtilization\x12\x18\n" +
+	"\aaddress\x18\x01 \x01(\tR\aaddress\x12\x12\n" +
+	"\x04used\x18\x02 \x01(\x04R\x04used\x12\x1a\n" +
+	"\bcapacity\x18\x03 \x01(\x04R\bcapacity\x12 \n" +
+	"\vutilization\x18\x04 \x01(\x01R\vutilization\x12\x14\n" +
+	"\x05class\x18\x05 \x01(\tR\x05class\"\xd1\x03\n" +
+	"\x19GetBalancerStatusResponse\x12\x18\n" +
+	"\arunning\x18\x01 \x01(\bR\arunning\x12\x1c\n" +
+	"\tthreshold\x18\x02 \x01(\x01R\tthreshold\x12\x1c\n" +
+	"\tbandwidth\x18\x03 \x01(\x04R\tbandwidth\x12/\n" +
+	"\x13average_utilization\x18\x04 \x01(\x01R\x12averageUtilization\x128\n" +
+	"\aservers\x18\x05 \x03(\v2\x1e.dfs_project.ServerUtilizationR\aservers\x12#\n" +
+	"\rpending_moves\x18\x06 \x01(\x04R\fpendingMoves\x12!\n" +
+	"\fmoved_blocks\x18\a \x01(\x04R\vmovedBlocks\x12\x1f\n" +
+	"\vmoved_bytes\x18\b \x01(\x04R\n" +
+	"movedBytes\x12!\n" +
+	"\ffailed_moves\x18\t \x01(\x04R\vfailedMoves\x12\x1d\n" +
+	"\n" +
+	"start_time\x18\n" +
+	" \x01(\x03R\tstartTime\x12.\n" +
+	"\x13last_iteration_time\x18\v \x01(\x03R\x11lastIterationTime\x12\x18\n" +
+	"\amessage\x18\f \x01(\tR\amessage\"\x12\n" +
 	"\x10GetLeaderRequest\"\x81\x01\n" +
 	"\x11GetLeaderResponse\x122\n" +
 	"\x06leader\x18\x01 \x01(\v2\x1a.dfs_project.MetaServerMsgR\x06leader\x128\n" +
-	"\tfollowers\x18\x02 \x03(\v2\x1a.dfs_project.MetaServerMsgR\tfollowers\"\xb2\x01\n" +
+	"\tfollowers\x18\x02 \x03(\v2\x1a.dfs_project.MetaServerMsgR\tfollowers\"\xc6\x01\n" +
 	"\bLogEntry\x12\x1b\n" +
 	"\tlog_index\x18\x01 \x01(\x04R\blogIndex\x12\x1c\n" +
 	"\ttimestamp\x18\x02 \x01(\x03R\ttimestamp\x12;\n" +
 	"\toperation\x18\x03 \x01(\x0e2\x1d.dfs_project.WALOperationTypeR\toperation\x12\x12\n" +
 	"\x04data\x18\x04 \x01(\fR\x04data\x12\x1a\n" +
-	"\bchecksum\x18\x05 \x01(\tR\bchecksum\"T\n" +
+	"\bchecksum\x18\x05 \x01(\tR\bchecksum\x12\x12\n" +
+	"\x04term\x18\x06 \x01(\x04R\x04term\"\xe7\x01\n" +
 	"\x13CreateNodeOperation\x12\x12\n" +
 	"\x04path\x18\x01 \x01(\tR\x04path\x12)\n" +
-	"\x04type\x18\x02 \x01(\x0e2\x15.dfs_project.FileTypeR\x04type\"G\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x15.dfs_project.FileTypeR\x04type\x12\x19\n" +
+	"\binode_id\x18\x03 \x01(\x04R\ainodeId\x12\x14\n" +
+	"\x05mtime\x18\x04 \x01(\x03R\x05mtime\x12 \n" +
+	"\vreplication\x18\x05 \x01(\rR\vreplication\x12\x14\n" +
+	"\x05owner\x18\x06 \x01(\tR\x05owner\x12\x14\n" +
+	"\x05group\x18\a \x01(\tR\x05group\x12\x12\n" +
+	"\x04mode\x18\b \x01(\rR\x04mode\"G\n" +
 	"\x13DeleteNodeOperation\x12\x12\n" +
 	"\x04path\x18\x01 \x01(\tR\x04path\x12\x1c\n" +
-	"\trecursive\x18\x02 \x01(\bR\trecursive\"S\n" +
+	"\trecursive\x18\x02 \x01(\bR\trecursive\"i\n" +
+	"\x13RenameNodeOperation\x12\x19\n" +
+	"\bsrc_path\x18\x01 \x01(\tR\asrcPath\x12\x19\n" +
+	"\bdst_path\x18\x02 \x01(\tR\adstPath\x12\x1c\n" +
+	"\toverwrite\x18\x03 \x01(\bR\toverwrite\"S\n" +
 	"\x13UpdateNodeOperation\x12\x12\n" +
 	"\x04path\x18\x01 \x01(\tR\x04path\x12\x12\n" +
 	"\x04size\x18\x02 \x01(\x03R\x04size\x12\x14\n" +
-	"\x05mtime\x18\x03 \x01(\x03R\x05mtime\"\xae\x01\n" +
+	"\x05mtime\x18\x03 \x01(\x03R\x05mtime\"\xe7\x01\n" +
 	"\x16FinalizeWriteOperation\x12\x12\n" +
 	"\x04path\x18\x01 \x01(\tR\x04path\x12D\n" +
 	"\x0fblock_locations\x18\x02 \x03(\v2\x1b.dfs_project.BlockLocationsR\x0eblockLocations\x12\x14\n" +
 	"\x05inode\x18\x03 \x01(\x04R\x05inode\x12\x12\n" +
 	"\x04size\x18\x04 \x01(\x03R\x04size\x12\x10\n" +
-	"\x03md5\x18\x05 \x01(\tR\x03md5*<\n" +
+	"\x03md5\x18\x05 \x01(\tR\x03md5\x12!\n" +
+	"\flease_holder\x18\x06 \x01(\tR\vleaseHolder\x12\x14\n" +
+	"\x05mtime\x18\a \x01(\x03R\x05mtime\"o\n" +
+	"\x1cUpdateBlockLocationOperation\x12\x19\n" +
+	"\bblock_id\x18\x01 \x01(\x04R\ablockId\x12\x19\n" +
+	"\bold_addr\x18\x02 \x01(\tR\aoldAddr\x12\x19\n" +
+	"\bnew_addr\x18\x03 \x01(\tR\anewAddr\"\x92\x01\n" +
+	"\x18SetBlockMappingOperation\x12\x19\n" +
+	"\binode_id\x18\x01 \x01(\x04R\ainodeId\x12\x1f\n" +
+	"\vblock_index\x18\x02 \x01(\x04R\n" +
+	"blockIndex\x12:\n" +
+	"\n" +
+	"block_locs\x18\x03 \x01(\v2\x1b.dfs_project.BlockLocationsR\tblockLocs\"\\\n" +
+	"\x1eTruncateBlockMappingsOperation\x12\x19\n" +
+	"\binode_id\x18\x01 \x01(\x04R\ainodeId\x12\x1f\n" +
+	"\vblock_count\x18\x02 \x01(\x04R\n" +
+	"blockCount\"\x91\x02\n" +
+	"\x13GrantLeaseOperation\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x16\n" +
+	"\x06holder\x18\x02 \x01(\tR\x06holder\x12\x14\n" +
+	"\x05inode\x18\x03 \x01(\x04R\x05inode\x12!\n" +
+	"\facquire_time\x18\x04 \x01(\x03R\vacquireTime\x12\x1f\n" +
+	"\vtarget_size\x18\x05 \x01(\x03R\n" +
+	"targetSize\x12\x1b\n" +
+	"\tprev_size\x18\x06 \x01(\x03R\bprevSize\x12\x19\n" +
+	"\bprev_md5\x18\a \x01(\tR\aprevMd5\x12<\n" +
+	"\vprev_blocks\x18\b \x03(\v2\x1b.dfs_project.BlockLocationsR\n" +
+	"prevBlocks\"C\n" +
+	"\x15ReleaseLeaseOperation\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x16\n" +
+	"\x06holder\x18\x02 \x01(\tR\x06holder\"c\n" +
+	"\x11SetQuotaOperation\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x1b\n" +
+	"\tmax_bytes\x18\x02 \x01(\x04R\bmaxBytes\x12\x1d\n" +
+	"\n" +
+	"max_inodes\x18\x03 \x01(\x04R\tmaxInodes\"O\n" +
+	"\x17SetReplicationOperation\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12 \n" +
+	"\vreplication\x18\x02 \x01(\rR\vreplication\"M\n" +
+	"\x1fSetErasureCodingPolicyOperation\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x16\n" +
+	"\x06policy\x18\x02 \x01(\tR\x06policy\"\xc5\x01\n" +
+	"\x15ConvertBlockOperation\x12\x19\n" +
+	"\binode_id\x18\x01 \x01(\x04R\ainodeId\x12\x1f\n" +
+	"\vblock_index\x18\x02 \x01(\x04R\n" +
+	"blockIndex\x12 \n" +
+	"\fold_block_id\x18\x03 \x01(\x04R\n" +
+	"oldBlockId\x121\n" +
+	"\x05group\x18\x04 \x01(\v2\x1b.dfs_project.BlockLocationsR\x05group\x12\x1b\n" +
+	"\tfile_size\x18\x05 \x01(\x03R\bfileSize\"\xa4\x01\n" +
+	"\x1bSetDataServerStateOperation\x12\x18\n" +
+	"\aaddress\x18\x01 \x01(\tR\aaddress\x12\x14\n" +
+	"\x05state\x18\x02 \x01(\tR\x05state\x12\x1d\n" +
+	"\n" +
+	"state_time\x18\x03 \x01(\x03R\tstateTime\x126\n" +
+	"\x17maintenance_expire_time\x18\x04 \x01(\x03R\x15maintenanceExpireTime\"\xc9\x01\n" +
+	"\x16SetPermissionOperation\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x14\n" +
+	"\x05owner\x18\x02 \x01(\tR\x05owner\x12\x14\n" +
+	"\x05group\x18\x03 \x01(\tR\x05group\x12\x12\n" +
+	"\x04mode\x18\x04 \x01(\rR\x04mode\x12\x19\n" +
+	"\bset_mode\x18\x05 \x01(\bR\asetMode\x12'\n" +
+	"\x03acl\x18\x06 \x03(\v2\x15.dfs_project.AclEntryR\x03acl\x12\x17\n" +
+	"\aset_acl\x18\a \x01(\bR\x06setAcl\"`\n" +
+	"\x17CreateSnapshotOperation\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\x03R\tcreatedAt\"-\n" +
+	"\x17DeleteSnapshotOperation\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"n\n" +
+	"\x15RequestWALSyncRequest\x12\x17\n" +
+	"\anode_id\x18\x01 \x01(\tR\x06nodeId\x12$\n" +
+	"\x0elast_log_index\x18\x02 \x01(\x04R\flastLogIndex\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"\x95\x01\n" +
+	"\x12RequestVoteRequest\x12\x12\n" +
+	"\x04term\x18\x01 \x01(\x04R\x04term\x12!\n" +
+	"\fcandidate_id\x18\x02 \x01(\tR\vcandidateId\x12$\n" +
+	"\x0elast_log_index\x18\x03 \x01(\x04R\flastLogIndex\x12\"\n" +
+	"\rlast_log_term\x18\x04 \x01(\x04R\vlastLogTerm\"L\n" +
+	"\x13RequestVoteResponse\x12\x12\n" +
+	"\x04term\x18\x01 \x01(\x04R\x04term\x12!\n" +
+	"\fvote_granted\x18\x02 \x01(\bR\vvoteGranted\"\x88\x02\n" +
+	"\x14AppendEntriesRequest\x12\x12\n" +
+	"\x04term\x18\x01 \x01(\x04R\x04term\x12\x1b\n" +
+	"\tleader_id\x18\x02 \x01(\tR\bleaderId\x12\x1f\n" +
+	"\vleader_addr\x18\x03 \x01(\tR\n" +
+	"leaderAddr\x12$\n" +
+	"\x0eprev_log_index\x18\x04 \x01(\x04R\fprevLogIndex\x12\"\n" +
+	"\rprev_log_term\x18\x05 \x01(\x04R\vprevLogTerm\x12/\n" +
+	"\aentries\x18\x06 \x03(\v2\x15.dfs_project.LogEntryR\aentries\x12#\n" +
+	"\rleader_commit\x18\a \x01(\x04R\fleaderCommit\"k\n" +
+	"\x15AppendEntriesResponse\x12\x12\n" +
+	"\x04term\x18\x01 \x01(\x04R\x04term\x12\x18\n" +
+	"\asuccess\x18\x02 \x01(\bR\asuccess\x12$\n" +
+	"\x0elast_log_index\x18\x03 \x01(\x04R\flastLogIndex\"\xdc\x01\n" +
+	"\x16InstallSnapshotRequest\x12\x12\n" +
+	"\x04term\x18\x01 \x01(\x04R\x04term\x12\x1b\n" +
+	"\tleader_id\x18\x02 \x01(\tR\bleaderId\x12\x1f\n" +
+	"\vleader_addr\x18\x03 \x01(\tR\n" +
+	"leaderAddr\x12.\n" +
+	"\x13last_included_index\x18\x04 \x01(\x04R\x11lastIncludedIndex\x12,\n" +
+	"\x12last_included_term\x18\x05 \x01(\x04R\x10lastIncludedTerm\x12\x12\n" +
+	"\x04data\x18\x06 \x01(\fR\x04data\"-\n" +
+	"\x17InstallSnapshotResponse\x12\x12\n" +
+	"\x04term\x18\x01 \x01(\x04R\x04term*<\n" +
 	"\bFileType\x12\v\n" +
 	"\aUnknown\x10\x00\x12\n" +
 	"\n" +
 	"\x06Volume\x10\x01\x12\b\n" +
 	"\x04File\x10\x02\x12\r\n" +
-	"\tDirectory\x10\x03*Y\n" +
+	"\tDirectory\x10\x03*\x8a\x03\n" +
 	"\x10WALOperationType\x12\x0f\n" +
 	"\vCREATE_NODE\x10\x00\x12\x0f\n" +
 	"\vDELETE_NODE\x10\x01\x12\x0f\n" +
 	"\vUPDATE_NODE\x10\x02\x12\x12\n" +
-	"\x0eFINALIZE_WRITE\x10\x032\xa3\a\n" +
+	"\x0eFINALIZE_WRITE\x10\x03\x12\x19\n" +
+	"\x15UPDATE_BLOCK_LOCATION\x10\x04\x12\x15\n" +
+	"\x11SET_BLOCK_MAPPING\x10\x05\x12\x0f\n" +
+	"\vRENAME_NODE\x10\x06\x12\x1b\n" +
+	"\x17TRUNCATE_BLOCK_MAPPINGS\x10\a\x12\x0f\n" +
+	"\vGRANT_LEASE\x10\b\x12\x11\n" +
+	"\rRELEASE_LEASE\x10\t\x12\t\n" +
+	"\x05NO_OP\x10\n" +
+	"\x12\r\n" +
+	"\tSET_QUOTA\x10\v\x12\x13\n" +
+	"\x0fCREATE_SNAPSHOT\x10\f\x12\x13\n" +
+	"\x0fDELETE_SNAPSHOT\x10\r\x12\x13\n" +
+	"\x0fSET_REPLICATION\x10\x0e\x12\x11\n" +
+	"\rSET_EC_POLICY\x10\x0f\x12\x11\n" +
+	"\rCONVERT_BLOCK\x10\x10\x12\x18\n" +
+	"\x14SET_DATASERVER_STATE\x10\x11\x12\x12\n" +
+	"\x0eSET_PERMISSION\x10\x122\xd4\x19\n" +
 	"\x11MetaServerService\x12I\n" +
 	"\n" +
 	"CreateNode\x12\x1e.dfs_project.CreateNodeRequest\x1a\x1b.dfs_project.SimpleResponse\x12P\n" +
 	"\vGetNodeInfo\x12\x1f.dfs_project.GetNodeInfoRequest\x1a .dfs_project.GetNodeInfoResponse\x12V\n" +
-	"\rListDirectory\x12!.dfs_project.ListDirectoryRequest\x1a\".dfs_project.ListDirectoryResponse\x12I\n" +
+	"\rListDirectory\x12!.dfs_project.ListDirectoryRequest\x1a\".dfs_project.ListDirectoryResponse\x12^\n" +
+	"\x13ListDirectoryStream\x12!.dfs_project.ListDirectoryRequest\x1a\".dfs_project.ListDirectoryResponse0\x01\x12I\n" +
+	"\n" +
+	"DeleteNode\x12\x1e.dfs_project.DeleteNodeRequest\x1a\x1b.dfs_project.SimpleResponse\x12P\n" +
+	"\vRestoreNode\x12\x1f.dfs_project.RestoreNodeRequest\x1a .dfs_project.RestoreNodeResponse\x12A\n" +
+	"\x06Rename\x12\x1a.dfs_project.RenameRequest\x1a\x1b.dfs_project.SimpleResponse\x12b\n" +
+	"\x11GetBlockLocations\x12%.dfs_project.GetBlockLocationsRequest\x1a&.dfs_project.GetBlockLocationsResponse\x12V\n" +
+	"\rGetBlockRange\x12!.dfs_project.GetBlockRangeRequest\x1a\".dfs_project.GetBlockRangeResponse\x12O\n" +
+	"\rFinalizeWrite\x12!.dfs_project.FinalizeWriteRequest\x1a\x1b.dfs_project.SimpleResponse\x12I\n" +
 	"\n" +
-	"DeleteNode\x12\x1e.dfs_project.DeleteNodeRequest\x1a\x1b.dfs_project.SimpleResponse\x12b\n" +
-	"\x11GetBlockLocations\x12%.dfs_project.GetBlockLocationsRequest\x1a&.dfs_project.GetBlockLocationsResponse\x12O\n" +
-	"\rFinalizeWrite\x12!.dfs_project.FinalizeWriteRequest\x1a\x1b.dfs_project.SimpleResponse\x12Y\n" +
+	"RenewLease\x12\x1e.dfs_project.RenewLeaseRequest\x1a\x1b.dfs_project.SimpleResponse\x12Y\n" +
 	"\x0eGetClusterInfo\x12\".dfs_project.GetClusterInfoRequest\x1a#.dfs_project.GetClusterInfoResponse\x12e\n" +
-	"\x12GetReplicationInfo\x12&.dfs_project.GetReplicationInfoRequest\x1a'.dfs_project.GetReplicationInfoResponse\x12J\n" +
+	"\x12GetReplicationInfo\x12&.dfs_project.GetReplicationInfoRequest\x1a'.dfs_project.GetReplicationInfoResponse\x12=\n" +
+	"\x04Fsck\x12\x18.dfs_project.FsckRequest\x1a\x19.dfs_project.FsckResponse0\x01\x12Q\n" +
+	"\x0eSetReplication\x12\".dfs_project.SetReplicationRequest\x1a\x1b.dfs_project.SimpleResponse\x12?\n" +
+	"\x05Chmod\x12\x19.dfs_project.ChmodRequest\x1a\x1b.dfs_project.SimpleResponse\x12?\n" +
+	"\x05Chown\x12\x19.dfs_project.ChownRequest\x1a\x1b.dfs_project.SimpleResponse\x12A\n" +
+	"\x06SetAcl\x12\x1a.dfs_project.SetAclRequest\x1a\x1b.dfs_project.SimpleResponse\x12\\\n" +
+	"\x0fGetOrphanReport\x12#.dfs_project.GetOrphanReportRequest\x1a$.dfs_project.GetOrphanReportResponse\x12E\n" +
+	"\bSetQuota\x12\x1c.dfs_project.SetQuotaRequest\x1a\x1b.dfs_project.SimpleResponse\x12G\n" +
+	"\bGetQuota\x12\x1c.dfs_project.GetQuotaRequest\x1a\x1d.dfs_project.GetQuotaResponse\x12Y\n" +
+	"\x0eGetUsageReport\x12\".dfs_project.GetUsageReportRequest\x1a#.dfs_project.GetUsageReportResponse\x12Q\n" +
+	"\x0eCreateSnapshot\x12\".dfs_project.CreateSnapshotRequest\x1a\x1b.dfs_project.SimpleResponse\x12Q\n" +
+	"\x0eDeleteSnapshot\x12\".dfs_project.DeleteSnapshotRequest\x1a\x1b.dfs_project.SimpleResponse\x12V\n" +
+	"\rListSnapshots\x12!.dfs_project.ListSnapshotsRequest\x1a\".dfs_project.ListSnapshotsResponse\x12a\n" +
+	"\x16SetErasureCodingPolicy\x12*.dfs_project.SetErasureCodingPolicyRequest\x1a\x1b.dfs_project.SimpleResponse\x12q\n" +
+	"\x16GetErasureCodingPolicy\x12*.dfs_project.GetErasureCodingPolicyRequest\x1a+.dfs_project.GetErasureCodingPolicyResponse\x12Y\n" +
+	"\x12SetDataServerState\x12&.dfs_project.SetDataServerStateRequest\x1a\x1b.dfs_project.SimpleResponse\x12k\n" +
+	"\x14ListDataServerStates\x12(.dfs_project.ListDataServerStatesRequest\x1a).dfs_project.ListDataServerStatesResponse\x12O\n" +
+	"\rStartBalancer\x12!.dfs_project.StartBalancerRequest\x1a\x1b.dfs_project.SimpleResponse\x12M\n" +
+	"\fStopBalancer\x12 .dfs_project.StopBalancerRequest\x1a\x1b.dfs_project.SimpleResponse\x12b\n" +
+	"\x11GetBalancerStatus\x12%.dfs_project.GetBalancerStatusRequest\x1a&.dfs_project.GetBalancerStatusResponse\x12J\n" +
 	"\tHeartbeat\x12\x1d.dfs_project.HeartbeatRequest\x1a\x1e.dfs_project.HeartbeatResponse\x12?\n" +
-	"\aSyncWAL\x12\x15.dfs_project.LogEntry\x1a\x1b.dfs_project.SimpleResponse(\x01\x12J\n" +
+	"\aSyncWAL\x12\x15.dfs_project.LogEntry\x1a\x1b.dfs_project.SimpleResponse(\x01\x12P\n" +
+	"\vRequestVote\x12\x1f.dfs_project.RequestVoteRequest\x1a .dfs_project.RequestVoteResponse\x12V\n" +
+	"\rAppendEntries\x12!.dfs_project.AppendEntriesRequest\x1a\".dfs_project.AppendEntriesResponse\x12^\n" +
+	"\x0fInstallSnapshot\x12#.dfs_project.InstallSnapshotRequest\x1a$.dfs_project.InstallSnapshotResponse(\x01\x12M\n" +
+	"\x0eRequestWALSync\x12\".dfs_project.RequestWALSyncRequest\x1a\x15.dfs_project.LogEntry0\x01\x12J\n" +
 	"\tGetLeader\x12\x1d.dfs_project.GetLeaderRequest\x1a\x1e.dfs_project.GetLeaderResponseB\x06Z\x04./pbb\x06proto3"
 
 var (
@@ -2318,94 +7090,235 @@ func file_metaServer_proto_rawDescGZIP() []byte {
 }
 
 var file_metaServer_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
-var file_metaServer_proto_msgTypes = make([]protoimpl.MessageInfo, 33)
+var file_metaServer_proto_msgTypes = make([]protoimpl.MessageInfo, 97)
 var file_metaServer_proto_goTypes = []any{
-	(FileType)(0),                      // 0: dfs_project.FileType
-	(WALOperationType)(0),              // 1: dfs_project.WALOperationType
-	(Command_Action)(0),                // 2: dfs_project.Command.Action
-	(*ReplicaData)(nil),                // 3: dfs_project.ReplicaData
-	(*StatInfo)(nil),                   // 4: dfs_project.StatInfo
-	(*MetaServerMsg)(nil),              // 5: dfs_project.MetaServerMsg
-	(*DataServerMsg)(nil),              // 6: dfs_project.DataServerMsg
-	(*ClusterInfo)(nil),                // 7: dfs_project.ClusterInfo
-	(*NodeInfo)(nil),                   // 8: dfs_project.NodeInfo
-	(*BlockLocations)(nil),             // 9: dfs_project.BlockLocations
-	(*SimpleResponse)(nil),             // 10: dfs_project.SimpleResponse
-	(*CreateNodeRequest)(nil),          // 11: dfs_project.CreateNodeRequest
-	(*GetNodeInfoRequest)(nil),         // 12: dfs_project.GetNodeInfoRequest
-	(*GetNodeInfoResponse)(nil),        // 13: dfs_project.GetNodeInfoResponse
-	(*ListDirectoryRequest)(nil),       // 14: dfs_project.ListDirectoryRequest
-	(*ListDirectoryResponse)(nil),      // 15: dfs_project.ListDirectoryResponse
-	(*DeleteNodeRequest)(nil),          // 16: dfs_project.DeleteNodeRequest
-	(*GetBlockLocationsRequest)(nil),   // 17: dfs_project.GetBlockLocationsRequest
-	(*GetBlockLocationsResponse)(nil),  // 18: dfs_project.GetBlockLocationsResponse
-	(*FinalizeWriteRequest)(nil),       // 19: dfs_project.FinalizeWriteRequest
-	(*GetClusterInfoRequest)(nil),      // 20: dfs_project.GetClusterInfoRequest
-	(*GetClusterInfoResponse)(nil),     // 21: dfs_project.GetClusterInfoResponse
-	(*HeartbeatRequest)(nil),           // 22: dfs_project.HeartbeatRequest
-	(*Command)(nil),                    // 23: dfs_project.Command
-	(*HeartbeatResponse)(nil),          // 24: dfs_project.HeartbeatResponse
-	(*GetReplicationInfoRequest)(nil),  // 25: dfs_project.GetReplicationInfoRequest
-	(*BlockReplicationInfo)(nil),       // 26: dfs_project.BlockReplicationInfo
-	(*ReplicationStatus)(nil),          // 27: dfs_project.ReplicationStatus
-	(*GetReplicationInfoResponse)(nil), // 28: dfs_project.GetReplicationInfoResponse
-	(*GetLeaderRequest)(nil),           // 29: dfs_project.GetLeaderRequest
-	(*GetLeaderResponse)(nil),          // 30: dfs_project.GetLeaderResponse
-	(*LogEntry)(nil),                   // 31: dfs_project.LogEntry
-	(*CreateNodeOperation)(nil),        // 32: dfs_project.CreateNodeOperation
-	(*DeleteNodeOperation)(nil),        // 33: dfs_project.DeleteNodeOperation
-	(*UpdateNodeOperation)(nil),        // 34: dfs_project.UpdateNodeOperation
-	(*FinalizeWriteOperation)(nil),     // 35: dfs_project.FinalizeWriteOperation
+	(FileType)(0),                           // 0: dfs_project.FileType
+	(WALOperationType)(0),                   // 1: dfs_project.WALOperationType
+	(Command_Action)(0),                     // 2: dfs_project.Command.Action
+	(*ReplicaData)(nil),                     // 3: dfs_project.ReplicaData
+	(*StatInfo)(nil),                        // 4: dfs_project.StatInfo
+	(*MetaServerMsg)(nil),                   // 5: dfs_project.MetaServerMsg
+	(*DataServerMsg)(nil),                   // 6: dfs_project.DataServerMsg
+	(*ClusterInfo)(nil),                     // 7: dfs_project.ClusterInfo
+	(*NodeInfo)(nil),                        // 8: dfs_project.NodeInfo
+	(*AclEntry)(nil),                        // 9: dfs_project.AclEntry
+	(*BlockLocations)(nil),                  // 10: dfs_project.BlockLocations
+	(*SimpleResponse)(nil),                  // 11: dfs_project.SimpleResponse
+	(*CreateNodeRequest)(nil),               // 12: dfs_project.CreateNodeRequest
+	(*GetNodeInfoRequest)(nil),              // 13: dfs_project.GetNodeInfoRequest
+	(*GetNodeInfoResponse)(nil),             // 14: dfs_project.GetNodeInfoResponse
+	(*ListDirectoryRequest)(nil),            // 15: dfs_project.ListDirectoryRequest
+	(*ListDirectoryResponse)(nil),           // 16: dfs_project.ListDirectoryResponse
+	(*DeleteNodeRequest)(nil),               // 17: dfs_project.DeleteNodeRequest
+	(*RestoreNodeRequest)(nil),              // 18: dfs_project.RestoreNodeRequest
+	(*RestoreNodeResponse)(nil),             // 19: dfs_project.RestoreNodeResponse
+	(*RenameRequest)(nil),                   // 20: dfs_project.RenameRequest
+	(*GetBlockLocationsRequest)(nil),        // 21: dfs_project.GetBlockLocationsRequest
+	(*GetBlockLocationsResponse)(nil),       // 22: dfs_project.GetBlockLocationsResponse
+	(*GetBlockRangeRequest)(nil),            // 23: dfs_project.GetBlockRangeRequest
+	(*BlockRange)(nil),                      // 24: dfs_project.BlockRange
+	(*GetBlockRangeResponse)(nil),           // 25: dfs_project.GetBlockRangeResponse
+	(*FinalizeWriteRequest)(nil),            // 26: dfs_project.FinalizeWriteRequest
+	(*RenewLeaseRequest)(nil),               // 27: dfs_project.RenewLeaseRequest
+	(*GetClusterInfoRequest)(nil),           // 28: dfs_project.GetClusterInfoRequest
+	(*GetClusterInfoResponse)(nil),          // 29: dfs_project.GetClusterInfoResponse
+	(*HeartbeatRequest)(nil),                // 30: dfs_project.HeartbeatRequest
+	(*ScrubStats)(nil),                      // 31: dfs_project.ScrubStats
+	(*Command)(nil),                         // 32: dfs_project.Command
+	(*HeartbeatResponse)(nil),               // 33: dfs_project.HeartbeatResponse
+	(*GetReplicationInfoRequest)(nil),       // 34: dfs_project.GetReplicationInfoRequest
+	(*BlockReplicationInfo)(nil),            // 35: dfs_project.BlockReplicationInfo
+	(*ReplicationStatus)(nil),               // 36: dfs_project.ReplicationStatus
+	(*FsckRequest)(nil),                     // 37: dfs_project.FsckRequest
+	(*FsckBlock)(nil),                       // 38: dfs_project.FsckBlock
+	(*FsckSummary)(nil),                     // 39: dfs_project.FsckSummary
+	(*FsckResponse)(nil),                    // 40: dfs_project.FsckResponse
+	(*GetOrphanReportRequest)(nil),          // 41: dfs_project.GetOrphanReportRequest
+	(*OrphanBlock)(nil),                     // 42: dfs_project.OrphanBlock
+	(*GetOrphanReportResponse)(nil),         // 43: dfs_project.GetOrphanReportResponse
+	(*GetReplicationInfoResponse)(nil),      // 44: dfs_project.GetReplicationInfoResponse
+	(*SetReplicationRequest)(nil),           // 45: dfs_project.SetReplicationRequest
+	(*ChmodRequest)(nil),                    // 46: dfs_project.ChmodRequest
+	(*ChownRequest)(nil),                    // 47: dfs_project.ChownRequest
+	(*SetAclRequest)(nil),                   // 48: dfs_project.SetAclRequest
+	(*DirectoryUsage)(nil),                  // 49: dfs_project.DirectoryUsage
+	(*SetQuotaRequest)(nil),                 // 50: dfs_project.SetQuotaRequest
+	(*GetQuotaRequest)(nil),                 // 51: dfs_project.GetQuotaRequest
+	(*GetQuotaResponse)(nil),                // 52: dfs_project.GetQuotaResponse
+	(*GetUsageReportRequest)(nil),           // 53: dfs_project.GetUsageReportRequest
+	(*GetUsageReportResponse)(nil),          // 54: dfs_project.GetUsageReportResponse
+	(*SnapshotInfo)(nil),                    // 55: dfs_project.SnapshotInfo
+	(*CreateSnapshotRequest)(nil),           // 56: dfs_project.CreateSnapshotRequest
+	(*DeleteSnapshotRequest)(nil),           // 57: dfs_project.DeleteSnapshotRequest
+	(*ListSnapshotsRequest)(nil),            // 58: dfs_project.ListSnapshotsRequest
+	(*ListSnapshotsResponse)(nil),           // 59: dfs_project.ListSnapshotsResponse
+	(*SetErasureCodingPolicyRequest)(nil),   // 60: dfs_project.SetErasureCodingPolicyRequest
+	(*GetErasureCodingPolicyRequest)(nil),   // 61: dfs_project.GetErasureCodingPolicyRequest
+	(*GetErasureCodingPolicyResponse)(nil),  // 62: dfs_project.GetErasureCodingPolicyResponse
+	(*SetDataServerStateRequest)(nil),       // 63: dfs_project.SetDataServerStateRequest
+	(*ListDataServerStatesRequest)(nil),     // 64: dfs_project.ListDataServerStatesRequest
+	(*DataServerState)(nil),                 // 65: dfs_project.DataServerState
+	(*ListDataServerStatesResponse)(nil),    // 66: dfs_project.ListDataServerStatesResponse
+	(*StartBalancerRequest)(nil),            // 67: dfs_project.StartBalancerRequest
+	(*StopBalancerRequest)(nil),             // 68: dfs_project.StopBalancerRequest
+	(*GetBalancerStatusRequest)(nil),        // 69: dfs_project.GetBalancerStatusRequest
+	(*ServerUtilization)(nil),               // 70: dfs_project.ServerUtilization
+	(*GetBalancerStatusResponse)(nil),       // 71: dfs_project.GetBalancerStatusResponse
+	(*GetLeaderRequest)(nil),                // 72: dfs_project.GetLeaderRequest
+	(*GetLeaderResponse)(nil),               // 73: dfs_project.GetLeaderResponse
+	(*LogEntry)(nil),                        // 74: dfs_project.LogEntry
+	(*CreateNodeOperation)(nil),             // 75: dfs_project.CreateNodeOperation
+	(*DeleteNodeOperation)(nil),             // 76: dfs_project.DeleteNodeOperation
+	(*RenameNodeOperation)(nil),             // 77: dfs_project.RenameNodeOperation
+	(*UpdateNodeOperation)(nil),             // 78: dfs_project.UpdateNodeOperation
+	(*FinalizeWriteOperation)(nil),          // 79: dfs_project.FinalizeWriteOperation
+	(*UpdateBlockLocationOperation)(nil),    // 80: dfs_project.UpdateBlockLocationOperation
+	(*SetBlockMappingOperation)(nil),        // 81: dfs_project.SetBlockMappingOperation
+	(*TruncateBlockMappingsOperation)(nil),  // 82: dfs_project.TruncateBlockMappingsOperation
+	(*GrantLeaseOperation)(nil),             // 83: dfs_project.GrantLeaseOperation
+	(*ReleaseLeaseOperation)(nil),           // 84: dfs_project.ReleaseLeaseOperation
+	(*SetQuotaOperation)(nil),               // 85: dfs_project.SetQuotaOperation
+	(*SetReplicationOperation)(nil),         // 86: dfs_project.SetReplicationOperation
+	(*SetErasureCodingPolicyOperation)(nil), // 87: dfs_project.SetErasureCodingPolicyOperation
+	(*ConvertBlockOperation)(nil),           // 88: dfs_project.ConvertBlockOperation
+	(*SetDataServerStateOperation)(nil),     // 89: dfs_project.SetDataServerStateOperation
+	(*SetPermissionOperation)(nil),          // 90: dfs_project.SetPermissionOperation
+	(*CreateSnapshotOperation)(nil),         // 91: dfs_project.CreateSnapshotOperation
+	(*DeleteSnapshotOperation)(nil),         // 92: dfs_project.DeleteSnapshotOperation
+	(*RequestWALSyncRequest)(nil),           // 93: dfs_project.RequestWALSyncRequest
+	(*RequestVoteRequest)(nil),              // 94: dfs_project.RequestVoteRequest
+	(*RequestVoteResponse)(nil),             // 95: dfs_project.RequestVoteResponse
+	(*AppendEntriesRequest)(nil),            // 96: dfs_project.AppendEntriesRequest
+	(*AppendEntriesResponse)(nil),           // 97: dfs_project.AppendEntriesResponse
+	(*InstallSnapshotRequest)(nil),          // 98: dfs_project.InstallSnapshotRequest
+	(*InstallSnapshotResponse)(nil),         // 99: dfs_project.InstallSnapshotResponse
 }
 var file_metaServer_proto_depIdxs = []int32{
 	0,  // 0: dfs_project.StatInfo.type:type_name -> dfs_project.FileType
 	3,  // 1: dfs_project.StatInfo.replicaData:type_name -> dfs_project.ReplicaData
-	5,  // 2: dfs_project.ClusterInfo.masterMetaServer:type_name -> dfs_project.MetaServerMsg
-	5,  // 3: dfs_project.ClusterInfo.slaveMetaServer:type_name -> dfs_project.MetaServerMsg
-	6,  // 4: dfs_project.ClusterInfo.dataServer:type_name -> dfs_project.DataServerMsg
-	0,  // 5: dfs_project.NodeInfo.type:type_name -> dfs_project.FileType
-	3,  // 6: dfs_project.NodeInfo.replicaData:type_name -> dfs_project.ReplicaData
-	0,  // 7: dfs_project.CreateNodeRequest.type:type_name -> dfs_project.FileType
-	4,  // 8: dfs_project.GetNodeInfoResponse.statInfo:type_name -> dfs_project.StatInfo
-	4,  // 9: dfs_project.ListDirectoryResponse.nodes:type_name -> dfs_project.StatInfo
-	9,  // 10: dfs_project.GetBlockLocationsResponse.block_locations:type_name -> dfs_project.BlockLocations
-	7,  // 11: dfs_project.GetClusterInfoResponse.clusterInfo:type_name -> dfs_project.ClusterInfo
-	2,  // 12: dfs_project.Command.action:type_name -> dfs_project.Command.Action
-	23, // 13: dfs_project.HeartbeatResponse.commands:type_name -> dfs_project.Command
-	26, // 14: dfs_project.ReplicationStatus.blocks:type_name -> dfs_project.BlockReplicationInfo
-	27, // 15: dfs_project.GetReplicationInfoResponse.files:type_name -> dfs_project.ReplicationStatus
-	5,  // 16: dfs_project.GetLeaderResponse.leader:type_name -> dfs_project.MetaServerMsg
-	5,  // 17: dfs_project.GetLeaderResponse.followers:type_name -> dfs_project.MetaServerMsg
-	1,  // 18: dfs_project.LogEntry.operation:type_name -> dfs_project.WALOperationType
-	0,  // 19: dfs_project.CreateNodeOperation.type:type_name -> dfs_project.FileType
-	9,  // 20: dfs_project.FinalizeWriteOperation.block_locations:type_name -> dfs_project.BlockLocations
-	11, // 21: dfs_project.MetaServerService.CreateNode:input_type -> dfs_project.CreateNodeRequest
-	12, // 22: dfs_project.MetaServerService.GetNodeInfo:input_type -> dfs_project.GetNodeInfoRequest
-	14, // 23: dfs_project.MetaServerService.ListDirectory:input_type -> dfs_project.ListDirectoryRequest
-	16, // 24: dfs_project.MetaServerService.DeleteNode:input_type -> dfs_project.DeleteNodeRequest
-	17, // 25: dfs_project.MetaServerService.GetBlockLocations:input_type -> dfs_project.GetBlockLocationsRequest
-	19, // 26: dfs_project.MetaServerService.FinalizeWrite:input_type -> dfs_project.FinalizeWriteRequest
-	20, // 27: dfs_project.MetaServerService.GetClusterInfo:input_type -> dfs_project.GetClusterInfoRequest
-	25, // 28: dfs_project.MetaServerService.GetReplicationInfo:input_type -> dfs_project.GetReplicationInfoRequest
-	22, // 29: dfs_project.MetaServerService.Heartbeat:input_type -> dfs_project.HeartbeatRequest
-	31, // 30: dfs_project.MetaServerService.SyncWAL:input_type -> dfs_project.LogEntry
-	29, // 31: dfs_project.MetaServerService.GetLeader:input_type -> dfs_project.GetLeaderRequest
-	10, // 32: dfs_project.MetaServerService.CreateNode:output_type -> dfs_project.SimpleResponse
-	13, // 33: dfs_project.MetaServerService.GetNodeInfo:output_type -> dfs_project.GetNodeInfoResponse
-	15, // 34: dfs_project.MetaServerService.ListDirectory:output_type -> dfs_project.ListDirectoryResponse
-	10, // 35: dfs_project.MetaServerService.DeleteNode:output_type -> dfs_project.SimpleResponse
-	18, // 36: dfs_project.MetaServerService.GetBlockLocations:output_type -> dfs_project.GetBlockLocationsResponse
-	10, // 37: dfs_project.MetaServerService.FinalizeWrite:output_type -> dfs_project.SimpleResponse
-	21, // 38: dfs_project.MetaServerService.GetClusterInfo:output_type -> dfs_project.GetClusterInfoResponse
-	28, // 39: dfs_project.MetaServerService.GetReplicationInfo:output_type -> dfs_project.GetReplicationInfoResponse
-	24, // 40: dfs_project.MetaServerService.Heartbeat:output_type -> dfs_project.HeartbeatResponse
-	10, // 41: dfs_project.MetaServerService.SyncWAL:output_type -> dfs_project.SimpleResponse
-	30, // 42: dfs_project.MetaServerService.GetLeader:output_type -> dfs_project.GetLeaderResponse
-	32, // [32:43] is the sub-list for method output_type
-	21, // [21:32] is the sub-list for method input_type
-	21, // [21:21] is the sub-list for extension type_name
-	21, // [21:21] is the sub-list for extension extendee
-	0,  // [0:21] is the sub-list for field type_name
+	9,  // 2: dfs_project.StatInfo.acl:type_name -> dfs_project.AclEntry
+	5,  // 3: dfs_project.ClusterInfo.masterMetaServer:type_name -> dfs_project.MetaServerMsg
+	5,  // 4: dfs_project.ClusterInfo.slaveMetaServer:type_name -> dfs_project.MetaServerMsg
+	6,  // 5: dfs_project.ClusterInfo.dataServer:type_name -> dfs_project.DataServerMsg
+	0,  // 6: dfs_project.NodeInfo.type:type_name -> dfs_project.FileType
+	3,  // 7: dfs_project.NodeInfo.replicaData:type_name -> dfs_project.ReplicaData
+	9,  // 8: dfs_project.NodeInfo.acl:type_name -> dfs_project.AclEntry
+	0,  // 9: dfs_project.CreateNodeRequest.type:type_name -> dfs_project.FileType
+	4,  // 10: dfs_project.GetNodeInfoResponse.statInfo:type_name -> dfs_project.StatInfo
+	4,  // 11: dfs_project.ListDirectoryResponse.nodes:type_name -> dfs_project.StatInfo
+	10, // 12: dfs_project.GetBlockLocationsResponse.block_locations:type_name -> dfs_project.BlockLocations
+	10, // 13: dfs_project.GetBlockLocationsResponse.prev_tail:type_name -> dfs_project.BlockLocations
+	10, // 14: dfs_project.BlockRange.block:type_name -> dfs_project.BlockLocations
+	24, // 15: dfs_project.GetBlockRangeResponse.ranges:type_name -> dfs_project.BlockRange
+	7,  // 16: dfs_project.GetClusterInfoResponse.clusterInfo:type_name -> dfs_project.ClusterInfo
+	31, // 17: dfs_project.HeartbeatRequest.scrub_stats:type_name -> dfs_project.ScrubStats
+	2,  // 18: dfs_project.Command.action:type_name -> dfs_project.Command.Action
+	10, // 19: dfs_project.Command.group:type_name -> dfs_project.BlockLocations
+	32, // 20: dfs_project.HeartbeatResponse.commands:type_name -> dfs_project.Command
+	35, // 21: dfs_project.ReplicationStatus.blocks:type_name -> dfs_project.BlockReplicationInfo
+	38, // 22: dfs_project.FsckResponse.blocks:type_name -> dfs_project.FsckBlock
+	39, // 23: dfs_project.FsckResponse.summary:type_name -> dfs_project.FsckSummary
+	42, // 24: dfs_project.GetOrphanReportResponse.orphans:type_name -> dfs_project.OrphanBlock
+	36, // 25: dfs_project.GetReplicationInfoResponse.files:type_name -> dfs_project.ReplicationStatus
+	9,  // 26: dfs_project.SetAclRequest.acl:type_name -> dfs_project.AclEntry
+	49, // 27: dfs_project.GetQuotaResponse.usage:type_name -> dfs_project.DirectoryUsage
+	49, // 28: dfs_project.GetUsageReportResponse.directories:type_name -> dfs_project.DirectoryUsage
+	55, // 29: dfs_project.ListSnapshotsResponse.snapshots:type_name -> dfs_project.SnapshotInfo
+	65, // 30: dfs_project.ListDataServerStatesResponse.servers:type_name -> dfs_project.DataServerState
+	70, // 31: dfs_project.GetBalancerStatusResponse.servers:type_name -> dfs_project.ServerUtilization
+	5,  // 32: dfs_project.GetLeaderResponse.leader:type_name -> dfs_project.MetaServerMsg
+	5,  // 33: dfs_project.GetLeaderResponse.followers:type_name -> dfs_project.MetaServerMsg
+	1,  // 34: dfs_project.LogEntry.operation:type_name -> dfs_project.WALOperationType
+	0,  // 35: dfs_project.CreateNodeOperation.type:type_name -> dfs_project.FileType
+	10, // 36: dfs_project.FinalizeWriteOperation.block_locations:type_name -> dfs_project.BlockLocations
+	10, // 37: dfs_project.SetBlockMappingOperation.block_locs:type_name -> dfs_project.BlockLocations
+	10, // 38: dfs_project.GrantLeaseOperation.prev_blocks:type_name -> dfs_project.BlockLocations
+	10, // 39: dfs_project.ConvertBlockOperation.group:type_name -> dfs_project.BlockLocations
+	9,  // 40: dfs_project.SetPermissionOperation.acl:type_name -> dfs_project.AclEntry
+	74, // 41: dfs_project.AppendEntriesRequest.entries:type_name -> dfs_project.LogEntry
+	12, // 42: dfs_project.MetaServerService.CreateNode:input_type -> dfs_project.CreateNodeRequest
+	13, // 43: dfs_project.MetaServerService.GetNodeInfo:input_type -> dfs_project.GetNodeInfoRequest
+	15, // 44: dfs_project.MetaServerService.ListDirectory:input_type -> dfs_project.ListDirectoryRequest
+	15, // 45: dfs_project.MetaServerService.ListDirectoryStream:input_type -> dfs_project.ListDirectoryRequest
+	17, // 46: dfs_project.MetaServerService.DeleteNode:input_type -> dfs_project.DeleteNodeRequest
+	18, // 47: dfs_project.MetaServerService.RestoreNode:input_type -> dfs_project.RestoreNodeRequest
+	20, // 48: dfs_project.MetaServerService.Rename:input_type -> dfs_project.RenameRequest
+	21, // 49: dfs_project.MetaServerService.GetBlockLocations:input_type -> dfs_project.GetBlockLocationsRequest
+	23, // 50: dfs_project.MetaServerService.GetBlockRange:input_type -> dfs_project.GetBlockRangeRequest
+	26, // 51: dfs_project.MetaServerService.FinalizeWrite:input_type -> dfs_project.FinalizeWriteRequest
+	27, // 52: dfs_project.MetaServerService.RenewLease:input_type -> dfs_project.RenewLeaseRequest
+	28, // 53: dfs_project.MetaServerService.GetClusterInfo:input_type -> dfs_project.GetClusterInfoRequest
+	34, // 54: dfs_project.MetaServerService.GetReplicationInfo:input_type -> dfs_project.GetReplicationInfoRequest
+	37, // 55: dfs_project.MetaServerService.Fsck:input_type -> dfs_project.FsckRequest
+	45, // 56: dfs_project.MetaServerService.SetReplication:input_type -> dfs_project.SetReplicationRequest
+	46, // 57: dfs_project.MetaServerService.Chmod:input_type -> dfs_project.ChmodRequest
+	47, // 58: dfs_project.MetaServerService.Chown:input_type -> dfs_project.ChownRequest
+	48, // 59: dfs_project.MetaServerService.SetAcl:input_type -> dfs_project.SetAclRequest
+	41, // 60: dfs_project.MetaServerService.GetOrphanReport:input_type -> dfs_project.GetOrphanReportRequest
+	50, // 61: dfs_project.MetaServerService.SetQuota:input_type -> dfs_project.SetQuotaRequest
+	51, // 62: dfs_project.MetaServerService.GetQuota:input_type -> dfs_project.GetQuotaRequest
+	53, // 63: dfs_project.MetaServerService.GetUsageReport:input_type -> dfs_project.GetUsageReportRequest
+	56, // 64: dfs_project.MetaServerService.CreateSnapshot:input_type -> dfs_project.CreateSnapshotRequest
+	57, // 65: dfs_project.MetaServerService.DeleteSnapshot:input_type -> dfs_project.DeleteSnapshotRequest
+	58, // 66: dfs_project.MetaServerService.ListSnapshots:input_type -> dfs_project.ListSnapshotsRequest
+	60, // 67: dfs_project.MetaServerService.SetErasureCodingPolicy:input_type -> dfs_project.SetErasureCodingPolicyRequest
+	61, // 68: dfs_project.MetaServerService.GetErasureCodingPolicy:input_type -> dfs_project.GetErasureCodingPolicyRequest
+	63, // 69: dfs_project.MetaServerService.SetDataServerState:input_type -> dfs_project.SetDataServerStateRequest
+	64, // 70: dfs_project.MetaServerService.ListDataServerStates:input_type -> dfs_project.ListDataServerStatesRequest
+	67, // 71: dfs_project.MetaServerService.StartBalancer:input_type -> dfs_project.StartBalancerRequest
+	68, // 72: dfs_project.MetaServerService.StopBalancer:input_type -> dfs_project.StopBalancerRequest
+	69, // 73: dfs_project.MetaServerService.GetBalancerStatus:input_type -> dfs_project.GetBalancerStatusRequest
+	30, // 74: dfs_project.MetaServerService.Heartbeat:input_type -> dfs_project.HeartbeatRequest
+	74, // 75: dfs_project.MetaServerService.SyncWAL:input_type -> dfs_project.LogEntry
+	94, // 76: dfs_project.MetaServerService.RequestVote:input_type -> dfs_project.RequestVoteRequest
+	96, // 77: dfs_project.MetaServerService.AppendEntries:input_type -> dfs_project.AppendEntriesRequest
+	98, // 78: dfs_project.MetaServerService.InstallSnapshot:input_type -> dfs_project.InstallSnapshotRequest
+	93, // 79: dfs_project.MetaServerService.RequestWALSync:input_type -> dfs_project.RequestWALSyncRequest
+	72, // 80: dfs_project.MetaServerService.GetLeader:input_type -> dfs_project.GetLeaderRequest
+	11, // 81: dfs_project.MetaServerService.CreateNode:output_type -> dfs_project.SimpleResponse
+	14, // 82: dfs_project.MetaServerService.GetNodeInfo:output_type -> dfs_project.GetNodeInfoResponse
+	16, // 83: dfs_project.MetaServerService.ListDirectory:output_type -> dfs_project.ListDirectoryResponse
+	16, // 84: dfs_project.MetaServerService.ListDirectoryStream:output_type -> dfs_project.ListDirectoryResponse
+	11, // 85: dfs_project.MetaServerService.DeleteNode:output_type -> dfs_project.SimpleResponse
+	19, // 86: dfs_project.MetaServerService.RestoreNode:output_type -> dfs_project.RestoreNodeResponse
+	11, // 87: dfs_project.MetaServerService.Rename:output_type -> dfs_project.SimpleResponse
+	22, // 88: dfs_project.MetaServerService.GetBlockLocations:output_type -> dfs_project.GetBlockLocationsResponse
+	25, // 89: dfs_project.MetaServerService.GetBlockRange:output_type -> dfs_project.GetBlockRangeResponse
+	11, // 90: dfs_project.MetaServerService.FinalizeWrite:output_type -> dfs_project.SimpleResponse
+	11, // 91: dfs_project.MetaServerService.RenewLease:output_type -> dfs_project.SimpleResponse
+	29, // 92: dfs_project.MetaServerService.GetClusterInfo:output_type -> dfs_project.GetClusterInfoResponse
+	44, // 93: dfs_project.MetaServerService.GetReplicationInfo:output_type -> dfs_project.GetReplicationInfoResponse
+	40, // 94: dfs_project.MetaServerService.Fsck:output_type -> dfs_project.FsckResponse
+	11, // 95: dfs_project.MetaServerService.SetReplication:output_type -> dfs_project.SimpleResponse
+	11, // 96: dfs_project.MetaServerService.Chmod:output_type -> dfs_project.SimpleResponse
+	11, // 97: dfs_project.MetaServerService.Chown:output_type -> dfs_project.SimpleResponse
+	11, // 98: dfs_project.MetaServerService.SetAcl:output_type -> dfs_project.SimpleResponse
+	43, // 99: dfs_project.MetaServerService.GetOrphanReport:output_type -> dfs_project.GetOrphanReportResponse
+	11, // 100: dfs_project.MetaServerService.SetQuota:output_type -> dfs_project.SimpleResponse
+	52, // 101: dfs_project.MetaServerService.GetQuota:output_type -> dfs_project.GetQuotaResponse
+	54, // 102: dfs_project.MetaServerService.GetUsageReport:output_type -> dfs_project.GetUsageReportResponse
+	11, // 103: dfs_project.MetaServerService.CreateSnapshot:output_type -> dfs_project.SimpleResponse
+	11, // 104: dfs_project.MetaServerService.DeleteSnapshot:output_type -> dfs_project.SimpleResponse
+	59, // 105: dfs_project.MetaServerService.ListSnapshots:output_type -> dfs_project.ListSnapshotsResponse
+	11, // 106: dfs_project.MetaServerService.SetErasureCodingPolicy:output_type -> dfs_project.SimpleResponse
+	62, // 107: dfs_project.MetaServerService.GetErasureCodingPolicy:output_type -> dfs_project.GetErasureCodingPolicyResponse
+	11, // 108: dfs_project.MetaServerService.SetDataServerState:output_type -> dfs_project.SimpleResponse
+	66, // 109: dfs_project.MetaServerService.ListDataServerStates:output_type -> dfs_project.ListDataServerStatesResponse
+	11, // 110: dfs_project.MetaServerService.StartBalancer:output_type -> dfs_project.SimpleResponse
+	11, // 111: dfs_project.MetaServerService.StopBalancer:output_type -> dfs_project.SimpleResponse
+	71, // 112: dfs_project.MetaServerService.GetBalancerStatus:output_type -> dfs_project.GetBalancerStatusResponse
+	33, // 113: dfs_project.MetaServerService.Heartbeat:output_type -> dfs_project.HeartbeatResponse
+	11, // 114: dfs_project.MetaServerService.SyncWAL:output_type -> dfs_project.SimpleResponse
+	95, // 115: dfs_project.MetaServerService.RequestVote:output_type -> dfs_project.RequestVoteResponse
+	97, // 116: dfs_project.MetaServerService.AppendEntries:output_type -> dfs_project.AppendEntriesResponse
+	99, // 117: dfs_project.MetaServerService.InstallSnapshot:output_type -> dfs_project.InstallSnapshotResponse
+	74, // 118: dfs_project.MetaServerService.RequestWALSync:output_type -> dfs_project.LogEntry
+	73, // 119: dfs_project.MetaServerService.GetLeader:output_type -> dfs_project.GetLeaderResponse
+	81, // [81:120] is the sub-list for method output_type
+	42, // [42:81] is the sub-list for method input_type
+	42, // [42:42] is the sub-list for extension type_name
+	42, // [42:42] is the sub-list for extension extendee
+	0,  // [0:42] is the sub-list for field type_name
 }
 
 func init() { file_metaServer_proto_init() }
@@ -2419,7 +7332,7 @@ func file_metaServer_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_metaServer_proto_rawDesc), len(file_metaServer_proto_rawDesc)),
 			NumEnums:      3,
-			NumMessages:   33,
+			NumMessages:   97,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
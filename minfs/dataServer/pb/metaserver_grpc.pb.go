@@ -1,7 +1,7 @@
 // Code generated by protoc-gen-go-grpc. DO NOT EDIT.
 // versions:
 // - protoc-gen-go-grpc v1.5.1
-// - protoc             v3.20.3
+// - protoc             v3.12.4
 // source: metaServer.proto
 
 // 与 dataServer.proto 使用同一个包名，便于管理
@@ -22,17 +22,45 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	MetaServerService_CreateNode_FullMethodName         = "/dfs_project.MetaServerService/CreateNode"
-	MetaServerService_GetNodeInfo_FullMethodName        = "/dfs_project.MetaServerService/GetNodeInfo"
-	MetaServerService_ListDirectory_FullMethodName      = "/dfs_project.MetaServerService/ListDirectory"
-	MetaServerService_DeleteNode_FullMethodName         = "/dfs_project.MetaServerService/DeleteNode"
-	MetaServerService_GetBlockLocations_FullMethodName  = "/dfs_project.MetaServerService/GetBlockLocations"
-	MetaServerService_FinalizeWrite_FullMethodName      = "/dfs_project.MetaServerService/FinalizeWrite"
-	MetaServerService_GetClusterInfo_FullMethodName     = "/dfs_project.MetaServerService/GetClusterInfo"
-	MetaServerService_GetReplicationInfo_FullMethodName = "/dfs_project.MetaServerService/GetReplicationInfo"
-	MetaServerService_Heartbeat_FullMethodName          = "/dfs_project.MetaServerService/Heartbeat"
-	MetaServerService_SyncWAL_FullMethodName            = "/dfs_project.MetaServerService/SyncWAL"
-	MetaServerService_GetLeader_FullMethodName          = "/dfs_project.MetaServerService/GetLeader"
+	MetaServerService_CreateNode_FullMethodName             = "/dfs_project.MetaServerService/CreateNode"
+	MetaServerService_GetNodeInfo_FullMethodName            = "/dfs_project.MetaServerService/GetNodeInfo"
+	MetaServerService_ListDirectory_FullMethodName          = "/dfs_project.MetaServerService/ListDirectory"
+	MetaServerService_ListDirectoryStream_FullMethodName    = "/dfs_project.MetaServerService/ListDirectoryStream"
+	MetaServerService_DeleteNode_FullMethodName             = "/dfs_project.MetaServerService/DeleteNode"
+	MetaServerService_RestoreNode_FullMethodName            = "/dfs_project.MetaServerService/RestoreNode"
+	MetaServerService_Rename_FullMethodName                 = "/dfs_project.MetaServerService/Rename"
+	MetaServerService_GetBlockLocations_FullMethodName      = "/dfs_project.MetaServerService/GetBlockLocations"
+	MetaServerService_GetBlockRange_FullMethodName          = "/dfs_project.MetaServerService/GetBlockRange"
+	MetaServerService_FinalizeWrite_FullMethodName          = "/dfs_project.MetaServerService/FinalizeWrite"
+	MetaServerService_RenewLease_FullMethodName             = "/dfs_project.MetaServerService/RenewLease"
+	MetaServerService_GetClusterInfo_FullMethodName         = "/dfs_project.MetaServerService/GetClusterInfo"
+	MetaServerService_GetReplicationInfo_FullMethodName     = "/dfs_project.MetaServerService/GetReplicationInfo"
+	MetaServerService_Fsck_FullMethodName                   = "/dfs_project.MetaServerService/Fsck"
+	MetaServerService_SetReplication_FullMethodName         = "/dfs_project.MetaServerService/SetReplication"
+	MetaServerService_Chmod_FullMethodName                  = "/dfs_project.MetaServerService/Chmod"
+	MetaServerService_Chown_FullMethodName                  = "/dfs_project.MetaServerService/Chown"
+	MetaServerService_SetAcl_FullMethodName                 = "/dfs_project.MetaServerService/SetAcl"
+	MetaServerService_GetOrphanReport_FullMethodName        = "/dfs_project.MetaServerService/GetOrphanReport"
+	MetaServerService_SetQuota_FullMethodName               = "/dfs_project.MetaServerService/SetQuota"
+	MetaServerService_GetQuota_FullMethodName               = "/dfs_project.MetaServerService/GetQuota"
+	MetaServerService_GetUsageReport_FullMethodName         = "/dfs_project.MetaServerService/GetUsageReport"
+	MetaServerService_CreateSnapshot_FullMethodName         = "/dfs_project.MetaServerService/CreateSnapshot"
+	MetaServerService_DeleteSnapshot_FullMethodName         = "/dfs_project.MetaServerService/DeleteSnapshot"
+	MetaServerService_ListSnapshots_FullMethodName          = "/dfs_project.MetaServerService/ListSnapshots"
+	MetaServerService_SetErasureCodingPolicy_FullMethodName = "/dfs_project.MetaServerService/SetErasureCodingPolicy"
+	MetaServerService_GetErasureCodingPolicy_FullMethodName = "/dfs_project.MetaServerService/GetErasureCodingPolicy"
+	MetaServerService_SetDataServerState_FullMethodName     = "/dfs_project.MetaServerService/SetDataServerState"
+	MetaServerService_ListDataServerStates_FullMethodName   = "/dfs_project.MetaServerService/ListDataServerStates"
+	MetaServerService_StartBalancer_FullMethodName          = "/dfs_project.MetaServerService/StartBalancer"
+	MetaServerService_StopBalancer_FullMethodName           = "/dfs_project.MetaServerService/StopBalancer"
+	MetaServerService_GetBalancerStatus_FullMethodName      = "/dfs_project.MetaServerService/GetBalancerStatus"
+	MetaServerService_Heartbeat_FullMethodName              = "/dfs_project.MetaServerService/Heartbeat"
+	MetaServerService_SyncWAL_FullMethodName                = "/dfs_project.MetaServerService/SyncWAL"
+	MetaServerService_RequestVote_FullMethodName            = "/dfs_project.MetaServerService/RequestVote"
+	MetaServerService_AppendEntries_FullMethodName          = "/dfs_project.MetaServerService/AppendEntries"
+	MetaServerService_InstallSnapshot_FullMethodName        = "/dfs_project.MetaServerService/InstallSnapshot"
+	MetaServerService_RequestWALSync_FullMethodName         = "/dfs_project.MetaServerService/RequestWALSync"
+	MetaServerService_GetLeader_FullMethodName              = "/dfs_project.MetaServerService/GetLeader"
 )
 
 // MetaServerServiceClient is the client API for MetaServerService service.
@@ -47,20 +75,76 @@ type MetaServerServiceClient interface {
 	GetNodeInfo(ctx context.Context, in *GetNodeInfoRequest, opts ...grpc.CallOption) (*GetNodeInfoResponse, error)
 	// 对应考核点 A2: 列出目录下的所有条目
 	ListDirectory(ctx context.Context, in *ListDirectoryRequest, opts ...grpc.CallOption) (*ListDirectoryResponse, error)
-	// 对应考核点 A3: 删除文件或目录 (支持递归)
+	// 流式列出目录内容，每个响应为一批子节点
+	ListDirectoryStream(ctx context.Context, in *ListDirectoryRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ListDirectoryResponse], error)
+	// 对应考核点 A3: 删除文件或目录 (支持递归)，开启回收站时移入 /.Trash
 	DeleteNode(ctx context.Context, in *DeleteNodeRequest, opts ...grpc.CallOption) (*SimpleResponse, error)
+	// 将回收站中的节点恢复到删除前的路径
+	RestoreNode(ctx context.Context, in *RestoreNodeRequest, opts ...grpc.CallOption) (*RestoreNodeResponse, error)
+	// 重命名/移动文件或目录 (单个事务内完成，不复制数据块)
+	Rename(ctx context.Context, in *RenameRequest, opts ...grpc.CallOption) (*SimpleResponse, error)
 	// 对应考核点 A4: 为写入/读取文件做准备，获取数据块的位置信息
 	GetBlockLocations(ctx context.Context, in *GetBlockLocationsRequest, opts ...grpc.CallOption) (*GetBlockLocationsResponse, error)
+	// 随机读取：将文件内的字节范围映射为数据块及块内偏移
+	GetBlockRange(ctx context.Context, in *GetBlockRangeRequest, opts ...grpc.CallOption) (*GetBlockRangeResponse, error)
 	// 对应考核点 A4: 当 Client 写完一个文件后，调用此接口来最终确认
 	FinalizeWrite(ctx context.Context, in *FinalizeWriteRequest, opts ...grpc.CallOption) (*SimpleResponse, error)
+	// 续约写租约，写入期间 Client 需定期调用
+	RenewLease(ctx context.Context, in *RenewLeaseRequest, opts ...grpc.CallOption) (*SimpleResponse, error)
 	// 对应考核点 A5, A6, B7: 获取集群信息，用于展示副本分布等
 	GetClusterInfo(ctx context.Context, in *GetClusterInfoRequest, opts ...grpc.CallOption) (*GetClusterInfoResponse, error)
 	// 获取文件的副本分布情况
 	GetReplicationInfo(ctx context.Context, in *GetReplicationInfoRequest, opts ...grpc.CallOption) (*GetReplicationInfoResponse, error)
+	// 流式 FSCK 报告：按路径前缀和过滤条件逐批返回块的副本状态，最后一条消息携带汇总
+	Fsck(ctx context.Context, in *FsckRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FsckResponse], error)
+	// 修改已有文件的副本数，FSCK 随后按新副本数增加或删除副本
+	SetReplication(ctx context.Context, in *SetReplicationRequest, opts ...grpc.CallOption) (*SimpleResponse, error)
+	// 修改节点的权限位，只有属主或超级用户可以修改
+	Chmod(ctx context.Context, in *ChmodRequest, opts ...grpc.CallOption) (*SimpleResponse, error)
+	// 修改节点的属主和属组，只有超级用户可以修改属主，属主可以把属组改为自己所在的组
+	Chown(ctx context.Context, in *ChownRequest, opts ...grpc.CallOption) (*SimpleResponse, error)
+	// 替换节点的 ACL 条目，只有属主或超级用户可以修改
+	SetAcl(ctx context.Context, in *SetAclRequest, opts ...grpc.CallOption) (*SimpleResponse, error)
+	// 获取 FSCK 孤儿块处理的统计和当前孤儿块列表
+	GetOrphanReport(ctx context.Context, in *GetOrphanReportRequest, opts ...grpc.CallOption) (*GetOrphanReportResponse, error)
+	// 设置目录配额：空间按副本数计算，0 表示不限制，两项均为 0 时删除配额
+	SetQuota(ctx context.Context, in *SetQuotaRequest, opts ...grpc.CallOption) (*SimpleResponse, error)
+	// 获取目录的配额和使用量
+	GetQuota(ctx context.Context, in *GetQuotaRequest, opts ...grpc.CallOption) (*GetQuotaResponse, error)
+	// 按目录报告使用量和配额
+	GetUsageReport(ctx context.Context, in *GetUsageReportRequest, opts ...grpc.CallOption) (*GetUsageReportResponse, error)
+	// 为目录子树创建只读快照，快照内容通过 /.snapshot/<name>/ 路径读取
+	CreateSnapshot(ctx context.Context, in *CreateSnapshotRequest, opts ...grpc.CallOption) (*SimpleResponse, error)
+	// 删除快照，不再被任何文件或快照引用的块加入垃圾回收队列
+	DeleteSnapshot(ctx context.Context, in *DeleteSnapshotRequest, opts ...grpc.CallOption) (*SimpleResponse, error)
+	// 列出所有快照
+	ListSnapshots(ctx context.Context, in *ListSnapshotsRequest, opts ...grpc.CallOption) (*ListSnapshotsResponse, error)
+	// 设置目录的纠删码策略（如 RS-6-3），之后在该目录下新建的文件以纠删码块组存储，policy 为空时删除
+	SetErasureCodingPolicy(ctx context.Context, in *SetErasureCodingPolicyRequest, opts ...grpc.CallOption) (*SimpleResponse, error)
+	// 获取路径生效的纠删码策略（最近的设置了策略的祖先目录）
+	GetErasureCodingPolicy(ctx context.Context, in *GetErasureCodingPolicyRequest, opts ...grpc.CallOption) (*GetErasureCodingPolicyResponse, error)
+	// 设置 DataServer 的管理状态：decommissioning 时迁出所有块，maintenance 时在到期前不因其宕机重新复制
+	SetDataServerState(ctx context.Context, in *SetDataServerStateRequest, opts ...grpc.CallOption) (*SimpleResponse, error)
+	// 列出所有 DataServer 的管理状态和退役进度
+	ListDataServerStates(ctx context.Context, in *ListDataServerStatesRequest, opts ...grpc.CallOption) (*ListDataServerStatesResponse, error)
+	// 启动均衡器，把块从磁盘使用率高的 DataServer 迁移到使用率低的 DataServer
+	StartBalancer(ctx context.Context, in *StartBalancerRequest, opts ...grpc.CallOption) (*SimpleResponse, error)
+	// 停止均衡器
+	StopBalancer(ctx context.Context, in *StopBalancerRequest, opts ...grpc.CallOption) (*SimpleResponse, error)
+	// 获取均衡器状态和各 DataServer 的使用率
+	GetBalancerStatus(ctx context.Context, in *GetBalancerStatusRequest, opts ...grpc.CallOption) (*GetBalancerStatusResponse, error)
 	// 接收来自 DataServer 的心跳和块报告
 	Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error)
-	// 用于主从节点之间，实时同步元数据操作日志 (WAL)
+	// 旧版主从日志推送接口，已由 AppendEntries 取代，调用会被拒绝
 	SyncWAL(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[LogEntry, SimpleResponse], error)
+	// Raft 选举：候选人请求投票
+	RequestVote(ctx context.Context, in *RequestVoteRequest, opts ...grpc.CallOption) (*RequestVoteResponse, error)
+	// Raft 日志复制：leader 追加日志条目，空条目作为心跳
+	AppendEntries(ctx context.Context, in *AppendEntriesRequest, opts ...grpc.CallOption) (*AppendEntriesResponse, error)
+	// Raft 快照安装：leader 向落后于快照的 follower 分块发送元数据快照，之后只复制快照之后的日志
+	InstallSnapshot(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[InstallSnapshotRequest, InstallSnapshotResponse], error)
+	// 节点重连后向leader申请WAL同步，只返回最近快照之后的日志
+	RequestWALSync(ctx context.Context, in *RequestWALSyncRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogEntry], error)
 	// 获取主从信息 (HA 支持)
 	GetLeader(ctx context.Context, in *GetLeaderRequest, opts ...grpc.CallOption) (*GetLeaderResponse, error)
 }
@@ -103,6 +187,25 @@ func (c *metaServerServiceClient) ListDirectory(ctx context.Context, in *ListDir
 	return out, nil
 }
 
+func (c *metaServerServiceClient) ListDirectoryStream(ctx context.Context, in *ListDirectoryRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ListDirectoryResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MetaServerService_ServiceDesc.Streams[0], MetaServerService_ListDirectoryStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ListDirectoryRequest, ListDirectoryResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MetaServerService_ListDirectoryStreamClient = grpc.ServerStreamingClient[ListDirectoryResponse]
+
 func (c *metaServerServiceClient) DeleteNode(ctx context.Context, in *DeleteNodeRequest, opts ...grpc.CallOption) (*SimpleResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(SimpleResponse)
@@ -113,6 +216,26 @@ func (c *metaServerServiceClient) DeleteNode(ctx context.Context, in *DeleteNode
 	return out, nil
 }
 
+func (c *metaServerServiceClient) RestoreNode(ctx context.Context, in *RestoreNodeRequest, opts ...grpc.CallOption) (*RestoreNodeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RestoreNodeResponse)
+	err := c.cc.Invoke(ctx, MetaServerService_RestoreNode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaServerServiceClient) Rename(ctx context.Context, in *RenameRequest, opts ...grpc.CallOption) (*SimpleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SimpleResponse)
+	err := c.cc.Invoke(ctx, MetaServerService_Rename_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *metaServerServiceClient) GetBlockLocations(ctx context.Context, in *GetBlockLocationsRequest, opts ...grpc.CallOption) (*GetBlockLocationsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetBlockLocationsResponse)
@@ -123,6 +246,16 @@ func (c *metaServerServiceClient) GetBlockLocations(ctx context.Context, in *Get
 	return out, nil
 }
 
+func (c *metaServerServiceClient) GetBlockRange(ctx context.Context, in *GetBlockRangeRequest, opts ...grpc.CallOption) (*GetBlockRangeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetBlockRangeResponse)
+	err := c.cc.Invoke(ctx, MetaServerService_GetBlockRange_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *metaServerServiceClient) FinalizeWrite(ctx context.Context, in *FinalizeWriteRequest, opts ...grpc.CallOption) (*SimpleResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(SimpleResponse)
@@ -133,6 +266,16 @@ func (c *metaServerServiceClient) FinalizeWrite(ctx context.Context, in *Finaliz
 	return out, nil
 }
 
+func (c *metaServerServiceClient) RenewLease(ctx context.Context, in *RenewLeaseRequest, opts ...grpc.CallOption) (*SimpleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SimpleResponse)
+	err := c.cc.Invoke(ctx, MetaServerService_RenewLease_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *metaServerServiceClient) GetClusterInfo(ctx context.Context, in *GetClusterInfoRequest, opts ...grpc.CallOption) (*GetClusterInfoResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetClusterInfoResponse)
@@ -153,6 +296,205 @@ func (c *metaServerServiceClient) GetReplicationInfo(ctx context.Context, in *Ge
 	return out, nil
 }
 
+func (c *metaServerServiceClient) Fsck(ctx context.Context, in *FsckRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FsckResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MetaServerService_ServiceDesc.Streams[1], MetaServerService_Fsck_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[FsckRequest, FsckResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MetaServerService_FsckClient = grpc.ServerStreamingClient[FsckResponse]
+
+func (c *metaServerServiceClient) SetReplication(ctx context.Context, in *SetReplicationRequest, opts ...grpc.CallOption) (*SimpleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SimpleResponse)
+	err := c.cc.Invoke(ctx, MetaServerService_SetReplication_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaServerServiceClient) Chmod(ctx context.Context, in *ChmodRequest, opts ...grpc.CallOption) (*SimpleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SimpleResponse)
+	err := c.cc.Invoke(ctx, MetaServerService_Chmod_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaServerServiceClient) Chown(ctx context.Context, in *ChownRequest, opts ...grpc.CallOption) (*SimpleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SimpleResponse)
+	err := c.cc.Invoke(ctx, MetaServerService_Chown_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaServerServiceClient) SetAcl(ctx context.Context, in *SetAclRequest, opts ...grpc.CallOption) (*SimpleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SimpleResponse)
+	err := c.cc.Invoke(ctx, MetaServerService_SetAcl_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaServerServiceClient) GetOrphanReport(ctx context.Context, in *GetOrphanReportRequest, opts ...grpc.CallOption) (*GetOrphanReportResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetOrphanReportResponse)
+	err := c.cc.Invoke(ctx, MetaServerService_GetOrphanReport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaServerServiceClient) SetQuota(ctx context.Context, in *SetQuotaRequest, opts ...grpc.CallOption) (*SimpleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SimpleResponse)
+	err := c.cc.Invoke(ctx, MetaServerService_SetQuota_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaServerServiceClient) GetQuota(ctx context.Context, in *GetQuotaRequest, opts ...grpc.CallOption) (*GetQuotaResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetQuotaResponse)
+	err := c.cc.Invoke(ctx, MetaServerService_GetQuota_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaServerServiceClient) GetUsageReport(ctx context.Context, in *GetUsageReportRequest, opts ...grpc.CallOption) (*GetUsageReportResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetUsageReportResponse)
+	err := c.cc.Invoke(ctx, MetaServerService_GetUsageReport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaServerServiceClient) CreateSnapshot(ctx context.Context, in *CreateSnapshotRequest, opts ...grpc.CallOption) (*SimpleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SimpleResponse)
+	err := c.cc.Invoke(ctx, MetaServerService_CreateSnapshot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaServerServiceClient) DeleteSnapshot(ctx context.Context, in *DeleteSnapshotRequest, opts ...grpc.CallOption) (*SimpleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SimpleResponse)
+	err := c.cc.Invoke(ctx, MetaServerService_DeleteSnapshot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaServerServiceClient) ListSnapshots(ctx context.Context, in *ListSnapshotsRequest, opts ...grpc.CallOption) (*ListSnapshotsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSnapshotsResponse)
+	err := c.cc.Invoke(ctx, MetaServerService_ListSnapshots_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaServerServiceClient) SetErasureCodingPolicy(ctx context.Context, in *SetErasureCodingPolicyRequest, opts ...grpc.CallOption) (*SimpleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SimpleResponse)
+	err := c.cc.Invoke(ctx, MetaServerService_SetErasureCodingPolicy_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaServerServiceClient) GetErasureCodingPolicy(ctx context.Context, in *GetErasureCodingPolicyRequest, opts ...grpc.CallOption) (*GetErasureCodingPolicyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetErasureCodingPolicyResponse)
+	err := c.cc.Invoke(ctx, MetaServerService_GetErasureCodingPolicy_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaServerServiceClient) SetDataServerState(ctx context.Context, in *SetDataServerStateRequest, opts ...grpc.CallOption) (*SimpleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SimpleResponse)
+	err := c.cc.Invoke(ctx, MetaServerService_SetDataServerState_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaServerServiceClient) ListDataServerStates(ctx context.Context, in *ListDataServerStatesRequest, opts ...grpc.CallOption) (*ListDataServerStatesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListDataServerStatesResponse)
+	err := c.cc.Invoke(ctx, MetaServerService_ListDataServerStates_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaServerServiceClient) StartBalancer(ctx context.Context, in *StartBalancerRequest, opts ...grpc.CallOption) (*SimpleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SimpleResponse)
+	err := c.cc.Invoke(ctx, MetaServerService_StartBalancer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaServerServiceClient) StopBalancer(ctx context.Context, in *StopBalancerRequest, opts ...grpc.CallOption) (*SimpleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SimpleResponse)
+	err := c.cc.Invoke(ctx, MetaServerService_StopBalancer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaServerServiceClient) GetBalancerStatus(ctx context.Context, in *GetBalancerStatusRequest, opts ...grpc.CallOption) (*GetBalancerStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetBalancerStatusResponse)
+	err := c.cc.Invoke(ctx, MetaServerService_GetBalancerStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *metaServerServiceClient) Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(HeartbeatResponse)
@@ -165,7 +507,7 @@ func (c *metaServerServiceClient) Heartbeat(ctx context.Context, in *HeartbeatRe
 
 func (c *metaServerServiceClient) SyncWAL(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[LogEntry, SimpleResponse], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	stream, err := c.cc.NewStream(ctx, &MetaServerService_ServiceDesc.Streams[0], MetaServerService_SyncWAL_FullMethodName, cOpts...)
+	stream, err := c.cc.NewStream(ctx, &MetaServerService_ServiceDesc.Streams[2], MetaServerService_SyncWAL_FullMethodName, cOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -176,6 +518,58 @@ func (c *metaServerServiceClient) SyncWAL(ctx context.Context, opts ...grpc.Call
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type MetaServerService_SyncWALClient = grpc.ClientStreamingClient[LogEntry, SimpleResponse]
 
+func (c *metaServerServiceClient) RequestVote(ctx context.Context, in *RequestVoteRequest, opts ...grpc.CallOption) (*RequestVoteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RequestVoteResponse)
+	err := c.cc.Invoke(ctx, MetaServerService_RequestVote_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaServerServiceClient) AppendEntries(ctx context.Context, in *AppendEntriesRequest, opts ...grpc.CallOption) (*AppendEntriesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AppendEntriesResponse)
+	err := c.cc.Invoke(ctx, MetaServerService_AppendEntries_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaServerServiceClient) InstallSnapshot(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[InstallSnapshotRequest, InstallSnapshotResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MetaServerService_ServiceDesc.Streams[3], MetaServerService_InstallSnapshot_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[InstallSnapshotRequest, InstallSnapshotResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MetaServerService_InstallSnapshotClient = grpc.ClientStreamingClient[InstallSnapshotRequest, InstallSnapshotResponse]
+
+func (c *metaServerServiceClient) RequestWALSync(ctx context.Context, in *RequestWALSyncRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogEntry], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MetaServerService_ServiceDesc.Streams[4], MetaServerService_RequestWALSync_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[RequestWALSyncRequest, LogEntry]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MetaServerService_RequestWALSyncClient = grpc.ServerStreamingClient[LogEntry]
+
 func (c *metaServerServiceClient) GetLeader(ctx context.Context, in *GetLeaderRequest, opts ...grpc.CallOption) (*GetLeaderResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetLeaderResponse)
@@ -198,20 +592,76 @@ type MetaServerServiceServer interface {
 	GetNodeInfo(context.Context, *GetNodeInfoRequest) (*GetNodeInfoResponse, error)
 	// 对应考核点 A2: 列出目录下的所有条目
 	ListDirectory(context.Context, *ListDirectoryRequest) (*ListDirectoryResponse, error)
-	// 对应考核点 A3: 删除文件或目录 (支持递归)
+	// 流式列出目录内容，每个响应为一批子节点
+	ListDirectoryStream(*ListDirectoryRequest, grpc.ServerStreamingServer[ListDirectoryResponse]) error
+	// 对应考核点 A3: 删除文件或目录 (支持递归)，开启回收站时移入 /.Trash
 	DeleteNode(context.Context, *DeleteNodeRequest) (*SimpleResponse, error)
+	// 将回收站中的节点恢复到删除前的路径
+	RestoreNode(context.Context, *RestoreNodeRequest) (*RestoreNodeResponse, error)
+	// 重命名/移动文件或目录 (单个事务内完成，不复制数据块)
+	Rename(context.Context, *RenameRequest) (*SimpleResponse, error)
 	// 对应考核点 A4: 为写入/读取文件做准备，获取数据块的位置信息
 	GetBlockLocations(context.Context, *GetBlockLocationsRequest) (*GetBlockLocationsResponse, error)
+	// 随机读取：将文件内的字节范围映射为数据块及块内偏移
+	GetBlockRange(context.Context, *GetBlockRangeRequest) (*GetBlockRangeResponse, error)
 	// 对应考核点 A4: 当 Client 写完一个文件后，调用此接口来最终确认
 	FinalizeWrite(context.Context, *FinalizeWriteRequest) (*SimpleResponse, error)
+	// 续约写租约，写入期间 Client 需定期调用
+	RenewLease(context.Context, *RenewLeaseRequest) (*SimpleResponse, error)
 	// 对应考核点 A5, A6, B7: 获取集群信息，用于展示副本分布等
 	GetClusterInfo(context.Context, *GetClusterInfoRequest) (*GetClusterInfoResponse, error)
 	// 获取文件的副本分布情况
 	GetReplicationInfo(context.Context, *GetReplicationInfoRequest) (*GetReplicationInfoResponse, error)
+	// 流式 FSCK 报告：按路径前缀和过滤条件逐批返回块的副本状态，最后一条消息携带汇总
+	Fsck(*FsckRequest, grpc.ServerStreamingServer[FsckResponse]) error
+	// 修改已有文件的副本数，FSCK 随后按新副本数增加或删除副本
+	SetReplication(context.Context, *SetReplicationRequest) (*SimpleResponse, error)
+	// 修改节点的权限位，只有属主或超级用户可以修改
+	Chmod(context.Context, *ChmodRequest) (*SimpleResponse, error)
+	// 修改节点的属主和属组，只有超级用户可以修改属主，属主可以把属组改为自己所在的组
+	Chown(context.Context, *ChownRequest) (*SimpleResponse, error)
+	// 替换节点的 ACL 条目，只有属主或超级用户可以修改
+	SetAcl(context.Context, *SetAclRequest) (*SimpleResponse, error)
+	// 获取 FSCK 孤儿块处理的统计和当前孤儿块列表
+	GetOrphanReport(context.Context, *GetOrphanReportRequest) (*GetOrphanReportResponse, error)
+	// 设置目录配额：空间按副本数计算，0 表示不限制，两项均为 0 时删除配额
+	SetQuota(context.Context, *SetQuotaRequest) (*SimpleResponse, error)
+	// 获取目录的配额和使用量
+	GetQuota(context.Context, *GetQuotaRequest) (*GetQuotaResponse, error)
+	// 按目录报告使用量和配额
+	GetUsageReport(context.Context, *GetUsageReportRequest) (*GetUsageReportResponse, error)
+	// 为目录子树创建只读快照，快照内容通过 /.snapshot/<name>/ 路径读取
+	CreateSnapshot(context.Context, *CreateSnapshotRequest) (*SimpleResponse, error)
+	// 删除快照，不再被任何文件或快照引用的块加入垃圾回收队列
+	DeleteSnapshot(context.Context, *DeleteSnapshotRequest) (*SimpleResponse, error)
+	// 列出所有快照
+	ListSnapshots(context.Context, *ListSnapshotsRequest) (*ListSnapshotsResponse, error)
+	// 设置目录的纠删码策略（如 RS-6-3），之后在该目录下新建的文件以纠删码块组存储，policy 为空时删除
+	SetErasureCodingPolicy(context.Context, *SetErasureCodingPolicyRequest) (*SimpleResponse, error)
+	// 获取路径生效的纠删码策略（最近的设置了策略的祖先目录）
+	GetErasureCodingPolicy(context.Context, *GetErasureCodingPolicyRequest) (*GetErasureCodingPolicyResponse, error)
+	// 设置 DataServer 的管理状态：decommissioning 时迁出所有块，maintenance 时在到期前不因其宕机重新复制
+	SetDataServerState(context.Context, *SetDataServerStateRequest) (*SimpleResponse, error)
+	// 列出所有 DataServer 的管理状态和退役进度
+	ListDataServerStates(context.Context, *ListDataServerStatesRequest) (*ListDataServerStatesResponse, error)
+	// 启动均衡器，把块从磁盘使用率高的 DataServer 迁移到使用率低的 DataServer
+	StartBalancer(context.Context, *StartBalancerRequest) (*SimpleResponse, error)
+	// 停止均衡器
+	StopBalancer(context.Context, *StopBalancerRequest) (*SimpleResponse, error)
+	// 获取均衡器状态和各 DataServer 的使用率
+	GetBalancerStatus(context.Context, *GetBalancerStatusRequest) (*GetBalancerStatusResponse, error)
 	// 接收来自 DataServer 的心跳和块报告
 	Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error)
-	// 用于主从节点之间，实时同步元数据操作日志 (WAL)
+	// 旧版主从日志推送接口，已由 AppendEntries 取代，调用会被拒绝
 	SyncWAL(grpc.ClientStreamingServer[LogEntry, SimpleResponse]) error
+	// Raft 选举：候选人请求投票
+	RequestVote(context.Context, *RequestVoteRequest) (*RequestVoteResponse, error)
+	// Raft 日志复制：leader 追加日志条目，空条目作为心跳
+	AppendEntries(context.Context, *AppendEntriesRequest) (*AppendEntriesResponse, error)
+	// Raft 快照安装：leader 向落后于快照的 follower 分块发送元数据快照，之后只复制快照之后的日志
+	InstallSnapshot(grpc.ClientStreamingServer[InstallSnapshotRequest, InstallSnapshotResponse]) error
+	// 节点重连后向leader申请WAL同步，只返回最近快照之后的日志
+	RequestWALSync(*RequestWALSyncRequest, grpc.ServerStreamingServer[LogEntry]) error
 	// 获取主从信息 (HA 支持)
 	GetLeader(context.Context, *GetLeaderRequest) (*GetLeaderResponse, error)
 	mustEmbedUnimplementedMetaServerServiceServer()
@@ -233,191 +683,693 @@ func (UnimplementedMetaServerServiceServer) GetNodeInfo(context.Context, *GetNod
 func (UnimplementedMetaServerServiceServer) ListDirectory(context.Context, *ListDirectoryRequest) (*ListDirectoryResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListDirectory not implemented")
 }
+func (UnimplementedMetaServerServiceServer) ListDirectoryStream(*ListDirectoryRequest, grpc.ServerStreamingServer[ListDirectoryResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ListDirectoryStream not implemented")
+}
 func (UnimplementedMetaServerServiceServer) DeleteNode(context.Context, *DeleteNodeRequest) (*SimpleResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteNode not implemented")
 }
+func (UnimplementedMetaServerServiceServer) RestoreNode(context.Context, *RestoreNodeRequest) (*RestoreNodeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreNode not implemented")
+}
+func (UnimplementedMetaServerServiceServer) Rename(context.Context, *RenameRequest) (*SimpleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Rename not implemented")
+}
 func (UnimplementedMetaServerServiceServer) GetBlockLocations(context.Context, *GetBlockLocationsRequest) (*GetBlockLocationsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetBlockLocations not implemented")
 }
-func (UnimplementedMetaServerServiceServer) FinalizeWrite(context.Context, *FinalizeWriteRequest) (*SimpleResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method FinalizeWrite not implemented")
+func (UnimplementedMetaServerServiceServer) GetBlockRange(context.Context, *GetBlockRangeRequest) (*GetBlockRangeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlockRange not implemented")
+}
+func (UnimplementedMetaServerServiceServer) FinalizeWrite(context.Context, *FinalizeWriteRequest) (*SimpleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FinalizeWrite not implemented")
+}
+func (UnimplementedMetaServerServiceServer) RenewLease(context.Context, *RenewLeaseRequest) (*SimpleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RenewLease not implemented")
+}
+func (UnimplementedMetaServerServiceServer) GetClusterInfo(context.Context, *GetClusterInfoRequest) (*GetClusterInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetClusterInfo not implemented")
+}
+func (UnimplementedMetaServerServiceServer) GetReplicationInfo(context.Context, *GetReplicationInfoRequest) (*GetReplicationInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReplicationInfo not implemented")
+}
+func (UnimplementedMetaServerServiceServer) Fsck(*FsckRequest, grpc.ServerStreamingServer[FsckResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method Fsck not implemented")
+}
+func (UnimplementedMetaServerServiceServer) SetReplication(context.Context, *SetReplicationRequest) (*SimpleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetReplication not implemented")
+}
+func (UnimplementedMetaServerServiceServer) Chmod(context.Context, *ChmodRequest) (*SimpleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Chmod not implemented")
+}
+func (UnimplementedMetaServerServiceServer) Chown(context.Context, *ChownRequest) (*SimpleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Chown not implemented")
+}
+func (UnimplementedMetaServerServiceServer) SetAcl(context.Context, *SetAclRequest) (*SimpleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetAcl not implemented")
+}
+func (UnimplementedMetaServerServiceServer) GetOrphanReport(context.Context, *GetOrphanReportRequest) (*GetOrphanReportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOrphanReport not implemented")
+}
+func (UnimplementedMetaServerServiceServer) SetQuota(context.Context, *SetQuotaRequest) (*SimpleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetQuota not implemented")
+}
+func (UnimplementedMetaServerServiceServer) GetQuota(context.Context, *GetQuotaRequest) (*GetQuotaResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetQuota not implemented")
+}
+func (UnimplementedMetaServerServiceServer) GetUsageReport(context.Context, *GetUsageReportRequest) (*GetUsageReportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUsageReport not implemented")
+}
+func (UnimplementedMetaServerServiceServer) CreateSnapshot(context.Context, *CreateSnapshotRequest) (*SimpleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateSnapshot not implemented")
+}
+func (UnimplementedMetaServerServiceServer) DeleteSnapshot(context.Context, *DeleteSnapshotRequest) (*SimpleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteSnapshot not implemented")
+}
+func (UnimplementedMetaServerServiceServer) ListSnapshots(context.Context, *ListSnapshotsRequest) (*ListSnapshotsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSnapshots not implemented")
+}
+func (UnimplementedMetaServerServiceServer) SetErasureCodingPolicy(context.Context, *SetErasureCodingPolicyRequest) (*SimpleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetErasureCodingPolicy not implemented")
+}
+func (UnimplementedMetaServerServiceServer) GetErasureCodingPolicy(context.Context, *GetErasureCodingPolicyRequest) (*GetErasureCodingPolicyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetErasureCodingPolicy not implemented")
+}
+func (UnimplementedMetaServerServiceServer) SetDataServerState(context.Context, *SetDataServerStateRequest) (*SimpleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetDataServerState not implemented")
+}
+func (UnimplementedMetaServerServiceServer) ListDataServerStates(context.Context, *ListDataServerStatesRequest) (*ListDataServerStatesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDataServerStates not implemented")
+}
+func (UnimplementedMetaServerServiceServer) StartBalancer(context.Context, *StartBalancerRequest) (*SimpleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartBalancer not implemented")
+}
+func (UnimplementedMetaServerServiceServer) StopBalancer(context.Context, *StopBalancerRequest) (*SimpleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StopBalancer not implemented")
+}
+func (UnimplementedMetaServerServiceServer) GetBalancerStatus(context.Context, *GetBalancerStatusRequest) (*GetBalancerStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBalancerStatus not implemented")
+}
+func (UnimplementedMetaServerServiceServer) Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Heartbeat not implemented")
+}
+func (UnimplementedMetaServerServiceServer) SyncWAL(grpc.ClientStreamingServer[LogEntry, SimpleResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method SyncWAL not implemented")
+}
+func (UnimplementedMetaServerServiceServer) RequestVote(context.Context, *RequestVoteRequest) (*RequestVoteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestVote not implemented")
+}
+func (UnimplementedMetaServerServiceServer) AppendEntries(context.Context, *AppendEntriesRequest) (*AppendEntriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AppendEntries not implemented")
+}
+func (UnimplementedMetaServerServiceServer) InstallSnapshot(grpc.ClientStreamingServer[InstallSnapshotRequest, InstallSnapshotResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method InstallSnapshot not implemented")
+}
+func (UnimplementedMetaServerServiceServer) RequestWALSync(*RequestWALSyncRequest, grpc.ServerStreamingServer[LogEntry]) error {
+	return status.Errorf(codes.Unimplemented, "method RequestWALSync not implemented")
+}
+func (UnimplementedMetaServerServiceServer) GetLeader(context.Context, *GetLeaderRequest) (*GetLeaderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLeader not implemented")
+}
+func (UnimplementedMetaServerServiceServer) mustEmbedUnimplementedMetaServerServiceServer() {}
+func (UnimplementedMetaServerServiceServer) testEmbeddedByValue()                           {}
+
+// UnsafeMetaServerServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MetaServerServiceServer will
+// result in compilation errors.
+type UnsafeMetaServerServiceServer interface {
+	mustEmbedUnimplementedMetaServerServiceServer()
+}
+
+func RegisterMetaServerServiceServer(s grpc.ServiceRegistrar, srv MetaServerServiceServer) {
+	// If the following call pancis, it indicates UnimplementedMetaServerServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&MetaServerService_ServiceDesc, srv)
+}
+
+func _MetaServerService_CreateNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateNodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaServerServiceServer).CreateNode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetaServerService_CreateNode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaServerServiceServer).CreateNode(ctx, req.(*CreateNodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetaServerService_GetNodeInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetNodeInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaServerServiceServer).GetNodeInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetaServerService_GetNodeInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaServerServiceServer).GetNodeInfo(ctx, req.(*GetNodeInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetaServerService_ListDirectory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDirectoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaServerServiceServer).ListDirectory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetaServerService_ListDirectory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaServerServiceServer).ListDirectory(ctx, req.(*ListDirectoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetaServerService_ListDirectoryStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListDirectoryRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MetaServerServiceServer).ListDirectoryStream(m, &grpc.GenericServerStream[ListDirectoryRequest, ListDirectoryResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MetaServerService_ListDirectoryStreamServer = grpc.ServerStreamingServer[ListDirectoryResponse]
+
+func _MetaServerService_DeleteNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteNodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaServerServiceServer).DeleteNode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetaServerService_DeleteNode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaServerServiceServer).DeleteNode(ctx, req.(*DeleteNodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetaServerService_RestoreNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreNodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaServerServiceServer).RestoreNode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetaServerService_RestoreNode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaServerServiceServer).RestoreNode(ctx, req.(*RestoreNodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetaServerService_Rename_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenameRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaServerServiceServer).Rename(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetaServerService_Rename_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaServerServiceServer).Rename(ctx, req.(*RenameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetaServerService_GetBlockLocations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBlockLocationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaServerServiceServer).GetBlockLocations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetaServerService_GetBlockLocations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaServerServiceServer).GetBlockLocations(ctx, req.(*GetBlockLocationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetaServerService_GetBlockRange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBlockRangeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaServerServiceServer).GetBlockRange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetaServerService_GetBlockRange_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaServerServiceServer).GetBlockRange(ctx, req.(*GetBlockRangeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetaServerService_FinalizeWrite_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FinalizeWriteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaServerServiceServer).FinalizeWrite(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetaServerService_FinalizeWrite_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaServerServiceServer).FinalizeWrite(ctx, req.(*FinalizeWriteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetaServerService_RenewLease_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenewLeaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaServerServiceServer).RenewLease(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetaServerService_RenewLease_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaServerServiceServer).RenewLease(ctx, req.(*RenewLeaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetaServerService_GetClusterInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetClusterInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaServerServiceServer).GetClusterInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetaServerService_GetClusterInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaServerServiceServer).GetClusterInfo(ctx, req.(*GetClusterInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetaServerService_GetReplicationInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetReplicationInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaServerServiceServer).GetReplicationInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetaServerService_GetReplicationInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaServerServiceServer).GetReplicationInfo(ctx, req.(*GetReplicationInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetaServerService_Fsck_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(FsckRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MetaServerServiceServer).Fsck(m, &grpc.GenericServerStream[FsckRequest, FsckResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MetaServerService_FsckServer = grpc.ServerStreamingServer[FsckResponse]
+
+func _MetaServerService_SetReplication_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetReplicationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaServerServiceServer).SetReplication(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetaServerService_SetReplication_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaServerServiceServer).SetReplication(ctx, req.(*SetReplicationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetaServerService_Chmod_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChmodRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaServerServiceServer).Chmod(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetaServerService_Chmod_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaServerServiceServer).Chmod(ctx, req.(*ChmodRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetaServerService_Chown_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChownRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaServerServiceServer).Chown(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetaServerService_Chown_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaServerServiceServer).Chown(ctx, req.(*ChownRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (UnimplementedMetaServerServiceServer) GetClusterInfo(context.Context, *GetClusterInfoRequest) (*GetClusterInfoResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetClusterInfo not implemented")
+
+func _MetaServerService_SetAcl_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetAclRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaServerServiceServer).SetAcl(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetaServerService_SetAcl_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaServerServiceServer).SetAcl(ctx, req.(*SetAclRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (UnimplementedMetaServerServiceServer) GetReplicationInfo(context.Context, *GetReplicationInfoRequest) (*GetReplicationInfoResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetReplicationInfo not implemented")
+
+func _MetaServerService_GetOrphanReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOrphanReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaServerServiceServer).GetOrphanReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetaServerService_GetOrphanReport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaServerServiceServer).GetOrphanReport(ctx, req.(*GetOrphanReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (UnimplementedMetaServerServiceServer) Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Heartbeat not implemented")
+
+func _MetaServerService_SetQuota_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetQuotaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaServerServiceServer).SetQuota(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetaServerService_SetQuota_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaServerServiceServer).SetQuota(ctx, req.(*SetQuotaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (UnimplementedMetaServerServiceServer) SyncWAL(grpc.ClientStreamingServer[LogEntry, SimpleResponse]) error {
-	return status.Errorf(codes.Unimplemented, "method SyncWAL not implemented")
+
+func _MetaServerService_GetQuota_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetQuotaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaServerServiceServer).GetQuota(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetaServerService_GetQuota_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaServerServiceServer).GetQuota(ctx, req.(*GetQuotaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (UnimplementedMetaServerServiceServer) GetLeader(context.Context, *GetLeaderRequest) (*GetLeaderResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetLeader not implemented")
+
+func _MetaServerService_GetUsageReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUsageReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaServerServiceServer).GetUsageReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetaServerService_GetUsageReport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaServerServiceServer).GetUsageReport(ctx, req.(*GetUsageReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (UnimplementedMetaServerServiceServer) mustEmbedUnimplementedMetaServerServiceServer() {}
-func (UnimplementedMetaServerServiceServer) testEmbeddedByValue()                           {}
 
-// UnsafeMetaServerServiceServer may be embedded to opt out of forward compatibility for this service.
-// Use of this interface is not recommended, as added methods to MetaServerServiceServer will
-// result in compilation errors.
-type UnsafeMetaServerServiceServer interface {
-	mustEmbedUnimplementedMetaServerServiceServer()
+func _MetaServerService_CreateSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateSnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaServerServiceServer).CreateSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetaServerService_CreateSnapshot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaServerServiceServer).CreateSnapshot(ctx, req.(*CreateSnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func RegisterMetaServerServiceServer(s grpc.ServiceRegistrar, srv MetaServerServiceServer) {
-	// If the following call pancis, it indicates UnimplementedMetaServerServiceServer was
-	// embedded by pointer and is nil.  This will cause panics if an
-	// unimplemented method is ever invoked, so we test this at initialization
-	// time to prevent it from happening at runtime later due to I/O.
-	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
-		t.testEmbeddedByValue()
+func _MetaServerService_DeleteSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteSnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
 	}
-	s.RegisterService(&MetaServerService_ServiceDesc, srv)
+	if interceptor == nil {
+		return srv.(MetaServerServiceServer).DeleteSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetaServerService_DeleteSnapshot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaServerServiceServer).DeleteSnapshot(ctx, req.(*DeleteSnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _MetaServerService_CreateNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CreateNodeRequest)
+func _MetaServerService_ListSnapshots_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSnapshotsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(MetaServerServiceServer).CreateNode(ctx, in)
+		return srv.(MetaServerServiceServer).ListSnapshots(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: MetaServerService_CreateNode_FullMethodName,
+		FullMethod: MetaServerService_ListSnapshots_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(MetaServerServiceServer).CreateNode(ctx, req.(*CreateNodeRequest))
+		return srv.(MetaServerServiceServer).ListSnapshots(ctx, req.(*ListSnapshotsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _MetaServerService_GetNodeInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetNodeInfoRequest)
+func _MetaServerService_SetErasureCodingPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetErasureCodingPolicyRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(MetaServerServiceServer).GetNodeInfo(ctx, in)
+		return srv.(MetaServerServiceServer).SetErasureCodingPolicy(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: MetaServerService_GetNodeInfo_FullMethodName,
+		FullMethod: MetaServerService_SetErasureCodingPolicy_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(MetaServerServiceServer).GetNodeInfo(ctx, req.(*GetNodeInfoRequest))
+		return srv.(MetaServerServiceServer).SetErasureCodingPolicy(ctx, req.(*SetErasureCodingPolicyRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _MetaServerService_ListDirectory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ListDirectoryRequest)
+func _MetaServerService_GetErasureCodingPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetErasureCodingPolicyRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(MetaServerServiceServer).ListDirectory(ctx, in)
+		return srv.(MetaServerServiceServer).GetErasureCodingPolicy(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: MetaServerService_ListDirectory_FullMethodName,
+		FullMethod: MetaServerService_GetErasureCodingPolicy_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(MetaServerServiceServer).ListDirectory(ctx, req.(*ListDirectoryRequest))
+		return srv.(MetaServerServiceServer).GetErasureCodingPolicy(ctx, req.(*GetErasureCodingPolicyRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _MetaServerService_DeleteNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(DeleteNodeRequest)
+func _MetaServerService_SetDataServerState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetDataServerStateRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(MetaServerServiceServer).DeleteNode(ctx, in)
+		return srv.(MetaServerServiceServer).SetDataServerState(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: MetaServerService_DeleteNode_FullMethodName,
+		FullMethod: MetaServerService_SetDataServerState_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(MetaServerServiceServer).DeleteNode(ctx, req.(*DeleteNodeRequest))
+		return srv.(MetaServerServiceServer).SetDataServerState(ctx, req.(*SetDataServerStateRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _MetaServerService_GetBlockLocations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetBlockLocationsRequest)
+func _MetaServerService_ListDataServerStates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDataServerStatesRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(MetaServerServiceServer).GetBlockLocations(ctx, in)
+		return srv.(MetaServerServiceServer).ListDataServerStates(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: MetaServerService_GetBlockLocations_FullMethodName,
+		FullMethod: MetaServerService_ListDataServerStates_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(MetaServerServiceServer).GetBlockLocations(ctx, req.(*GetBlockLocationsRequest))
+		return srv.(MetaServerServiceServer).ListDataServerStates(ctx, req.(*ListDataServerStatesRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _MetaServerService_FinalizeWrite_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(FinalizeWriteRequest)
+func _MetaServerService_StartBalancer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartBalancerRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(MetaServerServiceServer).FinalizeWrite(ctx, in)
+		return srv.(MetaServerServiceServer).StartBalancer(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: MetaServerService_FinalizeWrite_FullMethodName,
+		FullMethod: MetaServerService_StartBalancer_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(MetaServerServiceServer).FinalizeWrite(ctx, req.(*FinalizeWriteRequest))
+		return srv.(MetaServerServiceServer).StartBalancer(ctx, req.(*StartBalancerRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _MetaServerService_GetClusterInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetClusterInfoRequest)
+func _MetaServerService_StopBalancer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StopBalancerRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(MetaServerServiceServer).GetClusterInfo(ctx, in)
+		return srv.(MetaServerServiceServer).StopBalancer(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: MetaServerService_GetClusterInfo_FullMethodName,
+		FullMethod: MetaServerService_StopBalancer_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(MetaServerServiceServer).GetClusterInfo(ctx, req.(*GetClusterInfoRequest))
+		return srv.(MetaServerServiceServer).StopBalancer(ctx, req.(*StopBalancerRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _MetaServerService_GetReplicationInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetReplicationInfoRequest)
+func _MetaServerService_GetBalancerStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBalancerStatusRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(MetaServerServiceServer).GetReplicationInfo(ctx, in)
+		return srv.(MetaServerServiceServer).GetBalancerStatus(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: MetaServerService_GetReplicationInfo_FullMethodName,
+		FullMethod: MetaServerService_GetBalancerStatus_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(MetaServerServiceServer).GetReplicationInfo(ctx, req.(*GetReplicationInfoRequest))
+		return srv.(MetaServerServiceServer).GetBalancerStatus(ctx, req.(*GetBalancerStatusRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -447,6 +1399,60 @@ func _MetaServerService_SyncWAL_Handler(srv interface{}, stream grpc.ServerStrea
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type MetaServerService_SyncWALServer = grpc.ClientStreamingServer[LogEntry, SimpleResponse]
 
+func _MetaServerService_RequestVote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestVoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaServerServiceServer).RequestVote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetaServerService_RequestVote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaServerServiceServer).RequestVote(ctx, req.(*RequestVoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetaServerService_AppendEntries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AppendEntriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaServerServiceServer).AppendEntries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetaServerService_AppendEntries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaServerServiceServer).AppendEntries(ctx, req.(*AppendEntriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetaServerService_InstallSnapshot_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(MetaServerServiceServer).InstallSnapshot(&grpc.GenericServerStream[InstallSnapshotRequest, InstallSnapshotResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MetaServerService_InstallSnapshotServer = grpc.ClientStreamingServer[InstallSnapshotRequest, InstallSnapshotResponse]
+
+func _MetaServerService_RequestWALSync_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RequestWALSyncRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MetaServerServiceServer).RequestWALSync(m, &grpc.GenericServerStream[RequestWALSyncRequest, LogEntry]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MetaServerService_RequestWALSyncServer = grpc.ServerStreamingServer[LogEntry]
+
 func _MetaServerService_GetLeader_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetLeaderRequest)
 	if err := dec(in); err != nil {
@@ -488,14 +1494,30 @@ var MetaServerService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteNode",
 			Handler:    _MetaServerService_DeleteNode_Handler,
 		},
+		{
+			MethodName: "RestoreNode",
+			Handler:    _MetaServerService_RestoreNode_Handler,
+		},
+		{
+			MethodName: "Rename",
+			Handler:    _MetaServerService_Rename_Handler,
+		},
 		{
 			MethodName: "GetBlockLocations",
 			Handler:    _MetaServerService_GetBlockLocations_Handler,
 		},
+		{
+			MethodName: "GetBlockRange",
+			Handler:    _MetaServerService_GetBlockRange_Handler,
+		},
 		{
 			MethodName: "FinalizeWrite",
 			Handler:    _MetaServerService_FinalizeWrite_Handler,
 		},
+		{
+			MethodName: "RenewLease",
+			Handler:    _MetaServerService_RenewLease_Handler,
+		},
 		{
 			MethodName: "GetClusterInfo",
 			Handler:    _MetaServerService_GetClusterInfo_Handler,
@@ -504,21 +1526,121 @@ var MetaServerService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetReplicationInfo",
 			Handler:    _MetaServerService_GetReplicationInfo_Handler,
 		},
+		{
+			MethodName: "SetReplication",
+			Handler:    _MetaServerService_SetReplication_Handler,
+		},
+		{
+			MethodName: "Chmod",
+			Handler:    _MetaServerService_Chmod_Handler,
+		},
+		{
+			MethodName: "Chown",
+			Handler:    _MetaServerService_Chown_Handler,
+		},
+		{
+			MethodName: "SetAcl",
+			Handler:    _MetaServerService_SetAcl_Handler,
+		},
+		{
+			MethodName: "GetOrphanReport",
+			Handler:    _MetaServerService_GetOrphanReport_Handler,
+		},
+		{
+			MethodName: "SetQuota",
+			Handler:    _MetaServerService_SetQuota_Handler,
+		},
+		{
+			MethodName: "GetQuota",
+			Handler:    _MetaServerService_GetQuota_Handler,
+		},
+		{
+			MethodName: "GetUsageReport",
+			Handler:    _MetaServerService_GetUsageReport_Handler,
+		},
+		{
+			MethodName: "CreateSnapshot",
+			Handler:    _MetaServerService_CreateSnapshot_Handler,
+		},
+		{
+			MethodName: "DeleteSnapshot",
+			Handler:    _MetaServerService_DeleteSnapshot_Handler,
+		},
+		{
+			MethodName: "ListSnapshots",
+			Handler:    _MetaServerService_ListSnapshots_Handler,
+		},
+		{
+			MethodName: "SetErasureCodingPolicy",
+			Handler:    _MetaServerService_SetErasureCodingPolicy_Handler,
+		},
+		{
+			MethodName: "GetErasureCodingPolicy",
+			Handler:    _MetaServerService_GetErasureCodingPolicy_Handler,
+		},
+		{
+			MethodName: "SetDataServerState",
+			Handler:    _MetaServerService_SetDataServerState_Handler,
+		},
+		{
+			MethodName: "ListDataServerStates",
+			Handler:    _MetaServerService_ListDataServerStates_Handler,
+		},
+		{
+			MethodName: "StartBalancer",
+			Handler:    _MetaServerService_StartBalancer_Handler,
+		},
+		{
+			MethodName: "StopBalancer",
+			Handler:    _MetaServerService_StopBalancer_Handler,
+		},
+		{
+			MethodName: "GetBalancerStatus",
+			Handler:    _MetaServerService_GetBalancerStatus_Handler,
+		},
 		{
 			MethodName: "Heartbeat",
 			Handler:    _MetaServerService_Heartbeat_Handler,
 		},
+		{
+			MethodName: "RequestVote",
+			Handler:    _MetaServerService_RequestVote_Handler,
+		},
+		{
+			MethodName: "AppendEntries",
+			Handler:    _MetaServerService_AppendEntries_Handler,
+		},
 		{
 			MethodName: "GetLeader",
 			Handler:    _MetaServerService_GetLeader_Handler,
 		},
 	},
 	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListDirectoryStream",
+			Handler:       _MetaServerService_ListDirectoryStream_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Fsck",
+			Handler:       _MetaServerService_Fsck_Handler,
+			ServerStreams: true,
+		},
 		{
 			StreamName:    "SyncWAL",
 			Handler:       _MetaServerService_SyncWAL_Handler,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "InstallSnapshot",
+			Handler:       _MetaServerService_InstallSnapshot_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "RequestWALSync",
+			Handler:       _MetaServerService_RequestWALSync_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "metaServer.proto",
 }
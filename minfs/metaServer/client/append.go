@@ -0,0 +1,173 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"os"
+
+	"metaServer/pb"
+)
+
+// AppendWriter 向文件末尾顺序追加数据
+// 写入的数据按块缓存，填满文件的尾块时通过追加模式的 GetBlockLocations 写入并用 FinalizeWrite 提交，
+// Flush 和 Close 提交剩余的数据，每次提交后追加的内容对读取可见
+type AppendWriter struct {
+	client *Client
+	ctx    context.Context
+	path   string
+	size   int64     // 已提交的文件大小
+	buf    []byte    // 尚未提交的数据
+	hash   hash.Hash // 从空文件开始写入时为整个文件的 MD5，否则为 nil，提交时 MD5 置空
+	closed bool
+}
+
+// OpenAppend 打开文件用于追加，文件不存在时创建空文件
+func (c *Client) OpenAppend(ctx context.Context, filePath string) (*AppendWriter, error) {
+	stat, err := c.Stat(ctx, filePath)
+	if err != nil {
+		if createErr := c.CreateFile(ctx, filePath, 0); createErr != nil {
+			return nil, createErr
+		}
+		stat = &pb.StatInfo{Type: pb.FileType_File}
+	}
+	if stat.Type != pb.FileType_File {
+		return nil, fmt.Errorf("%s is not a file", filePath)
+	}
+
+	w := &AppendWriter{client: c, ctx: ctx, path: filePath, size: stat.Size}
+	if stat.Size == 0 {
+		w.hash = md5.New()
+	}
+	return w, nil
+}
+
+// Size 返回已提交和缓存的数据总长度，即下一次写入在文件中的偏移
+func (w *AppendWriter) Size() int64 {
+	return w.size + int64(len(w.buf))
+}
+
+// Write 实现 io.Writer，填满尾块的数据立即提交，提交失败时返回已提交的字节数
+func (w *AppendWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+
+	blockSize := w.client.config.BlockSize
+	written := 0
+	for len(p) > 0 {
+		room := blockSize - w.size%blockSize // 尾块剩余的空间
+		n := min(int64(len(p)), room-int64(len(w.buf)))
+		w.buf = append(w.buf, p[:n]...)
+		if int64(len(w.buf)) == room {
+			if err := w.commit(w.buf); err != nil {
+				w.buf = w.buf[:len(w.buf)-int(n)]
+				return written, err
+			}
+			w.buf = w.buf[:0]
+		}
+		written += int(n)
+		p = p[n:]
+	}
+	return written, nil
+}
+
+// Flush 提交缓存的数据，之后的写入会连同已有的尾块数据一起重写尾块
+func (w *AppendWriter) Flush() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	if err := w.commit(w.buf); err != nil {
+		return err
+	}
+	w.buf = w.buf[:0]
+	return nil
+}
+
+// Close 提交剩余的数据
+func (w *AppendWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	err := w.Flush()
+	w.closed = true
+	return err
+}
+
+// commit 将 data 追加到文件末尾并提交新的文件大小
+func (w *AppendWriter) commit(data []byte) error {
+	c := w.client
+	var resp *pb.GetBlockLocationsResponse
+	err := c.call(w.ctx, func(ctx context.Context, meta pb.MetaServerServiceClient) error {
+		var err error
+		resp, err = meta.GetBlockLocations(ctx, &pb.GetBlockLocationsRequest{
+			Path:       w.path,
+			Size:       int64(len(data)),
+			Append:     true,
+			ClientName: c.config.ClientName,
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to allocate blocks for %s: %w", w.path, err)
+	}
+
+	stopRenew := c.renewLease(w.ctx, w.path)
+	defer stopRenew()
+
+	// 未写满的尾块由新块替换，需要从原尾块读取已有的数据一起写入
+	payload := data
+	if resp.TailOffset > 0 {
+		if resp.PrevTail == nil {
+			return fmt.Errorf("meta server did not return the tail block of %s", w.path)
+		}
+		tail, err := c.readRange(w.ctx, &pb.BlockRange{
+			BlockIndex: resp.FirstBlockIndex,
+			Length:     resp.TailOffset,
+			Block:      resp.PrevTail,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to read tail block of %s: %w", w.path, err)
+		}
+		payload = append(tail, data...)
+	}
+
+	blockSize := c.config.BlockSize
+	expected := (int64(len(payload)) + blockSize - 1) / blockSize
+	if int64(len(resp.BlockLocations)) != expected {
+		return fmt.Errorf("meta server allocated %d blocks for %d bytes, expected %d with block size %d",
+			len(resp.BlockLocations), len(payload), expected, blockSize)
+	}
+	for i, block := range resp.BlockLocations {
+		start := int64(i) * blockSize
+		end := min(start+blockSize, int64(len(payload)))
+		if err := c.writeBlock(w.ctx, block, bytes.NewReader(payload[start:end]), end-start); err != nil {
+			return fmt.Errorf("failed to write block %d of %s: %w", resp.FirstBlockIndex+uint64(i), w.path, err)
+		}
+	}
+
+	md5sum := ""
+	if w.hash != nil {
+		w.hash.Write(data)
+		md5sum = hex.EncodeToString(w.hash.Sum(nil))
+	}
+	err = c.call(w.ctx, func(ctx context.Context, meta pb.MetaServerServiceClient) error {
+		return checkSimple(meta.FinalizeWrite(ctx, &pb.FinalizeWriteRequest{
+			Path:       w.path,
+			Inode:      resp.Inode,
+			Size:       w.size + int64(len(data)),
+			Md5:        md5sum,
+			ClientName: c.config.ClientName,
+		}))
+	})
+	if err != nil {
+		// 提交失败后无法确定文件内容，之后不再提交 MD5
+		w.hash = nil
+		return err
+	}
+	w.size += int64(len(data))
+	return nil
+}
@@ -0,0 +1,439 @@
+// Package client MinFS 的 Go 客户端：通过 GetLeader 或 etcd 发现 leader，
+// 封装 GetBlockLocations → WriteBlock → FinalizeWrite 的写入流程和按副本重试的读取流程
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"metaServer/pb"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultBlockSize 与 MetaServer scheduler.block_size 的默认值一致
+	DefaultBlockSize = 4 * 1024 * 1024
+
+	// etcdMetaServerPrefix MetaServer 在 etcd 中的注册前缀
+	etcdMetaServerPrefix = "/minfs/metaServers/"
+
+	chunkSize = 64 * 1024 // 发送给 DataServer 的分片大小
+)
+
+// Config 客户端配置，MetaServers 和 EtcdEndpoints 至少设置一项
+type Config struct {
+	MetaServers   []string // MetaServer 地址 (host:port)，任意一个节点即可找到 leader
+	EtcdEndpoints []string // etcd 地址，从 /minfs/metaServers/ 发现 MetaServer
+
+	User   string   // 调用者用户名，通过 minfs-user 传递，为空时不做权限检查
+	Groups []string // 调用者所属组，通过 minfs-groups 传递
+
+	BlockSize          int64         // 与 MetaServer 的 scheduler.block_size 一致，0 使用默认值
+	ClientName         string        // 写租约的持有者标识，为空时按主机名和进程号生成
+	LeaseRenewInterval time.Duration // 写入期间续约的间隔，应小于租约的 soft_limit，0 为 30s
+	DialTimeout        time.Duration // 发现 leader 和访问 etcd 的超时，0 为 5s
+
+	TLS *tls.Config // 开启 TLS 时的客户端配置，nil 为明文
+}
+
+// Client MinFS 客户端，可以在多个 goroutine 中使用
+type Client struct {
+	config Config
+
+	mu     sync.Mutex
+	conns  map[string]*grpc.ClientConn // addr -> conn，MetaServer 和 DataServer 共用
+	leader string                      // 当前 leader 地址，为空时需要重新发现
+}
+
+// New 创建客户端，leader 在第一次调用时发现
+func New(config Config) (*Client, error) {
+	if len(config.MetaServers) == 0 && len(config.EtcdEndpoints) == 0 {
+		return nil, fmt.Errorf("no meta servers or etcd endpoints configured")
+	}
+	if config.BlockSize <= 0 {
+		config.BlockSize = DefaultBlockSize
+	}
+	if config.ClientName == "" {
+		host, _ := os.Hostname()
+		config.ClientName = fmt.Sprintf("minfs-go-%s-%d", host, os.Getpid())
+	}
+	if config.LeaseRenewInterval <= 0 {
+		config.LeaseRenewInterval = 30 * time.Second
+	}
+	if config.DialTimeout <= 0 {
+		config.DialTimeout = 5 * time.Second
+	}
+	return &Client{config: config, conns: make(map[string]*grpc.ClientConn)}, nil
+}
+
+// Close 关闭所有连接
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var firstErr error
+	for addr, conn := range c.conns {
+		if err := conn.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(c.conns, addr)
+	}
+	c.leader = ""
+	return firstErr
+}
+
+// conn 获取到 addr 的连接，连接按地址复用
+func (c *Client) conn(addr string) (*grpc.ClientConn, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if conn, exists := c.conns[addr]; exists {
+		return conn, nil
+	}
+	creds := insecure.NewCredentials()
+	if c.config.TLS != nil {
+		creds = credentials.NewTLS(c.config.TLS)
+	}
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	c.conns[addr] = conn
+	return conn, nil
+}
+
+// dataClient 获取 DataServer 客户端
+func (c *Client) dataClient(addr string) (pb.DataServerServiceClient, error) {
+	conn, err := c.conn(addr)
+	if err != nil {
+		return nil, err
+	}
+	return pb.NewDataServerServiceClient(conn), nil
+}
+
+// withCaller 在请求中附带调用者身份
+func (c *Client) withCaller(ctx context.Context) context.Context {
+	if c.config.User == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx,
+		"minfs-user", c.config.User,
+		"minfs-groups", strings.Join(c.config.Groups, ","))
+}
+
+// metaClient 获取 leader 的客户端，没有缓存的 leader 时重新发现
+func (c *Client) metaClient(ctx context.Context) (pb.MetaServerServiceClient, error) {
+	c.mu.Lock()
+	leader := c.leader
+	c.mu.Unlock()
+
+	if leader == "" {
+		var err error
+		if leader, err = c.discoverLeader(ctx); err != nil {
+			return nil, err
+		}
+		c.mu.Lock()
+		c.leader = leader
+		c.mu.Unlock()
+	}
+
+	conn, err := c.conn(leader)
+	if err != nil {
+		return nil, err
+	}
+	return pb.NewMetaServerServiceClient(conn), nil
+}
+
+// Leader 返回当前 leader 的地址
+func (c *Client) Leader(ctx context.Context) (string, error) {
+	if _, err := c.metaClient(ctx); err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.leader, nil
+}
+
+// discoverLeader 依次向配置的和 etcd 中注册的 MetaServer 查询 leader
+func (c *Client) discoverLeader(ctx context.Context) (string, error) {
+	candidates := append([]string{}, c.config.MetaServers...)
+	var lastErr error
+	if len(c.config.EtcdEndpoints) > 0 {
+		addrs, err := c.etcdMetaServers(ctx)
+		if err != nil {
+			lastErr = err
+		}
+		candidates = append(candidates, addrs...)
+	}
+
+	for _, addr := range candidates {
+		conn, err := c.conn(addr)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		callCtx, cancel := context.WithTimeout(ctx, c.config.DialTimeout)
+		resp, err := pb.NewMetaServerServiceClient(conn).GetLeader(callCtx, &pb.GetLeaderRequest{})
+		cancel()
+		if err != nil {
+			lastErr = fmt.Errorf("GetLeader on %s failed: %w", addr, err)
+			continue
+		}
+		if resp.Leader == nil || resp.Leader.Host == "" {
+			lastErr = fmt.Errorf("%s does not know the leader", addr)
+			continue
+		}
+		return net.JoinHostPort(resp.Leader.Host, strconv.Itoa(int(resp.Leader.Port))), nil
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no meta servers found")
+	}
+	return "", fmt.Errorf("failed to discover leader: %w", lastErr)
+}
+
+// etcdMetaServers 读取 etcd 中注册的 MetaServer 地址
+func (c *Client) etcdMetaServers(ctx context.Context) ([]string, error) {
+	etcd, err := clientv3.New(clientv3.Config{
+		Endpoints:   c.config.EtcdEndpoints,
+		DialTimeout: c.config.DialTimeout,
+		TLS:         c.config.TLS,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to etcd: %w", err)
+	}
+	defer etcd.Close()
+
+	getCtx, cancel := context.WithTimeout(ctx, c.config.DialTimeout)
+	defer cancel()
+	resp, err := etcd.Get(getCtx, etcdMetaServerPrefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list meta servers from etcd: %w", err)
+	}
+
+	var addrs []string
+	for _, kv := range resp.Kvs {
+		var registration struct {
+			Addr string `json:"addr"`
+		}
+		if err := json.Unmarshal(kv.Value, &registration); err != nil || registration.Addr == "" {
+			continue
+		}
+		addrs = append(addrs, registration.Addr)
+	}
+	return addrs, nil
+}
+
+// partialError 流式响应已经有部分结果交给调用方后出现的错误，重试会重复交付，不能重试
+type partialError struct {
+	err error
+}
+
+func (e *partialError) Error() string { return e.err.Error() }
+func (e *partialError) Unwrap() error { return e.err }
+
+// shouldRediscover leader 不可达或已不是 leader 时需要重新发现
+func shouldRediscover(err error) bool {
+	var partial *partialError
+	if errors.As(err, &partial) {
+		return false
+	}
+	if status.Code(err) == codes.Unavailable {
+		return true
+	}
+	return strings.Contains(err.Error(), "leader")
+}
+
+// call 在 leader 上执行 fn，leader 切换或不可达时重新发现 leader 并重试一次
+func (c *Client) call(ctx context.Context, fn func(ctx context.Context, meta pb.MetaServerServiceClient) error) error {
+	ctx = c.withCaller(ctx)
+	meta, err := c.metaClient(ctx)
+	if err != nil {
+		return err
+	}
+	err = fn(ctx, meta)
+	if err == nil || !shouldRediscover(err) {
+		return unwrap(err)
+	}
+
+	c.mu.Lock()
+	c.leader = ""
+	c.mu.Unlock()
+	meta, discoverErr := c.metaClient(ctx)
+	if discoverErr != nil {
+		return unwrap(err)
+	}
+	return unwrap(fn(ctx, meta))
+}
+
+// unwrap 去掉内部的错误包装后返回给调用方
+func unwrap(err error) error {
+	var partial *partialError
+	if errors.As(err, &partial) {
+		return partial.err
+	}
+	return err
+}
+
+// checkSimple 将 SimpleResponse 中的失败转换为错误
+func checkSimple(resp *pb.SimpleResponse, err error) error {
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return fmt.Errorf("%s", resp.Message)
+	}
+	return nil
+}
+
+// Mkdir 创建目录
+func (c *Client) Mkdir(ctx context.Context, path string) error {
+	return c.MkdirWithMode(ctx, path, 0)
+}
+
+// MkdirWithMode 按指定权限位创建目录，mode 为 0 时使用默认的 0755
+func (c *Client) MkdirWithMode(ctx context.Context, path string, mode uint32) error {
+	return c.call(ctx, func(ctx context.Context, meta pb.MetaServerServiceClient) error {
+		return checkSimple(meta.CreateNode(ctx, &pb.CreateNodeRequest{Path: path, Type: pb.FileType_Directory, Mode: mode}))
+	})
+}
+
+// CreateFile 创建空文件，mode 为 0 时使用默认的 0644
+func (c *Client) CreateFile(ctx context.Context, path string, mode uint32) error {
+	return c.call(ctx, func(ctx context.Context, meta pb.MetaServerServiceClient) error {
+		return checkSimple(meta.CreateNode(ctx, &pb.CreateNodeRequest{Path: path, Type: pb.FileType_File, Mode: mode}))
+	})
+}
+
+// Chmod 修改权限位
+func (c *Client) Chmod(ctx context.Context, path string, mode uint32) error {
+	return c.call(ctx, func(ctx context.Context, meta pb.MetaServerServiceClient) error {
+		return checkSimple(meta.Chmod(ctx, &pb.ChmodRequest{Path: path, Mode: mode}))
+	})
+}
+
+// Stat 获取文件或目录的属性
+func (c *Client) Stat(ctx context.Context, path string) (*pb.StatInfo, error) {
+	var stat *pb.StatInfo
+	err := c.call(ctx, func(ctx context.Context, meta pb.MetaServerServiceClient) error {
+		resp, err := meta.GetNodeInfo(ctx, &pb.GetNodeInfoRequest{Path: path})
+		if err != nil {
+			return err
+		}
+		stat = resp.StatInfo
+		return nil
+	})
+	return stat, err
+}
+
+// List 流式列出目录内容，每个子节点交给 fn，fn 返回错误时停止
+func (c *Client) List(ctx context.Context, path string, fn func(*pb.StatInfo) error) error {
+	return c.call(ctx, func(ctx context.Context, meta pb.MetaServerServiceClient) error {
+		stream, err := meta.ListDirectoryStream(ctx, &pb.ListDirectoryRequest{Path: path})
+		if err != nil {
+			return err
+		}
+		delivered := false
+		for {
+			resp, err := stream.Recv()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				if delivered {
+					return &partialError{err}
+				}
+				return err
+			}
+			for _, node := range resp.Nodes {
+				delivered = true
+				if err := fn(node); err != nil {
+					return &partialError{err}
+				}
+			}
+		}
+	})
+}
+
+// Remove 删除文件或目录，skipTrash 为 false 时开启了回收站的集群会移入 /.Trash
+func (c *Client) Remove(ctx context.Context, path string, recursive, skipTrash bool) error {
+	return c.call(ctx, func(ctx context.Context, meta pb.MetaServerServiceClient) error {
+		return checkSimple(meta.DeleteNode(ctx, &pb.DeleteNodeRequest{Path: path, Recursive: recursive, SkipTrash: skipTrash}))
+	})
+}
+
+// Rename 重命名或移动文件和目录
+func (c *Client) Rename(ctx context.Context, src, dst string, overwrite bool) error {
+	return c.call(ctx, func(ctx context.Context, meta pb.MetaServerServiceClient) error {
+		return checkSimple(meta.Rename(ctx, &pb.RenameRequest{Src: src, Dst: dst, Overwrite: overwrite}))
+	})
+}
+
+// ClusterInfo 获取 MetaServer 和 DataServer 的集群信息
+func (c *Client) ClusterInfo(ctx context.Context) (*pb.ClusterInfo, error) {
+	var info *pb.ClusterInfo
+	err := c.call(ctx, func(ctx context.Context, meta pb.MetaServerServiceClient) error {
+		resp, err := meta.GetClusterInfo(ctx, &pb.GetClusterInfoRequest{})
+		if err != nil {
+			return err
+		}
+		info = resp.ClusterInfo
+		return nil
+	})
+	return info, err
+}
+
+// Fsck 获取 FSCK 报告，满足过滤条件的块逐个交给 fn，返回最后的汇总
+func (c *Client) Fsck(ctx context.Context, req *pb.FsckRequest, fn func(*pb.FsckBlock) error) (*pb.FsckSummary, error) {
+	var summary *pb.FsckSummary
+	err := c.call(ctx, func(ctx context.Context, meta pb.MetaServerServiceClient) error {
+		stream, err := meta.Fsck(ctx, req)
+		if err != nil {
+			return err
+		}
+		delivered := false
+		for {
+			resp, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				if delivered {
+					return &partialError{err}
+				}
+				return err
+			}
+			for _, block := range resp.Blocks {
+				delivered = true
+				if err := fn(block); err != nil {
+					return &partialError{err}
+				}
+			}
+			if resp.Summary != nil {
+				summary = resp.Summary
+			}
+		}
+		if summary == nil {
+			return fmt.Errorf("fsck stream ended without summary")
+		}
+		return nil
+	})
+	return summary, err
+}
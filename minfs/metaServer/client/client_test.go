@@ -0,0 +1,243 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+
+	"metaServer/pb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const testBlockSize = 1000
+
+// fakeMeta 只实现客户端用到的 MetaServer 接口，leader 为空时自己是 leader
+type fakeMeta struct {
+	pb.UnimplementedMetaServerServiceServer
+	leader    string
+	dataAddrs []string
+
+	mu     sync.Mutex
+	files  map[string]*pb.NodeInfo
+	blocks map[string][]*pb.BlockLocations
+	nextID uint64
+}
+
+func (m *fakeMeta) GetLeader(ctx context.Context, req *pb.GetLeaderRequest) (*pb.GetLeaderResponse, error) {
+	host, port, _ := net.SplitHostPort(m.leader)
+	p, _ := strconv.Atoi(port)
+	return &pb.GetLeaderResponse{Leader: &pb.MetaServerMsg{Host: host, Port: int32(p)}}, nil
+}
+
+func (m *fakeMeta) GetNodeInfo(ctx context.Context, req *pb.GetNodeInfoRequest) (*pb.GetNodeInfoResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	info, exists := m.files[req.Path]
+	if !exists {
+		return nil, fmt.Errorf("file not found: %s", req.Path)
+	}
+	return &pb.GetNodeInfoResponse{StatInfo: &pb.StatInfo{Path: info.Path, Size: info.Size, Type: info.Type, Md5: info.Md5}}, nil
+}
+
+func (m *fakeMeta) GetBlockLocations(ctx context.Context, req *pb.GetBlockLocationsRequest) (*pb.GetBlockLocationsResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nextID++
+	inode := m.nextID
+	m.files[req.Path] = &pb.NodeInfo{Inode: inode, Path: req.Path, Type: pb.FileType_File}
+
+	var blocks []*pb.BlockLocations
+	for remaining := req.Size; remaining > 0; remaining -= testBlockSize {
+		m.nextID++
+		blocks = append(blocks, &pb.BlockLocations{BlockId: m.nextID, Locations: m.dataAddrs})
+	}
+	m.blocks[req.Path] = blocks
+	return &pb.GetBlockLocationsResponse{Inode: inode, BlockLocations: blocks}, nil
+}
+
+func (m *fakeMeta) FinalizeWrite(ctx context.Context, req *pb.FinalizeWriteRequest) (*pb.SimpleResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	info := m.files[req.Path]
+	if info == nil || info.Inode != req.Inode {
+		return &pb.SimpleResponse{Success: false, Message: "inode mismatch"}, nil
+	}
+	info.Size = req.Size
+	info.Md5 = req.Md5
+	return &pb.SimpleResponse{Success: true}, nil
+}
+
+func (m *fakeMeta) GetBlockRange(ctx context.Context, req *pb.GetBlockRangeRequest) (*pb.GetBlockRangeResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	info := m.files[req.Path]
+	end := min(req.Offset+req.Length, info.Size)
+
+	resp := &pb.GetBlockRangeResponse{Inode: info.Inode, Size: info.Size}
+	for offset := req.Offset; offset < end; {
+		index := offset / testBlockSize
+		blockOffset := offset % testBlockSize
+		length := min(testBlockSize-blockOffset, end-offset)
+		resp.Ranges = append(resp.Ranges, &pb.BlockRange{
+			BlockIndex:  uint64(index),
+			BlockOffset: uint64(blockOffset),
+			Length:      uint64(length),
+			Block:       m.blocks[req.Path][index],
+		})
+		offset += length
+	}
+	return resp, nil
+}
+
+// fakeData 保存写入的块，写入时模拟流水线把数据复制给 ReplicaLocations
+type fakeData struct {
+	pb.UnimplementedDataServerServiceServer
+	corrupt bool
+	peers   map[string]*fakeData
+
+	mu     sync.Mutex
+	blocks map[uint64][]byte
+}
+
+func (d *fakeData) WriteBlock(stream pb.DataServerService_WriteBlockServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	meta := first.GetMetadata()
+	var data []byte
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		data = append(data, req.GetChunkData()...)
+	}
+	d.store(meta.BlockId, data)
+	for _, addr := range meta.ReplicaLocations {
+		d.peers[addr].store(meta.BlockId, data)
+	}
+	return stream.SendAndClose(&pb.WriteBlockResponse{Success: true})
+}
+
+func (d *fakeData) store(blockID uint64, data []byte) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.blocks[blockID] = data
+}
+
+func (d *fakeData) ReadBlock(req *pb.ReadBlockRequest, stream pb.DataServerService_ReadBlockServer) error {
+	if d.corrupt {
+		return status.Errorf(codes.DataLoss, "block %d is corrupt", req.BlockId)
+	}
+	d.mu.Lock()
+	data := d.blocks[req.BlockId]
+	d.mu.Unlock()
+
+	resp := &pb.ReadBlockResponse{ChunkData: data[req.Offset : req.Offset+req.Length]}
+	if req.Offset == 0 && req.Length >= uint64(len(data)) {
+		resp.Crc32C, resp.HasCrc32C = crc32.Checksum(data, crc32cTable), true
+	}
+	return stream.Send(resp)
+}
+
+// serve 在随机端口上启动 gRPC 服务
+func serve(t *testing.T, register func(*grpc.Server)) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	server := grpc.NewServer()
+	register(server)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	return listener.Addr().String()
+}
+
+func TestPutAndReadWithReplicaRetry(t *testing.T) {
+	// 第一个副本读取时报告损坏，读取应换到第二个副本
+	broken := &fakeData{corrupt: true, blocks: make(map[uint64][]byte)}
+	healthy := &fakeData{blocks: make(map[uint64][]byte)}
+	brokenAddr := serve(t, func(s *grpc.Server) { pb.RegisterDataServerServiceServer(s, broken) })
+	healthyAddr := serve(t, func(s *grpc.Server) { pb.RegisterDataServerServiceServer(s, healthy) })
+	peers := map[string]*fakeData{brokenAddr: broken, healthyAddr: healthy}
+	broken.peers, healthy.peers = peers, peers
+
+	leader := &fakeMeta{
+		dataAddrs: []string{brokenAddr, healthyAddr},
+		files:     make(map[string]*pb.NodeInfo),
+		blocks:    make(map[string][]*pb.BlockLocations),
+	}
+	leaderAddr := serve(t, func(s *grpc.Server) { pb.RegisterMetaServerServiceServer(s, leader) })
+	leader.leader = leaderAddr
+	// 客户端只配置了 follower，通过 GetLeader 找到 leader
+	follower := &fakeMeta{leader: leaderAddr}
+	followerAddr := serve(t, func(s *grpc.Server) { pb.RegisterMetaServerServiceServer(s, follower) })
+
+	c, err := New(Config{MetaServers: []string{followerAddr}, BlockSize: testBlockSize})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+
+	data := make([]byte, 2500)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	if err := c.Put(ctx, "/f", bytes.NewReader(data), int64(len(data)), 0); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	if addr, _ := c.Leader(ctx); addr != leaderAddr {
+		t.Errorf("leader = %s, want %s", addr, leaderAddr)
+	}
+	sum := md5.Sum(data)
+	if got := leader.files["/f"].Md5; got != hex.EncodeToString(sum[:]) {
+		t.Errorf("finalized md5 = %s, want %x", got, sum)
+	}
+	if len(healthy.blocks) != 3 {
+		t.Fatalf("healthy replica has %d blocks, want 3", len(healthy.blocks))
+	}
+
+	var out bytes.Buffer
+	if n, err := c.Get(ctx, "/f", &out); err != nil || n != int64(len(data)) {
+		t.Fatalf("get: n=%d err=%v", n, err)
+	}
+	if !bytes.Equal(out.Bytes(), data) {
+		t.Fatal("read data differs from written data")
+	}
+
+	// 从块中间开始的范围读取
+	r, err := c.Open(ctx, "/f")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if _, err := r.Seek(1500, io.SeekStart); err != nil {
+		t.Fatalf("seek: %v", err)
+	}
+	tail, err := io.ReadAll(r)
+	if err != nil || !bytes.Equal(tail, data[1500:]) {
+		t.Fatalf("read from 1500: %d bytes, err=%v", len(tail), err)
+	}
+
+	// 所有副本都不可用时返回错误
+	healthy.corrupt = true
+	if _, err := c.Get(ctx, "/f", io.Discard); err == nil {
+		t.Fatal("expected error when all replicas are corrupt")
+	}
+}
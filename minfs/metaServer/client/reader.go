@@ -0,0 +1,217 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+
+	"metaServer/pb"
+)
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// FileReader 按块读取文件，实现 io.ReadSeekCloser
+// 每次从 MetaServer 获取当前偏移所在块的范围并缓存该范围的数据，一个副本读取失败时换下一个副本
+type FileReader struct {
+	client *Client
+	ctx    context.Context
+	path   string
+	size   int64
+	offset int64
+
+	buf      []byte // 缓存的块内数据
+	bufStart int64  // buf[0] 在文件中的偏移
+}
+
+// Open 打开文件用于读取
+func (c *Client) Open(ctx context.Context, filePath string) (*FileReader, error) {
+	stat, err := c.Stat(ctx, filePath)
+	if err != nil {
+		return nil, err
+	}
+	if stat.Type != pb.FileType_File {
+		return nil, fmt.Errorf("%s is not a file", filePath)
+	}
+	return &FileReader{client: c, ctx: ctx, path: filePath, size: stat.Size}, nil
+}
+
+// Get 将整个文件写入 w，返回写入的字节数
+func (c *Client) Get(ctx context.Context, filePath string, w io.Writer) (int64, error) {
+	r, err := c.Open(ctx, filePath)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+	return io.Copy(w, r)
+}
+
+// Size 返回文件大小
+func (r *FileReader) Size() int64 {
+	return r.size
+}
+
+// Read 实现 io.Reader
+func (r *FileReader) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+	if r.offset < r.bufStart || r.offset >= r.bufStart+int64(len(r.buf)) {
+		if err := r.fill(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.buf[r.offset-r.bufStart:])
+	r.offset += int64(n)
+	return n, nil
+}
+
+// Seek 实现 io.Seeker
+func (r *FileReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return 0, fmt.Errorf("invalid whence %d", whence)
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("negative offset %d", offset)
+	}
+	r.offset = offset
+	return offset, nil
+}
+
+// Close 实现 io.Closer，连接由 Client 复用，这里只释放缓存
+func (r *FileReader) Close() error {
+	r.buf = nil
+	return nil
+}
+
+// fill 读取当前偏移到所在块末尾的数据
+func (r *FileReader) fill() error {
+	blockSize := r.client.config.BlockSize
+	length := blockSize - r.offset%blockSize
+
+	var resp *pb.GetBlockRangeResponse
+	err := r.client.call(r.ctx, func(ctx context.Context, meta pb.MetaServerServiceClient) error {
+		var err error
+		resp, err = meta.GetBlockRange(ctx, &pb.GetBlockRangeRequest{Path: r.path, Offset: r.offset, Length: length})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to locate offset %d of %s: %w", r.offset, r.path, err)
+	}
+
+	// 文件可能在打开后被覆盖
+	r.size = resp.Size
+	if r.offset >= r.size {
+		return io.EOF
+	}
+	if len(resp.Ranges) == 0 {
+		return fmt.Errorf("no block covers offset %d of %s", r.offset, r.path)
+	}
+
+	data, err := r.client.readRange(r.ctx, resp.Ranges[0])
+	if err != nil {
+		return fmt.Errorf("failed to read %s at offset %d: %w", r.path, r.offset, err)
+	}
+	if len(data) == 0 {
+		return io.ErrUnexpectedEOF
+	}
+	r.buf = data
+	r.bufStart = r.offset
+	return nil
+}
+
+// readRange 读取一个块内的范围，依次尝试每个副本直到成功；纠删码块组由任意一个条带所在的 DataServer 重建
+func (c *Client) readRange(ctx context.Context, rng *pb.BlockRange) ([]byte, error) {
+	block := rng.Block
+	if block == nil || len(block.Locations) == 0 {
+		return nil, fmt.Errorf("block %d has no locations", rng.BlockIndex)
+	}
+
+	var errs []error
+	for _, addr := range block.Locations {
+		var data []byte
+		var err error
+		if block.EcPolicy != "" {
+			data, err = c.readBlockGroup(ctx, addr, block, rng.BlockOffset, rng.Length)
+		} else {
+			data, err = c.readBlock(ctx, addr, block, rng.BlockOffset, rng.Length)
+		}
+		if err == nil {
+			return data, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", addr, err))
+	}
+	return nil, fmt.Errorf("all replicas of block %d failed: %w", block.BlockId, errors.Join(errs...))
+}
+
+// readBlock 从一个副本读取块内范围，读取整块时按 DataServer 返回的 CRC32C 校验
+func (c *Client) readBlock(ctx context.Context, addr string, block *pb.BlockLocations, offset, length uint64) ([]byte, error) {
+	data, err := c.dataClient(addr)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := data.ReadBlock(ctx, &pb.ReadBlockRequest{BlockId: block.BlockId, Offset: offset, Length: length, Token: block.Token})
+	if err != nil {
+		return nil, err
+	}
+
+	var buf []byte
+	var checksum uint32
+	var verify bool
+	for first := true; ; first = false {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if first {
+			checksum, verify = resp.Crc32C, resp.HasCrc32C
+		}
+		buf = append(buf, resp.ChunkData...)
+	}
+
+	if verify && crc32.Checksum(buf, crc32cTable) != checksum {
+		return nil, fmt.Errorf("checksum mismatch for block %d", block.BlockId)
+	}
+	if uint64(len(buf)) != length {
+		return nil, fmt.Errorf("short read of block %d: got %d of %d bytes", block.BlockId, len(buf), length)
+	}
+	return buf, nil
+}
+
+// readBlockGroup 通过 addr 读取纠删码块组内的范围
+func (c *Client) readBlockGroup(ctx context.Context, addr string, block *pb.BlockLocations, offset, length uint64) ([]byte, error) {
+	data, err := c.dataClient(addr)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := data.ReadBlockGroup(ctx, &pb.ReadBlockGroupRequest{Group: blockGroup(block), Offset: offset, Length: length})
+	if err != nil {
+		return nil, err
+	}
+
+	var buf []byte
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, resp.ChunkData...)
+	}
+	if uint64(len(buf)) != length {
+		return nil, fmt.Errorf("short read of block group %d: got %d of %d bytes", block.BlockId, len(buf), length)
+	}
+	return buf, nil
+}
@@ -0,0 +1,299 @@
+package client
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"time"
+
+	"metaServer/pb"
+)
+
+// Put 将 r 中的 size 字节写入 path，已存在的文件被整体覆盖；replication 为 0 时使用默认副本数
+// 数据按块流式发送给 DataServer，同时计算 MD5 交给 FinalizeWrite
+func (c *Client) Put(ctx context.Context, filePath string, r io.Reader, size int64, replication uint32) error {
+	if size < 0 {
+		return fmt.Errorf("invalid size %d", size)
+	}
+
+	stat, err := c.Stat(ctx, filePath)
+	exists := err == nil && stat != nil
+	if exists && stat.Type == pb.FileType_Directory {
+		return fmt.Errorf("%s is a directory", filePath)
+	}
+
+	if size == 0 {
+		return c.createEmpty(ctx, filePath, exists && stat.Size > 0, replication)
+	}
+
+	// MetaServer 把与原文件大小相同的请求视为读取，这种覆盖先写入临时文件再替换原文件
+	if exists && stat.Size == size {
+		tmpPath := path.Join(path.Dir(filePath), fmt.Sprintf(".%s.%s.tmp", path.Base(filePath), c.config.ClientName))
+		if err := c.put(ctx, tmpPath, r, size, replication); err != nil {
+			c.Remove(ctx, tmpPath, false, true)
+			return err
+		}
+		return c.Rename(ctx, tmpPath, filePath, true)
+	}
+	return c.put(ctx, filePath, r, size, replication)
+}
+
+// createEmpty 创建空文件，已有内容的文件先删除再重新创建
+func (c *Client) createEmpty(ctx context.Context, filePath string, truncate bool, replication uint32) error {
+	if truncate {
+		if err := c.Remove(ctx, filePath, false, false); err != nil {
+			return err
+		}
+	}
+	return c.call(ctx, func(ctx context.Context, meta pb.MetaServerServiceClient) error {
+		err := checkSimple(meta.CreateNode(ctx, &pb.CreateNodeRequest{Path: filePath, Type: pb.FileType_File, Replication: replication}))
+		if err != nil && !truncate {
+			// 已存在的空文件无需再创建
+			if resp, statErr := meta.GetNodeInfo(ctx, &pb.GetNodeInfoRequest{Path: filePath}); statErr == nil &&
+				resp.StatInfo.Type == pb.FileType_File && resp.StatInfo.Size == 0 {
+				return nil
+			}
+		}
+		return err
+	})
+}
+
+// put 分配块、逐块写入 DataServer 并提交文件
+func (c *Client) put(ctx context.Context, filePath string, r io.Reader, size int64, replication uint32) error {
+	var resp *pb.GetBlockLocationsResponse
+	err := c.call(ctx, func(ctx context.Context, meta pb.MetaServerServiceClient) error {
+		var err error
+		resp, err = meta.GetBlockLocations(ctx, &pb.GetBlockLocationsRequest{
+			Path:        filePath,
+			Size:        size,
+			ClientName:  c.config.ClientName,
+			Replication: replication,
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to allocate blocks for %s: %w", filePath, err)
+	}
+
+	// 按块大小切分数据，块数不一致说明客户端的 BlockSize 与 MetaServer 不同
+	expected := (size + c.config.BlockSize - 1) / c.config.BlockSize
+	if int64(len(resp.BlockLocations)) != expected {
+		return fmt.Errorf("meta server allocated %d blocks for %d bytes, expected %d with block size %d",
+			len(resp.BlockLocations), size, expected, c.config.BlockSize)
+	}
+
+	stopRenew := c.renewLease(ctx, filePath)
+	defer stopRenew()
+
+	hash := md5.New()
+	reader := io.TeeReader(r, hash)
+	remaining := size
+	for i, block := range resp.BlockLocations {
+		n := min(c.config.BlockSize, remaining)
+		if err := c.writeBlock(ctx, block, io.LimitReader(reader, n), n); err != nil {
+			return fmt.Errorf("failed to write block %d of %s: %w", i, filePath, err)
+		}
+		remaining -= n
+	}
+
+	return c.call(ctx, func(ctx context.Context, meta pb.MetaServerServiceClient) error {
+		return checkSimple(meta.FinalizeWrite(ctx, &pb.FinalizeWriteRequest{
+			Path:       filePath,
+			Inode:      resp.Inode,
+			Size:       size,
+			Md5:        hex.EncodeToString(hash.Sum(nil)),
+			ClientName: c.config.ClientName,
+		}))
+	})
+}
+
+// renewLease 写入期间定期续约写租约，返回停止续约的函数
+func (c *Client) renewLease(ctx context.Context, filePath string) func() {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(c.config.LeaseRenewInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				// 续约失败不中断写入，租约过期时 FinalizeWrite 会返回错误
+				c.call(ctx, func(ctx context.Context, meta pb.MetaServerServiceClient) error {
+					return checkSimple(meta.RenewLease(ctx, &pb.RenewLeaseRequest{ClientName: c.config.ClientName, Path: filePath}))
+				})
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// blockStream WriteBlock 和 WriteBlockGroup 的公共部分
+type blockStream struct {
+	send         func(chunk []byte) error
+	closeAndRecv func() (*pb.WriteBlockResponse, error)
+}
+
+// openBlockStream 打开块的写入流：多副本块发给第一个副本，由其沿流水线转发给其余副本；
+// 纠删码块组发给第一个条带所在的 DataServer，由其编码后写入各条带
+func (c *Client) openBlockStream(ctx context.Context, block *pb.BlockLocations) (*blockStream, error) {
+	if len(block.Locations) == 0 {
+		return nil, fmt.Errorf("block %d has no locations", block.BlockId)
+	}
+	data, err := c.dataClient(block.Locations[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if block.EcPolicy != "" {
+		stream, err := data.WriteBlockGroup(ctx)
+		if err != nil {
+			return nil, err
+		}
+		err = stream.Send(&pb.WriteBlockGroupRequest{Content: &pb.WriteBlockGroupRequest_Group{Group: blockGroup(block)}})
+		if err != nil {
+			return nil, err
+		}
+		return &blockStream{
+			send: func(chunk []byte) error {
+				return stream.Send(&pb.WriteBlockGroupRequest{Content: &pb.WriteBlockGroupRequest_ChunkData{ChunkData: chunk}})
+			},
+			closeAndRecv: stream.CloseAndRecv,
+		}, nil
+	}
+
+	stream, err := data.WriteBlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = stream.Send(&pb.WriteBlockRequest{Content: &pb.WriteBlockRequest_Metadata{Metadata: &pb.WriteBlockMetadata{
+		BlockId:          block.BlockId,
+		ReplicaLocations: block.Locations[1:],
+		Token:            block.Token,
+	}}})
+	if err != nil {
+		return nil, err
+	}
+	return &blockStream{
+		send: func(chunk []byte) error {
+			return stream.Send(&pb.WriteBlockRequest{Content: &pb.WriteBlockRequest_ChunkData{ChunkData: chunk}})
+		},
+		closeAndRecv: stream.CloseAndRecv,
+	}, nil
+}
+
+// writeBlock 将 r 中的 n 字节写入一个块
+func (c *Client) writeBlock(ctx context.Context, block *pb.BlockLocations, r io.Reader, n int64) error {
+	stream, err := c.openBlockStream(ctx, block)
+	if err != nil {
+		return err
+	}
+
+	buffer := make([]byte, chunkSize)
+	var written int64
+	for {
+		k, err := io.ReadFull(r, buffer)
+		if k > 0 {
+			if sendErr := stream.send(buffer[:k]); sendErr != nil {
+				return fmt.Errorf("failed to send data to %s: %w", block.Locations[0], sendErr)
+			}
+			written += int64(k)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read input: %w", err)
+		}
+	}
+	if written != n {
+		return fmt.Errorf("input ended after %d of %d bytes", written, n)
+	}
+
+	resp, err := stream.closeAndRecv()
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return fmt.Errorf("%s rejected block %d", block.Locations[0], block.BlockId)
+	}
+	return nil
+}
+
+// blockGroup 将纠删码块组的位置信息转换为 DataServer 的 BlockGroup
+func blockGroup(block *pb.BlockLocations) *pb.BlockGroup {
+	return &pb.BlockGroup{
+		GroupId:   block.BlockId,
+		EcPolicy:  block.EcPolicy,
+		StripeIds: block.StripeIds,
+		Locations: block.Locations,
+		Token:     block.Token,
+	}
+}
+
+// FileWriter 写入的数据先暂存在本地临时文件中，Close 时才知道文件大小并上传
+type FileWriter struct {
+	client      *Client
+	ctx         context.Context
+	path        string
+	replication uint32
+	tmp         *os.File
+	closed      bool
+}
+
+// Create 创建或覆盖文件，返回的 FileWriter 需要 Close 才会上传
+func (c *Client) Create(ctx context.Context, filePath string) (*FileWriter, error) {
+	return c.CreateWithReplication(ctx, filePath, 0)
+}
+
+// CreateWithReplication 按指定副本数创建或覆盖文件
+func (c *Client) CreateWithReplication(ctx context.Context, filePath string, replication uint32) (*FileWriter, error) {
+	tmp, err := os.CreateTemp("", "minfs-upload-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create spool file: %w", err)
+	}
+	return &FileWriter{client: c, ctx: ctx, path: filePath, replication: replication, tmp: tmp}, nil
+}
+
+// Write 实现 io.Writer
+func (w *FileWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+	return w.tmp.Write(p)
+}
+
+// Close 上传暂存的数据并删除临时文件
+func (w *FileWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	defer os.Remove(w.tmp.Name())
+	defer w.tmp.Close()
+
+	size, err := w.tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := w.tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return w.client.Put(w.ctx, w.path, w.tmp, size, w.replication)
+}
+
+// Abort 丢弃暂存的数据，不修改 MinFS 中的文件
+func (w *FileWriter) Abort() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	w.tmp.Close()
+	return os.Remove(w.tmp.Name())
+}
@@ -1,14 +1,13 @@
 package main
 
 import (
-	"context"
 	"flag"
 	"fmt"
 	"log"
 	"net"
 	"os"
 	"os/signal"
-	"sync"
+	"strings"
 	"syscall"
 	"time"
 
@@ -26,6 +25,8 @@ var (
 	port       = flag.Int("port", 9090, "gRPC server port")
 	nodeID     = flag.String("node-id", "", "MetaServer node ID (auto-generated if not provided)")
 	dataDir    = flag.String("data-dir", "", "BadgerDB data directory (overrides config)")
+	peers      = flag.String("peers", "", "Raft cluster members as id=host:port,... (overrides config)")
+	httpPort   = flag.Int("http-port", 0, "HTTP port for /metrics (overrides server.port, -1 disables)")
 )
 
 func main() {
@@ -44,6 +45,11 @@ func main() {
 		config.Server.GrpcPort = *port
 	}
 	
+	// 如果命令行指定了 HTTP 端口，覆盖配置文件；同一台机器上运行多个节点时需要分别指定
+	if *httpPort != 0 {
+		config.Server.Port = *httpPort
+	}
+	
 	// 如果命令行指定了数据目录，覆盖配置文件
 	if *dataDir != "" {
 		config.Database.BadgerDir = *dataDir
@@ -57,6 +63,15 @@ func main() {
 		currentNodeID = fmt.Sprintf("metaServer-%d", config.Server.GrpcPort)
 	}
 
+	// 如果命令行指定了集群成员，覆盖配置文件
+	if *peers != "" {
+		raftPeers, err := parsePeers(*peers)
+		if err != nil {
+			log.Fatalf("Invalid -peers: %v", err)
+		}
+		config.Raft.Peers = raftPeers
+	}
+
 	log.Printf("Configuration loaded: NodeID=%s, gRPC port=%d, BadgerDB dir=%s", 
 		currentNodeID, config.Server.GrpcPort, config.Database.BadgerDir)
 
@@ -83,57 +98,48 @@ func main() {
 	metadataService := service.NewMetadataService(db, config, walService)
 	clusterService := service.NewClusterService(config)
 	schedulerService := service.NewSchedulerService(config, clusterService, metadataService)
+	leaseManager := service.NewLeaseManager(config, metadataService, clusterService)
+	leaseManager.Start()
+	schedulerService.SetLeaseManager(leaseManager) // 正在写入的文件不做纠删码转换
+	balancer := service.NewBalancer(config, clusterService, metadataService, schedulerService)
+	schedulerService.SetBalancer(balancer) // FSCK 跳过正在迁移的块
+	metrics := service.NewMetrics()
+	schedulerService.SetMetrics(metrics) // FSCK 和 GC 结果上报到 /metrics
+	metrics.RegisterScheduler(schedulerService)
 	
-	// 初始化Leader Election服务
+	// 初始化Raft节点，元数据变更经多数节点持久化后才返回成功
 	nodeAddr := fmt.Sprintf("localhost:%d", config.Server.GrpcPort)
-	leaderElection, err := service.NewLeaderElection(config, currentNodeID, nodeAddr)
+	raftNode, err := service.NewRaftNode(config, currentNodeID, nodeAddr, db, walService, metadataService, nil)
 	if err != nil {
-		log.Fatalf("Failed to initialize leader election: %v", err)
+		log.Fatalf("Failed to initialize raft node: %v", err)
 	}
-	
-	// 设置Leader Election和WAL服务的相互引用
-	leaderElection.SetWALService(walService)
-	walService.SetLeaderElection(leaderElection)
-	clusterService.SetLeaderElection(leaderElection) // 传递选举服务
+	walService.SetRaftNode(raftNode)
+	metadataService.SetRaftNode(raftNode)
+	clusterService.SetRaftNode(raftNode)
+	metrics.RegisterRaft(raftNode)
 	
 	log.Printf("Node %s initialized and ready to start", currentNodeID)
-	
-	// 先设置所有回调函数
-	// 设置leader变化回调
-	leaderElection.SetLeaderChangeCallback(func(isLeader bool) {
-		if isLeader {
-			log.Printf("Node %s became leader, replaying WAL logs", currentNodeID)
-			if err := replayWALLogs(walService, metadataService); err != nil {
-				log.Printf("Warning: Failed to replay WAL logs: %v", err)
-			}
-		}
-	})
-	
-	// 设置WAL同步回调（当发现新leader时触发）
-	leaderElection.SetWALSyncCallback(func() {
-		log.Printf("Node %s triggered WAL sync from leader", currentNodeID)
-		if err := requestWALSyncFromLeader(walService, leaderElection, currentNodeID); err != nil {
-			log.Printf("Warning: Failed to sync WAL from leader: %v", err)
-		} else {
-			leaderElection.MarkWALSyncCompleted()
-			log.Printf("Node %s completed WAL sync from leader", currentNodeID)
-		}
-	})
-	
-	// 简化启动逻辑：所有节点都以follower模式启动
-	// RegisterAsFollower会自动参与选举，如果没有leader会自动成为leader
-	log.Printf("Node %s starting in follower mode (will auto-elect if no leader)", currentNodeID)
-	
-	if err := leaderElection.RegisterAsFollower(); err != nil {
-		log.Fatalf("Failed to register as follower: %v", err)
-	}
 
 	// 初始化 gRPC Handler
 	metaHandler := handler.NewMetaServerHandler(metadataService, clusterService, schedulerService)
 	metaHandler.SetWALService(walService) // 设置WAL服务
+	metaHandler.SetLeaseManager(leaseManager) // 设置写租约管理器
+	metaHandler.SetRaftNode(raftNode)         // 设置Raft节点
+	metaHandler.SetBalancer(balancer)         // 设置均衡器
+	metaHandler.SetBlockTokenManager(service.NewBlockTokenManager(config.Security.BlockTokenKey, config.Security.BlockTokenLifetime))
+
+	// 开启 TLS 时使用证书，client_auth 开启时要求客户端证书
+	serverOptions, err := service.ServerOptions(config)
+	if err != nil {
+		log.Fatalf("Failed to load TLS credentials: %v", err)
+	}
 
-	// 启动 gRPC 服务器
-	grpcServer := grpc.NewServer()
+	// 启动 gRPC 服务器，记录每个方法的耗时
+	serverOptions = append(serverOptions,
+		grpc.ChainUnaryInterceptor(metrics.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(metrics.StreamServerInterceptor()),
+	)
+	grpcServer := grpc.NewServer(serverOptions...)
 	pb.RegisterMetaServerServiceServer(grpcServer, metaHandler)
 
 	// 监听端口
@@ -151,6 +157,15 @@ func main() {
 		}
 	}()
 
+	// 注册到 etcd，客户端可以从 /minfs/metaServers/ 发现所有 MetaServer
+	clusterService.RegisterMetaServer(currentNodeID, nodeAddr)
+
+	// 在 server.port 上暴露 /metrics
+	metricsServer := metrics.Serve(config.Server.Port)
+
+	// 开始参与选举和日志复制
+	raftNode.Start()
+
 	// 等待信号来优雅关闭
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -162,44 +177,24 @@ func main() {
 	// 优雅关闭
 	log.Println("Shutting down MetaServer...")
 	
-	// 使用WaitGroup和超时机制保护关闭流程
-	var wg sync.WaitGroup
 	shutdownTimeout := 30 * time.Second
 	shutdownComplete := make(chan struct{})
 	
 	go func() {
 		defer close(shutdownComplete)
 		
-		// 主动注销etcd注册信息 (带超时保护)
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			if err := gracefulDeregisterFromETCD(leaderElection, currentNodeID); err != nil {
-				log.Printf("Failed to deregister from etcd: %v", err)
-			}
-		}()
-		
-		// 等待etcd注销完成或超时
-		etcdDone := make(chan struct{})
-		go func() {
-			wg.Wait()
-			close(etcdDone)
-		}()
-		
-		select {
-		case <-etcdDone:
-			log.Println("Etcd deregistration completed")
-		case <-time.After(10 * time.Second):
-			log.Println("Etcd deregistration timeout, proceeding with shutdown")
-		}
-		
 		// 停止 gRPC 服务器
 		grpcServer.GracefulStop()
+		if metricsServer != nil {
+			metricsServer.Close()
+		}
 		
 		// 停止后台服务
+		balancer.Stop()
 		schedulerService.Stop()
+		leaseManager.Stop()
 		clusterService.Stop()
-		leaderElection.Stop()
+		raftNode.Stop()
 		walService.Close()
 	}()
 	
@@ -213,79 +208,21 @@ func main() {
 	}
 }
 
-// checkExistingLeader 检查是否已存在leader（直接从etcd查询，避免竞态条件）
-func checkExistingLeader(leaderElection *service.LeaderElection) (bool, error) {
-	log.Println("Checking for existing leader directly from etcd...")
-	
-	// 直接从etcd查询，不依赖本地缓存
-	hasLeader := leaderElection.CheckLeaderDirectly()
-	if hasLeader {
-		log.Println("Found existing leader in etcd")
-		return true, nil
-	}
-	
-	log.Println("No existing leader found in etcd")
-	return false, nil
-}
-
-// requestWALSyncFromLeader 从leader请求WAL同步
-func requestWALSyncFromLeader(walService *service.WALService, leaderElection *service.LeaderElection, nodeID string) error {
-	log.Println("Requesting WAL sync from leader...")
-	
-	// 获取当前leader信息
-	leaderInfo := leaderElection.GetCurrentLeader()
-	if leaderInfo == nil {
-		return fmt.Errorf("no leader available")
-	}
-	
-	leaderAddr := fmt.Sprintf("%s:%d", leaderInfo.Host, leaderInfo.Port)
-	
-	// 获取本地最后的日志索引
-	lastLogIndex := walService.GetCurrentLogIndex()
-	
-	log.Printf("Requesting WAL sync from leader %s, last local index: %d", leaderAddr, lastLogIndex)
-	
-	// 请求WAL同步
-	err := walService.RequestWALSyncFromLeader(leaderAddr, nodeID, lastLogIndex)
-	if err != nil {
-		return fmt.Errorf("failed to sync WAL from leader: %v", err)
-	}
-	
-	log.Println("WAL sync from leader completed successfully")
-	return nil
-}
-
-// replayWALLogs 回放WAL日志
-func replayWALLogs(walService *service.WALService, metadataService *service.MetadataService) error {
-	log.Println("Starting WAL replay...")
-	
-	// 获取当前最大日志索引
-	currentIndex := walService.GetCurrentLogIndex()
-	if currentIndex == 0 {
-		log.Println("No WAL entries to replay")
-		return nil
-	}
-	
-	// 从索引1开始回放所有日志
-	entries, err := walService.GetLogEntriesFrom(1, int(currentIndex))
-	if err != nil {
-		return fmt.Errorf("failed to get WAL entries: %v", err)
-	}
-	
-	replayedCount := 0
-	for _, entry := range entries {
-		err := walService.ReplayLogEntry(entry, metadataService)
-		if err != nil {
-			log.Printf("Failed to replay WAL entry %d: %v", entry.LogIndex, err)
-			// 继续回放其他条目，不要因为一个失败就停止
+// parsePeers 解析 id=host:port,... 格式的集群成员列表
+func parsePeers(value string) ([]model.RaftPeer, error) {
+	var raftPeers []model.RaftPeer
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
 			continue
 		}
-		replayedCount++
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("expected id=host:port, got %q", item)
+		}
+		raftPeers = append(raftPeers, model.RaftPeer{ID: parts[0], Addr: parts[1]})
 	}
-	
-	log.Printf("WAL replay completed: %d/%d entries replayed successfully", 
-		replayedCount, len(entries))
-	return nil
+	return raftPeers, nil
 }
 
 // initBadgerDB 初始化 BadgerDB
@@ -298,6 +235,7 @@ func initBadgerDB(dbDir string) (*badger.DB, error) {
 	// BadgerDB 选项
 	opts := badger.DefaultOptions(dbDir)
 	opts.Logger = nil // 禁用 BadgerDB 的日志输出
+	opts.SyncWrites = true // Raft 日志和投票必须落盘后才能应答
 
 	db, err := badger.Open(opts)
 	if err != nil {
@@ -316,13 +254,13 @@ func initRootDirectory(db *badger.DB, config *model.Config) error {
 	// 检查根目录是否存在
 	_, err := metadataService.GetNodeInfo("/")
 	if err == nil {
-		// 根目录已存在
+		// 根目录已存在，旧版本的数据需要重建目录使用量
 		log.Println("Root directory already exists")
-		return nil
+		return metadataService.RebuildUsageIfMissing()
 	}
 
-	// 创建根目录
-	err = metadataService.CreateNode("/", pb.FileType_Directory)
+	// 创建根目录，每个节点在空库上得到相同的 inode，不经过日志；根目录没有属主，超级用户可通过 Chown/Chmod 设置
+	err = metadataService.CreateNodeWithInode("/", pb.FileType_Directory, nil, 0, model.Ownership{}, time.Now().UnixMilli())
 	if err != nil {
 		return fmt.Errorf("failed to create root directory: %v", err)
 	}
@@ -330,78 +268,3 @@ func initRootDirectory(db *badger.DB, config *model.Config) error {
 	log.Println("Root directory created successfully")
 	return nil
 }
-
-// gracefulDeregisterFromETCD 优雅地从etcd注销服务信息 (带保护机制)
-func gracefulDeregisterFromETCD(leaderElection *service.LeaderElection, nodeID string) error {
-	log.Printf("Attempting to gracefully deregister MetaServer %s from etcd...", nodeID)
-	
-	// 使用sync.Once确保只执行一次
-	var once sync.Once
-	var finalErr error
-	
-	once.Do(func() {
-		// 设置总体超时
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-		
-		// 获取etcd客户端
-		etcdClient, err := leaderElection.GetETCDClient()
-		if err != nil {
-			finalErr = fmt.Errorf("failed to get etcd client: %v", err)
-			return
-		}
-		
-		// 删除节点注册信息 (带重试机制)
-		nodeKey := fmt.Sprintf("/minfs/metaServer/nodes/%s", nodeID)
-		retryCount := 3
-		
-		for i := 0; i < retryCount; i++ {
-			if ctx.Err() != nil {
-				finalErr = fmt.Errorf("context timeout during node deletion")
-				return
-			}
-			
-			if _, err := etcdClient.Delete(ctx, nodeKey); err != nil {
-				log.Printf("Failed to delete node key %s (attempt %d/%d): %v", nodeKey, i+1, retryCount, err)
-				if i == retryCount-1 {
-					log.Printf("All retry attempts failed for node key deletion")
-				} else {
-					time.Sleep(time.Duration(i+1) * time.Second) // 指数退避
-				}
-			} else {
-				log.Printf("Successfully deleted node key: %s", nodeKey)
-				break
-			}
-		}
-		
-		// 如果是leader，主动resign (带超时保护)
-		if leaderElection.IsLeader() {
-			log.Printf("Node %s is leader, resigning from leadership...", nodeID)
-			resignDone := make(chan error, 1)
-			
-			go func() {
-				resignDone <- leaderElection.Resign()
-			}()
-			
-			select {
-			case err := <-resignDone:
-				if err != nil {
-					log.Printf("Failed to resign leadership: %v", err)
-				} else {
-					log.Printf("Successfully resigned from leadership")
-				}
-			case <-time.After(5 * time.Second):
-				log.Printf("Leadership resignation timeout")
-			case <-ctx.Done():
-				log.Printf("Context timeout during leadership resignation")
-			}
-		}
-	})
-	
-	if finalErr != nil {
-		return finalErr
-	}
-	
-	log.Printf("MetaServer %s successfully deregistered from etcd", nodeID)
-	return nil
-}
\ No newline at end of file
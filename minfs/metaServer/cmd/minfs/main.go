@@ -0,0 +1,458 @@
+// minfs MinFS 命令行工具
+//
+//	minfs [全局参数] <命令> [参数]
+//
+// 命令: ls, put, get, rm, mkdir, stat, fsck, cluster, mount
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"metaServer/client"
+	"metaServer/fusefs"
+	"metaServer/pb"
+)
+
+var (
+	metaServers = flag.String("meta", "localhost:9090", "Comma-separated MetaServer addresses, any node works")
+	etcd        = flag.String("etcd", "", "Comma-separated etcd endpoints to discover MetaServers")
+	user        = flag.String("user", os.Getenv("USER"), "User name sent to the MetaServer for permission checks")
+	groups      = flag.String("groups", "", "Comma-separated groups of -user")
+	blockSize   = flag.Int64("block-size", client.DefaultBlockSize, "Block size, must match scheduler.block_size of the MetaServer")
+	caFile      = flag.String("tls-ca", "", "CA certificate, enables TLS")
+	certFile    = flag.String("tls-cert", "", "Client certificate for mTLS")
+	keyFile     = flag.String("tls-key", "", "Client private key for mTLS")
+)
+
+// commands 子命令及用法
+var commands = []struct {
+	name  string
+	usage string
+}{
+	{"ls", "ls [path]"},
+	{"put", "put [-replication n] <local|-> <remote>"},
+	{"get", "get <remote> <local|->"},
+	{"rm", "rm [-r] [-skip-trash] <path>"},
+	{"mkdir", "mkdir <path>"},
+	{"stat", "stat <path>"},
+	{"fsck", "fsck [-under-replicated] [-missing] [-corrupt] [-trigger] [path]"},
+	{"cluster", "cluster"},
+	{"mount", "mount [-skip-trash] [-debug] <mountpoint>"},
+}
+
+// handlers 子命令的实现
+var handlers = map[string]func(ctx context.Context, c *client.Client, args []string) error{
+	"ls":      runLs,
+	"put":     runPut,
+	"get":     runGet,
+	"rm":      runRm,
+	"mkdir":   runMkdir,
+	"stat":    runStat,
+	"fsck":    runFsck,
+	"cluster": runCluster,
+	"mount":   runMount,
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: minfs [flags] <command> [args]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %s\n", cmd.usage)
+	}
+	fmt.Fprintf(os.Stderr, "\nFlags:\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	name, args := flag.Arg(0), flag.Args()[1:]
+	run, exists := handlers[name]
+	if !exists {
+		fmt.Fprintf(os.Stderr, "minfs: unknown command %q\n", name)
+		usage()
+		os.Exit(2)
+	}
+
+	c, err := newClient()
+	if err != nil {
+		fatal(err)
+	}
+	defer c.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := run(ctx, c, args); err != nil {
+		fatal(err)
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "minfs: %v\n", err)
+	os.Exit(1)
+}
+
+// splitList 解析逗号分隔的列表，忽略空项
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// newClient 按全局参数创建客户端
+func newClient() (*client.Client, error) {
+	config := client.Config{
+		MetaServers:   splitList(*metaServers),
+		EtcdEndpoints: splitList(*etcd),
+		User:          *user,
+		Groups:        splitList(*groups),
+		BlockSize:     *blockSize,
+	}
+
+	if *caFile != "" || *certFile != "" {
+		tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+		if *caFile != "" {
+			data, err := os.ReadFile(*caFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read tls ca file: %v", err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(data) {
+				return nil, fmt.Errorf("no certificates found in %s", *caFile)
+			}
+			tlsConfig.RootCAs = pool
+		}
+		if *certFile != "" {
+			cert, err := tls.LoadX509KeyPair(*certFile, *keyFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load tls key pair: %v", err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+		config.TLS = tlsConfig
+	}
+	return client.New(config)
+}
+
+// parseArgs 解析子命令参数并检查位置参数的个数
+func parseArgs(fs *flag.FlagSet, args []string, minArgs, maxArgs int) ([]string, error) {
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() < minArgs || fs.NArg() > maxArgs {
+		return nil, fmt.Errorf("usage: minfs %s", usageOf(fs.Name()))
+	}
+	return fs.Args(), nil
+}
+
+func usageOf(name string) string {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd.usage
+		}
+	}
+	return name
+}
+
+// formatMode 按 ls -l 的格式输出类型和权限位
+func formatMode(stat *pb.StatInfo) string {
+	var b strings.Builder
+	if stat.Type == pb.FileType_Directory {
+		b.WriteByte('d')
+	} else {
+		b.WriteByte('-')
+	}
+	const rwx = "rwx"
+	for i := 8; i >= 0; i-- {
+		if stat.Mode&(1<<uint(i)) != 0 {
+			b.WriteByte(rwx[(8-i)%3])
+		} else {
+			b.WriteByte('-')
+		}
+	}
+	return b.String()
+}
+
+func formatTime(millis int64) string {
+	return time.UnixMilli(millis).Format("2006-01-02 15:04")
+}
+
+func runLs(ctx context.Context, c *client.Client, args []string) error {
+	fs := flag.NewFlagSet("ls", flag.ContinueOnError)
+	rest, err := parseArgs(fs, args, 0, 1)
+	if err != nil {
+		return err
+	}
+	path := "/"
+	if len(rest) == 1 {
+		path = rest[0]
+	}
+
+	stat, err := c.Stat(ctx, path)
+	if err != nil {
+		return err
+	}
+	if stat.Type != pb.FileType_Directory {
+		printEntry(stat)
+		return nil
+	}
+	return c.List(ctx, path, func(stat *pb.StatInfo) error {
+		printEntry(stat)
+		return nil
+	})
+}
+
+func printEntry(stat *pb.StatInfo) {
+	fmt.Printf("%s %-8s %-8s %12d %s %s\n", formatMode(stat), stat.Owner, stat.Group, stat.Size, formatTime(stat.Mtime), stat.Path)
+}
+
+func runPut(ctx context.Context, c *client.Client, args []string) error {
+	fs := flag.NewFlagSet("put", flag.ContinueOnError)
+	replication := fs.Uint("replication", 0, "Replication factor, 0 uses the server default")
+	rest, err := parseArgs(fs, args, 2, 2)
+	if err != nil {
+		return err
+	}
+	local, remote := rest[0], rest[1]
+
+	// 已知大小的本地文件直接流式上传，标准输入先暂存到本地再上传
+	if local != "-" {
+		f, err := os.Open(local)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		return c.Put(ctx, remote, f, info.Size(), uint32(*replication))
+	}
+
+	w, err := c.CreateWithReplication(ctx, remote, uint32(*replication))
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, os.Stdin); err != nil {
+		w.Abort()
+		return err
+	}
+	return w.Close()
+}
+
+func runGet(ctx context.Context, c *client.Client, args []string) error {
+	fs := flag.NewFlagSet("get", flag.ContinueOnError)
+	rest, err := parseArgs(fs, args, 2, 2)
+	if err != nil {
+		return err
+	}
+	remote, local := rest[0], rest[1]
+
+	if local == "-" {
+		_, err := c.Get(ctx, remote, os.Stdout)
+		return err
+	}
+
+	// 先写入临时文件，读取失败时不留下不完整的文件
+	tmp := local + ".minfs-tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := c.Get(ctx, remote, f); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, local)
+}
+
+func runRm(ctx context.Context, c *client.Client, args []string) error {
+	fs := flag.NewFlagSet("rm", flag.ContinueOnError)
+	recursive := fs.Bool("r", false, "Remove directories and their contents")
+	skipTrash := fs.Bool("skip-trash", false, "Delete immediately instead of moving to /.Trash")
+	rest, err := parseArgs(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	return c.Remove(ctx, rest[0], *recursive, *skipTrash)
+}
+
+func runMkdir(ctx context.Context, c *client.Client, args []string) error {
+	fs := flag.NewFlagSet("mkdir", flag.ContinueOnError)
+	rest, err := parseArgs(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	return c.Mkdir(ctx, rest[0])
+}
+
+func runStat(ctx context.Context, c *client.Client, args []string) error {
+	fs := flag.NewFlagSet("stat", flag.ContinueOnError)
+	rest, err := parseArgs(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	stat, err := c.Stat(ctx, rest[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Path:     %s\n", stat.Path)
+	fmt.Printf("Type:     %s\n", strings.ToLower(stat.Type.String()))
+	fmt.Printf("Size:     %d\n", stat.Size)
+	fmt.Printf("Modified: %s\n", formatTime(stat.Mtime))
+	fmt.Printf("Mode:     %s (%04o)\n", formatMode(stat), stat.Mode)
+	fmt.Printf("Owner:    %s:%s\n", stat.Owner, stat.Group)
+	for _, entry := range stat.Acl {
+		fmt.Printf("ACL:      %s:%s:%o\n", entry.Type, entry.Name, entry.Perm)
+	}
+	if stat.Type == pb.FileType_Directory {
+		fmt.Printf("Children: %d\n", stat.ChildCount)
+	} else {
+		if stat.Md5 != "" {
+			fmt.Printf("MD5:      %s\n", stat.Md5)
+		}
+		for _, replica := range stat.ReplicaData {
+			fmt.Printf("Replica:  %s %s\n", replica.DsNode, replica.Path)
+		}
+	}
+	return nil
+}
+
+func runFsck(ctx context.Context, c *client.Client, args []string) error {
+	fs := flag.NewFlagSet("fsck", flag.ContinueOnError)
+	underReplicated := fs.Bool("under-replicated", false, "Only list under-replicated blocks")
+	missing := fs.Bool("missing", false, "Only list blocks without live replicas")
+	corrupt := fs.Bool("corrupt", false, "Only list blocks with corrupt replicas")
+	trigger := fs.Bool("trigger", false, "Start an FSCK repair pass for the path after reporting")
+	rest, err := parseArgs(fs, args, 0, 1)
+	if err != nil {
+		return err
+	}
+	req := &pb.FsckRequest{
+		Path:                "/",
+		OnlyUnderReplicated: *underReplicated,
+		OnlyMissing:         *missing,
+		OnlyCorrupt:         *corrupt,
+		TriggerFsck:         *trigger,
+	}
+	if len(rest) == 1 {
+		req.Path = rest[0]
+	}
+
+	summary, err := c.Fsck(ctx, req, func(block *pb.FsckBlock) error {
+		var states []string
+		if block.Missing {
+			states = append(states, "MISSING")
+		} else if block.UnderReplicated {
+			states = append(states, "UNDER_REPLICATED")
+		}
+		if block.Corrupt {
+			states = append(states, "CORRUPT")
+		}
+		if len(states) == 0 {
+			states = append(states, "HEALTHY")
+		}
+		path := block.Path
+		if path == "" {
+			path = "(snapshot)"
+		}
+		fmt.Printf("%s block=%d live=%d/%d [%s] %s\n", path, block.BlockId,
+			len(block.LiveLocations), len(block.ExpectedLocations), strings.Join(block.LiveLocations, ","), strings.Join(states, ","))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\nFiles:            %d\n", summary.TotalFiles)
+	fmt.Printf("Blocks:           %d\n", summary.TotalBlocks)
+	fmt.Printf("Healthy:          %d\n", summary.HealthyBlocks)
+	fmt.Printf("Under-replicated: %d\n", summary.UnderReplicatedBlocks)
+	fmt.Printf("Missing:          %d\n", summary.MissingBlocks)
+	fmt.Printf("Corrupt:          %d\n", summary.CorruptBlocks)
+	if summary.OrphanBlocks >= 0 {
+		fmt.Printf("Orphans:          %d\n", summary.OrphanBlocks)
+	}
+	if summary.FsckTriggered {
+		fmt.Printf("FSCK triggered for %s\n", req.Path)
+	}
+	return nil
+}
+
+func runCluster(ctx context.Context, c *client.Client, args []string) error {
+	fs := flag.NewFlagSet("cluster", flag.ContinueOnError)
+	if _, err := parseArgs(fs, args, 0, 0); err != nil {
+		return err
+	}
+	info, err := c.ClusterInfo(ctx)
+	if err != nil {
+		return err
+	}
+
+	if leader := info.MasterMetaServer; leader != nil {
+		fmt.Printf("MetaServer %s:%d (leader)\n", leader.Host, leader.Port)
+	}
+	for _, follower := range info.SlaveMetaServer {
+		fmt.Printf("MetaServer %s:%d\n", follower.Host, follower.Port)
+	}
+	for _, ds := range info.DataServer {
+		fmt.Printf("DataServer %s:%d files=%d used=%dMB/%dMB %s\n",
+			ds.Host, ds.Port, ds.FileTotal, ds.UseCapacity, ds.Capacity, ds.Topology)
+	}
+	return nil
+}
+
+func runMount(ctx context.Context, c *client.Client, args []string) error {
+	fs := flag.NewFlagSet("mount", flag.ContinueOnError)
+	skipTrash := fs.Bool("skip-trash", false, "Delete immediately instead of moving to /.Trash")
+	debug := fs.Bool("debug", false, "Log every FUSE request")
+	rest, err := parseArgs(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	mountpoint := rest[0]
+
+	// 挂载前确认能找到 leader
+	if _, err := c.Leader(ctx); err != nil {
+		return err
+	}
+	server, err := fusefs.Mount(mountpoint, fusefs.New(c, fusefs.Options{SkipTrash: *skipTrash}), *debug)
+	if err != nil {
+		return fmt.Errorf("failed to mount %s: %v", mountpoint, err)
+	}
+	fmt.Fprintf(os.Stderr, "minfs mounted on %s, interrupt or fusermount -u to unmount\n", mountpoint)
+
+	// 收到信号时卸载；外部卸载时 Wait 直接返回
+	go func() {
+		<-ctx.Done()
+		if err := server.Unmount(); err != nil {
+			fmt.Fprintf(os.Stderr, "minfs: failed to unmount %s: %v\n", mountpoint, err)
+		}
+	}()
+	server.Wait()
+	return nil
+}
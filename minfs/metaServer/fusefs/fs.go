@@ -0,0 +1,303 @@
+// Package fusefs 将 MinFS 挂载为本地目录
+// FileSystem 按路径把文件系统操作映射为 MetaServerService 和 DataServerService 的调用，
+// node.go 中的 go-fuse 节点只负责路径和属性的转换
+package fusefs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"sync"
+	"syscall"
+
+	"metaServer/client"
+	"metaServer/pb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Options 挂载选项
+type Options struct {
+	SkipTrash bool // 删除时跳过回收站直接删除
+}
+
+// FileSystem 基于路径的文件系统操作，错误转换为 errno
+type FileSystem struct {
+	client  *client.Client
+	options Options
+	ctx     context.Context // 打开的文件在多个请求间使用，不能使用单个请求的 context
+
+	mu      sync.Mutex
+	writers map[string]*Handle // 正在写入的文件，属性中的大小包含未提交的数据
+}
+
+// New 创建文件系统
+func New(c *client.Client, options Options) *FileSystem {
+	return &FileSystem{
+		client:  c,
+		options: options,
+		ctx:     context.Background(),
+		writers: make(map[string]*Handle),
+	}
+}
+
+// toErrno 按 MetaServer 的错误信息转换为 errno
+func toErrno(err error) syscall.Errno {
+	if err == nil {
+		return 0
+	}
+	if errors.Is(err, context.Canceled) || status.Code(err) == codes.Canceled {
+		return syscall.EINTR
+	}
+	if status.Code(err) == codes.NotFound {
+		return syscall.ENOENT
+	}
+
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found") || strings.Contains(msg, "does not exist"):
+		return syscall.ENOENT
+	case strings.Contains(msg, "already exists"):
+		return syscall.EEXIST
+	case strings.Contains(msg, "permission denied"):
+		return syscall.EACCES
+	case strings.Contains(msg, "directory not empty"):
+		return syscall.ENOTEMPTY
+	case strings.Contains(msg, "not a directory"):
+		return syscall.ENOTDIR
+	case strings.Contains(msg, "is not a file") || strings.Contains(msg, "for directory"):
+		return syscall.EISDIR
+	case strings.Contains(msg, "quota exceeded"):
+		return syscall.EDQUOT
+	case strings.Contains(msg, "read-only"):
+		return syscall.EROFS
+	case strings.Contains(msg, "is being written by"):
+		return syscall.EBUSY
+	}
+	return syscall.EIO
+}
+
+// Getattr 获取属性，正在写入的文件返回包含未提交数据的大小
+func (fsys *FileSystem) Getattr(ctx context.Context, p string) (*pb.StatInfo, syscall.Errno) {
+	stat, err := fsys.client.Stat(ctx, p)
+	if err != nil {
+		return nil, toErrno(err)
+	}
+
+	fsys.mu.Lock()
+	h := fsys.writers[p]
+	fsys.mu.Unlock()
+	if h != nil {
+		if size := h.Size(); size > stat.Size {
+			stat.Size = size
+		}
+	}
+	return stat, 0
+}
+
+// Readdir 列出目录
+func (fsys *FileSystem) Readdir(ctx context.Context, p string) ([]*pb.StatInfo, syscall.Errno) {
+	var entries []*pb.StatInfo
+	err := fsys.client.List(ctx, p, func(stat *pb.StatInfo) error {
+		entries = append(entries, stat)
+		return nil
+	})
+	if err != nil {
+		return nil, toErrno(err)
+	}
+	return entries, 0
+}
+
+// Mkdir 创建目录
+func (fsys *FileSystem) Mkdir(ctx context.Context, p string, mode uint32) (*pb.StatInfo, syscall.Errno) {
+	if err := fsys.client.MkdirWithMode(ctx, p, mode&07777); err != nil {
+		return nil, toErrno(err)
+	}
+	return fsys.Getattr(ctx, p)
+}
+
+// Create 创建空文件并打开用于写入
+func (fsys *FileSystem) Create(ctx context.Context, p string, mode uint32) (*Handle, *pb.StatInfo, syscall.Errno) {
+	if err := fsys.client.CreateFile(ctx, p, mode&07777); err != nil {
+		return nil, nil, toErrno(err)
+	}
+	stat, errno := fsys.Getattr(ctx, p)
+	if errno != 0 {
+		return nil, nil, errno
+	}
+	h, errno := fsys.openWriter(p)
+	return h, stat, errno
+}
+
+// Open 打开文件：只读时按需读取；写入时带 O_TRUNC 先清空文件，只支持从文件末尾顺序写入
+func (fsys *FileSystem) Open(ctx context.Context, p string, flags uint32) (*Handle, syscall.Errno) {
+	if flags&syscall.O_ACCMODE == syscall.O_RDONLY {
+		return &Handle{fsys: fsys, path: p}, 0
+	}
+	if flags&syscall.O_TRUNC != 0 {
+		if errno := fsys.Truncate(ctx, p, 0); errno != 0 {
+			return nil, errno
+		}
+	}
+	return fsys.openWriter(p)
+}
+
+// openWriter 打开追加写入并登记为正在写入的文件
+func (fsys *FileSystem) openWriter(p string) (*Handle, syscall.Errno) {
+	w, err := fsys.client.OpenAppend(fsys.ctx, p)
+	if err != nil {
+		return nil, toErrno(err)
+	}
+	h := &Handle{fsys: fsys, path: p, writer: w}
+
+	fsys.mu.Lock()
+	fsys.writers[p] = h
+	fsys.mu.Unlock()
+	return h, 0
+}
+
+// Unlink 删除文件
+func (fsys *FileSystem) Unlink(ctx context.Context, p string) syscall.Errno {
+	return toErrno(fsys.client.Remove(ctx, p, false, fsys.options.SkipTrash))
+}
+
+// Rmdir 删除空目录
+func (fsys *FileSystem) Rmdir(ctx context.Context, p string) syscall.Errno {
+	return toErrno(fsys.client.Remove(ctx, p, false, fsys.options.SkipTrash))
+}
+
+// Rename 重命名，目标已存在时按 POSIX 语义覆盖，noReplace 时不覆盖
+func (fsys *FileSystem) Rename(ctx context.Context, src, dst string, noReplace bool) syscall.Errno {
+	return toErrno(fsys.client.Rename(ctx, src, dst, !noReplace))
+}
+
+// Truncate 修改文件大小，只支持保持原大小和截断为空文件
+// 截断时删除原文件后按原权限位重新创建，属主和副本数恢复为默认值
+func (fsys *FileSystem) Truncate(ctx context.Context, p string, size int64) syscall.Errno {
+	stat, errno := fsys.Getattr(ctx, p)
+	if errno != 0 {
+		return errno
+	}
+	if stat.Type != pb.FileType_File {
+		return syscall.EISDIR
+	}
+	if stat.Size == size {
+		return 0
+	}
+	if size != 0 {
+		return syscall.ENOTSUP
+	}
+	if err := fsys.client.Remove(ctx, p, false, true); err != nil {
+		return toErrno(err)
+	}
+	return toErrno(fsys.client.CreateFile(ctx, p, stat.Mode))
+}
+
+// Chmod 修改权限位
+func (fsys *FileSystem) Chmod(ctx context.Context, p string, mode uint32) syscall.Errno {
+	return toErrno(fsys.client.Chmod(ctx, p, mode&07777))
+}
+
+// Handle 打开的文件
+type Handle struct {
+	fsys *FileSystem
+	path string
+
+	mu     sync.Mutex
+	reader *client.FileReader   // 第一次读取时打开，写入后重新打开
+	writer *client.AppendWriter // 只读打开时为 nil
+}
+
+// Size 返回写入中的文件大小，只读打开时为 0
+func (h *Handle) Size() int64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.writer == nil {
+		return 0
+	}
+	return h.writer.Size()
+}
+
+// Read 从 off 读取数据填充 dest，读取前先提交本句柄缓存的写入
+func (h *Handle) Read(ctx context.Context, dest []byte, off int64) (int, syscall.Errno) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.writer != nil {
+		if err := h.writer.Flush(); err != nil {
+			return 0, toErrno(err)
+		}
+	}
+	if h.reader == nil || off >= h.reader.Size() {
+		reader, err := h.fsys.client.Open(h.fsys.ctx, h.path)
+		if err != nil {
+			return 0, toErrno(err)
+		}
+		h.reader = reader
+	}
+	if off >= h.reader.Size() {
+		return 0, 0
+	}
+
+	if _, err := h.reader.Seek(off, io.SeekStart); err != nil {
+		return 0, syscall.EINVAL
+	}
+	n, err := io.ReadFull(h.reader, dest)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return n, toErrno(err)
+	}
+	return n, 0
+}
+
+// Write 在 off 写入数据，off 必须等于文件当前的末尾
+func (h *Handle) Write(ctx context.Context, data []byte, off int64) (int, syscall.Errno) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.writer == nil {
+		return 0, syscall.EBADF
+	}
+	if off != h.writer.Size() {
+		return 0, syscall.ENOTSUP
+	}
+	h.reader = nil
+	n, err := h.writer.Write(data)
+	return n, toErrno(err)
+}
+
+// Flush 在 close 时调用，提交缓存的写入
+func (h *Handle) Flush(ctx context.Context) syscall.Errno {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.writer == nil {
+		return 0
+	}
+	return toErrno(h.writer.Flush())
+}
+
+// Release 在文件的最后一个引用关闭时调用
+func (h *Handle) Release(ctx context.Context) syscall.Errno {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.reader = nil
+	if h.writer == nil {
+		return 0
+	}
+
+	h.fsys.mu.Lock()
+	if h.fsys.writers[h.path] == h {
+		delete(h.fsys.writers, h.path)
+	}
+	h.fsys.mu.Unlock()
+	return toErrno(h.writer.Close())
+}
+
+// childPath 拼接子节点的路径
+func childPath(parent, name string) string {
+	return path.Join(parent, name)
+}
@@ -0,0 +1,420 @@
+package fusefs
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"metaServer/client"
+	"metaServer/internal/handler"
+	"metaServer/internal/model"
+	"metaServer/internal/service"
+	"metaServer/pb"
+
+	"github.com/dgraph-io/badger/v3"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const testBlockSize = 1000
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// mockData 保存写入的块，写入时模拟流水线把数据复制给 ReplicaLocations
+type mockData struct {
+	pb.UnimplementedDataServerServiceServer
+	peers map[string]*mockData
+
+	mu     sync.Mutex
+	blocks map[uint64][]byte
+}
+
+func (d *mockData) WriteBlock(stream pb.DataServerService_WriteBlockServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	meta := first.GetMetadata()
+	var data []byte
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		data = append(data, req.GetChunkData()...)
+	}
+	d.store(meta.BlockId, data)
+	for _, addr := range meta.ReplicaLocations {
+		d.peers[addr].store(meta.BlockId, data)
+	}
+	return stream.SendAndClose(&pb.WriteBlockResponse{Success: true})
+}
+
+func (d *mockData) store(blockID uint64, data []byte) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.blocks[blockID] = data
+}
+
+func (d *mockData) ReadBlock(req *pb.ReadBlockRequest, stream pb.DataServerService_ReadBlockServer) error {
+	d.mu.Lock()
+	data, exists := d.blocks[req.BlockId]
+	d.mu.Unlock()
+	if !exists {
+		return fmt.Errorf("block %d not found", req.BlockId)
+	}
+
+	end := min(req.Offset+req.Length, uint64(len(data)))
+	resp := &pb.ReadBlockResponse{ChunkData: data[req.Offset:end]}
+	if req.Offset == 0 && req.Length >= uint64(len(data)) {
+		resp.Crc32C, resp.HasCrc32C = crc32.Checksum(data, crc32cTable), true
+	}
+	return stream.Send(resp)
+}
+
+// listen 在随机端口上监听
+func listen(t *testing.T) net.Listener {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	return listener
+}
+
+// startMetaServer 启动单节点的 MetaServer，等待成为 leader 后返回地址和元数据服务
+func startMetaServer(t *testing.T) (string, *service.MetadataService) {
+	t.Helper()
+	listener := listen(t)
+	addr := listener.Addr().String()
+
+	config := &model.Config{}
+	config.Database.BadgerDir = t.TempDir()
+	config.Cluster.DefaultReplication = 2
+	config.Cluster.MaxReplication = 2
+	config.Cluster.HeartbeatTimeout = time.Minute
+	config.Scheduler.BlockSize = testBlockSize
+	config.Scheduler.FSCKInterval = time.Hour
+	config.Scheduler.GCInterval = time.Hour
+	config.Scheduler.FSCKWorkers = 1
+	config.Scheduler.RepairWorkers = 1
+	config.Scheduler.RepairQueueSize = 16
+	config.Raft.ElectionTimeout = 150 * time.Millisecond
+	config.Raft.HeartbeatInterval = 30 * time.Millisecond
+	config.Raft.ProposeTimeout = time.Second
+	config.Raft.Peers = []model.RaftPeer{{ID: "meta-1", Addr: addr}}
+
+	db, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	if err != nil {
+		t.Fatalf("open badger: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	walService := service.NewWALService(db, config, "meta-1")
+	metadataService := service.NewMetadataService(db, config, walService)
+	if err := metadataService.CreateNodeWithInode("/", pb.FileType_Directory, nil, 0, model.Ownership{}, time.Now().UnixMilli()); err != nil {
+		t.Fatalf("create root: %v", err)
+	}
+	clusterService := service.NewClusterService(config)
+	t.Cleanup(clusterService.Stop)
+	schedulerService := service.NewSchedulerService(config, clusterService, metadataService)
+	t.Cleanup(schedulerService.Stop)
+	leaseManager := service.NewLeaseManager(config, metadataService, clusterService)
+
+	raftNode, err := service.NewRaftNode(config, "meta-1", addr, db, walService, metadataService, nil)
+	if err != nil {
+		t.Fatalf("new raft node: %v", err)
+	}
+	walService.SetRaftNode(raftNode)
+	metadataService.SetRaftNode(raftNode)
+	clusterService.SetRaftNode(raftNode)
+
+	metaHandler := handler.NewMetaServerHandler(metadataService, clusterService, schedulerService)
+	metaHandler.SetWALService(walService)
+	metaHandler.SetLeaseManager(leaseManager)
+	metaHandler.SetRaftNode(raftNode)
+
+	server := grpc.NewServer()
+	pb.RegisterMetaServerServiceServer(server, metaHandler)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	raftNode.Start()
+	t.Cleanup(raftNode.Stop)
+	deadline := time.Now().Add(5 * time.Second)
+	for !raftNode.IsLeaderReady() {
+		if time.Now().After(deadline) {
+			t.Fatal("meta server did not become leader")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return addr, metadataService
+}
+
+// startDataServers 启动 n 个 DataServer 并通过心跳注册到 MetaServer，返回地址到 DataServer 的映射
+func startDataServers(t *testing.T, metaAddr string, n int) map[string]*mockData {
+	t.Helper()
+	conn, err := grpc.NewClient(metaAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial meta server: %v", err)
+	}
+	defer conn.Close()
+	meta := pb.NewMetaServerServiceClient(conn)
+
+	peers := make(map[string]*mockData)
+	for i := 0; i < n; i++ {
+		listener := listen(t)
+		data := &mockData{peers: peers, blocks: make(map[uint64][]byte)}
+		peers[listener.Addr().String()] = data
+
+		server := grpc.NewServer()
+		pb.RegisterDataServerServiceServer(server, data)
+		go server.Serve(listener)
+		t.Cleanup(server.Stop)
+
+		_, err := meta.Heartbeat(context.Background(), &pb.HeartbeatRequest{
+			DataserverId:   fmt.Sprintf("data-%d", i),
+			DataserverAddr: listener.Addr().String(),
+			FreeSpace:      1 << 30,
+			TotalCapacity:  1 << 30,
+		})
+		if err != nil {
+			t.Fatalf("heartbeat: %v", err)
+		}
+	}
+	return peers
+}
+
+// newTestFileSystem 启动 MetaServer 和两个 DataServer，返回连接它们的文件系统
+func newTestFileSystem(t *testing.T) (*FileSystem, *service.MetadataService) {
+	t.Helper()
+	metaAddr, metadataService := startMetaServer(t)
+	startDataServers(t, metaAddr, 2)
+
+	c, err := client.New(client.Config{MetaServers: []string{metaAddr}, BlockSize: testBlockSize, User: "alice"})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return New(c, Options{SkipTrash: true}), metadataService
+}
+
+// readAll 通过句柄读出整个文件
+func readAll(t *testing.T, fsys *FileSystem, p string) []byte {
+	t.Helper()
+	ctx := context.Background()
+	h, errno := fsys.Open(ctx, p, syscall.O_RDONLY)
+	if errno != 0 {
+		t.Fatalf("open %s: %v", p, errno)
+	}
+	defer h.Release(ctx)
+
+	var out []byte
+	buf := make([]byte, 700)
+	for {
+		n, errno := h.Read(ctx, buf, int64(len(out)))
+		if errno != 0 {
+			t.Fatalf("read %s at %d: %v", p, len(out), errno)
+		}
+		if n == 0 {
+			return out
+		}
+		out = append(out, buf[:n]...)
+	}
+}
+
+func TestWriteAndReadThroughFileSystem(t *testing.T) {
+	fsys, metadataService := newTestFileSystem(t)
+	ctx := context.Background()
+
+	if _, errno := fsys.Mkdir(ctx, "/dir", 0750); errno != 0 {
+		t.Fatalf("mkdir: %v", errno)
+	}
+	h, stat, errno := fsys.Create(ctx, "/dir/f", 0640)
+	if errno != 0 {
+		t.Fatalf("create: %v", errno)
+	}
+	if stat.Size != 0 || stat.Mode&07777 != 0640 {
+		t.Fatalf("created file: size=%d mode=%o", stat.Size, stat.Mode)
+	}
+
+	// 内核按页写入，写入跨越多个块并以未写满的尾块结束
+	data := make([]byte, 2500)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	for off := 0; off < len(data); off += 300 {
+		chunk := data[off:min(off+300, len(data))]
+		if n, errno := h.Write(ctx, chunk, int64(off)); errno != 0 || n != len(chunk) {
+			t.Fatalf("write at %d: n=%d errno=%v", off, n, errno)
+		}
+	}
+	if _, errno := h.Write(ctx, []byte("x"), 10); errno != syscall.ENOTSUP {
+		t.Errorf("random write: errno=%v, want ENOTSUP", errno)
+	}
+	// 未提交的数据也计入属性中的大小
+	if stat, errno := fsys.Getattr(ctx, "/dir/f"); errno != 0 || stat.Size != int64(len(data)) {
+		t.Fatalf("getattr while writing: size=%v errno=%v", stat.GetSize(), errno)
+	}
+	if errno := h.Flush(ctx); errno != 0 {
+		t.Fatalf("flush: %v", errno)
+	}
+	if errno := h.Release(ctx); errno != 0 {
+		t.Fatalf("release: %v", errno)
+	}
+
+	// 每次提交都经过 FinalizeWrite，从空文件开始写入时记录整个文件的 MD5
+	info, err := metadataService.GetNodeInfo("/dir/f")
+	if err != nil {
+		t.Fatalf("get node info: %v", err)
+	}
+	sum := md5.Sum(data)
+	if info.Size != int64(len(data)) || info.Md5 != hex.EncodeToString(sum[:]) {
+		t.Fatalf("finalized file: size=%d md5=%s, want %d %x", info.Size, info.Md5, len(data), sum)
+	}
+	if got := readAll(t, fsys, "/dir/f"); !bytes.Equal(got, data) {
+		t.Fatalf("read %d bytes, differs from written data", len(got))
+	}
+
+	// 重新打开后从文件末尾继续追加，尾块连同已有数据一起重写
+	h, errno = fsys.Open(ctx, "/dir/f", syscall.O_WRONLY|syscall.O_APPEND)
+	if errno != 0 {
+		t.Fatalf("open for append: %v", errno)
+	}
+	more := []byte("appended")
+	if _, errno := h.Write(ctx, more, int64(len(data))); errno != 0 {
+		t.Fatalf("append: %v", errno)
+	}
+	if errno := h.Release(ctx); errno != 0 {
+		t.Fatalf("release: %v", errno)
+	}
+	data = append(data, more...)
+	if got := readAll(t, fsys, "/dir/f"); !bytes.Equal(got, data) {
+		t.Fatalf("read %d bytes after append, differs from written data", len(got))
+	}
+
+	entries, errno := fsys.Readdir(ctx, "/dir")
+	if errno != 0 || len(entries) != 1 || entries[0].Path != "/dir/f" {
+		t.Fatalf("readdir: %v errno=%v", entries, errno)
+	}
+
+	// O_TRUNC 清空文件后重新写入
+	h, errno = fsys.Open(ctx, "/dir/f", syscall.O_WRONLY|syscall.O_TRUNC)
+	if errno != 0 {
+		t.Fatalf("open with O_TRUNC: %v", errno)
+	}
+	if _, errno := h.Write(ctx, []byte("short"), 0); errno != 0 {
+		t.Fatalf("write after truncate: %v", errno)
+	}
+	if errno := h.Release(ctx); errno != 0 {
+		t.Fatalf("release: %v", errno)
+	}
+	if got := readAll(t, fsys, "/dir/f"); string(got) != "short" {
+		t.Fatalf("read after truncate: %q", got)
+	}
+	if stat, _ := fsys.Getattr(ctx, "/dir/f"); stat.Mode&07777 != 0640 {
+		t.Errorf("mode after truncate = %o, want 640", stat.Mode&07777)
+	}
+
+	if errno := fsys.Rmdir(ctx, "/dir"); errno != syscall.ENOTEMPTY {
+		t.Errorf("rmdir non-empty: errno=%v, want ENOTEMPTY", errno)
+	}
+	if errno := fsys.Unlink(ctx, "/dir/f"); errno != 0 {
+		t.Fatalf("unlink: %v", errno)
+	}
+	if _, errno := fsys.Getattr(ctx, "/dir/f"); errno != syscall.ENOENT {
+		t.Errorf("getattr after unlink: errno=%v, want ENOENT", errno)
+	}
+	if _, _, errno := fsys.Create(ctx, "/missing/f", 0644); errno != syscall.ENOENT {
+		t.Errorf("create in missing directory: errno=%v, want ENOENT", errno)
+	}
+}
+
+func TestAppendReplacesPartialTailBlock(t *testing.T) {
+	metaAddr, metadataService := startMetaServer(t)
+	datas := startDataServers(t, metaAddr, 2)
+	c, err := client.New(client.Config{MetaServers: []string{metaAddr}, BlockSize: testBlockSize})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	ctx := context.Background()
+
+	data := make([]byte, 1500)
+	for i := range data {
+		data[i] = byte(i * 3)
+	}
+	if err := c.Put(ctx, "/f", bytes.NewReader(data), int64(len(data)), 0); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	info, err := metadataService.GetNodeInfo("/f")
+	if err != nil {
+		t.Fatalf("get node info: %v", err)
+	}
+	before, err := metadataService.GetBlockMappings(info.Inode)
+	if err != nil || len(before) != 2 {
+		t.Fatalf("block mappings before append: %d, err=%v", len(before), err)
+	}
+
+	w, err := c.OpenAppend(ctx, "/f")
+	if err != nil {
+		t.Fatalf("open append: %v", err)
+	}
+	more := bytes.Repeat([]byte("a"), 600)
+	if _, err := w.Write(more); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	data = append(data, more...)
+
+	// 已写满的块不变，未写满的尾块由新块替换，原尾块的数据保持不变并交给垃圾回收
+	after, err := metadataService.GetBlockMappings(info.Inode)
+	if err != nil || len(after) != 3 {
+		t.Fatalf("block mappings after append: %d, err=%v", len(after), err)
+	}
+	if after[0].BlockId != before[0].BlockId {
+		t.Errorf("full block replaced: %d -> %d", before[0].BlockId, after[0].BlockId)
+	}
+	oldTail := before[1].BlockId
+	if after[1].BlockId == oldTail {
+		t.Fatalf("partial tail block %d rewritten in place", oldTail)
+	}
+	for addr, d := range datas {
+		d.mu.Lock()
+		stored, exists := d.blocks[oldTail]
+		d.mu.Unlock()
+		if exists && !bytes.Equal(stored, data[testBlockSize:1500]) {
+			t.Errorf("old tail block on %s modified: %d bytes", addr, len(stored))
+		}
+	}
+	entries, err := metadataService.GetGCEntries()
+	if err != nil {
+		t.Fatalf("get gc entries: %v", err)
+	}
+	queued := false
+	for _, entry := range entries {
+		queued = queued || entry.BlockID == oldTail
+	}
+	if !queued {
+		t.Errorf("old tail block %d not queued for garbage collection", oldTail)
+	}
+
+	var out bytes.Buffer
+	if _, err := c.Get(ctx, "/f", &out); err != nil || !bytes.Equal(out.Bytes(), data) {
+		t.Fatalf("read after append: %d bytes, err=%v", out.Len(), err)
+	}
+}
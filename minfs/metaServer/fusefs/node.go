@@ -0,0 +1,227 @@
+package fusefs
+
+import (
+	"context"
+	"os"
+	"path"
+	"syscall"
+	"time"
+
+	"metaServer/pb"
+
+	"github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+)
+
+// renameNoReplace renameat2 的 RENAME_NOREPLACE
+const renameNoReplace = 0x1
+
+// attrTimeout 内核缓存属性和目录项的时间，其他客户端的修改在此之后可见
+const attrTimeout = time.Second
+
+// Node go-fuse 节点，路径由节点在树中的位置决定
+type Node struct {
+	fs.Inode
+	fsys *FileSystem
+}
+
+var (
+	_ fs.NodeLookuper  = (*Node)(nil)
+	_ fs.NodeGetattrer = (*Node)(nil)
+	_ fs.NodeSetattrer = (*Node)(nil)
+	_ fs.NodeReaddirer = (*Node)(nil)
+	_ fs.NodeOpener    = (*Node)(nil)
+	_ fs.NodeCreater   = (*Node)(nil)
+	_ fs.NodeMkdirer   = (*Node)(nil)
+	_ fs.NodeUnlinker  = (*Node)(nil)
+	_ fs.NodeRmdirer   = (*Node)(nil)
+	_ fs.NodeRenamer   = (*Node)(nil)
+)
+
+// Root 返回挂载点的根节点
+func (fsys *FileSystem) Root() *Node {
+	return &Node{fsys: fsys}
+}
+
+// Mount 将文件系统挂载到 mountpoint，返回的 fuse.Server 在卸载前调用 Wait 阻塞
+func Mount(mountpoint string, fsys *FileSystem, debug bool) (*fuse.Server, error) {
+	timeout := attrTimeout
+	return fs.Mount(mountpoint, fsys.Root(), &fs.Options{
+		MountOptions: fuse.MountOptions{
+			FsName: "minfs",
+			Name:   "minfs",
+			Debug:  debug,
+		},
+		EntryTimeout: &timeout,
+		AttrTimeout:  &timeout,
+		UID:          uint32(os.Getuid()),
+		GID:          uint32(os.Getgid()),
+	})
+}
+
+// path 返回节点在 MinFS 中的绝对路径
+func (n *Node) path() string {
+	return "/" + n.Path(nil)
+}
+
+// newChild 为子节点创建 Inode 并填充属性
+func (n *Node) newChild(ctx context.Context, stat *pb.StatInfo, out *fuse.EntryOut) *fs.Inode {
+	fillAttr(stat, &out.Attr)
+	out.SetEntryTimeout(attrTimeout)
+	out.SetAttrTimeout(attrTimeout)
+	return n.NewInode(ctx, &Node{fsys: n.fsys}, fs.StableAttr{Mode: fileType(stat)})
+}
+
+// fileType 返回 S_IFDIR 或 S_IFREG
+func fileType(stat *pb.StatInfo) uint32 {
+	if stat.Type == pb.FileType_Directory {
+		return syscall.S_IFDIR
+	}
+	return syscall.S_IFREG
+}
+
+// fillAttr 将 StatInfo 转换为 FUSE 属性；MinFS 的属主是用户名，由挂载选项统一显示为挂载用户
+func fillAttr(stat *pb.StatInfo, out *fuse.Attr) {
+	mode := stat.Mode & 07777
+	if mode == 0 {
+		// 没有记录权限位的旧节点
+		mode = 0644
+		if stat.Type == pb.FileType_Directory {
+			mode = 0755
+		}
+	}
+	out.Mode = fileType(stat) | mode
+	out.Size = uint64(stat.Size)
+	out.Blocks = (out.Size + 511) / 512
+	out.Nlink = 1
+	if stat.Type == pb.FileType_Directory {
+		out.Nlink = 2
+	}
+	mtime := time.UnixMilli(stat.Mtime)
+	out.SetTimes(&mtime, &mtime, &mtime)
+}
+
+// Lookup 实现 fs.NodeLookuper
+func (n *Node) Lookup(ctx context.Context, name string, out *fuse.EntryOut) (*fs.Inode, syscall.Errno) {
+	stat, errno := n.fsys.Getattr(ctx, childPath(n.path(), name))
+	if errno != 0 {
+		return nil, errno
+	}
+	return n.newChild(ctx, stat, out), 0
+}
+
+// Getattr 实现 fs.NodeGetattrer
+func (n *Node) Getattr(ctx context.Context, f fs.FileHandle, out *fuse.AttrOut) syscall.Errno {
+	stat, errno := n.fsys.Getattr(ctx, n.path())
+	if errno != 0 {
+		return errno
+	}
+	fillAttr(stat, &out.Attr)
+	out.SetTimeout(attrTimeout)
+	return 0
+}
+
+// Setattr 实现 fs.NodeSetattrer：支持修改权限位和截断为空文件，属主和时间的修改被忽略
+func (n *Node) Setattr(ctx context.Context, f fs.FileHandle, in *fuse.SetAttrIn, out *fuse.AttrOut) syscall.Errno {
+	p := n.path()
+	if size, ok := in.GetSize(); ok {
+		// 写入中的句柄已经在文件末尾，保持当前大小不需要截断
+		if h, isHandle := f.(*fileHandle); !isHandle || h.Size() != int64(size) {
+			if errno := n.fsys.Truncate(ctx, p, int64(size)); errno != 0 {
+				return errno
+			}
+		}
+	}
+	if mode, ok := in.GetMode(); ok {
+		if errno := n.fsys.Chmod(ctx, p, mode); errno != 0 {
+			return errno
+		}
+	}
+	return n.Getattr(ctx, f, out)
+}
+
+// Readdir 实现 fs.NodeReaddirer
+func (n *Node) Readdir(ctx context.Context) (fs.DirStream, syscall.Errno) {
+	entries, errno := n.fsys.Readdir(ctx, n.path())
+	if errno != 0 {
+		return nil, errno
+	}
+	list := make([]fuse.DirEntry, 0, len(entries))
+	for _, stat := range entries {
+		list = append(list, fuse.DirEntry{Name: path.Base(stat.Path), Mode: fileType(stat)})
+	}
+	return fs.NewListDirStream(list), 0
+}
+
+// Open 实现 fs.NodeOpener
+func (n *Node) Open(ctx context.Context, flags uint32) (fs.FileHandle, uint32, syscall.Errno) {
+	h, errno := n.fsys.Open(ctx, n.path(), flags)
+	if errno != 0 {
+		return nil, 0, errno
+	}
+	return &fileHandle{h}, 0, 0
+}
+
+// Create 实现 fs.NodeCreater
+func (n *Node) Create(ctx context.Context, name string, flags uint32, mode uint32, out *fuse.EntryOut) (*fs.Inode, fs.FileHandle, uint32, syscall.Errno) {
+	h, stat, errno := n.fsys.Create(ctx, childPath(n.path(), name), mode)
+	if errno != 0 {
+		return nil, nil, 0, errno
+	}
+	return n.newChild(ctx, stat, out), &fileHandle{h}, 0, 0
+}
+
+// Mkdir 实现 fs.NodeMkdirer
+func (n *Node) Mkdir(ctx context.Context, name string, mode uint32, out *fuse.EntryOut) (*fs.Inode, syscall.Errno) {
+	stat, errno := n.fsys.Mkdir(ctx, childPath(n.path(), name), mode)
+	if errno != 0 {
+		return nil, errno
+	}
+	return n.newChild(ctx, stat, out), 0
+}
+
+// Unlink 实现 fs.NodeUnlinker
+func (n *Node) Unlink(ctx context.Context, name string) syscall.Errno {
+	return n.fsys.Unlink(ctx, childPath(n.path(), name))
+}
+
+// Rmdir 实现 fs.NodeRmdirer
+func (n *Node) Rmdir(ctx context.Context, name string) syscall.Errno {
+	return n.fsys.Rmdir(ctx, childPath(n.path(), name))
+}
+
+// Rename 实现 fs.NodeRenamer，不支持 RENAME_EXCHANGE
+func (n *Node) Rename(ctx context.Context, name string, newParent fs.InodeEmbedder, newName string, flags uint32) syscall.Errno {
+	if flags&fs.RENAME_EXCHANGE != 0 {
+		return syscall.ENOTSUP
+	}
+	dst := childPath("/"+newParent.EmbeddedInode().Path(nil), newName)
+	return n.fsys.Rename(ctx, childPath(n.path(), name), dst, flags&renameNoReplace != 0)
+}
+
+// fileHandle go-fuse 文件句柄
+type fileHandle struct {
+	*Handle
+}
+
+var (
+	_ fs.FileReader   = (*fileHandle)(nil)
+	_ fs.FileWriter   = (*fileHandle)(nil)
+	_ fs.FileFlusher  = (*fileHandle)(nil)
+	_ fs.FileReleaser = (*fileHandle)(nil)
+)
+
+// Read 实现 fs.FileReader
+func (f *fileHandle) Read(ctx context.Context, dest []byte, off int64) (fuse.ReadResult, syscall.Errno) {
+	n, errno := f.Handle.Read(ctx, dest, off)
+	if errno != 0 {
+		return nil, errno
+	}
+	return fuse.ReadResultData(dest[:n]), 0
+}
+
+// Write 实现 fs.FileWriter
+func (f *fileHandle) Write(ctx context.Context, data []byte, off int64) (uint32, syscall.Errno) {
+	n, errno := f.Handle.Write(ctx, data, off)
+	return uint32(n), errno
+}
@@ -5,9 +5,17 @@ import (
 	"fmt"
 	"log"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
+	"metaServer/internal/model"
 	"metaServer/internal/service"
 	"metaServer/pb"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 )
 
 type MetaServerHandler struct {
@@ -17,6 +25,10 @@ type MetaServerHandler struct {
 	clusterService   *service.ClusterService
 	schedulerService *service.SchedulerService
 	walService       *service.WALService
+	leaseManager     *service.LeaseManager
+	raftNode         *service.RaftNode
+	balancer         *service.Balancer
+	blockTokens      *service.BlockTokenManager
 }
 
 func NewMetaServerHandler(
@@ -37,6 +49,26 @@ func (h *MetaServerHandler) SetWALService(walService *service.WALService) {
 	h.walService = walService
 }
 
+// SetLeaseManager 设置写租约管理器
+func (h *MetaServerHandler) SetLeaseManager(leaseManager *service.LeaseManager) {
+	h.leaseManager = leaseManager
+}
+
+// SetRaftNode 设置Raft节点
+func (h *MetaServerHandler) SetRaftNode(raftNode *service.RaftNode) {
+	h.raftNode = raftNode
+}
+
+// SetBalancer 设置磁盘使用率均衡器
+func (h *MetaServerHandler) SetBalancer(balancer *service.Balancer) {
+	h.balancer = balancer
+}
+
+// SetBlockTokenManager 设置块访问令牌签发器，为 nil 时不签发令牌
+func (h *MetaServerHandler) SetBlockTokenManager(blockTokens *service.BlockTokenManager) {
+	h.blockTokens = blockTokens
+}
+
 // getWALService 获取WAL服务
 func (h *MetaServerHandler) getWALService() *service.WALService {
 	return h.walService
@@ -50,33 +82,69 @@ func (h *MetaServerHandler) isLeader() bool {
 	return h.clusterService.IsLeader()
 }
 
-// createWALEntry 创建WAL条目
-func (h *MetaServerHandler) createWALEntry(operation pb.WALOperationType, data interface{}) (*pb.LogEntry, error) {
-	walService := h.getWALService()
-	if walService == nil {
-		// 如果没有WAL服务，跳过WAL记录
-		return nil, nil
+// leaseHolder 获取写入方标识，旧版本客户端未携带时使用连接地址
+func leaseHolder(ctx context.Context, clientName string) string {
+	if clientName != "" {
+		return clientName
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		return p.Addr.String()
 	}
+	return "anonymous"
+}
 
-	return walService.AppendLogEntry(operation, data)
+// callerFromContext 从 gRPC metadata 获取调用者身份：minfs-user 为用户名，minfs-groups 为逗号分隔的组名
+func callerFromContext(ctx context.Context) model.Caller {
+	var caller model.Caller
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return caller
+	}
+	if users := md.Get("minfs-user"); len(users) > 0 {
+		caller.User = strings.TrimSpace(users[0])
+	}
+	// mTLS 连接以客户端证书的 CN 作为用户名、OU 作为所属组，不信任客户端声明的身份
+	if p, ok := peer.FromContext(ctx); ok {
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(tlsInfo.State.VerifiedChains) > 0 {
+			subject := tlsInfo.State.VerifiedChains[0][0].Subject
+			if subject.CommonName != "" {
+				caller.User = subject.CommonName
+			}
+			for _, group := range subject.OrganizationalUnit {
+				if group = strings.TrimSpace(group); group != "" {
+					caller.Groups = append(caller.Groups, group)
+				}
+			}
+			return caller
+		}
+	}
+	for _, value := range md.Get("minfs-groups") {
+		for _, group := range strings.Split(value, ",") {
+			if group = strings.TrimSpace(group); group != "" {
+				caller.Groups = append(caller.Groups, group)
+			}
+		}
+	}
+	return caller
 }
 
-// syncWALToFollowers 同步WAL到followers
-func (h *MetaServerHandler) syncWALToFollowers(entry *pb.LogEntry) {
-	walService := h.getWALService()
-	if walService == nil {
-		return
+// acquireLease 获取文件写租约并返回最新的节点信息
+// 抢占过期租约时会先恢复上一次写入，因此需要重新读取节点信息
+func (h *MetaServerHandler) acquireLease(ctx context.Context, path string, req *pb.GetBlockLocationsRequest, nodeInfo *pb.NodeInfo) (*pb.NodeInfo, error) {
+	if h.leaseManager == nil {
+		return nodeInfo, nil
 	}
 
-	err := walService.SyncToFollowers(entry)
-	if err != nil {
-		log.Printf("Failed to sync WAL entry %d to followers: %v", entry.LogIndex, err)
+	holder := leaseHolder(ctx, req.ClientName)
+	if _, err := h.leaseManager.Acquire(path, holder, req.Size, req.Append); err != nil {
+		return nil, err
 	}
+	return h.metadataService.GetNodeInfo(path)
 }
 
 // CreateNode 创建文件或目录
 func (h *MetaServerHandler) CreateNode(ctx context.Context, req *pb.CreateNodeRequest) (*pb.SimpleResponse, error) {
-	log.Printf("CreateNode request: path=%s, type=%v", req.Path, req.Type)
+	log.Printf("CreateNode request: path=%s, type=%v, replication=%d", req.Path, req.Type, req.Replication)
 
 	if req.Path == "" {
 		return &pb.SimpleResponse{Success: false}, fmt.Errorf("path cannot be empty")
@@ -92,36 +160,13 @@ func (h *MetaServerHandler) CreateNode(ctx context.Context, req *pb.CreateNodeRe
 		return &pb.SimpleResponse{Success: false}, fmt.Errorf("only leader can handle write operations")
 	}
 
-	// 1. 先执行实际的元数据操作，获得确定的inode ID
-	err := h.metadataService.CreateNode(path, req.Type)
+	// 多数节点提交后才返回成功
+	err := h.metadataService.CreateNodeAs(callerFromContext(ctx), path, req.Type, req.Replication, req.Mode)
 	if err != nil {
 		log.Printf("CreateNode error: %v", err)
 		return &pb.SimpleResponse{Success: false}, err
 	}
 
-	// 2. 获取创建后的文件信息（包含实际的inode ID）
-	nodeInfo, err := h.metadataService.GetNodeInfo(path)
-	if err != nil {
-		log.Printf("CreateNode: Failed to get node info: %v", err)
-		return &pb.SimpleResponse{Success: false}, err
-	}
-
-	// 3. 使用实际的inode ID创建WAL日志条目
-	walEntry, err := h.createWALEntry(pb.WALOperationType_CREATE_NODE, &pb.CreateNodeOperation{
-		Path:    path,
-		Type:    req.Type,
-		InodeId: nodeInfo.Inode, // 包含实际的inode ID
-	})
-	if err != nil {
-		log.Printf("CreateNode: Failed to create WAL entry: %v", err)
-		// 虽然WAL写入失败，但文件已创建，继续执行
-	}
-
-	// 3. 同步WAL到followers
-	if walEntry != nil {
-		go h.syncWALToFollowers(walEntry)
-	}
-
 	log.Printf("CreateNode success: %s", path)
 	return &pb.SimpleResponse{Success: true}, nil
 }
@@ -134,7 +179,17 @@ func (h *MetaServerHandler) GetNodeInfo(ctx context.Context, req *pb.GetNodeInfo
 		return nil, fmt.Errorf("path cannot be empty")
 	}
 
-	nodeInfo, err := h.metadataService.GetNodeInfo(req.Path)
+	if err := h.metadataService.CheckPermission(callerFromContext(ctx), req.Path, 0, 0); err != nil {
+		return nil, err
+	}
+
+	var nodeInfo *pb.NodeInfo
+	var err error
+	if service.IsSnapshotPath(req.Path) {
+		nodeInfo, err = h.metadataService.GetSnapshotNodeInfo(req.Path)
+	} else {
+		nodeInfo, err = h.metadataService.GetNodeInfo(req.Path)
+	}
 	if err != nil {
 		log.Printf("GetNodeInfo error: %v", err)
 		return nil, err
@@ -148,29 +203,97 @@ func (h *MetaServerHandler) GetNodeInfo(ctx context.Context, req *pb.GetNodeInfo
 	}, nil
 }
 
-// ListDirectory 列出目录内容
+// defaultListBatchSize ListDirectoryStream 未指定 limit 时每批返回的子节点数
+const defaultListBatchSize = 1000
+
+// ListDirectory 列出目录内容，limit 大于 0 时分页返回
 func (h *MetaServerHandler) ListDirectory(ctx context.Context, req *pb.ListDirectoryRequest) (*pb.ListDirectoryResponse, error) {
-	log.Printf("ListDirectory request: path=%s", req.Path)
+	log.Printf("ListDirectory request: path=%s, limit=%d, continuation_token=%q", req.Path, req.Limit, req.ContinuationToken)
 
 	if req.Path == "" {
 		return nil, fmt.Errorf("path cannot be empty")
 	}
 
-	nodes, err := h.metadataService.ListDirectory(req.Path)
+	if err := h.metadataService.CheckPermission(callerFromContext(ctx), req.Path, 0, model.PermRead|model.PermExecute); err != nil {
+		return nil, err
+	}
+
+	resp, err := h.listDirectoryPage(req.Path, req.ContinuationToken, int(req.Limit))
 	if err != nil {
 		log.Printf("ListDirectory error: %v", err)
 		return nil, err
 	}
 
+	log.Printf("ListDirectory success: %s (%d items)", req.Path, len(resp.Nodes))
+	return resp, nil
+}
+
+// ListDirectoryStream 流式列出目录内容，每批最多 limit 个子节点，从 continuation_token 之后开始
+// 每批在独立的读事务中读取，批次之间发生的修改可能部分可见
+func (h *MetaServerHandler) ListDirectoryStream(req *pb.ListDirectoryRequest, stream pb.MetaServerService_ListDirectoryStreamServer) error {
+	log.Printf("ListDirectoryStream request: path=%s, limit=%d, continuation_token=%q", req.Path, req.Limit, req.ContinuationToken)
+
+	if req.Path == "" {
+		return fmt.Errorf("path cannot be empty")
+	}
+
+	if err := h.metadataService.CheckPermission(callerFromContext(stream.Context()), req.Path, 0, model.PermRead|model.PermExecute); err != nil {
+		return err
+	}
+
+	limit := int(req.Limit)
+	if limit == 0 {
+		limit = defaultListBatchSize
+	}
+
+	token := req.ContinuationToken
+	total := 0
+	for {
+		if err := stream.Context().Err(); err != nil {
+			return err
+		}
+		resp, err := h.listDirectoryPage(req.Path, token, limit)
+		if err != nil {
+			log.Printf("ListDirectoryStream error: %v", err)
+			return err
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+		total += len(resp.Nodes)
+		if resp.NextContinuationToken == "" {
+			break
+		}
+		token = resp.NextContinuationToken
+	}
+
+	log.Printf("ListDirectoryStream success: %s (%d items)", req.Path, total)
+	return nil
+}
+
+// listDirectoryPage 读取一页目录内容并转换为 StatInfo 列表
+func (h *MetaServerHandler) listDirectoryPage(path, token string, limit int) (*pb.ListDirectoryResponse, error) {
+	var nodes []*pb.NodeInfo
+	var next string
+	var err error
+	if service.IsSnapshotPath(path) {
+		nodes, next, err = h.metadataService.ListSnapshotDirectoryPage(path, token, limit)
+	} else {
+		nodes, next, err = h.metadataService.ListDirectoryPage(path, token, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
 	// 转换为 StatInfo 列表
 	var statInfos []*pb.StatInfo
 	for _, nodeInfo := range nodes {
 		statInfos = append(statInfos, h.nodeInfoToStatInfo(nodeInfo))
 	}
 
-	log.Printf("ListDirectory success: %s (%d items)", req.Path, len(statInfos))
 	return &pb.ListDirectoryResponse{
-		Nodes: statInfos,
+		Nodes:                 statInfos,
+		NextContinuationToken: next,
 	}, nil
 }
 
@@ -191,34 +314,107 @@ func (h *MetaServerHandler) DeleteNode(ctx context.Context, req *pb.DeleteNodeRe
 		return &pb.SimpleResponse{Success: false}, fmt.Errorf("only leader can handle write operations")
 	}
 
-	// 1. 创建WAL日志条目
-	walEntry, err := h.createWALEntry(pb.WALOperationType_DELETE_NODE, &pb.DeleteNodeOperation{
-		Path:      req.Path,
-		Recursive: req.Recursive,
-	})
-	if err != nil {
-		log.Printf("DeleteNode: Failed to create WAL entry: %v", err)
+	if err := h.metadataService.CheckPermission(callerFromContext(ctx), req.Path, model.PermWrite, 0); err != nil {
 		return &pb.SimpleResponse{Success: false}, err
 	}
 
-	// 2. 执行实际的元数据操作
+	// 开启回收站时移入 /.Trash，块映射保留到回收站过期后再回收
+	if h.metadataService.TrashEnabled() && !req.SkipTrash && !service.IsInTrash(req.Path) {
+		trashPath, err := h.metadataService.MoveToTrash(req.Path, req.Recursive, time.Now())
+		if err != nil {
+			log.Printf("DeleteNode error: %v", err)
+			return &pb.SimpleResponse{Success: false}, err
+		}
+		log.Printf("DeleteNode success: %s moved to %s", req.Path, trashPath)
+		return &pb.SimpleResponse{Success: true, Message: trashPath}, nil
+	}
+
+	// 1. 通过日志提交删除操作
 	blocksToDelete, err := h.metadataService.DeleteNode(req.Path, req.Recursive)
 	if err != nil {
 		log.Printf("DeleteNode error: %v", err)
 		return &pb.SimpleResponse{Success: false}, err
 	}
 
-	// 3. 同步WAL到followers
-	if walEntry != nil {
-		go h.syncWALToFollowers(walEntry)
+	// 2. 调度块删除
+	for _, block := range blocksToDelete {
+		h.schedulerService.ScheduleBlockDeletion(block.BlockID, block.Locations)
+	}
+
+	log.Printf("DeleteNode success: %s (%d blocks scheduled for deletion)", req.Path, len(blocksToDelete))
+	return &pb.SimpleResponse{Success: true}, nil
+}
+
+// RestoreNode 将回收站中的节点恢复到删除前的路径
+func (h *MetaServerHandler) RestoreNode(ctx context.Context, req *pb.RestoreNodeRequest) (*pb.RestoreNodeResponse, error) {
+	log.Printf("RestoreNode request: trash_path=%s", req.TrashPath)
+
+	if req.TrashPath == "" {
+		return &pb.RestoreNodeResponse{Success: false}, fmt.Errorf("trash path cannot be empty")
+	}
+
+	// 检查Leader权限
+	if !h.isLeader() {
+		return &pb.RestoreNodeResponse{Success: false}, fmt.Errorf("only leader can handle write operations")
+	}
+
+	// 恢复相当于在原路径下创建节点，需要原父目录的写权限；回收站目录只有超级用户可以访问，不检查
+	original, err := service.TrashOriginalPath(req.TrashPath)
+	if err != nil {
+		return &pb.RestoreNodeResponse{Success: false}, err
+	}
+	if err := h.metadataService.CheckPermission(callerFromContext(ctx), original, model.PermWrite, 0); err != nil {
+		return &pb.RestoreNodeResponse{Success: false}, err
+	}
+
+	path, err := h.metadataService.RestoreFromTrash(req.TrashPath)
+	if err != nil {
+		log.Printf("RestoreNode error: %v", err)
+		return &pb.RestoreNodeResponse{Success: false}, err
+	}
+
+	log.Printf("RestoreNode success: %s restored to %s", req.TrashPath, path)
+	return &pb.RestoreNodeResponse{Success: true, Path: path}, nil
+}
+
+// Rename 重命名/移动文件或目录
+func (h *MetaServerHandler) Rename(ctx context.Context, req *pb.RenameRequest) (*pb.SimpleResponse, error) {
+	log.Printf("Rename request: src=%s, dst=%s, overwrite=%v", req.Src, req.Dst, req.Overwrite)
+
+	if req.Src == "" || req.Dst == "" {
+		return &pb.SimpleResponse{Success: false}, fmt.Errorf("src and dst cannot be empty")
+	}
+
+	// 检查Leader权限
+	if !h.isLeader() {
+		return &pb.SimpleResponse{Success: false}, fmt.Errorf("only leader can handle write operations")
+	}
+
+	src := filepath.Clean(req.Src)
+	dst := filepath.Clean(req.Dst)
+
+	// 源和目标的父目录都需要写权限
+	caller := callerFromContext(ctx)
+	if err := h.metadataService.CheckPermission(caller, src, model.PermWrite, 0); err != nil {
+		return &pb.SimpleResponse{Success: false}, err
+	}
+	if err := h.metadataService.CheckPermission(caller, dst, model.PermWrite, 0); err != nil {
+		return &pb.SimpleResponse{Success: false}, err
+	}
+
+	// 1. 通过日志提交重命名操作
+	blocksToDelete, err := h.metadataService.RenameNode(src, dst, req.Overwrite)
+	if err != nil {
+		log.Printf("Rename error: %v", err)
+		return &pb.SimpleResponse{Success: false}, err
 	}
 
-	// 4. 调度块删除
+	// 2. 被覆盖的目标文件的块交给调度器回收
 	for _, block := range blocksToDelete {
 		h.schedulerService.ScheduleBlockDeletion(block.BlockID, block.Locations)
 	}
 
-	log.Printf("DeleteNode success: %s (%d blocks scheduled for deletion)", req.Path, len(blocksToDelete))
+	log.Printf("Rename success: %s -> %s (%d blocks scheduled for deletion)", src, dst, len(blocksToDelete))
 	return &pb.SimpleResponse{Success: true}, nil
 }
 
@@ -235,6 +431,30 @@ func (h *MetaServerHandler) GetBlockLocations(ctx context.Context, req *pb.GetBl
 		path = "/"
 	}
 
+	caller := callerFromContext(ctx)
+
+	// 快照只读，只能获取已有的块映射
+	if service.IsSnapshotPath(path) {
+		if req.Append || req.Size < 0 {
+			return nil, fmt.Errorf("snapshot path is read-only: %s", path)
+		}
+		if err := h.metadataService.CheckPermission(caller, path, 0, model.PermRead); err != nil {
+			return nil, err
+		}
+		nodeInfo, blockMappings, err := h.metadataService.GetSnapshotBlockMappings(path)
+		if err != nil {
+			return nil, err
+		}
+		if req.Size > 0 && req.Size != nodeInfo.Size {
+			return nil, fmt.Errorf("snapshot path is read-only: %s", path)
+		}
+		h.blockTokens.Attach(blockMappings, service.BlockTokenRead)
+		return &pb.GetBlockLocationsResponse{
+			Inode:          nodeInfo.Inode,
+			BlockLocations: blockMappings,
+		}, nil
+	}
+
 	// 检查文件是否存在
 	nodeInfo, err := h.metadataService.GetNodeInfo(path)
 	if err != nil {
@@ -245,32 +465,33 @@ func (h *MetaServerHandler) GetBlockLocations(ctx context.Context, req *pb.GetBl
 				return nil, fmt.Errorf("only leader can handle write operations")
 			}
 
-			// 1. 先执行实际的元数据操作，获得确定的inode ID
-			err = h.metadataService.CreateNode(path, pb.FileType_File)
-			if err != nil {
+			replication := req.Replication
+			if replication == 0 {
+				replication = h.metadataService.DefaultReplication()
+			}
+			if err := h.metadataService.ValidateReplication(replication); err != nil {
 				return nil, err
 			}
 
-			// 2. 获取创建后的文件信息（包含实际的inode ID）
-			nodeInfo, err = h.metadataService.GetNodeInfo(path)
+			// 创建文件前检查空间配额，纠删码目录下的新文件按编码后的大小计算
+			ecPolicy, _, err := h.metadataService.GetErasureCodingPolicy(filepath.Dir(path))
 			if err != nil {
 				return nil, err
 			}
+			if err := h.metadataService.CheckSpaceQuota(path, req.Size, replication, ecPolicy); err != nil {
+				return nil, err
+			}
 
-			// 3. 使用实际的inode ID创建WAL日志条目
-			walEntry, err := h.createWALEntry(pb.WALOperationType_CREATE_NODE, &pb.CreateNodeOperation{
-				Path:    path,
-				Type:    pb.FileType_File,
-				InodeId: nodeInfo.Inode, // 包含实际的inode ID
-			})
+			// 1. 通过日志提交创建操作，父目录需要写权限
+			err = h.metadataService.CreateNodeAs(caller, path, pb.FileType_File, replication, 0)
 			if err != nil {
-				log.Printf("GetBlockLocations: Failed to create WAL entry: %v", err)
-				// 虽然WAL写入失败，但文件已创建，继续执行
+				return nil, err
 			}
 
-			// 4. 同步WAL到followers
-			if walEntry != nil {
-				go h.syncWALToFollowers(walEntry)
+			// 2. 获取创建后的文件信息（包含分配的inode ID）
+			nodeInfo, err = h.metadataService.GetNodeInfo(path)
+			if err != nil {
+				return nil, err
 			}
 		} else {
 			return nil, fmt.Errorf("file not found: %s", path)
@@ -281,6 +502,45 @@ func (h *MetaServerHandler) GetBlockLocations(ctx context.Context, req *pb.GetBl
 		return nil, fmt.Errorf("cannot get block locations for directory: %s", path)
 	}
 
+	// 读取需要读权限，追加和覆盖写需要写权限
+	access := model.PermWrite
+	if !req.Append && (req.Size == 0 || (req.Size == nodeInfo.Size && nodeInfo.Size > 0)) {
+		access = model.PermRead
+	}
+	if err := h.metadataService.CheckPermission(caller, path, 0, access); err != nil {
+		return nil, err
+	}
+
+	// 追加模式
+	if req.Append {
+		if !h.isLeader() {
+			return nil, fmt.Errorf("only leader can handle write operations")
+		}
+		if req.Size <= 0 {
+			return nil, fmt.Errorf("append size must be positive")
+		}
+		if nodeInfo.EcPolicy != "" {
+			return nil, fmt.Errorf("append is not supported on erasure-coded file: %s", path)
+		}
+		if err := h.metadataService.CheckSpaceQuota(path, req.Size, nodeInfo.Replication, ""); err != nil {
+			return nil, err
+		}
+		nodeInfo, err = h.acquireLease(ctx, path, req, nodeInfo)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := h.allocateAppendBlocks(path, nodeInfo, uint64(req.Size))
+		if err != nil {
+			return nil, err
+		}
+		h.blockTokens.Attach(resp.BlockLocations, service.BlockTokenRead+service.BlockTokenWrite)
+		// 追加时需要先读取原尾块中已有的数据
+		if resp.PrevTail != nil {
+			h.blockTokens.Attach([]*pb.BlockLocations{resp.PrevTail}, service.BlockTokenRead)
+		}
+		return resp, nil
+	}
+
 	// 判断是读取还是写入操作
 	if req.Size == 0 || (req.Size == nodeInfo.Size && nodeInfo.Size > 0) {
 		// 读取模式：返回已存在的块映射
@@ -291,15 +551,50 @@ func (h *MetaServerHandler) GetBlockLocations(ctx context.Context, req *pb.GetBl
 		}
 
 		log.Printf("GetBlockLocations (read) success: %s, inode=%d, %d existing blocks", path, nodeInfo.Inode, len(blockMappings))
+		h.blockTokens.Attach(blockMappings, service.BlockTokenRead)
 
 		return &pb.GetBlockLocationsResponse{
 			Inode:          nodeInfo.Inode,
 			BlockLocations: blockMappings,
 		}, nil
 	} else {
-		// 写入模式：分配新的数据块位置
+		// 写入模式：整体覆盖，分配新的数据块位置
+		if !h.isLeader() {
+			return nil, fmt.Errorf("only leader can handle write operations")
+		}
+
+		nodeInfo, err = h.acquireLease(ctx, path, req, nodeInfo)
+		if err != nil {
+			return nil, err
+		}
+
 		log.Printf("Write mode: allocating new blocks for %s", path)
-		blockLocations, err := h.schedulerService.AllocateBlocks(uint64(req.Size), int(nodeInfo.Replication))
+
+		// 覆盖写时指定了不同的副本数，新块按新副本数分配；纠删码文件不使用副本数
+		if nodeInfo.EcPolicy == "" && req.Replication != 0 && req.Replication != nodeInfo.Replication {
+			if err := h.metadataService.SetReplication(path, req.Replication); err != nil {
+				return nil, err
+			}
+			nodeInfo.Replication = req.Replication
+		}
+
+		// 覆盖写只需要检查增加的部分
+		if err := h.metadataService.CheckSpaceQuota(path, req.Size-nodeInfo.Size, nodeInfo.Replication, nodeInfo.EcPolicy); err != nil {
+			return nil, err
+		}
+
+		// 记录被替换的旧块，由写租约在写入完成后交给垃圾回收
+		oldBlocks, err := h.metadataService.GetBlockMappings(nodeInfo.Inode)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get existing block mappings: %v", err)
+		}
+
+		var blockLocations []*pb.BlockLocations
+		if nodeInfo.EcPolicy != "" {
+			blockLocations, err = h.schedulerService.AllocateBlockGroups(uint64(req.Size), nodeInfo.EcPolicy)
+		} else {
+			blockLocations, err = h.schedulerService.AllocateBlocks(uint64(req.Size), int(nodeInfo.Replication))
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -312,7 +607,27 @@ func (h *MetaServerHandler) GetBlockLocations(ctx context.Context, req *pb.GetBl
 			}
 		}
 
-		log.Printf("GetBlockLocations (write) success: %s, inode=%d, %d blocks allocated", path, nodeInfo.Inode, len(blockLocations))
+		// 新文件块数更少时删除多余的旧映射
+		if len(oldBlocks) > len(blockLocations) {
+			if _, err := h.metadataService.TruncateBlockMappings(nodeInfo.Inode, uint64(len(blockLocations))); err != nil {
+				return nil, fmt.Errorf("failed to truncate block mappings: %v", err)
+			}
+		}
+
+		// 没有写租约时直接回收旧块
+		if h.leaseManager == nil {
+			for _, block := range oldBlocks {
+				for _, physical := range service.PhysicalBlocks(block) {
+					h.queueBlockForGC(physical.BlockID, physical.Locations)
+				}
+			}
+		}
+
+		log.Printf("GetBlockLocations (write) success: %s, inode=%d, %d blocks allocated, %d old blocks replaced",
+			path, nodeInfo.Inode, len(blockLocations), len(oldBlocks))
+
+		// 块映射已提交，令牌只出现在响应中
+		h.blockTokens.Attach(blockLocations, service.BlockTokenRead+service.BlockTokenWrite)
 
 		return &pb.GetBlockLocationsResponse{
 			Inode:          nodeInfo.Inode,
@@ -321,6 +636,100 @@ func (h *MetaServerHandler) GetBlockLocations(ctx context.Context, req *pb.GetBl
 	}
 }
 
+// allocateAppendBlocks 追加模式：未写满的尾块连同追加的数据写入新分配的块，只有已写满的块保持不变
+// 原来的尾块不修改，快照和写入中断后的回滚仍然引用它；写入完成时由写租约交给垃圾回收
+func (h *MetaServerHandler) allocateAppendBlocks(path string, nodeInfo *pb.NodeInfo, appendSize uint64) (*pb.GetBlockLocationsResponse, error) {
+	log.Printf("Append mode: appending %d bytes to %s (size=%d)", appendSize, path, nodeInfo.Size)
+
+	// tailIndex 为已写满的块数，tailOffset 为尾块中已有的数据长度
+	tailIndex, tailOffset := h.metadataService.LocateOffset(uint64(nodeInfo.Size))
+
+	var prevTail *pb.BlockLocations
+	if tailOffset > 0 {
+		tail, err := h.metadataService.GetBlockMapping(nodeInfo.Inode, tailIndex)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tail block %d of %s: %v", tailIndex, path, err)
+		}
+		prevTail = tail
+	}
+
+	// 文件末尾之后残留的映射（例如空文件预分配的块）先删除并回收
+	nextIndex := tailIndex
+	if prevTail != nil {
+		nextIndex++
+	}
+	if _, err := h.metadataService.GetBlockMapping(nodeInfo.Inode, nextIndex); err == nil {
+		stale, err := h.metadataService.TruncateBlockMappings(nodeInfo.Inode, nextIndex)
+		if err != nil {
+			return nil, fmt.Errorf("failed to truncate block mappings: %v", err)
+		}
+		for _, block := range stale {
+			h.queueBlockForGC(block.BlockID, block.Locations)
+		}
+	}
+
+	blockLocations, err := h.schedulerService.AllocateBlocks(tailOffset+appendSize, int(nodeInfo.Replication))
+	if err != nil {
+		return nil, err
+	}
+	for i, blockLoc := range blockLocations {
+		if err := h.metadataService.SetBlockMapping(nodeInfo.Inode, tailIndex+uint64(i), blockLoc); err != nil {
+			return nil, err
+		}
+	}
+
+	log.Printf("GetBlockLocations (append) success: %s, inode=%d, first block index=%d, tail offset=%d, %d blocks",
+		path, nodeInfo.Inode, tailIndex, tailOffset, len(blockLocations))
+
+	return &pb.GetBlockLocationsResponse{
+		Inode:           nodeInfo.Inode,
+		BlockLocations:  blockLocations,
+		FirstBlockIndex: tailIndex,
+		TailOffset:      tailOffset,
+		PrevTail:        prevTail,
+	}, nil
+}
+
+// queueBlockForGC 将不再被引用的块加入垃圾回收队列，由GC周期统一删除
+func (h *MetaServerHandler) queueBlockForGC(blockID uint64, locations []string) {
+	if err := h.metadataService.AddGCEntry(blockID, locations); err != nil {
+		log.Printf("Failed to add GC entry for block %d: %v", blockID, err)
+	}
+}
+
+// GetBlockRange 获取文件字节范围对应的数据块及块内偏移，用于随机读取
+func (h *MetaServerHandler) GetBlockRange(ctx context.Context, req *pb.GetBlockRangeRequest) (*pb.GetBlockRangeResponse, error) {
+	log.Printf("GetBlockRange request: path=%s, offset=%d, length=%d", req.Path, req.Offset, req.Length)
+
+	if req.Path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+
+	if req.Offset < 0 || req.Length < 0 {
+		return nil, fmt.Errorf("invalid range: offset=%d, length=%d", req.Offset, req.Length)
+	}
+
+	if err := h.metadataService.CheckPermission(callerFromContext(ctx), req.Path, 0, model.PermRead); err != nil {
+		return nil, err
+	}
+
+	nodeInfo, ranges, err := h.metadataService.GetBlockRange(req.Path, uint64(req.Offset), uint64(req.Length))
+	if err != nil {
+		log.Printf("GetBlockRange error: %v", err)
+		return nil, err
+	}
+
+	for _, r := range ranges {
+		h.blockTokens.Attach([]*pb.BlockLocations{r.Block}, service.BlockTokenRead)
+	}
+
+	return &pb.GetBlockRangeResponse{
+		Inode:  nodeInfo.Inode,
+		Size:   nodeInfo.Size,
+		Ranges: ranges,
+	}, nil
+}
+
 // FinalizeWrite 完成文件写入
 func (h *MetaServerHandler) FinalizeWrite(ctx context.Context, req *pb.FinalizeWriteRequest) (*pb.SimpleResponse, error) {
 	log.Printf("FinalizeWrite request: path=%s, inode=%d, size=%d, md5=%s", req.Path, req.Inode, req.Size, req.Md5)
@@ -338,52 +747,69 @@ func (h *MetaServerHandler) FinalizeWrite(ctx context.Context, req *pb.FinalizeW
 		return &pb.SimpleResponse{Success: false}, fmt.Errorf("not leader, cannot execute FinalizeWrite")
 	}
 
-	// 获取块映射信息用于WAL记录
+	// 检查写租约，写入方标识与获取租约时的取法一致
+	var lease *service.Lease
+	if h.leaseManager != nil {
+		var err error
+		lease, err = h.leaseManager.CheckHolder(filepath.Clean(req.Path), leaseHolder(ctx, req.ClientName), req.Inode)
+		if err != nil {
+			log.Printf("FinalizeWrite rejected: %v", err)
+			return &pb.SimpleResponse{Success: false, Message: err.Error()}, err
+		}
+	}
+
+	// 获取块映射信息，与文件大小一起记录到日志
 	blockMappings, err := h.metadataService.GetBlockMappings(req.Inode)
 	if err != nil {
 		log.Printf("FinalizeWrite error getting block mappings: %v", err)
 		return &pb.SimpleResponse{Success: false}, err
 	}
 
-	// 记录到WAL
-	walService := h.getWALService()
-	if walService != nil {
-		// 构建FinalizeWrite操作数据
-		finalizeWriteOp := pb.FinalizeWriteOperation{
-			Path:           req.Path,
-			Inode:          req.Inode,
-			Size:           req.Size,
-			Md5:            req.Md5,
-			BlockLocations: blockMappings,
-		}
-
-		// 添加WAL条目
-		entry, err := walService.AppendLogEntry(pb.WALOperationType_FINALIZE_WRITE, finalizeWriteOp)
-		if err != nil {
-			log.Printf("FinalizeWrite failed to append WAL entry: %v", err)
-			return &pb.SimpleResponse{Success: false}, err
-		}
-
-		// 同步到followers
-		err = walService.SyncToFollowers(entry)
-		if err != nil {
-			log.Printf("FinalizeWrite failed to sync to followers: %v", err)
-			// 注意：这里不返回错误，因为本地操作已成功，只是同步失败
-		}
+	// 通过日志提交，多数节点持久化后才返回成功；租约在同一条日志中释放，租约已被恢复或抢占时提交失败
+	holder := ""
+	if lease != nil {
+		holder = lease.Holder
 	}
-
-	// 执行本地FinalizeWrite操作
-	err = h.metadataService.FinalizeWrite(req.Path, req.Inode, uint64(req.Size), req.Md5)
+	err = h.metadataService.CommitLeasedFileState(req.Path, req.Inode, req.Size, req.Md5, blockMappings, holder)
 	if err != nil {
 		log.Printf("FinalizeWrite error: %v", err)
 		return &pb.SimpleResponse{Success: false}, err
 	}
 
+	// 回收被替换的旧块
+	if lease != nil {
+		h.leaseManager.Complete(lease, blockMappings)
+	}
+
 	log.Printf("FinalizeWrite success: %s (MD5: %s)", req.Path, req.Md5)
 	return &pb.SimpleResponse{Success: true}, nil
 }
 
-// GetClusterInfo 获取集群信息
+// RenewLease 续约写租约
+func (h *MetaServerHandler) RenewLease(ctx context.Context, req *pb.RenewLeaseRequest) (*pb.SimpleResponse, error) {
+	if !h.isLeader() {
+		return &pb.SimpleResponse{Success: false}, fmt.Errorf("not leader, cannot renew lease")
+	}
+
+	if h.leaseManager == nil {
+		return &pb.SimpleResponse{Success: true}, nil
+	}
+
+	path := req.Path
+	if path != "" {
+		path = filepath.Clean(path)
+	}
+
+	holder := leaseHolder(ctx, req.ClientName)
+	renewed := h.leaseManager.Renew(holder, path)
+	if renewed == 0 {
+		return &pb.SimpleResponse{Success: false, Message: fmt.Sprintf("no lease held by %s", holder)}, nil
+	}
+
+	return &pb.SimpleResponse{Success: true, Message: fmt.Sprintf("renewed %d leases", renewed)}, nil
+}
+
+// GetClusterInfo 获取集群信息
 func (h *MetaServerHandler) GetClusterInfo(ctx context.Context, req *pb.GetClusterInfoRequest) (*pb.GetClusterInfoResponse, error) {
 	log.Printf("GetClusterInfo request")
 
@@ -409,6 +835,10 @@ func (h *MetaServerHandler) GetFileBlocks(ctx context.Context, req *pb.GetBlockL
 		path = "/"
 	}
 
+	if err := h.metadataService.CheckPermission(callerFromContext(ctx), path, 0, model.PermRead); err != nil {
+		return nil, err
+	}
+
 	// 获取文件信息
 	nodeInfo, err := h.metadataService.GetNodeInfo(path)
 	if err != nil {
@@ -426,6 +856,7 @@ func (h *MetaServerHandler) GetFileBlocks(ctx context.Context, req *pb.GetBlockL
 	}
 
 	log.Printf("GetFileBlocks success: %s, inode=%d, %d blocks found", path, nodeInfo.Inode, len(blockMappings))
+	h.blockTokens.Attach(blockMappings, service.BlockTokenRead)
 
 	return &pb.GetBlockLocationsResponse{
 		Inode:          nodeInfo.Inode,
@@ -527,6 +958,136 @@ func (h *MetaServerHandler) GetReplicationInfo(ctx context.Context, req *pb.GetR
 	}, nil
 }
 
+// Fsck 流式返回子树下块的副本状态，最后一条消息携带汇总
+func (h *MetaServerHandler) Fsck(req *pb.FsckRequest, stream pb.MetaServerService_FsckServer) error {
+	log.Printf("Fsck request: path=%s, under_replicated=%v, missing=%v, corrupt=%v, trigger=%v",
+		req.Path, req.OnlyUnderReplicated, req.OnlyMissing, req.OnlyCorrupt, req.TriggerFsck)
+
+	if err := h.metadataService.CheckFsckPermission(callerFromContext(stream.Context())); err != nil {
+		return err
+	}
+	if req.TriggerFsck && !h.isLeader() {
+		return fmt.Errorf("only leader can run fsck")
+	}
+
+	batchSize := int(req.BatchSize)
+	if batchSize == 0 {
+		batchSize = defaultListBatchSize
+	}
+
+	var batch []*pb.FsckBlock
+	sent := 0
+	summary, err := h.schedulerService.FsckReport(req, func(block *pb.FsckBlock) error {
+		batch = append(batch, block)
+		if len(batch) < batchSize {
+			return nil
+		}
+		if err := stream.Send(&pb.FsckResponse{Blocks: batch}); err != nil {
+			return err
+		}
+		sent += len(batch)
+		batch = nil
+		return nil
+	})
+	if err != nil {
+		log.Printf("Fsck error: %v", err)
+		return err
+	}
+
+	// 报告反映触发前的状态，修复结果在之后的 FSCK 中体现
+	if req.TriggerFsck {
+		h.schedulerService.ForceFSCKUnder(req.Path)
+		summary.FsckTriggered = true
+	}
+
+	log.Printf("Fsck success: %s files=%d blocks=%d under=%d missing=%d corrupt=%d reported=%d",
+		req.Path, summary.TotalFiles, summary.TotalBlocks, summary.UnderReplicatedBlocks,
+		summary.MissingBlocks, summary.CorruptBlocks, sent+len(batch))
+	return stream.Send(&pb.FsckResponse{Blocks: batch, Summary: summary})
+}
+
+// SetReplication 修改文件的副本数
+func (h *MetaServerHandler) SetReplication(ctx context.Context, req *pb.SetReplicationRequest) (*pb.SimpleResponse, error) {
+	log.Printf("SetReplication request: path=%s, replication=%d", req.Path, req.Replication)
+
+	if req.Path == "" {
+		return &pb.SimpleResponse{Success: false}, fmt.Errorf("path cannot be empty")
+	}
+
+	if !h.isLeader() {
+		return &pb.SimpleResponse{Success: false}, fmt.Errorf("only leader can handle write operations")
+	}
+
+	if err := h.metadataService.CheckPermission(callerFromContext(ctx), req.Path, 0, model.PermWrite); err != nil {
+		return &pb.SimpleResponse{Success: false}, err
+	}
+
+	if err := h.metadataService.SetReplication(req.Path, req.Replication); err != nil {
+		log.Printf("SetReplication error: %v", err)
+		return &pb.SimpleResponse{Success: false}, err
+	}
+
+	log.Printf("SetReplication success: %s -> %d", req.Path, req.Replication)
+	return &pb.SimpleResponse{Success: true}, nil
+}
+
+// Chmod 修改节点的权限位
+func (h *MetaServerHandler) Chmod(ctx context.Context, req *pb.ChmodRequest) (*pb.SimpleResponse, error) {
+	log.Printf("Chmod request: path=%s, mode=%o", req.Path, req.Mode)
+
+	if req.Path == "" {
+		return &pb.SimpleResponse{Success: false}, fmt.Errorf("path cannot be empty")
+	}
+
+	if !h.isLeader() {
+		return &pb.SimpleResponse{Success: false}, fmt.Errorf("only leader can handle write operations")
+	}
+
+	if err := h.metadataService.Chmod(callerFromContext(ctx), req.Path, req.Mode); err != nil {
+		log.Printf("Chmod error: %v", err)
+		return &pb.SimpleResponse{Success: false}, err
+	}
+	return &pb.SimpleResponse{Success: true}, nil
+}
+
+// Chown 修改节点的属主和属组
+func (h *MetaServerHandler) Chown(ctx context.Context, req *pb.ChownRequest) (*pb.SimpleResponse, error) {
+	log.Printf("Chown request: path=%s, owner=%s, group=%s", req.Path, req.Owner, req.Group)
+
+	if req.Path == "" {
+		return &pb.SimpleResponse{Success: false}, fmt.Errorf("path cannot be empty")
+	}
+
+	if !h.isLeader() {
+		return &pb.SimpleResponse{Success: false}, fmt.Errorf("only leader can handle write operations")
+	}
+
+	if err := h.metadataService.Chown(callerFromContext(ctx), req.Path, req.Owner, req.Group); err != nil {
+		log.Printf("Chown error: %v", err)
+		return &pb.SimpleResponse{Success: false}, err
+	}
+	return &pb.SimpleResponse{Success: true}, nil
+}
+
+// SetAcl 替换节点的 ACL 条目
+func (h *MetaServerHandler) SetAcl(ctx context.Context, req *pb.SetAclRequest) (*pb.SimpleResponse, error) {
+	log.Printf("SetAcl request: path=%s, %d entries", req.Path, len(req.Acl))
+
+	if req.Path == "" {
+		return &pb.SimpleResponse{Success: false}, fmt.Errorf("path cannot be empty")
+	}
+
+	if !h.isLeader() {
+		return &pb.SimpleResponse{Success: false}, fmt.Errorf("only leader can handle write operations")
+	}
+
+	if err := h.metadataService.SetAcl(callerFromContext(ctx), req.Path, req.Acl); err != nil {
+		log.Printf("SetAcl error: %v", err)
+		return &pb.SimpleResponse{Success: false}, err
+	}
+	return &pb.SimpleResponse{Success: true}, nil
+}
+
 // buildReplicationStatus 构建单个文件的副本状态信息
 func (h *MetaServerHandler) buildReplicationStatus(nodeInfo *pb.NodeInfo) (*pb.ReplicationStatus, error) {
 	// 获取文件的所有块映射
@@ -537,46 +1098,56 @@ func (h *MetaServerHandler) buildReplicationStatus(nodeInfo *pb.NodeInfo) (*pb.R
 
 	var blockInfos []*pb.BlockReplicationInfo
 	var totalReplicas uint32 = 0
-	var minReplicas uint32 = ^uint32(0) // 最大值
+	underReplicated, overReplicated := false, false
 
 	for _, blockMapping := range blockMappings {
-		actualLocations := make([]string, 0)
+		// 纠删码块组的每个条带各有一个位置，按物理块逐个检查
+		expected := nodeInfo.Replication
+		if blockMapping.EcPolicy != "" {
+			expected = 1
+		}
 
-		// 检查每个声明的位置是否真实存在这个块
-		for _, location := range blockMapping.Locations {
-			dataServer := h.clusterService.GetDataServerByAddr(location)
-			if dataServer != nil && dataServer.HasBlock(blockMapping.BlockId) {
-				actualLocations = append(actualLocations, location)
+		for _, block := range service.PhysicalBlocks(blockMapping) {
+			actualLocations := make([]string, 0)
+
+			// 检查每个声明的位置是否真实存在这个块
+			for _, location := range block.Locations {
+				dataServer := h.clusterService.GetDataServerByAddr(location)
+				if dataServer != nil && dataServer.HasBlock(block.BlockID) {
+					actualLocations = append(actualLocations, location)
+				}
 			}
-		}
 
-		replicaCount := uint32(len(actualLocations))
-		totalReplicas += replicaCount
+			replicaCount := uint32(len(actualLocations))
+			totalReplicas += replicaCount
 
-		if replicaCount < minReplicas {
-			minReplicas = replicaCount
-		}
+			if replicaCount < expected {
+				underReplicated = true
+			} else if replicaCount > expected {
+				overReplicated = true
+			}
 
-		blockInfo := &pb.BlockReplicationInfo{
-			BlockId:           blockMapping.BlockId,
-			Locations:         actualLocations,
-			ExpectedLocations: blockMapping.Locations,
-			ReplicaCount:      replicaCount,
-		}
+			blockInfo := &pb.BlockReplicationInfo{
+				BlockId:           block.BlockID,
+				Locations:         actualLocations,
+				ExpectedLocations: block.Locations,
+				ReplicaCount:      replicaCount,
+			}
 
-		blockInfos = append(blockInfos, blockInfo)
+			blockInfos = append(blockInfos, blockInfo)
+		}
 	}
 
 	var actualReplicas uint32
-	if len(blockMappings) > 0 {
-		actualReplicas = totalReplicas / uint32(len(blockMappings))
+	if len(blockInfos) > 0 {
+		actualReplicas = totalReplicas / uint32(len(blockInfos))
 	}
 
 	// 确定健康状态
 	status := "healthy"
-	if minReplicas < nodeInfo.Replication {
+	if underReplicated {
 		status = "under_replicated"
-	} else if minReplicas > nodeInfo.Replication {
+	} else if overReplicated {
 		status = "over_replicated"
 	}
 
@@ -609,70 +1180,396 @@ func (h *MetaServerHandler) getAllFileNodes() ([]*pb.NodeInfo, error) {
 	return files, nil
 }
 
-// SyncWAL 同步 WAL（用于主从复制）
-func (h *MetaServerHandler) SyncWAL(stream pb.MetaServerService_SyncWALServer) error {
-	log.Printf("SyncWAL stream started")
+// SetQuota 设置目录配额
+func (h *MetaServerHandler) SetQuota(ctx context.Context, req *pb.SetQuotaRequest) (*pb.SimpleResponse, error) {
+	log.Printf("SetQuota request: path=%s, max_bytes=%d, max_inodes=%d", req.Path, req.MaxBytes, req.MaxInodes)
+
+	if err := h.metadataService.CheckSuperuserPermission(callerFromContext(ctx), "set quota"); err != nil {
+		return &pb.SimpleResponse{Success: false}, err
+	}
+	if req.Path == "" {
+		return &pb.SimpleResponse{Success: false}, fmt.Errorf("path cannot be empty")
+	}
+	if !h.isLeader() {
+		return &pb.SimpleResponse{Success: false}, fmt.Errorf("only leader can handle write operations")
+	}
 
-	var processedCount int
+	if err := h.metadataService.SetQuota(req.Path, req.MaxBytes, req.MaxInodes); err != nil {
+		log.Printf("SetQuota error: %v", err)
+		return &pb.SimpleResponse{Success: false, Message: err.Error()}, err
+	}
+	return &pb.SimpleResponse{Success: true}, nil
+}
 
-	for {
-		entry, err := stream.Recv()
-		if err != nil {
-			if err.Error() == "EOF" {
-				log.Printf("SyncWAL stream ended, processed %d entries", processedCount)
-				return stream.SendAndClose(&pb.SimpleResponse{
-					Success: true,
-					Message: fmt.Sprintf("Processed %d WAL entries", processedCount),
-				})
-			}
-			log.Printf("SyncWAL stream error: %v", err)
-			return err
-		}
+// GetQuota 获取目录配额和使用量
+func (h *MetaServerHandler) GetQuota(ctx context.Context, req *pb.GetQuotaRequest) (*pb.GetQuotaResponse, error) {
+	if req.Path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
 
-		// 检查是否有WAL服务
-		walService := h.getWALService()
-		if walService == nil {
-			log.Printf("WAL service not available, skipping entry %d", entry.LogIndex)
-			continue
+	usage, err := h.metadataService.GetQuota(req.Path)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetQuotaResponse{Usage: usage}, nil
+}
+
+// GetUsageReport 按目录报告使用量和配额
+func (h *MetaServerHandler) GetUsageReport(ctx context.Context, req *pb.GetUsageReportRequest) (*pb.GetUsageReportResponse, error) {
+	if req.Path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+
+	directories, err := h.metadataService.GetUsageReport(req.Path, req.Recursive)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetUsageReportResponse{Directories: directories}, nil
+}
+
+// GetOrphanReport 获取 FSCK 孤儿块处理的统计和当前孤儿块列表
+func (h *MetaServerHandler) GetOrphanReport(ctx context.Context, req *pb.GetOrphanReportRequest) (*pb.GetOrphanReportResponse, error) {
+	stats := h.schedulerService.GetOrphanStats()
+
+	resp := &pb.GetOrphanReportResponse{
+		DryRun:        stats.DryRun,
+		LastPassFound: uint32(stats.LastPassFound),
+		Pending:       uint32(stats.Pending),
+		DeletedTotal:  stats.Deleted,
+		ReportedTotal: stats.Reported,
+	}
+	if !stats.LastPassTime.IsZero() {
+		resp.LastPassTime = stats.LastPassTime.UnixMilli()
+	}
+	for _, orphan := range stats.Orphans {
+		ageMs := int64(-1)
+		if orphan.Age >= 0 {
+			ageMs = orphan.Age.Milliseconds()
 		}
+		resp.Orphans = append(resp.Orphans, &pb.OrphanBlock{
+			BlockId:   orphan.BlockID,
+			Locations: orphan.Locations,
+			Passes:    uint32(orphan.Passes),
+			AgeMs:     ageMs,
+			Eligible:  orphan.Eligible,
+		})
+	}
+	return resp, nil
+}
 
-		// 检查当前节点是否为leader
-		isLeader := h.isLeader()
+// SetErasureCodingPolicy 设置目录的纠删码策略
+func (h *MetaServerHandler) SetErasureCodingPolicy(ctx context.Context, req *pb.SetErasureCodingPolicyRequest) (*pb.SimpleResponse, error) {
+	log.Printf("SetErasureCodingPolicy request: path=%s, policy=%s", req.Path, req.Policy)
 
-		if isLeader {
-			// 作为leader：回放WAL条目（执行操作）
-			err = walService.ReplayLogEntry(entry, h.metadataService)
-			if err != nil {
-				log.Printf("Failed to replay WAL entry %d: %v", entry.LogIndex, err)
-				return stream.SendAndClose(&pb.SimpleResponse{
-					Success: false,
-					Message: fmt.Sprintf("Failed to replay entry %d: %v", entry.LogIndex, err),
-				})
-			}
-			log.Printf("SyncWAL (Leader): Replayed entry %d, operation: %v", entry.LogIndex, entry.Operation)
-		} else {
-			// 作为follower：只存储WAL条目，不执行操作
-			log.Printf("SyncWAL (Follower): Storing entry %d without execution, operation: %v",
-				entry.LogIndex, entry.Operation)
+	if err := h.metadataService.CheckSuperuserPermission(callerFromContext(ctx), "set erasure coding policy"); err != nil {
+		return &pb.SimpleResponse{Success: false}, err
+	}
+	if req.Path == "" {
+		return &pb.SimpleResponse{Success: false}, fmt.Errorf("path cannot be empty")
+	}
+	if !h.isLeader() {
+		return &pb.SimpleResponse{Success: false}, fmt.Errorf("only leader can handle write operations")
+	}
+
+	if err := h.metadataService.SetErasureCodingPolicy(req.Path, req.Policy); err != nil {
+		log.Printf("SetErasureCodingPolicy error: %v", err)
+		return &pb.SimpleResponse{Success: false, Message: err.Error()}, err
+	}
+
+	log.Printf("SetErasureCodingPolicy success: %s -> %q", req.Path, req.Policy)
+	return &pb.SimpleResponse{Success: true}, nil
+}
+
+// GetErasureCodingPolicy 获取路径生效的纠删码策略
+func (h *MetaServerHandler) GetErasureCodingPolicy(ctx context.Context, req *pb.GetErasureCodingPolicyRequest) (*pb.GetErasureCodingPolicyResponse, error) {
+	if req.Path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+
+	policy, dir, err := h.metadataService.GetErasureCodingPolicy(req.Path)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetErasureCodingPolicyResponse{Policy: policy, Directory: dir}, nil
+}
+
+// SetDataServerState 设置 DataServer 的管理状态
+func (h *MetaServerHandler) SetDataServerState(ctx context.Context, req *pb.SetDataServerStateRequest) (*pb.SimpleResponse, error) {
+	log.Printf("SetDataServerState request: address=%s, state=%s, maintenance_duration=%ds", req.Address, req.State, req.MaintenanceDuration)
+
+	if err := h.metadataService.CheckSuperuserPermission(callerFromContext(ctx), "change dataserver state"); err != nil {
+		return &pb.SimpleResponse{Success: false}, err
+	}
+	if !h.isLeader() {
+		return &pb.SimpleResponse{Success: false}, fmt.Errorf("only leader can handle write operations")
+	}
+	// 已退役状态由 FSCK 在块迁移完成后设置
+	if req.State == model.DataServerDecommissioned {
+		return &pb.SimpleResponse{Success: false}, fmt.Errorf("state %s is set automatically once decommissioning completes", req.State)
+	}
+
+	duration := time.Duration(req.MaintenanceDuration) * time.Second
+	if err := h.metadataService.SetDataServerState(req.Address, req.State, duration); err != nil {
+		log.Printf("SetDataServerState error: %v", err)
+		return &pb.SimpleResponse{Success: false, Message: err.Error()}, err
+	}
+
+	log.Printf("SetDataServerState success: %s -> %s", req.Address, req.State)
+	return &pb.SimpleResponse{Success: true}, nil
+}
+
+// ListDataServerStates 列出集群中和设置了管理状态的所有 DataServer
+func (h *MetaServerHandler) ListDataServerStates(ctx context.Context, req *pb.ListDataServerStatesRequest) (*pb.ListDataServerStatesResponse, error) {
+	states, err := h.metadataService.GetDataServerStates()
+	if err != nil {
+		return nil, err
+	}
+	progress := h.schedulerService.GetDecommissionProgress()
+	now := time.Now()
+
+	servers := make(map[string]*pb.DataServerState)
+	for _, ds := range h.clusterService.GetAllDataServers() {
+		_, _, _, _, isHealthy := ds.GetStatus()
+		servers[ds.Addr] = &pb.DataServerState{
+			Address: ds.Addr,
+			Id:      ds.ID,
+			State:   model.DataServerInService,
+			Healthy: isHealthy && !ds.IsPermanentlyDownStatus(),
+		}
+	}
+	for addr, state := range states {
+		server, ok := servers[addr]
+		if !ok {
+			server = &pb.DataServerState{Address: addr}
+			servers[addr] = server
 		}
+		// 到期未清理的维护模式按 in_service 报告
+		if state.State == model.DataServerMaintenance && now.UnixMilli() >= state.MaintenanceExpire {
+			continue
+		}
+		server.State = state.State
+		server.StateTime = state.StateTime
+		server.MaintenanceExpireTime = state.MaintenanceExpire
+		server.RemainingBlocks = uint64(progress[addr])
+	}
 
-		// 将日志条目写入本地WAL存储
-		err = walService.WriteLogEntry(entry)
-		if err != nil {
-			log.Printf("Failed to write WAL entry %d locally: %v", entry.LogIndex, err)
-			return stream.SendAndClose(&pb.SimpleResponse{
-				Success: false,
-				Message: fmt.Sprintf("Failed to write entry %d locally: %v", entry.LogIndex, err),
-			})
+	resp := &pb.ListDataServerStatesResponse{}
+	for _, server := range servers {
+		if server.State == "" {
+			server.State = model.DataServerInService
 		}
+		resp.Servers = append(resp.Servers, server)
+	}
+	sort.Slice(resp.Servers, func(i, j int) bool {
+		return resp.Servers[i].Address < resp.Servers[j].Address
+	})
+	return resp, nil
+}
 
-		// 更新下一个日志索引
-		walService.UpdateNextLogIndex(entry.LogIndex + 1)
+// StartBalancer 启动磁盘使用率均衡器
+func (h *MetaServerHandler) StartBalancer(ctx context.Context, req *pb.StartBalancerRequest) (*pb.SimpleResponse, error) {
+	log.Printf("StartBalancer request: threshold=%v, bandwidth=%d", req.Threshold, req.Bandwidth)
 
-		processedCount++
-		log.Printf("SyncWAL: Processed entry %d, operation: %v, isLeader: %v",
-			entry.LogIndex, entry.Operation, isLeader)
+	if err := h.metadataService.CheckSuperuserPermission(callerFromContext(ctx), "run the balancer"); err != nil {
+		return &pb.SimpleResponse{Success: false}, err
+	}
+	if !h.isLeader() {
+		return &pb.SimpleResponse{Success: false}, fmt.Errorf("only leader can run the balancer")
+	}
+	if h.balancer == nil {
+		return &pb.SimpleResponse{Success: false}, fmt.Errorf("balancer is not configured")
+	}
+
+	if err := h.balancer.Start(req.Threshold, req.Bandwidth); err != nil {
+		log.Printf("StartBalancer error: %v", err)
+		return &pb.SimpleResponse{Success: false}, err
+	}
+	return &pb.SimpleResponse{Success: true}, nil
+}
+
+// StopBalancer 停止磁盘使用率均衡器
+func (h *MetaServerHandler) StopBalancer(ctx context.Context, req *pb.StopBalancerRequest) (*pb.SimpleResponse, error) {
+	log.Printf("StopBalancer request")
+
+	if err := h.metadataService.CheckSuperuserPermission(callerFromContext(ctx), "stop the balancer"); err != nil {
+		return &pb.SimpleResponse{Success: false}, err
+	}
+	if h.balancer == nil {
+		return &pb.SimpleResponse{Success: false}, fmt.Errorf("balancer is not configured")
+	}
+	h.balancer.Stop()
+	return &pb.SimpleResponse{Success: true}, nil
+}
+
+// GetBalancerStatus 获取均衡器状态和各 DataServer 的使用率
+func (h *MetaServerHandler) GetBalancerStatus(ctx context.Context, req *pb.GetBalancerStatusRequest) (*pb.GetBalancerStatusResponse, error) {
+	if h.balancer == nil {
+		return nil, fmt.Errorf("balancer is not configured")
+	}
+	status := h.balancer.Status()
+
+	resp := &pb.GetBalancerStatusResponse{
+		Running:            status.Running,
+		Threshold:          status.Threshold,
+		Bandwidth:          status.Bandwidth,
+		AverageUtilization: status.AverageUtilization,
+		PendingMoves:       uint64(status.PendingMoves),
+		MovedBlocks:        status.MovedBlocks,
+		MovedBytes:         status.MovedBytes,
+		FailedMoves:        status.FailedMoves,
+		Message:            status.Message,
+	}
+	if !status.StartTime.IsZero() {
+		resp.StartTime = status.StartTime.UnixMilli()
+	}
+	if !status.LastIterationTime.IsZero() {
+		resp.LastIterationTime = status.LastIterationTime.UnixMilli()
+	}
+	for _, server := range status.Servers {
+		resp.Servers = append(resp.Servers, &pb.ServerUtilization{
+			Address:     server.Addr,
+			Used:        server.Used,
+			Capacity:    server.Capacity,
+			Utilization: server.Utilization,
+			Class:       server.Class,
+		})
+	}
+	return resp, nil
+}
+
+// CreateSnapshot 为目录子树创建只读快照
+func (h *MetaServerHandler) CreateSnapshot(ctx context.Context, req *pb.CreateSnapshotRequest) (*pb.SimpleResponse, error) {
+	log.Printf("CreateSnapshot request: path=%s, name=%s", req.Path, req.Name)
+
+	if err := h.metadataService.CheckSuperuserPermission(callerFromContext(ctx), "create snapshots"); err != nil {
+		return &pb.SimpleResponse{Success: false}, err
+	}
+	if req.Path == "" {
+		return &pb.SimpleResponse{Success: false}, fmt.Errorf("path cannot be empty")
+	}
+
+	if !h.isLeader() {
+		return &pb.SimpleResponse{Success: false}, fmt.Errorf("only leader can handle write operations")
+	}
+
+	if err := h.metadataService.CreateSnapshot(req.Path, req.Name); err != nil {
+		log.Printf("CreateSnapshot error: %v", err)
+		return &pb.SimpleResponse{Success: false}, err
+	}
+
+	log.Printf("CreateSnapshot success: %s of %s", req.Name, req.Path)
+	return &pb.SimpleResponse{Success: true}, nil
+}
+
+// DeleteSnapshot 删除快照，不再被引用的块加入垃圾回收队列
+func (h *MetaServerHandler) DeleteSnapshot(ctx context.Context, req *pb.DeleteSnapshotRequest) (*pb.SimpleResponse, error) {
+	log.Printf("DeleteSnapshot request: name=%s", req.Name)
+
+	if err := h.metadataService.CheckSuperuserPermission(callerFromContext(ctx), "delete snapshots"); err != nil {
+		return &pb.SimpleResponse{Success: false}, err
+	}
+	if req.Name == "" {
+		return &pb.SimpleResponse{Success: false}, fmt.Errorf("name cannot be empty")
+	}
+
+	if !h.isLeader() {
+		return &pb.SimpleResponse{Success: false}, fmt.Errorf("only leader can handle write operations")
+	}
+
+	released, err := h.metadataService.DeleteSnapshot(req.Name)
+	if err != nil {
+		log.Printf("DeleteSnapshot error: %v", err)
+		return &pb.SimpleResponse{Success: false}, err
+	}
+	for _, block := range released {
+		h.queueBlockForGC(block.BlockID, block.Locations)
+	}
+
+	log.Printf("DeleteSnapshot success: %s (%d blocks queued for GC)", req.Name, len(released))
+	return &pb.SimpleResponse{Success: true}, nil
+}
+
+// ListSnapshots 列出所有快照
+func (h *MetaServerHandler) ListSnapshots(ctx context.Context, req *pb.ListSnapshotsRequest) (*pb.ListSnapshotsResponse, error) {
+	snapshots, err := h.metadataService.ListSnapshots()
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []*pb.SnapshotInfo
+	for _, snapshot := range snapshots {
+		infos = append(infos, &pb.SnapshotInfo{
+			Name:       snapshot.Name,
+			Path:       snapshot.Path,
+			CreatedAt:  snapshot.CreatedAt,
+			Bytes:      snapshot.Bytes,
+			InodeCount: snapshot.InodeCount,
+		})
+	}
+	return &pb.ListSnapshotsResponse{Snapshots: infos}, nil
+}
+
+// SyncWAL 旧版主从日志推送接口，日志复制已由 AppendEntries 取代
+func (h *MetaServerHandler) SyncWAL(stream pb.MetaServerService_SyncWALServer) error {
+	log.Printf("SyncWAL rejected: log replication is handled by AppendEntries")
+	return stream.SendAndClose(&pb.SimpleResponse{
+		Success: false,
+		Message: "SyncWAL is no longer supported, log entries are replicated through AppendEntries",
+	})
+}
+
+// RequestVote 处理Raft投票请求
+func (h *MetaServerHandler) RequestVote(ctx context.Context, req *pb.RequestVoteRequest) (*pb.RequestVoteResponse, error) {
+	if h.raftNode == nil {
+		return nil, fmt.Errorf("raft is not enabled on this node")
+	}
+	return h.raftNode.HandleRequestVote(req)
+}
+
+// AppendEntries 处理Raft日志复制和心跳
+func (h *MetaServerHandler) AppendEntries(ctx context.Context, req *pb.AppendEntriesRequest) (*pb.AppendEntriesResponse, error) {
+	if h.raftNode == nil {
+		return nil, fmt.Errorf("raft is not enabled on this node")
+	}
+	return h.raftNode.HandleAppendEntries(req)
+}
+
+// InstallSnapshot 接收leader分块发送的快照并安装
+func (h *MetaServerHandler) InstallSnapshot(stream pb.MetaServerService_InstallSnapshotServer) error {
+	if h.raftNode == nil {
+		return fmt.Errorf("raft is not enabled on this node")
+	}
+
+	req, err := stream.Recv()
+	if err != nil {
+		return fmt.Errorf("failed to receive snapshot: %v", err)
+	}
+
+	resp, err := h.raftNode.HandleInstallSnapshot(req, &snapshotStreamReader{stream: stream, buf: req.Data})
+	if err != nil {
+		log.Printf("InstallSnapshot %d from %s failed: %v", req.LastIncludedIndex, req.LeaderId, err)
+		return err
+	}
+	return stream.SendAndClose(resp)
+}
+
+// snapshotStreamReader 将 InstallSnapshot 流中的数据分块拼接为连续的快照内容
+type snapshotStreamReader struct {
+	stream pb.MetaServerService_InstallSnapshotServer
+	buf    []byte
+}
+
+func (r *snapshotStreamReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		chunk, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		r.buf = chunk.Data
 	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
 }
 
 // RequestWALSync 处理节点重连后的WAL同步请求
@@ -759,6 +1656,11 @@ func (h *MetaServerHandler) nodeInfoToStatInfo(nodeInfo *pb.NodeInfo) *pb.StatIn
 		Mtime:       nodeInfo.Mtime,
 		Type:        nodeInfo.Type, // 使用统一的 FileType
 		ReplicaData: replicaData,
+		Owner:       nodeInfo.Owner,
+		Group:       nodeInfo.Group,
+		Mode:        nodeInfo.Mode,
+		Acl:         nodeInfo.Acl,
+		ChildCount:  nodeInfo.ChildCount,
 	}
 }
 
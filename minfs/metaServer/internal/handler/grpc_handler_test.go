@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestCallerFromContext(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		"minfs-user", "alice", "minfs-groups", "staff, supergroup"))
+	caller := callerFromContext(ctx)
+	if caller.User != "alice" || !reflect.DeepEqual(caller.Groups, []string{"staff", "supergroup"}) {
+		t.Errorf("caller without mTLS: %+v", caller)
+	}
+
+	// mTLS 连接忽略客户端声明的用户和组，使用证书中的 CN 和 OU
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "bob", OrganizationalUnit: []string{"ops"}}}
+	ctx = peer.NewContext(ctx, &peer.Peer{AuthInfo: credentials.TLSInfo{
+		State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+	}})
+	caller = callerFromContext(ctx)
+	if caller.User != "bob" || !reflect.DeepEqual(caller.Groups, []string{"ops"}) {
+		t.Errorf("caller with mTLS: %+v", caller)
+	}
+}
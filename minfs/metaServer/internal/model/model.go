@@ -5,6 +5,8 @@ import (
 	"sync"
 	"time"
 
+	"metaServer/pb"
+
 	"gopkg.in/yaml.v3"
 )
 
@@ -21,8 +23,10 @@ type Config struct {
 
 	Cluster struct {
 		DefaultReplication     int           `yaml:"default_replication"`
+		MaxReplication         int           `yaml:"max_replication"` // 单个文件允许的最大副本数
 		HeartbeatTimeout       time.Duration `yaml:"heartbeat_timeout"`
 		PermanentDownThreshold time.Duration `yaml:"permanent_down_threshold"`
+		MaintenanceDuration    time.Duration `yaml:"maintenance_duration"` // 维护模式的默认持续时间
 	} `yaml:"cluster"`
 
 	Etcd struct {
@@ -39,14 +43,81 @@ type Config struct {
 		RepairWorkers        int           `yaml:"repair_workers"`
 		RepairQueueSize      int           `yaml:"repair_queue_size"`
 		MaxConcurrentRepairs int           `yaml:"max_concurrent_repairs"`
+		OrphanGracePasses    int           `yaml:"orphan_grace_passes"` // 孤儿块连续出现多少次 FSCK 后删除
+		OrphanMinAge         time.Duration `yaml:"orphan_min_age"`      // 按块ID中的时间戳超过该年龄的孤儿块不必等待宽限次数
+		OrphanDryRun         bool          `yaml:"orphan_dry_run"`      // 只报告孤儿块，不删除
+		PlacementPolicy      string        `yaml:"placement_policy"`    // 副本放置策略: distinct, capacity, rack-aware
 	} `yaml:"scheduler"`
 
+	Lease struct {
+		SoftLimit     time.Duration `yaml:"soft_limit"`     // 超过该时间未续约，其他写入方可以抢占
+		HardLimit     time.Duration `yaml:"hard_limit"`     // 超过该时间未续约，后台自动恢复文件
+		CheckInterval time.Duration `yaml:"check_interval"` // 过期租约检查间隔
+	} `yaml:"lease"`
+
+	Raft struct {
+		Peers             []RaftPeer    `yaml:"peers"`              // 集群中所有MetaServer（包括自身）
+		ElectionTimeout   time.Duration `yaml:"election_timeout"`   // 选举超时下限，实际超时在 [t, 2t) 内随机
+		HeartbeatInterval time.Duration `yaml:"heartbeat_interval"` // Leader心跳间隔
+		ProposeTimeout    time.Duration `yaml:"propose_timeout"`    // 写操作等待多数节点提交的超时
+		SnapshotInterval  time.Duration `yaml:"snapshot_interval"`  // 检查是否需要生成快照的间隔
+		SnapshotThreshold uint64        `yaml:"snapshot_threshold"` // 距上次快照应用了多少条日志后生成新快照
+	} `yaml:"raft"`
+
+	ErasureCoding struct {
+		ConvertInterval    time.Duration `yaml:"convert_interval"`     // 将纠删码目录下的多副本文件转换为纠删码的检查间隔，0 表示不转换
+		ConvertTimeout     time.Duration `yaml:"convert_timeout"`      // 块转换等待所有条带上报的超时
+		MaxPendingConverts int           `yaml:"max_pending_converts"` // 同时进行的块转换数上限
+	} `yaml:"erasure_coding"`
+
+	Balancer struct {
+		Threshold          float64       `yaml:"threshold"`            // 节点使用率与集群平均使用率允许的偏差 (0-1)
+		Bandwidth          uint64        `yaml:"bandwidth"`            // 每个块迁移的复制带宽上限（字节/秒），0 表示不限速
+		Interval           time.Duration `yaml:"interval"`             // 均衡迭代间隔
+		MaxConcurrentMoves int           `yaml:"max_concurrent_moves"` // 同时进行的块迁移数上限
+		MoveTimeout        time.Duration `yaml:"move_timeout"`         // 块迁移等待目标节点上报的超时
+	} `yaml:"balancer"`
+
+	Trash struct {
+		Enabled       bool          `yaml:"enabled"`        // 删除时移入回收站而不是直接删除
+		Retention     time.Duration `yaml:"retention"`      // 回收站中的节点保留多久后彻底删除
+		CheckInterval time.Duration `yaml:"check_interval"` // 过期回收站检查间隔
+	} `yaml:"trash"`
+
+	Security struct {
+		PermissionsEnabled bool   `yaml:"permissions_enabled"` // 是否检查权限，关闭时仍记录属主和权限位
+		Superuser          string `yaml:"superuser"`           // 超级用户，不受权限限制
+		Supergroup         string `yaml:"supergroup"`          // 超级用户组，组内用户均为超级用户
+		DefaultUser        string `yaml:"default_user"`        // 未携带身份的调用者使用的用户名
+
+		UserGroups map[string][]string `yaml:"user_groups"` // 服务端配置的用户所属组，与客户端声明或证书中的组合并
+
+		TLS                TLSConfig     `yaml:"tls"`
+		BlockTokenKey      string        `yaml:"block_token_key"`      // 块访问令牌的 HMAC 密钥，与 DataServer 一致，为空时不签发令牌
+		BlockTokenLifetime time.Duration `yaml:"block_token_lifetime"` // 块访问令牌的有效期
+	} `yaml:"security"`
+
 	Logging struct {
 		Level string `yaml:"level"`
 		File  string `yaml:"file"`
 	} `yaml:"logging"`
 }
 
+// TLSConfig gRPC 传输层安全配置
+type TLSConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	CertFile   string `yaml:"cert_file"`   // 本节点证书，作为服务端和客户端（mTLS）使用
+	KeyFile    string `yaml:"key_file"`    // 本节点私钥
+	CAFile     string `yaml:"ca_file"`     // 校验对端证书的 CA，为空时使用系统根证书
+	ClientAuth bool   `yaml:"client_auth"` // 是否要求并校验客户端证书 (mTLS)
+}
+
+// RaftPeer Raft 集群成员
+type RaftPeer struct {
+	ID   string `yaml:"id"`   // 节点ID，与 -node-id 一致
+	Addr string `yaml:"addr"` // gRPC 地址 (IP:Port)
+}
+
 // LoadConfig 从文件加载配置
 func LoadConfig(configPath string) (*Config, error) {
 	data, err := os.ReadFile(configPath)
@@ -62,6 +133,9 @@ func LoadConfig(configPath string) (*Config, error) {
 	return &config, nil
 }
 
+// DefaultTopology 未上报拓扑标签的 DataServer 所在的机架
+const DefaultTopology = "/default-zone/default-rack"
+
 // DataServerInfo 存储 DataServer 的运行时状态信息
 type DataServerInfo struct {
 	ID             string          // DataServer 唯一标识符
@@ -72,10 +146,17 @@ type DataServerInfo struct {
 	LastHeartbeat  time.Time       // 最后心跳时间
 	IsHealthy      bool            // 是否健康 (基于心跳超时判断)
 	ReportedBlocks map[uint64]bool // 当前报告的块列表
+	CorruptBlocks  map[uint64]bool // 最近一次心跳上报的损坏块
+	Topology       string          // 拓扑标签 (/zone/rack)，来自注册信息或心跳，为空时视为 DefaultTopology
 
 	// 用于调度算法的轮询计数器
 	RoundRobinIndex int
 
+	// 后台块巡检统计 (来自心跳)
+	ScrubBlocksScanned uint64
+	ScrubCorruptBlocks uint64
+	ScrubLastPassTime  time.Time
+
 	// 节点宕机相关
 	UnhealthyStartTime *time.Time // 节点变为不健康的开始时间
 	IsPermanentlyDown  bool       // 是否被标记为永久宕机
@@ -96,6 +177,38 @@ func (ds *DataServerInfo) UpdateStatus(blockCount, freeSpace, totalCapacity uint
 	ds.IsHealthy = true
 }
 
+// SetTopology 更新拓扑标签，空标签不覆盖已有值 (线程安全)
+func (ds *DataServerInfo) SetTopology(topology string) {
+	if topology == "" {
+		return
+	}
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	ds.Topology = topology
+}
+
+// GetTopology 获取拓扑标签，未设置时返回 DefaultTopology (线程安全)
+func (ds *DataServerInfo) GetTopology() string {
+	ds.mutex.RLock()
+	defer ds.mutex.RUnlock()
+
+	if ds.Topology == "" {
+		return DefaultTopology
+	}
+	return ds.Topology
+}
+
+// UpdateScrubStats 更新后台巡检统计 (线程安全)
+func (ds *DataServerInfo) UpdateScrubStats(blocksScanned, corruptBlocks uint64, lastPassTime time.Time) {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	ds.ScrubBlocksScanned = blocksScanned
+	ds.ScrubCorruptBlocks = corruptBlocks
+	ds.ScrubLastPassTime = lastPassTime
+}
+
 // UpdateReportedBlocks 更新报告的块列表，返回新增的块ID列表
 func (ds *DataServerInfo) UpdateReportedBlocks(blockIDs []uint64) []uint64 {
 	ds.mutex.Lock()
@@ -127,6 +240,26 @@ func (ds *DataServerInfo) HasBlock(blockID uint64) bool {
 	return ds.ReportedBlocks[blockID]
 }
 
+// UpdateCorruptBlocks 替换上报的损坏块列表 (线程安全)
+func (ds *DataServerInfo) UpdateCorruptBlocks(blockIDs []uint64) {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	corrupt := make(map[uint64]bool, len(blockIDs))
+	for _, blockID := range blockIDs {
+		corrupt[blockID] = true
+	}
+	ds.CorruptBlocks = corrupt
+}
+
+// HasCorruptBlock 检查该节点是否上报指定的块校验失败
+func (ds *DataServerInfo) HasCorruptBlock(blockID uint64) bool {
+	ds.mutex.RLock()
+	defer ds.mutex.RUnlock()
+
+	return ds.CorruptBlocks[blockID]
+}
+
 // GetStatus 获取 DataServer 状态 (线程安全读取)
 func (ds *DataServerInfo) GetStatus() (blockCount, freeSpace, totalCapacity uint64, lastHeartbeat time.Time, isHealthy bool) {
 	ds.mutex.RLock()
@@ -219,9 +352,13 @@ type GCEntry struct {
 
 // Command 表示需要下发给 DataServer 的指令
 type Command struct {
-	Action  string   // 动作类型: "DELETE_BLOCK" 或 "COPY_BLOCK"
+	Action  string   // 动作类型: "DELETE_BLOCK"、"COPY_BLOCK"、"RECONSTRUCT_STRIPE" 或 "ENCODE_BLOCK"
 	BlockID uint64   // 目标块 ID
 	Targets []string // 目标地址列表
+
+	Group       *pb.BlockLocations // RECONSTRUCT_STRIPE 和 ENCODE_BLOCK 使用的纠删码块组
+	StripeIndex int                // RECONSTRUCT_STRIPE 需要重建的条带下标
+	Bandwidth   uint64             // COPY_BLOCK 的限速（字节/秒），0 表示不限速
 }
 
 // RepairTask 修复任务结构体
@@ -240,17 +377,159 @@ type FSCKCheckTask struct {
 	BlockID           uint64   // 块ID
 	ExpectedLocations []string // 期望位置
 	ActualLocations   []string // 实际位置
+	Replication       int      // 文件的目标副本数，0 表示块只被快照引用或为纠删码条带，不调整副本数
+
+	Group       *pb.BlockLocations // 块为纠删码条带时所属的块组，缺失时由其他条带重建
+	StripeIndex int                // 条带在块组中的下标
+}
+
+// OrphanBlock DataServer 上报但元数据中没有引用的块
+type OrphanBlock struct {
+	BlockID   uint64
+	Locations []string
+	Passes    int           // 连续出现的 FSCK 次数
+	Age       time.Duration // 按块ID中的时间戳计算，无法解析时为 -1
+	Eligible  bool          // 已达到删除条件
+}
+
+// OrphanStats FSCK 孤儿块处理统计
+type OrphanStats struct {
+	DryRun        bool
+	LastPassTime  time.Time
+	LastPassFound int           // 最近一次 FSCK 发现的孤儿块数
+	Pending       int           // 仍在宽限期内的孤儿块数
+	Deleted       uint64        // 累计调度删除的孤儿块数
+	Reported      uint64        // dry-run 下累计达到删除条件但只报告的次数
+	Orphans       []OrphanBlock // 最近一次 FSCK 发现的孤儿块
+}
+
+// ServerUtilization 均衡器计算的 DataServer 使用率
+type ServerUtilization struct {
+	Addr        string
+	Used        uint64  // 已用空间，包括正在迁入和迁出的块
+	Capacity    uint64  // 总容量
+	Utilization float64 // Used / Capacity
+	Class       string  // over (高于平均值+阈值), above, below, under (低于平均值-阈值)
+}
+
+// BalancerStatus 均衡器状态
+type BalancerStatus struct {
+	Running            bool
+	Threshold          float64
+	Bandwidth          uint64
+	StartTime          time.Time
+	LastIterationTime  time.Time
+	AverageUtilization float64
+	Servers            []ServerUtilization
+	PendingMoves       int
+	MovedBlocks        uint64 // 累计完成的块迁移数
+	MovedBytes         uint64 // 累计迁移的字节数（按块大小估算）
+	FailedMoves        uint64 // 累计超时或提交失败的块迁移数
+	Message            string
+}
+
+// DirectoryUsage 目录子树的使用量，随创建、写入、删除和重命名增量维护
+type DirectoryUsage struct {
+	Bytes      int64 `json:"bytes"`       // 文件逻辑大小之和
+	Space      int64 `json:"space"`       // 按副本数计算的占用空间
+	InodeCount int64 `json:"inode_count"` // 子树中的节点数（包括目录本身）
+	ChildCount int64 `json:"child_count"` // 直接子节点数，只在父目录条目增删时调整，不向祖先累加
+}
+
+// DirectoryQuota 目录配额，0 表示不限制
+type DirectoryQuota struct {
+	MaxBytes  uint64 `json:"max_bytes"`  // 空间配额，按副本数计算
+	MaxInodes uint64 `json:"max_inodes"` // 节点数配额
+}
+
+// DataServer 管理状态，没有记录的节点为 in_service
+const (
+	DataServerInService       = "in_service"
+	DataServerDecommissioning = "decommissioning" // 不再分配新块，块被迁移到其他节点
+	DataServerDecommissioned  = "decommissioned"  // 已没有块映射引用该节点，可以下线
+	DataServerMaintenance     = "maintenance"     // 不再分配新块，到期前宕机不触发重新复制
+)
+
+// DataServerAdminState DataServer 的管理状态，通过日志提交保存在 ds/<addr>
+type DataServerAdminState struct {
+	State             string `json:"state"`
+	StateTime         int64  `json:"state_time"`              // Unix 毫秒
+	MaintenanceExpire int64  `json:"maintenance_expire_time"` // Unix 毫秒，仅维护模式使用
 }
 
 // BadgerDB Key Prefixes
 const (
-	PrefixInode   = "i/"  // 存储 NodeInfo
-	PrefixPath    = "p/"  // 路径到 Inode ID 的映射
-	PrefixDir     = "d/"  // 目录条目
-	PrefixBlock   = "b/"  // 块映射
-	PrefixGC      = "gc/" // 垃圾回收
-	PrefixCounter = "c/"  // 计数器 (如 Inode ID 生成器)
+	PrefixInode           = "i/"  // 存储 NodeInfo
+	PrefixPath            = "p/"  // 路径到 Inode ID 的映射
+	PrefixDir             = "d/"  // 目录条目
+	PrefixBlock           = "b/"  // 块映射
+	PrefixGC              = "gc/" // 垃圾回收
+	PrefixCounter         = "c/"  // 计数器 (如 Inode ID 生成器)
+	PrefixUsage           = "u/"  // 目录使用量
+	PrefixQuota           = "q/"  // 目录配额
+	PrefixECPolicy        = "ec/" // 目录纠删码策略
+	PrefixDataServerState = "ds/" // DataServer 管理状态: ds/<addr>
+
+	PrefixLease = "lease/" // 文件写租约: lease/<path>
+
+	PrefixSnapshot      = "snap/"    // 目录快照: snap/<name>/info, snap/<name>/n<相对路径>, snap/<name>/b/<inode>/<index>
+	PrefixSnapshotBlock = "snapblk/" // 快照引用的块: snapblk/<blockID>/<name> -> 快照中的块映射键
 )
 
 // InodeCounter 用于生成唯一的 Inode ID
 const InodeCounterKey = PrefixCounter + "inode"
+
+// 回收站
+const (
+	TrashRoot             = "/.Trash"             // 回收站根目录
+	TrashCheckpointLayout = "20060102-150405.000" // 回收站下每次删除的时间目录名格式 (UTC)
+)
+
+// SnapshotRoot 目录快照的只读访问路径 /.snapshot/<name>/
+const SnapshotRoot = "/.snapshot"
+
+// SnapshotInfo 目录快照的描述
+type SnapshotInfo struct {
+	Name       string `json:"name"`
+	Path       string `json:"path"`
+	CreatedAt  int64  `json:"created_at"` // Unix 毫秒
+	Bytes      int64  `json:"bytes"`
+	InodeCount int64  `json:"inode_count"`
+}
+
+// Raft 持久化状态
+const (
+	RaftTermKey     = "raft:term"     // 当前任期
+	RaftVoteKey     = "raft:vote"     // 当前任期投票给的节点
+	RaftAppliedKey  = "raft:applied"  // 已应用到元数据的日志索引
+	RaftSnapshotKey = "raft:snapshot" // 最近快照包含的最后一条日志的索引和任期
+)
+
+// SnapshotPrefixes 快照包含的元数据键前缀
+var SnapshotPrefixes = []string{PrefixInode, PrefixPath, PrefixDir, PrefixBlock, PrefixCounter, PrefixUsage, PrefixQuota, PrefixECPolicy, PrefixDataServerState, PrefixLease, PrefixSnapshot, PrefixSnapshotBlock}
+
+// Caller 调用者身份，来自 gRPC metadata
+type Caller struct {
+	User   string
+	Groups []string
+}
+
+// Ownership 新建节点的属主、属组和权限位，Owner 为空时不记录属主
+type Ownership struct {
+	Owner string
+	Group string
+	Mode  uint32
+}
+
+// 权限位
+const (
+	PermRead    uint32 = 4
+	PermWrite   uint32 = 2
+	PermExecute uint32 = 1
+)
+
+// ACL 条目类型
+const (
+	AclUser  = "user"
+	AclGroup = "group"
+)
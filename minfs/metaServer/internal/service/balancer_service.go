@@ -0,0 +1,429 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"metaServer/internal/model"
+)
+
+// 均衡器使用率分类
+const (
+	utilizationOver  = "over"  // 高于平均值 + 阈值
+	utilizationAbove = "above" // 高于平均值，在阈值内
+	utilizationBelow = "below" // 低于平均值，在阈值内
+	utilizationUnder = "under" // 低于平均值 - 阈值
+)
+
+// blockMove 一个正在进行的块迁移，目标节点上报该块后替换块映射中的位置并删除源副本
+type blockMove struct {
+	blockID   uint64
+	source    string
+	target    string
+	size      uint64
+	startTime time.Time
+}
+
+// Balancer 均衡各 DataServer 的磁盘使用率：把块从使用率高的节点迁移到使用率低的节点
+// 只在 Leader 上运行，与 FSCK 并行时互不处理对方正在处理的块
+type Balancer struct {
+	config          *model.Config
+	clusterService  *ClusterService
+	metadataService *MetadataService
+	scheduler       *SchedulerService
+
+	moves    map[uint64]*blockMove // 块ID -> 正在进行的迁移
+	status   model.BalancerStatus
+	stopChan chan struct{}
+	mutex    sync.Mutex
+}
+
+// NewBalancer 创建均衡器，通过 Start 启动
+func NewBalancer(config *model.Config, clusterService *ClusterService, metadataService *MetadataService, scheduler *SchedulerService) *Balancer {
+	return &Balancer{
+		config:          config,
+		clusterService:  clusterService,
+		metadataService: metadataService,
+		scheduler:       scheduler,
+		moves:           make(map[uint64]*blockMove),
+		status:          model.BalancerStatus{Message: "not started"},
+	}
+}
+
+// SetBalancer 设置磁盘使用率均衡器，FSCK 跳过正在迁移的块
+func (ss *SchedulerService) SetBalancer(balancer *Balancer) {
+	ss.balancer = balancer
+}
+
+// Start 启动均衡，threshold 和 bandwidth 为 0 时使用配置值
+func (b *Balancer) Start(threshold float64, bandwidth uint64) error {
+	if threshold == 0 {
+		threshold = b.config.Balancer.Threshold
+	}
+	if threshold == 0 {
+		threshold = 0.1
+	}
+	if threshold <= 0 || threshold >= 1 {
+		return fmt.Errorf("balancer threshold %v out of range (0, 1)", threshold)
+	}
+	if bandwidth == 0 {
+		bandwidth = b.config.Balancer.Bandwidth
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.status.Running {
+		return fmt.Errorf("balancer is already running")
+	}
+	b.status = model.BalancerStatus{
+		Running:   true,
+		Threshold: threshold,
+		Bandwidth: bandwidth,
+		StartTime: time.Now(),
+		Message:   "running",
+	}
+	b.moves = make(map[uint64]*blockMove)
+	b.stopChan = make(chan struct{})
+	go b.loop(b.stopChan)
+
+	log.Printf("Balancer started (threshold: %.2f, bandwidth: %d B/s)", threshold, bandwidth)
+	return nil
+}
+
+// Stop 停止均衡，正在进行的迁移被放弃，已复制到目标节点的副本由 FSCK 作为多余副本删除
+func (b *Balancer) Stop() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.stopLocked("stopped")
+}
+
+// stopLocked 停止均衡并记录原因，调用方需持有锁
+func (b *Balancer) stopLocked(message string) {
+	if !b.status.Running {
+		return
+	}
+	close(b.stopChan)
+	b.status.Running = false
+	b.status.PendingMoves = 0
+	b.status.Message = message
+	b.moves = make(map[uint64]*blockMove)
+	log.Printf("Balancer stopped: %s", message)
+}
+
+// Status 获取均衡器状态
+func (b *Balancer) Status() model.BalancerStatus {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	status := b.status
+	status.Servers = append([]model.ServerUtilization(nil), b.status.Servers...)
+	return status
+}
+
+// isMoving 块是否正在被迁移
+func (b *Balancer) isMoving(blockID uint64) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	_, moving := b.moves[blockID]
+	return moving
+}
+
+// loop 均衡循环，启动后立即执行一次迭代
+func (b *Balancer) loop(stopChan chan struct{}) {
+	interval := b.config.Balancer.Interval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		b.iterate()
+		select {
+		case <-ticker.C:
+		case <-stopChan:
+			return
+		}
+	}
+}
+
+// iterate 一次均衡迭代：完成已复制的迁移，计算使用率，再为超出阈值的节点规划新的迁移
+func (b *Balancer) iterate() {
+	if !b.clusterService.IsLeader() || !b.clusterService.IsLeaderReady() {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if !b.status.Running {
+		return
+	}
+
+	b.finishMovesLocked()
+	b.status.PendingMoves = len(b.moves)
+
+	servers, average := b.utilizationLocked()
+	b.status.Servers = servers
+	b.status.AverageUtilization = average
+	b.status.LastIterationTime = time.Now()
+
+	pairs := pairServers(servers)
+	if len(pairs) == 0 {
+		if len(b.moves) == 0 {
+			b.stopLocked("cluster is balanced")
+		}
+		return
+	}
+
+	planned := b.planMovesLocked(pairs, average)
+	b.status.PendingMoves = len(b.moves)
+	if planned > 0 {
+		log.Printf("Balancer: scheduled %d block moves (average utilization %.3f, %d pending)", planned, average, len(b.moves))
+	}
+}
+
+// pairServers 规划迁移的源和目标节点对：过载节点优先迁往低载节点，其次迁往低于平均值的节点，
+// 低载节点也接收高于平均值节点的块；没有过载或低载节点时集群已均衡
+func pairServers(servers []model.ServerUtilization) [][2]model.ServerUtilization {
+	var over, above, below, under []model.ServerUtilization
+	for _, server := range servers {
+		switch server.Class {
+		case utilizationOver:
+			over = append(over, server)
+		case utilizationAbove:
+			above = append(above, server)
+		case utilizationBelow:
+			below = append(below, server)
+		case utilizationUnder:
+			under = append(under, server)
+		}
+	}
+	if len(over) == 0 && len(under) == 0 {
+		return nil
+	}
+
+	var pairs [][2]model.ServerUtilization
+	add := func(sources, targets []model.ServerUtilization) {
+		for _, source := range sources {
+			for _, target := range targets {
+				pairs = append(pairs, [2]model.ServerUtilization{source, target})
+			}
+		}
+	}
+	add(over, under)
+	add(over, below)
+	add(above, under)
+	return pairs
+}
+
+// utilizationLocked 计算在役健康节点的使用率并按使用率从高到低排序，正在进行的迁移计入目标节点并从源节点扣除
+func (b *Balancer) utilizationLocked() ([]model.ServerUtilization, float64) {
+	states := b.scheduler.dataServerStates()
+	now := time.Now()
+
+	pending := make(map[string]int64)
+	for _, move := range b.moves {
+		pending[move.source] -= int64(move.size)
+		pending[move.target] += int64(move.size)
+	}
+
+	var servers []model.ServerUtilization
+	var totalUsed, totalCapacity uint64
+	for _, ds := range b.clusterService.GetHealthyDataServers() {
+		_, freeSpace, capacity, _, _ := ds.GetStatus()
+		if capacity == 0 || ds.IsPermanentlyDownStatus() ||
+			effectiveDataServerState(states, ds.Addr, now) != model.DataServerInService {
+			continue
+		}
+		used := int64(capacity) - int64(freeSpace) + pending[ds.Addr]
+		if used < 0 {
+			used = 0
+		}
+		servers = append(servers, model.ServerUtilization{
+			Addr:        ds.Addr,
+			Used:        uint64(used),
+			Capacity:    capacity,
+			Utilization: float64(used) / float64(capacity),
+		})
+		totalUsed += uint64(used)
+		totalCapacity += capacity
+	}
+	if totalCapacity == 0 {
+		return servers, 0
+	}
+
+	average := float64(totalUsed) / float64(totalCapacity)
+	threshold := b.status.Threshold
+	for i := range servers {
+		switch u := servers[i].Utilization; {
+		case u > average+threshold:
+			servers[i].Class = utilizationOver
+		case u > average:
+			servers[i].Class = utilizationAbove
+		case u < average-threshold:
+			servers[i].Class = utilizationUnder
+		default:
+			servers[i].Class = utilizationBelow
+		}
+	}
+	sort.Slice(servers, func(i, j int) bool {
+		return servers[i].Utilization > servers[j].Utilization
+	})
+	return servers, average
+}
+
+// planMovesLocked 按节点对规划块迁移，源节点迁出到平均使用率、目标节点迁入到平均使用率为止，返回新规划的迁移数
+func (b *Balancer) planMovesLocked(pairs [][2]model.ServerUtilization, average float64) int {
+	maxMoves := b.config.Balancer.MaxConcurrentMoves
+	if maxMoves <= 0 {
+		maxMoves = 10
+	}
+	if len(b.moves) >= maxMoves || len(pairs) == 0 {
+		return 0
+	}
+
+	expectedBlocks, _, stripeGroups, err := b.scheduler.collectExpectedBlocks()
+	if err != nil {
+		log.Printf("Balancer: failed to collect blocks: %v", err)
+		return 0
+	}
+	actualBlocks := b.scheduler.getAllActualBlocks()
+
+	// 每个节点还能迁出或迁入的字节数
+	excess := make(map[string]float64)
+	room := make(map[string]float64)
+	for _, pair := range pairs {
+		for _, server := range pair {
+			delta := (server.Utilization - average) * float64(server.Capacity)
+			excess[server.Addr] = delta
+			room[server.Addr] = -delta
+		}
+	}
+
+	planned := 0
+	for _, pair := range pairs {
+		source, target := pair[0].Addr, pair[1].Addr
+		for blockID, locations := range expectedBlocks {
+			if len(b.moves) >= maxMoves {
+				return planned
+			}
+			if excess[source] <= 0 || room[target] <= 0 {
+				break
+			}
+
+			exclude := locations
+			size := b.metadataService.BlockSize()
+			if ref, ok := stripeGroups[blockID]; ok {
+				exclude = ref.group.Locations
+				if k, _, err := ParseECPolicy(ref.group.EcPolicy); err == nil {
+					size = (size + uint64(k) - 1) / uint64(k)
+				}
+			}
+			if !b.canMoveLocked(blockID, source, target, locations, exclude, actualBlocks[blockID]) {
+				continue
+			}
+
+			targetDS := b.clusterService.GetDataServerByAddr(target)
+			if targetDS == nil {
+				break
+			}
+			b.moves[blockID] = &blockMove{
+				blockID:   blockID,
+				source:    source,
+				target:    target,
+				size:      size,
+				startTime: time.Now(),
+			}
+			b.clusterService.SendCommand(targetDS.ID, &model.Command{
+				Action:    "COPY_BLOCK",
+				BlockID:   blockID,
+				Targets:   []string{source},
+				Bandwidth: b.status.Bandwidth,
+			})
+			excess[source] -= float64(size)
+			room[target] -= float64(size)
+			planned++
+		}
+	}
+	return planned
+}
+
+// canMoveLocked 块是否可以从 source 迁移到 target：块的所有副本都已上报且没有正在进行的修复或迁移，
+// target 上没有该块（纠删码条带还要求没有同一块组的其他条带），迁移后副本分布的机架数不减少
+func (b *Balancer) canMoveLocked(blockID uint64, source, target string, locations, exclude, actualLocations []string) bool {
+	if !b.scheduler.isLocationInExpected(source, locations) || b.scheduler.isLocationInExpected(target, exclude) {
+		return false
+	}
+	if _, moving := b.moves[blockID]; moving || len(b.scheduler.getRepairingTargets(blockID)) > 0 {
+		return false
+	}
+	if len(b.scheduler.findMissingReplicas(locations, actualLocations)) > 0 {
+		return false
+	}
+
+	after := make([]string, 0, len(exclude))
+	for _, addr := range exclude {
+		if addr != source {
+			after = append(after, addr)
+		}
+	}
+	after = append(after, target)
+	return b.rackCount(after) >= b.rackCount(exclude)
+}
+
+// rackCount 地址列表分布的机架数
+func (b *Balancer) rackCount(addrs []string) int {
+	racks := make(map[string]bool)
+	for _, addr := range addrs {
+		topology := ""
+		if ds := b.clusterService.GetDataServerByAddr(addr); ds != nil {
+			topology = ds.GetTopology()
+		}
+		_, rack := parseTopology(topology)
+		racks[rack] = true
+	}
+	return len(racks)
+}
+
+// finishMovesLocked 目标节点已上报的迁移：替换块映射中的位置并删除源副本；超时的迁移被放弃
+func (b *Balancer) finishMovesLocked() {
+	timeout := b.config.Balancer.MoveTimeout
+	if timeout <= 0 {
+		timeout = 5 * time.Minute
+	}
+
+	for blockID, move := range b.moves {
+		targetDS := b.clusterService.GetDataServerByAddr(move.target)
+		if targetDS == nil || !targetDS.HasBlock(blockID) {
+			if time.Since(move.startTime) > timeout {
+				log.Printf("Balancer: move of block %d from %s to %s timed out", blockID, move.source, move.target)
+				b.status.FailedMoves++
+				delete(b.moves, blockID)
+			}
+			continue
+		}
+		delete(b.moves, blockID)
+
+		// 块在迁移期间被删除或覆盖时更新失败，目标节点上的副本由 FSCK 回收
+		if err := b.metadataService.UpdateBlockLocation(blockID, move.source, move.target); err != nil {
+			log.Printf("Balancer: failed to move block %d from %s to %s: %v", blockID, move.source, move.target, err)
+			b.status.FailedMoves++
+			continue
+		}
+		if sourceDS := b.clusterService.GetDataServerByAddr(move.source); sourceDS != nil {
+			b.clusterService.SendCommand(sourceDS.ID, &model.Command{
+				Action:  "DELETE_BLOCK",
+				BlockID: blockID,
+			})
+		}
+		b.status.MovedBlocks++
+		b.status.MovedBytes += move.size
+		log.Printf("Balancer: moved block %d from %s to %s", blockID, move.source, move.target)
+	}
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"testing"
+
+	"metaServer/internal/model"
+	"metaServer/pb"
+)
+
+func TestBalancerMovesBlocks(t *testing.T) {
+	_, servers := newTestCluster(t)
+	leader := waitForLeader(t, servers)
+
+	const gb = 1 << 30
+	cs := &ClusterService{
+		dataServers:     make(map[string]*model.DataServerInfo),
+		pendingCommands: make(map[string][]*model.Command),
+	}
+	used := map[string]uint64{"ds1": 90, "ds2": 50, "ds3": 50, "ds4": 10}
+	for id, usedGB := range used {
+		ds := &model.DataServerInfo{ID: id, Addr: id + ":8001", ReportedBlocks: make(map[uint64]bool)}
+		ds.UpdateStatus(0, (100-usedGB)*gb, 100*gb)
+		cs.dataServers[id] = ds
+	}
+	placement, _ := NewPlacementPolicy(PlacementDistinct)
+	ss := &SchedulerService{
+		config:          &model.Config{},
+		clusterService:  cs,
+		metadataService: leader.metadata,
+		placementPolicy: placement,
+		repairingBlocks: make(map[uint64][]model.RepairTask),
+	}
+	b := NewBalancer(&model.Config{}, cs, leader.metadata, ss)
+	b.status = model.BalancerStatus{Running: true, Threshold: 0.1, Bandwidth: 1024}
+
+	if err := leader.metadata.CreateNodeWithReplication("/f", pb.FileType_File, 2); err != nil {
+		t.Fatalf("create file: %v", err)
+	}
+	info, _ := leader.metadata.GetNodeInfo("/f")
+	blocks := []*pb.BlockLocations{
+		{BlockId: 1, Locations: []string{"ds1:8001", "ds2:8001"}},
+		{BlockId: 2, Locations: []string{"ds2:8001", "ds3:8001"}},
+	}
+	if err := leader.metadata.CommitFileState("/f", info.Inode, 100, "", blocks); err != nil {
+		t.Fatalf("finalize: %v", err)
+	}
+	cs.dataServers["ds1"].UpdateReportedBlocks([]uint64{1})
+	cs.dataServers["ds2"].UpdateReportedBlocks([]uint64{1, 2})
+	cs.dataServers["ds3"].UpdateReportedBlocks([]uint64{2})
+
+	utilization, average := b.utilizationLocked()
+	if average != 0.5 || len(utilization) != 4 {
+		t.Fatalf("utilization: %+v, average %v", utilization, average)
+	}
+	classes := make(map[string]string)
+	for _, server := range utilization {
+		classes[server.Addr] = server.Class
+	}
+	if classes["ds1:8001"] != utilizationOver || classes["ds4:8001"] != utilizationUnder || classes["ds2:8001"] != utilizationBelow {
+		t.Errorf("classes: %v", classes)
+	}
+
+	// 只有过载节点上的块迁往低载节点
+	pairs := pairServers(utilization)
+	if planned := b.planMovesLocked(pairs, average); planned != 1 || !b.isMoving(1) || b.isMoving(2) {
+		t.Fatalf("planned %d moves: %+v", planned, b.moves)
+	}
+	commands := cs.pendingCommands["ds4"]
+	if len(commands) != 1 || commands[0].Action != "COPY_BLOCK" || commands[0].Targets[0] != "ds1:8001" || commands[0].Bandwidth != 1024 {
+		t.Fatalf("commands for ds4: %+v", commands)
+	}
+
+	// 目标节点未上报前迁移保持进行中
+	b.finishMovesLocked()
+	if !b.isMoving(1) {
+		t.Fatalf("move finished before the copy was reported")
+	}
+
+	cs.dataServers["ds4"].UpdateReportedBlocks([]uint64{1})
+	b.finishMovesLocked()
+	if b.isMoving(1) || b.status.MovedBlocks != 1 {
+		t.Fatalf("move not finished: %+v", b.status)
+	}
+	mapping, err := leader.metadata.GetBlockMapping(info.Inode, 0)
+	if err != nil || len(mapping.Locations) != 2 || mapping.Locations[0] != "ds4:8001" && mapping.Locations[1] != "ds4:8001" {
+		t.Errorf("block 1 after move: %v, %v", mapping, err)
+	}
+	if commands := cs.pendingCommands["ds1"]; len(commands) != 1 || commands[0].Action != "DELETE_BLOCK" {
+		t.Errorf("commands for ds1: %+v", commands)
+	}
+}
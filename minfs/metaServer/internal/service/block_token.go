@@ -0,0 +1,59 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"metaServer/pb"
+)
+
+// 块访问令牌允许的操作，一个令牌可以包含多个
+const (
+	BlockTokenRead  = "r"
+	BlockTokenWrite = "w"
+)
+
+// BlockTokenManager 签发块访问令牌：<块ID>:<操作>:<过期时间(毫秒)>:<HMAC-SHA256>，
+// DataServer 使用相同的密钥校验
+type BlockTokenManager struct {
+	key      []byte
+	lifetime time.Duration
+}
+
+// NewBlockTokenManager 创建令牌签发器，key 为空时返回 nil，不签发令牌
+func NewBlockTokenManager(key string, lifetime time.Duration) *BlockTokenManager {
+	if key == "" {
+		return nil
+	}
+	if lifetime <= 0 {
+		lifetime = 10 * time.Minute
+	}
+	return &BlockTokenManager{key: []byte(key), lifetime: lifetime}
+}
+
+// Sign 为块签发允许 ops 操作的令牌
+func (m *BlockTokenManager) Sign(blockID uint64, ops string) string {
+	if m == nil {
+		return ""
+	}
+	payload := fmt.Sprintf("%d:%s:%d", blockID, ops, time.Now().Add(m.lifetime).UnixMilli())
+	mac := hmac.New(sha256.New, m.key)
+	mac.Write([]byte(payload))
+	return payload + ":" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Attach 为返回给客户端的块签发令牌，纠删码块组的令牌按块组ID签发
+// 只能用于不再持久化的块映射
+func (m *BlockTokenManager) Attach(blocks []*pb.BlockLocations, ops string) {
+	if m == nil {
+		return
+	}
+	for _, block := range blocks {
+		if block != nil {
+			block.Token = m.Sign(block.BlockId, ops)
+		}
+	}
+}
@@ -1,7 +1,6 @@
 package service
 
 import (
-	"fmt"
 	"log"
 	"strconv"
 	"strings"
@@ -15,6 +14,9 @@ import (
 // BlockReplicationCallback 块复制完成回调函数类型
 type BlockReplicationCallback func(blockID uint64, targetAddr string, success bool)
 
+// CorruptBlockCallback DataServer 上报损坏块时的回调函数类型
+type CorruptBlockCallback func(dataServerAddr string, blockIDs []uint64)
+
 type ClusterService struct {
 	config      *model.Config
 	dataServers map[string]*model.DataServerInfo // Key: DataServer ID
@@ -31,11 +33,14 @@ type ClusterService struct {
 	// etcd 服务发现
 	etcdService *EtcdService
 
-	// Leader Election 服务
-	leaderElection *LeaderElection
+	// Raft 节点，决定当前节点是否为leader
+	raftNode *RaftNode
 
 	// 块复制完成回调
 	replicationCallback BlockReplicationCallback
+
+	// 损坏块上报回调
+	corruptBlockCallback CorruptBlockCallback
 }
 
 func NewClusterService(config *model.Config) *ClusterService {
@@ -66,9 +71,19 @@ func NewClusterService(config *model.Config) *ClusterService {
 	return cs
 }
 
-// SetLeaderElection 设置Leader Election服务
-func (cs *ClusterService) SetLeaderElection(le *LeaderElection) {
-	cs.leaderElection = le
+// SetRaftNode 设置Raft节点
+func (cs *ClusterService) SetRaftNode(rn *RaftNode) {
+	cs.raftNode = rn
+}
+
+// RegisterMetaServer 将本节点注册到 etcd，供客户端发现 MetaServer；etcd 不可用时跳过
+func (cs *ClusterService) RegisterMetaServer(id, addr string) {
+	if cs.etcdService == nil {
+		return
+	}
+	if err := cs.etcdService.RegisterMetaServer(id, addr); err != nil {
+		log.Printf("Warning: %v", err)
+	}
 }
 
 // SetReplicationCallback 设置块复制完成回调
@@ -76,12 +91,37 @@ func (cs *ClusterService) SetReplicationCallback(callback BlockReplicationCallba
 	cs.replicationCallback = callback
 }
 
+// SetCorruptBlockCallback 设置损坏块上报回调
+func (cs *ClusterService) SetCorruptBlockCallback(callback CorruptBlockCallback) {
+	cs.corruptBlockCallback = callback
+}
+
 // IsLeader 检查当前节点是否为leader
 func (cs *ClusterService) IsLeader() bool {
-	if cs.leaderElection == nil {
+	if cs.raftNode == nil {
+		return false
+	}
+	return cs.raftNode.IsLeader()
+}
+
+// IsLeaderReady 检查当前节点是否为leader且元数据已追上当选前提交的日志
+func (cs *ClusterService) IsLeaderReady() bool {
+	if cs.raftNode == nil {
 		return false
 	}
-	return cs.leaderElection.IsLeader()
+	return cs.raftNode.IsLeaderReady()
+}
+
+// LeaderTerm 当前节点担任 leader 的任期，不是 leader 时返回 0
+func (cs *ClusterService) LeaderTerm() uint64 {
+	if cs.raftNode == nil {
+		return 0
+	}
+	state, term, _ := cs.raftNode.Status()
+	if state != RaftLeader {
+		return 0
+	}
+	return term
 }
 
 // startHealthCheck 启动健康检查goroutine
@@ -149,6 +189,7 @@ func (cs *ClusterService) ProcessHeartbeat(req *pb.HeartbeatRequest) (*pb.Heartb
 	// 更新状态和块报告
 	wasUnhealthy := !ds.IsHealthy
 	ds.UpdateStatus(req.BlockCount, req.FreeSpace, req.TotalCapacity)
+	ds.SetTopology(req.Topology)
 
 	// 如果节点从不健康状态恢复，重置永久宕机标志
 	if wasUnhealthy {
@@ -157,6 +198,14 @@ func (cs *ClusterService) ProcessHeartbeat(req *pb.HeartbeatRequest) (*pb.Heartb
 	}
 
 	newBlocks := ds.UpdateReportedBlocks(req.BlockIdsReport)
+	ds.UpdateCorruptBlocks(req.CorruptBlockIds)
+	if scrub := req.ScrubStats; scrub != nil {
+		var lastPassTime time.Time
+		if scrub.LastPassTime > 0 {
+			lastPassTime = time.UnixMilli(scrub.LastPassTime)
+		}
+		ds.UpdateScrubStats(scrub.BlocksScanned, scrub.CorruptBlocks, lastPassTime)
+	}
 	cs.mutex.Unlock()
 
 	// 如果有回调函数且有新增的块，触发回调
@@ -166,6 +215,12 @@ func (cs *ClusterService) ProcessHeartbeat(req *pb.HeartbeatRequest) (*pb.Heartb
 		}
 	}
 
+	// 损坏的块不在块报告中，交给调度器从健康副本重新复制
+	if cs.corruptBlockCallback != nil && len(req.CorruptBlockIds) > 0 {
+		log.Printf("DataServer %s reported %d corrupt blocks: %v", req.DataserverId, len(req.CorruptBlockIds), req.CorruptBlockIds)
+		cs.corruptBlockCallback(req.DataserverAddr, req.CorruptBlockIds)
+	}
+
 	// 获取待下发的命令
 	cs.commandMutex.Lock()
 	commands := cs.pendingCommands[req.DataserverId]
@@ -177,8 +232,11 @@ func (cs *ClusterService) ProcessHeartbeat(req *pb.HeartbeatRequest) (*pb.Heartb
 	var pbCommands []*pb.Command
 	for _, cmd := range commands {
 		pbCmd := &pb.Command{
-			BlockId: cmd.BlockID,
-			Targets: cmd.Targets,
+			BlockId:     cmd.BlockID,
+			Targets:     cmd.Targets,
+			Group:       cmd.Group,
+			StripeIndex: uint32(cmd.StripeIndex),
+			Bandwidth:   cmd.Bandwidth,
 		}
 
 		switch cmd.Action {
@@ -186,6 +244,10 @@ func (cs *ClusterService) ProcessHeartbeat(req *pb.HeartbeatRequest) (*pb.Heartb
 			pbCmd.Action = pb.Command_DELETE_BLOCK
 		case "COPY_BLOCK":
 			pbCmd.Action = pb.Command_COPY_BLOCK
+		case "RECONSTRUCT_STRIPE":
+			pbCmd.Action = pb.Command_RECONSTRUCT_STRIPE
+		case "ENCODE_BLOCK":
+			pbCmd.Action = pb.Command_ENCODE_BLOCK
 		}
 
 		pbCommands = append(pbCommands, pbCmd)
@@ -229,45 +291,6 @@ func (cs *ClusterService) GetAllDataServers() []*model.DataServerInfo {
 	return servers
 }
 
-// SelectDataServersForThreeReplica 为三副本存储选择服务器（全局负载均衡）
-func (cs *ClusterService) SelectDataServersForThreeReplica(totalPhysicalBlocks int) ([]string, error) {
-	healthyServers := cs.GetHealthyDataServers()
-
-	if len(healthyServers) < 3 {
-		return nil, fmt.Errorf("need at least 3 healthy DataServers for three-replica, have %d", len(healthyServers))
-	}
-
-	serverCount := len(healthyServers)
-
-	// 为了负载均衡，我们采用轮询分配策略
-	var assignments []string
-	serverIndex := 0
-
-	for i := 0; i < totalPhysicalBlocks; i++ {
-		// 选择当前服务器
-		selectedServer := healthyServers[serverIndex%serverCount]
-		assignments = append(assignments, selectedServer.Addr)
-
-		// 移动到下一个服务器
-		serverIndex++
-	}
-
-	log.Printf("Three-replica distribution: %d physical blocks distributed across %d servers",
-		totalPhysicalBlocks, serverCount)
-
-	// 统计每个服务器分配到的块数量
-	serverBlockCount := make(map[string]int)
-	for _, addr := range assignments {
-		serverBlockCount[addr]++
-	}
-
-	for addr, count := range serverBlockCount {
-		log.Printf("  Server %s: %d blocks", addr, count)
-	}
-
-	return assignments, nil
-}
-
 // SendCommand 向指定的 DataServer 发送命令
 func (cs *ClusterService) SendCommand(dataServerID string, command *model.Command) {
 	cs.commandMutex.Lock()
@@ -358,6 +381,7 @@ func (cs *ClusterService) GetClusterInfo() *pb.ClusterInfo {
 			FileTotal:   int32(blockCount),
 			Capacity:    totalCapacityMB,
 			UseCapacity: useCapacityMB,
+			Topology:    ds.GetTopology(),
 		}
 
 		dataServers = append(dataServers, dataServerMsg)
@@ -367,9 +391,9 @@ func (cs *ClusterService) GetClusterInfo() *pb.ClusterInfo {
 	var masterMetaServer *pb.MetaServerMsg
 	var slaveMetaServers []*pb.MetaServerMsg
 
-	if cs.leaderElection != nil {
-		masterMetaServer = cs.leaderElection.GetCurrentLeader()
-		slaveMetaServers = cs.leaderElection.GetFollowers()
+	if cs.raftNode != nil {
+		masterMetaServer = cs.raftNode.GetCurrentLeader()
+		slaveMetaServers = cs.raftNode.GetFollowers()
 	} else {
 		// 如果没有选举服务，返回默认值
 		masterMetaServer = &pb.MetaServerMsg{
@@ -414,6 +438,7 @@ func (cs *ClusterService) onDataServerChange(id string, registration *DataServer
 		if ds, exists := cs.dataServers[id]; exists {
 			// 更新地址信息并恢复健康状态
 			ds.Addr = registration.Addr
+			ds.SetTopology(registration.Topology)
 			ds.RecoverToHealthy() // 确保恢复到健康状态
 			log.Printf("DataServer address updated and recovered: %s -> %s", id, registration.Addr)
 		} else {
@@ -421,6 +446,7 @@ func (cs *ClusterService) onDataServerChange(id string, registration *DataServer
 			ds = &model.DataServerInfo{
 				ID:             registration.ID,
 				Addr:           registration.Addr,
+				Topology:       registration.Topology,
 				ReportedBlocks: make(map[uint64]bool),
 			}
 			ds.RecoverToHealthy() // 明确标记为健康
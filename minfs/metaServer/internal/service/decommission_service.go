@@ -0,0 +1,265 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"metaServer/internal/model"
+	"metaServer/pb"
+
+	"github.com/dgraph-io/badger/v3"
+)
+
+// defaultMaintenanceDuration 未配置 cluster.maintenance_duration 时维护模式的持续时间
+const defaultMaintenanceDuration = time.Hour
+
+// SetDataServerState 设置 DataServer 的管理状态（通过日志提交），in_service 时删除记录
+// maintenanceDuration 只用于维护模式，不大于 0 时使用 cluster.maintenance_duration
+func (ms *MetadataService) SetDataServerState(addr, state string, maintenanceDuration time.Duration) error {
+	if addr == "" {
+		return fmt.Errorf("data server address cannot be empty")
+	}
+	switch state {
+	case model.DataServerInService, model.DataServerDecommissioning, model.DataServerDecommissioned, model.DataServerMaintenance:
+	default:
+		return fmt.Errorf("invalid data server state %q", state)
+	}
+
+	now := time.Now()
+	op := &pb.SetDataServerStateOperation{
+		Address:   addr,
+		State:     state,
+		StateTime: now.UnixMilli(),
+	}
+	if state == model.DataServerMaintenance {
+		if maintenanceDuration <= 0 {
+			maintenanceDuration = ms.config.Cluster.MaintenanceDuration
+		}
+		if maintenanceDuration <= 0 {
+			maintenanceDuration = defaultMaintenanceDuration
+		}
+		op.MaintenanceExpireTime = now.Add(maintenanceDuration).UnixMilli()
+	}
+
+	_, err := ms.propose(pb.WALOperationType_SET_DATASERVER_STATE, op)
+	return err
+}
+
+// setDataServerStateInDB 设置 DataServer 的管理状态（仅数据库操作，不写WAL）
+// 只有退役中的节点可以变为已退役，退役中或已退役的节点不能进入维护模式
+func (ms *MetadataService) setDataServerStateInDB(op *pb.SetDataServerStateOperation) error {
+	key := []byte(model.PrefixDataServerState + op.Address)
+
+	return ms.applyUpdate(func(txn *badger.Txn) error {
+		current := model.DataServerInService
+		item, err := txn.Get(key)
+		if err == nil {
+			var existing model.DataServerAdminState
+			if err := item.Value(func(val []byte) error {
+				return json.Unmarshal(val, &existing)
+			}); err != nil {
+				return err
+			}
+			current = existing.State
+		} else if err != badger.ErrKeyNotFound {
+			return err
+		}
+
+		switch op.State {
+		case model.DataServerInService:
+			return txn.Delete(key)
+		case model.DataServerDecommissioned:
+			if current != model.DataServerDecommissioning {
+				return fmt.Errorf("data server %s is %s, not decommissioning", op.Address, current)
+			}
+		case model.DataServerDecommissioning:
+			if current == model.DataServerDecommissioning || current == model.DataServerDecommissioned {
+				return fmt.Errorf("data server %s is already %s", op.Address, current)
+			}
+		case model.DataServerMaintenance:
+			if current == model.DataServerDecommissioning || current == model.DataServerDecommissioned {
+				return fmt.Errorf("data server %s is %s, cannot enter maintenance", op.Address, current)
+			}
+		}
+
+		data, err := json.Marshal(model.DataServerAdminState{
+			State:             op.State,
+			StateTime:         op.StateTime,
+			MaintenanceExpire: op.MaintenanceExpireTime,
+		})
+		if err != nil {
+			return err
+		}
+		return txn.Set(key, data)
+	})
+}
+
+// GetDataServerStates 获取所有设置了管理状态的 DataServer，键为地址
+func (ms *MetadataService) GetDataServerStates() (map[string]model.DataServerAdminState, error) {
+	states := make(map[string]model.DataServerAdminState)
+
+	err := ms.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		prefix := []byte(model.PrefixDataServerState)
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			var state model.DataServerAdminState
+			if err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &state)
+			}); err != nil {
+				return err
+			}
+			addr := strings.TrimPrefix(string(it.Item().Key()), model.PrefixDataServerState)
+			states[addr] = state
+		}
+		return nil
+	})
+	return states, err
+}
+
+// effectiveDataServerState 节点当前生效的管理状态，维护模式到期后视为 in_service
+func effectiveDataServerState(states map[string]model.DataServerAdminState, addr string, now time.Time) string {
+	state, ok := states[addr]
+	if !ok {
+		return model.DataServerInService
+	}
+	if state.State == model.DataServerMaintenance && now.UnixMilli() >= state.MaintenanceExpire {
+		return model.DataServerInService
+	}
+	return state.State
+}
+
+// dataServerStates 获取管理状态，读取失败时按全部 in_service 处理
+func (ss *SchedulerService) dataServerStates() map[string]model.DataServerAdminState {
+	states, err := ss.metadataService.GetDataServerStates()
+	if err != nil {
+		log.Printf("Failed to load data server states: %v", err)
+		return nil
+	}
+	return states
+}
+
+// inMaintenance 节点是否处于未到期的维护模式
+func (ss *SchedulerService) inMaintenance(addr string) bool {
+	return effectiveDataServerState(ss.dataServerStates(), addr, time.Now()) == model.DataServerMaintenance
+}
+
+// GetDecommissionProgress 最近一次 FSCK 时每个退役中节点仍被引用的块数
+func (ss *SchedulerService) GetDecommissionProgress() map[string]int {
+	ss.decommissionMutex.Lock()
+	defer ss.decommissionMutex.Unlock()
+
+	progress := make(map[string]int, len(ss.decommissionRemaining))
+	for addr, remaining := range ss.decommissionRemaining {
+		progress[addr] = remaining
+	}
+	return progress
+}
+
+// processDataServerStates 在 FSCK 中处理管理状态：
+// 到期的维护模式恢复为 in_service；退役中节点上的块逐个复制到其他节点并替换块映射中的位置，
+// 没有块映射再引用该节点时标记为已退役
+func (ss *SchedulerService) processDataServerStates(expectedBlocks, actualBlocks map[uint64][]string, stripeGroups map[uint64]stripeRef) {
+	states := ss.dataServerStates()
+	now := time.Now()
+	remaining := make(map[string]int)
+
+	for addr, state := range states {
+		switch {
+		case state.State == model.DataServerMaintenance && effectiveDataServerState(states, addr, now) == model.DataServerInService:
+			log.Printf("Maintenance of data server %s expired, returning it to service", addr)
+			if err := ss.metadataService.SetDataServerState(addr, model.DataServerInService, 0); err != nil {
+				log.Printf("Failed to end maintenance of %s: %v", addr, err)
+			}
+
+		case state.State == model.DataServerDecommissioning:
+			count := ss.decommissionBlocks(addr, expectedBlocks, actualBlocks, stripeGroups)
+			remaining[addr] = count
+			if count > 0 {
+				log.Printf("Decommissioning %s: %d blocks still reference it", addr, count)
+				continue
+			}
+			if err := ss.metadataService.SetDataServerState(addr, model.DataServerDecommissioned, 0); err != nil {
+				log.Printf("Failed to mark %s decommissioned: %v", addr, err)
+				continue
+			}
+			log.Printf("Data server %s decommissioned", addr)
+		}
+	}
+
+	ss.decommissionMutex.Lock()
+	ss.decommissionRemaining = remaining
+	ss.decommissionMutex.Unlock()
+}
+
+// decommissionBlocks 为仍引用退役中节点的块调度替换复制，返回引用该节点的块数
+// 每次 FSCK 最多调度 scheduler.max_concurrent_repairs 个块，正在复制或被均衡器迁移的块不重复调度
+func (ss *SchedulerService) decommissionBlocks(addr string, expectedBlocks, actualBlocks map[uint64][]string, stripeGroups map[uint64]stripeRef) int {
+	limit := ss.config.Scheduler.MaxConcurrentRepairs
+	if limit <= 0 {
+		limit = 10
+	}
+
+	count := 0
+	scheduled := 0
+	for blockID, locations := range expectedBlocks {
+		if !ss.isLocationInExpected(addr, locations) {
+			continue
+		}
+		count++
+		if scheduled >= limit || len(ss.getRepairingTargets(blockID)) > 0 {
+			continue
+		}
+		if ss.balancer != nil && ss.balancer.isMoving(blockID) {
+			continue
+		}
+
+		// 条带的新位置避开同一块组的其他条带
+		exclude := locations
+		if ref, ok := stripeGroups[blockID]; ok {
+			exclude = ref.group.Locations
+		}
+		if ss.migrateBlock(blockID, addr, exclude, actualBlocks[blockID]) {
+			scheduled++
+		}
+	}
+	return count
+}
+
+// migrateBlock 将块从退役中节点复制到新节点，复制完成后块映射中的旧位置替换为新位置
+// 优先从其他在役节点读取，只有退役中节点持有该块（如纠删码条带）时从其本身读取
+func (ss *SchedulerService) migrateBlock(blockID uint64, addr string, exclude, actualLocations []string) bool {
+	states := ss.dataServerStates()
+	now := time.Now()
+
+	var sourceAddr string
+	for _, location := range actualLocations {
+		if location == addr || !ss.isServerUsable(location) {
+			continue
+		}
+		if effectiveDataServerState(states, location, now) == model.DataServerInService {
+			sourceAddr = location
+			break
+		}
+	}
+	if sourceAddr == "" && ss.isLocationInExpected(addr, actualLocations) && ss.isServerUsable(addr) {
+		sourceAddr = addr
+	}
+	if sourceAddr == "" {
+		log.Printf("Decommissioning %s: no readable replica of block %d", addr, blockID)
+		return false
+	}
+
+	targets, err := ss.chooseTargets(1, exclude)
+	if err != nil {
+		log.Printf("Decommissioning %s: cannot find target for block %d: %v", addr, blockID, err)
+		return false
+	}
+
+	ss.ScheduleBlockReplicationWithReplacement(blockID, sourceAddr, targets[0].Addr, addr)
+	return true
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"metaServer/internal/model"
+)
+
+func TestDataServerStateTransitions(t *testing.T) {
+	_, servers := newTestCluster(t)
+	leader := waitForLeader(t, servers)
+
+	if err := leader.metadata.SetDataServerState("ds1:8001", "retired", 0); err == nil {
+		t.Errorf("unknown state accepted")
+	}
+	if err := leader.metadata.SetDataServerState("ds1:8001", model.DataServerDecommissioned, 0); err == nil {
+		t.Errorf("in-service server marked decommissioned")
+	}
+	if err := leader.metadata.SetDataServerState("ds1:8001", model.DataServerDecommissioning, 0); err != nil {
+		t.Fatalf("decommission: %v", err)
+	}
+	if err := leader.metadata.SetDataServerState("ds1:8001", model.DataServerMaintenance, 0); err == nil {
+		t.Errorf("decommissioning server entered maintenance")
+	}
+	if err := leader.metadata.SetDataServerState("ds1:8001", model.DataServerDecommissioned, 0); err != nil {
+		t.Fatalf("mark decommissioned: %v", err)
+	}
+	if err := leader.metadata.SetDataServerState("ds2:8001", model.DataServerMaintenance, time.Minute); err != nil {
+		t.Fatalf("maintenance: %v", err)
+	}
+
+	states, err := leader.metadata.GetDataServerStates()
+	if err != nil || len(states) != 2 || states["ds1:8001"].State != model.DataServerDecommissioned {
+		t.Fatalf("states: %+v, %v", states, err)
+	}
+	now := time.Now()
+	if state := effectiveDataServerState(states, "ds2:8001", now); state != model.DataServerMaintenance {
+		t.Errorf("ds2 state = %s", state)
+	}
+	if state := effectiveDataServerState(states, "ds2:8001", now.Add(2*time.Minute)); state != model.DataServerInService {
+		t.Errorf("ds2 state after expiry = %s", state)
+	}
+
+	// 恢复服务删除记录
+	if err := leader.metadata.SetDataServerState("ds1:8001", model.DataServerInService, 0); err != nil {
+		t.Fatalf("recommission: %v", err)
+	}
+	if states, _ := leader.metadata.GetDataServerStates(); len(states) != 1 {
+		t.Errorf("states after recommission: %+v", states)
+	}
+}
+
+func TestDecommissionMigratesBlocks(t *testing.T) {
+	_, servers := newTestCluster(t)
+	leader := waitForLeader(t, servers)
+
+	cs := &ClusterService{
+		dataServers:     make(map[string]*model.DataServerInfo),
+		pendingCommands: make(map[string][]*model.Command),
+	}
+	for _, id := range []string{"ds1", "ds2", "ds3", "ds4"} {
+		ds := &model.DataServerInfo{ID: id, Addr: id + ":8001", ReportedBlocks: make(map[uint64]bool)}
+		ds.RecoverToHealthy()
+		cs.dataServers[id] = ds
+	}
+	placement, _ := NewPlacementPolicy(PlacementDistinct)
+	ss := &SchedulerService{
+		config:          &model.Config{},
+		clusterService:  cs,
+		metadataService: leader.metadata,
+		placementPolicy: placement,
+		repairingBlocks: make(map[uint64][]model.RepairTask),
+	}
+
+	if err := leader.metadata.SetDataServerState("ds3:8001", model.DataServerDecommissioning, 0); err != nil {
+		t.Fatalf("decommission: %v", err)
+	}
+	if err := leader.metadata.SetDataServerState("ds4:8001", model.DataServerMaintenance, 0); err != nil {
+		t.Fatalf("maintenance: %v", err)
+	}
+	if !ss.inMaintenance("ds4:8001") || ss.inMaintenance("ds3:8001") {
+		t.Errorf("maintenance lookup is wrong")
+	}
+
+	// 退役中和维护中的节点不接收新块
+	targets, err := ss.chooseTargets(2, nil)
+	if err != nil {
+		t.Fatalf("choose targets: %v", err)
+	}
+	for _, target := range targets {
+		if target.ID != "ds1" && target.ID != "ds2" {
+			t.Errorf("block placed on %s", target.ID)
+		}
+	}
+	if _, err := ss.chooseTargets(3, nil); err == nil {
+		t.Errorf("placement used a decommissioning or maintenance server")
+	}
+
+	// 块从在役副本复制到新节点，替换退役中节点的位置
+	expected := map[uint64][]string{7: {"ds1:8001", "ds3:8001"}, 8: {"ds1:8001", "ds2:8001"}}
+	actual := map[uint64][]string{7: {"ds1:8001", "ds3:8001"}, 8: {"ds1:8001", "ds2:8001"}}
+	ss.processDataServerStates(expected, actual, nil)
+	if progress := ss.GetDecommissionProgress(); progress["ds3:8001"] != 1 {
+		t.Errorf("progress: %v", progress)
+	}
+	task := ss.getRepairTask(7, "ds2:8001")
+	if task == nil || !task.IsReplacement || task.ReplacedAddr != "ds3:8001" || task.SourceAddr != "ds1:8001" {
+		t.Fatalf("migration task: %+v", task)
+	}
+
+	// 没有块再引用该节点时标记为已退役
+	expected[7] = []string{"ds1:8001", "ds2:8001"}
+	ss.processDataServerStates(expected, actual, nil)
+	states, _ := leader.metadata.GetDataServerStates()
+	if states["ds3:8001"].State != model.DataServerDecommissioned {
+		t.Errorf("ds3 state = %s", states["ds3:8001"].State)
+	}
+}
@@ -0,0 +1,598 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"metaServer/internal/model"
+	"metaServer/pb"
+
+	"github.com/dgraph-io/badger/v3"
+	"google.golang.org/protobuf/proto"
+)
+
+// IsSnapshotPath 路径是否位于只读的快照命名空间 /.snapshot 下
+func IsSnapshotPath(path string) bool {
+	path = filepath.Clean(path)
+	return path == model.SnapshotRoot || strings.HasPrefix(path, model.SnapshotRoot+"/")
+}
+
+// splitSnapshotPath 将 /.snapshot/<name>/<相对路径> 拆分为快照名和相对路径，快照根目录的相对路径为空
+func splitSnapshotPath(path string) (string, string) {
+	rest := strings.TrimPrefix(filepath.Clean(path), model.SnapshotRoot+"/")
+	if idx := strings.Index(rest, "/"); idx >= 0 {
+		return rest[:idx], rest[idx:]
+	}
+	return rest, ""
+}
+
+// validateSnapshotName 检查快照名是否可以作为路径中的一级
+func validateSnapshotName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.Contains(name, "/") {
+		return fmt.Errorf("invalid snapshot name: %q", name)
+	}
+	return nil
+}
+
+func snapshotInfoKey(name string) string {
+	return model.PrefixSnapshot + name + "/info"
+}
+
+func snapshotNodeKey(name, rel string) string {
+	return model.PrefixSnapshot + name + "/n" + rel
+}
+
+func snapshotBlockPrefix(name string, inodeID uint64) string {
+	return fmt.Sprintf("%s%s/b/%d/", model.PrefixSnapshot, name, inodeID)
+}
+
+func snapshotBlockRefKey(blockID uint64, name string) string {
+	return fmt.Sprintf("%s%d/%s", model.PrefixSnapshotBlock, blockID, name)
+}
+
+// CreateSnapshot 为目录子树创建只读快照（通过日志提交）
+func (ms *MetadataService) CreateSnapshot(path, name string) error {
+	if err := validateSnapshotName(name); err != nil {
+		return err
+	}
+	_, err := ms.propose(pb.WALOperationType_CREATE_SNAPSHOT, &pb.CreateSnapshotOperation{
+		Name:      name,
+		Path:      filepath.Clean(path),
+		CreatedAt: time.Now().UnixMilli(),
+	})
+	return err
+}
+
+// createSnapshotInDB 在一个事务内复制子树的节点和块映射到 snap/<name>/ 下（仅数据库操作，不写WAL）
+// 数据块本身不复制，覆盖写总是分配新块，被替换的旧块由快照继续引用
+func (ms *MetadataService) createSnapshotInDB(name, path string, createdAt int64) error {
+	if err := validateSnapshotName(name); err != nil {
+		return err
+	}
+	path = filepath.Clean(path)
+	if path == "." {
+		path = "/"
+	}
+
+	return ms.applyUpdate(func(txn *badger.Txn) error {
+		if _, err := txn.Get([]byte(snapshotInfoKey(name))); err == nil {
+			return fmt.Errorf("snapshot already exists: %s", name)
+		} else if err != badger.ErrKeyNotFound {
+			return err
+		}
+
+		inodeID, err := ms.getInodeIDByPathInTx(txn, path)
+		if err == badger.ErrKeyNotFound {
+			return fmt.Errorf("directory not found: %s", path)
+		}
+		if err != nil {
+			return err
+		}
+		root, err := ms.getNodeInfoInTx(txn, inodeID)
+		if err != nil {
+			return err
+		}
+		if root.Type != pb.FileType_Directory {
+			return fmt.Errorf("snapshot can only be taken of a directory: %s", path)
+		}
+
+		usage, err := ms.getUsageInTx(txn, inodeID)
+		if err != nil {
+			return err
+		}
+		if err := ms.copyToSnapshotInTx(txn, name, path, root); err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(model.SnapshotInfo{
+			Name:       name,
+			Path:       path,
+			CreatedAt:  createdAt,
+			Bytes:      usage.Bytes,
+			InodeCount: usage.InodeCount,
+		})
+		if err != nil {
+			return err
+		}
+		return txn.Set([]byte(snapshotInfoKey(name)), data)
+	})
+}
+
+// copyToSnapshotInTx 在事务中递归复制节点到快照，目录的大小取自其使用量
+func (ms *MetadataService) copyToSnapshotInTx(txn *badger.Txn, name, root string, nodeInfo *pb.NodeInfo) error {
+	rel := strings.TrimPrefix(nodeInfo.Path, root)
+	if root == "/" && nodeInfo.Path != "/" {
+		rel = nodeInfo.Path
+	}
+
+	if nodeInfo.Type == pb.FileType_Directory {
+		usage, err := ms.getUsageInTx(txn, nodeInfo.Inode)
+		if err != nil {
+			return err
+		}
+		nodeInfo.Size = usage.Bytes
+	}
+	data, err := proto.Marshal(nodeInfo)
+	if err != nil {
+		return err
+	}
+	if err := txn.Set([]byte(snapshotNodeKey(name, rel)), data); err != nil {
+		return err
+	}
+
+	if nodeInfo.Type == pb.FileType_Directory {
+		children, err := ms.listDirectoryInTx(txn, nodeInfo.Inode)
+		if err != nil {
+			return err
+		}
+		for _, child := range children {
+			// 快照不包含回收站
+			if IsInTrash(child.Path) {
+				continue
+			}
+			if err := ms.copyToSnapshotInTx(txn, name, root, child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// 复制块映射，并记录块被该快照引用
+	mappings, err := ms.listBlockMappingsInTx(txn, fmt.Sprintf("%s%d/", model.PrefixBlock, nodeInfo.Inode))
+	if err != nil {
+		return err
+	}
+	for index, blockLocs := range mappings {
+		data, err := proto.Marshal(blockLocs)
+		if err != nil {
+			return err
+		}
+		key := fmt.Sprintf("%s%d", snapshotBlockPrefix(name, nodeInfo.Inode), index)
+		if err := txn.Set([]byte(key), data); err != nil {
+			return err
+		}
+		for _, block := range PhysicalBlocks(blockLocs) {
+			if err := txn.Set([]byte(snapshotBlockRefKey(block.BlockID, name)), []byte(key)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// listBlockMappingsInTx 在事务中读取前缀下的块映射，键的最后一段为块索引
+func (ms *MetadataService) listBlockMappingsInTx(txn *badger.Txn, prefix string) (map[uint64]*pb.BlockLocations, error) {
+	mappings := make(map[uint64]*pb.BlockLocations)
+
+	it := txn.NewIterator(badger.DefaultIteratorOptions)
+	defer it.Close()
+
+	for it.Seek([]byte(prefix)); it.ValidForPrefix([]byte(prefix)); it.Next() {
+		item := it.Item()
+		index, err := strconv.ParseUint(string(item.Key()[len(prefix):]), 10, 64)
+		if err != nil {
+			continue
+		}
+		blockLocs := &pb.BlockLocations{}
+		if err := item.Value(func(val []byte) error {
+			return proto.Unmarshal(val, blockLocs)
+		}); err != nil {
+			return nil, err
+		}
+		mappings[index] = blockLocs
+	}
+	return mappings, nil
+}
+
+// DeleteSnapshot 删除快照（通过日志提交），返回不再被任何文件或快照引用的块
+func (ms *MetadataService) DeleteSnapshot(name string) ([]model.BlockWithLocations, error) {
+	result, err := ms.propose(pb.WALOperationType_DELETE_SNAPSHOT, &pb.DeleteSnapshotOperation{Name: name})
+	if err != nil {
+		return nil, err
+	}
+	blocks, _ := result.([]model.BlockWithLocations)
+	return blocks, nil
+}
+
+// deleteSnapshotInDB 删除快照的所有键（仅数据库操作，不写WAL）
+func (ms *MetadataService) deleteSnapshotInDB(name string) ([]model.BlockWithLocations, error) {
+	var released []model.BlockWithLocations
+
+	err := ms.applyUpdate(func(txn *badger.Txn) error {
+		// 事务重试时清空，避免重复累积
+		released = released[:0]
+
+		if _, err := txn.Get([]byte(snapshotInfoKey(name))); err == badger.ErrKeyNotFound {
+			return fmt.Errorf("snapshot not found: %s", name)
+		} else if err != nil {
+			return err
+		}
+
+		// 快照中的所有块
+		blocks := make(map[uint64][]string)
+		prefix := []byte(model.PrefixSnapshot + name + "/b/")
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			var blockLocs pb.BlockLocations
+			if err := it.Item().Value(func(val []byte) error {
+				return proto.Unmarshal(val, &blockLocs)
+			}); err != nil {
+				it.Close()
+				return err
+			}
+			for _, block := range PhysicalBlocks(&blockLocs) {
+				blocks[block.BlockID] = block.Locations
+			}
+		}
+		it.Close()
+
+		for blockID := range blocks {
+			if err := txn.Delete([]byte(snapshotBlockRefKey(blockID, name))); err != nil {
+				return err
+			}
+		}
+		if err := ms.deleteKeysWithPrefixInTx(txn, model.PrefixSnapshot+name+"/"); err != nil {
+			return err
+		}
+		if len(blocks) == 0 {
+			return nil
+		}
+
+		// 仍被文件或其他快照引用的块保留
+		live, err := ms.liveBlockIDsInTx(txn)
+		if err != nil {
+			return err
+		}
+		for blockID, locations := range blocks {
+			if live[blockID] {
+				continue
+			}
+			referenced, err := ms.isSnapshotBlockInTx(txn, blockID)
+			if err != nil {
+				return err
+			}
+			if !referenced {
+				released = append(released, model.BlockWithLocations{BlockID: blockID, Locations: locations})
+			}
+		}
+		return nil
+	})
+
+	return released, err
+}
+
+// liveBlockIDsInTx 在事务中收集所有文件引用的块ID，纠删码块组按条带展开
+func (ms *MetadataService) liveBlockIDsInTx(txn *badger.Txn) (map[uint64]bool, error) {
+	live := make(map[uint64]bool)
+
+	prefix := []byte(model.PrefixBlock)
+	it := txn.NewIterator(badger.DefaultIteratorOptions)
+	defer it.Close()
+
+	for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+		var blockLocs pb.BlockLocations
+		if err := it.Item().Value(func(val []byte) error {
+			return proto.Unmarshal(val, &blockLocs)
+		}); err != nil {
+			return nil, err
+		}
+		for _, block := range PhysicalBlocks(&blockLocs) {
+			live[block.BlockID] = true
+		}
+	}
+	return live, nil
+}
+
+// IsSnapshotBlock 块是否被任一快照引用，被引用的块不能被垃圾回收
+func (ms *MetadataService) IsSnapshotBlock(blockID uint64) (bool, error) {
+	var referenced bool
+	err := ms.db.View(func(txn *badger.Txn) error {
+		var err error
+		referenced, err = ms.isSnapshotBlockInTx(txn, blockID)
+		return err
+	})
+	return referenced, err
+}
+
+// isSnapshotBlockInTx 在事务中检查块是否被任一快照引用
+func (ms *MetadataService) isSnapshotBlockInTx(txn *badger.Txn, blockID uint64) (bool, error) {
+	prefix := []byte(fmt.Sprintf("%s%d/", model.PrefixSnapshotBlock, blockID))
+	opts := badger.DefaultIteratorOptions
+	opts.PrefetchValues = false
+	it := txn.NewIterator(opts)
+	defer it.Close()
+
+	it.Seek(prefix)
+	return it.ValidForPrefix(prefix), nil
+}
+
+// GetSnapshotBlocks 返回所有快照引用的块及其位置
+func (ms *MetadataService) GetSnapshotBlocks() (map[uint64][]string, error) {
+	blocks := make(map[uint64][]string)
+
+	err := ms.db.View(func(txn *badger.Txn) error {
+		prefix := []byte(model.PrefixSnapshotBlock)
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			mappingKey, err := it.Item().ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			item, err := txn.Get(mappingKey)
+			if err != nil {
+				continue
+			}
+			var blockLocs pb.BlockLocations
+			if err := item.Value(func(val []byte) error {
+				return proto.Unmarshal(val, &blockLocs)
+			}); err != nil {
+				return err
+			}
+			for _, block := range PhysicalBlocks(&blockLocs) {
+				blocks[block.BlockID] = block.Locations
+			}
+		}
+		return nil
+	})
+
+	return blocks, err
+}
+
+// updateSnapshotBlockLocationInTx 在事务中更新快照引用的块位置
+func (ms *MetadataService) updateSnapshotBlockLocationInTx(txn *badger.Txn, blockID uint64, oldAddr, newAddr string) error {
+	var mappingKeys [][]byte
+
+	prefix := []byte(fmt.Sprintf("%s%d/", model.PrefixSnapshotBlock, blockID))
+	it := txn.NewIterator(badger.DefaultIteratorOptions)
+	for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+		mappingKey, err := it.Item().ValueCopy(nil)
+		if err != nil {
+			it.Close()
+			return err
+		}
+		mappingKeys = append(mappingKeys, mappingKey)
+	}
+	it.Close()
+
+	for _, mappingKey := range mappingKeys {
+		item, err := txn.Get(mappingKey)
+		if err != nil {
+			continue
+		}
+		var blockLocs pb.BlockLocations
+		if err := item.Value(func(val []byte) error {
+			return proto.Unmarshal(val, &blockLocs)
+		}); err != nil {
+			return err
+		}
+		if !replaceBlockLocation(&blockLocs, blockID, oldAddr, newAddr) {
+			continue
+		}
+		data, err := proto.Marshal(&blockLocs)
+		if err != nil {
+			return err
+		}
+		if err := txn.Set(mappingKey, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListSnapshots 列出所有快照，按名称排序
+func (ms *MetadataService) ListSnapshots() ([]model.SnapshotInfo, error) {
+	var snapshots []model.SnapshotInfo
+
+	err := ms.db.View(func(txn *badger.Txn) error {
+		prefix := model.PrefixSnapshot
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Seek([]byte(prefix)); it.ValidForPrefix([]byte(prefix)); {
+			name := strings.SplitN(string(it.Item().Key()[len(prefix):]), "/", 2)[0]
+
+			item, err := txn.Get([]byte(snapshotInfoKey(name)))
+			if err == nil {
+				var info model.SnapshotInfo
+				if err := item.Value(func(val []byte) error {
+					return json.Unmarshal(val, &info)
+				}); err != nil {
+					return err
+				}
+				snapshots = append(snapshots, info)
+			} else if err != badger.ErrKeyNotFound {
+				return err
+			}
+
+			// '0' 紧跟在 '/' 之后，跳过该快照的其余键
+			it.Seek([]byte(prefix + name + "0"))
+		}
+		return nil
+	})
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Name < snapshots[j].Name
+	})
+	return snapshots, err
+}
+
+// GetSnapshotNodeInfo 获取快照中节点的信息，返回的路径位于 /.snapshot 下
+func (ms *MetadataService) GetSnapshotNodeInfo(path string) (*pb.NodeInfo, error) {
+	path = filepath.Clean(path)
+	if path == model.SnapshotRoot {
+		return &pb.NodeInfo{Path: model.SnapshotRoot, Type: pb.FileType_Directory}, nil
+	}
+
+	var nodeInfo *pb.NodeInfo
+	err := ms.db.View(func(txn *badger.Txn) error {
+		var err error
+		nodeInfo, err = ms.getSnapshotNodeInfoInTx(txn, path)
+		return err
+	})
+	return nodeInfo, err
+}
+
+// getSnapshotNodeInfoInTx 在事务中读取快照节点，不存在时返回 badger.ErrKeyNotFound
+func (ms *MetadataService) getSnapshotNodeInfoInTx(txn *badger.Txn, path string) (*pb.NodeInfo, error) {
+	name, rel := splitSnapshotPath(path)
+	item, err := txn.Get([]byte(snapshotNodeKey(name, rel)))
+	if err != nil {
+		return nil, err
+	}
+
+	nodeInfo := &pb.NodeInfo{}
+	if err := item.Value(func(val []byte) error {
+		return proto.Unmarshal(val, nodeInfo)
+	}); err != nil {
+		return nil, err
+	}
+	nodeInfo.Path = model.SnapshotRoot + "/" + name + rel
+	return nodeInfo, nil
+}
+
+// ListSnapshotDirectory 列出快照中的目录，/.snapshot 本身列出所有快照的根目录
+func (ms *MetadataService) ListSnapshotDirectory(path string) ([]*pb.NodeInfo, error) {
+	path = filepath.Clean(path)
+
+	if path == model.SnapshotRoot {
+		snapshots, err := ms.ListSnapshots()
+		if err != nil {
+			return nil, err
+		}
+		var nodes []*pb.NodeInfo
+		for _, snapshot := range snapshots {
+			nodeInfo, err := ms.GetSnapshotNodeInfo(model.SnapshotRoot + "/" + snapshot.Name)
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, nodeInfo)
+		}
+		return nodes, nil
+	}
+
+	var nodes []*pb.NodeInfo
+	err := ms.db.View(func(txn *badger.Txn) error {
+		dirInfo, err := ms.getSnapshotNodeInfoInTx(txn, path)
+		if err != nil {
+			return err
+		}
+		if dirInfo.Type != pb.FileType_Directory {
+			return fmt.Errorf("not a directory: %s", path)
+		}
+
+		name, rel := splitSnapshotPath(path)
+		prefix := snapshotNodeKey(name, rel) + "/"
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		for it.Seek([]byte(prefix)); it.ValidForPrefix([]byte(prefix)); it.Next() {
+			// 只返回直接子节点
+			childName := string(it.Item().Key()[len(prefix):])
+			if strings.Contains(childName, "/") {
+				continue
+			}
+			nodeInfo := &pb.NodeInfo{}
+			if err := it.Item().Value(func(val []byte) error {
+				return proto.Unmarshal(val, nodeInfo)
+			}); err != nil {
+				return err
+			}
+			nodeInfo.Path = path + "/" + childName
+			nodes = append(nodes, nodeInfo)
+		}
+		return nil
+	})
+	return nodes, err
+}
+
+// ListSnapshotDirectoryPage 分页列出快照中的目录，语义与 ListDirectoryPage 相同
+// 快照中的节点键不按子节点名称排序，先取出全部子节点再排序分页
+func (ms *MetadataService) ListSnapshotDirectoryPage(path, startAfter string, limit int) ([]*pb.NodeInfo, string, error) {
+	nodes, err := ms.ListSnapshotDirectory(path)
+	if err != nil {
+		return nil, "", err
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return filepath.Base(nodes[i].Path) < filepath.Base(nodes[j].Path)
+	})
+
+	start := sort.Search(len(nodes), func(i int) bool {
+		return filepath.Base(nodes[i].Path) > startAfter
+	})
+	nodes = nodes[start:]
+	if limit > 0 && len(nodes) > limit {
+		return nodes[:limit], filepath.Base(nodes[limit-1].Path), nil
+	}
+	return nodes, "", nil
+}
+
+// GetSnapshotBlockMappings 获取快照中文件的块映射，按块索引排序
+func (ms *MetadataService) GetSnapshotBlockMappings(path string) (*pb.NodeInfo, []*pb.BlockLocations, error) {
+	var nodeInfo *pb.NodeInfo
+	var blockMappings []*pb.BlockLocations
+
+	err := ms.db.View(func(txn *badger.Txn) error {
+		var err error
+		nodeInfo, err = ms.getSnapshotNodeInfoInTx(txn, path)
+		if err == badger.ErrKeyNotFound {
+			return fmt.Errorf("file not found: %s", path)
+		}
+		if err != nil {
+			return err
+		}
+		if nodeInfo.Type == pb.FileType_Directory {
+			return fmt.Errorf("cannot get block locations for directory: %s", path)
+		}
+
+		name, _ := splitSnapshotPath(path)
+		mappings, err := ms.listBlockMappingsInTx(txn, snapshotBlockPrefix(name, nodeInfo.Inode))
+		if err != nil {
+			return err
+		}
+		var indices []uint64
+		for index := range mappings {
+			indices = append(indices, index)
+		}
+		sort.Slice(indices, func(i, j int) bool {
+			return indices[i] < indices[j]
+		})
+		for _, index := range indices {
+			blockMappings = append(blockMappings, mappings[index])
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, nil, err
+	}
+	return nodeInfo, blockMappings, nil
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"metaServer/pb"
+)
+
+func TestDirectorySnapshotKeepsDeletedBlocks(t *testing.T) {
+	_, servers := newTestCluster(t)
+	leader := waitForLeader(t, servers)
+
+	for _, dir := range []string{"/data", "/data/sub"} {
+		if err := leader.metadata.CreateNode(dir, pb.FileType_Directory); err != nil {
+			t.Fatalf("create %s: %v", dir, err)
+		}
+	}
+	writeTestFile(t, leader, "/data/sub/f", 10)
+	info, err := leader.metadata.GetNodeInfo("/data/sub/f")
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	block := &pb.BlockLocations{BlockId: 42, Locations: []string{"ds1:8001", "ds2:8001"}}
+	if err := leader.metadata.SetBlockMapping(info.Inode, 0, block); err != nil {
+		t.Fatalf("set block mapping: %v", err)
+	}
+
+	if err := leader.metadata.CreateSnapshot("/data", "s1"); err != nil {
+		t.Fatalf("create snapshot: %v", err)
+	}
+	if err := leader.metadata.CreateSnapshot("/data", "s1"); err == nil {
+		t.Fatalf("duplicate snapshot name accepted")
+	}
+	if err := leader.metadata.CreateNode("/.snapshot/s1/x", pb.FileType_File); err == nil {
+		t.Fatalf("write into snapshot path succeeded")
+	}
+
+	// 删除原文件后快照仍可读，块仍被引用
+	blocks, err := leader.metadata.DeleteNode("/data/sub", true)
+	if err != nil || len(blocks) != 1 {
+		t.Fatalf("delete: %v, %v", blocks, err)
+	}
+	if referenced, err := leader.metadata.IsSnapshotBlock(42); err != nil || !referenced {
+		t.Fatalf("block 42 not referenced by snapshot: %v", err)
+	}
+
+	root, err := leader.metadata.ListSnapshotDirectory("/.snapshot")
+	if err != nil || len(root) != 1 || root[0].Path != "/.snapshot/s1" || root[0].Size != 10 {
+		t.Fatalf("list /.snapshot: %v, %v", root, err)
+	}
+	children, err := leader.metadata.ListSnapshotDirectory("/.snapshot/s1/sub")
+	if err != nil || len(children) != 1 || children[0].Path != "/.snapshot/s1/sub/f" {
+		t.Fatalf("list snapshot dir: %v, %v", children, err)
+	}
+	_, ranges, err := leader.metadata.GetBlockRange("/.snapshot/s1/sub/f", 2, 0)
+	if err != nil || len(ranges) != 1 || ranges[0].Block.BlockId != 42 || ranges[0].Length != 8 {
+		t.Fatalf("read snapshot file: %v, %v", ranges, err)
+	}
+
+	// 快照复制到所有节点
+	for _, server := range servers {
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			snapshots, err := server.metadata.ListSnapshots()
+			if err == nil && len(snapshots) == 1 && snapshots[0].Path == "/data" && snapshots[0].InodeCount == 3 {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("%s: snapshots did not converge: %+v, %v", server.id, snapshots, err)
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+
+	released, err := leader.metadata.DeleteSnapshot("s1")
+	if err != nil || len(released) != 1 || released[0].BlockID != 42 {
+		t.Fatalf("delete snapshot: %v, %v", released, err)
+	}
+	if referenced, _ := leader.metadata.IsSnapshotBlock(42); referenced {
+		t.Errorf("block 42 still referenced after snapshot deletion")
+	}
+	if snapshots, _ := leader.metadata.ListSnapshots(); len(snapshots) != 0 {
+		t.Errorf("snapshots after deletion: %+v", snapshots)
+	}
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"log"
+	"time"
+
+	"metaServer/internal/model"
+	"metaServer/pb"
+)
+
+// ecConversion 一个正在转换为纠删码的多副本块，ENCODE_BLOCK 已下发，等待所有条带出现在块报告中
+type ecConversion struct {
+	inode      uint64
+	path       string
+	fileSize   int64
+	blockIndex uint64
+	oldBlock   *pb.BlockLocations
+	group      *pb.BlockLocations
+	startTime  time.Time
+}
+
+// SetLeaseManager 设置写租约管理器，正在写入的文件不做纠删码转换
+func (ss *SchedulerService) SetLeaseManager(leaseManager *LeaseManager) {
+	ss.leaseManager = leaseManager
+}
+
+// ecConvertLoop 纠删码转换循环
+func (ss *SchedulerService) ecConvertLoop() {
+	for {
+		select {
+		case <-ss.ecConvertTicker.C:
+			ss.runECConversion()
+		case <-ss.stopChan:
+			return
+		}
+	}
+}
+
+// runECConversion 将纠删码目录下的多副本文件逐块重新编码：
+// 先由持有副本的 DataServer 编码并写出各条带，所有条带上报后通过日志提交块映射的替换，再回收旧副本
+func (ss *SchedulerService) runECConversion() {
+	if !ss.clusterService.IsLeader() || !ss.clusterService.IsLeaderReady() {
+		return
+	}
+
+	completed := ss.finishECConversions()
+	started := ss.startECConversions()
+	if completed > 0 || started > 0 {
+		log.Printf("EC conversion: %d blocks converted, %d conversions started", completed, started)
+	}
+}
+
+// finishECConversions 提交所有条带都已上报的转换，返回完成的块数
+// 超时或提交失败的转换被放弃，已写出的条带不被引用，由孤儿块处理回收
+func (ss *SchedulerService) finishECConversions() int {
+	timeout := ss.config.ErasureCoding.ConvertTimeout
+	if timeout <= 0 {
+		timeout = 10 * time.Minute
+	}
+
+	ss.conversionMutex.Lock()
+	pending := make([]*ecConversion, 0, len(ss.pendingConversions))
+	for _, conversion := range ss.pendingConversions {
+		pending = append(pending, conversion)
+	}
+	ss.conversionMutex.Unlock()
+
+	completed := 0
+	for _, conversion := range pending {
+		oldBlockID := conversion.oldBlock.BlockId
+		if !ss.stripesReported(conversion.group) {
+			if time.Since(conversion.startTime) > timeout {
+				log.Printf("EC conversion of block %d (%s) timed out", oldBlockID, conversion.path)
+				ss.removeConversion(oldBlockID)
+			}
+			continue
+		}
+		ss.removeConversion(oldBlockID)
+
+		if ss.leaseManager != nil && ss.leaseManager.IsLeased(conversion.path) {
+			log.Printf("EC conversion of block %d abandoned: %s is being written", oldBlockID, conversion.path)
+			continue
+		}
+		err := ss.metadataService.ConvertBlock(conversion.inode, conversion.blockIndex, oldBlockID, conversion.fileSize, conversion.group)
+		if err != nil {
+			log.Printf("EC conversion of block %d abandoned: %v", oldBlockID, err)
+			continue
+		}
+
+		if err := ss.metadataService.AddGCEntry(oldBlockID, conversion.oldBlock.Locations); err != nil {
+			log.Printf("Failed to add GC entry for converted block %d: %v", oldBlockID, err)
+		}
+		log.Printf("Converted block %d of %s to group %d (%s)", oldBlockID, conversion.path, conversion.group.BlockId, conversion.group.EcPolicy)
+		completed++
+	}
+	return completed
+}
+
+// startECConversions 为待转换文件的块分配块组并下发 ENCODE_BLOCK，返回新开始的转换数
+func (ss *SchedulerService) startECConversions() int {
+	maxPending := ss.config.ErasureCoding.MaxPendingConverts
+	if maxPending <= 0 {
+		maxPending = 16
+	}
+
+	ss.conversionMutex.Lock()
+	room := maxPending - len(ss.pendingConversions)
+	ss.conversionMutex.Unlock()
+	if room <= 0 {
+		return 0
+	}
+
+	candidates, err := ss.metadataService.ListECConversionCandidates(room)
+	if err != nil {
+		log.Printf("EC conversion: failed to list candidates: %v", err)
+		return 0
+	}
+
+	started := 0
+	for _, candidate := range candidates {
+		if ss.leaseManager != nil && ss.leaseManager.IsLeased(candidate.Path) {
+			continue
+		}
+		for index, block := range candidate.Blocks {
+			if started >= room {
+				return started
+			}
+			if ss.startECConversion(candidate, index, block) {
+				started++
+			}
+		}
+	}
+	return started
+}
+
+// startECConversion 开始转换一个块，块被快照引用或正在转换时跳过
+func (ss *SchedulerService) startECConversion(candidate ECConversionCandidate, index uint64, block *pb.BlockLocations) bool {
+	ss.conversionMutex.Lock()
+	_, converting := ss.pendingConversions[block.BlockId]
+	ss.conversionMutex.Unlock()
+	if converting {
+		return false
+	}
+
+	// 快照与文件共享同一组副本，转换后回收旧副本会破坏快照
+	referenced, err := ss.metadataService.IsSnapshotBlock(block.BlockId)
+	if err != nil || referenced {
+		return false
+	}
+
+	var source *model.DataServerInfo
+	for _, addr := range block.Locations {
+		if ds := ss.clusterService.GetDataServerByAddr(addr); ds != nil && ss.isServerUsable(addr) && ds.HasBlock(block.BlockId) {
+			source = ds
+			break
+		}
+	}
+	if source == nil {
+		log.Printf("EC conversion: no healthy replica of block %d (%s)", block.BlockId, candidate.Path)
+		return false
+	}
+
+	k, m, err := ParseECPolicy(candidate.Policy)
+	if err != nil {
+		return false
+	}
+	group, err := ss.allocateBlockGroup(candidate.Policy, k+m)
+	if err != nil {
+		log.Printf("EC conversion of block %d (%s): %v", block.BlockId, candidate.Path, err)
+		return false
+	}
+
+	ss.conversionMutex.Lock()
+	ss.pendingConversions[block.BlockId] = &ecConversion{
+		inode:      candidate.Inode,
+		path:       candidate.Path,
+		fileSize:   candidate.Size,
+		blockIndex: index,
+		oldBlock:   block,
+		group:      group,
+		startTime:  time.Now(),
+	}
+	ss.conversionMutex.Unlock()
+
+	ss.clusterService.SendCommand(source.ID, &model.Command{
+		Action:  "ENCODE_BLOCK",
+		BlockID: block.BlockId,
+		Group:   group,
+	})
+	log.Printf("EC conversion of block %d (%s) into group %d scheduled on %s", block.BlockId, candidate.Path, group.BlockId, source.Addr)
+	return true
+}
+
+// stripesReported 块组的每个条带是否都已出现在其所在节点的块报告中
+func (ss *SchedulerService) stripesReported(group *pb.BlockLocations) bool {
+	for i, stripeID := range group.StripeIds {
+		ds := ss.clusterService.GetDataServerByAddr(group.Locations[i])
+		if ds == nil || !ds.HasBlock(stripeID) {
+			return false
+		}
+	}
+	return true
+}
+
+// removeConversion 移除转换跟踪
+func (ss *SchedulerService) removeConversion(oldBlockID uint64) {
+	ss.conversionMutex.Lock()
+	delete(ss.pendingConversions, oldBlockID)
+	ss.conversionMutex.Unlock()
+}
+
+// convertingStripes 正在进行的转换已写出或将要写出的条带
+func (ss *SchedulerService) convertingStripes() map[uint64]bool {
+	ss.conversionMutex.Lock()
+	defer ss.conversionMutex.Unlock()
+
+	stripes := make(map[uint64]bool)
+	for _, conversion := range ss.pendingConversions {
+		for _, stripeID := range conversion.group.StripeIds {
+			stripes[stripeID] = true
+		}
+	}
+	return stripes
+}
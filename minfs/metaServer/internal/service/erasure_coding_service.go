@@ -0,0 +1,315 @@
+package service
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"metaServer/internal/model"
+	"metaServer/pb"
+
+	"github.com/dgraph-io/badger/v3"
+	"google.golang.org/protobuf/proto"
+)
+
+// ParseECPolicy 解析 RS-<k>-<m> 形式的纠删码策略，返回数据条带数和校验条带数
+func ParseECPolicy(policy string) (int, int, error) {
+	parts := strings.Split(policy, "-")
+	if len(parts) != 3 || parts[0] != "RS" {
+		return 0, 0, fmt.Errorf("invalid erasure coding policy %q, want RS-<data>-<parity>", policy)
+	}
+	k, err1 := strconv.Atoi(parts[1])
+	m, err2 := strconv.Atoi(parts[2])
+	if err1 != nil || err2 != nil || k < 1 || m < 1 || k+m > 255 {
+		return 0, 0, fmt.Errorf("invalid erasure coding policy %q", policy)
+	}
+	return k, m, nil
+}
+
+// ecSpace 纠删码文件占用的空间，每 k 字节数据额外保存 m 字节校验数据
+func ecSpace(size int64, policy string) (int64, bool) {
+	k, m, err := ParseECPolicy(policy)
+	if err != nil {
+		return 0, false
+	}
+	return (size*int64(k+m) + int64(k) - 1) / int64(k), true
+}
+
+// PhysicalBlocks 块映射在 DataServer 上对应的物理块：多副本块为其自身，纠删码块组为每个条带
+func PhysicalBlocks(blockLocs *pb.BlockLocations) []model.BlockWithLocations {
+	if blockLocs.EcPolicy == "" {
+		return []model.BlockWithLocations{{BlockID: blockLocs.BlockId, Locations: blockLocs.Locations}}
+	}
+	blocks := make([]model.BlockWithLocations, 0, len(blockLocs.StripeIds))
+	for i, stripeID := range blockLocs.StripeIds {
+		var locations []string
+		if i < len(blockLocs.Locations) && blockLocs.Locations[i] != "" {
+			locations = []string{blockLocs.Locations[i]}
+		}
+		blocks = append(blocks, model.BlockWithLocations{BlockID: stripeID, Locations: locations})
+	}
+	return blocks
+}
+
+// replaceBlockLocation 更新块映射中 blockID 的位置，blockID 可以是多副本块或纠删码条带
+// 条带的位置与下标一一对应，只支持替换，不支持增加或删除
+func replaceBlockLocation(blockLocs *pb.BlockLocations, blockID uint64, oldAddr, newAddr string) bool {
+	if blockLocs.EcPolicy == "" {
+		if blockLocs.BlockId != blockID {
+			return false
+		}
+		var updated bool
+		blockLocs.Locations, updated = replaceLocation(blockLocs.Locations, oldAddr, newAddr)
+		return updated
+	}
+
+	if oldAddr == "" || newAddr == "" {
+		return false
+	}
+	for i, stripeID := range blockLocs.StripeIds {
+		if stripeID == blockID && i < len(blockLocs.Locations) && blockLocs.Locations[i] == oldAddr {
+			blockLocs.Locations[i] = newAddr
+			return true
+		}
+	}
+	return false
+}
+
+// SetErasureCodingPolicy 设置目录的纠删码策略（通过日志提交），policy 为空时删除
+// 只影响之后新建的文件，已有的多副本文件由后台转换任务重新编码
+func (ms *MetadataService) SetErasureCodingPolicy(path, policy string) error {
+	if policy != "" {
+		if _, _, err := ParseECPolicy(policy); err != nil {
+			return err
+		}
+	}
+	path = filepath.Clean(path)
+	if IsSnapshotPath(path) {
+		return fmt.Errorf("snapshot path is read-only: %s", path)
+	}
+
+	_, err := ms.propose(pb.WALOperationType_SET_EC_POLICY, &pb.SetErasureCodingPolicyOperation{
+		Path:   path,
+		Policy: policy,
+	})
+	return err
+}
+
+// setECPolicyInDB 设置目录的纠删码策略（仅数据库操作，不写WAL）
+func (ms *MetadataService) setECPolicyInDB(path, policy string) error {
+	path = filepath.Clean(path)
+	if path == "." {
+		path = "/"
+	}
+
+	return ms.applyUpdate(func(txn *badger.Txn) error {
+		inodeID, err := ms.getInodeIDByPathInTx(txn, path)
+		if err == badger.ErrKeyNotFound {
+			return fmt.Errorf("directory not found: %s", path)
+		}
+		if err != nil {
+			return err
+		}
+		nodeInfo, err := ms.getNodeInfoInTx(txn, inodeID)
+		if err != nil {
+			return err
+		}
+		if nodeInfo.Type != pb.FileType_Directory {
+			return fmt.Errorf("erasure coding policy can only be set on a directory: %s", path)
+		}
+
+		key := fmt.Sprintf("%s%d", model.PrefixECPolicy, inodeID)
+		if policy == "" {
+			return txn.Delete([]byte(key))
+		}
+		return txn.Set([]byte(key), []byte(policy))
+	})
+}
+
+// GetErasureCodingPolicy 获取路径生效的纠删码策略及设置该策略的目录，没有策略时返回空字符串
+// 文件返回其自身的存储方式，尚未转换的多副本文件返回所在目录的策略
+func (ms *MetadataService) GetErasureCodingPolicy(path string) (string, string, error) {
+	path = filepath.Clean(path)
+	if path == "." {
+		path = "/"
+	}
+
+	var policy, dir string
+	err := ms.db.View(func(txn *badger.Txn) error {
+		inodeID, err := ms.getInodeIDByPathInTx(txn, path)
+		if err == badger.ErrKeyNotFound {
+			return fmt.Errorf("path not found: %s", path)
+		}
+		if err != nil {
+			return err
+		}
+		nodeInfo, err := ms.getNodeInfoInTx(txn, inodeID)
+		if err != nil {
+			return err
+		}
+
+		if nodeInfo.Type == pb.FileType_File {
+			if nodeInfo.EcPolicy != "" {
+				policy, dir = nodeInfo.EcPolicy, filepath.Dir(path)
+				return nil
+			}
+			path = filepath.Dir(path)
+		}
+		policy, dir, err = ms.effectiveECPolicyInTx(txn, path)
+		return err
+	})
+	return policy, dir, err
+}
+
+// effectiveECPolicyInTx 在事务中从目录 dir 向上查找最近的设置了纠删码策略的目录
+func (ms *MetadataService) effectiveECPolicyInTx(txn *badger.Txn, dir string) (string, string, error) {
+	for {
+		inodeID, err := ms.getInodeIDByPathInTx(txn, dir)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to resolve %s: %v", dir, err)
+		}
+		item, err := txn.Get([]byte(fmt.Sprintf("%s%d", model.PrefixECPolicy, inodeID)))
+		if err == nil {
+			value, err := item.ValueCopy(nil)
+			return string(value), dir, err
+		}
+		if err != badger.ErrKeyNotFound {
+			return "", "", err
+		}
+		if dir == "/" {
+			return "", "", nil
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// ConvertBlock 将文件的一个多副本块替换为已写好的纠删码块组（通过日志提交）
+// fileSize 为开始转换时的文件大小，追加写会原地重写尾块，文件大小变化后转换不生效
+func (ms *MetadataService) ConvertBlock(inodeID, blockIndex, oldBlockID uint64, fileSize int64, group *pb.BlockLocations) error {
+	_, err := ms.propose(pb.WALOperationType_CONVERT_BLOCK, &pb.ConvertBlockOperation{
+		InodeId:    inodeID,
+		BlockIndex: blockIndex,
+		OldBlockId: oldBlockID,
+		Group:      group,
+		FileSize:   fileSize,
+	})
+	return err
+}
+
+// convertBlockInDB 替换块映射（仅数据库操作，不写WAL），块映射已被覆盖写或删除时返回错误
+// 文件的所有块都已转换后，文件标记为纠删码存储并按新的占用空间更新祖先目录的使用量
+func (ms *MetadataService) convertBlockInDB(op *pb.ConvertBlockOperation) error {
+	if op.Group == nil || op.Group.EcPolicy == "" {
+		return fmt.Errorf("convert block: group is required")
+	}
+
+	return ms.applyUpdate(func(txn *badger.Txn) error {
+		nodeInfo, err := ms.getNodeInfoInTx(txn, op.InodeId)
+		if err != nil {
+			return fmt.Errorf("convert block: inode %d not found: %v", op.InodeId, err)
+		}
+		if nodeInfo.Type != pb.FileType_File || nodeInfo.EcPolicy != "" {
+			return fmt.Errorf("convert block: inode %d is not a replicated file", op.InodeId)
+		}
+		if nodeInfo.Size != op.FileSize {
+			return fmt.Errorf("convert block: inode %d was modified during conversion", op.InodeId)
+		}
+
+		current, err := ms.getBlockMappingInTx(txn, op.InodeId, op.BlockIndex)
+		if err != nil {
+			return fmt.Errorf("convert block: block %d of inode %d not found: %v", op.BlockIndex, op.InodeId, err)
+		}
+		if current.BlockId != op.OldBlockId || current.EcPolicy != "" {
+			return fmt.Errorf("convert block: block %d of inode %d changed", op.BlockIndex, op.InodeId)
+		}
+
+		data, err := proto.Marshal(op.Group)
+		if err != nil {
+			return err
+		}
+		if err := txn.Set([]byte(fmt.Sprintf("%s%d/%d", model.PrefixBlock, op.InodeId, op.BlockIndex)), data); err != nil {
+			return err
+		}
+
+		mappings, err := ms.listBlockMappingsInTx(txn, fmt.Sprintf("%s%d/", model.PrefixBlock, op.InodeId))
+		if err != nil {
+			return err
+		}
+		for index, mapping := range mappings {
+			if index != op.BlockIndex && mapping.EcPolicy == "" {
+				return nil
+			}
+		}
+
+		oldUsage := fileUsage(nodeInfo.Size, nodeInfo.Replication, "")
+		newUsage := fileUsage(nodeInfo.Size, nodeInfo.Replication, op.Group.EcPolicy)
+		if err := ms.addUsageInTx(txn, nodeInfo.Path, model.DirectoryUsage{Space: newUsage.Space - oldUsage.Space}); err != nil {
+			return err
+		}
+		nodeInfo.EcPolicy = op.Group.EcPolicy
+		data, err = proto.Marshal(nodeInfo)
+		if err != nil {
+			return err
+		}
+		return txn.Set([]byte(fmt.Sprintf("%s%d", model.PrefixInode, op.InodeId)), data)
+	})
+}
+
+// ECConversionCandidate 位于纠删码目录下、仍有多副本块的文件
+type ECConversionCandidate struct {
+	Inode  uint64
+	Path   string
+	Size   int64
+	Policy string                        // 目标纠删码策略
+	Blocks map[uint64]*pb.BlockLocations // 块索引 -> 尚未转换的多副本块
+}
+
+// ListECConversionCandidates 列出需要转换为纠删码存储的文件，最多返回 limit 个
+func (ms *MetadataService) ListECConversionCandidates(limit int) ([]ECConversionCandidate, error) {
+	var candidates []ECConversionCandidate
+
+	err := ms.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		inodePrefix := []byte(model.PrefixInode)
+		for it.Seek(inodePrefix); it.ValidForPrefix(inodePrefix) && len(candidates) < limit; it.Next() {
+			var nodeInfo pb.NodeInfo
+			if err := it.Item().Value(func(val []byte) error {
+				return proto.Unmarshal(val, &nodeInfo)
+			}); err != nil {
+				continue
+			}
+			if nodeInfo.Type != pb.FileType_File || nodeInfo.EcPolicy != "" || nodeInfo.Size == 0 {
+				continue
+			}
+
+			policy, _, err := ms.effectiveECPolicyInTx(txn, filepath.Dir(nodeInfo.Path))
+			if err != nil || policy == "" {
+				continue
+			}
+
+			mappings, err := ms.listBlockMappingsInTx(txn, fmt.Sprintf("%s%d/", model.PrefixBlock, nodeInfo.Inode))
+			if err != nil {
+				return err
+			}
+			blocks := make(map[uint64]*pb.BlockLocations)
+			for index, mapping := range mappings {
+				if mapping.EcPolicy == "" {
+					blocks[index] = mapping
+				}
+			}
+			candidates = append(candidates, ECConversionCandidate{
+				Inode:  nodeInfo.Inode,
+				Path:   nodeInfo.Path,
+				Size:   nodeInfo.Size,
+				Policy: policy,
+				Blocks: blocks,
+			})
+		}
+		return nil
+	})
+	return candidates, err
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"testing"
+
+	"metaServer/pb"
+)
+
+func TestErasureCodingPolicy(t *testing.T) {
+	_, servers := newTestCluster(t)
+	leader := waitForLeader(t, servers)
+
+	if err := leader.metadata.CreateNode("/cold", pb.FileType_Directory); err != nil {
+		t.Fatalf("create dir: %v", err)
+	}
+	if err := leader.metadata.CreateNodeWithReplication("/cold/old", pb.FileType_File, 3); err != nil {
+		t.Fatalf("create file: %v", err)
+	}
+	for _, policy := range []string{"RS-6", "XOR-2-1", "RS-0-2"} {
+		if err := leader.metadata.SetErasureCodingPolicy("/cold", policy); err == nil {
+			t.Errorf("policy %q accepted", policy)
+		}
+	}
+	if err := leader.metadata.SetErasureCodingPolicy("/cold/old", "RS-2-1"); err == nil {
+		t.Errorf("policy set on a file")
+	}
+	if err := leader.metadata.SetErasureCodingPolicy("/cold", "RS-2-1"); err != nil {
+		t.Fatalf("set policy: %v", err)
+	}
+
+	// 新文件继承目录的策略，已有文件在转换前仍为多副本
+	if err := leader.metadata.CreateNode("/cold/sub", pb.FileType_Directory); err != nil {
+		t.Fatalf("create subdir: %v", err)
+	}
+	if err := leader.metadata.CreateNode("/cold/sub/new", pb.FileType_File); err != nil {
+		t.Fatalf("create ec file: %v", err)
+	}
+	info, err := leader.metadata.GetNodeInfo("/cold/sub/new")
+	if err != nil || info.EcPolicy != "RS-2-1" {
+		t.Fatalf("new file: %v, %v", info, err)
+	}
+	if policy, dir, err := leader.metadata.GetErasureCodingPolicy("/cold/sub"); err != nil || policy != "RS-2-1" || dir != "/cold" {
+		t.Errorf("policy of /cold/sub = %q from %q, %v", policy, dir, err)
+	}
+	if old, _ := leader.metadata.GetNodeInfo("/cold/old"); old.EcPolicy != "" {
+		t.Errorf("existing file became %q", old.EcPolicy)
+	}
+	if err := leader.metadata.SetReplication("/cold/sub/new", 2); err == nil {
+		t.Errorf("set replication on erasure-coded file succeeded")
+	}
+
+	// 块组的每个条带都是独立的物理块，使用量按 (k+m)/k 计算
+	group := &pb.BlockLocations{
+		BlockId:   100,
+		EcPolicy:  "RS-2-1",
+		StripeIds: []uint64{101, 102, 103},
+		Locations: []string{"ds1:8001", "ds2:8001", "ds3:8001"},
+	}
+	if err := leader.metadata.CommitFileState("/cold/sub/new", info.Inode, 100, "", []*pb.BlockLocations{group}); err != nil {
+		t.Fatalf("finalize: %v", err)
+	}
+	usage, err := leader.metadata.GetQuota("/cold/sub")
+	if err != nil || usage.Bytes != 100 || usage.SpaceConsumed != 150 {
+		t.Errorf("usage: %+v, %v", usage, err)
+	}
+	if err := leader.metadata.UpdateBlockLocation(102, "ds2:8001", "ds4:8001"); err != nil {
+		t.Fatalf("move stripe: %v", err)
+	}
+	blocks, err := leader.metadata.DeleteNode("/cold/sub/new", false)
+	if err != nil || len(blocks) != 3 {
+		t.Fatalf("delete: %v, %v", blocks, err)
+	}
+	if blocks[1].BlockID != 102 || len(blocks[1].Locations) != 1 || blocks[1].Locations[0] != "ds4:8001" {
+		t.Errorf("stripe after move: %+v", blocks[1])
+	}
+
+	if err := leader.metadata.SetErasureCodingPolicy("/cold", ""); err != nil {
+		t.Fatalf("clear policy: %v", err)
+	}
+	if policy, _, err := leader.metadata.GetErasureCodingPolicy("/cold/sub"); err != nil || policy != "" {
+		t.Errorf("policy after clear = %q, %v", policy, err)
+	}
+}
+
+func TestConvertBlock(t *testing.T) {
+	_, servers := newTestCluster(t)
+	leader := waitForLeader(t, servers)
+
+	if err := leader.metadata.CreateNode("/cold", pb.FileType_Directory); err != nil {
+		t.Fatalf("create dir: %v", err)
+	}
+	if err := leader.metadata.CreateNodeWithReplication("/cold/f", pb.FileType_File, 3); err != nil {
+		t.Fatalf("create file: %v", err)
+	}
+	info, _ := leader.metadata.GetNodeInfo("/cold/f")
+	replicas := []string{"ds1:8001", "ds2:8001", "ds3:8001"}
+	blocks := []*pb.BlockLocations{
+		{BlockId: 1, Locations: replicas},
+		{BlockId: 2, Locations: replicas},
+	}
+	if err := leader.metadata.CommitFileState("/cold/f", info.Inode, 200, "", blocks); err != nil {
+		t.Fatalf("finalize: %v", err)
+	}
+	if err := leader.metadata.SetErasureCodingPolicy("/cold", "RS-2-1"); err != nil {
+		t.Fatalf("set policy: %v", err)
+	}
+
+	candidates, err := leader.metadata.ListECConversionCandidates(10)
+	if err != nil || len(candidates) != 1 || len(candidates[0].Blocks) != 2 || candidates[0].Policy != "RS-2-1" {
+		t.Fatalf("candidates: %+v, %v", candidates, err)
+	}
+
+	groupFor := func(id uint64) *pb.BlockLocations {
+		return &pb.BlockLocations{
+			BlockId:   id,
+			EcPolicy:  "RS-2-1",
+			StripeIds: []uint64{id + 1, id + 2, id + 3},
+			Locations: replicas,
+		}
+	}
+	// 文件大小或块已变化时转换不生效
+	if err := leader.metadata.ConvertBlock(info.Inode, 0, 1, 100, groupFor(10)); err == nil {
+		t.Errorf("conversion with stale size succeeded")
+	}
+	if err := leader.metadata.ConvertBlock(info.Inode, 0, 9, 200, groupFor(10)); err == nil {
+		t.Errorf("conversion of replaced block succeeded")
+	}
+
+	if err := leader.metadata.ConvertBlock(info.Inode, 0, 1, 200, groupFor(10)); err != nil {
+		t.Fatalf("convert block 0: %v", err)
+	}
+	if info, _ := leader.metadata.GetNodeInfo("/cold/f"); info.EcPolicy != "" {
+		t.Errorf("file marked %q with a replicated block left", info.EcPolicy)
+	}
+	if err := leader.metadata.ConvertBlock(info.Inode, 1, 2, 200, groupFor(20)); err != nil {
+		t.Fatalf("convert block 1: %v", err)
+	}
+	if info, _ := leader.metadata.GetNodeInfo("/cold/f"); info.EcPolicy != "RS-2-1" {
+		t.Errorf("file policy = %q after conversion", info.EcPolicy)
+	}
+	usage, err := leader.metadata.GetQuota("/cold")
+	if err != nil || usage.SpaceConsumed != 300 {
+		t.Errorf("usage after conversion: %+v, %v", usage, err)
+	}
+	if candidates, _ := leader.metadata.ListECConversionCandidates(10); len(candidates) != 0 {
+		t.Errorf("converted file still a candidate: %+v", candidates)
+	}
+}
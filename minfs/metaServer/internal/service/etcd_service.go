@@ -29,6 +29,7 @@ type DataServerRegistration struct {
 	ID         string    `json:"id"`
 	Addr       string    `json:"addr"`
 	RegisterAt time.Time `json:"register_at"`
+	Topology   string    `json:"topology"` // 拓扑标签 (/zone/rack)
 }
 
 // NewEtcdService 创建新的 etcd 服务
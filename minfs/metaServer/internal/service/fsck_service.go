@@ -0,0 +1,167 @@
+package service
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"metaServer/pb"
+)
+
+// FsckPrefix 规范化 FSCK 的路径前缀，整个命名空间返回空字符串
+func FsckPrefix(path string) string {
+	if path == "" {
+		return ""
+	}
+	path = filepath.Clean(path)
+	if path == "/" {
+		return ""
+	}
+	return path
+}
+
+// inSubtree path 是否为 prefix 本身或位于其下
+func inSubtree(path, prefix string) bool {
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// blockReplicaState 期望位置中实际上报该块的节点为可用副本；期望位置少于目标副本数时也视为副本不足
+func blockReplicaState(expected, actual []string, replication int) (live []string, underReplicated, missing bool) {
+	reported := make(map[string]bool, len(actual))
+	for _, addr := range actual {
+		reported[addr] = true
+	}
+	for _, addr := range expected {
+		if reported[addr] {
+			live = append(live, addr)
+		}
+	}
+	underReplicated = len(live) < len(expected) || len(expected) < replication
+	return live, underReplicated, len(live) == 0
+}
+
+// fsckMatches 块是否满足请求的过滤条件，没有设置过滤条件时返回所有块
+func fsckMatches(req *pb.FsckRequest, block *pb.FsckBlock) bool {
+	if !req.OnlyUnderReplicated && !req.OnlyMissing && !req.OnlyCorrupt {
+		return true
+	}
+	return (req.OnlyUnderReplicated && block.UnderReplicated) ||
+		(req.OnlyMissing && block.Missing) ||
+		(req.OnlyCorrupt && block.Corrupt)
+}
+
+// FsckReport 按 FSCK 相同的规则检查 req.Path 子树下所有文件的块，满足过滤条件的块逐个交给 emit
+// 检查整个命名空间时同样包含只被快照引用的块，汇总与 runFSCK 的统计一致
+func (ss *SchedulerService) FsckReport(req *pb.FsckRequest, emit func(*pb.FsckBlock) error) (*pb.FsckSummary, error) {
+	prefix := FsckPrefix(req.Path)
+	actualBlocks := ss.getAllActualBlocks()
+	healthyServers := ss.clusterService.GetHealthyDataServers()
+	summary := &pb.FsckSummary{OrphanBlocks: -1}
+	seen := make(map[uint64]bool)
+
+	check := func(block *pb.FsckBlock) error {
+		if seen[block.BlockId] {
+			return nil
+		}
+		seen[block.BlockId] = true
+
+		var under, missing bool
+		block.LiveLocations, under, missing = blockReplicaState(block.ExpectedLocations, actualBlocks[block.BlockId], int(block.Replication))
+		for _, server := range healthyServers {
+			if server.HasCorruptBlock(block.BlockId) {
+				block.CorruptLocations = append(block.CorruptLocations, server.Addr)
+			}
+		}
+		block.UnderReplicated = under
+		block.Missing = missing
+		block.Corrupt = len(block.CorruptLocations) > 0
+
+		summary.TotalBlocks++
+		if under {
+			summary.UnderReplicatedBlocks++
+		}
+		if missing {
+			summary.MissingBlocks++
+		}
+		if block.Corrupt {
+			summary.CorruptBlocks++
+		}
+		if !under && !block.Corrupt {
+			summary.HealthyBlocks++
+		}
+
+		if !fsckMatches(req, block) {
+			return nil
+		}
+		return emit(block)
+	}
+
+	err := ss.metadataService.TraverseAllFiles(func(nodeInfo *pb.NodeInfo) error {
+		if nodeInfo.Type != pb.FileType_File {
+			return nil
+		}
+		if prefix != "" && !inSubtree(nodeInfo.Path, prefix) {
+			return nil
+		}
+		summary.TotalFiles++
+
+		blockMappings, err := ss.metadataService.GetBlockMappings(nodeInfo.Inode)
+		if err != nil {
+			return fmt.Errorf("failed to get block mappings for %s: %v", nodeInfo.Path, err)
+		}
+		for _, blockMapping := range blockMappings {
+			if blockMapping.EcPolicy != "" {
+				for _, stripe := range PhysicalBlocks(blockMapping) {
+					if err := check(&pb.FsckBlock{
+						Path:              nodeInfo.Path,
+						Inode:             nodeInfo.Inode,
+						BlockId:           stripe.BlockID,
+						GroupId:           blockMapping.BlockId,
+						ExpectedLocations: stripe.Locations,
+					}); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if err := check(&pb.FsckBlock{
+				Path:              nodeInfo.Path,
+				Inode:             nodeInfo.Inode,
+				BlockId:           blockMapping.BlockId,
+				Replication:       nodeInfo.Replication,
+				ExpectedLocations: blockMapping.Locations,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if prefix != "" {
+		return summary, nil
+	}
+
+	// 只被快照引用的块
+	snapshotBlocks, err := ss.metadataService.GetSnapshotBlocks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect snapshot blocks: %v", err)
+	}
+	blockIDs := make([]uint64, 0, len(snapshotBlocks))
+	for blockID := range snapshotBlocks {
+		if !seen[blockID] {
+			blockIDs = append(blockIDs, blockID)
+		}
+	}
+	sort.Slice(blockIDs, func(i, j int) bool { return blockIDs[i] < blockIDs[j] })
+	for _, blockID := range blockIDs {
+		if err := check(&pb.FsckBlock{BlockId: blockID, ExpectedLocations: snapshotBlocks[blockID]}); err != nil {
+			return nil, err
+		}
+	}
+
+	summary.OrphanBlocks = int64(ss.GetOrphanStats().LastPassFound)
+	return summary, nil
+}
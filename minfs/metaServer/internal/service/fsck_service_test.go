@@ -0,0 +1,91 @@
+package service
+
+import (
+	"testing"
+
+	"metaServer/internal/model"
+	"metaServer/pb"
+)
+
+func TestFsckReport(t *testing.T) {
+	_, servers := newTestCluster(t)
+	leader := waitForLeader(t, servers)
+
+	cs := &ClusterService{dataServers: make(map[string]*model.DataServerInfo)}
+	for _, id := range []string{"ds1", "ds2"} {
+		ds := &model.DataServerInfo{ID: id, Addr: id + ":8001", ReportedBlocks: make(map[uint64]bool)}
+		ds.UpdateStatus(0, 0, 0)
+		cs.dataServers[id] = ds
+	}
+	ss := &SchedulerService{clusterService: cs, metadataService: leader.metadata}
+
+	if err := leader.metadata.CreateNode("/a", pb.FileType_Directory); err != nil {
+		t.Fatalf("create /a: %v", err)
+	}
+	files := map[string][]*pb.BlockLocations{
+		"/a/f": {
+			{BlockId: 1, Locations: []string{"ds1:8001", "ds2:8001"}},
+			{BlockId: 2, Locations: []string{"ds1:8001", "ds2:8001"}},
+		},
+		"/ab": {
+			{BlockId: 3, Locations: []string{"ds1:8001", "ds2:8001"}},
+		},
+	}
+	for path, blocks := range files {
+		if err := leader.metadata.CreateNodeWithReplication(path, pb.FileType_File, 2); err != nil {
+			t.Fatalf("create %s: %v", path, err)
+		}
+		info, _ := leader.metadata.GetNodeInfo(path)
+		if err := leader.metadata.CommitFileState(path, info.Inode, 100, "", blocks); err != nil {
+			t.Fatalf("finalize %s: %v", path, err)
+		}
+	}
+
+	// 块1健康，块2在 ds2 上损坏，块3没有任何副本
+	cs.dataServers["ds1"].UpdateReportedBlocks([]uint64{1, 2})
+	cs.dataServers["ds2"].UpdateReportedBlocks([]uint64{1})
+	cs.dataServers["ds2"].UpdateCorruptBlocks([]uint64{2})
+
+	report := func(req *pb.FsckRequest) ([]*pb.FsckBlock, *pb.FsckSummary) {
+		t.Helper()
+		var blocks []*pb.FsckBlock
+		summary, err := ss.FsckReport(req, func(block *pb.FsckBlock) error {
+			blocks = append(blocks, block)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("fsck %+v: %v", req, err)
+		}
+		return blocks, summary
+	}
+
+	blocks, summary := report(&pb.FsckRequest{Path: "/"})
+	if len(blocks) != 3 || summary.TotalFiles != 2 || summary.TotalBlocks != 3 || summary.HealthyBlocks != 1 ||
+		summary.UnderReplicatedBlocks != 2 || summary.MissingBlocks != 1 || summary.CorruptBlocks != 1 || summary.OrphanBlocks != 0 {
+		t.Fatalf("full report: %d blocks, %+v", len(blocks), summary)
+	}
+
+	// 汇总与 FSCK 的统计一致
+	expected, replication, _, err := ss.collectExpectedBlocks()
+	if err != nil {
+		t.Fatalf("collect expected blocks: %v", err)
+	}
+	under, missing := replicationHealth(expected, ss.getAllActualBlocks(), replication)
+	if uint64(under) != summary.UnderReplicatedBlocks || uint64(missing) != summary.MissingBlocks {
+		t.Errorf("summary %+v, fsck under=%d missing=%d", summary, under, missing)
+	}
+
+	// /a 不包含 /ab
+	blocks, summary = report(&pb.FsckRequest{Path: "/a/", OnlyCorrupt: true})
+	if len(blocks) != 1 || blocks[0].BlockId != 2 || blocks[0].Path != "/a/f" || summary.TotalFiles != 1 || summary.OrphanBlocks != -1 {
+		t.Fatalf("corrupt under /a: %+v, %+v", blocks, summary)
+	}
+	if got := blocks[0]; len(got.LiveLocations) != 1 || got.CorruptLocations[0] != "ds2:8001" || !got.UnderReplicated || got.Missing {
+		t.Errorf("block 2: %+v", got)
+	}
+
+	blocks, _ = report(&pb.FsckRequest{OnlyMissing: true})
+	if len(blocks) != 1 || blocks[0].BlockId != 3 {
+		t.Errorf("missing blocks: %+v", blocks)
+	}
+}
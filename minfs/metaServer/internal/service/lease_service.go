@@ -0,0 +1,560 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"metaServer/internal/model"
+	"metaServer/pb"
+
+	"github.com/dgraph-io/badger/v3"
+	"google.golang.org/protobuf/proto"
+)
+
+// Lease 文件写租约
+// 记录写入开始前的文件状态，写入方中断后据此完成或回滚文件
+type Lease struct {
+	Path        string
+	Holder      string    // 写入方标识
+	Inode       uint64    // 文件 inode
+	AcquireTime time.Time // 获取时间
+	LastRenewed time.Time // 最后续约时间
+	TargetSize  int64     // 写入完成后预期的文件大小
+
+	PrevSize   int64                // 写入前的文件大小
+	PrevMd5    string               // 写入前的文件MD5
+	PrevBlocks []*pb.BlockLocations // 写入前的块映射
+}
+
+// LeaseManager 写租约管理
+// 同一路径同时只允许一个写入方；租约超过软限制未续约时可被其他写入方抢占，
+// 超过硬限制时由后台恢复：新分配的块都已上报则按预期大小完成写入，否则回滚到写入前的状态，
+// 不再被引用的块交给垃圾回收。租约记录通过日志提交，leader 切换后仍然有效；
+// 续约时间只保存在 leader 内存中，新 leader 从第一次看到租约时开始计算
+type LeaseManager struct {
+	metadataService *MetadataService
+	clusterService  *ClusterService
+
+	softLimit     time.Duration
+	hardLimit     time.Duration
+	checkInterval time.Duration
+
+	renewed map[string]time.Time // path -> 最后续约时间
+	term    uint64               // renewed 记录的 leader 任期
+	mu      sync.Mutex
+
+	stopChan chan struct{}
+}
+
+// NewLeaseManager 创建写租约管理器
+func NewLeaseManager(config *model.Config, metadataService *MetadataService, clusterService *ClusterService) *LeaseManager {
+	softLimit := config.Lease.SoftLimit
+	if softLimit <= 0 {
+		softLimit = time.Minute
+	}
+	hardLimit := config.Lease.HardLimit
+	if hardLimit < softLimit {
+		hardLimit = 10 * softLimit
+	}
+	checkInterval := config.Lease.CheckInterval
+	if checkInterval <= 0 {
+		checkInterval = 30 * time.Second
+	}
+
+	return &LeaseManager{
+		metadataService: metadataService,
+		clusterService:  clusterService,
+		softLimit:       softLimit,
+		hardLimit:       hardLimit,
+		checkInterval:   checkInterval,
+		renewed:         make(map[string]time.Time),
+		stopChan:        make(chan struct{}),
+	}
+}
+
+// Start 启动过期租约检查
+func (lm *LeaseManager) Start() {
+	go lm.monitorLoop()
+	log.Printf("Lease manager started (soft limit: %v, hard limit: %v)", lm.softLimit, lm.hardLimit)
+}
+
+// Stop 停止过期租约检查
+func (lm *LeaseManager) Stop() {
+	close(lm.stopChan)
+}
+
+// Acquire 为写入方获取文件的写租约
+// size 为 GetBlockLocations 请求的大小，追加模式下为追加的字节数
+// 有效租约的持有者再次写入时拒绝，只有过了软限制或文件已被替换的租约会先恢复再重新授予
+func (lm *LeaseManager) Acquire(path, holder string, size int64, appendMode bool) (*Lease, error) {
+	// 当选前提交的租约记录应用之后才能判断文件是否正在被写入
+	if lm.clusterService != nil && !lm.clusterService.IsLeaderReady() {
+		return nil, fmt.Errorf("leader is not ready to grant leases")
+	}
+
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	nodeInfo, err := lm.metadataService.GetNodeInfo(path)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := lm.getLocked(path)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		if existing.Inode == nodeInfo.Inode && time.Since(existing.LastRenewed) < lm.softLimit {
+			if existing.Holder == holder {
+				return nil, fmt.Errorf("file %s is already being written by %s", path, holder)
+			}
+			return nil, fmt.Errorf("file %s is being written by %s", path, existing.Holder)
+		}
+
+		// 原写入方租约已过软限制或文件已被替换：先恢复上一次未完成的写入
+		log.Printf("Lease on %s held by %s is replaced by %s, recovering previous write", path, existing.Holder, holder)
+		if err := lm.recoverLocked(existing); err != nil {
+			return nil, err
+		}
+		if nodeInfo, err = lm.metadataService.GetNodeInfo(path); err != nil {
+			return nil, err
+		}
+	}
+
+	blocks, err := lm.metadataService.GetBlockMappings(nodeInfo.Inode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block mappings of %s: %v", path, err)
+	}
+
+	// 只保留文件大小覆盖到的块，之后的残留映射不属于文件内容
+	blockSize := lm.metadataService.BlockSize()
+	blockCount := (uint64(nodeInfo.Size) + blockSize - 1) / blockSize
+	if uint64(len(blocks)) > blockCount {
+		blocks = blocks[:blockCount]
+	}
+
+	targetSize := size
+	if appendMode {
+		targetSize = nodeInfo.Size + size
+	}
+
+	now := time.Now()
+	record := &pb.GrantLeaseOperation{
+		Path:        path,
+		Holder:      holder,
+		Inode:       nodeInfo.Inode,
+		AcquireTime: now.UnixMilli(),
+		TargetSize:  targetSize,
+		PrevSize:    nodeInfo.Size,
+		PrevMd5:     nodeInfo.Md5,
+		PrevBlocks:  blocks,
+	}
+	if err := lm.metadataService.GrantLease(record); err != nil {
+		return nil, err
+	}
+	lm.syncTermLocked()
+	lm.renewed[path] = now
+
+	log.Printf("Lease on %s granted to %s (inode=%d, target size=%d)", path, holder, nodeInfo.Inode, targetSize)
+	return leaseFromRecord(record, now), nil
+}
+
+// Renew 续约写租约，path 为空时续约该写入方持有的所有租约，返回续约的数量
+func (lm *LeaseManager) Renew(holder, path string) int {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	records, err := lm.metadataService.GetLeases()
+	if err != nil {
+		log.Printf("Failed to load leases: %v", err)
+		return 0
+	}
+
+	lm.syncTermLocked()
+	renewed := 0
+	now := time.Now()
+	for _, record := range records {
+		if record.Holder != holder || (path != "" && record.Path != path) {
+			continue
+		}
+		lm.renewed[record.Path] = now
+		renewed++
+	}
+	return renewed
+}
+
+// CheckHolder 检查写入方是否可以完成文件写入，返回其持有的租约
+// 文件必须持有该写入方对应 inode 的租约，且租约未超过硬限制
+func (lm *LeaseManager) CheckHolder(path, holder string, inodeID uint64) (*Lease, error) {
+	if holder == "" {
+		return nil, fmt.Errorf("lease holder cannot be empty")
+	}
+
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	lease, err := lm.getLocked(path)
+	if err != nil {
+		return nil, err
+	}
+	if lease == nil {
+		return nil, fmt.Errorf("no lease on %s held by %s", path, holder)
+	}
+	if lease.Holder != holder {
+		return nil, fmt.Errorf("file %s is being written by %s", path, lease.Holder)
+	}
+	if lease.Inode != inodeID {
+		return nil, fmt.Errorf("inode %d does not match lease on %s (inode %d)", inodeID, path, lease.Inode)
+	}
+	if time.Since(lease.LastRenewed) > lm.hardLimit {
+		return nil, fmt.Errorf("lease on %s held by %s has expired", path, holder)
+	}
+	return lease, nil
+}
+
+// IsLeased 文件是否正在被写入
+func (lm *LeaseManager) IsLeased(path string) bool {
+	record, err := lm.metadataService.GetLease(path)
+	return err == nil && record != nil
+}
+
+// Complete 写入正常提交（租约已随 FINALIZE_WRITE 释放）后，回收被 committed 替换的旧块
+func (lm *LeaseManager) Complete(lease *Lease, committed []*pb.BlockLocations) {
+	lm.mu.Lock()
+	delete(lm.renewed, lease.Path)
+	lm.mu.Unlock()
+
+	lm.collectUnreferenced(lease.PrevBlocks, committed)
+	log.Printf("Lease on %s released by %s", lease.Path, lease.Holder)
+}
+
+// getLocked 读取路径上的租约，没有租约时返回 nil，调用方需持有 lm.mu
+func (lm *LeaseManager) getLocked(path string) (*Lease, error) {
+	record, err := lm.metadataService.GetLease(path)
+	if err != nil || record == nil {
+		return nil, err
+	}
+	lm.syncTermLocked()
+	return leaseFromRecord(record, lm.lastRenewedLocked(record.Path)), nil
+}
+
+// lastRenewedLocked 租约在本节点担任 leader 期间的最后续约时间，第一次看到时从当前时间开始计算，调用方需持有 lm.mu
+func (lm *LeaseManager) lastRenewedLocked(path string) time.Time {
+	if renewed, exists := lm.renewed[path]; exists {
+		return renewed
+	}
+	now := time.Now()
+	lm.renewed[path] = now
+	return now
+}
+
+// syncTermLocked leader 任期变化后丢弃之前记录的续约时间，调用方需持有 lm.mu
+func (lm *LeaseManager) syncTermLocked() {
+	var term uint64
+	if lm.clusterService != nil {
+		term = lm.clusterService.LeaderTerm()
+	}
+	if term != lm.term {
+		lm.term = term
+		lm.renewed = make(map[string]time.Time)
+	}
+}
+
+// leaseFromRecord 由日志提交的租约记录和续约时间构造租约
+func leaseFromRecord(record *pb.GrantLeaseOperation, lastRenewed time.Time) *Lease {
+	return &Lease{
+		Path:        record.Path,
+		Holder:      record.Holder,
+		Inode:       record.Inode,
+		AcquireTime: time.UnixMilli(record.AcquireTime),
+		LastRenewed: lastRenewed,
+		TargetSize:  record.TargetSize,
+		PrevSize:    record.PrevSize,
+		PrevMd5:     record.PrevMd5,
+		PrevBlocks:  record.PrevBlocks,
+	}
+}
+
+// monitorLoop 定期恢复超过硬限制的租约
+func (lm *LeaseManager) monitorLoop() {
+	ticker := time.NewTicker(lm.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			lm.checkExpiredLeases()
+		case <-lm.stopChan:
+			return
+		}
+	}
+}
+
+// checkExpiredLeases 恢复超过硬限制的租约
+// 只在已追上日志的 leader 上执行，新 leader 上的租约从当选后第一次检查开始计时
+func (lm *LeaseManager) checkExpiredLeases() {
+	if lm.clusterService != nil && !lm.clusterService.IsLeaderReady() {
+		return
+	}
+
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	records, err := lm.metadataService.GetLeases()
+	if err != nil {
+		log.Printf("Failed to load leases: %v", err)
+		return
+	}
+
+	lm.syncTermLocked()
+	for _, record := range records {
+		lease := leaseFromRecord(record, lm.lastRenewedLocked(record.Path))
+		if time.Since(lease.LastRenewed) > lm.hardLimit {
+			log.Printf("Lease on %s held by %s exceeded hard limit, recovering", lease.Path, lease.Holder)
+			if err := lm.recoverLocked(lease); err != nil {
+				log.Printf("Lease recovery: %v", err)
+			}
+		}
+	}
+}
+
+// recoverLocked 恢复未完成的写入并释放租约，调用方需持有 lm.mu
+// 完成或回滚与释放租约通过同一条日志提交，租约已被替换时提交失败
+func (lm *LeaseManager) recoverLocked(lease *Lease) error {
+	nodeInfo, err := lm.metadataService.GetNodeInfo(lease.Path)
+	if err != nil || nodeInfo.Inode != lease.Inode {
+		// 文件已被删除或替换，其块由对应操作负责回收
+		log.Printf("Lease recovery: %s no longer refers to inode %d, dropping lease", lease.Path, lease.Inode)
+		if err := lm.metadataService.ReleaseLease(lease.Path, lease.Holder); err != nil {
+			return fmt.Errorf("failed to release lease on %s: %v", lease.Path, err)
+		}
+		delete(lm.renewed, lease.Path)
+		return nil
+	}
+
+	current, err := lm.metadataService.GetBlockMappings(lease.Inode)
+	if err != nil {
+		return fmt.Errorf("failed to get block mappings of %s: %v", lease.Path, err)
+	}
+
+	if lm.writeLooksComplete(lease, current) {
+		if err := lm.metadataService.CommitLeasedFileState(lease.Path, lease.Inode, lease.TargetSize, "", current, lease.Holder); err != nil {
+			return fmt.Errorf("failed to finalize %s: %v", lease.Path, err)
+		}
+		delete(lm.renewed, lease.Path)
+		lm.collectUnreferenced(lease.PrevBlocks, current)
+		log.Printf("Lease recovery: finalized %s with size %d", lease.Path, lease.TargetSize)
+		return nil
+	}
+
+	if err := lm.metadataService.CommitLeasedFileState(lease.Path, lease.Inode, lease.PrevSize, lease.PrevMd5, lease.PrevBlocks, lease.Holder); err != nil {
+		return fmt.Errorf("failed to roll back %s: %v", lease.Path, err)
+	}
+	delete(lm.renewed, lease.Path)
+	lm.collectUnreferenced(current, lease.PrevBlocks)
+	log.Printf("Lease recovery: rolled back %s to size %d", lease.Path, lease.PrevSize)
+	return nil
+}
+
+// writeLooksComplete 判断中断的写入是否已写完所有数据
+// 覆盖写和追加都把数据写入新分配的块（追加时未写满的尾块也由新块替换），已有的块不被修改；
+// 块数与预期大小一致、存在新分配的块且新块都已被DataServer上报时认为写入完成
+func (lm *LeaseManager) writeLooksComplete(lease *Lease, current []*pb.BlockLocations) bool {
+	blockSize := lm.metadataService.BlockSize()
+	expectedBlocks := (uint64(lease.TargetSize) + blockSize - 1) / blockSize
+	if uint64(len(current)) != expectedBlocks {
+		return false
+	}
+
+	prev := make(map[uint64]bool, len(lease.PrevBlocks))
+	for _, block := range lease.PrevBlocks {
+		prev[block.BlockId] = true
+	}
+
+	reported := make(map[uint64]bool)
+	if lm.clusterService != nil {
+		for _, server := range lm.clusterService.GetHealthyDataServers() {
+			for blockID := range server.GetReportedBlocks() {
+				reported[blockID] = true
+			}
+		}
+	}
+
+	// 没有新块说明还没有为本次写入分配块
+	newBlocks := 0
+	for _, block := range current {
+		if prev[block.BlockId] {
+			continue
+		}
+		if !blockReported(block, reported) {
+			return false
+		}
+		newBlocks++
+	}
+	return newBlocks > 0
+}
+
+// blockReported 块是否已被上报，纠删码块组至少上报了 k 个条带时即可读出，缺失的条带由 FSCK 重建
+func blockReported(block *pb.BlockLocations, reported map[uint64]bool) bool {
+	if block.EcPolicy == "" {
+		return reported[block.BlockId]
+	}
+	k, _, err := ParseECPolicy(block.EcPolicy)
+	if err != nil {
+		return false
+	}
+	count := 0
+	for _, stripeID := range block.StripeIds {
+		if reported[stripeID] {
+			count++
+		}
+	}
+	return count >= k
+}
+
+// collectUnreferenced 将 from 中不再出现在 to 中的块加入垃圾回收
+func (lm *LeaseManager) collectUnreferenced(from, to []*pb.BlockLocations) {
+	keep := make(map[uint64]bool, len(to))
+	for _, block := range to {
+		for _, physical := range PhysicalBlocks(block) {
+			keep[physical.BlockID] = true
+		}
+	}
+
+	for _, block := range from {
+		for _, physical := range PhysicalBlocks(block) {
+			if keep[physical.BlockID] {
+				continue
+			}
+			if err := lm.metadataService.AddGCEntry(physical.BlockID, physical.Locations); err != nil {
+				log.Printf("Failed to add GC entry for block %d: %v", physical.BlockID, err)
+			}
+		}
+	}
+}
+
+// GrantLease 通过日志提交写租约记录，替换路径上已有的租约
+func (ms *MetadataService) GrantLease(record *pb.GrantLeaseOperation) error {
+	_, err := ms.propose(pb.WALOperationType_GRANT_LEASE, record)
+	if err != nil {
+		return fmt.Errorf("failed to grant lease on %s: %v", record.Path, err)
+	}
+	return nil
+}
+
+// ReleaseLease 通过日志释放写租约，租约已属于其他写入方时不做修改
+func (ms *MetadataService) ReleaseLease(path, holder string) error {
+	_, err := ms.propose(pb.WALOperationType_RELEASE_LEASE, &pb.ReleaseLeaseOperation{Path: path, Holder: holder})
+	return err
+}
+
+// grantLeaseInDB 保存写租约记录（仅数据库操作，不写WAL）
+func (ms *MetadataService) grantLeaseInDB(record *pb.GrantLeaseOperation) error {
+	data, err := proto.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return ms.applyUpdate(func(txn *badger.Txn) error {
+		return txn.Set([]byte(model.PrefixLease+record.Path), data)
+	})
+}
+
+// releaseLeaseInDB 删除写租约记录（仅数据库操作，不写WAL）
+func (ms *MetadataService) releaseLeaseInDB(path, holder string) error {
+	return ms.applyUpdate(func(txn *badger.Txn) error {
+		record, err := getLeaseInTx(txn, path)
+		if err != nil || record == nil || record.Holder != holder {
+			return err
+		}
+		return txn.Delete([]byte(model.PrefixLease + path))
+	})
+}
+
+// releaseLeaseInTx 在事务中释放写入方对 inode 的租约，没有匹配的租约时返回错误
+func (ms *MetadataService) releaseLeaseInTx(txn *badger.Txn, path, holder string, inodeID uint64) error {
+	record, err := getLeaseInTx(txn, path)
+	if err != nil {
+		return err
+	}
+	if record == nil || record.Holder != holder || record.Inode != inodeID {
+		return fmt.Errorf("no lease on %s (inode %d) held by %s", path, inodeID, holder)
+	}
+	return txn.Delete([]byte(model.PrefixLease + path))
+}
+
+// GetLease 获取路径上的写租约记录，没有租约时返回 nil
+func (ms *MetadataService) GetLease(path string) (*pb.GrantLeaseOperation, error) {
+	var record *pb.GrantLeaseOperation
+	err := ms.db.View(func(txn *badger.Txn) error {
+		var err error
+		record, err = getLeaseInTx(txn, path)
+		return err
+	})
+	return record, err
+}
+
+// GetLeases 获取所有写租约记录
+func (ms *MetadataService) GetLeases() ([]*pb.GrantLeaseOperation, error) {
+	var records []*pb.GrantLeaseOperation
+	err := ms.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		prefix := []byte(model.PrefixLease)
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			var record pb.GrantLeaseOperation
+			if err := it.Item().Value(func(val []byte) error {
+				return proto.Unmarshal(val, &record)
+			}); err != nil {
+				return err
+			}
+			records = append(records, &record)
+		}
+		return nil
+	})
+	return records, err
+}
+
+// getLeaseInTx 在事务中读取写租约记录，没有租约时返回 nil
+func getLeaseInTx(txn *badger.Txn, path string) (*pb.GrantLeaseOperation, error) {
+	item, err := txn.Get([]byte(model.PrefixLease + path))
+	if err == badger.ErrKeyNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var record pb.GrantLeaseOperation
+	if err := item.Value(func(val []byte) error {
+		return proto.Unmarshal(val, &record)
+	}); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// leasedPathInTx 在事务中查找 path 或其子树中持有写租约的文件，没有时返回空
+func leasedPathInTx(txn *badger.Txn, path string) (string, error) {
+	record, err := getLeaseInTx(txn, path)
+	if err != nil {
+		return "", err
+	}
+	if record != nil {
+		return record.Path, nil
+	}
+
+	opts := badger.DefaultIteratorOptions
+	opts.PrefetchValues = false
+	it := txn.NewIterator(opts)
+	defer it.Close()
+
+	prefix := []byte(model.PrefixLease + path + "/")
+	it.Seek(prefix)
+	if it.ValidForPrefix(prefix) {
+		return string(it.Item().Key()[len(model.PrefixLease):]), nil
+	}
+	return "", nil
+}
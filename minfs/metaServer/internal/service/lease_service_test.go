@@ -0,0 +1,200 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"metaServer/internal/model"
+	"metaServer/pb"
+)
+
+// newTestLeaseManager 在 server 上创建软限制为 softLimit 的写租约管理器
+func newTestLeaseManager(server *testMetaServer, softLimit time.Duration) *LeaseManager {
+	config := &model.Config{}
+	config.Lease.SoftLimit = softLimit
+	config.Lease.HardLimit = 2 * softLimit
+	return NewLeaseManager(config, server.metadata, nil)
+}
+
+// createTestFile 创建只有一个块的文件，返回其 inode
+func createTestFile(t *testing.T, server *testMetaServer, path string, block *pb.BlockLocations) uint64 {
+	t.Helper()
+	if err := server.metadata.CreateNode(path, pb.FileType_File); err != nil {
+		t.Fatalf("create %s: %v", path, err)
+	}
+	info, err := server.metadata.GetNodeInfo(path)
+	if err != nil {
+		t.Fatalf("stat %s: %v", path, err)
+	}
+	if err := server.metadata.CommitFileState(path, info.Inode, 100, "md5-1", []*pb.BlockLocations{block}); err != nil {
+		t.Fatalf("finalize %s: %v", path, err)
+	}
+	return info.Inode
+}
+
+func TestLeaseTakeoverRollsBackUnfinishedWrite(t *testing.T) {
+	_, servers := newTestCluster(t)
+	leader := waitForLeader(t, servers)
+	lm := newTestLeaseManager(leader, 200*time.Millisecond)
+
+	oldTail := &pb.BlockLocations{BlockId: 1, Locations: []string{"ds1:8001"}}
+	inode := createTestFile(t, leader, "/f", oldTail)
+
+	if _, err := lm.Acquire("/f", "alice", 50, true); err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	// 有效租约的持有者和其他写入方都不能再次获取
+	if _, err := lm.Acquire("/f", "alice", 50, true); err == nil {
+		t.Fatal("second write from the live holder accepted")
+	}
+	if _, err := lm.Acquire("/f", "bob", 50, true); err == nil {
+		t.Fatal("live lease taken over")
+	}
+
+	// alice 的追加用新块替换了尾块，但没有提交
+	newTail := &pb.BlockLocations{BlockId: 2, Locations: []string{"ds1:8001"}}
+	if err := leader.metadata.SetBlockMapping(inode, 0, newTail); err != nil {
+		t.Fatalf("set block mapping: %v", err)
+	}
+
+	for _, c := range []struct {
+		holder string
+		inode  uint64
+	}{{"", inode}, {"bob", inode}, {"alice", inode + 1}} {
+		if _, err := lm.CheckHolder("/f", c.holder, c.inode); err == nil {
+			t.Errorf("CheckHolder(%q, %d) accepted", c.holder, c.inode)
+		}
+	}
+	if _, err := lm.CheckHolder("/f", "alice", inode); err != nil {
+		t.Fatalf("CheckHolder for the holder: %v", err)
+	}
+
+	// 超过软限制后 bob 抢占租约，alice 的写入回滚到写入前的状态
+	time.Sleep(250 * time.Millisecond)
+	if _, err := lm.Acquire("/f", "bob", 10, false); err != nil {
+		t.Fatalf("take over expired lease: %v", err)
+	}
+	info, err := leader.metadata.GetNodeInfo("/f")
+	if err != nil || info.Size != 100 || info.Md5 != "md5-1" {
+		t.Fatalf("file after rollback: %v, %v", info, err)
+	}
+	blocks, err := leader.metadata.GetBlockMappings(inode)
+	if err != nil || len(blocks) != 1 || blocks[0].BlockId != oldTail.BlockId {
+		t.Fatalf("block mappings after rollback: %v, %v", blocks, err)
+	}
+	entries, err := leader.metadata.GetGCEntries()
+	if err != nil || len(entries) != 1 || entries[0].BlockID != newTail.BlockId {
+		t.Errorf("gc entries after rollback: %v, %v", entries, err)
+	}
+
+	// alice 迟到的 FinalizeWrite 不能提交
+	if _, err := lm.CheckHolder("/f", "alice", inode); err == nil {
+		t.Error("late finalize from the previous holder passed CheckHolder")
+	}
+	if err := leader.metadata.CommitLeasedFileState("/f", inode, 150, "", []*pb.BlockLocations{newTail}, "alice"); err == nil {
+		t.Error("late finalize from the previous holder committed")
+	}
+
+	lease, err := lm.CheckHolder("/f", "bob", inode)
+	if err != nil {
+		t.Fatalf("CheckHolder for the new holder: %v", err)
+	}
+	if err := leader.metadata.CommitLeasedFileState("/f", inode, 10, "md5-2", blocks, "bob"); err != nil {
+		t.Fatalf("finalize by the new holder: %v", err)
+	}
+	lm.Complete(lease, blocks)
+	if lm.IsLeased("/f") {
+		t.Error("lease not released by finalize")
+	}
+}
+
+func TestLeaseSurvivesLeaderChange(t *testing.T) {
+	network, servers := newTestCluster(t)
+	leader := waitForLeader(t, servers)
+
+	inode := createTestFile(t, leader, "/f", &pb.BlockLocations{BlockId: 1, Locations: []string{"ds1:8001"}})
+	if _, err := newTestLeaseManager(leader, time.Minute).Acquire("/f", "alice", 50, true); err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+
+	network.setDown(leader.id, true)
+	var others []*testMetaServer
+	for _, server := range servers {
+		if server != leader {
+			others = append(others, server)
+		}
+	}
+	newLeader := waitForLeader(t, others)
+	deadline := time.Now().Add(5 * time.Second)
+	for !newLeader.raft.IsLeaderReady() {
+		if time.Now().After(deadline) {
+			t.Fatal("new leader did not catch up")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// 新 leader 上租约仍然有效，从第一次看到时开始计算续约时间
+	lm := newTestLeaseManager(newLeader, time.Minute)
+	if !lm.IsLeased("/f") {
+		t.Fatal("lease lost after leader change")
+	}
+	if _, err := lm.Acquire("/f", "bob", 10, false); err == nil {
+		t.Fatal("lease taken over right after leader change")
+	}
+	lease, err := lm.CheckHolder("/f", "alice", inode)
+	if err != nil {
+		t.Fatalf("CheckHolder on the new leader: %v", err)
+	}
+	if lease.PrevSize != 100 || lease.TargetSize != 150 || len(lease.PrevBlocks) != 1 {
+		t.Errorf("lease on the new leader: %+v", lease)
+	}
+	if err := newLeader.metadata.CommitLeasedFileState("/f", inode, 150, "", nil, "alice"); err != nil {
+		t.Fatalf("finalize on the new leader: %v", err)
+	}
+	if lm.IsLeased("/f") {
+		t.Error("lease not released by finalize")
+	}
+}
+
+func TestRenameRejectsLeasedFiles(t *testing.T) {
+	_, servers := newTestCluster(t)
+	leader := waitForLeader(t, servers)
+	lm := newTestLeaseManager(leader, time.Minute)
+
+	if err := leader.metadata.CreateNode("/d", pb.FileType_Directory); err != nil {
+		t.Fatalf("create /d: %v", err)
+	}
+	inode := createTestFile(t, leader, "/d/f", &pb.BlockLocations{BlockId: 1, Locations: []string{"ds1:8001"}})
+	createTestFile(t, leader, "/other", &pb.BlockLocations{BlockId: 2, Locations: []string{"ds1:8001"}})
+	if _, err := lm.Acquire("/d/f", "alice", 50, true); err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+
+	// 正在写入的文件、包含它的目录都不能移动，也不能被覆盖
+	for _, c := range []struct{ src, dst string }{
+		{"/d/f", "/g"},
+		{"/d", "/e"},
+		{"/other", "/d/f"},
+	} {
+		if _, err := leader.metadata.RenameNode(c.src, c.dst, true); err == nil {
+			t.Errorf("rename %s -> %s succeeded while /d/f is leased", c.src, c.dst)
+		}
+	}
+	if !lm.IsLeased("/d/f") {
+		t.Fatal("lease lost after rejected renames")
+	}
+
+	lease, err := lm.CheckHolder("/d/f", "alice", inode)
+	if err != nil {
+		t.Fatalf("CheckHolder: %v", err)
+	}
+	if err := leader.metadata.CommitLeasedFileState("/d/f", inode, 150, "", nil, "alice"); err != nil {
+		t.Fatalf("finalize: %v", err)
+	}
+	lm.Complete(lease, nil)
+
+	// 写入完成后可以正常移动
+	if _, err := leader.metadata.RenameNode("/d", "/e", false); err != nil {
+		t.Errorf("rename after finalize: %v", err)
+	}
+}
@@ -21,6 +21,11 @@ type MetadataService struct {
 	db         *badger.DB
 	config     *model.Config
 	walService *WALService
+	raftNode   *RaftNode
+
+	// 正在应用的日志索引及其是否已随元数据写入，只在 Raft 应用协程中访问
+	applyingIndex  uint64
+	applyPersisted bool
 }
 
 func NewMetadataService(db *badger.DB, config *model.Config, walService *WALService) *MetadataService {
@@ -31,6 +36,54 @@ func NewMetadataService(db *badger.DB, config *model.Config, walService *WALServ
 	}
 }
 
+// SetRaftNode 设置Raft节点，元数据变更通过其日志提交
+func (ms *MetadataService) SetRaftNode(rn *RaftNode) {
+	ms.raftNode = rn
+}
+
+// beginApply 开始应用日志条目，之后 applyUpdate 的事务同时写入该条目的索引
+func (ms *MetadataService) beginApply(index uint64) {
+	ms.applyingIndex = index
+	ms.applyPersisted = false
+}
+
+// endApply 结束应用日志条目，返回条目的索引是否已随元数据在同一事务中写入
+func (ms *MetadataService) endApply() bool {
+	persisted := ms.applyPersisted
+	ms.applyingIndex = 0
+	ms.applyPersisted = false
+	return persisted
+}
+
+// applyUpdate 应用日志条目的数据库事务，元数据修改和已应用的日志索引一起提交，
+// 重启后不会重复应用已生效的条目；不在应用日志时与 db.Update 相同
+func (ms *MetadataService) applyUpdate(fn func(txn *badger.Txn) error) error {
+	index := ms.applyingIndex
+	err := ms.db.Update(func(txn *badger.Txn) error {
+		if err := fn(txn); err != nil {
+			return err
+		}
+		if index == 0 {
+			return nil
+		}
+		buf := make([]byte, 8)
+		binary.BigEndian.PutUint64(buf, index)
+		return txn.Set([]byte(model.RaftAppliedKey), buf)
+	})
+	if err == nil && index != 0 {
+		ms.applyPersisted = true
+	}
+	return err
+}
+
+// propose 提交一个元数据变更，多数节点持久化并在本地应用后返回应用结果
+func (ms *MetadataService) propose(operation pb.WALOperationType, data interface{}) (interface{}, error) {
+	if ms.raftNode == nil {
+		return nil, fmt.Errorf("metadata replication is not configured")
+	}
+	return ms.raftNode.Propose(operation, data)
+}
+
 // generateInodeID 生成新的 Inode ID
 func (ms *MetadataService) generateInodeID() (uint64, error) {
 	var inodeID uint64
@@ -59,13 +112,50 @@ func (ms *MetadataService) generateInodeID() (uint64, error) {
 	return inodeID, err
 }
 
-// CreateNode 创建文件或目录节点
+// CreateNode 创建文件或目录节点，先分配 Inode ID 再通过日志提交
 func (ms *MetadataService) CreateNode(path string, nodeType pb.FileType) error {
-	return ms.CreateNodeWithInode(path, nodeType, nil)
+	return ms.CreateNodeWithReplication(path, nodeType, 0)
+}
+
+// CreateNodeWithReplication 创建节点并指定文件副本数，0 表示使用默认副本数；节点不记录属主
+func (ms *MetadataService) CreateNodeWithReplication(path string, nodeType pb.FileType, replication uint32) error {
+	return ms.createNode(path, nodeType, replication, model.Ownership{})
 }
 
-// CreateNodeWithInode 创建文件或目录节点，可以指定Inode ID（用于WAL回放）
-func (ms *MetadataService) CreateNodeWithInode(path string, nodeType pb.FileType, inodeID *uint64) error {
+// createNode 分配 Inode ID 并通过日志提交节点创建
+func (ms *MetadataService) createNode(path string, nodeType pb.FileType, replication uint32, ownership model.Ownership) error {
+	if err := ms.ValidateReplication(replication); err != nil {
+		return err
+	}
+	path = filepath.Clean(path)
+	if path == "." {
+		path = "/"
+	}
+	if IsSnapshotPath(path) {
+		return fmt.Errorf("snapshot path is read-only: %s", path)
+	}
+
+	inodeID, err := ms.generateInodeID()
+	if err != nil {
+		return fmt.Errorf("failed to generate inode ID: %v", err)
+	}
+
+	_, err = ms.propose(pb.WALOperationType_CREATE_NODE, &pb.CreateNodeOperation{
+		Path:        path,
+		Type:        nodeType,
+		InodeId:     inodeID,
+		Replication: replication,
+		Owner:       ownership.Owner,
+		Group:       ownership.Group,
+		Mode:        ownership.Mode,
+		Mtime:       time.Now().UnixMilli(),
+	})
+	return err
+}
+
+// CreateNodeWithInode 创建文件或目录节点，可以指定Inode ID（用于WAL回放），replication 为 0 时使用默认副本数
+// ownership.Owner 为空时不记录属主和权限位，mtime 为 Unix 毫秒时间戳
+func (ms *MetadataService) CreateNodeWithInode(path string, nodeType pb.FileType, inodeID *uint64, replication uint32, ownership model.Ownership, mtime int64) error {
 	// 转换 FileType 为内部使用的 NodeType
 	var internalType pb.FileType
 	switch nodeType {
@@ -82,7 +172,7 @@ func (ms *MetadataService) CreateNodeWithInode(path string, nodeType pb.FileType
 		path = "/"
 	}
 
-	return ms.db.Update(func(txn *badger.Txn) error {
+	return ms.applyUpdate(func(txn *badger.Txn) error {
 		// 检查路径是否已存在
 		pathKey := model.PrefixPath + path
 		_, err := txn.Get([]byte(pathKey))
@@ -136,14 +226,41 @@ func (ms *MetadataService) CreateNodeWithInode(path string, nodeType pb.FileType
 			}
 		}
 
+		// 新节点计入所有祖先目录的节点数
+		if path != "/" {
+			delta := model.DirectoryUsage{InodeCount: 1}
+			if err := ms.checkQuotaInTx(txn, path, delta, nil); err != nil {
+				return err
+			}
+			if err := ms.addUsageInTx(txn, path, delta); err != nil {
+				return err
+			}
+		}
+
 		// 创建 NodeInfo
 		nodeInfo := &pb.NodeInfo{
 			Inode:       nodeInodeID,
 			Path:        path,
 			Type:        internalType,
 			Size:        0,
-			Mtime:       time.Now().UnixMilli(),
-			Replication: uint32(ms.config.Cluster.DefaultReplication),
+			Mtime:       mtime,
+			Replication: replication,
+		}
+		if nodeInfo.Replication == 0 {
+			nodeInfo.Replication = ms.DefaultReplication()
+		}
+		if ownership.Owner != "" {
+			nodeInfo.Owner = ownership.Owner
+			nodeInfo.Group = ownership.Group
+			nodeInfo.Mode = ownership.Mode
+		}
+
+		// 新文件使用最近的祖先目录上设置的纠删码策略
+		if internalType == pb.FileType_File {
+			nodeInfo.EcPolicy, _, err = ms.effectiveECPolicyInTx(txn, parentPath)
+			if err != nil {
+				return err
+			}
 		}
 
 		// 序列化并存储 Inode 信息
@@ -157,6 +274,12 @@ func (ms *MetadataService) CreateNodeWithInode(path string, nodeType pb.FileType
 			return err
 		}
 
+		if internalType == pb.FileType_Directory {
+			if err := ms.setUsageInTx(txn, nodeInodeID, model.DirectoryUsage{InodeCount: 1}); err != nil {
+				return err
+			}
+		}
+
 		// 存储路径映射
 		inodeBuf := make([]byte, 8)
 		binary.BigEndian.PutUint64(inodeBuf, nodeInodeID)
@@ -180,6 +303,9 @@ func (ms *MetadataService) CreateNodeWithInode(path string, nodeType pb.FileType
 			if err := txn.Set([]byte(dirKey), inodeBuf); err != nil {
 				return err
 			}
+			if err := ms.addChildCountInTx(txn, parentInodeID, 1); err != nil {
+				return err
+			}
 		}
 
 		return nil
@@ -260,15 +386,14 @@ func (ms *MetadataService) GetNodeInfo(path string) (*pb.NodeInfo, error) {
 			return err
 		}
 
-		// 如果是目录，计算其总大小（递归计算子文件和子目录大小）
+		// 目录的大小为子树中文件大小之和
 		if nodeInfo.Type == pb.FileType_Directory {
-			totalSize, err := ms.calculateDirectorySizeInTx(txn, inodeID)
+			usage, err := ms.getUsageInTx(txn, inodeID)
 			if err != nil {
-				// 如果计算失败，记录错误但不中断操作，使用原始大小
-				fmt.Printf("Warning: failed to calculate directory size for %s: %v\n", path, err)
-			} else {
-				nodeInfo.Size = totalSize
+				return err
 			}
+			nodeInfo.Size = usage.Bytes
+			nodeInfo.ChildCount = usage.ChildCount
 		}
 
 		return nil
@@ -277,14 +402,23 @@ func (ms *MetadataService) GetNodeInfo(path string) (*pb.NodeInfo, error) {
 	return nodeInfo, err
 }
 
-// ListDirectory 列出目录内容
+// ListDirectory 列出目录的全部内容
 func (ms *MetadataService) ListDirectory(path string) ([]*pb.NodeInfo, error) {
+	nodes, _, err := ms.ListDirectoryPage(path, "", 0)
+	return nodes, err
+}
+
+// ListDirectoryPage 按名称顺序列出目录中 startAfter 之后的至多 limit 个子节点（limit 为 0 时不限制）
+// 还有剩余子节点时返回本页最后一个子节点的名称，作为下一页的 startAfter。
+// 目录的大小和子节点数直接读取其使用量记录，列出目录的开销只与返回的子节点数有关
+func (ms *MetadataService) ListDirectoryPage(path, startAfter string, limit int) ([]*pb.NodeInfo, string, error) {
 	path = filepath.Clean(path)
 	if path == "." {
 		path = "/"
 	}
 
 	var nodes []*pb.NodeInfo
+	var next string
 
 	err := ms.db.View(func(txn *badger.Txn) error {
 		// 获取目录的 Inode ID
@@ -293,15 +427,24 @@ func (ms *MetadataService) ListDirectory(path string) ([]*pb.NodeInfo, error) {
 			return err
 		}
 
-		// 遍历目录条目
+		// 遍历目录条目，条目键按子节点名称排序
 		prefix := fmt.Sprintf("%s%d/", model.PrefixDir, inodeID)
 		opts := badger.DefaultIteratorOptions
 		opts.PrefetchValues = false
 		it := txn.NewIterator(opts)
 		defer it.Close()
 
-		for it.Seek([]byte(prefix)); it.ValidForPrefix([]byte(prefix)); it.Next() {
+		var lastName string
+		for it.Seek([]byte(prefix + startAfter)); it.ValidForPrefix([]byte(prefix)); it.Next() {
 			item := it.Item()
+			name := string(item.Key()[len(prefix):])
+			if name == startAfter {
+				continue
+			}
+			if limit > 0 && len(nodes) == limit {
+				next = lastName
+				break
+			}
 
 			// 获取子节点的 Inode ID
 			var childInodeID uint64
@@ -314,42 +457,46 @@ func (ms *MetadataService) ListDirectory(path string) ([]*pb.NodeInfo, error) {
 			}
 
 			// 获取子节点的 NodeInfo
-			childInodeKey := fmt.Sprintf("%s%d", model.PrefixInode, childInodeID)
-			childItem, err := txn.Get([]byte(childInodeKey))
+			childNodeInfo, err := ms.getNodeInfoInTx(txn, childInodeID)
 			if err != nil {
 				continue
 			}
 
-			var childNodeInfo pb.NodeInfo
-			err = childItem.Value(func(val []byte) error {
-				return proto.Unmarshal(val, &childNodeInfo)
-			})
-			if err != nil {
-				continue
-			}
-
-			// 如果是目录，计算其总大小
+			// 目录的大小为子树中文件大小之和
 			if childNodeInfo.Type == pb.FileType_Directory {
-				totalSize, err := ms.calculateDirectorySizeInTx(txn, childInodeID)
+				usage, err := ms.getUsageInTx(txn, childInodeID)
 				if err != nil {
-					// 如果计算失败，记录错误但不中断操作，使用原始大小
-					fmt.Printf("Warning: failed to calculate directory size for inode %d: %v\n", childInodeID, err)
-				} else {
-					childNodeInfo.Size = totalSize
+					return err
 				}
+				childNodeInfo.Size = usage.Bytes
+				childNodeInfo.ChildCount = usage.ChildCount
 			}
 
-			nodes = append(nodes, &childNodeInfo)
+			nodes = append(nodes, childNodeInfo)
+			lastName = name
 		}
 
 		return nil
 	})
 
-	return nodes, err
+	return nodes, next, err
 }
 
-// DeleteNode 删除节点
+// DeleteNode 删除节点（通过日志提交），返回需要回收的块
 func (ms *MetadataService) DeleteNode(path string, recursive bool) ([]model.BlockWithLocations, error) {
+	result, err := ms.propose(pb.WALOperationType_DELETE_NODE, &pb.DeleteNodeOperation{
+		Path:      path,
+		Recursive: recursive,
+	})
+	if err != nil {
+		return nil, err
+	}
+	blocks, _ := result.([]model.BlockWithLocations)
+	return blocks, nil
+}
+
+// deleteNodeInDB 删除节点（仅数据库操作，不写WAL）
+func (ms *MetadataService) deleteNodeInDB(path string, recursive bool) ([]model.BlockWithLocations, error) {
 	path = filepath.Clean(path)
 	if path == "." {
 		path = "/"
@@ -361,7 +508,7 @@ func (ms *MetadataService) DeleteNode(path string, recursive bool) ([]model.Bloc
 
 	var blocksToDelete []model.BlockWithLocations
 
-	err := ms.db.Update(func(txn *badger.Txn) error {
+	err := ms.applyUpdate(func(txn *badger.Txn) error {
 		// 关键修复：每次事务重试时清空blocksToDelete，避免重复累积
 		blocksToDelete = blocksToDelete[:0]
 		// 获取节点信息
@@ -384,6 +531,15 @@ func (ms *MetadataService) DeleteNode(path string, recursive bool) ([]model.Bloc
 			return err
 		}
 
+		// 被删除子树的使用量从祖先目录中扣除
+		usage, err := ms.nodeUsageInTx(txn, &nodeInfo)
+		if err != nil {
+			return err
+		}
+		if err := ms.addUsageInTx(txn, path, negateUsage(usage)); err != nil {
+			return err
+		}
+
 		// 如果是目录，检查是否为空或需要递归删除
 		if nodeInfo.Type == pb.FileType_Directory {
 			children, err := ms.listDirectoryInTx(txn, inodeID)
@@ -496,6 +652,20 @@ func (ms *MetadataService) deleteNodeInTx(txn *badger.Txn, inodeID uint64, path
 		if err := txn.Delete([]byte(dirKey)); err != nil {
 			return err
 		}
+		if err := ms.addChildCountInTx(txn, parentInodeID, -1); err != nil {
+			return err
+		}
+	}
+
+	// 删除目录的使用量、配额和纠删码策略
+	if err := txn.Delete([]byte(fmt.Sprintf("%s%d", model.PrefixUsage, inodeID))); err != nil {
+		return err
+	}
+	if err := txn.Delete([]byte(fmt.Sprintf("%s%d", model.PrefixQuota, inodeID))); err != nil {
+		return err
+	}
+	if err := txn.Delete([]byte(fmt.Sprintf("%s%d", model.PrefixECPolicy, inodeID))); err != nil {
+		return err
 	}
 
 	// 删除所有相关的块映射
@@ -503,6 +673,262 @@ func (ms *MetadataService) deleteNodeInTx(txn *badger.Txn, inodeID uint64, path
 	return ms.deleteKeysWithPrefixInTx(txn, blockPrefix)
 }
 
+// RenameNode 重命名/移动节点（通过日志提交），返回被覆盖的目标文件需要回收的块
+func (ms *MetadataService) RenameNode(src, dst string, overwrite bool) ([]model.BlockWithLocations, error) {
+	if IsSnapshotPath(src) || IsSnapshotPath(dst) {
+		return nil, fmt.Errorf("snapshot path is read-only")
+	}
+	result, err := ms.propose(pb.WALOperationType_RENAME_NODE, &pb.RenameNodeOperation{
+		SrcPath:   filepath.Clean(src),
+		DstPath:   filepath.Clean(dst),
+		Overwrite: overwrite,
+	})
+	if err != nil {
+		return nil, err
+	}
+	blocks, _ := result.([]model.BlockWithLocations)
+	return blocks, nil
+}
+
+// renameNodeInDB 重命名/移动节点（仅数据库操作，不写WAL）
+// 路径映射、目录条目和子树中所有节点的路径在同一个事务内更新，数据块不动
+func (ms *MetadataService) renameNodeInDB(src, dst string, overwrite bool) ([]model.BlockWithLocations, error) {
+	src = filepath.Clean(src)
+	if src == "." {
+		src = "/"
+	}
+	dst = filepath.Clean(dst)
+	if dst == "." {
+		dst = "/"
+	}
+
+	if src == "/" || dst == "/" {
+		return nil, fmt.Errorf("cannot rename root directory")
+	}
+	if src == dst {
+		return nil, nil
+	}
+	if strings.HasPrefix(dst, src+"/") {
+		return nil, fmt.Errorf("cannot move %s into its own subdirectory %s", src, dst)
+	}
+
+	var blocksToDelete []model.BlockWithLocations
+
+	err := ms.applyUpdate(func(txn *badger.Txn) error {
+		// 事务重试时清空，避免重复累积
+		blocksToDelete = blocksToDelete[:0]
+
+		srcInodeID, err := ms.getInodeIDByPathInTx(txn, src)
+		if err == badger.ErrKeyNotFound {
+			return fmt.Errorf("source does not exist: %s", src)
+		}
+		if err != nil {
+			return err
+		}
+		srcInfo, err := ms.getNodeInfoInTx(txn, srcInodeID)
+		if err != nil {
+			return err
+		}
+
+		// 正在写入的文件不能移动或被覆盖，否则租约不再对应文件路径；移入回收站等同于删除，不受限制
+		if !IsInTrash(dst) {
+			for _, path := range []string{src, dst} {
+				leased, err := leasedPathInTx(txn, path)
+				if err != nil {
+					return err
+				}
+				if leased != "" {
+					return fmt.Errorf("file %s is being written", leased)
+				}
+			}
+		}
+
+		// 检查目标父目录
+		dstParent := filepath.Dir(dst)
+		dstParentInodeID, err := ms.getInodeIDByPathInTx(txn, dstParent)
+		if err == badger.ErrKeyNotFound {
+			return fmt.Errorf("parent directory does not exist: %s", dstParent)
+		}
+		if err != nil {
+			return err
+		}
+		dstParentInfo, err := ms.getNodeInfoInTx(txn, dstParentInodeID)
+		if err != nil {
+			return err
+		}
+		if dstParentInfo.Type != pb.FileType_Directory {
+			return fmt.Errorf("parent is not a directory: %s", dstParent)
+		}
+
+		// 处理目标已存在的情况
+		dstInodeID, err := ms.getInodeIDByPathInTx(txn, dst)
+		if err == nil {
+			if !overwrite {
+				return fmt.Errorf("destination already exists: %s", dst)
+			}
+			dstInfo, err := ms.getNodeInfoInTx(txn, dstInodeID)
+			if err != nil {
+				return err
+			}
+			if dstInfo.Type != srcInfo.Type {
+				return fmt.Errorf("cannot overwrite %s with a node of different type", dst)
+			}
+			if dstInfo.Type == pb.FileType_Directory {
+				children, err := ms.listDirectoryInTx(txn, dstInodeID)
+				if err != nil {
+					return err
+				}
+				if len(children) > 0 {
+					return fmt.Errorf("directory not empty: %s", dst)
+				}
+			} else {
+				blocks, err := ms.getFileBlocksWithLocationsInTx(txn, dstInodeID)
+				if err != nil {
+					return err
+				}
+				blocksToDelete = append(blocksToDelete, blocks...)
+			}
+			removed, err := ms.nodeUsageInTx(txn, dstInfo)
+			if err != nil {
+				return err
+			}
+			if err := ms.addUsageInTx(txn, dst, negateUsage(removed)); err != nil {
+				return err
+			}
+			if err := ms.deleteNodeInTx(txn, dstInodeID, dst); err != nil {
+				return err
+			}
+		} else if err != badger.ErrKeyNotFound {
+			return err
+		}
+
+		// 子树的使用量从源的祖先目录转到目标的祖先目录，两者共同的祖先不变，不需要检查配额
+		moved, err := ms.nodeUsageInTx(txn, srcInfo)
+		if err != nil {
+			return err
+		}
+		srcDirs, err := ms.ancestorDirsInTx(txn, src)
+		if err != nil {
+			return err
+		}
+		common := make(map[uint64]bool)
+		for _, dir := range srcDirs {
+			common[dir.inode] = true
+		}
+		if err := ms.checkQuotaInTx(txn, dst, moved, common); err != nil {
+			return err
+		}
+		if err := ms.addUsageInTx(txn, src, negateUsage(moved)); err != nil {
+			return err
+		}
+		if err := ms.addUsageInTx(txn, dst, moved); err != nil {
+			return err
+		}
+
+		// 移动父目录中的条目
+		srcParentInodeID, err := ms.getInodeIDByPathInTx(txn, filepath.Dir(src))
+		if err != nil {
+			return err
+		}
+		oldDirKey := fmt.Sprintf("%s%d/%s", model.PrefixDir, srcParentInodeID, filepath.Base(src))
+		if err := txn.Delete([]byte(oldDirKey)); err != nil {
+			return err
+		}
+		if err := ms.addChildCountInTx(txn, srcParentInodeID, -1); err != nil {
+			return err
+		}
+		inodeBuf := make([]byte, 8)
+		binary.BigEndian.PutUint64(inodeBuf, srcInodeID)
+		newDirKey := fmt.Sprintf("%s%d/%s", model.PrefixDir, dstParentInodeID, filepath.Base(dst))
+		if err := txn.Set([]byte(newDirKey), inodeBuf); err != nil {
+			return err
+		}
+		if err := ms.addChildCountInTx(txn, dstParentInodeID, 1); err != nil {
+			return err
+		}
+
+		// 重写子树中所有节点的路径映射和 NodeInfo.Path
+		return ms.rewriteSubtreePathsInTx(txn, src, dst)
+	})
+
+	return blocksToDelete, err
+}
+
+// rewriteSubtreePathsInTx 在事务中将 src 及其子树的路径前缀替换为 dst
+func (ms *MetadataService) rewriteSubtreePathsInTx(txn *badger.Txn, src, dst string) error {
+	type pathEntry struct {
+		path    string
+		inodeID uint64
+	}
+
+	// 先收集再修改，避免迭代过程中写入
+	var entries []pathEntry
+	opts := badger.DefaultIteratorOptions
+	it := txn.NewIterator(opts)
+	prefix := []byte(model.PrefixPath + src)
+	for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+		item := it.Item()
+		path := string(item.Key())[len(model.PrefixPath):]
+		// 跳过 /a/bc 这类仅字符串前缀相同的兄弟节点
+		if path != src && !strings.HasPrefix(path, src+"/") {
+			continue
+		}
+		err := item.Value(func(val []byte) error {
+			entries = append(entries, pathEntry{path: path, inodeID: binary.BigEndian.Uint64(val)})
+			return nil
+		})
+		if err != nil {
+			it.Close()
+			return err
+		}
+	}
+	it.Close()
+
+	for _, entry := range entries {
+		newPath := dst + entry.path[len(src):]
+
+		if err := txn.Delete([]byte(model.PrefixPath + entry.path)); err != nil {
+			return err
+		}
+		inodeBuf := make([]byte, 8)
+		binary.BigEndian.PutUint64(inodeBuf, entry.inodeID)
+		if err := txn.Set([]byte(model.PrefixPath+newPath), inodeBuf); err != nil {
+			return err
+		}
+
+		nodeInfo, err := ms.getNodeInfoInTx(txn, entry.inodeID)
+		if err != nil {
+			return err
+		}
+		nodeInfo.Path = newPath
+		data, err := proto.Marshal(nodeInfo)
+		if err != nil {
+			return err
+		}
+		inodeKey := fmt.Sprintf("%s%d", model.PrefixInode, entry.inodeID)
+		if err := txn.Set([]byte(inodeKey), data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getNodeInfoInTx 在事务中通过 Inode ID 获取 NodeInfo
+func (ms *MetadataService) getNodeInfoInTx(txn *badger.Txn, inodeID uint64) (*pb.NodeInfo, error) {
+	inodeKey := fmt.Sprintf("%s%d", model.PrefixInode, inodeID)
+	item, err := txn.Get([]byte(inodeKey))
+	if err != nil {
+		return nil, err
+	}
+
+	nodeInfo := &pb.NodeInfo{}
+	err = item.Value(func(val []byte) error {
+		return proto.Unmarshal(val, nodeInfo)
+	})
+	return nodeInfo, err
+}
+
 // listDirectoryInTx 在事务中列出目录内容
 func (ms *MetadataService) listDirectoryInTx(txn *badger.Txn, inodeID uint64) ([]*pb.NodeInfo, error) {
 	var nodes []*pb.NodeInfo
@@ -545,7 +971,7 @@ func (ms *MetadataService) listDirectoryInTx(txn *badger.Txn, inodeID uint64) ([
 	return nodes, nil
 }
 
-// getFileBlocksInTx 在事务中获取文件的所有块ID
+// getFileBlocksInTx 在事务中获取文件的所有块ID，纠删码块组按条带展开
 func (ms *MetadataService) getFileBlocksInTx(txn *badger.Txn, inodeID uint64) ([]uint64, error) {
 	var blocks []uint64
 
@@ -562,7 +988,9 @@ func (ms *MetadataService) getFileBlocksInTx(txn *badger.Txn, inodeID uint64) ([
 			if err := proto.Unmarshal(val, &blockLocs); err != nil {
 				return err
 			}
-			blocks = append(blocks, blockLocs.BlockId)
+			for _, block := range PhysicalBlocks(&blockLocs) {
+				blocks = append(blocks, block.BlockID)
+			}
 			return nil
 		})
 		if err != nil {
@@ -573,7 +1001,7 @@ func (ms *MetadataService) getFileBlocksInTx(txn *badger.Txn, inodeID uint64) ([
 	return blocks, nil
 }
 
-// getFileBlocksWithLocationsInTx 在事务中获取文件的所有块及其位置信息
+// getFileBlocksWithLocationsInTx 在事务中获取文件的所有块及其位置信息，纠删码块组按条带展开
 func (ms *MetadataService) getFileBlocksWithLocationsInTx(txn *badger.Txn, inodeID uint64) ([]model.BlockWithLocations, error) {
 	var blocks []model.BlockWithLocations
 
@@ -590,11 +1018,7 @@ func (ms *MetadataService) getFileBlocksWithLocationsInTx(txn *badger.Txn, inode
 			if err := proto.Unmarshal(val, &blockLocs); err != nil {
 				return err
 			}
-			block := model.BlockWithLocations{
-				BlockID:   blockLocs.BlockId,
-				Locations: blockLocs.Locations,
-			}
-			blocks = append(blocks, block)
+			blocks = append(blocks, PhysicalBlocks(&blockLocs)...)
 			return nil
 		})
 		if err != nil {
@@ -633,37 +1057,22 @@ func (ms *MetadataService) deleteKeysWithPrefixInTx(txn *badger.Txn, prefix stri
 	return nil
 }
 
-// SetBlockMapping 设置文件块映射（带WAL日志）
+// SetBlockMapping 设置文件块映射（通过日志提交）
 func (ms *MetadataService) SetBlockMapping(inodeID uint64, blockIndex uint64, blockLocs *pb.BlockLocations) error {
-	// 1. 先写WAL日志
-	if ms.walService != nil {
-		// 构建操作数据
-		operation := &pb.SetBlockMappingOperation{
-			InodeId:    inodeID,
-			BlockIndex: blockIndex,
-			BlockLocs:  blockLocs,
-		}
-
-		// 写入日志
-		entry, err := ms.walService.AppendLogEntry(pb.WALOperationType_SET_BLOCK_MAPPING, operation)
-		if err != nil {
-			return fmt.Errorf("failed to write WAL for SetBlockMapping: %v", err)
-		}
-
-		// 如果是Leader，需要将此日志同步给Followers
-		if entry != nil && ms.walService.IsLeader() {
-			// 异步同步，不阻塞主流程
-			go ms.walService.SyncToFollowers(entry)
-		}
+	_, err := ms.propose(pb.WALOperationType_SET_BLOCK_MAPPING, &pb.SetBlockMappingOperation{
+		InodeId:    inodeID,
+		BlockIndex: blockIndex,
+		BlockLocs:  blockLocs,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to commit SetBlockMapping: %v", err)
 	}
-
-	// 2. 再执行数据库更新
-	return ms.setBlockMappingInDB(inodeID, blockIndex, blockLocs)
+	return nil
 }
 
 // setBlockMappingInDB 设置文件块映射（仅数据库操作，不写WAL）
 func (ms *MetadataService) setBlockMappingInDB(inodeID uint64, blockIndex uint64, blockLocs *pb.BlockLocations) error {
-	return ms.db.Update(func(txn *badger.Txn) error {
+	return ms.applyUpdate(func(txn *badger.Txn) error {
 		data, err := proto.Marshal(blockLocs)
 		if err != nil {
 			return err
@@ -743,16 +1152,213 @@ func (ms *MetadataService) GetBlockMappings(inodeID uint64) ([]*pb.BlockLocation
 	return blockMappings, nil
 }
 
-// FinalizeWrite 完成文件写入，更新文件大小、修改时间和MD5哈希
-func (ms *MetadataService) FinalizeWrite(path string, inodeID uint64, size uint64, md5Hash string) error {
+// GetBlockMapping 获取文件指定索引的块映射
+func (ms *MetadataService) GetBlockMapping(inodeID uint64, blockIndex uint64) (*pb.BlockLocations, error) {
+	var blockLocs *pb.BlockLocations
+	err := ms.db.View(func(txn *badger.Txn) error {
+		var err error
+		blockLocs, err = ms.getBlockMappingInTx(txn, inodeID, blockIndex)
+		return err
+	})
+	return blockLocs, err
+}
+
+// TruncateBlockMappings 删除文件中索引不小于 blockCount 的块映射（通过日志提交）
+// 返回被删除的块，由调用方交给垃圾回收
+func (ms *MetadataService) TruncateBlockMappings(inodeID uint64, blockCount uint64) ([]model.BlockWithLocations, error) {
+	result, err := ms.propose(pb.WALOperationType_TRUNCATE_BLOCK_MAPPINGS, &pb.TruncateBlockMappingsOperation{
+		InodeId:    inodeID,
+		BlockCount: blockCount,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit TruncateBlockMappings: %v", err)
+	}
+	removed, _ := result.([]model.BlockWithLocations)
+	return removed, nil
+}
+
+// truncateBlockMappingsInDB 删除索引不小于 blockCount 的块映射（仅数据库操作，不写WAL）
+func (ms *MetadataService) truncateBlockMappingsInDB(inodeID uint64, blockCount uint64) ([]model.BlockWithLocations, error) {
+	var removed []model.BlockWithLocations
+
+	err := ms.applyUpdate(func(txn *badger.Txn) error {
+		prefix := fmt.Sprintf("%s%d/", model.PrefixBlock, inodeID)
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+
+		var keys [][]byte
+		for it.Seek([]byte(prefix)); it.ValidForPrefix([]byte(prefix)); it.Next() {
+			item := it.Item()
+			blockIndex, err := strconv.ParseUint(string(item.Key())[len(prefix):], 10, 64)
+			if err != nil || blockIndex < blockCount {
+				continue
+			}
+
+			var blockLocs pb.BlockLocations
+			if err := item.Value(func(val []byte) error {
+				return proto.Unmarshal(val, &blockLocs)
+			}); err != nil {
+				it.Close()
+				return err
+			}
+
+			keys = append(keys, item.KeyCopy(nil))
+			removed = append(removed, PhysicalBlocks(&blockLocs)...)
+		}
+		it.Close()
+
+		for _, key := range keys {
+			if err := txn.Delete(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
+
+// BlockSize 获取数据块大小，未配置时使用 4MB
+func (ms *MetadataService) BlockSize() uint64 {
+	if ms.config != nil && ms.config.Scheduler.BlockSize > 0 {
+		return ms.config.Scheduler.BlockSize
+	}
+	return 4 * 1024 * 1024
+}
+
+// LocateOffset 将文件内偏移映射为块索引和块内偏移
+// 除最后一个块外，文件的每个块大小都等于配置的 block_size
+func (ms *MetadataService) LocateOffset(offset uint64) (blockIndex uint64, blockOffset uint64) {
+	blockSize := ms.BlockSize()
+	return offset / blockSize, offset % blockSize
+}
+
+// getBlockMappingInTx 在事务中获取文件指定索引的块映射
+func (ms *MetadataService) getBlockMappingInTx(txn *badger.Txn, inodeID uint64, blockIndex uint64) (*pb.BlockLocations, error) {
+	return ms.getBlockMappingByKeyInTx(txn, fmt.Sprintf("%s%d/%d", model.PrefixBlock, inodeID, blockIndex))
+}
+
+// getBlockMappingByKeyInTx 在事务中读取指定键的块映射
+func (ms *MetadataService) getBlockMappingByKeyInTx(txn *badger.Txn, key string) (*pb.BlockLocations, error) {
+	item, err := txn.Get([]byte(key))
+	if err != nil {
+		return nil, err
+	}
+
+	blockLocs := &pb.BlockLocations{}
+	err = item.Value(func(val []byte) error {
+		return proto.Unmarshal(val, blockLocs)
+	})
+	return blockLocs, err
+}
+
+// GetBlockRange 将文件内 [offset, offset+length) 映射为各数据块内的读取范围
+// length 为 0 表示读到文件末尾，超出文件大小的部分会被截断
+func (ms *MetadataService) GetBlockRange(path string, offset, length uint64) (*pb.NodeInfo, []*pb.BlockRange, error) {
 	path = filepath.Clean(path)
 	if path == "." {
 		path = "/"
 	}
 
-	return ms.db.Update(func(txn *badger.Txn) error {
+	var nodeInfo *pb.NodeInfo
+	var ranges []*pb.BlockRange
+
+	err := ms.db.View(func(txn *badger.Txn) error {
+		// 快照中的文件从 snap/ 下读取节点和块映射
+		getBlock := ms.getBlockMappingInTx
+		if IsSnapshotPath(path) {
+			var err error
+			nodeInfo, err = ms.getSnapshotNodeInfoInTx(txn, path)
+			if err == badger.ErrKeyNotFound {
+				return fmt.Errorf("file not found: %s", path)
+			}
+			if err != nil {
+				return err
+			}
+			name, _ := splitSnapshotPath(path)
+			getBlock = func(txn *badger.Txn, inodeID uint64, blockIndex uint64) (*pb.BlockLocations, error) {
+				return ms.getBlockMappingByKeyInTx(txn, fmt.Sprintf("%s%d", snapshotBlockPrefix(name, inodeID), blockIndex))
+			}
+		} else {
+			inodeID, err := ms.getInodeIDByPathInTx(txn, path)
+			if err != nil {
+				if err == badger.ErrKeyNotFound {
+					return fmt.Errorf("file not found: %s", path)
+				}
+				return err
+			}
+
+			nodeInfo, err = ms.getNodeInfoInTx(txn, inodeID)
+			if err != nil {
+				return err
+			}
+		}
+		inodeID := nodeInfo.Inode
+		if nodeInfo.Type == pb.FileType_Directory {
+			return fmt.Errorf("cannot read range of directory: %s", path)
+		}
+
+		fileSize := uint64(nodeInfo.Size)
+		if offset >= fileSize {
+			return nil
+		}
+		end := fileSize
+		if length > 0 && offset+length < end {
+			end = offset + length
+		}
+
+		blockSize := ms.BlockSize()
+		for pos := offset; pos < end; {
+			blockIndex, blockOffset := ms.LocateOffset(pos)
+			n := blockSize - blockOffset
+			if pos+n > end {
+				n = end - pos
+			}
+
+			blockLocs, err := getBlock(txn, inodeID, blockIndex)
+			if err != nil {
+				if err == badger.ErrKeyNotFound {
+					return fmt.Errorf("block %d of %s not found", blockIndex, path)
+				}
+				return err
+			}
+
+			ranges = append(ranges, &pb.BlockRange{
+				BlockIndex:  blockIndex,
+				BlockOffset: blockOffset,
+				Length:      n,
+				Block:       blockLocs,
+			})
+			pos += n
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, nil, err
+	}
+	return nodeInfo, ranges, nil
+}
+
+// finalizeWriteInDB 完成文件写入，设置块映射并更新文件大小、修改时间和MD5哈希（仅数据库操作，不写WAL）
+// 指定了租约持有者时文件必须持有该写入方的租约，租约与文件状态在同一事务中释放
+func (ms *MetadataService) finalizeWriteInDB(op *pb.FinalizeWriteOperation) error {
+	path := filepath.Clean(op.Path)
+	if path == "." {
+		path = "/"
+	}
+
+	return ms.applyUpdate(func(txn *badger.Txn) error {
+		if op.LeaseHolder != "" {
+			if err := ms.releaseLeaseInTx(txn, path, op.LeaseHolder, op.Inode); err != nil {
+				return err
+			}
+		}
+
 		// 获取当前的 NodeInfo
-		inodeKey := fmt.Sprintf("%s%d", model.PrefixInode, inodeID)
+		inodeKey := fmt.Sprintf("%s%d", model.PrefixInode, op.Inode)
 		item, err := txn.Get([]byte(inodeKey))
 		if err != nil {
 			return err
@@ -766,10 +1372,29 @@ func (ms *MetadataService) FinalizeWrite(path string, inodeID uint64, size uint6
 			return err
 		}
 
+		for i, blockLocs := range op.BlockLocations {
+			data, err := proto.Marshal(blockLocs)
+			if err != nil {
+				return err
+			}
+			key := fmt.Sprintf("%s%d/%d", model.PrefixBlock, op.Inode, i)
+			if err := txn.Set([]byte(key), data); err != nil {
+				return err
+			}
+		}
+
+		// 按大小变化更新祖先目录的使用量，配额已在分配数据块时检查
+		oldUsage := fileUsage(nodeInfo.Size, nodeInfo.Replication, nodeInfo.EcPolicy)
+		newUsage := fileUsage(op.Size, nodeInfo.Replication, nodeInfo.EcPolicy)
+		delta := model.DirectoryUsage{Bytes: newUsage.Bytes - oldUsage.Bytes, Space: newUsage.Space - oldUsage.Space}
+		if err := ms.addUsageInTx(txn, nodeInfo.Path, delta); err != nil {
+			return err
+		}
+
 		// 更新文件信息
-		nodeInfo.Size = int64(size)
-		nodeInfo.Mtime = time.Now().UnixMilli()
-		nodeInfo.Md5 = md5Hash
+		nodeInfo.Size = op.Size
+		nodeInfo.Mtime = op.Mtime
+		nodeInfo.Md5 = op.Md5
 
 		// 重新序列化并保存
 		data, err := proto.Marshal(&nodeInfo)
@@ -781,6 +1406,36 @@ func (ms *MetadataService) FinalizeWrite(path string, inodeID uint64, size uint6
 	})
 }
 
+// CommitFileState 将文件的块映射、大小和MD5设置为指定状态（通过日志提交）
+func (ms *MetadataService) CommitFileState(path string, inodeID uint64, size int64, md5Hash string, blocks []*pb.BlockLocations) error {
+	return ms.CommitLeasedFileState(path, inodeID, size, md5Hash, blocks, "")
+}
+
+// CommitLeasedFileState 与 CommitFileState 相同，holder 非空时要求文件持有该写入方的租约并同时释放租约
+// 用于完成写入，以及写租约恢复时完成中断的写入或回滚到写入前的状态
+func (ms *MetadataService) CommitLeasedFileState(path string, inodeID uint64, size int64, md5Hash string, blocks []*pb.BlockLocations, holder string) error {
+	_, err := ms.propose(pb.WALOperationType_FINALIZE_WRITE, &pb.FinalizeWriteOperation{
+		Path:           path,
+		Inode:          inodeID,
+		Size:           size,
+		Md5:            md5Hash,
+		BlockLocations: blocks,
+		LeaseHolder:    holder,
+		Mtime:          time.Now().UnixMilli(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to commit file state of %s: %v", path, err)
+	}
+
+	// 删除超出新块数的残留映射
+	if _, err := ms.GetBlockMapping(inodeID, uint64(len(blocks))); err == nil {
+		if _, err := ms.TruncateBlockMappings(inodeID, uint64(len(blocks))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // AddGCEntry 添加垃圾回收条目
 func (ms *MetadataService) AddGCEntry(blockID uint64, locations []string) error {
 	return ms.db.Update(func(txn *badger.Txn) error {
@@ -907,34 +1562,22 @@ func (ms *MetadataService) TraverseAllFiles(callback func(*pb.NodeInfo) error) e
 	})
 }
 
-// UpdateBlockLocation 更新块位置信息，将旧地址替换为新地址
+// UpdateBlockLocation 更新块位置信息，将旧地址替换为新地址（通过日志提交）
 func (ms *MetadataService) UpdateBlockLocation(blockID uint64, oldAddr, newAddr string) error {
-	// 1. 先写WAL日志（如果WAL服务可用）
-	if ms.walService != nil {
-		operation := &pb.UpdateBlockLocationOperation{
-			BlockId: blockID,
-			OldAddr: oldAddr,
-			NewAddr: newAddr,
-		}
-		entry, err := ms.walService.AppendLogEntry(pb.WALOperationType_UPDATE_BLOCK_LOCATION, operation)
-		if err != nil {
-			return fmt.Errorf("failed to write WAL entry for block location update: %v", err)
-		}
-
-		// 如果是Leader，需要将此日志异步同步给Followers
-		if entry != nil && ms.walService.IsLeader() {
-			// 异步同步，不阻塞主流程
-			go ms.walService.SyncToFollowers(entry)
-		}
+	_, err := ms.propose(pb.WALOperationType_UPDATE_BLOCK_LOCATION, &pb.UpdateBlockLocationOperation{
+		BlockId: blockID,
+		OldAddr: oldAddr,
+		NewAddr: newAddr,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to commit block location update: %v", err)
 	}
-
-	// 2. 执行实际的数据库更新
-	return ms.updateBlockLocationInDB(blockID, oldAddr, newAddr)
+	return nil
 }
 
 // updateBlockLocationInDB 在数据库中更新块位置信息（不写WAL）
 func (ms *MetadataService) updateBlockLocationInDB(blockID uint64, oldAddr, newAddr string) error {
-	return ms.db.Update(func(txn *badger.Txn) error {
+	return ms.applyUpdate(func(txn *badger.Txn) error {
 		// 需要遍历所有包含该块的文件，找到对应的块映射并更新
 		inodePrefix := []byte(model.PrefixInode)
 		opts := badger.DefaultIteratorOptions
@@ -964,7 +1607,8 @@ func (ms *MetadataService) updateBlockLocationInDB(blockID uint64, oldAddr, newA
 			}
 		}
 
-		return nil
+		// 快照中的块映射同步更新
+		return ms.updateSnapshotBlockLocationInTx(txn, blockID, oldAddr, newAddr)
 	})
 }
 
@@ -988,23 +1632,9 @@ func (ms *MetadataService) updateFileBlockLocation(txn *badger.Txn, inodeID, blo
 			continue
 		}
 
-		// 检查这个块是否是我们要更新的
-		if blockLocs.BlockId != blockID {
-			continue
-		}
-
-		// 更新位置信息
-		updated := false
-		for i, location := range blockLocs.Locations {
-			if location == oldAddr {
-				blockLocs.Locations[i] = newAddr
-				updated = true
-				break
-			}
-		}
-
+		// 更新位置信息，blockID 可以是多副本块或纠删码条带
 		// 如果有更新，保存回数据库
-		if updated {
+		if replaceBlockLocation(&blockLocs, blockID, oldAddr, newAddr) {
 			data, err := proto.Marshal(&blockLocs)
 			if err != nil {
 				return fmt.Errorf("failed to marshal updated block locations: %v", err)
@@ -1022,61 +1652,28 @@ func (ms *MetadataService) updateFileBlockLocation(txn *badger.Txn, inodeID, blo
 	return nil
 }
 
-// calculateDirectorySizeInTx 在事务中递归计算目录的总大小
-func (ms *MetadataService) calculateDirectorySizeInTx(txn *badger.Txn, dirInodeID uint64) (int64, error) {
-	var totalSize int64 = 0
-
-	// 遍历目录中的所有子项
-	prefix := fmt.Sprintf("%s%d/", model.PrefixDir, dirInodeID)
-	opts := badger.DefaultIteratorOptions
-	opts.PrefetchValues = false
-	it := txn.NewIterator(opts)
-	defer it.Close()
-
-	for it.Seek([]byte(prefix)); it.ValidForPrefix([]byte(prefix)); it.Next() {
-		item := it.Item()
-
-		// 获取子项的 Inode ID
-		var childInodeID uint64
-		err := item.Value(func(val []byte) error {
-			childInodeID = binary.BigEndian.Uint64(val)
-			return nil
-		})
-		if err != nil {
-			continue
-		}
-
-		// 获取子项的 NodeInfo
-		childInodeKey := fmt.Sprintf("%s%d", model.PrefixInode, childInodeID)
-		childItem, err := txn.Get([]byte(childInodeKey))
-		if err != nil {
-			continue
+// replaceLocation 将 oldAddr 替换为 newAddr；oldAddr 为空时添加 newAddr，newAddr 为空时删除 oldAddr
+func replaceLocation(locations []string, oldAddr, newAddr string) ([]string, bool) {
+	if oldAddr == "" {
+		for _, location := range locations {
+			if location == newAddr {
+				return locations, false
+			}
 		}
+		return append(locations, newAddr), true
+	}
 
-		var childNodeInfo pb.NodeInfo
-		err = childItem.Value(func(val []byte) error {
-			return proto.Unmarshal(val, &childNodeInfo)
-		})
-		if err != nil {
+	for i, location := range locations {
+		if location != oldAddr {
 			continue
 		}
-
-		if childNodeInfo.Type == pb.FileType_File {
-			// 如果是文件，直接累加文件大小
-			totalSize += childNodeInfo.Size
-		} else if childNodeInfo.Type == pb.FileType_Directory {
-			// 如果是目录，递归计算子目录大小
-			subDirSize, err := ms.calculateDirectorySizeInTx(txn, childInodeID)
-			if err != nil {
-				// 递归计算失败，记录警告但继续处理其他子项
-				fmt.Printf("Warning: failed to calculate subdirectory size for inode %d: %v\n", childInodeID, err)
-				continue
-			}
-			totalSize += subDirSize
+		if newAddr == "" {
+			return append(locations[:i], locations[i+1:]...), true
 		}
+		locations[i] = newAddr
+		return locations, true
 	}
-
-	return totalSize, nil
+	return locations, false
 }
 
 // updateInodeCounterIfNeededInTx 如果指定的 Inode ID 比当前计数器大，则更新计数器
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"metaServer/pb"
+)
+
+// createFileWithBlocks 创建文件并提交大小和块映射，返回其 inode
+func createFileWithBlocks(t *testing.T, ms *MetadataService, path string, size int64, blocks []*pb.BlockLocations) uint64 {
+	t.Helper()
+	if err := ms.CreateNode(path, pb.FileType_File); err != nil {
+		t.Fatalf("create %s: %v", path, err)
+	}
+	info, err := ms.GetNodeInfo(path)
+	if err != nil {
+		t.Fatalf("stat %s: %v", path, err)
+	}
+	if err := ms.CommitFileState(path, info.Inode, size, "", blocks); err != nil {
+		t.Fatalf("finalize %s: %v", path, err)
+	}
+	return info.Inode
+}
+
+func TestRenameOverwrite(t *testing.T) {
+	_, servers := newTestCluster(t)
+	leader := waitForLeader(t, servers)
+	ms := leader.metadata
+
+	srcInode := createFileWithBlocks(t, ms, "/src", 10, []*pb.BlockLocations{{BlockId: 1, Locations: []string{"ds1:8001"}}})
+	createFileWithBlocks(t, ms, "/dst", 10, []*pb.BlockLocations{{BlockId: 2, Locations: []string{"ds2:8001"}}})
+
+	if _, err := ms.RenameNode("/src", "/dst", false); err == nil {
+		t.Fatal("rename over an existing file without overwrite")
+	}
+
+	// 覆盖时目标文件被替换，返回目标文件的块等待回收
+	released, err := ms.RenameNode("/src", "/dst", true)
+	if err != nil {
+		t.Fatalf("rename with overwrite: %v", err)
+	}
+	if len(released) != 1 || released[0].BlockID != 2 || len(released[0].Locations) != 1 || released[0].Locations[0] != "ds2:8001" {
+		t.Errorf("released blocks: %+v", released)
+	}
+	if _, err := ms.GetNodeInfo("/src"); err == nil {
+		t.Error("source still exists after rename")
+	}
+	info, err := ms.GetNodeInfo("/dst")
+	if err != nil || info.Inode != srcInode {
+		t.Fatalf("destination after rename: %v, %v", info, err)
+	}
+	blocks, err := ms.GetBlockMappings(srcInode)
+	if err != nil || len(blocks) != 1 || blocks[0].BlockId != 1 {
+		t.Errorf("block mappings after rename: %v, %v", blocks, err)
+	}
+
+	// 源不存在时即使目标存在也返回错误
+	for _, overwrite := range []bool{false, true} {
+		if _, err := ms.RenameNode("/src", "/dst", overwrite); err == nil {
+			t.Errorf("rename of a missing source onto an existing destination (overwrite=%v) succeeded", overwrite)
+		}
+	}
+
+	// 目录只能覆盖空目录，文件和目录不能互相覆盖
+	for _, path := range []string{"/a", "/a/sub", "/empty", "/full"} {
+		if err := ms.CreateNode(path, pb.FileType_Directory); err != nil {
+			t.Fatalf("create %s: %v", path, err)
+		}
+	}
+	createFileWithBlocks(t, ms, "/a/sub/f", 10, nil)
+	createFileWithBlocks(t, ms, "/full/f", 10, nil)
+
+	if _, err := ms.RenameNode("/a", "/full", true); err == nil {
+		t.Error("overwrote a non-empty directory")
+	}
+	if _, err := ms.RenameNode("/dst", "/empty", true); err == nil {
+		t.Error("overwrote a directory with a file")
+	}
+	if _, err := ms.RenameNode("/a", "/empty", true); err != nil {
+		t.Fatalf("overwrite empty directory: %v", err)
+	}
+	if _, err := ms.GetNodeInfo("/empty/sub/f"); err != nil {
+		t.Errorf("subtree not moved: %v", err)
+	}
+	if _, err := ms.GetNodeInfo("/a/sub/f"); err == nil {
+		t.Error("old subtree path still resolves")
+	}
+
+	// Follower 回放同样的结果
+	for _, server := range servers {
+		if server == leader {
+			continue
+		}
+		waitForNode(t, server, "/empty/sub/f")
+		if info, err := server.metadata.GetNodeInfo("/dst"); err != nil || info.Inode != srcInode {
+			t.Errorf("%s: destination after rename: %v, %v", server.id, info, err)
+		}
+		if _, err := server.metadata.GetNodeInfo("/src"); err == nil {
+			t.Errorf("%s: source still exists after rename", server.id)
+		}
+	}
+}
+
+func TestGetBlockRange(t *testing.T) {
+	_, servers := newTestCluster(t)
+	leader := waitForLeader(t, servers)
+	ms := leader.metadata
+	ms.config.Scheduler.BlockSize = 100
+
+	if err := ms.CreateNode("/r", pb.FileType_Directory); err != nil {
+		t.Fatalf("create /r: %v", err)
+	}
+	var blocks []*pb.BlockLocations
+	for i := uint64(1); i <= 3; i++ {
+		blocks = append(blocks, &pb.BlockLocations{BlockId: i, Locations: []string{"ds1:8001"}})
+	}
+	createFileWithBlocks(t, ms, "/r/f", 250, blocks)
+	if err := ms.CreateSnapshot("/r", "s"); err != nil {
+		t.Fatalf("create snapshot: %v", err)
+	}
+
+	// want 中每项为 {块索引, 块内偏移, 长度}，块 ID 为索引加一
+	for _, c := range []struct {
+		path           string
+		offset, length uint64
+		want           [][3]uint64
+	}{
+		{"/r/f", 0, 10, [][3]uint64{{0, 0, 10}}},
+		{"/r/f", 130, 20, [][3]uint64{{1, 30, 20}}},
+		{"/r/f", 90, 120, [][3]uint64{{0, 90, 10}, {1, 0, 100}, {2, 0, 10}}},
+		{"/r/f", 120, 0, [][3]uint64{{1, 20, 80}, {2, 0, 50}}},
+		{"/r/f", 240, 100, [][3]uint64{{2, 40, 10}}},
+		{"/r/f", 250, 10, nil},
+		{"/r/f", 300, 0, nil},
+		{"/.snapshot/s/f", 90, 20, [][3]uint64{{0, 90, 10}, {1, 0, 10}}},
+	} {
+		_, ranges, err := ms.GetBlockRange(c.path, c.offset, c.length)
+		if err != nil {
+			t.Errorf("%s [%d, +%d): %v", c.path, c.offset, c.length, err)
+			continue
+		}
+		var got [][3]uint64
+		for _, r := range ranges {
+			if r.Block.BlockId != r.BlockIndex+1 {
+				t.Errorf("%s [%d, +%d): block %d at index %d", c.path, c.offset, c.length, r.Block.BlockId, r.BlockIndex)
+			}
+			got = append(got, [3]uint64{r.BlockIndex, r.BlockOffset, r.Length})
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s [%d, +%d): ranges %v, want %v", c.path, c.offset, c.length, got, c.want)
+		}
+	}
+
+	for _, path := range []string{"/r", "/.snapshot/s", "/r/missing"} {
+		if _, _, err := ms.GetBlockRange(path, 0, 0); err == nil {
+			t.Errorf("%s: range read accepted", path)
+		}
+	}
+}
+
+func TestListDirectoryPage(t *testing.T) {
+	_, servers := newTestCluster(t)
+	leader := waitForLeader(t, servers)
+
+	for _, dir := range []string{"/list", "/list/d", "/other"} {
+		if err := leader.metadata.CreateNode(dir, pb.FileType_Directory); err != nil {
+			t.Fatalf("create %s: %v", dir, err)
+		}
+	}
+	for _, name := range []string{"a", "b", "c", "e"} {
+		writeTestFile(t, leader, "/list/"+name, 10)
+	}
+	writeTestFile(t, leader, "/list/d/x", 5)
+
+	// 分页遍历，每页 2 个
+	var names []string
+	token := ""
+	for pages := 0; ; pages++ {
+		if pages > 5 {
+			t.Fatalf("pagination did not terminate")
+		}
+		nodes, next, err := leader.metadata.ListDirectoryPage("/list", token, 2)
+		if err != nil {
+			t.Fatalf("list page: %v", err)
+		}
+		for _, node := range nodes {
+			names = append(names, node.Path)
+			if node.Path == "/list/d" && (node.Size != 5 || node.ChildCount != 1) {
+				t.Errorf("/list/d: size=%d children=%d", node.Size, node.ChildCount)
+			}
+		}
+		if next == "" {
+			break
+		}
+		token = next
+	}
+	want := []string{"/list/a", "/list/b", "/list/c", "/list/d", "/list/e"}
+	if len(names) != len(want) {
+		t.Fatalf("listed %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("listed %v, want %v", names, want)
+		}
+	}
+
+	// 子节点数随删除和重命名调整
+	if _, err := leader.metadata.DeleteNode("/list/a", false); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := leader.metadata.RenameNode("/list/b", "/other/b", false); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	for path, count := range map[string]int64{"/list": 3, "/other": 1, "/": 2} {
+		info, err := leader.metadata.GetNodeInfo(path)
+		if err != nil || info.ChildCount != count {
+			t.Errorf("%s: children=%v, want %d (%v)", path, info.GetChildCount(), count, err)
+		}
+	}
+}
+
+func TestTruncateBlockMappings(t *testing.T) {
+	_, servers := newTestCluster(t)
+	leader := waitForLeader(t, servers)
+	ms := leader.metadata
+
+	var blocks []*pb.BlockLocations
+	for i := uint64(1); i <= 12; i++ {
+		blocks = append(blocks, &pb.BlockLocations{BlockId: i, Locations: []string{"ds1:8001"}})
+	}
+	inode := createFileWithBlocks(t, ms, "/f", 0, blocks)
+
+	// 索引按数值比较，"10" 和 "11" 不会因为字符串顺序被保留
+	removed, err := ms.TruncateBlockMappings(inode, 2)
+	if err != nil {
+		t.Fatalf("truncate: %v", err)
+	}
+	var removedIDs []uint64
+	for _, block := range removed {
+		removedIDs = append(removedIDs, block.BlockID)
+	}
+	sort.Slice(removedIDs, func(i, j int) bool { return removedIDs[i] < removedIDs[j] })
+	if want := []uint64{3, 4, 5, 6, 7, 8, 9, 10, 11, 12}; !reflect.DeepEqual(removedIDs, want) {
+		t.Errorf("removed blocks %v, want %v", removedIDs, want)
+	}
+
+	remaining, err := ms.GetBlockMappings(inode)
+	if err != nil || len(remaining) != 2 || remaining[0].BlockId != 1 || remaining[1].BlockId != 2 {
+		t.Errorf("remaining mappings: %v, %v", remaining, err)
+	}
+	if _, err := ms.GetBlockMapping(inode, 2); err == nil {
+		t.Error("truncated mapping still readable")
+	}
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// Metrics MetaServer 的 Prometheus 指标，通过 HTTP /metrics 暴露
+// FSCK 和 GC 只在 leader 上运行，相关指标以 leader 上的值为准
+type Metrics struct {
+	registry *prometheus.Registry
+
+	rpcDuration           *prometheus.HistogramVec
+	fsckDuration          prometheus.Histogram
+	fsckBlocks            prometheus.Gauge
+	underReplicatedBlocks prometheus.Gauge
+	missingBlocks         prometheus.Gauge
+	orphanBlocks          prometheus.Gauge
+	gcQueueDepth          prometheus.Gauge
+	gcDeletedBlocks       prometheus.Counter
+}
+
+// NewMetrics 创建指标并注册 Go 运行时和进程指标
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		rpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "minfs",
+			Subsystem: "metaserver",
+			Name:      "rpc_duration_seconds",
+			Help:      "gRPC request latency by method and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "code"}),
+		fsckDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "minfs",
+			Subsystem: "fsck",
+			Name:      "duration_seconds",
+			Help:      "Duration of an FSCK pass.",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 4, 10),
+		}),
+		fsckBlocks: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "minfs",
+			Subsystem: "fsck",
+			Name:      "blocks",
+			Help:      "Number of blocks checked by the last FSCK pass.",
+		}),
+		underReplicatedBlocks: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "minfs",
+			Name:      "under_replicated_blocks",
+			Help:      "Under-replicated blocks (including missing erasure-coded stripes) found by the last FSCK pass.",
+		}),
+		missingBlocks: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "minfs",
+			Name:      "missing_blocks",
+			Help:      "Blocks without any live replica found by the last FSCK pass.",
+		}),
+		orphanBlocks: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "minfs",
+			Name:      "orphan_blocks",
+			Help:      "Orphan blocks found by the last FSCK pass.",
+		}),
+		gcQueueDepth: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "minfs",
+			Subsystem: "gc",
+			Name:      "queue_depth",
+			Help:      "Blocks waiting in the GC queue.",
+		}),
+		gcDeletedBlocks: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "minfs",
+			Subsystem: "gc",
+			Name:      "deleted_blocks_total",
+			Help:      "Blocks whose deletion was confirmed by GC.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.rpcDuration, m.fsckDuration, m.fsckBlocks,
+		m.underReplicatedBlocks, m.missingBlocks, m.orphanBlocks,
+		m.gcQueueDepth, m.gcDeletedBlocks,
+	)
+	return m
+}
+
+// SetMetrics 设置调度器上报的指标
+func (ss *SchedulerService) SetMetrics(metrics *Metrics) {
+	ss.metrics = metrics
+}
+
+// RegisterScheduler 注册修复队列深度，抓取时读取
+func (m *Metrics) RegisterScheduler(ss *SchedulerService) {
+	m.registry.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: "minfs",
+			Subsystem: "repair",
+			Name:      "queue_depth",
+			Help:      "Repair tasks waiting to be dispatched.",
+		}, func() float64 {
+			return float64(len(ss.repairTaskQueue))
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: "minfs",
+			Subsystem: "repair",
+			Name:      "in_progress_blocks",
+			Help:      "Blocks with replication commands in flight.",
+		}, func() float64 {
+			ss.repairMutex.RLock()
+			defer ss.repairMutex.RUnlock()
+			return float64(len(ss.repairingBlocks))
+		}),
+	)
+}
+
+// RegisterRaft 注册 Raft 日志进度，抓取时读取
+func (m *Metrics) RegisterRaft(rn *RaftNode) {
+	m.registry.MustRegister(&raftCollector{node: rn})
+}
+
+var (
+	raftTermDesc        = prometheus.NewDesc("minfs_raft_term", "Current raft term.", nil, nil)
+	raftLeaderDesc      = prometheus.NewDesc("minfs_raft_is_leader", "Whether this node is the raft leader.", nil, nil)
+	walLastIndexDesc    = prometheus.NewDesc("minfs_wal_last_index", "Index of the last local WAL entry.", nil, nil)
+	walCommitIndexDesc  = prometheus.NewDesc("minfs_wal_commit_index", "Committed WAL index.", nil, nil)
+	walAppliedIndexDesc = prometheus.NewDesc("minfs_wal_applied_index", "WAL index applied to metadata.", nil, nil)
+	followerLagDesc     = prometheus.NewDesc("minfs_raft_follower_lag", "WAL entries each follower is behind the leader.", []string{"follower"}, nil)
+)
+
+// raftCollector 抓取时读取 Raft 节点的日志进度
+type raftCollector struct {
+	node *RaftNode
+}
+
+// Describe 实现 prometheus.Collector
+func (c *raftCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- raftTermDesc
+	ch <- raftLeaderDesc
+	ch <- walLastIndexDesc
+	ch <- walCommitIndexDesc
+	ch <- walAppliedIndexDesc
+	ch <- followerLagDesc
+}
+
+// Collect 实现 prometheus.Collector
+func (c *raftCollector) Collect(ch chan<- prometheus.Metric) {
+	progress := c.node.Progress()
+
+	leader := 0.0
+	if progress.State == RaftLeader {
+		leader = 1
+	}
+	ch <- prometheus.MustNewConstMetric(raftTermDesc, prometheus.GaugeValue, float64(progress.Term))
+	ch <- prometheus.MustNewConstMetric(raftLeaderDesc, prometheus.GaugeValue, leader)
+	ch <- prometheus.MustNewConstMetric(walLastIndexDesc, prometheus.GaugeValue, float64(progress.LastIndex))
+	ch <- prometheus.MustNewConstMetric(walCommitIndexDesc, prometheus.GaugeValue, float64(progress.CommitIndex))
+	ch <- prometheus.MustNewConstMetric(walAppliedIndexDesc, prometheus.GaugeValue, float64(progress.AppliedIndex))
+	for id, match := range progress.MatchIndex {
+		lag := 0.0
+		if progress.LastIndex > match {
+			lag = float64(progress.LastIndex - match)
+		}
+		ch <- prometheus.MustNewConstMetric(followerLagDesc, prometheus.GaugeValue, lag, id)
+	}
+}
+
+// UnaryServerInterceptor 记录一元 RPC 的耗时
+func (m *Metrics) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		m.observeRPC(info.FullMethod, err, time.Since(start))
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor 记录流式 RPC 从开始到结束的耗时
+func (m *Metrics) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, stream)
+		m.observeRPC(info.FullMethod, err, time.Since(start))
+		return err
+	}
+}
+
+// observeRPC 记录 RPC 耗时，方法名去掉服务前缀
+func (m *Metrics) observeRPC(fullMethod string, err error, duration time.Duration) {
+	method := fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+	m.rpcDuration.WithLabelValues(method, status.Code(err).String()).Observe(duration.Seconds())
+}
+
+// observeFSCK 记录一轮 FSCK 的耗时和块的副本状态
+func (m *Metrics) observeFSCK(duration time.Duration, blocks, underReplicated, missing int) {
+	if m == nil {
+		return
+	}
+	m.fsckDuration.Observe(duration.Seconds())
+	m.fsckBlocks.Set(float64(blocks))
+	m.underReplicatedBlocks.Set(float64(underReplicated))
+	m.missingBlocks.Set(float64(missing))
+}
+
+// setOrphanBlocks 记录孤儿块数，leader 未就绪跳过孤儿检查时不更新
+func (m *Metrics) setOrphanBlocks(orphans int) {
+	if m == nil {
+		return
+	}
+	m.orphanBlocks.Set(float64(orphans))
+}
+
+// observeGC 记录一轮 GC 开始时的队列深度和确认删除的块数
+func (m *Metrics) observeGC(queueDepth, deleted int) {
+	if m == nil {
+		return
+	}
+	m.gcQueueDepth.Set(float64(queueDepth))
+	m.gcDeletedBlocks.Add(float64(deleted))
+}
+
+// Serve 在 port 上启动 HTTP 服务暴露 /metrics，port 不大于 0 时不启动
+func (m *Metrics) Serve(port int) *http.Server {
+	if port <= 0 {
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+	server := &http.Server{Addr: fmt.Sprintf(":%d", port), Handler: mux}
+
+	go func() {
+		log.Printf("Metrics listening on :%d/metrics", port)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("Metrics server error: %v", err)
+		}
+	}()
+	return server
+}
+
+// replicationHealth 统计副本不足和没有任何可用副本的块数
+func replicationHealth(expectedBlocks, actualBlocks map[uint64][]string, blockReplication map[uint64]int) (int, int) {
+	underReplicated, missing := 0, 0
+	for blockID, expected := range expectedBlocks {
+		_, under, lost := blockReplicaState(expected, actualBlocks[blockID], blockReplication[blockID])
+		if lost {
+			missing++
+		}
+		if under {
+			underReplicated++
+		}
+	}
+	return underReplicated, missing
+}
@@ -0,0 +1,25 @@
+package service
+
+import "testing"
+
+func TestReplicationHealth(t *testing.T) {
+	expected := map[uint64][]string{
+		1: {"a", "b", "c"}, // 完整
+		2: {"a", "b", "c"}, // 缺一个副本
+		3: {"a", "b"},      // 缺失
+		4: {"a"},           // 位置少于目标副本数
+		5: {"d"},           // 纠删码条带
+	}
+	actual := map[uint64][]string{
+		1: {"a", "b", "c"},
+		2: {"a", "c", "x"},
+		4: {"a"},
+		5: {"d"},
+	}
+	replication := map[uint64]int{1: 3, 2: 3, 3: 2, 4: 2}
+
+	under, missing := replicationHealth(expected, actual, replication)
+	if under != 3 || missing != 1 {
+		t.Errorf("under-replicated=%d missing=%d, want 3 and 1", under, missing)
+	}
+}
@@ -0,0 +1,373 @@
+package service
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"metaServer/internal/model"
+	"metaServer/pb"
+
+	"github.com/dgraph-io/badger/v3"
+	"google.golang.org/protobuf/proto"
+)
+
+// 新建节点未指定权限位时使用的默认值
+const (
+	defaultDirMode  uint32 = 0755
+	defaultFileMode uint32 = 0644
+)
+
+// PermissionsEnabled 是否检查权限
+func (ms *MetadataService) PermissionsEnabled() bool {
+	return ms.config != nil && ms.config.Security.PermissionsEnabled
+}
+
+// superuser 超级用户名，未配置时为 root
+func (ms *MetadataService) superuser() string {
+	if ms.config != nil && ms.config.Security.Superuser != "" {
+		return ms.config.Security.Superuser
+	}
+	return "root"
+}
+
+// supergroup 超级用户组，未配置时为 supergroup
+func (ms *MetadataService) supergroup() string {
+	if ms.config != nil && ms.config.Security.Supergroup != "" {
+		return ms.config.Security.Supergroup
+	}
+	return "supergroup"
+}
+
+// normalizeCaller 未携带用户名的调用者使用 security.default_user，并加入 security.user_groups 中为该用户配置的组
+func (ms *MetadataService) normalizeCaller(caller model.Caller) model.Caller {
+	if caller.User == "" {
+		caller.User = "anonymous"
+		if ms.config != nil && ms.config.Security.DefaultUser != "" {
+			caller.User = ms.config.Security.DefaultUser
+		}
+	}
+	if ms.config != nil {
+		if groups := ms.config.Security.UserGroups[caller.User]; len(groups) > 0 {
+			caller.Groups = append(append([]string(nil), caller.Groups...), groups...)
+		}
+	}
+	return caller
+}
+
+// IsSuperuser 调用者是否为超级用户或属于超级用户组
+func (ms *MetadataService) IsSuperuser(caller model.Caller) bool {
+	caller = ms.normalizeCaller(caller)
+	return caller.User == ms.superuser() || inGroups(caller, ms.supergroup())
+}
+
+// CheckSuperuserPermission 管理操作只有超级用户可以执行，未开启权限检查时不限制
+func (ms *MetadataService) CheckSuperuserPermission(caller model.Caller, action string) error {
+	if ms.PermissionsEnabled() && !ms.IsSuperuser(caller) {
+		return fmt.Errorf("permission denied: only superuser can %s", action)
+	}
+	return nil
+}
+
+// CheckFsckPermission 只有超级用户可以执行 FSCK
+func (ms *MetadataService) CheckFsckPermission(caller model.Caller) error {
+	return ms.CheckSuperuserPermission(caller, "run fsck")
+}
+
+// inGroups 调用者是否属于指定的组
+func inGroups(caller model.Caller, group string) bool {
+	for _, g := range caller.Groups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
+
+// permitted 调用者对节点是否具有 access 要求的全部权限
+// 依次匹配属主、ACL 用户条目、属组和 ACL 组条目（所有匹配的组权限取并集）、其他用户；
+// 没有属主的节点由旧版本创建，不做限制
+func permitted(caller model.Caller, node *pb.NodeInfo, access uint32) bool {
+	if node.Owner == "" || access == 0 {
+		return true
+	}
+
+	var perm uint32
+	switch {
+	case caller.User == node.Owner:
+		perm = node.Mode >> 6 & 7
+	default:
+		matched := false
+		for _, entry := range node.Acl {
+			if entry.Type == model.AclUser && entry.Name == caller.User {
+				perm, matched = entry.Perm&7, true
+				break
+			}
+		}
+		if matched {
+			break
+		}
+		if inGroups(caller, node.Group) {
+			perm |= node.Mode >> 3 & 7
+			matched = true
+		}
+		for _, entry := range node.Acl {
+			if entry.Type == model.AclGroup && inGroups(caller, entry.Name) {
+				perm |= entry.Perm & 7
+				matched = true
+			}
+		}
+		if !matched {
+			perm = node.Mode & 7
+		}
+	}
+	return perm&access == access
+}
+
+// accessString 权限位的 rwx 表示
+func accessString(access uint32) string {
+	var b strings.Builder
+	for i, c := range "rwx" {
+		if access&(4>>i) != 0 {
+			b.WriteRune(c)
+		} else {
+			b.WriteByte('-')
+		}
+	}
+	return b.String()
+}
+
+// CheckPermission 检查调用者对路径的访问权限：所有祖先目录需要执行权限，
+// 父目录还需要 parentAccess，路径本身需要 access。路径或祖先不存在时不报错，由后续操作返回不存在。
+// 快照路径按快照中记录的权限检查
+func (ms *MetadataService) CheckPermission(caller model.Caller, path string, parentAccess, access uint32) error {
+	caller = ms.normalizeCaller(caller)
+	if !ms.PermissionsEnabled() || ms.IsSuperuser(caller) {
+		return nil
+	}
+
+	path = filepath.Clean(path)
+	if path == "." {
+		path = "/"
+	}
+
+	// 从根目录开始逐级检查
+	var chain []string
+	for p := path; ; p = filepath.Dir(p) {
+		chain = append([]string{p}, chain...)
+		if p == "/" {
+			break
+		}
+	}
+
+	return ms.db.View(func(txn *badger.Txn) error {
+		for i, p := range chain {
+			required := access
+			if i < len(chain)-1 {
+				required = model.PermExecute
+				if i == len(chain)-2 {
+					required |= parentAccess
+				}
+			}
+			if required == 0 {
+				continue
+			}
+
+			node, err := ms.lookupNodeInTx(txn, p)
+			if err == badger.ErrKeyNotFound {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if !permitted(caller, node, required) {
+				return fmt.Errorf("permission denied: user=%s, access=%s, path=%s", caller.User, accessString(required), p)
+			}
+		}
+		return nil
+	})
+}
+
+// lookupNodeInTx 在事务中读取路径对应的节点，快照路径读取快照中的节点
+func (ms *MetadataService) lookupNodeInTx(txn *badger.Txn, path string) (*pb.NodeInfo, error) {
+	if IsSnapshotPath(path) {
+		if path == model.SnapshotRoot {
+			return &pb.NodeInfo{Path: model.SnapshotRoot, Type: pb.FileType_Directory}, nil
+		}
+		return ms.getSnapshotNodeInfoInTx(txn, path)
+	}
+	inodeID, err := ms.getInodeIDByPathInTx(txn, path)
+	if err != nil {
+		return nil, err
+	}
+	return ms.getNodeInfoInTx(txn, inodeID)
+}
+
+// CreateNodeAs 以调用者身份创建节点：父目录需要写权限，属主为调用者，属组继承父目录，mode 为 0 时使用默认权限位
+func (ms *MetadataService) CreateNodeAs(caller model.Caller, path string, nodeType pb.FileType, replication, mode uint32) error {
+	caller = ms.normalizeCaller(caller)
+	if mode > 07777 {
+		return fmt.Errorf("invalid mode %o", mode)
+	}
+	if err := ms.CheckPermission(caller, path, model.PermWrite, 0); err != nil {
+		return err
+	}
+
+	ownership := model.Ownership{Owner: caller.User, Mode: mode}
+	if ownership.Mode == 0 {
+		ownership.Mode = defaultFileMode
+		if nodeType == pb.FileType_Directory {
+			ownership.Mode = defaultDirMode
+		}
+	}
+	if parent, err := ms.GetNodeInfo(filepath.Dir(filepath.Clean(path))); err == nil && parent.Group != "" {
+		ownership.Group = parent.Group
+	} else if len(caller.Groups) > 0 {
+		ownership.Group = caller.Groups[0]
+	} else {
+		ownership.Group = caller.User
+	}
+
+	return ms.createNode(path, nodeType, replication, ownership)
+}
+
+// Chmod 修改节点的权限位，只有属主或超级用户可以修改
+func (ms *MetadataService) Chmod(caller model.Caller, path string, mode uint32) error {
+	if mode > 07777 {
+		return fmt.Errorf("invalid mode %o", mode)
+	}
+	op, err := ms.permissionOperation(caller, path)
+	if err != nil {
+		return err
+	}
+	op.Mode = mode
+	op.SetMode = true
+
+	_, err = ms.propose(pb.WALOperationType_SET_PERMISSION, op)
+	return err
+}
+
+// Chown 修改节点的属主和属组，空值不修改
+// 只有超级用户可以修改属主；属主可以把属组改为自己所在的组
+func (ms *MetadataService) Chown(caller model.Caller, path, owner, group string) error {
+	if owner == "" && group == "" {
+		return fmt.Errorf("owner and group cannot both be empty")
+	}
+	caller = ms.normalizeCaller(caller)
+	op, err := ms.permissionOperation(caller, path)
+	if err != nil {
+		return err
+	}
+
+	if ms.PermissionsEnabled() && !ms.IsSuperuser(caller) {
+		if owner != "" && owner != op.Owner {
+			return fmt.Errorf("permission denied: only superuser can change owner of %s", path)
+		}
+		if group != "" && !inGroups(caller, group) {
+			return fmt.Errorf("permission denied: user=%s is not a member of group %s", caller.User, group)
+		}
+	}
+	if owner != "" {
+		op.Owner = owner
+	}
+	if group != "" {
+		op.Group = group
+	}
+
+	_, err = ms.propose(pb.WALOperationType_SET_PERMISSION, op)
+	return err
+}
+
+// SetAcl 替换节点的 ACL 条目，只有属主或超级用户可以修改
+func (ms *MetadataService) SetAcl(caller model.Caller, path string, acl []*pb.AclEntry) error {
+	for _, entry := range acl {
+		if entry.Type != model.AclUser && entry.Type != model.AclGroup {
+			return fmt.Errorf("invalid acl entry type %q", entry.Type)
+		}
+		if entry.Name == "" || entry.Perm > 7 {
+			return fmt.Errorf("invalid acl entry %s:%s:%o", entry.Type, entry.Name, entry.Perm)
+		}
+	}
+	op, err := ms.permissionOperation(caller, path)
+	if err != nil {
+		return err
+	}
+	op.Acl = acl
+	op.SetAcl = true
+
+	_, err = ms.propose(pb.WALOperationType_SET_PERMISSION, op)
+	return err
+}
+
+// permissionOperation 检查调用者可以修改节点的权限并生成修改操作，带上节点当前的属主和属组
+// 没有属主的旧节点修改后归超级用户所有
+func (ms *MetadataService) permissionOperation(caller model.Caller, path string) (*pb.SetPermissionOperation, error) {
+	caller = ms.normalizeCaller(caller)
+	path = filepath.Clean(path)
+	if IsSnapshotPath(path) {
+		return nil, fmt.Errorf("snapshot path is read-only: %s", path)
+	}
+	if err := ms.CheckPermission(caller, path, 0, 0); err != nil {
+		return nil, err
+	}
+
+	node, err := ms.GetNodeInfo(path)
+	if err != nil {
+		return nil, err
+	}
+	if ms.PermissionsEnabled() && !ms.IsSuperuser(caller) && caller.User != node.Owner {
+		return nil, fmt.Errorf("permission denied: user=%s is not the owner of %s", caller.User, path)
+	}
+
+	op := &pb.SetPermissionOperation{Path: path, Owner: node.Owner, Group: node.Group}
+	if op.Owner == "" {
+		op.Owner = ms.superuser()
+		op.Group = ms.supergroup()
+	}
+	return op, nil
+}
+
+// setPermissionInDB 修改节点的属主、属组、权限位或 ACL（仅数据库操作，不写WAL）
+func (ms *MetadataService) setPermissionInDB(op *pb.SetPermissionOperation) error {
+	path := filepath.Clean(op.Path)
+
+	return ms.applyUpdate(func(txn *badger.Txn) error {
+		inodeID, err := ms.getInodeIDByPathInTx(txn, path)
+		if err == badger.ErrKeyNotFound {
+			return fmt.Errorf("path not found: %s", path)
+		}
+		if err != nil {
+			return err
+		}
+		nodeInfo, err := ms.getNodeInfoInTx(txn, inodeID)
+		if err != nil {
+			return err
+		}
+
+		// 旧节点第一次设置属主时使用默认权限位
+		if nodeInfo.Owner == "" && nodeInfo.Mode == 0 {
+			nodeInfo.Mode = defaultFileMode
+			if nodeInfo.Type == pb.FileType_Directory {
+				nodeInfo.Mode = defaultDirMode
+			}
+		}
+		if op.Owner != "" {
+			nodeInfo.Owner = op.Owner
+		}
+		if op.Group != "" {
+			nodeInfo.Group = op.Group
+		}
+		if op.SetMode {
+			nodeInfo.Mode = op.Mode
+		}
+		if op.SetAcl {
+			nodeInfo.Acl = op.Acl
+		}
+
+		data, err := proto.Marshal(nodeInfo)
+		if err != nil {
+			return err
+		}
+		return txn.Set([]byte(fmt.Sprintf("%s%d", model.PrefixInode, inodeID)), data)
+	})
+}
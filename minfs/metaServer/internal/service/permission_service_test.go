@@ -0,0 +1,140 @@
+package service
+
+import (
+	"testing"
+
+	"metaServer/internal/model"
+	"metaServer/pb"
+)
+
+func TestPermitted(t *testing.T) {
+	node := &pb.NodeInfo{
+		Owner: "alice",
+		Group: "staff",
+		Mode:  0640,
+		Acl: []*pb.AclEntry{
+			{Type: model.AclUser, Name: "carol", Perm: 6},
+			{Type: model.AclGroup, Name: "ops", Perm: 1},
+		},
+	}
+	cases := []struct {
+		caller model.Caller
+		access uint32
+		want   bool
+	}{
+		{model.Caller{User: "alice"}, model.PermRead | model.PermWrite, true},
+		{model.Caller{User: "alice"}, model.PermExecute, false},
+		{model.Caller{User: "bob", Groups: []string{"staff"}}, model.PermRead, true},
+		{model.Caller{User: "bob", Groups: []string{"staff"}}, model.PermWrite, false},
+		{model.Caller{User: "carol", Groups: []string{"staff"}}, model.PermWrite, true},
+		{model.Caller{User: "dave", Groups: []string{"staff", "ops"}}, model.PermRead | model.PermExecute, true},
+		{model.Caller{User: "dave", Groups: []string{"ops"}}, model.PermRead, false},
+		{model.Caller{User: "eve"}, model.PermRead, false},
+	}
+	for _, c := range cases {
+		if got := permitted(c.caller, node, c.access); got != c.want {
+			t.Errorf("permitted(%+v, %s) = %v", c.caller, accessString(c.access), got)
+		}
+	}
+
+	// 没有属主的旧节点不做限制
+	if !permitted(model.Caller{User: "eve"}, &pb.NodeInfo{}, model.PermWrite) {
+		t.Errorf("legacy node denied access")
+	}
+}
+
+func TestPermissionChecks(t *testing.T) {
+	_, servers := newTestCluster(t)
+	leader := waitForLeader(t, servers)
+	leader.metadata.config.Security.PermissionsEnabled = true
+
+	root := model.Caller{User: "root"}
+	alice := model.Caller{User: "alice", Groups: []string{"staff"}}
+	bob := model.Caller{User: "bob", Groups: []string{"staff"}}
+	carol := model.Caller{User: "carol"}
+
+	if err := leader.metadata.CreateNodeAs(root, "/data", pb.FileType_Directory, 0, 0); err != nil {
+		t.Fatalf("create /data: %v", err)
+	}
+	if info, _ := leader.metadata.GetNodeInfo("/data"); info.Owner != "root" || info.Mode != 0755 {
+		t.Fatalf("/data: %+v", info)
+	}
+	if err := leader.metadata.CreateNodeAs(alice, "/data/f", pb.FileType_File, 0, 0); err == nil {
+		t.Errorf("alice created a file in root's directory")
+	}
+	if err := leader.metadata.Chown(alice, "/data", "alice", ""); err == nil {
+		t.Errorf("alice took ownership of /data")
+	}
+	if err := leader.metadata.Chown(root, "/data", "alice", "staff"); err != nil {
+		t.Fatalf("chown: %v", err)
+	}
+
+	// 新文件的属组继承父目录
+	if err := leader.metadata.CreateNodeAs(alice, "/data/f", pb.FileType_File, 0, 0640); err != nil {
+		t.Fatalf("create /data/f: %v", err)
+	}
+	info, _ := leader.metadata.GetNodeInfo("/data/f")
+	if info.Owner != "alice" || info.Group != "staff" || info.Mode != 0640 {
+		t.Fatalf("/data/f: %+v", info)
+	}
+
+	if err := leader.metadata.CheckPermission(bob, "/data/f", 0, model.PermRead); err != nil {
+		t.Errorf("group member cannot read: %v", err)
+	}
+	if err := leader.metadata.CheckPermission(bob, "/data/f", 0, model.PermWrite); err == nil {
+		t.Errorf("group member can write")
+	}
+	if err := leader.metadata.CheckPermission(carol, "/data/f", 0, model.PermRead); err == nil {
+		t.Errorf("other user can read")
+	}
+	if err := leader.metadata.CheckPermission(carol, "/data/f", model.PermWrite, 0); err == nil {
+		t.Errorf("other user can delete")
+	}
+
+	if err := leader.metadata.SetAcl(bob, "/data/f", nil); err == nil {
+		t.Errorf("non-owner changed acl")
+	}
+	if err := leader.metadata.SetAcl(alice, "/data/f", []*pb.AclEntry{{Type: model.AclUser, Name: "carol", Perm: 4}}); err != nil {
+		t.Fatalf("set acl: %v", err)
+	}
+	if err := leader.metadata.CheckPermission(carol, "/data/f", 0, model.PermRead); err != nil {
+		t.Errorf("acl user cannot read: %v", err)
+	}
+
+	// 父目录没有执行权限时无法访问其下的节点
+	if err := leader.metadata.Chmod(alice, "/data", 0700); err != nil {
+		t.Fatalf("chmod: %v", err)
+	}
+	if err := leader.metadata.CheckPermission(carol, "/data/f", 0, model.PermRead); err == nil {
+		t.Errorf("traversal without execute permission")
+	}
+	if err := leader.metadata.CheckPermission(carol, "/data", 0, model.PermRead|model.PermExecute); err == nil {
+		t.Errorf("list without read permission")
+	}
+	if err := leader.metadata.CheckPermission(root, "/data/f", model.PermWrite, model.PermWrite); err != nil {
+		t.Errorf("superuser denied: %v", err)
+	}
+
+	if err := leader.metadata.Chown(alice, "/data/f", "", "ops"); err == nil {
+		t.Errorf("owner changed group to one they are not in")
+	}
+	if err := leader.metadata.Chmod(alice, "/data/f", 010000); err == nil {
+		t.Errorf("invalid mode accepted")
+	}
+
+	// 管理操作只有超级用户和超级用户组可以执行
+	if err := leader.metadata.CheckSuperuserPermission(alice, "set quota"); err == nil {
+		t.Errorf("non-superuser allowed to run admin operations")
+	}
+	for _, caller := range []model.Caller{root, {User: "ops", Groups: []string{"supergroup"}}} {
+		if err := leader.metadata.CheckSuperuserPermission(caller, "set quota"); err != nil {
+			t.Errorf("superuser %+v denied: %v", caller, err)
+		}
+	}
+
+	// 服务端为用户配置的组与声明的组合并
+	leader.metadata.config.Security.UserGroups = map[string][]string{"carol": {"supergroup"}}
+	if err := leader.metadata.CheckSuperuserPermission(carol, "set quota"); err != nil {
+		t.Errorf("configured supergroup member denied: %v", err)
+	}
+}
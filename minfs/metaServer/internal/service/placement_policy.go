@@ -0,0 +1,252 @@
+package service
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"metaServer/internal/model"
+)
+
+// 副本放置策略名称，对应 config.yaml 中的 scheduler.placement_policy
+const (
+	PlacementDistinct  = "distinct"
+	PlacementCapacity  = "capacity"
+	PlacementRackAware = "rack-aware"
+)
+
+// PlacementPolicy 副本放置策略，新块分配和副本修复使用同一个策略
+type PlacementPolicy interface {
+	// Name 策略名称
+	Name() string
+	// ChooseTargets 从 candidates 中为一个块选择 count 个不同的服务器
+	// existing 为该块已有副本所在的服务器，不在 candidates 中
+	ChooseTargets(candidates, existing []*model.DataServerInfo, count int) ([]*model.DataServerInfo, error)
+}
+
+// NewPlacementPolicy 按名称创建放置策略，名称为空时使用机架感知策略
+func NewPlacementPolicy(name string) (PlacementPolicy, error) {
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	switch name {
+	case PlacementDistinct:
+		return &distinctPlacement{}, nil
+	case PlacementCapacity:
+		return &capacityPlacement{rng: rng}, nil
+	case PlacementRackAware, "":
+		return &rackAwarePlacement{capacity: capacityPlacement{rng: rng}}, nil
+	default:
+		return nil, fmt.Errorf("unknown placement policy: %s", name)
+	}
+}
+
+// checkCandidates 候选服务器不足时返回错误
+func checkCandidates(candidates []*model.DataServerInfo, count int) error {
+	if len(candidates) < count {
+		return fmt.Errorf("need %d healthy DataServers for placement, have %d", count, len(candidates))
+	}
+	return nil
+}
+
+// distinctPlacement 在服务器间轮询，同一个块的副本不会放在同一个服务器上
+type distinctPlacement struct {
+	mu   sync.Mutex
+	next int
+}
+
+func (p *distinctPlacement) Name() string {
+	return PlacementDistinct
+}
+
+func (p *distinctPlacement) ChooseTargets(candidates, existing []*model.DataServerInfo, count int) ([]*model.DataServerInfo, error) {
+	if err := checkCandidates(candidates, count); err != nil {
+		return nil, err
+	}
+
+	// 候选列表来自 map，先排序保证轮询顺序稳定
+	sorted := append([]*model.DataServerInfo(nil), candidates...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	p.mu.Lock()
+	start := p.next
+	p.next++
+	p.mu.Unlock()
+
+	targets := make([]*model.DataServerInfo, 0, count)
+	for i := 0; i < count; i++ {
+		targets = append(targets, sorted[(start+i)%len(sorted)])
+	}
+	return targets, nil
+}
+
+// capacityPlacement 按剩余空间加权随机选择不同的服务器
+type capacityPlacement struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+func (p *capacityPlacement) Name() string {
+	return PlacementCapacity
+}
+
+func (p *capacityPlacement) ChooseTargets(candidates, existing []*model.DataServerInfo, count int) ([]*model.DataServerInfo, error) {
+	if err := checkCandidates(candidates, count); err != nil {
+		return nil, err
+	}
+
+	remaining := append([]*model.DataServerInfo(nil), candidates...)
+	targets := make([]*model.DataServerInfo, 0, count)
+	for len(targets) < count {
+		i := p.pick(remaining)
+		targets = append(targets, remaining[i])
+		remaining = append(remaining[:i], remaining[i+1:]...)
+	}
+	return targets, nil
+}
+
+// pick 按剩余空间加权随机返回一个下标，所有服务器都没有剩余空间信息时等概率选择
+func (p *capacityPlacement) pick(servers []*model.DataServerInfo) int {
+	weights := make([]float64, len(servers))
+	var total float64
+	for i, server := range servers {
+		_, freeSpace, _, _, _ := server.GetStatus()
+		weights[i] = float64(freeSpace)
+		total += weights[i]
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if total == 0 {
+		return p.rng.Intn(len(servers))
+	}
+	r := p.rng.Float64() * total
+	for i, weight := range weights {
+		if r < weight {
+			return i
+		}
+		r -= weight
+	}
+	return len(servers) - 1
+}
+
+// rackAwarePlacement 优先放到尚无副本的可用区，其次尚无副本的机架，同一级别内按剩余空间加权
+type rackAwarePlacement struct {
+	capacity capacityPlacement
+}
+
+func (p *rackAwarePlacement) Name() string {
+	return PlacementRackAware
+}
+
+func (p *rackAwarePlacement) ChooseTargets(candidates, existing []*model.DataServerInfo, count int) ([]*model.DataServerInfo, error) {
+	if err := checkCandidates(candidates, count); err != nil {
+		return nil, err
+	}
+
+	usedZones := make(map[string]bool)
+	usedRacks := make(map[string]bool)
+	use := func(server *model.DataServerInfo) {
+		zone, rack := parseTopology(server.GetTopology())
+		usedZones[zone] = true
+		usedRacks[rack] = true
+	}
+	for _, server := range existing {
+		use(server)
+	}
+
+	remaining := append([]*model.DataServerInfo(nil), candidates...)
+	targets := make([]*model.DataServerInfo, 0, count)
+	for len(targets) < count {
+		var newZone, newRack, rest []int
+		for i, server := range remaining {
+			zone, rack := parseTopology(server.GetTopology())
+			switch {
+			case !usedZones[zone]:
+				newZone = append(newZone, i)
+			case !usedRacks[rack]:
+				newRack = append(newRack, i)
+			default:
+				rest = append(rest, i)
+			}
+		}
+
+		tier := rest
+		if len(newZone) > 0 {
+			tier = newZone
+		} else if len(newRack) > 0 {
+			tier = newRack
+		}
+		tierServers := make([]*model.DataServerInfo, len(tier))
+		for j, i := range tier {
+			tierServers[j] = remaining[i]
+		}
+		i := tier[p.capacity.pick(tierServers)]
+
+		use(remaining[i])
+		targets = append(targets, remaining[i])
+		remaining = append(remaining[:i], remaining[i+1:]...)
+	}
+	return targets, nil
+}
+
+// parseTopology 将 /zone/rack 形式的拓扑标签拆分为可用区和机架，只有一级时可用区与机架相同
+func parseTopology(topology string) (string, string) {
+	rack := "/" + strings.Trim(topology, "/")
+	if rack == "/" {
+		rack = model.DefaultTopology
+	}
+	zone := rack
+	if idx := strings.Index(rack[1:], "/"); idx >= 0 {
+		zone = rack[:idx+1]
+	}
+	return zone, rack
+}
+
+// selectExcessReplicas 从一个块的副本中选择 count 个需要删除的副本，返回其下标；replicas 中为 nil 表示节点未知
+// 优先删除未知、不健康或永久宕机节点上的副本，其次删除所在机架副本最多的，同等情况下删除剩余空间最少的
+func selectExcessReplicas(replicas []*model.DataServerInfo, count int) []int {
+	remaining := make([]int, len(replicas))
+	for i := range replicas {
+		remaining[i] = i
+	}
+
+	var removed []int
+	for len(removed) < count && len(remaining) > 0 {
+		rackCount := make(map[string]int)
+		for _, i := range remaining {
+			if replicas[i] != nil {
+				_, rack := parseTopology(replicas[i].GetTopology())
+				rackCount[rack]++
+			}
+		}
+
+		best := -1
+		var bestRackCount int
+		var bestFreeSpace uint64
+		for pos, i := range remaining {
+			server := replicas[i]
+			if server == nil || server.IsPermanentlyDownStatus() {
+				best = pos
+				break
+			}
+			_, freeSpace, _, _, isHealthy := server.GetStatus()
+			if !isHealthy {
+				best = pos
+				break
+			}
+			_, rack := parseTopology(server.GetTopology())
+			if best < 0 || rackCount[rack] > bestRackCount || (rackCount[rack] == bestRackCount && freeSpace < bestFreeSpace) {
+				best, bestRackCount, bestFreeSpace = pos, rackCount[rack], freeSpace
+			}
+		}
+
+		removed = append(removed, remaining[best])
+		remaining = append(remaining[:best], remaining[best+1:]...)
+	}
+	return removed
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"testing"
+
+	"metaServer/internal/model"
+)
+
+func TestPlacementPolicies(t *testing.T) {
+	newServer := func(id, topology string) *model.DataServerInfo {
+		ds := &model.DataServerInfo{ID: id, Addr: id + ":8001", Topology: topology}
+		ds.UpdateStatus(0, 1<<30, 1<<30)
+		return ds
+	}
+	servers := []*model.DataServerInfo{
+		newServer("ds1", "/z1/r1"),
+		newServer("ds2", "/z1/r1"),
+		newServer("ds3", "/z1/r2"),
+		newServer("ds4", "/z2/r3"),
+		newServer("ds5", "/z2/r3"),
+	}
+
+	for _, name := range []string{PlacementDistinct, PlacementCapacity, PlacementRackAware} {
+		policy, err := NewPlacementPolicy(name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		for round := 0; round < 20; round++ {
+			targets, err := policy.ChooseTargets(servers, nil, 3)
+			if err != nil || len(targets) != 3 {
+				t.Fatalf("%s: choose: %v, %v", name, targets, err)
+			}
+			seen := make(map[string]bool)
+			for _, target := range targets {
+				if seen[target.ID] {
+					t.Fatalf("%s: replicas co-located on %s", name, target.ID)
+				}
+				seen[target.ID] = true
+			}
+		}
+		if _, err := policy.ChooseTargets(servers[:2], nil, 3); err == nil {
+			t.Errorf("%s: placement succeeded with too few servers", name)
+		}
+	}
+
+	// 机架感知：三副本覆盖两个可用区和三个机架
+	policy, _ := NewPlacementPolicy(PlacementRackAware)
+	for round := 0; round < 20; round++ {
+		targets, _ := policy.ChooseTargets(servers, nil, 3)
+		zones := make(map[string]bool)
+		racks := make(map[string]bool)
+		for _, target := range targets {
+			zone, rack := parseTopology(target.GetTopology())
+			zones[zone] = true
+			racks[rack] = true
+		}
+		if len(zones) != 2 || len(racks) != 3 {
+			t.Fatalf("rack-aware placement not spread: zones=%v racks=%v", zones, racks)
+		}
+	}
+
+	// 修复时避开已有副本所在的可用区
+	targets, err := policy.ChooseTargets(servers[:3], servers[3:4], 1)
+	if err != nil || len(targets) != 1 {
+		t.Fatalf("repair placement: %v, %v", targets, err)
+	}
+	if zone, _ := parseTopology(targets[0].GetTopology()); zone != "/z1" {
+		t.Errorf("repair target in zone %s, want /z1", zone)
+	}
+
+	if zone, rack := parseTopology(""); zone != "/default-zone" || rack != model.DefaultTopology {
+		t.Errorf("default topology parsed as %s %s", zone, rack)
+	}
+	if _, err := NewPlacementPolicy("random"); err == nil {
+		t.Errorf("unknown placement policy accepted")
+	}
+}
+
+func TestSelectExcessReplicas(t *testing.T) {
+	newServer := func(id, topology string, freeSpace uint64) *model.DataServerInfo {
+		ds := &model.DataServerInfo{ID: id, Addr: id + ":8001", Topology: topology}
+		ds.UpdateStatus(0, freeSpace, 1<<30)
+		return ds
+	}
+	replicas := []*model.DataServerInfo{
+		newServer("ds1", "/z1/r1", 300),
+		newServer("ds2", "/z1/r1", 100),
+		nil,
+		newServer("ds3", "/z1/r2", 50),
+	}
+
+	// 先删除未知节点上的副本，再删除副本最多的机架中剩余空间最少的
+	removed := selectExcessReplicas(replicas, 2)
+	if len(removed) != 2 || removed[0] != 2 || removed[1] != 1 {
+		t.Errorf("removed %v, want [2 1]", removed)
+	}
+}
@@ -0,0 +1,474 @@
+package service
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"metaServer/internal/model"
+	"metaServer/pb"
+
+	"github.com/dgraph-io/badger/v3"
+	"google.golang.org/protobuf/proto"
+)
+
+// QuotaExceededError 操作会使目录超出配额
+type QuotaExceededError struct {
+	Path      string // 设置配额的目录
+	Resource  string // "space" 或 "inodes"
+	Limit     uint64
+	Used      int64
+	Requested int64
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("quota exceeded: %s quota of %s is %d, used %d, requested %d",
+		e.Resource, e.Path, e.Limit, e.Used, e.Requested)
+}
+
+// quotaDir 路径上的一个祖先目录
+type quotaDir struct {
+	path  string
+	inode uint64
+}
+
+// SetQuota 设置目录配额（通过日志提交）
+func (ms *MetadataService) SetQuota(path string, maxBytes, maxInodes uint64) error {
+	_, err := ms.propose(pb.WALOperationType_SET_QUOTA, &pb.SetQuotaOperation{
+		Path:      filepath.Clean(path),
+		MaxBytes:  maxBytes,
+		MaxInodes: maxInodes,
+	})
+	return err
+}
+
+// setQuotaInDB 设置目录配额（仅数据库操作，不写WAL），两项均为 0 时删除配额
+// 已有使用量超过新配额时仍然生效，之后的新增操作会被拒绝
+func (ms *MetadataService) setQuotaInDB(path string, maxBytes, maxInodes uint64) error {
+	path = filepath.Clean(path)
+	if path == "." {
+		path = "/"
+	}
+
+	return ms.applyUpdate(func(txn *badger.Txn) error {
+		inodeID, err := ms.getInodeIDByPathInTx(txn, path)
+		if err == badger.ErrKeyNotFound {
+			return fmt.Errorf("directory not found: %s", path)
+		}
+		if err != nil {
+			return err
+		}
+		nodeInfo, err := ms.getNodeInfoInTx(txn, inodeID)
+		if err != nil {
+			return err
+		}
+		if nodeInfo.Type != pb.FileType_Directory {
+			return fmt.Errorf("quota can only be set on a directory: %s", path)
+		}
+
+		key := fmt.Sprintf("%s%d", model.PrefixQuota, inodeID)
+		if maxBytes == 0 && maxInodes == 0 {
+			return txn.Delete([]byte(key))
+		}
+		data, err := json.Marshal(model.DirectoryQuota{MaxBytes: maxBytes, MaxInodes: maxInodes})
+		if err != nil {
+			return err
+		}
+		return txn.Set([]byte(key), data)
+	})
+}
+
+// GetQuota 获取目录的配额和使用量
+func (ms *MetadataService) GetQuota(path string) (*pb.DirectoryUsage, error) {
+	path = filepath.Clean(path)
+	if path == "." {
+		path = "/"
+	}
+
+	var usage *pb.DirectoryUsage
+	err := ms.db.View(func(txn *badger.Txn) error {
+		inodeID, err := ms.getInodeIDByPathInTx(txn, path)
+		if err == badger.ErrKeyNotFound {
+			return fmt.Errorf("directory not found: %s", path)
+		}
+		if err != nil {
+			return err
+		}
+		nodeInfo, err := ms.getNodeInfoInTx(txn, inodeID)
+		if err != nil {
+			return err
+		}
+		if nodeInfo.Type != pb.FileType_Directory {
+			return fmt.Errorf("not a directory: %s", path)
+		}
+
+		usage, err = ms.directoryUsageInTx(txn, nodeInfo)
+		return err
+	})
+	return usage, err
+}
+
+// GetUsageReport 报告目录及其子目录的使用量和配额，recursive 为 false 时只包括直接子目录
+func (ms *MetadataService) GetUsageReport(path string, recursive bool) ([]*pb.DirectoryUsage, error) {
+	path = filepath.Clean(path)
+	if path == "." {
+		path = "/"
+	}
+
+	var report []*pb.DirectoryUsage
+	err := ms.db.View(func(txn *badger.Txn) error {
+		inodeID, err := ms.getInodeIDByPathInTx(txn, path)
+		if err == badger.ErrKeyNotFound {
+			return fmt.Errorf("directory not found: %s", path)
+		}
+		if err != nil {
+			return err
+		}
+		nodeInfo, err := ms.getNodeInfoInTx(txn, inodeID)
+		if err != nil {
+			return err
+		}
+		if nodeInfo.Type != pb.FileType_Directory {
+			return fmt.Errorf("not a directory: %s", path)
+		}
+
+		var walk func(dir *pb.NodeInfo, depth int) error
+		walk = func(dir *pb.NodeInfo, depth int) error {
+			usage, err := ms.directoryUsageInTx(txn, dir)
+			if err != nil {
+				return err
+			}
+			report = append(report, usage)
+
+			if depth > 0 && !recursive {
+				return nil
+			}
+			children, err := ms.listDirectoryInTx(txn, dir.Inode)
+			if err != nil {
+				return err
+			}
+			for _, child := range children {
+				if child.Type == pb.FileType_Directory {
+					if err := walk(child, depth+1); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		}
+		return walk(nodeInfo, 0)
+	})
+	return report, err
+}
+
+// CheckSpaceQuota 检查向 path 写入 bytes 字节（按 replication 个副本或 ecPolicy 纠删码计算）是否超出祖先目录的空间配额
+// path 可以尚不存在，只检查其父目录链
+func (ms *MetadataService) CheckSpaceQuota(path string, bytes int64, replication uint32, ecPolicy string) error {
+	if bytes <= 0 {
+		return nil
+	}
+	path = filepath.Clean(path)
+
+	return ms.db.View(func(txn *badger.Txn) error {
+		delta := fileUsage(bytes, replication, ecPolicy)
+		delta.InodeCount = 0
+		return ms.checkQuotaInTx(txn, path, delta, nil)
+	})
+}
+
+// DefaultReplication 获取新文件的默认副本数
+func (ms *MetadataService) DefaultReplication() uint32 {
+	if ms.config != nil && ms.config.Cluster.DefaultReplication > 0 {
+		return uint32(ms.config.Cluster.DefaultReplication)
+	}
+	return 1
+}
+
+// directoryUsageInTx 在事务中组装目录的使用量和配额
+func (ms *MetadataService) directoryUsageInTx(txn *badger.Txn, dir *pb.NodeInfo) (*pb.DirectoryUsage, error) {
+	usage, err := ms.getUsageInTx(txn, dir.Inode)
+	if err != nil {
+		return nil, err
+	}
+	quota, err := ms.getQuotaInTx(txn, dir.Inode)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &pb.DirectoryUsage{
+		Path:          dir.Path,
+		Inode:         dir.Inode,
+		Bytes:         usage.Bytes,
+		SpaceConsumed: usage.Space,
+		InodeCount:    usage.InodeCount,
+	}
+	if quota != nil {
+		result.MaxBytes = quota.MaxBytes
+		result.MaxInodes = quota.MaxInodes
+	}
+	return result, nil
+}
+
+// getUsageInTx 在事务中获取目录使用量，没有记录时返回零值
+func (ms *MetadataService) getUsageInTx(txn *badger.Txn, inodeID uint64) (model.DirectoryUsage, error) {
+	var usage model.DirectoryUsage
+
+	item, err := txn.Get([]byte(fmt.Sprintf("%s%d", model.PrefixUsage, inodeID)))
+	if err == badger.ErrKeyNotFound {
+		return usage, nil
+	}
+	if err != nil {
+		return usage, err
+	}
+	err = item.Value(func(val []byte) error {
+		return json.Unmarshal(val, &usage)
+	})
+	return usage, err
+}
+
+// setUsageInTx 在事务中保存目录使用量
+func (ms *MetadataService) setUsageInTx(txn *badger.Txn, inodeID uint64, usage model.DirectoryUsage) error {
+	data, err := json.Marshal(usage)
+	if err != nil {
+		return err
+	}
+	return txn.Set([]byte(fmt.Sprintf("%s%d", model.PrefixUsage, inodeID)), data)
+}
+
+// getQuotaInTx 在事务中获取目录配额，没有配额时返回 nil
+func (ms *MetadataService) getQuotaInTx(txn *badger.Txn, inodeID uint64) (*model.DirectoryQuota, error) {
+	item, err := txn.Get([]byte(fmt.Sprintf("%s%d", model.PrefixQuota, inodeID)))
+	if err == badger.ErrKeyNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var quota model.DirectoryQuota
+	err = item.Value(func(val []byte) error {
+		return json.Unmarshal(val, &quota)
+	})
+	return &quota, err
+}
+
+// ancestorDirsInTx 在事务中获取 path 的所有祖先目录，从父目录到根目录
+func (ms *MetadataService) ancestorDirsInTx(txn *badger.Txn, path string) ([]quotaDir, error) {
+	var dirs []quotaDir
+	for path != "/" {
+		path = filepath.Dir(path)
+		inodeID, err := ms.getInodeIDByPathInTx(txn, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve ancestor %s: %v", path, err)
+		}
+		dirs = append(dirs, quotaDir{path: path, inode: inodeID})
+	}
+	return dirs, nil
+}
+
+// addUsageInTx 在事务中将 delta 累加到 path 的所有祖先目录
+func (ms *MetadataService) addUsageInTx(txn *badger.Txn, path string, delta model.DirectoryUsage) error {
+	if delta == (model.DirectoryUsage{}) {
+		return nil
+	}
+
+	dirs, err := ms.ancestorDirsInTx(txn, path)
+	if err != nil {
+		return err
+	}
+	for _, dir := range dirs {
+		usage, err := ms.getUsageInTx(txn, dir.inode)
+		if err != nil {
+			return err
+		}
+		usage.Bytes += delta.Bytes
+		usage.Space += delta.Space
+		usage.InodeCount += delta.InodeCount
+		if err := ms.setUsageInTx(txn, dir.inode, usage); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addChildCountInTx 在事务中调整目录的直接子节点数
+func (ms *MetadataService) addChildCountInTx(txn *badger.Txn, dirInodeID uint64, delta int64) error {
+	usage, err := ms.getUsageInTx(txn, dirInodeID)
+	if err != nil {
+		return err
+	}
+	usage.ChildCount += delta
+	return ms.setUsageInTx(txn, dirInodeID, usage)
+}
+
+// checkQuotaInTx 在事务中检查 path 的祖先目录在增加 delta 后是否超出配额
+// skip 中的目录不检查（例如重命名时源和目标共同的祖先，其使用量不变）
+func (ms *MetadataService) checkQuotaInTx(txn *badger.Txn, path string, delta model.DirectoryUsage, skip map[uint64]bool) error {
+	if delta.Space <= 0 && delta.InodeCount <= 0 {
+		return nil
+	}
+
+	dirs, err := ms.ancestorDirsInTx(txn, path)
+	if err != nil {
+		return err
+	}
+	for _, dir := range dirs {
+		if skip[dir.inode] {
+			continue
+		}
+		quota, err := ms.getQuotaInTx(txn, dir.inode)
+		if err != nil {
+			return err
+		}
+		if quota == nil {
+			continue
+		}
+		usage, err := ms.getUsageInTx(txn, dir.inode)
+		if err != nil {
+			return err
+		}
+
+		if quota.MaxInodes > 0 && delta.InodeCount > 0 && usage.InodeCount+delta.InodeCount > int64(quota.MaxInodes) {
+			return &QuotaExceededError{Path: dir.path, Resource: "inodes", Limit: quota.MaxInodes, Used: usage.InodeCount, Requested: delta.InodeCount}
+		}
+		if quota.MaxBytes > 0 && delta.Space > 0 && usage.Space+delta.Space > int64(quota.MaxBytes) {
+			return &QuotaExceededError{Path: dir.path, Resource: "space", Limit: quota.MaxBytes, Used: usage.Space, Requested: delta.Space}
+		}
+	}
+	return nil
+}
+
+// nodeUsageInTx 在事务中获取节点子树的使用量：目录为其使用量记录，文件为自身大小
+func (ms *MetadataService) nodeUsageInTx(txn *badger.Txn, nodeInfo *pb.NodeInfo) (model.DirectoryUsage, error) {
+	if nodeInfo.Type == pb.FileType_Directory {
+		return ms.getUsageInTx(txn, nodeInfo.Inode)
+	}
+	return fileUsage(nodeInfo.Size, nodeInfo.Replication, nodeInfo.EcPolicy), nil
+}
+
+// fileUsage 单个文件的使用量，纠删码文件按数据和校验条带计算占用空间
+func fileUsage(size int64, replication uint32, ecPolicy string) model.DirectoryUsage {
+	if ecPolicy != "" {
+		if space, ok := ecSpace(size, ecPolicy); ok {
+			return model.DirectoryUsage{Bytes: size, Space: space, InodeCount: 1}
+		}
+	}
+	return model.DirectoryUsage{Bytes: size, Space: size * int64(replication), InodeCount: 1}
+}
+
+// negateUsage 取反，用于从祖先目录中扣除
+func negateUsage(usage model.DirectoryUsage) model.DirectoryUsage {
+	return model.DirectoryUsage{Bytes: -usage.Bytes, Space: -usage.Space, InodeCount: -usage.InodeCount}
+}
+
+// RebuildUsageIfMissing 根目录没有使用量记录或记录中没有子节点数时（旧版本的数据）遍历命名空间重建所有目录的使用量
+func (ms *MetadataService) RebuildUsageIfMissing() error {
+	var rootInode uint64
+	missing := false
+	err := ms.db.View(func(txn *badger.Txn) error {
+		var err error
+		rootInode, err = ms.getInodeIDByPathInTx(txn, "/")
+		if err != nil {
+			return err
+		}
+		item, err := txn.Get([]byte(fmt.Sprintf("%s%d", model.PrefixUsage, rootInode)))
+		if err == badger.ErrKeyNotFound {
+			missing = true
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			var fields map[string]json.RawMessage
+			if err := json.Unmarshal(val, &fields); err != nil {
+				return err
+			}
+			_, ok := fields["child_count"]
+			missing = !ok
+			return nil
+		})
+	})
+	if err != nil || !missing {
+		return err
+	}
+
+	usages := make(map[uint64]model.DirectoryUsage)
+	err = ms.db.View(func(txn *badger.Txn) error {
+		_, err := ms.computeUsageInTx(txn, rootInode, usages)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to compute directory usage: %v", err)
+	}
+
+	batch := ms.db.NewWriteBatch()
+	defer batch.Cancel()
+	for inodeID, usage := range usages {
+		data, err := json.Marshal(usage)
+		if err != nil {
+			return err
+		}
+		if err := batch.Set([]byte(fmt.Sprintf("%s%d", model.PrefixUsage, inodeID)), data); err != nil {
+			return err
+		}
+	}
+	if err := batch.Flush(); err != nil {
+		return err
+	}
+
+	log.Printf("Rebuilt usage for %d directories", len(usages))
+	return nil
+}
+
+// computeUsageInTx 在事务中递归计算目录的使用量，结果记录到 usages
+func (ms *MetadataService) computeUsageInTx(txn *badger.Txn, dirInodeID uint64, usages map[uint64]model.DirectoryUsage) (model.DirectoryUsage, error) {
+	usage := model.DirectoryUsage{InodeCount: 1}
+
+	prefix := fmt.Sprintf("%s%d/", model.PrefixDir, dirInodeID)
+	opts := badger.DefaultIteratorOptions
+	it := txn.NewIterator(opts)
+
+	var childInodes []uint64
+	for it.Seek([]byte(prefix)); it.ValidForPrefix([]byte(prefix)); it.Next() {
+		err := it.Item().Value(func(val []byte) error {
+			childInodes = append(childInodes, binary.BigEndian.Uint64(val))
+			return nil
+		})
+		if err != nil {
+			it.Close()
+			return usage, err
+		}
+	}
+	it.Close()
+
+	for _, childInodeID := range childInodes {
+		item, err := txn.Get([]byte(fmt.Sprintf("%s%d", model.PrefixInode, childInodeID)))
+		if err != nil {
+			continue
+		}
+		var child pb.NodeInfo
+		if err := item.Value(func(val []byte) error {
+			return proto.Unmarshal(val, &child)
+		}); err != nil {
+			continue
+		}
+
+		childUsage := fileUsage(child.Size, child.Replication, child.EcPolicy)
+		if child.Type == pb.FileType_Directory {
+			childUsage, err = ms.computeUsageInTx(txn, childInodeID, usages)
+			if err != nil {
+				return usage, err
+			}
+		}
+		usage.Bytes += childUsage.Bytes
+		usage.Space += childUsage.Space
+		usage.InodeCount += childUsage.InodeCount
+		usage.ChildCount++
+	}
+
+	usages[dirInodeID] = usage
+	return usage, nil
+}
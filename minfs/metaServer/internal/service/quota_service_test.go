@@ -0,0 +1,141 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"metaServer/pb"
+)
+
+// writeTestFile 创建文件并提交指定大小
+func writeTestFile(t *testing.T, server *testMetaServer, path string, size int64) {
+	t.Helper()
+
+	if err := server.metadata.CreateNode(path, pb.FileType_File); err != nil {
+		t.Fatalf("create %s: %v", path, err)
+	}
+	nodeInfo, err := server.metadata.GetNodeInfo(path)
+	if err != nil {
+		t.Fatalf("stat %s: %v", path, err)
+	}
+	if err := server.metadata.CommitFileState(path, nodeInfo.Inode, size, "", nil); err != nil {
+		t.Fatalf("finalize %s: %v", path, err)
+	}
+}
+
+func TestQuotaLimitsInodesAndSpace(t *testing.T) {
+	_, servers := newTestCluster(t)
+	leader := waitForLeader(t, servers)
+	replication := int64(leader.metadata.DefaultReplication())
+
+	if err := leader.metadata.CreateNode("/q", pb.FileType_Directory); err != nil {
+		t.Fatalf("create /q: %v", err)
+	}
+	// 节点数包括目录本身
+	if err := leader.metadata.SetQuota("/q", uint64(250*replication), 3); err != nil {
+		t.Fatalf("set quota: %v", err)
+	}
+
+	writeTestFile(t, leader, "/q/a", 100)
+	if err := leader.metadata.CreateNode("/q/sub", pb.FileType_Directory); err != nil {
+		t.Fatalf("create /q/sub: %v", err)
+	}
+
+	var quotaErr *QuotaExceededError
+	err := leader.metadata.CreateNode("/q/sub/b", pb.FileType_File)
+	if !errors.As(err, &quotaErr) || quotaErr.Resource != "inodes" || quotaErr.Path != "/q" {
+		t.Fatalf("create beyond inode quota: got %v", err)
+	}
+
+	if err := leader.metadata.CheckSpaceQuota("/q/sub/c", 150, uint32(replication), ""); err != nil {
+		t.Errorf("write within space quota rejected: %v", err)
+	}
+	err = leader.metadata.CheckSpaceQuota("/q/sub/c", 151, uint32(replication), "")
+	if !errors.As(err, &quotaErr) || quotaErr.Resource != "space" {
+		t.Errorf("write beyond space quota: got %v", err)
+	}
+
+	usage, err := leader.metadata.GetQuota("/q")
+	if err != nil {
+		t.Fatalf("get quota: %v", err)
+	}
+	if usage.Bytes != 100 || usage.SpaceConsumed != 100*replication || usage.InodeCount != 3 {
+		t.Errorf("usage of /q: %+v", usage)
+	}
+
+	// 删除后释放配额
+	if _, err := leader.metadata.DeleteNode("/q/a", false); err != nil {
+		t.Fatalf("delete /q/a: %v", err)
+	}
+	if err := leader.metadata.CreateNode("/q/sub/b", pb.FileType_File); err != nil {
+		t.Errorf("create after delete: %v", err)
+	}
+}
+
+func TestUsageFollowsRenameAndDelete(t *testing.T) {
+	_, servers := newTestCluster(t)
+	leader := waitForLeader(t, servers)
+
+	for _, dir := range []string{"/src", "/src/inner", "/dst"} {
+		if err := leader.metadata.CreateNode(dir, pb.FileType_Directory); err != nil {
+			t.Fatalf("create %s: %v", dir, err)
+		}
+	}
+	writeTestFile(t, leader, "/src/inner/f", 40)
+	writeTestFile(t, leader, "/src/g", 2)
+
+	// 目标目录的配额拒绝放不下的子树
+	if err := leader.metadata.SetQuota("/dst", 0, 2); err != nil {
+		t.Fatalf("set quota: %v", err)
+	}
+	if _, err := leader.metadata.RenameNode("/src/inner", "/dst/inner", false); err == nil {
+		t.Fatalf("rename beyond inode quota succeeded")
+	}
+	if err := leader.metadata.SetQuota("/dst", 0, 0); err != nil {
+		t.Fatalf("clear quota: %v", err)
+	}
+	if _, err := leader.metadata.RenameNode("/src/inner", "/dst/inner", false); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+
+	report, err := leader.metadata.GetUsageReport("/", false)
+	if err != nil {
+		t.Fatalf("usage report: %v", err)
+	}
+	want := map[string][2]int64{"/": {42, 6}, "/src": {2, 2}, "/dst": {40, 3}}
+	for _, usage := range report {
+		if w, ok := want[usage.Path]; ok && (usage.Bytes != w[0] || usage.InodeCount != w[1]) {
+			t.Errorf("%s: bytes=%d inodes=%d, want %v", usage.Path, usage.Bytes, usage.InodeCount, w)
+		}
+	}
+	if len(report) != 3 {
+		t.Errorf("report has %d directories, want 3", len(report))
+	}
+
+	if _, err := leader.metadata.DeleteNode("/dst", true); err != nil {
+		t.Fatalf("delete /dst: %v", err)
+	}
+	root, err := leader.metadata.GetNodeInfo("/")
+	if err != nil {
+		t.Fatalf("stat /: %v", err)
+	}
+	if root.Size != 2 {
+		t.Errorf("root size %d after delete, want 2", root.Size)
+	}
+
+	// 所有节点的使用量一致
+	for _, server := range servers {
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			usage, err := server.metadata.GetQuota("/")
+			if err == nil && usage.Bytes == 2 && usage.InodeCount == 3 {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("%s: root usage did not converge: %+v, %v", server.id, usage, err)
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+}
@@ -0,0 +1,1010 @@
+package service
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"metaServer/internal/model"
+	"metaServer/pb"
+
+	"github.com/dgraph-io/badger/v3"
+	"google.golang.org/grpc"
+)
+
+// RaftState Raft 节点角色
+type RaftState int
+
+const (
+	RaftFollower RaftState = iota
+	RaftCandidate
+	RaftLeader
+)
+
+func (s RaftState) String() string {
+	switch s {
+	case RaftLeader:
+		return "leader"
+	case RaftCandidate:
+		return "candidate"
+	default:
+		return "follower"
+	}
+}
+
+// maxAppendEntries 单次 AppendEntries 最多携带的日志条目数
+const maxAppendEntries = 256
+
+// RaftTransport Raft 节点之间的通信
+type RaftTransport interface {
+	RequestVote(ctx context.Context, peer model.RaftPeer, req *pb.RequestVoteRequest) (*pb.RequestVoteResponse, error)
+	AppendEntries(ctx context.Context, peer model.RaftPeer, req *pb.AppendEntriesRequest) (*pb.AppendEntriesResponse, error)
+	// InstallSnapshot 发送快照，req 携带快照元信息，data 为快照内容
+	InstallSnapshot(ctx context.Context, peer model.RaftPeer, req *pb.InstallSnapshotRequest, data io.Reader) (*pb.InstallSnapshotResponse, error)
+}
+
+// proposal 等待提交的写操作
+type proposal struct {
+	term   uint64
+	result chan proposalResult
+}
+
+type proposalResult struct {
+	value interface{}
+	err   error
+}
+
+// RaftNode 基于 Raft 的元数据复制
+// 日志条目保存在 WALService 的 wal: 键下，任期、投票和已应用索引持久化在 BadgerDB 中。
+// 写操作先追加到leader日志，多数节点持久化后提交，再由每个节点按日志顺序应用到元数据；
+// 只有日志不比自己旧的候选人才能获得投票，因此已提交的条目不会因leader切换丢失。
+// 每个节点定期把已应用的元数据写成快照并删除快照之前的日志，落后于leader快照的follower通过 InstallSnapshot 追赶
+type RaftNode struct {
+	id    string
+	addr  string
+	peers []model.RaftPeer // 不包括自身
+
+	db              *badger.DB
+	walService      *WALService
+	metadataService *MetadataService
+	transport       RaftTransport
+
+	electionTimeout   time.Duration
+	heartbeatInterval time.Duration
+	proposeTimeout    time.Duration
+	snapshotDir       string
+	snapshotInterval  time.Duration
+	snapshotThreshold uint64
+
+	// applyMu 串行化日志应用、快照生成和快照安装，保证快照与其索引处的元数据一致
+	applyMu sync.Mutex
+
+	mu               sync.Mutex
+	state            RaftState
+	currentTerm      uint64
+	votedFor         string
+	leaderID         string
+	leaderAddr       string
+	commitIndex      uint64
+	lastApplied      uint64
+	nextIndex        map[string]uint64
+	matchIndex       map[string]uint64
+	electionDeadline time.Time
+	proposals        map[uint64]*proposal // log index -> 等待提交的写操作
+	readyIndex       uint64               // leader 当选时追加的空条目索引，应用后本地元数据包含所有已提交的写操作
+	replicateChans   map[string]chan struct{}
+
+	applyChan chan struct{}
+	stopChan  chan struct{}
+	stopOnce  sync.Once
+	wg        sync.WaitGroup // 后台协程，Stop 等待它们退出
+}
+
+// NewRaftNode 创建 Raft 节点，transport 为 nil 时使用 gRPC
+// config.Raft.Peers 为空时作为单节点集群运行
+func NewRaftNode(config *model.Config, nodeID, nodeAddr string, db *badger.DB, walService *WALService, metadataService *MetadataService, transport RaftTransport) (*RaftNode, error) {
+	electionTimeout := config.Raft.ElectionTimeout
+	if electionTimeout <= 0 {
+		electionTimeout = time.Second
+	}
+	heartbeatInterval := config.Raft.HeartbeatInterval
+	if heartbeatInterval <= 0 || heartbeatInterval >= electionTimeout {
+		heartbeatInterval = electionTimeout / 5
+	}
+	proposeTimeout := config.Raft.ProposeTimeout
+	if proposeTimeout <= 0 {
+		proposeTimeout = 5 * time.Second
+	}
+	snapshotInterval := config.Raft.SnapshotInterval
+	if snapshotInterval <= 0 {
+		snapshotInterval = time.Minute
+	}
+	snapshotThreshold := config.Raft.SnapshotThreshold
+	if snapshotThreshold == 0 {
+		snapshotThreshold = 10000
+	}
+	if transport == nil {
+		dialOption, err := DialOption(config)
+		if err != nil {
+			return nil, err
+		}
+		transport = NewGrpcRaftTransport(dialOption)
+	}
+
+	rn := &RaftNode{
+		id:                nodeID,
+		addr:              nodeAddr,
+		db:                db,
+		walService:        walService,
+		metadataService:   metadataService,
+		transport:         transport,
+		electionTimeout:   electionTimeout,
+		heartbeatInterval: heartbeatInterval,
+		proposeTimeout:    proposeTimeout,
+		snapshotDir:       filepath.Join(config.Database.BadgerDir, "snapshots"),
+		snapshotInterval:  snapshotInterval,
+		snapshotThreshold: snapshotThreshold,
+		state:             RaftFollower,
+		nextIndex:         make(map[string]uint64),
+		matchIndex:        make(map[string]uint64),
+		proposals:         make(map[uint64]*proposal),
+		replicateChans:    make(map[string]chan struct{}),
+		applyChan:         make(chan struct{}, 1),
+		stopChan:          make(chan struct{}),
+	}
+
+	for _, peer := range config.Raft.Peers {
+		if peer.ID == nodeID {
+			if peer.Addr != "" {
+				rn.addr = peer.Addr
+			}
+			continue
+		}
+		rn.peers = append(rn.peers, peer)
+	}
+
+	if err := rn.loadState(); err != nil {
+		return nil, fmt.Errorf("failed to load raft state: %v", err)
+	}
+	// 已应用的条目一定已提交
+	rn.commitIndex = rn.lastApplied
+
+	lastIndex, lastTerm := walService.LastLogIndexAndTerm()
+	log.Printf("Raft node %s initialized: term=%d, last log=%d (term %d), applied=%d, %d peers",
+		nodeID, rn.currentTerm, lastIndex, lastTerm, rn.lastApplied, len(rn.peers))
+	return rn, nil
+}
+
+// Start 启动选举计时、日志应用和定期快照
+func (rn *RaftNode) Start() {
+	rn.mu.Lock()
+	rn.resetElectionDeadlineLocked()
+	rn.mu.Unlock()
+
+	rn.wg.Add(3)
+	go rn.runLoop()
+	go rn.applyLoop()
+	go rn.snapshotLoop()
+}
+
+// Stop 停止 Raft 节点，等待中的写操作返回错误，返回前后台协程均已退出
+func (rn *RaftNode) Stop() {
+	rn.stopOnce.Do(func() {
+		close(rn.stopChan)
+
+		rn.mu.Lock()
+		rn.failProposalsLocked(fmt.Errorf("raft node stopped"))
+		rn.mu.Unlock()
+		rn.wg.Wait()
+
+		if closer, ok := rn.transport.(interface{ Close() error }); ok {
+			closer.Close()
+		}
+	})
+}
+
+// IsLeader 检查当前节点是否是leader
+func (rn *RaftNode) IsLeader() bool {
+	rn.mu.Lock()
+	defer rn.mu.Unlock()
+	return rn.state == RaftLeader
+}
+
+// IsLeaderReady 检查当前节点是否是leader且已应用当选前提交的所有日志
+func (rn *RaftNode) IsLeaderReady() bool {
+	rn.mu.Lock()
+	defer rn.mu.Unlock()
+	return rn.state == RaftLeader && rn.lastApplied >= rn.readyIndex
+}
+
+// LeaderAddr 获取当前leader地址，未知时返回空字符串
+func (rn *RaftNode) LeaderAddr() string {
+	rn.mu.Lock()
+	defer rn.mu.Unlock()
+	return rn.leaderAddr
+}
+
+// Status 获取当前角色、任期和提交索引
+func (rn *RaftNode) Status() (RaftState, uint64, uint64) {
+	rn.mu.Lock()
+	defer rn.mu.Unlock()
+	return rn.state, rn.currentTerm, rn.commitIndex
+}
+
+// RaftProgress 节点的日志进度，用于监控
+type RaftProgress struct {
+	State        RaftState
+	Term         uint64
+	LastIndex    uint64            // 本地最后一条日志的索引
+	CommitIndex  uint64            // 已提交的索引
+	AppliedIndex uint64            // 已应用到元数据的索引
+	MatchIndex   map[string]uint64 // leader 上各 follower 已复制的索引，key 为节点ID；非 leader 时为空
+}
+
+// Progress 获取当前的日志进度
+func (rn *RaftNode) Progress() RaftProgress {
+	rn.mu.Lock()
+	defer rn.mu.Unlock()
+
+	lastIndex, _ := rn.walService.LastLogIndexAndTerm()
+	progress := RaftProgress{
+		State:        rn.state,
+		Term:         rn.currentTerm,
+		LastIndex:    lastIndex,
+		CommitIndex:  rn.commitIndex,
+		AppliedIndex: rn.lastApplied,
+	}
+	if rn.state == RaftLeader {
+		progress.MatchIndex = make(map[string]uint64, len(rn.matchIndex))
+		for id, match := range rn.matchIndex {
+			progress.MatchIndex[id] = match
+		}
+	}
+	return progress
+}
+
+// GetCurrentLeader 获取当前leader信息，未知时返回nil
+func (rn *RaftNode) GetCurrentLeader() *pb.MetaServerMsg {
+	addr := rn.LeaderAddr()
+	if addr == "" {
+		return nil
+	}
+	return metaServerMsg(addr)
+}
+
+// GetFollowers 获取除leader以外的集群成员
+func (rn *RaftNode) GetFollowers() []*pb.MetaServerMsg {
+	leader := rn.LeaderAddr()
+
+	var followers []*pb.MetaServerMsg
+	if rn.addr != leader {
+		followers = append(followers, metaServerMsg(rn.addr))
+	}
+	for _, peer := range rn.peers {
+		if peer.Addr != leader {
+			followers = append(followers, metaServerMsg(peer.Addr))
+		}
+	}
+	return followers
+}
+
+// Propose 提交一个元数据操作，日志被多数节点持久化并在本地应用后返回应用结果
+func (rn *RaftNode) Propose(operation pb.WALOperationType, data interface{}) (interface{}, error) {
+	rn.mu.Lock()
+	if rn.state != RaftLeader {
+		leader := rn.leaderAddr
+		rn.mu.Unlock()
+		return nil, fmt.Errorf("not leader, current leader: %s", leader)
+	}
+
+	entry, err := rn.walService.AppendLogEntry(rn.currentTerm, operation, data)
+	if err != nil {
+		rn.mu.Unlock()
+		return nil, err
+	}
+
+	p := &proposal{term: entry.Term, result: make(chan proposalResult, 1)}
+	rn.proposals[entry.LogIndex] = p
+	rn.advanceCommitLocked()
+	rn.triggerReplicationLocked()
+	rn.mu.Unlock()
+
+	timer := time.NewTimer(rn.proposeTimeout)
+	defer timer.Stop()
+
+	select {
+	case res := <-p.result:
+		return res.value, res.err
+	case <-timer.C:
+		rn.mu.Lock()
+		delete(rn.proposals, entry.LogIndex)
+		rn.mu.Unlock()
+		return nil, fmt.Errorf("timed out waiting for log entry %d to be committed by a majority", entry.LogIndex)
+	case <-rn.stopChan:
+		return nil, fmt.Errorf("raft node stopped")
+	}
+}
+
+// HandleRequestVote 处理候选人的投票请求
+func (rn *RaftNode) HandleRequestVote(req *pb.RequestVoteRequest) (*pb.RequestVoteResponse, error) {
+	rn.mu.Lock()
+	defer rn.mu.Unlock()
+
+	if req.Term > rn.currentTerm {
+		if err := rn.becomeFollowerLocked(req.Term, "", ""); err != nil {
+			return nil, err
+		}
+	}
+
+	resp := &pb.RequestVoteResponse{Term: rn.currentTerm}
+	if req.Term < rn.currentTerm {
+		return resp, nil
+	}
+
+	// 只投票给日志至少和自己一样新的候选人
+	lastIndex, lastTerm := rn.walService.LastLogIndexAndTerm()
+	upToDate := req.LastLogTerm > lastTerm || (req.LastLogTerm == lastTerm && req.LastLogIndex >= lastIndex)
+
+	if (rn.votedFor == "" || rn.votedFor == req.CandidateId) && upToDate {
+		rn.votedFor = req.CandidateId
+		if err := rn.persistStateLocked(); err != nil {
+			return nil, err
+		}
+		rn.resetElectionDeadlineLocked()
+		resp.VoteGranted = true
+		log.Printf("Raft: %s voted for %s in term %d", rn.id, req.CandidateId, req.Term)
+	}
+	return resp, nil
+}
+
+// HandleAppendEntries 处理leader的日志复制和心跳
+func (rn *RaftNode) HandleAppendEntries(req *pb.AppendEntriesRequest) (*pb.AppendEntriesResponse, error) {
+	rn.mu.Lock()
+	defer rn.mu.Unlock()
+
+	lastIndex, _ := rn.walService.LastLogIndexAndTerm()
+	resp := &pb.AppendEntriesResponse{Term: rn.currentTerm, LastLogIndex: lastIndex}
+	if req.Term < rn.currentTerm {
+		return resp, nil
+	}
+
+	if req.Term > rn.currentTerm || rn.state != RaftFollower {
+		if err := rn.becomeFollowerLocked(req.Term, req.LeaderId, req.LeaderAddr); err != nil {
+			return nil, err
+		}
+	}
+	rn.leaderID = req.LeaderId
+	rn.leaderAddr = req.LeaderAddr
+	rn.resetElectionDeadlineLocked()
+	resp.Term = rn.currentTerm
+
+	// 快照包含的条目都已提交，一定与leader一致，从快照位置开始检查
+	prevIndex, prevLogTerm := req.PrevLogIndex, req.PrevLogTerm
+	entries := req.Entries
+	if snapshotIndex, snapshotTerm := rn.walService.SnapshotIndexAndTerm(); prevIndex < snapshotIndex {
+		for len(entries) > 0 && entries[0].LogIndex <= snapshotIndex {
+			entries = entries[1:]
+		}
+		prevIndex, prevLogTerm = snapshotIndex, snapshotTerm
+	}
+
+	// 一致性检查：本地必须有 prev_log_index 处任期相同的条目
+	if prevIndex > lastIndex {
+		return resp, nil
+	}
+	prevTerm, err := rn.walService.TermAt(prevIndex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read log entry %d: %v", prevIndex, err)
+	}
+	if prevTerm != prevLogTerm {
+		resp.LastLogIndex = prevIndex - 1
+		return resp, nil
+	}
+
+	// 跳过已有的条目，遇到任期冲突时删除本地该位置及之后的条目
+	for len(entries) > 0 && entries[0].LogIndex <= lastIndex {
+		term, err := rn.walService.TermAt(entries[0].LogIndex)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read log entry %d: %v", entries[0].LogIndex, err)
+		}
+		if term != entries[0].Term {
+			if entries[0].LogIndex <= rn.commitIndex {
+				return nil, fmt.Errorf("leader %s conflicts with committed entry %d", req.LeaderId, entries[0].LogIndex)
+			}
+			if err := rn.walService.TruncateFrom(entries[0].LogIndex); err != nil {
+				return nil, err
+			}
+			break
+		}
+		entries = entries[1:]
+	}
+	if err := rn.walService.StoreEntries(entries); err != nil {
+		return nil, err
+	}
+
+	lastNew := req.PrevLogIndex + uint64(len(req.Entries))
+	if req.LeaderCommit > rn.commitIndex {
+		commit := req.LeaderCommit
+		if lastNew < commit {
+			commit = lastNew
+		}
+		if commit > rn.commitIndex {
+			rn.commitIndex = commit
+			rn.signalApply()
+		}
+	}
+
+	resp.Success = true
+	resp.LastLogIndex, _ = rn.walService.LastLogIndexAndTerm()
+	return resp, nil
+}
+
+// runLoop 选举超时后发起选举
+func (rn *RaftNode) runLoop() {
+	defer rn.wg.Done()
+	tick := rn.heartbeatInterval / 2
+	if tick < 10*time.Millisecond {
+		tick = 10 * time.Millisecond
+	}
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			rn.mu.Lock()
+			expired := rn.state != RaftLeader && time.Now().After(rn.electionDeadline)
+			rn.mu.Unlock()
+			if expired {
+				rn.startElection()
+			}
+		case <-rn.stopChan:
+			return
+		}
+	}
+}
+
+// startElection 进入新任期并向其他节点请求投票
+func (rn *RaftNode) startElection() {
+	rn.mu.Lock()
+	rn.state = RaftCandidate
+	rn.currentTerm++
+	rn.votedFor = rn.id
+	rn.leaderID = ""
+	rn.leaderAddr = ""
+	rn.resetElectionDeadlineLocked()
+	if err := rn.persistStateLocked(); err != nil {
+		log.Printf("Raft: %s failed to persist state for election: %v", rn.id, err)
+		rn.mu.Unlock()
+		return
+	}
+
+	term := rn.currentTerm
+	lastIndex, lastTerm := rn.walService.LastLogIndexAndTerm()
+	log.Printf("Raft: %s starting election for term %d (last log %d, term %d)", rn.id, term, lastIndex, lastTerm)
+
+	votes := 1
+	if votes >= rn.quorum() {
+		rn.becomeLeaderLocked()
+		rn.mu.Unlock()
+		return
+	}
+	rn.mu.Unlock()
+
+	req := &pb.RequestVoteRequest{
+		Term:         term,
+		CandidateId:  rn.id,
+		LastLogIndex: lastIndex,
+		LastLogTerm:  lastTerm,
+	}
+	for _, peer := range rn.peers {
+		rn.wg.Add(1)
+		go func(peer model.RaftPeer) {
+			defer rn.wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), rn.electionTimeout)
+			defer cancel()
+
+			resp, err := rn.transport.RequestVote(ctx, peer, req)
+			if err != nil {
+				return
+			}
+
+			rn.mu.Lock()
+			defer rn.mu.Unlock()
+
+			if resp.Term > rn.currentTerm {
+				if err := rn.becomeFollowerLocked(resp.Term, "", ""); err != nil {
+					log.Printf("Raft: %s failed to step down: %v", rn.id, err)
+				}
+				return
+			}
+			if rn.state != RaftCandidate || rn.currentTerm != term || !resp.VoteGranted {
+				return
+			}
+
+			votes++
+			if votes >= rn.quorum() {
+				rn.becomeLeaderLocked()
+			}
+		}(peer)
+	}
+}
+
+// becomeLeaderLocked 成为leader，调用方需持有 rn.mu
+func (rn *RaftNode) becomeLeaderLocked() {
+	rn.state = RaftLeader
+	rn.leaderID = rn.id
+	rn.leaderAddr = rn.addr
+
+	lastIndex, _ := rn.walService.LastLogIndexAndTerm()
+	rn.nextIndex = make(map[string]uint64)
+	rn.matchIndex = make(map[string]uint64)
+	for _, peer := range rn.peers {
+		rn.nextIndex[peer.ID] = lastIndex + 1
+		rn.matchIndex[peer.ID] = 0
+	}
+
+	// 之前任期的条目只能随当前任期的条目一起提交，当选后立即追加一个空条目
+	rn.readyIndex = lastIndex + 1
+	if entry, err := rn.walService.AppendLogEntry(rn.currentTerm, pb.WALOperationType_NO_OP, struct{}{}); err != nil {
+		log.Printf("Raft: %s failed to append no-op entry: %v", rn.id, err)
+	} else {
+		rn.readyIndex = entry.LogIndex
+	}
+
+	term := rn.currentTerm
+	rn.replicateChans = make(map[string]chan struct{})
+	for _, peer := range rn.peers {
+		ch := make(chan struct{}, 1)
+		rn.replicateChans[peer.ID] = ch
+		rn.wg.Add(1)
+		go rn.replicateLoop(peer, term, ch)
+	}
+	rn.advanceCommitLocked()
+
+	log.Printf("Raft: %s became leader for term %d", rn.id, term)
+}
+
+// becomeFollowerLocked 转为follower，任期增大时清空投票，调用方需持有 rn.mu
+func (rn *RaftNode) becomeFollowerLocked(term uint64, leaderID, leaderAddr string) error {
+	wasLeader := rn.state == RaftLeader
+	rn.state = RaftFollower
+	rn.leaderID = leaderID
+	rn.leaderAddr = leaderAddr
+
+	if term > rn.currentTerm {
+		rn.currentTerm = term
+		rn.votedFor = ""
+		if err := rn.persistStateLocked(); err != nil {
+			return err
+		}
+	}
+
+	if wasLeader {
+		log.Printf("Raft: %s stepped down in term %d", rn.id, rn.currentTerm)
+		rn.resetElectionDeadlineLocked()
+		rn.failProposalsLocked(fmt.Errorf("leadership lost before the operation was committed"))
+	}
+	return nil
+}
+
+// replicateLoop leader向单个follower复制日志，没有新条目时按心跳间隔发送空请求
+func (rn *RaftNode) replicateLoop(peer model.RaftPeer, term uint64, notify chan struct{}) {
+	defer rn.wg.Done()
+	ticker := time.NewTicker(rn.heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		more, active := rn.replicateTo(peer, term)
+		if !active {
+			return
+		}
+		if more {
+			continue
+		}
+
+		select {
+		case <-ticker.C:
+		case <-notify:
+		case <-rn.stopChan:
+			return
+		}
+	}
+}
+
+// replicateTo 发送一次 AppendEntries，follower需要的条目已被快照删除时改为发送快照
+// more 表示还有需要立即发送的条目，active 为 false 表示已不再是该任期的leader
+func (rn *RaftNode) replicateTo(peer model.RaftPeer, term uint64) (more bool, active bool) {
+	rn.mu.Lock()
+	if rn.state != RaftLeader || rn.currentTerm != term {
+		rn.mu.Unlock()
+		return false, false
+	}
+
+	next := rn.nextIndex[peer.ID]
+	if snapshotIndex, _ := rn.walService.SnapshotIndexAndTerm(); next <= snapshotIndex {
+		rn.mu.Unlock()
+		return rn.sendSnapshot(peer, term)
+	}
+
+	prevIndex := next - 1
+	prevTerm, err := rn.walService.TermAt(prevIndex)
+	if err == ErrLogCompacted {
+		// 刚生成了新快照，下一轮发送快照
+		rn.mu.Unlock()
+		return true, true
+	}
+	if err != nil {
+		rn.mu.Unlock()
+		log.Printf("Raft: failed to read log entry %d for %s: %v", prevIndex, peer.ID, err)
+		return false, true
+	}
+
+	var entries []*pb.LogEntry
+	lastIndex, _ := rn.walService.LastLogIndexAndTerm()
+	if next <= lastIndex {
+		entries, err = rn.walService.GetLogEntriesFrom(next, maxAppendEntries)
+		if err != nil {
+			rn.mu.Unlock()
+			log.Printf("Raft: failed to read log entries from %d for %s: %v", next, peer.ID, err)
+			return false, true
+		}
+		if len(entries) > 0 && entries[0].LogIndex != next {
+			// 读取时日志被快照截断
+			rn.mu.Unlock()
+			return true, true
+		}
+	}
+
+	req := &pb.AppendEntriesRequest{
+		Term:         term,
+		LeaderId:     rn.id,
+		LeaderAddr:   rn.addr,
+		PrevLogIndex: prevIndex,
+		PrevLogTerm:  prevTerm,
+		Entries:      entries,
+		LeaderCommit: rn.commitIndex,
+	}
+	rn.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), rn.electionTimeout)
+	resp, err := rn.transport.AppendEntries(ctx, peer, req)
+	cancel()
+	if err != nil {
+		return false, true
+	}
+
+	rn.mu.Lock()
+	defer rn.mu.Unlock()
+
+	if resp.Term > rn.currentTerm {
+		if err := rn.becomeFollowerLocked(resp.Term, "", ""); err != nil {
+			log.Printf("Raft: %s failed to step down: %v", rn.id, err)
+		}
+		return false, false
+	}
+	if rn.state != RaftLeader || rn.currentTerm != term {
+		return false, false
+	}
+
+	if resp.Success {
+		match := prevIndex + uint64(len(entries))
+		if match > rn.matchIndex[peer.ID] {
+			rn.matchIndex[peer.ID] = match
+		}
+		rn.nextIndex[peer.ID] = match + 1
+		rn.advanceCommitLocked()
+
+		lastIndex, _ := rn.walService.LastLogIndexAndTerm()
+		return match < lastIndex, true
+	}
+
+	// 日志不匹配，按follower返回的最后索引回退
+	next--
+	if resp.LastLogIndex+1 < next {
+		next = resp.LastLogIndex + 1
+	}
+	if next < 1 {
+		next = 1
+	}
+	rn.nextIndex[peer.ID] = next
+	return true, true
+}
+
+// advanceCommitLocked 提交已被多数节点持久化的当前任期条目，调用方需持有 rn.mu
+func (rn *RaftNode) advanceCommitLocked() {
+	lastIndex, _ := rn.walService.LastLogIndexAndTerm()
+	for n := lastIndex; n > rn.commitIndex; n-- {
+		term, err := rn.walService.TermAt(n)
+		if err != nil || term != rn.currentTerm {
+			return
+		}
+
+		count := 1
+		for _, peer := range rn.peers {
+			if rn.matchIndex[peer.ID] >= n {
+				count++
+			}
+		}
+		if count >= rn.quorum() {
+			rn.commitIndex = n
+			rn.signalApply()
+			return
+		}
+	}
+}
+
+// applyLoop 按日志顺序将已提交的条目应用到元数据
+func (rn *RaftNode) applyLoop() {
+	defer rn.wg.Done()
+	for {
+		select {
+		case <-rn.applyChan:
+			rn.applyCommitted()
+		case <-rn.stopChan:
+			return
+		}
+	}
+}
+
+// applyCommitted 应用所有已提交但未应用的条目，并通知等待的写操作
+func (rn *RaftNode) applyCommitted() {
+	for {
+		rn.applyMu.Lock()
+		rn.mu.Lock()
+		if rn.lastApplied >= rn.commitIndex {
+			rn.mu.Unlock()
+			rn.applyMu.Unlock()
+			return
+		}
+		index := rn.lastApplied + 1
+		rn.mu.Unlock()
+
+		entry, err := rn.walService.GetLogEntry(index)
+		if err != nil {
+			rn.applyMu.Unlock()
+			log.Printf("Raft: failed to read committed entry %d: %v", index, err)
+			return
+		}
+
+		// 应用结果在所有节点上相同，操作被拒绝（如路径已存在）时错误返回给写操作的调用方
+		// 元数据修改与已应用索引在同一事务中写入；被拒绝或不修改元数据的条目单独记录索引
+		rn.metadataService.beginApply(index)
+		value, applyErr := rn.walService.ApplyLogEntry(entry, rn.metadataService)
+		persisted := rn.metadataService.endApply()
+		if applyErr != nil {
+			log.Printf("Raft: entry %d (%v) rejected: %v", index, entry.Operation, applyErr)
+		}
+		if !persisted {
+			if err := rn.persistApplied(index); err != nil {
+				log.Printf("Raft: failed to persist applied index %d: %v", index, err)
+			}
+		}
+
+		rn.mu.Lock()
+		rn.lastApplied = index
+		p := rn.proposals[index]
+		delete(rn.proposals, index)
+		rn.mu.Unlock()
+		rn.applyMu.Unlock()
+
+		if p != nil {
+			if p.term != entry.Term {
+				p.result <- proposalResult{err: fmt.Errorf("log entry %d was replaced by a new leader", index)}
+			} else {
+				p.result <- proposalResult{value: value, err: applyErr}
+			}
+		}
+	}
+}
+
+// failProposalsLocked 让所有等待中的写操作返回错误，调用方需持有 rn.mu
+func (rn *RaftNode) failProposalsLocked(err error) {
+	for index, p := range rn.proposals {
+		p.result <- proposalResult{err: err}
+		delete(rn.proposals, index)
+	}
+}
+
+// triggerReplicationLocked 通知所有复制协程立即发送新条目，调用方需持有 rn.mu
+func (rn *RaftNode) triggerReplicationLocked() {
+	for _, ch := range rn.replicateChans {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// signalApply 通知应用协程
+func (rn *RaftNode) signalApply() {
+	select {
+	case rn.applyChan <- struct{}{}:
+	default:
+	}
+}
+
+// resetElectionDeadlineLocked 重置选举超时，超时时间在 [t, 2t) 内随机，调用方需持有 rn.mu
+func (rn *RaftNode) resetElectionDeadlineLocked() {
+	timeout := rn.electionTimeout + time.Duration(rand.Int63n(int64(rn.electionTimeout)))
+	rn.electionDeadline = time.Now().Add(timeout)
+}
+
+// quorum 多数派节点数
+func (rn *RaftNode) quorum() int {
+	return (len(rn.peers)+1)/2 + 1
+}
+
+// loadState 从BadgerDB加载任期、投票和已应用索引
+func (rn *RaftNode) loadState() error {
+	return rn.db.View(func(txn *badger.Txn) error {
+		if item, err := txn.Get([]byte(model.RaftTermKey)); err == nil {
+			if err := item.Value(func(val []byte) error {
+				rn.currentTerm = binary.BigEndian.Uint64(val)
+				return nil
+			}); err != nil {
+				return err
+			}
+		} else if err != badger.ErrKeyNotFound {
+			return err
+		}
+
+		if item, err := txn.Get([]byte(model.RaftVoteKey)); err == nil {
+			if err := item.Value(func(val []byte) error {
+				rn.votedFor = string(val)
+				return nil
+			}); err != nil {
+				return err
+			}
+		} else if err != badger.ErrKeyNotFound {
+			return err
+		}
+
+		if item, err := txn.Get([]byte(model.RaftAppliedKey)); err == nil {
+			return item.Value(func(val []byte) error {
+				rn.lastApplied = binary.BigEndian.Uint64(val)
+				return nil
+			})
+		} else if err != badger.ErrKeyNotFound {
+			return err
+		}
+		return nil
+	})
+}
+
+// persistStateLocked 持久化任期和投票，必须在响应其他节点之前完成，调用方需持有 rn.mu
+func (rn *RaftNode) persistStateLocked() error {
+	return rn.db.Update(func(txn *badger.Txn) error {
+		buf := make([]byte, 8)
+		binary.BigEndian.PutUint64(buf, rn.currentTerm)
+		if err := txn.Set([]byte(model.RaftTermKey), buf); err != nil {
+			return err
+		}
+		return txn.Set([]byte(model.RaftVoteKey), []byte(rn.votedFor))
+	})
+}
+
+// persistApplied 持久化已应用的日志索引，重启后不再重复应用
+func (rn *RaftNode) persistApplied(index uint64) error {
+	return rn.db.Update(func(txn *badger.Txn) error {
+		buf := make([]byte, 8)
+		binary.BigEndian.PutUint64(buf, index)
+		return txn.Set([]byte(model.RaftAppliedKey), buf)
+	})
+}
+
+// metaServerMsg 将 "host:port" 转换为 MetaServerMsg
+func metaServerMsg(addr string) *pb.MetaServerMsg {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return &pb.MetaServerMsg{Host: addr}
+	}
+	port, _ := strconv.Atoi(portStr)
+	return &pb.MetaServerMsg{Host: host, Port: int32(port)}
+}
+
+// GrpcRaftTransport 通过 gRPC 调用其他 MetaServer 的 Raft 接口
+type GrpcRaftTransport struct {
+	mu         sync.Mutex
+	conns      map[string]*grpc.ClientConn // addr -> conn
+	dialOption grpc.DialOption             // 传输凭证，开启 TLS 时使用证书
+}
+
+// NewGrpcRaftTransport 创建 gRPC Raft 通信
+func NewGrpcRaftTransport(dialOption grpc.DialOption) *GrpcRaftTransport {
+	return &GrpcRaftTransport{conns: make(map[string]*grpc.ClientConn), dialOption: dialOption}
+}
+
+// client 获取到指定节点的客户端，连接按地址复用
+func (t *GrpcRaftTransport) client(addr string) (pb.MetaServerServiceClient, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	conn, exists := t.conns[addr]
+	if !exists {
+		var err error
+		conn, err = grpc.Dial(addr, t.dialOption)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to %s: %v", addr, err)
+		}
+		t.conns[addr] = conn
+	}
+	return pb.NewMetaServerServiceClient(conn), nil
+}
+
+// RequestVote 向节点请求投票
+func (t *GrpcRaftTransport) RequestVote(ctx context.Context, peer model.RaftPeer, req *pb.RequestVoteRequest) (*pb.RequestVoteResponse, error) {
+	client, err := t.client(peer.Addr)
+	if err != nil {
+		return nil, err
+	}
+	return client.RequestVote(ctx, req)
+}
+
+// AppendEntries 向节点追加日志条目
+func (t *GrpcRaftTransport) AppendEntries(ctx context.Context, peer model.RaftPeer, req *pb.AppendEntriesRequest) (*pb.AppendEntriesResponse, error) {
+	client, err := t.client(peer.Addr)
+	if err != nil {
+		return nil, err
+	}
+	return client.AppendEntries(ctx, req)
+}
+
+// Close 关闭所有连接
+func (t *GrpcRaftTransport) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for addr, conn := range t.conns {
+		conn.Close()
+		delete(t.conns, addr)
+	}
+	return nil
+}
+
+// InstallSnapshot 分块发送快照，第一个分块携带快照元信息
+func (t *GrpcRaftTransport) InstallSnapshot(ctx context.Context, peer model.RaftPeer, req *pb.InstallSnapshotRequest, data io.Reader) (*pb.InstallSnapshotResponse, error) {
+	client, err := t.client(peer.Addr)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := client.InstallSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, snapshotChunkSize)
+	first := true
+	for {
+		n, readErr := io.ReadFull(data, buf)
+		if n > 0 || first {
+			chunk := &pb.InstallSnapshotRequest{Data: append([]byte(nil), buf[:n]...)}
+			if first {
+				chunk.Term = req.Term
+				chunk.LeaderId = req.LeaderId
+				chunk.LeaderAddr = req.LeaderAddr
+				chunk.LastIncludedIndex = req.LastIncludedIndex
+				chunk.LastIncludedTerm = req.LastIncludedTerm
+				first = false
+			}
+			if err := stream.Send(chunk); err != nil {
+				return nil, fmt.Errorf("failed to send snapshot chunk: %v", err)
+			}
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			return nil, fmt.Errorf("failed to read snapshot: %v", readErr)
+		}
+	}
+	return stream.CloseAndRecv()
+}
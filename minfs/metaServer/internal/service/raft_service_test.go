@@ -0,0 +1,341 @@
+package service
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"metaServer/internal/model"
+	"metaServer/pb"
+
+	"github.com/dgraph-io/badger/v3"
+)
+
+// testNetwork 进程内的 Raft 网络，可以断开节点模拟宕机和网络分区
+type testNetwork struct {
+	mu    sync.Mutex
+	nodes map[string]*RaftNode
+	down  map[string]bool
+}
+
+// testTransport 从某个节点出发的进程内通信
+type testTransport struct {
+	net  *testNetwork
+	from string
+}
+
+func (t *testTransport) target(peer model.RaftPeer) (*RaftNode, error) {
+	t.net.mu.Lock()
+	defer t.net.mu.Unlock()
+
+	if t.net.down[t.from] || t.net.down[peer.ID] {
+		return nil, fmt.Errorf("%s is unreachable from %s", peer.ID, t.from)
+	}
+	return t.net.nodes[peer.ID], nil
+}
+
+func (t *testTransport) RequestVote(ctx context.Context, peer model.RaftPeer, req *pb.RequestVoteRequest) (*pb.RequestVoteResponse, error) {
+	node, err := t.target(peer)
+	if err != nil {
+		return nil, err
+	}
+	return node.HandleRequestVote(req)
+}
+
+func (t *testTransport) AppendEntries(ctx context.Context, peer model.RaftPeer, req *pb.AppendEntriesRequest) (*pb.AppendEntriesResponse, error) {
+	node, err := t.target(peer)
+	if err != nil {
+		return nil, err
+	}
+	return node.HandleAppendEntries(req)
+}
+
+func (t *testTransport) InstallSnapshot(ctx context.Context, peer model.RaftPeer, req *pb.InstallSnapshotRequest, data io.Reader) (*pb.InstallSnapshotResponse, error) {
+	node, err := t.target(peer)
+	if err != nil {
+		return nil, err
+	}
+	return node.HandleInstallSnapshot(req, data)
+}
+
+func (n *testNetwork) setDown(id string, down bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.down[id] = down
+}
+
+type testMetaServer struct {
+	id       string
+	raft     *RaftNode
+	metadata *MetadataService
+	wal      *WALService
+}
+
+// newTestCluster 启动三个使用内存 BadgerDB 的 MetaServer
+func newTestCluster(t *testing.T) (*testNetwork, []*testMetaServer) {
+	t.Helper()
+
+	network := &testNetwork{nodes: make(map[string]*RaftNode), down: make(map[string]bool)}
+	ids := []string{"meta-1", "meta-2", "meta-3"}
+
+	baseDir := t.TempDir()
+	config := &model.Config{}
+	config.Cluster.DefaultReplication = 3
+	config.Raft.ElectionTimeout = 150 * time.Millisecond
+	config.Raft.HeartbeatInterval = 30 * time.Millisecond
+	config.Raft.ProposeTimeout = time.Second
+	for _, id := range ids {
+		config.Raft.Peers = append(config.Raft.Peers, model.RaftPeer{ID: id, Addr: id + ":9090"})
+	}
+
+	var servers []*testMetaServer
+	for _, id := range ids {
+		nodeConfig := *config
+		nodeConfig.Database.BadgerDir = filepath.Join(baseDir, id)
+
+		db, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+		if err != nil {
+			t.Fatalf("open badger: %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+
+		walService := NewWALService(db, &nodeConfig, id)
+		metadataService := NewMetadataService(db, &nodeConfig, walService)
+		if err := metadataService.CreateNodeWithInode("/", pb.FileType_Directory, nil, 0, model.Ownership{}, time.Now().UnixMilli()); err != nil {
+			t.Fatalf("create root: %v", err)
+		}
+
+		raftNode, err := NewRaftNode(&nodeConfig, id, id+":9090", db, walService, metadataService, &testTransport{net: network, from: id})
+		if err != nil {
+			t.Fatalf("new raft node: %v", err)
+		}
+		walService.SetRaftNode(raftNode)
+		metadataService.SetRaftNode(raftNode)
+
+		network.nodes[id] = raftNode
+		servers = append(servers, &testMetaServer{id: id, raft: raftNode, metadata: metadataService, wal: walService})
+	}
+
+	for _, server := range servers {
+		server.raft.Start()
+		t.Cleanup(server.raft.Stop)
+	}
+	return network, servers
+}
+
+// waitForLeader 等待 servers 中选出唯一的leader
+func waitForLeader(t *testing.T, servers []*testMetaServer) *testMetaServer {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		var leaders []*testMetaServer
+		for _, server := range servers {
+			if server.raft.IsLeader() {
+				leaders = append(leaders, server)
+			}
+		}
+		if len(leaders) == 1 {
+			return leaders[0]
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("no leader elected")
+	return nil
+}
+
+// waitForNode 等待节点上出现指定路径，返回其 inode
+func waitForNode(t *testing.T, server *testMetaServer, path string) uint64 {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if nodeInfo, err := server.metadata.GetNodeInfo(path); err == nil {
+			return nodeInfo.Inode
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("%s: %s was not replicated", server.id, path)
+	return 0
+}
+
+func TestRaftReplicatesCommittedWrites(t *testing.T) {
+	_, servers := newTestCluster(t)
+	leader := waitForLeader(t, servers)
+
+	if err := leader.metadata.CreateNode("/dir", pb.FileType_Directory); err != nil {
+		t.Fatalf("create /dir: %v", err)
+	}
+	if err := leader.metadata.CreateNode("/dir/file", pb.FileType_File); err != nil {
+		t.Fatalf("create /dir/file: %v", err)
+	}
+
+	// 已提交的写操作在leader上立即可见
+	want, err := leader.metadata.GetNodeInfo("/dir/file")
+	if err != nil {
+		t.Fatalf("leader lost /dir/file: %v", err)
+	}
+	// 修改时间由 leader 决定，所有节点应用后相同
+	for _, server := range servers {
+		if inode := waitForNode(t, server, "/dir/file"); inode != want.Inode {
+			t.Errorf("%s: inode %d, want %d", server.id, inode, want.Inode)
+		}
+		if got, _ := server.metadata.GetNodeInfo("/dir/file"); got.GetMtime() != want.Mtime {
+			t.Errorf("%s: mtime %d, want %d", server.id, got.GetMtime(), want.Mtime)
+		}
+	}
+
+	// 状态机拒绝的操作把错误返回给调用方
+	if err := leader.metadata.CreateNode("/dir", pb.FileType_Directory); err == nil {
+		t.Errorf("creating an existing path succeeded")
+	}
+
+	// 已应用的索引随元数据修改持久化，被拒绝的条目也会记录
+	var persisted uint64
+	if err := leader.raft.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(model.RaftAppliedKey))
+		if err != nil {
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			persisted = binary.BigEndian.Uint64(val)
+			return nil
+		})
+	}); err != nil {
+		t.Fatalf("read applied index: %v", err)
+	}
+	if applied := leader.raft.Progress().AppliedIndex; persisted != applied {
+		t.Errorf("persisted applied index %d, want %d", persisted, applied)
+	}
+
+	for _, server := range servers {
+		if server != leader {
+			if err := server.metadata.CreateNode("/other", pb.FileType_Directory); err == nil {
+				t.Errorf("%s accepted a write as follower", server.id)
+			}
+		}
+	}
+}
+
+func TestRaftWriteFailsWithoutQuorum(t *testing.T) {
+	network, servers := newTestCluster(t)
+	leader := waitForLeader(t, servers)
+
+	for _, server := range servers {
+		if server != leader {
+			network.setDown(server.id, true)
+		}
+	}
+
+	if err := leader.metadata.CreateNode("/lost", pb.FileType_Directory); err == nil {
+		t.Fatalf("write succeeded without a majority")
+	}
+	if _, err := leader.metadata.GetNodeInfo("/lost"); err == nil {
+		t.Fatalf("uncommitted write was applied")
+	}
+}
+
+func TestRaftFailoverKeepsCommittedWrites(t *testing.T) {
+	network, servers := newTestCluster(t)
+	oldLeader := waitForLeader(t, servers)
+
+	if err := oldLeader.metadata.CreateNode("/before", pb.FileType_Directory); err != nil {
+		t.Fatalf("create /before: %v", err)
+	}
+
+	// 隔离旧leader，它的写操作无法提交
+	network.setDown(oldLeader.id, true)
+	if err := oldLeader.metadata.CreateNode("/orphan", pb.FileType_Directory); err == nil {
+		t.Fatalf("isolated leader committed a write")
+	}
+
+	var rest []*testMetaServer
+	for _, server := range servers {
+		if server != oldLeader {
+			rest = append(rest, server)
+		}
+	}
+	newLeader := waitForLeader(t, rest)
+	waitForNode(t, newLeader, "/before")
+
+	if err := newLeader.metadata.CreateNode("/after", pb.FileType_Directory); err != nil {
+		t.Fatalf("create /after on new leader: %v", err)
+	}
+
+	// 旧leader恢复后丢弃未提交的条目并追上新leader的日志
+	network.setDown(oldLeader.id, false)
+	waitForNode(t, oldLeader, "/after")
+	if _, err := oldLeader.metadata.GetNodeInfo("/orphan"); err == nil {
+		t.Errorf("uncommitted entry from the old leader survived")
+	}
+	if oldLeader.raft.IsLeader() {
+		t.Errorf("stale leader did not step down")
+	}
+}
+
+func TestRaftLaggingFollowerInstallsSnapshot(t *testing.T) {
+	network, servers := newTestCluster(t)
+	leader := waitForLeader(t, servers)
+
+	var lagging *testMetaServer
+	for _, server := range servers {
+		if server != leader {
+			lagging = server
+			break
+		}
+	}
+	network.setDown(lagging.id, true)
+
+	for i := 0; i < 20; i++ {
+		if err := leader.metadata.CreateNode(fmt.Sprintf("/dir%d", i), pb.FileType_Directory); err != nil {
+			t.Fatalf("create /dir%d: %v", i, err)
+		}
+	}
+	if _, err := leader.metadata.DeleteNode("/dir0", false); err != nil {
+		t.Fatalf("delete /dir0: %v", err)
+	}
+
+	// 快照之后leader不再保留之前的日志
+	if err := leader.raft.TakeSnapshot(); err != nil {
+		t.Fatalf("take snapshot: %v", err)
+	}
+	snapshotIndex, _ := leader.wal.SnapshotIndexAndTerm()
+	if snapshotIndex == 0 {
+		t.Fatalf("snapshot index was not recorded")
+	}
+	if _, err := leader.wal.GetLogEntry(1); err == nil {
+		t.Errorf("log entry 1 survived compaction")
+	}
+	if _, err := leader.wal.GetAllLogEntries(1); err == nil {
+		t.Errorf("WAL sync from a compacted index succeeded")
+	}
+
+	if err := leader.metadata.CreateNode("/tail", pb.FileType_Directory); err != nil {
+		t.Fatalf("create /tail: %v", err)
+	}
+
+	// 落后的follower先安装快照，再只接收快照之后的日志
+	network.setDown(lagging.id, false)
+	waitForNode(t, lagging, "/tail")
+	want, _ := leader.metadata.GetNodeInfo("/dir19")
+	if inode := waitForNode(t, lagging, "/dir19"); inode != want.Inode {
+		t.Errorf("/dir19: inode %d, want %d", inode, want.Inode)
+	}
+	if _, err := lagging.metadata.GetNodeInfo("/dir0"); err == nil {
+		t.Errorf("deleted /dir0 was restored from the snapshot")
+	}
+	if index, _ := lagging.wal.SnapshotIndexAndTerm(); index != snapshotIndex {
+		t.Errorf("follower snapshot index %d, want %d", index, snapshotIndex)
+	}
+
+	// 安装快照后继续正常复制
+	if err := leader.metadata.CreateNode("/after", pb.FileType_Directory); err != nil {
+		t.Fatalf("create /after: %v", err)
+	}
+	waitForNode(t, lagging, "/after")
+}
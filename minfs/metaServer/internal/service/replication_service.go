@@ -0,0 +1,98 @@
+package service
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"metaServer/internal/model"
+	"metaServer/pb"
+
+	"github.com/dgraph-io/badger/v3"
+	"google.golang.org/protobuf/proto"
+)
+
+// defaultMaxReplication 未配置 cluster.max_replication 时单个文件允许的最大副本数
+const defaultMaxReplication = 10
+
+// MaxReplication 获取单个文件允许的最大副本数
+func (ms *MetadataService) MaxReplication() uint32 {
+	if ms.config != nil && ms.config.Cluster.MaxReplication > 0 {
+		return uint32(ms.config.Cluster.MaxReplication)
+	}
+	return defaultMaxReplication
+}
+
+// ValidateReplication 检查副本数是否在 1..MaxReplication 之间，0 表示使用默认值
+func (ms *MetadataService) ValidateReplication(replication uint32) error {
+	if maxReplication := ms.MaxReplication(); replication > maxReplication {
+		return fmt.Errorf("replication %d out of range [1, %d]", replication, maxReplication)
+	}
+	return nil
+}
+
+// SetReplication 修改文件的副本数（通过日志提交），块的副本由 FSCK 按新副本数增加或删除
+func (ms *MetadataService) SetReplication(path string, replication uint32) error {
+	if replication == 0 {
+		return fmt.Errorf("replication %d out of range [1, %d]", replication, ms.MaxReplication())
+	}
+	if err := ms.ValidateReplication(replication); err != nil {
+		return err
+	}
+	path = filepath.Clean(path)
+	if IsSnapshotPath(path) {
+		return fmt.Errorf("snapshot path is read-only: %s", path)
+	}
+
+	_, err := ms.propose(pb.WALOperationType_SET_REPLICATION, &pb.SetReplicationOperation{
+		Path:        path,
+		Replication: replication,
+	})
+	return err
+}
+
+// setReplicationInDB 修改文件的副本数（仅数据库操作，不写WAL），增加副本时检查祖先目录的空间配额
+func (ms *MetadataService) setReplicationInDB(path string, replication uint32) error {
+	path = filepath.Clean(path)
+
+	return ms.applyUpdate(func(txn *badger.Txn) error {
+		inodeID, err := ms.getInodeIDByPathInTx(txn, path)
+		if err == badger.ErrKeyNotFound {
+			return fmt.Errorf("file not found: %s", path)
+		}
+		if err != nil {
+			return err
+		}
+		nodeInfo, err := ms.getNodeInfoInTx(txn, inodeID)
+		if err != nil {
+			return err
+		}
+		if nodeInfo.Type != pb.FileType_File {
+			return fmt.Errorf("replication can only be set on a file: %s", path)
+		}
+		if nodeInfo.EcPolicy != "" {
+			return fmt.Errorf("replication cannot be set on erasure-coded file: %s", path)
+		}
+		if nodeInfo.Replication == replication {
+			return nil
+		}
+
+		oldUsage := fileUsage(nodeInfo.Size, nodeInfo.Replication, "")
+		newUsage := fileUsage(nodeInfo.Size, replication, "")
+		delta := model.DirectoryUsage{Space: newUsage.Space - oldUsage.Space}
+		if delta.Space > 0 {
+			if err := ms.checkQuotaInTx(txn, path, delta, nil); err != nil {
+				return err
+			}
+		}
+		if err := ms.addUsageInTx(txn, path, delta); err != nil {
+			return err
+		}
+
+		nodeInfo.Replication = replication
+		data, err := proto.Marshal(nodeInfo)
+		if err != nil {
+			return err
+		}
+		return txn.Set([]byte(fmt.Sprintf("%s%d", model.PrefixInode, inodeID)), data)
+	})
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"testing"
+
+	"metaServer/pb"
+)
+
+func TestSetReplication(t *testing.T) {
+	_, servers := newTestCluster(t)
+	leader := waitForLeader(t, servers)
+
+	if err := leader.metadata.CreateNode("/scratch", pb.FileType_Directory); err != nil {
+		t.Fatalf("create dir: %v", err)
+	}
+	if err := leader.metadata.CreateNodeWithReplication("/scratch/f", pb.FileType_File, 1); err != nil {
+		t.Fatalf("create file: %v", err)
+	}
+	if err := leader.metadata.CreateNodeWithReplication("/scratch/g", pb.FileType_File, 11); err == nil {
+		t.Fatalf("replication above max accepted")
+	}
+	info, err := leader.metadata.GetNodeInfo("/scratch/f")
+	if err != nil || info.Replication != 1 {
+		t.Fatalf("stat: %v, %v", info, err)
+	}
+	if err := leader.metadata.CommitFileState("/scratch/f", info.Inode, 100, "", nil); err != nil {
+		t.Fatalf("finalize: %v", err)
+	}
+
+	if err := leader.metadata.SetReplication("/scratch/f", 5); err != nil {
+		t.Fatalf("set replication: %v", err)
+	}
+	if info, _ := leader.metadata.GetNodeInfo("/scratch/f"); info.Replication != 5 {
+		t.Errorf("replication = %d, want 5", info.Replication)
+	}
+	usage, err := leader.metadata.GetQuota("/scratch")
+	if err != nil || usage.Bytes != 100 || usage.SpaceConsumed != 500 {
+		t.Errorf("usage after set replication: %+v, %v", usage, err)
+	}
+
+	for _, replication := range []uint32{0, 11} {
+		if err := leader.metadata.SetReplication("/scratch/f", replication); err == nil {
+			t.Errorf("replication %d accepted", replication)
+		}
+	}
+	if err := leader.metadata.SetReplication("/scratch", 2); err == nil {
+		t.Errorf("set replication on directory succeeded")
+	}
+
+	// 增加和删除块的副本位置
+	block := &pb.BlockLocations{BlockId: 7, Locations: []string{"ds1:8001"}}
+	if err := leader.metadata.SetBlockMapping(info.Inode, 0, block); err != nil {
+		t.Fatalf("set block mapping: %v", err)
+	}
+	if err := leader.metadata.UpdateBlockLocation(7, "", "ds2:8001"); err != nil {
+		t.Fatalf("add location: %v", err)
+	}
+	if err := leader.metadata.UpdateBlockLocation(7, "ds1:8001", ""); err != nil {
+		t.Fatalf("remove location: %v", err)
+	}
+	mappings, err := leader.metadata.GetBlockMappings(info.Inode)
+	if err != nil || len(mappings) != 1 || len(mappings[0].Locations) != 1 || mappings[0].Locations[0] != "ds2:8001" {
+		t.Errorf("block locations: %v, %v", mappings, err)
+	}
+}
@@ -28,6 +28,15 @@ type SchedulerService struct {
 	
 	// 垃圾回收相关
 	gcTicker *time.Ticker
+
+	// 回收站清理相关
+	trashTicker *time.Ticker
+
+	// 纠删码转换相关
+	ecConvertTicker    *time.Ticker
+	pendingConversions map[uint64]*ecConversion // 旧块ID -> 正在进行的转换
+	conversionMutex    sync.Mutex
+	leaseManager       *LeaseManager
 	
 	// 结束信号
 	stopChan chan bool
@@ -45,6 +54,26 @@ type SchedulerService struct {
 	concurrentRepairs int32                     // 当前并发修复数
 	maxConcurrentRepairs int32                  // 最大并发修复数
 	
+	// 孤儿块宽限期
+	orphanGracePasses int
+	orphanMinAge      time.Duration
+	orphanPasses      map[uint64]int // blockID -> 连续出现为孤儿块的 FSCK 次数
+	orphanStats       model.OrphanStats
+	orphanMutex       sync.Mutex
+	
+	// 副本放置策略
+	placementPolicy PlacementPolicy
+	
+	// 退役进度：节点地址 -> 最近一次 FSCK 时仍引用该节点的块数
+	decommissionRemaining map[string]int
+	decommissionMutex     sync.Mutex
+	
+	// 磁盘使用率均衡器，FSCK 不处理正在迁移的块
+	balancer *Balancer
+	
+	// Prometheus 指标，为 nil 时不上报
+	metrics *Metrics
+	
 	// 块ID生成相关
 	lastTimestamp int64 // 上次生成ID的时间戳
 	counter       int64 // 当前时间戳下的计数器
@@ -58,19 +87,42 @@ func NewSchedulerService(config *model.Config, clusterService *ClusterService, m
 		metadataService: metadataService,
 		stopChan:        make(chan bool),
 		repairingBlocks: make(map[uint64][]model.RepairTask),
+		pendingConversions: make(map[uint64]*ecConversion),
 		
 		// 初始化Worker Pool
 		fsckCheckQueue:       make(chan *model.FSCKCheckTask, config.Scheduler.RepairQueueSize),
 		repairTaskQueue:      make(chan *model.RepairTask, config.Scheduler.RepairQueueSize),
 		maxConcurrentRepairs: int32(config.Scheduler.MaxConcurrentRepairs),
 		
+		// 初始化孤儿块宽限期
+		orphanGracePasses: config.Scheduler.OrphanGracePasses,
+		orphanMinAge:      config.Scheduler.OrphanMinAge,
+		orphanPasses:      make(map[uint64]int),
+		
 		// 初始化ID生成相关字段
 		lastTimestamp: 0,
 		counter:       0,
 	}
 	
+	if ss.orphanGracePasses <= 0 {
+		ss.orphanGracePasses = 3
+	}
+	if ss.orphanMinAge <= 0 {
+		ss.orphanMinAge = 10 * time.Minute
+	}
+	ss.orphanStats.DryRun = config.Scheduler.OrphanDryRun
+	
+	placementPolicy, err := NewPlacementPolicy(config.Scheduler.PlacementPolicy)
+	if err != nil {
+		log.Printf("Invalid placement policy, falling back to %s: %v", PlacementRackAware, err)
+		placementPolicy, _ = NewPlacementPolicy(PlacementRackAware)
+	}
+	ss.placementPolicy = placementPolicy
+	log.Printf("Using %s replica placement policy", placementPolicy.Name())
+	
 	// 设置块复制完成回调
 	clusterService.SetReplicationCallback(ss.OnBlockReplicationComplete)
+	clusterService.SetCorruptBlockCallback(ss.OnCorruptBlocksReported)
 	
 	// 启动后台任务
 	ss.startBackgroundTasks()
@@ -93,6 +145,22 @@ func (ss *SchedulerService) startBackgroundTasks() {
 	// 启动垃圾回收
 	ss.gcTicker = time.NewTicker(ss.config.Scheduler.GCInterval)
 	go ss.gcLoop()
+
+	// 启动回收站清理
+	if ss.config.Trash.Enabled {
+		checkInterval := ss.config.Trash.CheckInterval
+		if checkInterval <= 0 {
+			checkInterval = 10 * time.Minute
+		}
+		ss.trashTicker = time.NewTicker(checkInterval)
+		go ss.trashLoop()
+	}
+
+	// 启动纠删码转换
+	if ss.config.ErasureCoding.ConvertInterval > 0 {
+		ss.ecConvertTicker = time.NewTicker(ss.config.ErasureCoding.ConvertInterval)
+		go ss.ecConvertLoop()
+	}
 	
 	log.Printf("Scheduler background tasks started (FSCK: %v, GC: %v)", 
 		ss.config.Scheduler.FSCKInterval, ss.config.Scheduler.GCInterval)
@@ -136,54 +204,94 @@ func (ss *SchedulerService) AllocateBlocks(fileSize uint64, replication int) ([]
 		logicalBlockCount = 1 // 至少分配一个块
 	}
 	
-	// 计算总的物理块数量（逻辑块数 × 副本数）
-	totalPhysicalBlocks := int(logicalBlockCount) * replication
-	
-	log.Printf("File allocation: size=%d bytes, logical blocks=%d, replication=%d, total physical blocks=%d",
-		fileSize, logicalBlockCount, replication, totalPhysicalBlocks)
-	
-	// 获取所有物理块的分布（轮询分配到所有DataServer）
-	physicalBlockAssignments, err := ss.clusterService.SelectDataServersForThreeReplica(totalPhysicalBlocks)
-	if err != nil {
-		return nil, fmt.Errorf("failed to allocate physical blocks: %v", err)
-	}
+	log.Printf("File allocation: size=%d bytes, logical blocks=%d, replication=%d, placement policy=%s",
+		fileSize, logicalBlockCount, replication, ss.placementPolicy.Name())
 	
 	var blockLocations []*pb.BlockLocations
 	
-	// 为每个逻辑块创建其副本分布
+	// 为每个逻辑块按放置策略选择副本位置
 	for i := uint64(0); i < logicalBlockCount; i++ {
-        // 生成逻辑块 ID（基于毫秒时间戳）
-        blockID, err := ss.generateBlockID()
-        if err != nil {
-            return nil, fmt.Errorf("failed to generate block ID: %v", err)
-        }
-        
-        // 为当前逻辑块分配副本位置
-        var locations []string
-        for j := 0; j < replication; j++ {
-            physicalBlockIndex := int(i)*replication + j
-            if physicalBlockIndex < len(physicalBlockAssignments) {
-                locations = append(locations, physicalBlockAssignments[physicalBlockIndex])
-            }
-        }
-        
-        blockLoc := &pb.BlockLocations{
-            BlockId:   blockID,
-            Locations: locations,
-        }
-        
-        blockLocations = append(blockLocations, blockLoc)
-        
-        log.Printf("Logical block %d (ID: %d) with %d replicas distributed at: %v",
-            i, blockID, len(locations), locations)
-    }
+		// 生成逻辑块 ID（基于毫秒时间戳）
+		blockID, err := ss.generateBlockID()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate block ID: %v", err)
+		}
+		
+		targets, err := ss.chooseTargets(replication, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to allocate block replicas: %v", err)
+		}
+		
+		var locations []string
+		for _, target := range targets {
+			locations = append(locations, target.Addr)
+		}
+		
+		blockLocations = append(blockLocations, &pb.BlockLocations{
+			BlockId:   blockID,
+			Locations: locations,
+		})
+		
+		log.Printf("Logical block %d (ID: %d) with %d replicas distributed at: %v",
+			i, blockID, len(locations), locations)
+	}
 	
-	log.Printf("Successfully allocated %d logical blocks with %d replicas each, total %d physical blocks distributed across DataServers",
-		logicalBlockCount, replication, totalPhysicalBlocks)
+	log.Printf("Successfully allocated %d logical blocks with %d replicas each",
+		logicalBlockCount, replication)
 	
 	return blockLocations, nil
 }
 
+// AllocateBlockGroups 按纠删码策略为文件分配块组，每个逻辑块编码为 k+m 个条带，放在不同的服务器上
+func (ss *SchedulerService) AllocateBlockGroups(fileSize uint64, policy string) ([]*pb.BlockLocations, error) {
+	k, m, err := ParseECPolicy(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	logicalBlockCount := (fileSize + ss.config.Scheduler.BlockSize - 1) / ss.config.Scheduler.BlockSize
+	if logicalBlockCount == 0 {
+		logicalBlockCount = 1
+	}
+
+	var groups []*pb.BlockLocations
+	for i := uint64(0); i < logicalBlockCount; i++ {
+		group, err := ss.allocateBlockGroup(policy, k+m)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, group)
+
+		log.Printf("Logical block %d (group ID: %d) with %s stripes distributed at: %v",
+			i, group.BlockId, policy, group.Locations)
+	}
+
+	return groups, nil
+}
+
+// allocateBlockGroup 为一个块组生成块组ID和各条带的块ID，并按放置策略选择条带位置
+func (ss *SchedulerService) allocateBlockGroup(policy string, stripes int) (*pb.BlockLocations, error) {
+	targets, err := ss.chooseTargets(stripes, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to allocate %s stripes: %v", policy, err)
+	}
+
+	groupID, err := ss.generateBlockID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate block ID: %v", err)
+	}
+	group := &pb.BlockLocations{BlockId: groupID, EcPolicy: policy}
+	for _, target := range targets {
+		stripeID, err := ss.generateBlockID()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate block ID: %v", err)
+		}
+		group.StripeIds = append(group.StripeIds, stripeID)
+		group.Locations = append(group.Locations, target.Addr)
+	}
+	return group, nil
+}
+
 
 // fsckLoop FSCK 循环检查
 func (ss *SchedulerService) fsckLoop() {
@@ -199,32 +307,61 @@ func (ss *SchedulerService) fsckLoop() {
 
 // runFSCK 运行文件系统检查
 func (ss *SchedulerService) runFSCK() {
-	log.Println("Starting FSCK...")
+	ss.runFSCKUnder("")
+}
+
+// runFSCKUnder 检查并修复 prefix 子树下文件的块，prefix 为空时检查整个命名空间
+// 子树检查不处理宕机和退役节点，也不检查孤儿块和只被快照引用的块，这些仍由周期性的完整 FSCK 负责
+func (ss *SchedulerService) runFSCKUnder(prefix string) {
+	// 块位置更新需要通过leader提交，follower不执行修复
+	if !ss.clusterService.IsLeader() {
+		return
+	}
+
+	if prefix != "" {
+		log.Printf("Starting FSCK under %s...", prefix)
+	} else {
+		log.Println("Starting FSCK...")
+	}
 	
 	start := time.Now()
 	repairedBlocks := 0
 	cleanedOrphanBlocks := 0
 	orphanBlocks := 0
 	underReplicatedBlocks := 0
+	missingBlocks := 0
 	redistributedBlocks := 0
 	
-	// 检查集群健康状况
+	// 检查集群健康状况，副本数较少的文件在健康节点不足默认副本数时仍然可以修复
 	healthyServers := ss.clusterService.GetHealthyDataServers()
+	if len(healthyServers) == 0 {
+		log.Printf("FSCK warning: no healthy servers")
+		return
+	}
 	if len(healthyServers) < ss.config.Cluster.DefaultReplication {
 		log.Printf("FSCK warning: only %d healthy servers, cannot maintain %d replicas", 
 			len(healthyServers), ss.config.Cluster.DefaultReplication)
-		return
 	}
 	
-	// 处理永久宕机节点的副本重分布
-	permanentlyDownServers := ss.clusterService.GetPermanentlyDownServers()
+	// 处理永久宕机节点的副本重分布，维护模式中的节点到期前不处理；子树检查不处理
+	var downServers, permanentlyDownServers []*model.DataServerInfo
+	if prefix == "" {
+		downServers = ss.clusterService.GetPermanentlyDownServers()
+	}
+	for _, server := range downServers {
+		if ss.inMaintenance(server.Addr) {
+			log.Printf("FSCK: %s is down for maintenance, skipping redistribution", server.Addr)
+			continue
+		}
+		permanentlyDownServers = append(permanentlyDownServers, server)
+	}
 	if len(permanentlyDownServers) > 0 {
 		redistributedCount := ss.handlePermanentlyDownServers(permanentlyDownServers)
 		redistributedBlocks += redistributedCount
 	}
 	
-	// 1. 获取所有应该存在的块（从元数据）
-	expectedBlocks, err := ss.getAllExpectedBlocks()
+	// 1. 获取所有应该存在的块及其目标副本数（从元数据）
+	expectedBlocks, blockReplication, stripeGroups, err := ss.collectExpectedBlocksUnder(prefix)
 	if err != nil {
 		log.Printf("FSCK error: failed to get expected blocks: %v", err)
 		return
@@ -232,6 +369,12 @@ func (ss *SchedulerService) runFSCK() {
 	
 	// 2. 获取实际存在的块（从DataServer心跳报告）
 	actualBlocks := ss.getAllActualBlocks()
+	underReplicatedBlocks, missingBlocks = replicationHealth(expectedBlocks, actualBlocks, blockReplication)
+	
+	// 迁移退役中节点上的块，结束到期的维护模式
+	if prefix == "" {
+		ss.processDataServerStates(expectedBlocks, actualBlocks, stripeGroups)
+	}
 	
 	log.Printf("FSCK: checking %d expected blocks against actual blocks from %d servers", 
 		len(expectedBlocks), len(healthyServers))
@@ -249,6 +392,11 @@ func (ss *SchedulerService) runFSCK() {
 			BlockID:           blockID,
 			ExpectedLocations: expectedLocations,
 			ActualLocations:   actualLocations,
+			Replication:       blockReplication[blockID],
+		}
+		if ref, ok := stripeGroups[blockID]; ok {
+			checkTask.Group = ref.group
+			checkTask.StripeIndex = ref.index
 		}
 		
 		// 提交任务到worker pool
@@ -265,30 +413,139 @@ func (ss *SchedulerService) runFSCK() {
 	log.Printf("FSCK: Submitted %d check tasks to worker pool", tasksSubmitted)
 	
 	// 4. 检查完全孤儿的块（只在DataServer存在，元数据中完全没有的块）
-	for blockID, actualLocations := range actualBlocks {
-		if _, exists := expectedBlocks[blockID]; !exists {
-			orphanBlocks++
-			log.Printf("FSCK: Found orphan block %d at locations: %v", blockID, actualLocations)
-			
-			// 立即清理孤儿块
-			log.Printf("FSCK: Scheduling deletion of orphan block %d", blockID)
-			ss.ScheduleBlockDeletion(blockID, actualLocations)
-			cleanedOrphanBlocks++ // 计入清理数量
-		}
+	// 新当选的leader应用完之前提交的日志前，元数据可能还缺少块映射，暂不处理
+	if prefix != "" {
+		log.Printf("FSCK: skipping orphan check for subtree %s", prefix)
+	} else if ss.clusterService.IsLeaderReady() {
+		orphanBlocks, cleanedOrphanBlocks = ss.handleOrphanBlocks(expectedBlocks, actualBlocks, time.Now())
+		ss.metrics.setOrphanBlocks(orphanBlocks)
+	} else {
+		log.Printf("FSCK: leader has not applied all committed entries yet, skipping orphan check")
 	}
 	
 	duration := time.Since(start)
+	if prefix == "" {
+		ss.metrics.observeFSCK(duration, len(expectedBlocks), underReplicatedBlocks, missingBlocks)
+	}
 	log.Printf("FSCK completed in %v: checked %d blocks, repaired %d under-replicated, found %d orphans, cleaned %d orphans, redistributed %d blocks from down servers", 
 		duration, len(expectedBlocks), repairedBlocks, orphanBlocks, cleanedOrphanBlocks, redistributedBlocks)
 	
 	if underReplicatedBlocks > 0 {
-		log.Printf("FSCK: %d blocks are under-replicated and need attention", underReplicatedBlocks)
+		log.Printf("FSCK: %d blocks are under-replicated and need attention (%d without any live replica)", underReplicatedBlocks, missingBlocks)
+	}
+}
+
+// handleOrphanBlocks 处理孤儿块，返回发现的孤儿块数和调度删除的数量
+// 客户端写完块到提交块映射之间，新块也会表现为孤儿块，因此只有连续 orphanGracePasses 次 FSCK 都是孤儿块，
+// 或按块ID中的时间戳已超过 orphanMinAge 时才删除；dry-run 模式下只报告
+func (ss *SchedulerService) handleOrphanBlocks(expectedBlocks, actualBlocks map[uint64][]string, now time.Time) (int, int) {
+	ss.orphanMutex.Lock()
+	defer ss.orphanMutex.Unlock()
+	
+	passes := make(map[uint64]int)
+	var orphans []model.OrphanBlock
+	pending := 0
+	cleaned := 0
+	// 正在转换为纠删码的块组条带在提交块映射前同样不被引用
+	converting := ss.convertingStripes()
+	
+	for blockID, actualLocations := range actualBlocks {
+		if _, exists := expectedBlocks[blockID]; exists {
+			continue
+		}
+		if converting[blockID] {
+			continue
+		}
+		
+		// 只连续计数，上一次没有出现的块重新开始
+		passes[blockID] = ss.orphanPasses[blockID] + 1
+		orphan := model.OrphanBlock{
+			BlockID:   blockID,
+			Locations: actualLocations,
+			Passes:    passes[blockID],
+			Age:       -1,
+		}
+		if created, ok := blockIDTime(blockID); ok {
+			orphan.Age = now.Sub(created)
+		}
+		orphan.Eligible = orphan.Passes >= ss.orphanGracePasses || orphan.Age >= ss.orphanMinAge
+		orphans = append(orphans, orphan)
+		
+		if !orphan.Eligible {
+			pending++
+			log.Printf("FSCK: Found orphan block %d at locations %v (pass %d/%d, age %v), keeping for now",
+				blockID, actualLocations, orphan.Passes, ss.orphanGracePasses, orphan.Age)
+			continue
+		}
+		if ss.orphanStats.DryRun {
+			ss.orphanStats.Reported++
+			log.Printf("FSCK: [dry-run] orphan block %d at locations %v would be deleted (pass %d, age %v)",
+				blockID, actualLocations, orphan.Passes, orphan.Age)
+			continue
+		}
+		
+		log.Printf("FSCK: Scheduling deletion of orphan block %d at locations %v (pass %d, age %v)",
+			blockID, actualLocations, orphan.Passes, orphan.Age)
+		ss.ScheduleBlockDeletion(blockID, actualLocations)
+		ss.orphanStats.Deleted++
+		cleaned++
 	}
+	
+	sort.Slice(orphans, func(i, j int) bool {
+		return orphans[i].BlockID < orphans[j].BlockID
+	})
+	ss.orphanPasses = passes
+	ss.orphanStats.LastPassTime = now
+	ss.orphanStats.LastPassFound = len(orphans)
+	ss.orphanStats.Pending = pending
+	ss.orphanStats.Orphans = orphans
+	
+	return len(orphans), cleaned
+}
+
+// GetOrphanStats 获取孤儿块处理统计
+func (ss *SchedulerService) GetOrphanStats() model.OrphanStats {
+	ss.orphanMutex.Lock()
+	defer ss.orphanMutex.Unlock()
+	
+	stats := ss.orphanStats
+	stats.Orphans = append([]model.OrphanBlock(nil), ss.orphanStats.Orphans...)
+	return stats
+}
+
+// blockIDTime 从块ID中解析生成时间，不是 generateBlockID 格式的块ID返回 false
+func blockIDTime(blockID uint64) (time.Time, bool) {
+	millis := int64(blockID / 1000)
+	// 2020-01-01 之前的时间戳不可能由 generateBlockID 生成
+	if millis < 1577836800000 {
+		return time.Time{}, false
+	}
+	return time.UnixMilli(millis), true
 }
 
 // getAllExpectedBlocks 从元数据中获取所有应该存在的块及其位置
 func (ss *SchedulerService) getAllExpectedBlocks() (map[uint64][]string, error) {
+	expectedBlocks, _, _, err := ss.collectExpectedBlocks()
+	return expectedBlocks, err
+}
+
+// stripeRef 纠删码条带所属的块组及其在块组中的下标
+type stripeRef struct {
+	group *pb.BlockLocations
+	index int
+}
+
+// collectExpectedBlocks 从元数据中获取所有应该存在的块及其位置，以及块所属文件的目标副本数
+// 只被快照引用的块和纠删码条带没有目标副本数；纠删码块组按条带展开，并返回条带所属的块组
+func (ss *SchedulerService) collectExpectedBlocks() (map[uint64][]string, map[uint64]int, map[uint64]stripeRef, error) {
+	return ss.collectExpectedBlocksUnder("")
+}
+
+// collectExpectedBlocksUnder 同 collectExpectedBlocks，prefix 不为空时只收集该子树下的文件，不包含只被快照引用的块
+func (ss *SchedulerService) collectExpectedBlocksUnder(prefix string) (map[uint64][]string, map[uint64]int, map[uint64]stripeRef, error) {
 	expectedBlocks := make(map[uint64][]string)
+	blockReplication := make(map[uint64]int)
+	stripeGroups := make(map[uint64]stripeRef)
 	
 	log.Println("FSCK: Collecting expected blocks from metadata...")
 	
@@ -315,6 +572,9 @@ func (ss *SchedulerService) getAllExpectedBlocks() (map[uint64][]string, error)
 				if nodeInfo.Type != pb.FileType_File {
 					return nil
 				}
+				if prefix != "" && !inSubtree(nodeInfo.Path, prefix) {
+					return nil
+				}
 				
 				// 获取该文件的块映射
 				blockMappings, err := ss.metadataService.GetBlockMappings(nodeInfo.Inode)
@@ -325,7 +585,15 @@ func (ss *SchedulerService) getAllExpectedBlocks() (map[uint64][]string, error)
 				
 				// 添加到期望块列表中
 				for _, blockMapping := range blockMappings {
+					if blockMapping.EcPolicy != "" {
+						for i, stripe := range PhysicalBlocks(blockMapping) {
+							expectedBlocks[stripe.BlockID] = stripe.Locations
+							stripeGroups[stripe.BlockID] = stripeRef{group: blockMapping, index: i}
+						}
+						continue
+					}
 					expectedBlocks[blockMapping.BlockId] = blockMapping.Locations
+					blockReplication[blockMapping.BlockId] = int(nodeInfo.Replication)
 				}
 				
 				return nil
@@ -340,11 +608,27 @@ func (ss *SchedulerService) getAllExpectedBlocks() (map[uint64][]string, error)
 	})
 	
 	if err != nil {
-		return nil, fmt.Errorf("failed to traverse metadata: %v", err)
+		return nil, nil, nil, fmt.Errorf("failed to traverse metadata: %v", err)
+	}
+
+	if prefix != "" {
+		log.Printf("FSCK: Found %d expected blocks under %s", len(expectedBlocks), prefix)
+		return expectedBlocks, blockReplication, stripeGroups, nil
+	}
+
+	// 只被快照引用的块同样需要保留并维持副本数
+	snapshotBlocks, err := ss.metadataService.GetSnapshotBlocks()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to collect snapshot blocks: %v", err)
+	}
+	for blockID, locations := range snapshotBlocks {
+		if _, exists := expectedBlocks[blockID]; !exists {
+			expectedBlocks[blockID] = locations
+		}
 	}
 	
 	log.Printf("FSCK: Found %d expected blocks in metadata", len(expectedBlocks))
-	return expectedBlocks, nil
+	return expectedBlocks, blockReplication, stripeGroups, nil
 }
 
 // getAllActualBlocks 从DataServer心跳报告中获取实际存在的块
@@ -445,6 +729,7 @@ func (ss *SchedulerService) runGC() {
 	}
 	
 	if len(gcEntries) == 0 {
+		ss.metrics.observeGC(0, 0)
 		return
 	}
 	
@@ -452,6 +737,20 @@ func (ss *SchedulerService) runGC() {
 	confirmedCount := 0
 	
 	for _, entry := range gcEntries {
+		// 被快照引用的块仍然有效，由快照删除时重新加入队列
+		referenced, err := ss.metadataService.IsSnapshotBlock(entry.BlockID)
+		if err != nil {
+			log.Printf("GC error: failed to check snapshot references of block %d: %v", entry.BlockID, err)
+			continue
+		}
+		if referenced {
+			log.Printf("GC: block %d is referenced by a snapshot, skipping", entry.BlockID)
+			if err := ss.metadataService.RemoveGCEntry(entry.BlockID); err != nil {
+				log.Printf("GC error: failed to remove GC entry for block %d: %v", entry.BlockID, err)
+			}
+			continue
+		}
+
 		switch entry.Status {
 		case "pending":
 			// 发送删除命令
@@ -481,11 +780,51 @@ func (ss *SchedulerService) runGC() {
 		}
 	}
 	
+	ss.metrics.observeGC(len(gcEntries), confirmedCount)
 	if sentCount > 0 || confirmedCount > 0 {
 		log.Printf("GC: sent %d delete commands, confirmed %d deletions", sentCount, confirmedCount)
 	}
 }
 
+// trashLoop 回收站清理循环
+func (ss *SchedulerService) trashLoop() {
+	for {
+		select {
+		case <-ss.trashTicker.C:
+			ss.purgeTrash()
+		case <-ss.stopChan:
+			return
+		}
+	}
+}
+
+// purgeTrash 彻底删除超过保留时间的回收站目录，此时才将其中的块加入垃圾回收队列
+func (ss *SchedulerService) purgeTrash() {
+	if !ss.clusterService.IsLeader() {
+		return
+	}
+
+	checkpoints, err := ss.metadataService.ExpiredTrashCheckpoints(ss.config.Trash.Retention, time.Now())
+	if err != nil {
+		log.Printf("Trash error: failed to list expired checkpoints: %v", err)
+		return
+	}
+
+	for _, checkpoint := range checkpoints {
+		blocks, err := ss.metadataService.DeleteNode(checkpoint, true)
+		if err != nil {
+			log.Printf("Trash error: failed to purge %s: %v", checkpoint, err)
+			continue
+		}
+		for _, block := range blocks {
+			if err := ss.metadataService.AddGCEntry(block.BlockID, block.Locations); err != nil {
+				log.Printf("Trash error: failed to add GC entry for block %d: %v", block.BlockID, err)
+			}
+		}
+		log.Printf("Trash: purged %s (%d blocks queued for GC)", checkpoint, len(blocks))
+	}
+}
+
 // sendDeleteCommand 发送删除命令
 func (ss *SchedulerService) sendDeleteCommand(entry model.GCEntry) bool {
 	dataServerIDs := ss.clusterService.GetDataServerIDsByAddresses(entry.Locations)
@@ -537,8 +876,19 @@ func (ss *SchedulerService) checkDeletionComplete(entry model.GCEntry) bool {
 
 // ScheduleBlockDeletion 调度块删除
 func (ss *SchedulerService) ScheduleBlockDeletion(blockID uint64, locations []string) {
+	// 被快照引用的块不能删除
+	referenced, err := ss.metadataService.IsSnapshotBlock(blockID)
+	if err != nil {
+		log.Printf("Failed to check snapshot references of block %d: %v", blockID, err)
+		return
+	}
+	if referenced {
+		log.Printf("Block %d is referenced by a snapshot, not deleting", blockID)
+		return
+	}
+
 	// 添加到垃圾回收队列
-	err := ss.metadataService.AddGCEntry(blockID, locations)
+	err = ss.metadataService.AddGCEntry(blockID, locations)
 	if err != nil {
 		log.Printf("Failed to add GC entry for block %d: %v", blockID, err)
 		return
@@ -635,6 +985,14 @@ func (ss *SchedulerService) Stop() {
 	if ss.gcTicker != nil {
 		ss.gcTicker.Stop()
 	}
+
+	if ss.trashTicker != nil {
+		ss.trashTicker.Stop()
+	}
+
+	if ss.ecConvertTicker != nil {
+		ss.ecConvertTicker.Stop()
+	}
 	
 	// 发送停止信号
 	select {
@@ -657,6 +1015,11 @@ func (ss *SchedulerService) ForceFSCK() {
 	go ss.runFSCK()
 }
 
+// ForceFSCKUnder 强制检查 path 子树下的文件，path 为空或 / 时执行完整的 FSCK
+func (ss *SchedulerService) ForceFSCKUnder(path string) {
+	go ss.runFSCKUnder(FsckPrefix(path))
+}
+
 // addRepairTask 添加修复任务跟踪（普通修复）
 func (ss *SchedulerService) addRepairTask(blockID uint64, sourceAddr, targetAddr string) {
 	ss.addRepairTaskWithReplacement(blockID, sourceAddr, targetAddr, false, "")
@@ -762,6 +1125,35 @@ func (ss *SchedulerService) OnBlockReplicationComplete(blockID uint64, targetAdd
 	}
 }
 
+// OnCorruptBlocksReported 当DataServer上报校验失败的块时调用
+// 从其他健康副本复制覆盖损坏的副本，复制完成后块会重新出现在该节点的块报告中
+func (ss *SchedulerService) OnCorruptBlocksReported(dataServerAddr string, blockIDs []uint64) {
+	healthyServers := ss.clusterService.GetHealthyDataServers()
+	
+	for _, blockID := range blockIDs {
+		// 已经在修复中，避免每次心跳重复调度
+		if ss.isLocationInExpected(dataServerAddr, ss.getRepairingTargets(blockID)) {
+			continue
+		}
+		
+		var sourceAddr string
+		for _, server := range healthyServers {
+			if server.Addr != dataServerAddr && !server.IsPermanentlyDownStatus() && server.HasBlock(blockID) {
+				sourceAddr = server.Addr
+				break
+			}
+		}
+		
+		if sourceAddr == "" {
+			log.Printf("No healthy replica found to repair corrupt block %d on %s", blockID, dataServerAddr)
+			continue
+		}
+		
+		log.Printf("Repairing corrupt block %d on %s from %s", blockID, dataServerAddr, sourceAddr)
+		ss.ScheduleBlockReplication(blockID, sourceAddr, []string{dataServerAddr})
+	}
+}
+
 // handlePermanentlyDownServers 处理永久宕机服务器的副本重分布
 func (ss *SchedulerService) handlePermanentlyDownServers(downServers []*model.DataServerInfo) int {
 	redistributedCount := 0
@@ -863,33 +1255,44 @@ func (ss *SchedulerService) redistributeBlockFromDownServer(blockID uint64, down
 
 // selectBestTargetServer 为副本重分布选择最佳目标服务器
 func (ss *SchedulerService) selectBestTargetServer(excludeAddrs []string) (*model.DataServerInfo, error) {
-	healthyServers := ss.clusterService.GetHealthyDataServers()
-	
-	// 过滤掉已经包含该块的服务器
-	excludeSet := make(map[string]bool)
-	for _, addr := range excludeAddrs {
-		excludeSet[addr] = true
+	targets, err := ss.chooseTargets(1, excludeAddrs)
+	if err != nil {
+		return nil, fmt.Errorf("no suitable target servers available: %v", err)
 	}
-	
-	var candidateServers []*model.DataServerInfo
-	for _, server := range healthyServers {
-		if !excludeSet[server.Addr] && !server.IsPermanentlyDownStatus() {
-			candidateServers = append(candidateServers, server)
-		}
+	return targets[0], nil
+}
+
+// isServerUsable 服务器是否健康且未被标记为永久宕机
+func (ss *SchedulerService) isServerUsable(addr string) bool {
+	ds := ss.clusterService.GetDataServerByAddr(addr)
+	if ds == nil {
+		return false
 	}
-	
-	if len(candidateServers) == 0 {
-		return nil, fmt.Errorf("no suitable target servers available")
+	_, _, _, _, isHealthy := ds.GetStatus()
+	return isHealthy && !ds.IsPermanentlyDownStatus()
+}
+
+// chooseTargets 按放置策略选择 count 个服务器，existingAddrs 为该块已有副本的位置
+func (ss *SchedulerService) chooseTargets(count int, existingAddrs []string) ([]*model.DataServerInfo, error) {
+	existingSet := make(map[string]bool)
+	for _, addr := range existingAddrs {
+		existingSet[addr] = true
+	}
+	
+	// 退役中、已退役和维护中的节点不接收新块
+	states := ss.dataServerStates()
+	now := time.Now()
+	
+	var candidates, existing []*model.DataServerInfo
+	for _, server := range ss.clusterService.GetHealthyDataServers() {
+		if existingSet[server.Addr] {
+			existing = append(existing, server)
+		} else if !server.IsPermanentlyDownStatus() && effectiveDataServerState(states, server.Addr, now) == model.DataServerInService {
+			candidates = append(candidates, server)
+		}
 	}
 	
-	// 选择块数量最少的服务器（负载均衡）
-	sort.Slice(candidateServers, func(i, j int) bool {
-		blockCountI, _, _, _, _ := candidateServers[i].GetStatus()
-		blockCountJ, _, _, _, _ := candidateServers[j].GetStatus()
-		return blockCountI < blockCountJ
-	})
-	
-	return candidateServers[0], nil
+	return ss.placementPolicy.ChooseTargets(candidates, existing, count)
 }
 
 // startFSCKWorkerPool 启动FSCK检查工作协程池
@@ -974,9 +1377,34 @@ func (ss *SchedulerService) processFSCKCheckTask(task *model.FSCKCheckTask, work
 	expectedLocations := task.ExpectedLocations
 	actualLocations := task.ActualLocations
 	
+	// 正在迁移的块在迁移完成前会多出一个副本，由均衡器负责删除源副本
+	if ss.balancer != nil && ss.balancer.isMoving(blockID) {
+		return
+	}
+	
 	// 检查副本不足的情况
 	missingLocations := ss.findMissingReplicas(expectedLocations, actualLocations)
 	if len(missingLocations) > 0 {
+		// 维护中的节点重启期间不上报块，到期前不重新复制
+		var lost []string
+		for _, addr := range missingLocations {
+			if !ss.inMaintenance(addr) {
+				lost = append(lost, addr)
+			}
+		}
+		if len(lost) == 0 {
+			return
+		}
+		missingLocations = lost
+		
+		// 纠删码条带只有一个位置，由同一块组的其他条带重建
+		if task.Group != nil {
+			log.Printf("Worker %d: Stripe %d of group %d is missing from %s",
+				workerID, blockID, task.Group.BlockId, missingLocations[0])
+			ss.rebuildStripe(task)
+			return
+		}
+		
 		log.Printf("Worker %d: Block %d is under-replicated, missing from %d servers: %v", 
 			workerID, blockID, len(missingLocations), missingLocations)
 		
@@ -995,9 +1423,18 @@ func (ss *SchedulerService) processFSCKCheckTask(task *model.FSCKCheckTask, work
 		return
 	}
 	
-	// 只有在副本数正常的情况下，才检查并清理孤儿块
+	// 副本数与文件的目标副本数不一致时增加或删除副本
+	if task.Replication > 0 && len(expectedLocations) != task.Replication {
+		log.Printf("Worker %d: Block %d has %d replicas, file replication is %d", 
+			workerID, blockID, len(expectedLocations), task.Replication)
+		ss.adjustReplicaCount(blockID, expectedLocations, task.Replication)
+		return
+	}
+	
+	// 只有在副本数正常的情况下，才检查并清理孤儿块；正在复制到的目标节点在元数据更新前也不在期望位置中
+	repairingTargets := ss.getRepairingTargets(blockID)
 	for _, location := range actualLocations {
-		if !ss.isLocationInExpected(location, expectedLocations) {
+		if !ss.isLocationInExpected(location, expectedLocations) && !ss.isLocationInExpected(location, repairingTargets) {
 			log.Printf("Worker %d: Found orphan block %d at %s, scheduling deletion", workerID, blockID, location)
 			// 立即删除这个孤儿副本
 			ss.ScheduleBlockDeletion(blockID, []string{location})
@@ -1005,6 +1442,110 @@ func (ss *SchedulerService) processFSCKCheckTask(task *model.FSCKCheckTask, work
 	}
 }
 
+// adjustReplicaCount 按文件的目标副本数为块增加或删除副本
+// 增加时先把新位置写入元数据再下发 COPY_BLOCK，复制完成前 FSCK 将其视为正在修复的副本；
+// 删除时先从元数据中移除位置再下发 DELETE_BLOCK
+func (ss *SchedulerService) adjustReplicaCount(blockID uint64, locations []string, replication int) {
+	if len(locations) < replication {
+		if len(ss.getRepairingTargets(blockID)) > 0 {
+			return
+		}
+		
+		var sourceAddr string
+		for _, addr := range locations {
+			if ds := ss.clusterService.GetDataServerByAddr(addr); ds != nil {
+				_, _, _, _, isHealthy := ds.GetStatus()
+				if isHealthy && !ds.IsPermanentlyDownStatus() {
+					sourceAddr = addr
+					break
+				}
+			}
+		}
+		if sourceAddr == "" {
+			log.Printf("No healthy source found to add replicas of block %d", blockID)
+			return
+		}
+		
+		targets, err := ss.chooseTargets(replication-len(locations), locations)
+		if err != nil {
+			log.Printf("Cannot find targets to add replicas of block %d: %v", blockID, err)
+			return
+		}
+		for _, target := range targets {
+			if err := ss.metadataService.UpdateBlockLocation(blockID, "", target.Addr); err != nil {
+				log.Printf("Failed to add location %s to block %d: %v", target.Addr, blockID, err)
+				continue
+			}
+			ss.ScheduleBlockReplication(blockID, sourceAddr, []string{target.Addr})
+		}
+		return
+	}
+	
+	// 快照中的映射与文件共享同一组副本，被快照引用时保留多余的副本
+	referenced, err := ss.metadataService.IsSnapshotBlock(blockID)
+	if err != nil {
+		log.Printf("Failed to check snapshot references of block %d: %v", blockID, err)
+		return
+	}
+	if referenced {
+		return
+	}
+	
+	replicas := make([]*model.DataServerInfo, len(locations))
+	for i, addr := range locations {
+		replicas[i] = ss.clusterService.GetDataServerByAddr(addr)
+	}
+	for _, i := range selectExcessReplicas(replicas, len(locations)-replication) {
+		addr := locations[i]
+		if err := ss.metadataService.UpdateBlockLocation(blockID, addr, ""); err != nil {
+			log.Printf("Failed to remove location %s from block %d: %v", addr, blockID, err)
+			continue
+		}
+		log.Printf("Removing excess replica of block %d from %s", blockID, addr)
+		ss.ScheduleBlockDeletion(blockID, []string{addr})
+	}
+}
+
+// rebuildStripe 重建缺失的纠删码条带：原节点健康时在原节点重建，否则选择块组中尚未使用的节点并先更新元数据
+// 重建命令下发给目标节点，由其读取任意 k 个条带解码；重建完成后条带出现在该节点的块报告中
+func (ss *SchedulerService) rebuildStripe(task *model.FSCKCheckTask) {
+	stripeID := task.BlockID
+	if len(ss.getRepairingTargets(stripeID)) > 0 {
+		return
+	}
+
+	group := proto.Clone(task.Group).(*pb.BlockLocations)
+	oldAddr := group.Locations[task.StripeIndex]
+	targetAddr := oldAddr
+	if !ss.isServerUsable(oldAddr) {
+		targets, err := ss.chooseTargets(1, group.Locations)
+		if err != nil {
+			log.Printf("Cannot find target to rebuild stripe %d of group %d: %v", stripeID, group.BlockId, err)
+			return
+		}
+		targetAddr = targets[0].Addr
+		if err := ss.metadataService.UpdateBlockLocation(stripeID, oldAddr, targetAddr); err != nil {
+			log.Printf("Failed to move stripe %d of group %d from %s to %s: %v", stripeID, group.BlockId, oldAddr, targetAddr, err)
+			return
+		}
+		group.Locations[task.StripeIndex] = targetAddr
+	}
+
+	targetDS := ss.clusterService.GetDataServerByAddr(targetAddr)
+	if targetDS == nil {
+		log.Printf("Target DataServer not found for address %s", targetAddr)
+		return
+	}
+	ss.addRepairTask(stripeID, "", targetAddr)
+	ss.clusterService.SendCommand(targetDS.ID, &model.Command{
+		Action:      "RECONSTRUCT_STRIPE",
+		BlockID:     stripeID,
+		Group:       group,
+		StripeIndex: task.StripeIndex,
+	})
+	log.Printf("Stripe %d (index %d) of group %d rebuild scheduled on %s", stripeID, task.StripeIndex, group.BlockId, targetAddr)
+}
+
 // processRepairTask 处理修复任务
 func (ss *SchedulerService) processRepairTask(task *model.RepairTask, workerID int) {
 	atomic.AddInt32(&ss.concurrentRepairs, 1)
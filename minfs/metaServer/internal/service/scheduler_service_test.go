@@ -0,0 +1,55 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"metaServer/internal/model"
+)
+
+func TestOrphanGracePeriod(t *testing.T) {
+	ss := &SchedulerService{
+		orphanGracePasses: 3,
+		orphanMinAge:      10 * time.Minute,
+		orphanPasses:      make(map[uint64]int),
+		orphanStats:       model.OrphanStats{DryRun: true},
+	}
+
+	now := time.Now()
+	fresh := uint64(now.UnixMilli())*1000 + 1
+	old := uint64(now.Add(-time.Hour).UnixMilli())*1000 + 1
+	legacy := uint64(42)
+	expected := map[uint64][]string{7: {"ds1"}}
+	actual := map[uint64][]string{7: {"ds1"}, fresh: {"ds1"}, old: {"ds2"}, legacy: {"ds3"}}
+
+	eligible := func() map[uint64]bool {
+		result := make(map[uint64]bool)
+		for _, orphan := range ss.GetOrphanStats().Orphans {
+			result[orphan.BlockID] = orphan.Eligible
+		}
+		return result
+	}
+
+	found, cleaned := ss.handleOrphanBlocks(expected, actual, now)
+	if found != 3 || cleaned != 0 {
+		t.Fatalf("pass 1: found %d, cleaned %d", found, cleaned)
+	}
+	if e := eligible(); e[fresh] || !e[old] || e[legacy] {
+		t.Fatalf("pass 1 eligibility: %v", e)
+	}
+
+	// 连续出现才计数，中间消失后重新开始
+	delete(actual, legacy)
+	ss.handleOrphanBlocks(expected, actual, now)
+	actual[legacy] = []string{"ds3"}
+	ss.handleOrphanBlocks(expected, actual, now)
+	e := eligible()
+	if !e[fresh] || e[legacy] {
+		t.Fatalf("pass 3 eligibility: %v", e)
+	}
+
+	stats := ss.GetOrphanStats()
+	if stats.Pending != 1 || stats.Reported != 4 || stats.Deleted != 0 {
+		t.Errorf("stats: pending=%d reported=%d deleted=%d", stats.Pending, stats.Reported, stats.Deleted)
+	}
+}
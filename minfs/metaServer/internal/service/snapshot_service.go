@@ -0,0 +1,379 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"metaServer/internal/model"
+	"metaServer/pb"
+
+	"github.com/dgraph-io/badger/v3"
+	bpb "github.com/dgraph-io/badger/v3/pb"
+)
+
+const (
+	// snapshotChunkSize InstallSnapshot 每个分块的大小，低于 gRPC 默认的 4MB 消息上限
+	snapshotChunkSize = 1 << 20
+	// installSnapshotTimeout 向单个follower发送快照的超时
+	installSnapshotTimeout = 5 * time.Minute
+	// badgerBitDelete Badger Backup 中删除标记的 meta 位
+	badgerBitDelete byte = 1 << 0
+)
+
+// snapshotLoop 定期检查距上次快照应用的日志条数，超过阈值时生成新快照
+func (rn *RaftNode) snapshotLoop() {
+	defer rn.wg.Done()
+	ticker := time.NewTicker(rn.snapshotInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			rn.mu.Lock()
+			applied := rn.lastApplied
+			rn.mu.Unlock()
+
+			snapshotIndex, _ := rn.walService.SnapshotIndexAndTerm()
+			if applied >= snapshotIndex+rn.snapshotThreshold {
+				if err := rn.TakeSnapshot(); err != nil {
+					log.Printf("Raft: %s failed to take snapshot: %v", rn.id, err)
+				}
+			}
+		case <-rn.stopChan:
+			return
+		}
+	}
+}
+
+// TakeSnapshot 将已应用的元数据写入快照文件，并删除快照覆盖的日志条目
+func (rn *RaftNode) TakeSnapshot() error {
+	// 生成快照期间暂停应用日志，快照内容与 lastApplied 处的元数据一致
+	rn.applyMu.Lock()
+	rn.mu.Lock()
+	index := rn.lastApplied
+	rn.mu.Unlock()
+
+	snapshotIndex, _ := rn.walService.SnapshotIndexAndTerm()
+	if index <= snapshotIndex {
+		rn.applyMu.Unlock()
+		return nil
+	}
+	term, err := rn.walService.TermAt(index)
+	if err != nil {
+		rn.applyMu.Unlock()
+		return fmt.Errorf("failed to read log entry %d: %v", index, err)
+	}
+
+	start := time.Now()
+	path, err := rn.writeSnapshot(index, term)
+	rn.applyMu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	// 快照文件落盘后才能删除日志
+	if err := rn.walService.CompactTo(index, term); err != nil {
+		return err
+	}
+	rn.removeSnapshotsExcept(path)
+
+	log.Printf("Raft: %s took snapshot at index %d (term %d) in %v", rn.id, index, term, time.Since(start))
+	return nil
+}
+
+// HandleInstallSnapshot 处理leader发送的快照，用快照替换本地元数据
+func (rn *RaftNode) HandleInstallSnapshot(req *pb.InstallSnapshotRequest, data io.Reader) (*pb.InstallSnapshotResponse, error) {
+	rn.mu.Lock()
+	if req.Term < rn.currentTerm {
+		resp := &pb.InstallSnapshotResponse{Term: rn.currentTerm}
+		rn.mu.Unlock()
+		return resp, nil
+	}
+	if req.Term > rn.currentTerm || rn.state != RaftFollower {
+		if err := rn.becomeFollowerLocked(req.Term, req.LeaderId, req.LeaderAddr); err != nil {
+			rn.mu.Unlock()
+			return nil, err
+		}
+	}
+	rn.leaderID = req.LeaderId
+	rn.leaderAddr = req.LeaderAddr
+	rn.resetElectionDeadlineLocked()
+	resp := &pb.InstallSnapshotResponse{Term: rn.currentTerm}
+	rn.mu.Unlock()
+
+	log.Printf("Raft: %s receiving snapshot %d (term %d) from %s", rn.id, req.LastIncludedIndex, req.LastIncludedTerm, req.LeaderId)
+
+	// 接收期间持续重置选举超时，避免传输大快照时发起选举
+	path, err := rn.receiveSnapshot(req.LastIncludedIndex, req.LastIncludedTerm, &electionResetReader{rn: rn, r: data})
+	if err != nil {
+		return nil, err
+	}
+	if err := rn.installSnapshot(path, req.LastIncludedIndex, req.LastIncludedTerm); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// sendSnapshot leader向follower发送最近的快照，成功后从快照之后的日志继续复制
+func (rn *RaftNode) sendSnapshot(peer model.RaftPeer, term uint64) (more bool, active bool) {
+	index, snapshotTerm := rn.walService.SnapshotIndexAndTerm()
+	file, err := os.Open(rn.snapshotPath(index, snapshotTerm))
+	if err != nil {
+		log.Printf("Raft: failed to open snapshot %d for %s: %v", index, peer.ID, err)
+		return false, true
+	}
+	defer file.Close()
+
+	req := &pb.InstallSnapshotRequest{
+		Term:              term,
+		LeaderId:          rn.id,
+		LeaderAddr:        rn.addr,
+		LastIncludedIndex: index,
+		LastIncludedTerm:  snapshotTerm,
+	}
+	log.Printf("Raft: %s sending snapshot %d (term %d) to %s", rn.id, index, snapshotTerm, peer.ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), installSnapshotTimeout)
+	resp, err := rn.transport.InstallSnapshot(ctx, peer, req, bufio.NewReader(file))
+	cancel()
+	if err != nil {
+		log.Printf("Raft: failed to send snapshot %d to %s: %v", index, peer.ID, err)
+		return false, true
+	}
+
+	rn.mu.Lock()
+	defer rn.mu.Unlock()
+
+	if resp.Term > rn.currentTerm {
+		if err := rn.becomeFollowerLocked(resp.Term, "", ""); err != nil {
+			log.Printf("Raft: %s failed to step down: %v", rn.id, err)
+		}
+		return false, false
+	}
+	if rn.state != RaftLeader || rn.currentTerm != term {
+		return false, false
+	}
+
+	if index > rn.matchIndex[peer.ID] {
+		rn.matchIndex[peer.ID] = index
+	}
+	rn.nextIndex[peer.ID] = index + 1
+	rn.advanceCommitLocked()
+
+	lastIndex, _ := rn.walService.LastLogIndexAndTerm()
+	return index < lastIndex, true
+}
+
+// receiveSnapshot 将收到的快照写入快照目录
+func (rn *RaftNode) receiveSnapshot(index, term uint64, data io.Reader) (string, error) {
+	if err := os.MkdirAll(rn.snapshotDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create snapshot directory: %v", err)
+	}
+
+	path := rn.snapshotPath(index, term)
+	return path, writeFileAtomic(path, func(w io.Writer) error {
+		_, err := io.Copy(w, data)
+		return err
+	})
+}
+
+// installSnapshot 用快照替换本地元数据，并丢弃快照覆盖的日志
+func (rn *RaftNode) installSnapshot(path string, index, term uint64) error {
+	rn.applyMu.Lock()
+	defer rn.applyMu.Unlock()
+
+	rn.mu.Lock()
+	applied := rn.lastApplied
+	rn.mu.Unlock()
+	if index <= applied {
+		log.Printf("Raft: %s ignored snapshot %d, already applied up to %d", rn.id, index, applied)
+		return nil
+	}
+
+	if err := rn.restoreSnapshot(path, index); err != nil {
+		return fmt.Errorf("failed to restore snapshot %d: %v", index, err)
+	}
+	if err := rn.walService.CompactTo(index, term); err != nil {
+		return err
+	}
+
+	rn.mu.Lock()
+	rn.lastApplied = index
+	if rn.commitIndex < index {
+		rn.commitIndex = index
+	}
+	rn.mu.Unlock()
+	rn.removeSnapshotsExcept(path)
+
+	log.Printf("Raft: %s installed snapshot %d (term %d)", rn.id, index, term)
+	return nil
+}
+
+// writeSnapshot 使用 Badger Stream 导出元数据键空间，调用方需持有 rn.applyMu
+func (rn *RaftNode) writeSnapshot(index, term uint64) (string, error) {
+	if err := os.MkdirAll(rn.snapshotDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create snapshot directory: %v", err)
+	}
+
+	path := rn.snapshotPath(index, term)
+	err := writeFileAtomic(path, func(w io.Writer) error {
+		stream := rn.db.NewStream()
+		stream.LogPrefix = "Raft.Snapshot"
+		stream.ChooseKey = func(item *badger.Item) bool {
+			return isSnapshotKey(item.Key())
+		}
+		_, err := stream.Backup(w, 0)
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to write snapshot %d: %v", index, err)
+	}
+	return path, nil
+}
+
+// restoreSnapshot 清空元数据键空间并载入快照中每个键的最新版本，已应用索引随数据一起写入
+func (rn *RaftNode) restoreSnapshot(path string, index uint64) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var prefixes [][]byte
+	for _, prefix := range model.SnapshotPrefixes {
+		prefixes = append(prefixes, []byte(prefix))
+	}
+	if err := rn.db.DropPrefix(prefixes...); err != nil {
+		return fmt.Errorf("failed to drop metadata: %v", err)
+	}
+
+	batch := rn.db.NewWriteBatch()
+	defer batch.Cancel()
+
+	// Backup 格式：小端 uint64 长度 + KVList，同一个键的各版本从新到旧相邻排列
+	reader := bufio.NewReader(file)
+	var lastKey []byte
+	for {
+		var size uint64
+		if err := binary.Read(reader, binary.LittleEndian, &size); err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		buf := make([]byte, size)
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return err
+		}
+		var list bpb.KVList
+		if err := list.Unmarshal(buf); err != nil {
+			return err
+		}
+
+		for _, kv := range list.Kv {
+			if bytes.Equal(kv.Key, lastKey) {
+				continue
+			}
+			lastKey = kv.Key
+			if len(kv.Meta) > 0 && kv.Meta[0]&badgerBitDelete != 0 {
+				continue
+			}
+			if err := batch.Set(kv.Key, kv.Value); err != nil {
+				return err
+			}
+		}
+	}
+
+	applied := make([]byte, 8)
+	binary.BigEndian.PutUint64(applied, index)
+	if err := batch.Set([]byte(model.RaftAppliedKey), applied); err != nil {
+		return err
+	}
+	return batch.Flush()
+}
+
+// snapshotPath 快照文件路径
+func (rn *RaftNode) snapshotPath(index, term uint64) string {
+	return filepath.Join(rn.snapshotDir, fmt.Sprintf("snapshot-%020d-%d", index, term))
+}
+
+// removeSnapshotsExcept 删除除 keep 以外的快照文件（包括未完成的临时文件）
+func (rn *RaftNode) removeSnapshotsExcept(keep string) {
+	files, err := os.ReadDir(rn.snapshotDir)
+	if err != nil {
+		return
+	}
+	for _, file := range files {
+		path := filepath.Join(rn.snapshotDir, file.Name())
+		if path == keep {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			log.Printf("Raft: failed to remove old snapshot %s: %v", path, err)
+		}
+	}
+}
+
+// isSnapshotKey 检查键是否属于快照包含的元数据键空间
+func isSnapshotKey(key []byte) bool {
+	for _, prefix := range model.SnapshotPrefixes {
+		if strings.HasPrefix(string(key), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeFileAtomic 先写临时文件并同步到磁盘，再重命名为目标文件
+func writeFileAtomic(path string, write func(w io.Writer) error) error {
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(file)
+	if err := write(w); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// electionResetReader 每读到一段快照数据就重置选举超时
+type electionResetReader struct {
+	rn *RaftNode
+	r  io.Reader
+}
+
+func (e *electionResetReader) Read(p []byte) (int, error) {
+	n, err := e.r.Read(p)
+	e.rn.mu.Lock()
+	e.rn.resetElectionDeadlineLocked()
+	e.rn.mu.Unlock()
+	return n, err
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"metaServer/internal/model"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// loadTLSConfig 根据配置加载证书和 CA，server 为 true 时按 client_auth 要求客户端证书
+func loadTLSConfig(config model.TLSConfig, server bool) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if config.CertFile != "" || config.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tls key pair: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	} else if server {
+		return nil, fmt.Errorf("tls is enabled but cert_file or key_file is missing")
+	}
+
+	if config.CAFile != "" {
+		data, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tls ca file: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates found in %s", config.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+		tlsConfig.ClientCAs = pool
+	}
+
+	if server && config.ClientAuth {
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
+// ServerOptions gRPC 服务端的传输安全选项，未开启 TLS 时为空
+func ServerOptions(config *model.Config) ([]grpc.ServerOption, error) {
+	if !config.Security.TLS.Enabled {
+		return nil, nil
+	}
+	tlsConfig, err := loadTLSConfig(config.Security.TLS, true)
+	if err != nil {
+		return nil, err
+	}
+	return []grpc.ServerOption{grpc.Creds(credentials.NewTLS(tlsConfig))}, nil
+}
+
+// DialOption 连接其他节点使用的传输凭证，未开启 TLS 时为明文
+func DialOption(config *model.Config) (grpc.DialOption, error) {
+	if config == nil || !config.Security.TLS.Enabled {
+		return grpc.WithTransportCredentials(insecure.NewCredentials()), nil
+	}
+	tlsConfig, err := loadTLSConfig(config.Security.TLS, false)
+	if err != nil {
+		return nil, err
+	}
+	return grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)), nil
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"metaServer/internal/model"
+	"metaServer/pb"
+
+	"github.com/dgraph-io/badger/v3"
+)
+
+// TrashEnabled 删除时是否移入回收站
+func (ms *MetadataService) TrashEnabled() bool {
+	return ms.config != nil && ms.config.Trash.Enabled
+}
+
+// IsInTrash 路径是否为回收站或其中的节点，回收站中的删除不再经过回收站
+func IsInTrash(path string) bool {
+	path = filepath.Clean(path)
+	return path == model.TrashRoot || strings.HasPrefix(path, model.TrashRoot+"/")
+}
+
+// MoveToTrash 将节点移入 /.Trash/<时间>/<原路径>，保留块映射，返回回收站中的路径
+// 缺失的回收站目录逐个创建，最后一次重命名完成移动，均通过日志提交
+func (ms *MetadataService) MoveToTrash(path string, recursive bool, now time.Time) (string, error) {
+	path = filepath.Clean(path)
+	if path == "/" || path == "." {
+		return "", fmt.Errorf("cannot delete root directory")
+	}
+	if IsInTrash(path) {
+		return "", fmt.Errorf("already in trash: %s", path)
+	}
+
+	nodeInfo, err := ms.GetNodeInfo(path)
+	if err != nil {
+		return "", err
+	}
+	if nodeInfo.Type == pb.FileType_Directory && !recursive {
+		children, err := ms.ListDirectory(path)
+		if err != nil {
+			return "", err
+		}
+		if len(children) > 0 {
+			return "", fmt.Errorf("directory not empty: %s", path)
+		}
+	}
+
+	checkpoint := model.TrashRoot + "/" + now.UTC().Format(model.TrashCheckpointLayout)
+	trashPath := checkpoint + path
+	if err := ms.ensureDirectories(filepath.Dir(trashPath)); err != nil {
+		return "", fmt.Errorf("failed to create trash directory: %v", err)
+	}
+	if _, err := ms.RenameNode(path, trashPath, false); err != nil {
+		return "", err
+	}
+	return trashPath, nil
+}
+
+// TrashOriginalPath 回收站中的节点删除前的路径
+func TrashOriginalPath(trashPath string) (string, error) {
+	trashPath = filepath.Clean(trashPath)
+	rel := strings.TrimPrefix(trashPath, model.TrashRoot+"/")
+	idx := strings.Index(rel, "/")
+	if rel == trashPath || idx < 0 {
+		return "", fmt.Errorf("not a node in trash: %s", trashPath)
+	}
+	return rel[idx:], nil
+}
+
+// RestoreFromTrash 将回收站中的节点移回删除前的路径，原父目录必须存在，返回恢复后的路径
+func (ms *MetadataService) RestoreFromTrash(trashPath string) (string, error) {
+	original, err := TrashOriginalPath(trashPath)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := ms.RenameNode(trashPath, original, false); err != nil {
+		return "", err
+	}
+	return original, nil
+}
+
+// ExpiredTrashCheckpoints 返回删除时间早于 now - retention 的回收站时间目录
+func (ms *MetadataService) ExpiredTrashCheckpoints(retention time.Duration, now time.Time) ([]string, error) {
+	children, err := ms.ListDirectory(model.TrashRoot)
+	if err == badger.ErrKeyNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var expired []string
+	for _, child := range children {
+		deletedAt, err := time.ParseInLocation(model.TrashCheckpointLayout, filepath.Base(child.Path), time.UTC)
+		if err != nil {
+			// 不是回收站创建的目录
+			continue
+		}
+		if now.Sub(deletedAt) >= retention {
+			expired = append(expired, child.Path)
+		}
+	}
+	return expired, nil
+}
+
+// ensureDirectories 逐级创建缺失的回收站目录，已存在的目录直接跳过
+// 新目录属于超级用户且权限位为 0700，其他用户不能浏览或修改回收站中的内容
+func (ms *MetadataService) ensureDirectories(dir string) error {
+	current := ""
+	for _, name := range strings.Split(strings.TrimPrefix(filepath.Clean(dir), "/"), "/") {
+		if name == "" {
+			continue
+		}
+		current += "/" + name
+
+		nodeInfo, err := ms.GetNodeInfo(current)
+		if err == badger.ErrKeyNotFound {
+			ownership := model.Ownership{Owner: ms.superuser(), Group: ms.supergroup(), Mode: 0700}
+			if err := ms.createNode(current, pb.FileType_Directory, 0, ownership); err != nil {
+				// 并发的删除可能已创建同一目录
+				if nodeInfo, statErr := ms.GetNodeInfo(current); statErr != nil || nodeInfo.Type != pb.FileType_Directory {
+					return err
+				}
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if nodeInfo.Type != pb.FileType_Directory {
+			return fmt.Errorf("not a directory: %s", current)
+		}
+	}
+	return nil
+}
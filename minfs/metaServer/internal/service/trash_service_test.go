@@ -0,0 +1,82 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"metaServer/internal/model"
+	"metaServer/pb"
+
+	"github.com/dgraph-io/badger/v3"
+)
+
+func TestTrashRestoreAndExpire(t *testing.T) {
+	_, servers := newTestCluster(t)
+	leader := waitForLeader(t, servers)
+
+	for _, dir := range []string{"/proj", "/proj/src"} {
+		if err := leader.metadata.CreateNode(dir, pb.FileType_Directory); err != nil {
+			t.Fatalf("create %s: %v", dir, err)
+		}
+	}
+	writeTestFile(t, leader, "/proj/src/main.go", 10)
+
+	if _, err := leader.metadata.MoveToTrash("/proj", false, time.Now()); err == nil {
+		t.Fatalf("non-recursive delete of a non-empty directory moved to trash")
+	}
+
+	deletedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	trashPath, err := leader.metadata.MoveToTrash("/proj/src", true, deletedAt)
+	if err != nil {
+		t.Fatalf("move to trash: %v", err)
+	}
+	if want := "/.Trash/20260102-030405.000/proj/src"; trashPath != want {
+		t.Fatalf("trash path %s, want %s", trashPath, want)
+	}
+	if _, err := leader.metadata.GetNodeInfo("/proj/src"); err != badger.ErrKeyNotFound {
+		t.Fatalf("original path still exists: %v", err)
+	}
+	// 回收站目录属于超级用户，其他用户不能访问
+	for _, dir := range []string{model.TrashRoot, "/.Trash/20260102-030405.000", "/.Trash/20260102-030405.000/proj"} {
+		info, err := leader.metadata.GetNodeInfo(dir)
+		if err != nil || info.Owner != "root" || info.Mode != 0700 {
+			t.Errorf("trash directory %s: %+v, %v", dir, info, err)
+		}
+	}
+	if info, err := leader.metadata.GetNodeInfo(trashPath + "/main.go"); err != nil || info.Size != 10 {
+		t.Fatalf("trashed file: %+v, %v", info, err)
+	}
+
+	restored, err := leader.metadata.RestoreFromTrash(trashPath)
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if restored != "/proj/src" {
+		t.Errorf("restored to %s", restored)
+	}
+	if _, err := leader.metadata.GetNodeInfo("/proj/src/main.go"); err != nil {
+		t.Fatalf("restored file: %v", err)
+	}
+
+	// 过期的时间目录被彻底删除，块映射随之释放
+	if _, err := leader.metadata.MoveToTrash("/proj/src/main.go", false, deletedAt); err != nil {
+		t.Fatalf("move file to trash: %v", err)
+	}
+	if err := leader.metadata.CreateNode(model.TrashRoot+"/not-a-checkpoint", pb.FileType_Directory); err != nil {
+		t.Fatalf("create unrelated trash dir: %v", err)
+	}
+	expired, err := leader.metadata.ExpiredTrashCheckpoints(time.Hour, deletedAt.Add(30*time.Minute))
+	if err != nil || len(expired) != 0 {
+		t.Fatalf("expired before retention: %v, %v", expired, err)
+	}
+	expired, err = leader.metadata.ExpiredTrashCheckpoints(time.Hour, deletedAt.Add(2*time.Hour))
+	if err != nil || len(expired) != 1 || expired[0] != "/.Trash/20260102-030405.000" {
+		t.Fatalf("expired after retention: %v, %v", expired, err)
+	}
+	if _, err := leader.metadata.DeleteNode(expired[0], true); err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if _, err := leader.metadata.GetNodeInfo(trashPath); err != badger.ErrKeyNotFound {
+		t.Errorf("purged checkpoint still exists: %v", err)
+	}
+}
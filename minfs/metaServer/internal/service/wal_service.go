@@ -1,10 +1,11 @@
 package service
 
 import (
-	"context"
 	"crypto/sha256"
+	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -16,34 +17,25 @@ import (
 	"metaServer/pb"
 
 	"github.com/dgraph-io/badger/v3"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/protobuf/proto"
 )
 
+// ErrLogCompacted 请求的日志条目已被快照取代并删除
+var ErrLogCompacted = errors.New("log entries have been compacted into a snapshot")
+
 // WALService 管理Write-Ahead Log
 type WALService struct {
 	db            *badger.DB
 	config        *model.Config
 	nextLogIndex  uint64
+	lastLogTerm   uint64 // 最后一条日志的任期
+	snapshotIndex uint64 // 最近快照包含的最后一条日志的索引，之前的日志已删除
+	snapshotTerm  uint64 // 最近快照包含的最后一条日志的任期
 	mutex         sync.RWMutex
 	walDir        string
 	
-	// 用于同步到followers
-	followers     map[string]*FollowerClient
-	followerMutex sync.RWMutex
-	
-	// Leader选举引用
-	leaderElection *LeaderElection
-}
-
-// FollowerClient 表示一个follower连接
-type FollowerClient struct {
-	nodeID   string
-	address  string
-	client   pb.MetaServerServiceClient
-	conn     *grpc.ClientConn // gRPC连接
-	lastSync uint64           // 最后同步的日志索引
+	// Raft节点引用
+	raftNode *RaftNode
 }
 
 // NewWALService 创建WAL服务
@@ -52,28 +44,44 @@ func NewWALService(db *badger.DB, config *model.Config, nodeID string) *WALServi
 	os.MkdirAll(walDir, 0755)
 	
 	ws := &WALService{
-		db:        db,
-		config:    config,
-		walDir:    walDir,
-		followers: make(map[string]*FollowerClient),
+		db:     db,
+		config: config,
+		walDir: walDir,
 	}
 	
 	// 初始化下一个日志索引
 	ws.initNextLogIndex()
 	
-	log.Printf("WAL Service initialized for node %s, next log index: %d", nodeID, ws.nextLogIndex)
+	log.Printf("WAL Service initialized for node %s, next log index: %d, snapshot index: %d", nodeID, ws.nextLogIndex, ws.snapshotIndex)
 	return ws
 }
 
 // initNextLogIndex 初始化下一个日志索引
 func (ws *WALService) initNextLogIndex() {
 	err := ws.db.View(func(txn *badger.Txn) error {
+		// 日志被快照截断后从快照位置继续编号
+		if item, err := txn.Get([]byte(model.RaftSnapshotKey)); err == nil {
+			if err := item.Value(func(val []byte) error {
+				if len(val) != 16 {
+					return fmt.Errorf("invalid snapshot metadata")
+				}
+				ws.snapshotIndex = binary.BigEndian.Uint64(val[:8])
+				ws.snapshotTerm = binary.BigEndian.Uint64(val[8:])
+				return nil
+			}); err != nil {
+				return err
+			}
+		} else if err != badger.ErrKeyNotFound {
+			return err
+		}
+
 		// 查找最大的日志索引
 		it := txn.NewIterator(badger.DefaultIteratorOptions)
 		defer it.Close()
 		
 		prefix := []byte("wal:")
 		maxIndex := uint64(0)
+		maxTerm := uint64(0)
 		
 		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
 			item := it.Item()
@@ -82,6 +90,7 @@ func (ws *WALService) initNextLogIndex() {
 				if err := proto.Unmarshal(val, &entry); err == nil {
 					if entry.LogIndex > maxIndex {
 						maxIndex = entry.LogIndex
+						maxTerm = entry.Term
 					}
 				}
 				return nil
@@ -91,18 +100,23 @@ func (ws *WALService) initNextLogIndex() {
 			}
 		}
 		
+		if maxIndex < ws.snapshotIndex {
+			maxIndex = ws.snapshotIndex
+			maxTerm = ws.snapshotTerm
+		}
 		ws.nextLogIndex = maxIndex + 1
+		ws.lastLogTerm = maxTerm
 		return nil
 	})
 	
 	if err != nil {
 		log.Printf("Failed to initialize next log index: %v", err)
-		ws.nextLogIndex = 1
+		ws.nextLogIndex = ws.snapshotIndex + 1
 	}
 }
 
-// AppendLogEntry 追加一个日志条目
-func (ws *WALService) AppendLogEntry(operation pb.WALOperationType, data interface{}) (*pb.LogEntry, error) {
+// AppendLogEntry 以指定任期追加一个日志条目（leader使用）
+func (ws *WALService) AppendLogEntry(term uint64, operation pb.WALOperationType, data interface{}) (*pb.LogEntry, error) {
 	ws.mutex.Lock()
 	defer ws.mutex.Unlock()
 	
@@ -123,6 +137,7 @@ func (ws *WALService) AppendLogEntry(operation pb.WALOperationType, data interfa
 		Operation: operation,
 		Data:      jsonData,
 		Checksum:  checksum,
+		Term:      term,
 	}
 	
 	// 写入到BadgerDB
@@ -133,14 +148,181 @@ func (ws *WALService) AppendLogEntry(operation pb.WALOperationType, data interfa
 	
 	// 增加日志索引
 	ws.nextLogIndex++
+	ws.lastLogTerm = term
 	
 	log.Printf("WAL: Appended log entry %d, operation: %v", entry.LogIndex, operation)
 	return entry, nil
 }
 
-// WriteLogEntry 将日志条目写入BadgerDB（公共方法，供SyncWAL使用）
-func (ws *WALService) WriteLogEntry(entry *pb.LogEntry) error {
-	return ws.writeLogEntry(entry)
+// StoreEntries 保存从leader收到的日志条目（follower使用）
+// 条目必须紧接在当前最后一条日志之后，冲突的条目需先通过 TruncateFrom 删除
+func (ws *WALService) StoreEntries(entries []*pb.LogEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+
+	if entries[0].LogIndex != ws.nextLogIndex {
+		return fmt.Errorf("log entry %d does not follow last index %d", entries[0].LogIndex, ws.nextLogIndex-1)
+	}
+
+	err := ws.db.Update(func(txn *badger.Txn) error {
+		for _, entry := range entries {
+			data, err := proto.Marshal(entry)
+			if err != nil {
+				return err
+			}
+			if err := txn.Set([]byte(fmt.Sprintf("wal:%010d", entry.LogIndex)), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store log entries: %v", err)
+	}
+
+	last := entries[len(entries)-1]
+	ws.nextLogIndex = last.LogIndex + 1
+	ws.lastLogTerm = last.Term
+	return nil
+}
+
+// TruncateFrom 删除索引不小于 index 的日志条目（follower日志与leader冲突时使用）
+func (ws *WALService) TruncateFrom(index uint64) error {
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+
+	if index >= ws.nextLogIndex {
+		return nil
+	}
+
+	err := ws.db.Update(func(txn *badger.Txn) error {
+		for i := index; i < ws.nextLogIndex; i++ {
+			if err := txn.Delete([]byte(fmt.Sprintf("wal:%010d", i))); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to truncate log from %d: %v", index, err)
+	}
+
+	log.Printf("WAL: Truncated conflicting entries %d-%d", index, ws.nextLogIndex-1)
+	ws.nextLogIndex = index
+	term, err := ws.termAtLocked(index - 1)
+	if err != nil {
+		return fmt.Errorf("failed to read log entry %d: %v", index-1, err)
+	}
+	ws.lastLogTerm = term
+	return nil
+}
+
+// TermAt 获取指定索引日志条目的任期，索引0的任期为0
+// 快照位置返回快照的任期，更早的索引返回 ErrLogCompacted
+func (ws *WALService) TermAt(index uint64) (uint64, error) {
+	ws.mutex.RLock()
+	defer ws.mutex.RUnlock()
+	return ws.termAtLocked(index)
+}
+
+// termAtLocked 同 TermAt，调用方需持有 ws.mutex
+func (ws *WALService) termAtLocked(index uint64) (uint64, error) {
+	if index == 0 {
+		return 0, nil
+	}
+	if index == ws.snapshotIndex {
+		return ws.snapshotTerm, nil
+	}
+	if index < ws.snapshotIndex {
+		return 0, ErrLogCompacted
+	}
+	entry, err := ws.GetLogEntry(index)
+	if err != nil {
+		return 0, err
+	}
+	return entry.Term, nil
+}
+
+// SnapshotIndexAndTerm 获取最近快照包含的最后一条日志的索引和任期
+func (ws *WALService) SnapshotIndexAndTerm() (uint64, uint64) {
+	ws.mutex.RLock()
+	defer ws.mutex.RUnlock()
+	return ws.snapshotIndex, ws.snapshotTerm
+}
+
+// CompactTo 记录新快照的位置并删除索引不大于 index 的日志条目
+// 本地日志在 index 处的任期与快照不一致（或日志更短）时，之后的条目也一并删除，日志从快照位置重新开始
+func (ws *WALService) CompactTo(index, term uint64) error {
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+
+	if index <= ws.snapshotIndex {
+		return nil
+	}
+
+	end := index
+	keepTail := false
+	if index < ws.nextLogIndex {
+		if localTerm, err := ws.termAtLocked(index); err == nil && localTerm == term {
+			keepTail = true
+		}
+	}
+	if !keepTail && ws.nextLogIndex-1 > end {
+		end = ws.nextLogIndex - 1
+	}
+
+	meta := make([]byte, 16)
+	binary.BigEndian.PutUint64(meta[:8], index)
+	binary.BigEndian.PutUint64(meta[8:], term)
+
+	// 分批删除，避免单个事务过大
+	start := ws.snapshotIndex + 1
+	for start <= end {
+		txn := ws.db.NewTransaction(true)
+		i := start
+		for ; i <= end; i++ {
+			err := txn.Delete([]byte(fmt.Sprintf("wal:%010d", i)))
+			if err == badger.ErrTxnTooBig {
+				break
+			}
+			if err != nil {
+				txn.Discard()
+				return fmt.Errorf("failed to compact log: %v", err)
+			}
+		}
+		if i > end {
+			// 最后一批与快照位置一起提交
+			if err := txn.Set([]byte(model.RaftSnapshotKey), meta); err != nil {
+				txn.Discard()
+				return fmt.Errorf("failed to save snapshot metadata: %v", err)
+			}
+		}
+		if err := txn.Commit(); err != nil {
+			return fmt.Errorf("failed to compact log: %v", err)
+		}
+		start = i
+	}
+
+	ws.snapshotIndex = index
+	ws.snapshotTerm = term
+	if !keepTail {
+		ws.nextLogIndex = index + 1
+		ws.lastLogTerm = term
+	}
+
+	log.Printf("WAL: Compacted log entries up to %d (term %d), next log index: %d", index, term, ws.nextLogIndex)
+	return nil
+}
+
+// LastLogIndexAndTerm 获取最后一条日志的索引和任期
+func (ws *WALService) LastLogIndexAndTerm() (uint64, uint64) {
+	ws.mutex.RLock()
+	defer ws.mutex.RUnlock()
+	return ws.nextLogIndex - 1, ws.lastLogTerm
 }
 
 // writeLogEntry 将日志条目写入BadgerDB
@@ -188,9 +370,10 @@ func (ws *WALService) GetLogEntriesFrom(startIndex uint64, limit int) ([]*pb.Log
 		defer it.Close()
 		
 		startKey := fmt.Sprintf("wal:%010d", startIndex)
+		prefix := []byte("wal:")
 		count := 0
 		
-		for it.Seek([]byte(startKey)); it.Valid() && count < limit; it.Next() {
+		for it.Seek([]byte(startKey)); it.ValidForPrefix(prefix) && count < limit; it.Next() {
 			item := it.Item()
 			
 			err := item.Value(func(val []byte) error {
@@ -214,13 +397,14 @@ func (ws *WALService) GetLogEntriesFrom(startIndex uint64, limit int) ([]*pb.Log
 	return entries, err
 }
 
-// ReplayLogEntry 回放一个日志条目
-func (ws *WALService) ReplayLogEntry(entry *pb.LogEntry, metadataService *MetadataService) error {
+// ApplyLogEntry 将已提交的日志条目应用到元数据，返回操作结果
+// 删除、重命名和截断返回不再被引用的块，由leader交给垃圾回收
+func (ws *WALService) ApplyLogEntry(entry *pb.LogEntry, metadataService *MetadataService) (interface{}, error) {
 	// 验证校验和
 	hash := sha256.Sum256(entry.Data)
 	expectedChecksum := hex.EncodeToString(hash[:])
 	if entry.Checksum != expectedChecksum {
-		return fmt.Errorf("checksum mismatch for log entry %d", entry.LogIndex)
+		return nil, fmt.Errorf("checksum mismatch for log entry %d", entry.LogIndex)
 	}
 	
 	// 根据操作类型回放
@@ -228,7 +412,7 @@ func (ws *WALService) ReplayLogEntry(entry *pb.LogEntry, metadataService *Metada
 	case pb.WALOperationType_CREATE_NODE:
 		var op pb.CreateNodeOperation
 		if err := json.Unmarshal(entry.Data, &op); err != nil {
-			return fmt.Errorf("failed to unmarshal CreateNodeOperation: %v", err)
+			return nil, fmt.Errorf("failed to unmarshal CreateNodeOperation: %v", err)
 		}
 		
 		log.Printf("WAL Replay: CreateNode %s (type: %v, expected inode: %d)", op.Path, op.Type, op.InodeId)
@@ -239,55 +423,81 @@ func (ws *WALService) ReplayLogEntry(entry *pb.LogEntry, metadataService *Metada
 			// 文件已存在，检查inode ID是否一致
 			if existingNodeInfo.Inode == op.InodeId {
 				log.Printf("WAL Replay: CreateNode %s already exists with correct inode %d, skipping", op.Path, op.InodeId)
-				return nil
+				return nil, nil
 			} else {
 				log.Printf("WAL Replay: CreateNode %s already exists but with different inode %d (expected %d), this indicates data inconsistency", 
 					op.Path, existingNodeInfo.Inode, op.InodeId)
-				return fmt.Errorf("inode mismatch for path %s: existing=%d, expected=%d", op.Path, existingNodeInfo.Inode, op.InodeId)
+				return nil, fmt.Errorf("inode mismatch for path %s: existing=%d, expected=%d", op.Path, existingNodeInfo.Inode, op.InodeId)
 			}
 		}
 		
-		// 文件不存在，使用指定的 Inode ID 创建
-		err = metadataService.CreateNodeWithInode(op.Path, op.Type, &op.InodeId)
+		// 文件不存在，使用指定的 Inode ID 创建；修改时间由 leader 决定，各节点一致
+		mtime := op.Mtime
+		if mtime == 0 {
+			mtime = entry.Timestamp
+		}
+		err = metadataService.CreateNodeWithInode(op.Path, op.Type, &op.InodeId, op.Replication, model.Ownership{
+			Owner: op.Owner,
+			Group: op.Group,
+			Mode:  op.Mode,
+		}, mtime)
 		if err != nil {
 			log.Printf("WAL Replay: Failed to create node %s with inode %d: %v", op.Path, op.InodeId, err)
-			return err
+			return nil, err
 		}
 		
 		log.Printf("WAL Replay: Successfully created node %s with inode %d", op.Path, op.InodeId)
-		return nil
+		return nil, nil
 		
 	case pb.WALOperationType_DELETE_NODE:
 		var op pb.DeleteNodeOperation
 		if err := json.Unmarshal(entry.Data, &op); err != nil {
-			return fmt.Errorf("failed to unmarshal DeleteNodeOperation: %v", err)
+			return nil, fmt.Errorf("failed to unmarshal DeleteNodeOperation: %v", err)
 		}
 		
 		log.Printf("WAL Replay: DeleteNode %s (recursive: %v)", op.Path, op.Recursive)
 		
 		// 执行删除操作
-		_, err := metadataService.DeleteNode(op.Path, op.Recursive)
+		blocks, err := metadataService.deleteNodeInDB(op.Path, op.Recursive)
 		if err != nil {
 			log.Printf("WAL Replay: Failed to delete node %s: %v", op.Path, err)
-			return err
+			return nil, err
 		}
 		
 		log.Printf("WAL Replay: Successfully deleted node %s", op.Path)
-		return nil
+		return blocks, nil
+		
+	case pb.WALOperationType_RENAME_NODE:
+		var op pb.RenameNodeOperation
+		if err := json.Unmarshal(entry.Data, &op); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal RenameNodeOperation: %v", err)
+		}
+		
+		log.Printf("WAL Replay: Rename %s -> %s (overwrite: %v)", op.SrcPath, op.DstPath, op.Overwrite)
+		
+		// 被覆盖文件的块由leader负责回收
+		blocks, err := metadataService.renameNodeInDB(op.SrcPath, op.DstPath, op.Overwrite)
+		if err != nil {
+			log.Printf("WAL Replay: Failed to rename %s -> %s: %v", op.SrcPath, op.DstPath, err)
+			return nil, err
+		}
+		
+		log.Printf("WAL Replay: Successfully renamed %s -> %s", op.SrcPath, op.DstPath)
+		return blocks, nil
 		
 	case pb.WALOperationType_UPDATE_NODE:
 		var op pb.UpdateNodeOperation
 		if err := json.Unmarshal(entry.Data, &op); err != nil {
-			return fmt.Errorf("failed to unmarshal UpdateNodeOperation: %v", err)
+			return nil, fmt.Errorf("failed to unmarshal UpdateNodeOperation: %v", err)
 		}
 		// 这里需要在MetadataService中添加UpdateNode方法
 		log.Printf("WAL Replay: UpdateNode %s (size: %d, mtime: %d)", op.Path, op.Size, op.Mtime)
-		return nil
+		return nil, nil
 		
 	case pb.WALOperationType_FINALIZE_WRITE:
 		var op pb.FinalizeWriteOperation
 		if err := json.Unmarshal(entry.Data, &op); err != nil {
-			return fmt.Errorf("failed to unmarshal FinalizeWriteOperation: %v", err)
+			return nil, fmt.Errorf("failed to unmarshal FinalizeWriteOperation: %v", err)
 		}
 		
 		log.Printf("WAL Replay: FinalizeWrite %s (inode=%d, size=%d, md5=%s, %d blocks)", 
@@ -298,42 +508,35 @@ func (ws *WALService) ReplayLogEntry(entry *pb.LogEntry, metadataService *Metada
 		if err != nil {
 			// 文件不存在，这不应该发生，因为应该先有CreateNode
 			log.Printf("WAL Replay: ERROR - FinalizeWrite for non-existent file %s (expected inode %d)", op.Path, op.Inode)
-			return fmt.Errorf("cannot finalize write for non-existent file: %s", op.Path)
+			return nil, fmt.Errorf("cannot finalize write for non-existent file: %s", op.Path)
 		}
 		
 		// 验证 Inode ID 是否一致
 		if nodeInfo.Inode != op.Inode {
 			log.Printf("WAL Replay: ERROR - Inode mismatch for FinalizeWrite %s: existing=%d, expected=%d", 
 				op.Path, nodeInfo.Inode, op.Inode)
-			return fmt.Errorf("inode mismatch for FinalizeWrite %s: existing=%d, expected=%d", 
+			return nil, fmt.Errorf("inode mismatch for FinalizeWrite %s: existing=%d, expected=%d", 
 				op.Path, nodeInfo.Inode, op.Inode)
 		}
 		
-		// 设置块映射
-		log.Printf("WAL Replay: Setting block mappings for inode %d", op.Inode)
-		for i, blockMapping := range op.BlockLocations {
-			err := metadataService.setBlockMappingInDB(op.Inode, uint64(i), blockMapping)
-			if err != nil {
-				log.Printf("WAL Replay: Failed to set block mapping for %s (inode %d): %v", op.Path, op.Inode, err)
-				return err
-			}
+		// 块映射、文件大小和写租约在同一事务中更新
+		if op.Mtime == 0 {
+			op.Mtime = entry.Timestamp
 		}
-		
-		// 执行FinalizeWrite操作
 		log.Printf("WAL Replay: Finalizing write for %s with inode %d", op.Path, op.Inode)
-		err = metadataService.FinalizeWrite(op.Path, op.Inode, uint64(op.Size), op.Md5)
+		err = metadataService.finalizeWriteInDB(&op)
 		if err != nil {
 			log.Printf("WAL Replay: Failed to finalize write for %s: %v", op.Path, err)
-			return err
+			return nil, err
 		}
 		
 		log.Printf("WAL Replay: Successfully replayed FinalizeWrite %s", op.Path)
-		return nil
+		return nil, nil
 		
 	case pb.WALOperationType_UPDATE_BLOCK_LOCATION:
 		var op pb.UpdateBlockLocationOperation
 		if err := json.Unmarshal(entry.Data, &op); err != nil {
-			return fmt.Errorf("failed to unmarshal UpdateBlockLocationOperation: %v", err)
+			return nil, fmt.Errorf("failed to unmarshal UpdateBlockLocationOperation: %v", err)
 		}
 		
 		log.Printf("WAL Replay: UpdateBlockLocation block=%d from %s to %s", 
@@ -343,17 +546,17 @@ func (ws *WALService) ReplayLogEntry(entry *pb.LogEntry, metadataService *Metada
 		err := metadataService.updateBlockLocationInDB(op.BlockId, op.OldAddr, op.NewAddr)
 		if err != nil {
 			log.Printf("WAL Replay: Failed to update block location for block %d: %v", op.BlockId, err)
-			return err
+			return nil, err
 		}
 		
 		log.Printf("WAL Replay: Successfully updated block %d location from %s to %s", 
 			op.BlockId, op.OldAddr, op.NewAddr)
-		return nil
+		return nil, nil
 		
 	case pb.WALOperationType_SET_BLOCK_MAPPING:
 		var op pb.SetBlockMappingOperation
 		if err := json.Unmarshal(entry.Data, &op); err != nil {
-			return fmt.Errorf("failed to unmarshal SetBlockMappingOperation: %v", err)
+			return nil, fmt.Errorf("failed to unmarshal SetBlockMappingOperation: %v", err)
 		}
 		
 		log.Printf("WAL Replay: SetBlockMapping inode=%d, index=%d", op.InodeId, op.BlockIndex)
@@ -363,187 +566,126 @@ func (ws *WALService) ReplayLogEntry(entry *pb.LogEntry, metadataService *Metada
 		if err != nil {
 			log.Printf("WAL Replay: Failed to set block mapping for inode %d index %d: %v", 
 				op.InodeId, op.BlockIndex, err)
-			return err
+			return nil, err
 		}
 		
 		log.Printf("WAL Replay: Successfully set block mapping for inode %d index %d", 
 			op.InodeId, op.BlockIndex)
-		return nil
-		
-	default:
-		return fmt.Errorf("unknown WAL operation type: %v", entry.Operation)
-	}
-}
+		return nil, nil
 
-// AddFollower 添加一个follower
-func (ws *WALService) AddFollower(nodeID, address string, client pb.MetaServerServiceClient) {
-	ws.followerMutex.Lock()
-	defer ws.followerMutex.Unlock()
-	
-	ws.followers[nodeID] = &FollowerClient{
-		nodeID:   nodeID,
-		address:  address,
-		client:   client,
-		lastSync: 0,
-	}
-	
-	log.Printf("WAL: Added follower %s at %s", nodeID, address)
-}
+	case pb.WALOperationType_TRUNCATE_BLOCK_MAPPINGS:
+		var op pb.TruncateBlockMappingsOperation
+		if err := json.Unmarshal(entry.Data, &op); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal TruncateBlockMappingsOperation: %v", err)
+		}
 
-// AddFollowerByAddr 通过地址添加follower（创建gRPC连接）
-func (ws *WALService) AddFollowerByAddr(nodeID, address string) error {
-	ws.followerMutex.Lock()
-	defer ws.followerMutex.Unlock()
-	
-	// 创建gRPC连接
-	conn, err := grpc.Dial(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	if err != nil {
-		return fmt.Errorf("failed to connect to follower %s at %s: %v", nodeID, address, err)
-	}
-	
-	client := pb.NewMetaServerServiceClient(conn)
-	
-	// 如果已存在，先关闭旧连接
-	if existingFollower, exists := ws.followers[nodeID]; exists && existingFollower.conn != nil {
-		existingFollower.conn.Close()
-	}
-	
-	ws.followers[nodeID] = &FollowerClient{
-		nodeID:   nodeID,
-		address:  address,
-		client:   client,
-		conn:     conn,
-		lastSync: 0,
-	}
-	
-	log.Printf("WAL: Added follower %s at %s with gRPC connection", nodeID, address)
-	return nil
-}
+		log.Printf("WAL Replay: TruncateBlockMappings inode=%d, count=%d", op.InodeId, op.BlockCount)
 
-// RemoveFollower 移除一个follower
-func (ws *WALService) RemoveFollower(nodeID string) {
-	ws.followerMutex.Lock()
-	defer ws.followerMutex.Unlock()
-	
-	if follower, exists := ws.followers[nodeID]; exists && follower.conn != nil {
-		follower.conn.Close()
-	}
-	
-	delete(ws.followers, nodeID)
-	log.Printf("WAL: Removed follower %s", nodeID)
-}
+		// 被截断的块由Leader负责回收
+		removed, err := metadataService.truncateBlockMappingsInDB(op.InodeId, op.BlockCount)
+		if err != nil {
+			log.Printf("WAL Replay: Failed to truncate block mappings for inode %d: %v", op.InodeId, err)
+			return nil, err
+		}
+		return removed, nil
 
-// UpdateFollowers 更新followers列表（leader选举时调用）
-func (ws *WALService) UpdateFollowers(followerNodes []*MetaServerNode) {
-	ws.followerMutex.Lock()
-	defer ws.followerMutex.Unlock()
-	
-	// 关闭所有现有连接
-	for _, follower := range ws.followers {
-		if follower.conn != nil {
-			follower.conn.Close()
+	case pb.WALOperationType_GRANT_LEASE:
+		var op pb.GrantLeaseOperation
+		if err := json.Unmarshal(entry.Data, &op); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal GrantLeaseOperation: %v", err)
 		}
-	}
-	
-	// 清空followers
-	ws.followers = make(map[string]*FollowerClient)
-	
-	// 添加新的followers
-	for _, node := range followerNodes {
-		conn, err := grpc.Dial(node.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
-		if err != nil {
-			log.Printf("WAL: Failed to connect to follower %s at %s: %v", node.NodeID, node.Addr, err)
-			continue
+
+		log.Printf("WAL Replay: GrantLease %s to %s (inode=%d)", op.Path, op.Holder, op.Inode)
+		return nil, metadataService.grantLeaseInDB(&op)
+
+	case pb.WALOperationType_RELEASE_LEASE:
+		var op pb.ReleaseLeaseOperation
+		if err := json.Unmarshal(entry.Data, &op); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal ReleaseLeaseOperation: %v", err)
 		}
-		
-		client := pb.NewMetaServerServiceClient(conn)
-		
-		ws.followers[node.NodeID] = &FollowerClient{
-			nodeID:   node.NodeID,
-			address:  node.Addr,
-			client:   client,
-			conn:     conn,
-			lastSync: 0,
+
+		log.Printf("WAL Replay: ReleaseLease %s held by %s", op.Path, op.Holder)
+		return nil, metadataService.releaseLeaseInDB(op.Path, op.Holder)
+
+	case pb.WALOperationType_SET_QUOTA:
+		var op pb.SetQuotaOperation
+		if err := json.Unmarshal(entry.Data, &op); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal SetQuotaOperation: %v", err)
 		}
-		
-		log.Printf("WAL: Connected to follower %s at %s", node.NodeID, node.Addr)
-	}
-	
-	log.Printf("WAL: Updated followers list, now have %d followers", len(ws.followers))
-}
 
-// SyncToFollowers 同步日志到所有followers
-func (ws *WALService) SyncToFollowers(entry *pb.LogEntry) error {
-	ws.followerMutex.RLock()
-	followers := make(map[string]*FollowerClient)
-	for k, v := range ws.followers {
-		followers[k] = v
-	}
-	ws.followerMutex.RUnlock()
-	
-	// 并行同步到所有followers
-	var wg sync.WaitGroup
-	errors := make(chan error, len(followers))
-	
-	for _, follower := range followers {
-		wg.Add(1)
-		go func(f *FollowerClient) {
-			defer wg.Done()
-			
-			// 调用follower的SyncWAL接口发送WAL条目
-			err := ws.syncWALToFollower(f, entry)
-			if err != nil {
-				log.Printf("Failed to sync WAL entry %d to follower %s: %v", entry.LogIndex, f.nodeID, err)
-				errors <- err
-			} else {
-				log.Printf("WAL: Successfully synced entry %d to follower %s", entry.LogIndex, f.nodeID)
-				f.lastSync = entry.LogIndex
-			}
-		}(follower)
-	}
-	
-	wg.Wait()
-	close(errors)
-	
-	// 检查是否有错误
-	for err := range errors {
-		if err != nil {
-			return fmt.Errorf("failed to sync to some followers: %v", err)
+		log.Printf("WAL Replay: SetQuota %s (max bytes: %d, max inodes: %d)", op.Path, op.MaxBytes, op.MaxInodes)
+		return nil, metadataService.setQuotaInDB(op.Path, op.MaxBytes, op.MaxInodes)
+
+	case pb.WALOperationType_SET_REPLICATION:
+		var op pb.SetReplicationOperation
+		if err := json.Unmarshal(entry.Data, &op); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal SetReplicationOperation: %v", err)
 		}
-	}
-	
-	return nil
-}
 
-// syncWALToFollower 向单个follower同步WAL条目
-func (ws *WALService) syncWALToFollower(follower *FollowerClient, entry *pb.LogEntry) error {
-	if follower.client == nil {
-		return fmt.Errorf("follower %s has no gRPC client", follower.nodeID)
-	}
-	
-	// 创建gRPC流
-	stream, err := follower.client.SyncWAL(context.Background())
-	if err != nil {
-		return fmt.Errorf("failed to create SyncWAL stream: %v", err)
-	}
-	
-	// 发送WAL条目
-	err = stream.Send(entry)
-	if err != nil {
-		return fmt.Errorf("failed to send WAL entry: %v", err)
-	}
-	
-	// 关闭流并获取响应
-	resp, err := stream.CloseAndRecv()
-	if err != nil {
-		return fmt.Errorf("failed to close stream: %v", err)
-	}
-	
-	if !resp.Success {
-		return fmt.Errorf("follower rejected WAL entry: %s", resp.Message)
+		log.Printf("WAL Replay: SetReplication %s to %d", op.Path, op.Replication)
+		return nil, metadataService.setReplicationInDB(op.Path, op.Replication)
+
+	case pb.WALOperationType_SET_PERMISSION:
+		var op pb.SetPermissionOperation
+		if err := json.Unmarshal(entry.Data, &op); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal SetPermissionOperation: %v", err)
+		}
+
+		log.Printf("WAL Replay: SetPermission %s", op.Path)
+		return nil, metadataService.setPermissionInDB(&op)
+
+	case pb.WALOperationType_SET_EC_POLICY:
+		var op pb.SetErasureCodingPolicyOperation
+		if err := json.Unmarshal(entry.Data, &op); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal SetErasureCodingPolicyOperation: %v", err)
+		}
+
+		log.Printf("WAL Replay: SetErasureCodingPolicy %s to %q", op.Path, op.Policy)
+		return nil, metadataService.setECPolicyInDB(op.Path, op.Policy)
+
+	case pb.WALOperationType_CONVERT_BLOCK:
+		var op pb.ConvertBlockOperation
+		if err := json.Unmarshal(entry.Data, &op); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal ConvertBlockOperation: %v", err)
+		}
+
+		log.Printf("WAL Replay: ConvertBlock inode %d index %d from block %d to group %d",
+			op.InodeId, op.BlockIndex, op.OldBlockId, op.GetGroup().GetBlockId())
+		return nil, metadataService.convertBlockInDB(&op)
+
+	case pb.WALOperationType_SET_DATASERVER_STATE:
+		var op pb.SetDataServerStateOperation
+		if err := json.Unmarshal(entry.Data, &op); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal SetDataServerStateOperation: %v", err)
+		}
+
+		log.Printf("WAL Replay: SetDataServerState %s to %s", op.Address, op.State)
+		return nil, metadataService.setDataServerStateInDB(&op)
+
+	case pb.WALOperationType_CREATE_SNAPSHOT:
+		var op pb.CreateSnapshotOperation
+		if err := json.Unmarshal(entry.Data, &op); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal CreateSnapshotOperation: %v", err)
+		}
+
+		log.Printf("WAL Replay: CreateSnapshot %s of %s", op.Name, op.Path)
+		return nil, metadataService.createSnapshotInDB(op.Name, op.Path, op.CreatedAt)
+
+	case pb.WALOperationType_DELETE_SNAPSHOT:
+		var op pb.DeleteSnapshotOperation
+		if err := json.Unmarshal(entry.Data, &op); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal DeleteSnapshotOperation: %v", err)
+		}
+
+		log.Printf("WAL Replay: DeleteSnapshot %s", op.Name)
+		return metadataService.deleteSnapshotInDB(op.Name)
+
+	case pb.WALOperationType_NO_OP:
+		return nil, nil
+		
+	default:
+		return nil, fmt.Errorf("unknown WAL operation type: %v", entry.Operation)
 	}
-	
-	return nil
 }
 
 // GetCurrentLogIndex 获取当前日志索引
@@ -553,121 +695,23 @@ func (ws *WALService) GetCurrentLogIndex() uint64 {
 	return ws.nextLogIndex - 1
 }
 
-// UpdateNextLogIndex 更新下一个日志索引（用于同步时）
-func (ws *WALService) UpdateNextLogIndex(nextIndex uint64) {
-	ws.mutex.Lock()
-	defer ws.mutex.Unlock()
-	if nextIndex > ws.nextLogIndex {
-		ws.nextLogIndex = nextIndex
-	}
-}
-
 // Close 关闭WAL服务
 func (ws *WALService) Close() error {
-	ws.followerMutex.Lock()
-	defer ws.followerMutex.Unlock()
-	
-	// 关闭所有follower连接
-	for _, follower := range ws.followers {
-		if follower.conn != nil {
-			follower.conn.Close()
-		}
-	}
-	
 	log.Println("WAL Service stopped")
 	return nil
 }
 
-// SetLeaderElection 设置Leader选举引用
-func (ws *WALService) SetLeaderElection(le *LeaderElection) {
-	ws.leaderElection = le
+// SetRaftNode 设置Raft节点引用
+func (ws *WALService) SetRaftNode(rn *RaftNode) {
+	ws.raftNode = rn
 }
 
 // IsLeader 检查当前节点是否是Leader
 func (ws *WALService) IsLeader() bool {
-	if ws.leaderElection == nil {
+	if ws.raftNode == nil {
 		return false
 	}
-	return ws.leaderElection.IsLeader()
-}
-
-// RequestWALSyncFromLeader 从leader请求WAL同步（当节点重新加入集群时）
-func (ws *WALService) RequestWALSyncFromLeader(leaderAddr string, nodeID string, lastLogIndex uint64) error {
-	log.Printf("Requesting WAL sync from leader %s, node %s, from index %d", leaderAddr, nodeID, lastLogIndex)
-	
-	// 创建连接到leader的gRPC客户端
-	conn, err := grpc.Dial(leaderAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	if err != nil {
-		return fmt.Errorf("failed to connect to leader %s: %v", leaderAddr, err)
-	}
-	defer conn.Close()
-	
-	client := pb.NewMetaServerServiceClient(conn)
-	
-	// 发送WAL同步请求
-	req := &pb.RequestWALSyncRequest{
-		NodeId:       nodeID,
-		LastLogIndex: lastLogIndex,
-		Reason:       "rejoin_cluster",
-	}
-	
-	stream, err := client.RequestWALSync(context.Background(), req)
-	if err != nil {
-		return fmt.Errorf("failed to request WAL sync: %v", err)
-	}
-	
-	syncedCount := 0
-	fullReset := false
-	
-	// 接收WAL条目流
-	for {
-		entry, err := stream.Recv()
-		if err != nil {
-			if err.Error() == "EOF" {
-				log.Printf("WAL sync completed from leader, synced %d entries (full reset: %v)", syncedCount, fullReset)
-				break
-			}
-			return fmt.Errorf("failed to receive WAL entry: %v", err)
-		}
-		
-		// 检查是否需要完全重置（第一个条目的索引为1表示需要完全重置）
-		if syncedCount == 0 && entry.LogIndex == 1 {
-			log.Printf("Detected log divergence, performing full WAL reset")
-			fullReset = true
-			
-			// 清空现有WAL数据
-			err = ws.clearAllWALEntries()
-			if err != nil {
-				return fmt.Errorf("failed to clear WAL entries: %v", err)
-			}
-			
-			// 重置日志索引
-			ws.mutex.Lock()
-			ws.nextLogIndex = 1
-			ws.mutex.Unlock()
-			
-			log.Printf("WAL database cleared, ready for complete resync")
-		}
-		
-		// 将WAL条目写入本地存储但不执行
-		err = ws.WriteLogEntry(entry)
-		if err != nil {
-			return fmt.Errorf("failed to write WAL entry %d: %v", entry.LogIndex, err)
-		}
-		
-		// 更新下一个日志索引
-		ws.mutex.Lock()
-		if entry.LogIndex >= ws.nextLogIndex {
-			ws.nextLogIndex = entry.LogIndex + 1
-		}
-		ws.mutex.Unlock()
-		
-		syncedCount++
-		log.Printf("Synced WAL entry %d from leader, operation: %v", entry.LogIndex, entry.Operation)
-	}
-	
-	log.Printf("WAL sync from leader completed successfully, total entries: %d (full reset: %v)", syncedCount, fullReset)
-	return nil
+	return ws.raftNode.IsLeader()
 }
 
 // GetAllLogEntries 获取所有WAL日志条目（用于leader响应同步请求）
@@ -682,6 +726,12 @@ func (ws *WALService) GetAllLogEntries(fromIndex uint64) ([]*pb.LogEntry, error)
 		// 返回从索引1开始的所有条目
 		fromIndex = 1
 	}
+
+	// 快照之前的日志已删除，follower需要先安装快照
+	snapshotIndex, _ := ws.SnapshotIndexAndTerm()
+	if fromIndex <= snapshotIndex {
+		return nil, fmt.Errorf("entries from %d are covered by snapshot %d: %v", fromIndex, snapshotIndex, ErrLogCompacted)
+	}
 	
 	err := ws.db.View(func(txn *badger.Txn) error {
 		it := txn.NewIterator(badger.DefaultIteratorOptions)
@@ -721,74 +771,3 @@ func (ws *WALService) GetAllLogEntries(fromIndex uint64) ([]*pb.LogEntry, error)
 	}
 	return entries, nil
 }
-
-// ReplayWALFromIndex 从指定索引开始回放WAL（仅在当选leader时使用）
-func (ws *WALService) ReplayWALFromIndex(startIndex uint64, metadataService *MetadataService) error {
-	if !ws.IsLeader() {
-		return fmt.Errorf("only leader can replay WAL")
-	}
-	
-	log.Printf("Starting WAL replay from index %d", startIndex)
-	
-	entries, err := ws.GetAllLogEntries(startIndex)
-	if err != nil {
-		return fmt.Errorf("failed to get WAL entries: %v", err)
-	}
-	
-	replayedCount := 0
-	for _, entry := range entries {
-		err = ws.ReplayLogEntry(entry, metadataService)
-		if err != nil {
-			log.Printf("Failed to replay WAL entry %d: %v", entry.LogIndex, err)
-			return err
-		}
-		replayedCount++
-	}
-	
-	log.Printf("WAL replay completed, replayed %d entries", replayedCount)
-	return nil
-}
-
-// clearAllWALEntries 清空所有WAL条目（用于分叉恢复时的强制重置）
-func (ws *WALService) clearAllWALEntries() error {
-	log.Printf("Clearing all WAL entries due to log divergence")
-	
-	// 收集所有WAL keys
-	var keysToDelete []string
-	
-	err := ws.db.View(func(txn *badger.Txn) error {
-		it := txn.NewIterator(badger.DefaultIteratorOptions)
-		defer it.Close()
-		
-		prefix := []byte("wal:")
-		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
-			item := it.Item()
-			key := string(item.Key())
-			keysToDelete = append(keysToDelete, key)
-		}
-		
-		return nil
-	})
-	
-	if err != nil {
-		return fmt.Errorf("failed to collect WAL keys: %v", err)
-	}
-	
-	// 删除所有WAL keys
-	err = ws.db.Update(func(txn *badger.Txn) error {
-		for _, key := range keysToDelete {
-			err := txn.Delete([]byte(key))
-			if err != nil {
-				return fmt.Errorf("failed to delete WAL key %s: %v", key, err)
-			}
-		}
-		return nil
-	})
-	
-	if err != nil {
-		return fmt.Errorf("failed to delete WAL entries: %v", err)
-	}
-	
-	log.Printf("Successfully cleared %d WAL entries", len(keysToDelete))
-	return nil
-}
\ No newline at end of file